@@ -11,8 +11,12 @@ import (
 	"k8s.io/component-base/cli"
 
 	// us
+	"github.com/openshift/console-operator/pkg/cmd/backup"
+	"github.com/openshift/console-operator/pkg/cmd/check"
 	"github.com/openshift/console-operator/pkg/cmd/crdconversionwebhook"
+	"github.com/openshift/console-operator/pkg/cmd/diff"
 	"github.com/openshift/console-operator/pkg/cmd/operator"
+	"github.com/openshift/console-operator/pkg/cmd/render"
 	"github.com/openshift/console-operator/pkg/cmd/version"
 )
 
@@ -40,6 +44,10 @@ func NewOperatorCommand() *cobra.Command {
 	cmd.AddCommand(operator.NewOperator())
 	cmd.AddCommand(version.NewVersion())
 	cmd.AddCommand(crdconversionwebhook.NewConverter())
+	cmd.AddCommand(render.NewRender())
+	cmd.AddCommand(diff.NewDiff())
+	cmd.AddCommand(check.NewCheck())
+	cmd.AddCommand(backup.NewBackup())
 
 	return cmd
 }
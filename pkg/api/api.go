@@ -1,27 +1,119 @@
 package api
 
 const (
+	AccessLogClaimAnnotation            = "console.operator.openshift.io/access-log-claim"
+	AccessLogDestinationAnnotation      = "console.operator.openshift.io/access-log-destination"
+	AccessLogFormatAnnotation           = "console.operator.openshift.io/access-log-format"
+	AccessLogMountDir                   = "/var/log/console"
+	AccessLogSamplingAnnotation         = "console.operator.openshift.io/access-log-sampling-ratio"
+	AccessLogVolumeName                 = "access-log"
+	AccessTokenInactivityAnnotation     = "console.operator.openshift.io/access-token-inactivity-timeout"
+	AccessTokenMaxAgeAnnotation         = "console.operator.openshift.io/access-token-max-age"
+	APIClientBurstAnnotation            = "console.operator.openshift.io/api-client-burst"
+	APIClientQPSAnnotation              = "console.operator.openshift.io/api-client-qps"
+	APIClientTimeoutAnnotation          = "console.operator.openshift.io/api-client-timeout"
+	APIProxyRateLimitAnnotation         = "console.operator.openshift.io/rate-limit-api-proxy-per-user"
 	AuthServerCAMountDir                = "/var/auth-server-ca"
 	AuthServerCAFileName                = "ca-bundle.crt"
+	AutoscalingAnnotation               = "console.operator.openshift.io/autoscaling"
+	BlueGreenDurationAnnotation         = "console.operator.openshift.io/blue-green-duration"
+	BlueGreenRollbackAnnotation         = "console.operator.openshift.io/blue-green-rollback"
+	BlueGreenStateAnnotation            = "console.operator.openshift.io/blue-green-state"
+	CABundleHashAnnotation              = "console.openshift.io/ca-bundle-hash"
+	CanaryConfigOverridesAnnotation     = "console.operator.openshift.io/canary-config-overrides"
+	CanaryDurationAnnotation            = "console.operator.openshift.io/canary-duration"
+	CanaryServingCertName               = "console-canary-serving-cert"
+	CanaryStateAnnotation               = "console.operator.openshift.io/canary-state"
+	CanaryWeightAnnotation              = "console.operator.openshift.io/canary-weight"
+	CapabilityAnnotation                = "capability.openshift.io/name"
 	ClusterOperatorName                 = "console"
+	ClusterProxyConfigAnnotation        = "console.operator.openshift.io/cluster-proxy-config"
+	ClusterProxyHealthConfigMapName     = "cluster-proxy-health"
 	ConfigResourceName                  = "cluster"
+	ConfigMapChangeChainAnnotation      = "console.operator.openshift.io/config-change-chain"
+	ConfigMapDataHashAnnotation         = "console.operator.openshift.io/applied-data-hash"
+	ConfigMapLastDriftAnnotation        = "console.operator.openshift.io/last-drift"
 	ConsoleContainerPort                = 443
 	ConsoleContainerPortName            = "https"
 	ConsoleContainerTargetPort          = 8443
+	ConsoleEndpointsConfigMapName       = "console-endpoints"
+	ConsoleEnvAnnotation                = "console.operator.openshift.io/console-env"
+	ConsoleMountsAnnotation             = "console.operator.openshift.io/console-mounts"
+	ConsoleProbesAnnotation             = "console.operator.openshift.io/console-probes"
+	ConsoleReleaseVersionAnnotation     = "console.openshift.io/release-version"
+	ConsoleResourcesAnnotation          = "console.operator.openshift.io/console-resources"
+	ConsoleSamplesStatusConfigMapName   = "console-samples-status"
 	ConsoleServingCertName              = "console-serving-cert"
+	ContentSecurityPolicyAnnotation     = "console.operator.openshift.io/content-security-policy"
+	CustomizationBundleAnnotation       = "console.operator.openshift.io/customization-bundle"
 	DefaultIngressCertConfigMapName     = "default-ingress-cert"
 	DownloadsPort                       = 8080
 	DownloadsPortName                   = "http"
 	DownloadsResourceName               = "downloads"
+	DownloadsResourcesAnnotation        = "console.operator.openshift.io/downloads-resources"
+	ForceSyncAnnotation                 = "console.operator.openshift.io/force-sync"
+	GracefulTerminationAnnotation       = "console.operator.openshift.io/graceful-termination"
+	GreenServingCertName                = "console-green-serving-cert"
+	GroupInactivityTimeoutsAnnotation   = "console.operator.openshift.io/group-inactivity-timeouts"
+	ImageVerificationKeysAnnotation     = "console.operator.openshift.io/image-verification-keys"
+	InfraNodePlacementAnnotation        = "console.operator.openshift.io/infra-node-placement"
+	InspectionConfigMapName             = "console-operator-inspection"
+	InstallConfigConfigMapName          = "cluster-config-v1"
+	InstallConfigKey                    = "install-config"
+	KubeSystemNamespace                 = "kube-system"
+	LoginLockoutAnnotation              = "console.operator.openshift.io/login-lockout"
+	LoginRateLimitAnnotation            = "console.operator.openshift.io/rate-limit-login-per-ip"
+	MaintenanceWindowLabel              = "console.operator.openshift.io/maintenance-window"
+	MaintenanceWindowsConfigMapName     = "console-maintenance-windows"
+	ManagedClusterCABundleConfigMapName = "managed-cluster-ca-bundle"
+	ManagedClusterCABundleMountDir      = "/var/managed-cluster-ca"
+	ManagedClusterDisplayNameAnnotation = "console.openshift.io/display-name"
+	ManagedClusterOAuthClientName       = "multicluster-console"
+	ManagedClusterOAuthMountDir         = "/var/managed-cluster-oauth"
+	ManagedClusterOAuthSecretName       = "managed-cluster-oauth-clients"
+	ManagedClustersConfigMapName        = "managed-clusters"
 	NodeArchitectureLabel               = "kubernetes.io/arch"
 	NodeOperatingSystemLabel            = "kubernetes.io/os"
+	NodeRoleInfraLabel                  = "node-role.kubernetes.io/infra"
+	NodeRoleMasterLabel                 = "node-role.kubernetes.io/master"
+	NodeSelectorAnnotation              = "console.operator.openshift.io/node-selector"
+	NodeUpdateConsoleNotification       = "node-updates"
+	NodeUpdateNotificationsAnnotation   = "console.operator.openshift.io/node-update-notifications"
+	OAuthChallengesAnnotation           = "console.operator.openshift.io/oauth-respond-with-challenges"
+	OAuthClientSecretMaxAgeAnnotation   = "console.operator.openshift.io/oauth-client-secret-max-age"
+	OAuthClientSecretRefAnnotation      = "console.operator.openshift.io/oauth-client-secret-ref"
+	OAuthClientSecretRotateAnnotation   = "console.operator.openshift.io/oauth-client-secret-rotate"
+	OAuthClientSecretRotationAnnotation = "console.operator.openshift.io/oauth-client-secret-rotation-interval"
 	OAuthConfigMapName                  = "oauth-openshift"
+	OAuthErrorTemplateSecretName        = "console-oauth-error-template"
+	OAuthGrantMethodAnnotation          = "console.operator.openshift.io/oauth-grant-method"
+	OAuthLoginTemplateSecretName        = "console-oauth-login-template"
+	OAuthProvidersTemplateSecretName    = "console-oauth-providers-template"
+	OAuthRedirectURIsAnnotation         = "console.operator.openshift.io/oauth-redirect-uris"
+	OAuthRouteName                      = "oauth-openshift"
 	OAuthServingCertConfigMapName       = "oauth-serving-cert"
+	OAuthTemplatesAnnotation            = "console.operator.openshift.io/oauth-templates"
+	OAuthTemplatesColorsAnnotation      = "console.operator.openshift.io/oauth-templates-colors"
 	OCCLIDownloadsCustomResourceName    = "oc-cli-downloads"
 	ODOCLIDownloadsCustomResourceName   = "odo-cli-downloads"
+	OIDCCABundleAnnotation              = "console.operator.openshift.io/oidc-ca-bundle"
+	OIDCCATrustConfigMapName            = "oidc-ca-trust-bundle"
+	OIDCClientRegistrationAnnotation    = "console.operator.openshift.io/oidc-client-registration"
+	OIDCClientSecretRotationAnnotation  = "console.operator.openshift.io/oidc-client-secret-rotation"
+	OIDCClientSecretSourceAnnotation    = "console.openshift.io/oidc-client-secret-source"
+	OIDCClockSkewAnnotation             = "console.operator.openshift.io/oidc-clock-skew"
+	OIDCIDTokenLeewayAnnotation         = "console.operator.openshift.io/oidc-id-token-leeway"
+	OIDCIssuerEndpointsAnnotation       = "console.operator.openshift.io/oidc-issuer-endpoints"
+	OIDCLoginProbeAnnotation            = "console.operator.openshift.io/oidc-login-probe"
+	OIDCPublicClientAnnotation          = "console.operator.openshift.io/oidc-public-client"
+	OIDCRegisteredClientSecretName      = "console-oidc-registered-client"
+	OIDCRegisteredIssuerAnnotation      = "console.openshift.io/oidc-registered-issuer"
+	OIDCSecretProviderClassAnnotation   = "console.operator.openshift.io/oidc-client-secret-provider-class"
 	OLMConfigGroup                      = "operators.coreos.com"
 	OLMConfigResource                   = "olmconfigs"
 	OLMConfigVersion                    = "v1"
+	OpenShiftAuthenticationNamespace    = "openshift-authentication"
+	OpenShiftCLIComponentName           = "cli"
 	OpenShiftConfigManagedNamespace     = "openshift-config-managed"
 	OpenShiftConfigNamespace            = "openshift-config"
 	OpenShiftConsoleConfigMapName       = "console-config"
@@ -34,24 +126,71 @@ const (
 	OpenshiftConsoleCustomRouteName     = "console-custom"
 	OpenshiftDownloadsCustomRouteName   = "downloads-custom"
 	OpenshiftConsoleRedirectServiceName = "console-redirect"
+	PausedAnnotation                    = "console.operator.openshift.io/paused"
+	PausedPoolsConsoleNotification      = "paused-machine-config-pools"
+	PausedPoolsSinceAnnotation          = "console.operator.openshift.io/paused-since"
+	PluginConsoleVersionAnnotation      = "console.openshift.io/console-version-range"
+	PluginCSPAnnotation                 = "console.openshift.io/content-security-policy"
+	PodDisruptionBudgetAnnotation       = "console.operator.openshift.io/pod-disruption-budget"
+	PodTemplateAnnotationsAnnotation    = "console.operator.openshift.io/pod-template-annotations"
+	PodTemplateLabelsAnnotation         = "console.operator.openshift.io/pod-template-labels"
+	PreUpgradeWindowAnnotation          = "console.operator.openshift.io/pre-upgrade-window"
+	PreviousSessionAuthenticationKey    = "previousSessionAuthenticationKey"
+	PreviousSessionEncryptionKey        = "previousSessionEncryptionKey"
+	PriorityClassAnnotation             = "console.operator.openshift.io/priority-class"
+	PullSecretName                      = "pull-secret"
+	ReadOnlyConsoleNotification         = "read-only-mode"
+	ReadOnlyModeAnnotation              = "console.operator.openshift.io/read-only"
 	RedirectContainerPort               = 8444
 	RedirectContainerPortName           = "custom-route-redirect"
+	ReplicasAnnotation                  = "console.operator.openshift.io/replicas"
+	RollingUpdateAnnotation             = "console.operator.openshift.io/rolling-update"
+	RolloutStrategyAnnotation           = "console.operator.openshift.io/rollout-strategy"
+	RouteRateLimitAnnotation            = "console.operator.openshift.io/rate-limit-http-per-ip"
+	SampleConnectivityPolicyAnnotation  = "console.operator.openshift.io/sample-connectivity-policy"
+	SecretsStoreCSIDriverName           = "secrets-store.csi.k8s.io"
 	ServiceCAConfigMapName              = "service-ca"
+	ServerCompressionAnnotation         = "console.operator.openshift.io/server-compression"
+	ServerIdleTimeoutAnnotation         = "console.operator.openshift.io/server-idle-timeout"
+	ServerMaxHeaderBytesAnnotation      = "console.operator.openshift.io/server-max-header-bytes"
+	ServerReadTimeoutAnnotation         = "console.operator.openshift.io/server-read-timeout"
+	ServerWriteTimeoutAnnotation        = "console.operator.openshift.io/server-write-timeout"
+	SessionAuthenticationKey            = "sessionAuthenticationKey"
+	SessionEncryptionKey                = "sessionEncryptionKey"
+	SessionKeyRotationAnnotation        = "console.operator.openshift.io/session-key-rotation-interval"
+	SessionPolicyAnnotation             = "console.openshift.io/session-policy"
+	SessionSecretMountDir               = "/var/session-secret"
 	SessionSecretName                   = "session-secret"
+	SidecarContainersAnnotation         = "console.operator.openshift.io/sidecar-containers"
+	StatusProviderIntervalAnnotation    = "console.operator.openshift.io/status-provider-poll-interval"
+	StatusProviderTypeAnnotation        = "console.operator.openshift.io/status-provider-type"
+	StatusProviderURLAnnotation         = "console.operator.openshift.io/status-provider-url"
 	TargetNamespace                     = "openshift-console"
+	TeardownFinalizer                   = "console.operator.openshift.io/teardown"
+	TelemetryConfigAnnotation           = "console.operator.openshift.io/telemetry-config"
+	TolerationsAnnotation               = "console.operator.openshift.io/tolerations"
+	TopologySpreadAnnotation            = "console.operator.openshift.io/topology-spread"
 	TrustedCABundleKey                  = "ca-bundle.crt"
 	TrustedCABundleMountDir             = "/etc/pki/ca-trust/extracted/pem"
 	TrustedCABundleMountFile            = "tls-ca-bundle.pem"
 	TrustedCAConfigMapName              = "trusted-ca-bundle"
+	UpdateSummaryConfigMapName          = "console-update-summary"
 	UpgradeConsoleNotification          = "cluster-upgrade"
+	UpgradeFailedConsoleNotification    = "cluster-upgrade-failed"
 	V1Alpha1PluginI18nAnnotation        = "console.openshift.io/use-i18n"
+	ValidatingWebhookConfigurationName  = "console-operator-validation"
 	VersionResourceName                 = "version"
 
 	OAuthClientName                         = OpenShiftConsoleName
+	OpenShiftConsoleCanaryName              = "console-canary"
+	OpenShiftConsoleCanaryConfigMapName     = "console-canary-config"
+	OpenShiftConsoleCandidateConfigMapName  = "console-candidate-config"
 	OpenShiftConsoleDeploymentName          = OpenShiftConsoleName
 	OpenShiftConsoleDownloadsDeploymentName = DownloadsResourceName
 	OpenShiftConsoleDownloadsPDBName        = DownloadsResourceName
 	OpenShiftConsoleDownloadsRouteName      = DownloadsResourceName
+	OpenShiftConsoleGreenName               = "console-green"
+	OpenShiftConsoleGreenConfigMapName      = "console-green-config"
 	OpenShiftConsoleNamespace               = TargetNamespace
 	OpenShiftConsolePDBName                 = OpenShiftConsoleName
 	OpenShiftConsoleRouteName               = OpenShiftConsoleName
@@ -0,0 +1,332 @@
+package backup
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	// kube
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	// openshift
+	configv1 "github.com/openshift/api/config/v1"
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	// us
+	"github.com/openshift/console-operator/pkg/api"
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+)
+
+const (
+	BackupKind    = "ConsoleCustomizationBackup"
+	BackupVersion = "v1"
+)
+
+var (
+	configMapsResource      = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	secretsResource         = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	operatorConfigResource  = operatorv1.GroupVersion.WithResource("consoles")
+	ingressConfigResource   = configv1.GroupVersion.WithResource("ingresses")
+	clusterVersionResource  = configv1.GroupVersion.WithResource("clusterversions")
+	consolePluginsResource  = consolev1.GroupVersion.WithResource("consoleplugins")
+	operatorStateAnnotation = map[string]bool{
+		// written by the operator, or one-shot triggers, they do not carry over to another cluster
+		api.BlueGreenRollbackAnnotation: true,
+		api.BlueGreenStateAnnotation:    true,
+		api.CanaryStateAnnotation:       true,
+		api.ForceSyncAnnotation:         true,
+	}
+)
+
+// Backup is the console customization state of a cluster: the spec of the operator config with
+// the plugins it enables, its annotations, the openshift-config ConfigMaps it references and the
+// custom hostnames of the console routes. Secrets are not backed up, only what they are for and
+// the certificates they hold, they are restored separately.
+type Backup struct {
+	Kind    string `json:"kind"`
+	Version string `json:"version"`
+	// ClusterVersion is the version of the cluster the backup was taken from
+	ClusterVersion  string                        `json:"clusterVersion,omitempty"`
+	CreatedAt       metav1.Time                   `json:"createdAt"`
+	Annotations     map[string]string             `json:"annotations,omitempty"`
+	Spec            operatorv1.ConsoleSpec        `json:"spec"`
+	ConfigMaps      []ConfigMap                   `json:"configMaps,omitempty"`
+	ComponentRoutes []configv1.ComponentRouteSpec `json:"componentRoutes,omitempty"`
+	Secrets         []SecretReference             `json:"secrets,omitempty"`
+}
+
+// ConfigMap is an openshift-config ConfigMap referenced by the operator config.
+type ConfigMap struct {
+	Name       string            `json:"name"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Data       map[string]string `json:"data,omitempty"`
+	BinaryData map[string][]byte `json:"binaryData,omitempty"`
+}
+
+// SecretReference is an openshift-config Secret referenced by the console customization.
+type SecretReference struct {
+	Name    string `json:"name"`
+	Purpose string `json:"purpose"`
+	// DNSNames and NotAfter describe the serving certificate of a route secret
+	DNSNames []string     `json:"dnsNames,omitempty"`
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+	Missing  bool         `json:"missing,omitempty"`
+}
+
+// Export reads the console customization state out of a cluster.
+func Export(ctx context.Context, client dynamic.Interface) (*Backup, error) {
+	operatorConfig := &operatorv1.Console{}
+	if err := get(ctx, client, operatorConfigResource, "", api.ConfigResourceName, operatorConfig); err != nil {
+		return nil, err
+	}
+	ingressConfig := &configv1.Ingress{}
+	if err := get(ctx, client, ingressConfigResource, "", api.ConfigResourceName, ingressConfig); err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	backup := FromConfigs(operatorConfig, ingressConfig)
+
+	clusterVersion := &configv1.ClusterVersion{}
+	if err := get(ctx, client, clusterVersionResource, "", api.VersionResourceName, clusterVersion); err == nil {
+		backup.ClusterVersion = clusterVersion.Status.Desired.Version
+	}
+	for _, name := range ReferencedConfigMaps(operatorConfig) {
+		configMap := &corev1.ConfigMap{}
+		err := get(ctx, client, configMapsResource, api.OpenShiftConfigNamespace, name, configMap)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		backup.ConfigMaps = append(backup.ConfigMaps, ConfigMap{Name: name, Labels: configMap.Labels, Data: configMap.Data, BinaryData: configMap.BinaryData})
+	}
+	for i, reference := range backup.Secrets {
+		secret := &corev1.Secret{}
+		err := get(ctx, client, secretsResource, api.OpenShiftConfigNamespace, reference.Name, secret)
+		if apierrors.IsNotFound(err) {
+			backup.Secrets[i].Missing = true
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		backup.Secrets[i].DNSNames, backup.Secrets[i].NotAfter = certificateMetadata(secret.Data[corev1.TLSCertKey])
+	}
+	return backup, nil
+}
+
+// FromConfigs captures the customization of operatorConfig and the custom hostnames of the console
+// routes in ingressConfig, the ConfigMaps and Secrets they reference are added by Export.
+func FromConfigs(operatorConfig *operatorv1.Console, ingressConfig *configv1.Ingress) *Backup {
+	backup := &Backup{
+		Kind:        BackupKind,
+		Version:     BackupVersion,
+		CreatedAt:   metav1.Now(),
+		Annotations: map[string]string{},
+		Spec:        *operatorConfig.Spec.DeepCopy(),
+	}
+	for key, value := range operatorConfig.Annotations {
+		if strings.HasPrefix(key, "console.operator.openshift.io/") && !operatorStateAnnotation[key] {
+			backup.Annotations[key] = value
+		}
+	}
+
+	if name := operatorConfig.Spec.Route.Secret.Name; len(name) > 0 {
+		backup.Secrets = append(backup.Secrets, SecretReference{Name: name, Purpose: "console route serving certificate"})
+	}
+	for _, componentRoute := range ingressConfig.Spec.ComponentRoutes {
+		if componentRoute.Namespace != api.OpenShiftConsoleNamespace {
+			continue
+		}
+		backup.ComponentRoutes = append(backup.ComponentRoutes, componentRoute)
+		if name := componentRoute.ServingCertKeyPairSecret.Name; len(name) > 0 {
+			backup.Secrets = append(backup.Secrets, SecretReference{Name: name, Purpose: fmt.Sprintf("%s route serving certificate", componentRoute.Name)})
+		}
+	}
+	if kind, name, err := configmapsub.CustomizationBundleReference(operatorConfig); err == nil && kind == configmapsub.CustomizationBundleSecretKind {
+		backup.Secrets = append(backup.Secrets, SecretReference{Name: name, Purpose: "customization bundle"})
+	}
+	return backup
+}
+
+// ReferencedConfigMaps returns the names of the openshift-config ConfigMaps the operator config
+// customizes the console with.
+func ReferencedConfigMaps(operatorConfig *operatorv1.Console) []string {
+	names := map[string]bool{
+		// read by its fixed name
+		api.MaintenanceWindowsConfigMapName: true,
+	}
+	if name := operatorConfig.Spec.Customization.CustomLogoFile.Name; len(name) > 0 {
+		names[name] = true
+	}
+	if kind, name, err := configmapsub.CustomizationBundleReference(operatorConfig); err == nil && kind == configmapsub.CustomizationBundleConfigMapKind {
+		names[name] = true
+	}
+	for _, annotation := range []string{api.ClusterProxyConfigAnnotation, api.ImageVerificationKeysAnnotation, api.TelemetryConfigAnnotation} {
+		if name := operatorConfig.Annotations[annotation]; len(name) > 0 {
+			names[name] = true
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// Import re-applies backup to a cluster and prints what it does to out. The management state of
+// the operator config is left as it is. The Secrets and ConsolePlugins the customization needs are
+// not created, the missing ones are reported.
+func Import(ctx context.Context, client dynamic.Interface, backup *Backup, dryRun bool, out io.Writer) error {
+	if backup.Kind != BackupKind || backup.Version != BackupVersion {
+		return fmt.Errorf("unsupported backup %s %s, expected %s %s", backup.Kind, backup.Version, BackupKind, BackupVersion)
+	}
+	suffix := ""
+	if dryRun {
+		suffix = " (dry run)"
+	}
+
+	for _, backupConfigMap := range backup.ConfigMaps {
+		configMap := &corev1.ConfigMap{}
+		err := get(ctx, client, configMapsResource, api.OpenShiftConfigNamespace, backupConfigMap.Name, configMap)
+		action := "updated"
+		switch {
+		case apierrors.IsNotFound(err):
+			action = "created"
+			configMap = &corev1.ConfigMap{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+				ObjectMeta: metav1.ObjectMeta{Name: backupConfigMap.Name, Namespace: api.OpenShiftConfigNamespace},
+			}
+		case err != nil:
+			return err
+		}
+		configMap.Labels, configMap.Data, configMap.BinaryData = backupConfigMap.Labels, backupConfigMap.Data, backupConfigMap.BinaryData
+		if !dryRun {
+			if err := apply(ctx, client, configMapsResource, api.OpenShiftConfigNamespace, configMap, action == "created"); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(out, "configmap %s/%s %s%s\n", api.OpenShiftConfigNamespace, backupConfigMap.Name, action, suffix)
+	}
+
+	if len(backup.ComponentRoutes) > 0 {
+		ingressConfig := &configv1.Ingress{}
+		if err := get(ctx, client, ingressConfigResource, "", api.ConfigResourceName, ingressConfig); err != nil {
+			return err
+		}
+		ingressConfig.Spec.ComponentRoutes = RestoreComponentRoutes(ingressConfig.Spec.ComponentRoutes, backup.ComponentRoutes)
+		if !dryRun {
+			if err := apply(ctx, client, ingressConfigResource, "", ingressConfig, false); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(out, "ingresses.config.openshift.io/%s component routes updated%s\n", api.ConfigResourceName, suffix)
+	}
+
+	operatorConfig := &operatorv1.Console{}
+	if err := get(ctx, client, operatorConfigResource, "", api.ConfigResourceName, operatorConfig); err != nil {
+		return err
+	}
+	if !dryRun {
+		if err := apply(ctx, client, operatorConfigResource, "", RestoreOperatorConfig(operatorConfig, backup), false); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(out, "consoles.operator.openshift.io/%s updated%s\n", api.ConfigResourceName, suffix)
+
+	for _, reference := range backup.Secrets {
+		err := get(ctx, client, secretsResource, api.OpenShiftConfigNamespace, reference.Name, &corev1.Secret{})
+		if apierrors.IsNotFound(err) {
+			fmt.Fprintf(out, "warning: secret %s/%s holding the %s is missing, restore it\n", api.OpenShiftConfigNamespace, reference.Name, reference.Purpose)
+		} else if err != nil {
+			return err
+		}
+	}
+	for _, plugin := range backup.Spec.Plugins {
+		err := get(ctx, client, consolePluginsResource, "", plugin, &consolev1.ConsolePlugin{})
+		if apierrors.IsNotFound(err) {
+			fmt.Fprintf(out, "warning: plugin %s is enabled but its ConsolePlugin is missing, install the operator providing it\n", plugin)
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestoreOperatorConfig returns live with the spec and annotations of backup, keeping its
+// management state.
+func RestoreOperatorConfig(live *operatorv1.Console, backup *Backup) *operatorv1.Console {
+	restored := live.DeepCopy()
+	restored.Spec = *backup.Spec.DeepCopy()
+	restored.Spec.ManagementState = live.Spec.ManagementState
+	if restored.Annotations == nil {
+		restored.Annotations = map[string]string{}
+	}
+	for key, value := range backup.Annotations {
+		restored.Annotations[key] = value
+	}
+	return restored
+}
+
+// RestoreComponentRoutes returns live with the component routes of backup, replacing the ones of
+// the same route.
+func RestoreComponentRoutes(live, backup []configv1.ComponentRouteSpec) []configv1.ComponentRouteSpec {
+	restored := []configv1.ComponentRouteSpec{}
+	backedUp := map[string]bool{}
+	for _, componentRoute := range backup {
+		backedUp[componentRoute.Namespace+"/"+componentRoute.Name] = true
+	}
+	for _, componentRoute := range live {
+		if !backedUp[componentRoute.Namespace+"/"+componentRoute.Name] {
+			restored = append(restored, componentRoute)
+		}
+	}
+	return append(restored, backup...)
+}
+
+// certificateMetadata returns the DNS names and expiry of the first certificate of a PEM bundle.
+func certificateMetadata(certPEM []byte) ([]string, *metav1.Time) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, nil
+	}
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, nil
+	}
+	notAfter := metav1.NewTime(certificate.NotAfter)
+	return certificate.DNSNames, &notAfter
+}
+
+func get(ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource, namespace, name string, into interface{}) error {
+	obj, err := client.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, into)
+}
+
+func apply(ctx context.Context, client dynamic.Interface, gvr schema.GroupVersionResource, namespace string, obj interface{}, create bool) error {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	if create {
+		_, err = client.Resource(gvr).Namespace(namespace).Create(ctx, &unstructured.Unstructured{Object: content}, metav1.CreateOptions{})
+		return err
+	}
+	_, err = client.Resource(gvr).Namespace(namespace).Update(ctx, &unstructured.Unstructured{Object: content}, metav1.UpdateOptions{})
+	return err
+}
@@ -0,0 +1,165 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestReferencedConfigMaps(t *testing.T) {
+	tests := []struct {
+		name           string
+		operatorConfig *operatorv1.Console
+		want           []string
+	}{
+		{
+			name:           "Test default config",
+			operatorConfig: &operatorv1.Console{},
+			want:           []string{api.MaintenanceWindowsConfigMapName},
+		},
+		{
+			name: "Test custom logo and annotations",
+			operatorConfig: &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					api.TelemetryConfigAnnotation:       "telemetry",
+					api.ImageVerificationKeysAnnotation: "keys",
+				}},
+				Spec: operatorv1.ConsoleSpec{Customization: operatorv1.ConsoleCustomization{
+					CustomLogoFile: configv1.ConfigMapFileReference{Name: "logo", Key: "logo.svg"},
+				}},
+			},
+			want: []string{api.MaintenanceWindowsConfigMapName, "keys", "logo", "telemetry"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(ReferencedConfigMaps(tt.operatorConfig), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestFromConfigs(t *testing.T) {
+	operatorConfig := &operatorv1.Console{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			api.TelemetryConfigAnnotation: "telemetry",
+			api.BlueGreenStateAnnotation:  "green",
+			"other.openshift.io/key":      "value",
+		}},
+		Spec: operatorv1.ConsoleSpec{
+			Plugins: []string{"monitoring"},
+			Route:   operatorv1.ConsoleConfigRoute{Hostname: "console.example.com", Secret: configv1.SecretNameReference{Name: "console-tls"}},
+		},
+	}
+	ingressConfig := &configv1.Ingress{Spec: configv1.IngressSpec{ComponentRoutes: []configv1.ComponentRouteSpec{
+		{Namespace: api.OpenShiftConsoleNamespace, Name: api.OpenShiftConsoleDownloadsRouteName, Hostname: "downloads.example.com", ServingCertKeyPairSecret: configv1.SecretNameReference{Name: "downloads-tls"}},
+		{Namespace: "openshift-authentication", Name: "oauth-openshift", Hostname: "oauth.example.com"},
+	}}}
+
+	backup := FromConfigs(operatorConfig, ingressConfig)
+	backup.CreatedAt = metav1.Time{}
+	want := &Backup{
+		Kind:        BackupKind,
+		Version:     BackupVersion,
+		Annotations: map[string]string{api.TelemetryConfigAnnotation: "telemetry"},
+		Spec:        operatorConfig.Spec,
+		ComponentRoutes: []configv1.ComponentRouteSpec{
+			ingressConfig.Spec.ComponentRoutes[0],
+		},
+		Secrets: []SecretReference{
+			{Name: "console-tls", Purpose: "console route serving certificate"},
+			{Name: "downloads-tls", Purpose: "downloads route serving certificate"},
+		},
+	}
+	if diff := deep.Equal(backup, want); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestRestoreOperatorConfig(t *testing.T) {
+	backup := &Backup{
+		Annotations: map[string]string{api.TelemetryConfigAnnotation: "telemetry"},
+		Spec: operatorv1.ConsoleSpec{
+			OperatorSpec: operatorv1.OperatorSpec{ManagementState: operatorv1.Managed},
+			Plugins:      []string{"monitoring"},
+		},
+	}
+	tests := []struct {
+		name string
+		live *operatorv1.Console
+		want *operatorv1.Console
+	}{
+		{
+			name: "Test management state is kept",
+			live: &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Name: api.ConfigResourceName, Annotations: map[string]string{api.BlueGreenStateAnnotation: "green"}},
+				Spec:       operatorv1.ConsoleSpec{OperatorSpec: operatorv1.OperatorSpec{ManagementState: operatorv1.Unmanaged}},
+			},
+			want: &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Name: api.ConfigResourceName, Annotations: map[string]string{
+					api.BlueGreenStateAnnotation:  "green",
+					api.TelemetryConfigAnnotation: "telemetry",
+				}},
+				Spec: operatorv1.ConsoleSpec{
+					OperatorSpec: operatorv1.OperatorSpec{ManagementState: operatorv1.Unmanaged},
+					Plugins:      []string{"monitoring"},
+				},
+			},
+		},
+		{
+			name: "Test live config without annotations",
+			live: &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Name: api.ConfigResourceName}},
+			want: &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Name: api.ConfigResourceName, Annotations: map[string]string{api.TelemetryConfigAnnotation: "telemetry"}},
+				Spec:       operatorv1.ConsoleSpec{Plugins: []string{"monitoring"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(RestoreOperatorConfig(tt.live, backup), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestRestoreComponentRoutes(t *testing.T) {
+	oauth := configv1.ComponentRouteSpec{Namespace: "openshift-authentication", Name: "oauth-openshift", Hostname: "oauth.example.com"}
+	oldConsole := configv1.ComponentRouteSpec{Namespace: api.OpenShiftConsoleNamespace, Name: api.OpenShiftConsoleRouteName, Hostname: "old.example.com"}
+	newConsole := configv1.ComponentRouteSpec{Namespace: api.OpenShiftConsoleNamespace, Name: api.OpenShiftConsoleRouteName, Hostname: "console.example.com"}
+
+	tests := []struct {
+		name   string
+		live   []configv1.ComponentRouteSpec
+		backup []configv1.ComponentRouteSpec
+		want   []configv1.ComponentRouteSpec
+	}{
+		{
+			name:   "Test route added",
+			live:   []configv1.ComponentRouteSpec{oauth},
+			backup: []configv1.ComponentRouteSpec{newConsole},
+			want:   []configv1.ComponentRouteSpec{oauth, newConsole},
+		},
+		{
+			name:   "Test route replaced",
+			live:   []configv1.ComponentRouteSpec{oldConsole, oauth},
+			backup: []configv1.ComponentRouteSpec{newConsole},
+			want:   []configv1.ComponentRouteSpec{oauth, newConsole},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(RestoreComponentRoutes(tt.live, tt.backup), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	kubeconfig string
+	output     string
+	filename   string
+	dryRun     bool
+)
+
+func NewBackup() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Export and import the console customization of a cluster",
+		Long: `Capture the console customization of a cluster in a single artifact: the spec and annotations
+of the operator config, the plugins it enables, the openshift-config ConfigMaps it references, the
+custom hostnames of the console routes and the metadata of their serving certificates. Import the
+artifact to re-apply the customization on a rebuilt cluster. Secrets are never exported, the ones
+the customization needs are listed and have to be restored separately.`,
+	}
+	cmd.PersistentFlags().StringVar(&kubeconfig, "kubeconfig", os.Getenv("KUBECONFIG"), "Path to the kubeconfig of the cluster, the in-cluster config is used when empty.")
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the console customization of a cluster",
+		Run: func(command *cobra.Command, args []string) {
+			if err := export(context.Background()); err != nil {
+				klog.Fatalf("Error exporting console customization: %v", err)
+			}
+		},
+	}
+	exportCmd.Flags().StringVarP(&output, "output", "o", "", "Path to write the backup to, stdout when empty.")
+
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Re-apply an exported console customization to a cluster",
+		Run: func(command *cobra.Command, args []string) {
+			if err := importBackup(context.Background(), os.Stdout); err != nil {
+				klog.Fatalf("Error importing console customization: %v", err)
+			}
+		},
+	}
+	importCmd.Flags().StringVarP(&filename, "filename", "f", "", "Path to the backup to import.")
+	importCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print what would be applied without changing the cluster.")
+	importCmd.MarkFlagRequired("filename")
+
+	cmd.AddCommand(exportCmd, importCmd)
+	return cmd
+}
+
+func newClient() (dynamic.Interface, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	return dynamic.NewForConfig(config)
+}
+
+func export(ctx context.Context) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	backup, err := Export(ctx, client)
+	if err != nil {
+		return err
+	}
+	content, err := yaml.Marshal(backup)
+	if err != nil {
+		return err
+	}
+	if len(output) == 0 {
+		_, err = os.Stdout.Write(content)
+		return err
+	}
+	return os.WriteFile(output, content, 0600)
+}
+
+func importBackup(ctx context.Context, out io.Writer) error {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	backup := &Backup{}
+	if err := yaml.UnmarshalStrict(content, backup); err != nil {
+		return fmt.Errorf("failed to parse backup %s: %w", filename, err)
+	}
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	return Import(ctx, client, backup, dryRun, out)
+}
@@ -0,0 +1,315 @@
+package check
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	// 3rd party
+	"github.com/blang/semver"
+
+	// kube
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer/json"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	// openshift
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	// us
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/cmd/crdconversionwebhook/validator"
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+const customizationBundleFile = "customization.yaml"
+
+var (
+	scheme = runtime.NewScheme()
+	// strict decoding reports unknown and duplicate fields, the API server would drop them silently
+	decoder = json.NewSerializerWithOptions(json.DefaultMetaFactory, scheme, scheme, json.SerializerOptions{Yaml: true, Strict: true})
+)
+
+func init() {
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		corev1.AddToScheme,
+		consolev1.AddToScheme,
+		operatorv1.AddToScheme,
+	} {
+		if err := addToScheme(scheme); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Manifest is a resource decoded from a manifest file.
+type Manifest struct {
+	File   string
+	Object runtime.Object
+}
+
+func (m Manifest) resource() string {
+	accessor, err := meta.Accessor(m.Object)
+	if err != nil {
+		return m.Object.GetObjectKind().GroupVersionKind().Kind
+	}
+	name := accessor.GetName()
+	if namespace := accessor.GetNamespace(); len(namespace) > 0 {
+		name = namespace + "/" + name
+	}
+	return fmt.Sprintf("%s %s", m.Object.GetObjectKind().GroupVersionKind().Kind, name)
+}
+
+// Problem is a validation error of a manifest, Resource is empty when the file itself is invalid.
+type Problem struct {
+	File     string
+	Resource string
+	Message  string
+}
+
+func (p Problem) String() string {
+	if len(p.Resource) == 0 {
+		return fmt.Sprintf("%s: %s", p.File, p.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", p.File, p.Resource, p.Message)
+}
+
+// ReadManifests decodes every YAML or JSON document of the files in paths, directories are
+// walked recursively. Documents of kinds that are not console customization resources are
+// skipped, documents that cannot be decoded are reported as problems.
+func ReadManifests(paths []string) ([]Manifest, []Problem, error) {
+	manifests := []Manifest{}
+	problems := []Problem{}
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() || (file != path && !isManifest(file)) {
+				return nil
+			}
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			fileManifests, fileProblems := decodeManifests(file, content)
+			manifests = append(manifests, fileManifests...)
+			problems = append(problems, fileProblems...)
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return manifests, problems, nil
+}
+
+func isManifest(name string) bool {
+	switch filepath.Ext(name) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+func decodeManifests(file string, content []byte) ([]Manifest, []Problem) {
+	manifests := []Manifest{}
+	problems := []Problem{}
+	documents := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), 4096)
+	for {
+		raw := runtime.RawExtension{}
+		if err := documents.Decode(&raw); err != nil {
+			if !errors.Is(err, io.EOF) {
+				problems = append(problems, Problem{File: file, Message: err.Error()})
+			}
+			return manifests, problems
+		}
+		if len(bytes.TrimSpace(raw.Raw)) == 0 || string(bytes.TrimSpace(raw.Raw)) == "null" {
+			continue
+		}
+		obj, _, err := decoder.Decode(raw.Raw, nil, nil)
+		switch {
+		case runtime.IsNotRegisteredError(err) || runtime.IsMissingKind(err):
+			continue
+		case runtime.IsStrictDecodingError(err):
+			manifest := Manifest{File: file, Object: obj}
+			problems = append(problems, Problem{File: file, Resource: manifest.resource(), Message: err.Error()})
+		case err != nil:
+			problems = append(problems, Problem{File: file, Message: err.Error()})
+			continue
+		}
+		manifests = append(manifests, Manifest{File: file, Object: obj})
+	}
+}
+
+// Check validates the console customization resources among manifests the same way the
+// operator does when it reconciles them: the operator config along with the openshift-config
+// ConfigMaps and Secrets it references, ConsolePlugins and customization bundles. The referenced
+// resources that are not among manifests are assumed to be valid.
+func Check(manifests []Manifest) []Problem {
+	problems := []Problem{}
+	report := func(manifest Manifest, err error) {
+		if err != nil {
+			problems = append(problems, Problem{File: manifest.File, Resource: manifest.resource(), Message: err.Error()})
+		}
+	}
+
+	configMaps := map[string]Manifest{}
+	secrets := map[string]Manifest{}
+	for _, manifest := range manifests {
+		switch o := manifest.Object.(type) {
+		case *corev1.ConfigMap:
+			configMaps[o.Namespace+"/"+o.Name] = manifest
+		case *corev1.Secret:
+			secrets[o.Namespace+"/"+o.Name] = manifest
+		}
+	}
+	// the bundles referenced by an operator config are checked along with it
+	checkedBundles := map[string]bool{}
+
+	for _, manifest := range manifests {
+		switch o := manifest.Object.(type) {
+		case *operatorv1.Console:
+			for _, err := range validator.ValidateConsole(o) {
+				report(manifest, err)
+			}
+			_, err := utilsub.GetGroupInactivityTimeouts(o)
+			report(manifest, err)
+			contentSecurityPolicy, err := utilsub.GetContentSecurityPolicy(o)
+			report(manifest, err)
+			_, err = utilsub.GetStatusProviderConfig(o, contentSecurityPolicy)
+			report(manifest, err)
+
+			kind, name, err := configmapsub.CustomizationBundleReference(o)
+			report(manifest, err)
+			if len(name) > 0 {
+				key := api.OpenShiftConfigNamespace + "/" + name
+				bundles := configMaps
+				if kind == configmapsub.CustomizationBundleSecretKind {
+					bundles = secrets
+				}
+				if bundle, ok := bundles[key]; ok {
+					report(bundle, checkCustomizationBundle(bundle))
+					checkedBundles[bundle.File+"/"+bundle.resource()] = true
+				}
+			}
+			if logo := o.Spec.Customization.CustomLogoFile; len(logo.Name) > 0 {
+				if configMap, ok := configMaps[api.OpenShiftConfigNamespace+"/"+logo.Name]; ok {
+					report(configMap, checkCustomLogo(configMap.Object.(*corev1.ConfigMap), logo.Key))
+				}
+			}
+			if name, ok := o.Annotations[api.TelemetryConfigAnnotation]; ok {
+				if configMap, ok := configMaps[api.OpenShiftConfigNamespace+"/"+name]; ok {
+					_, err := configmapsub.ValidateTelemetryConfig(configMap.Object.(*corev1.ConfigMap).Data)
+					report(configMap, err)
+				}
+			}
+			if name, ok := o.Annotations[api.ClusterProxyConfigAnnotation]; ok {
+				if configMap, ok := configMaps[api.OpenShiftConfigNamespace+"/"+name]; ok {
+					_, err := configmapsub.ValidateClusterProxyConfig(configMap.Object.(*corev1.ConfigMap).Data)
+					report(configMap, err)
+				}
+			}
+		case *consolev1.ConsolePlugin:
+			for _, err := range checkConsolePlugin(o) {
+				report(manifest, err)
+			}
+		}
+	}
+
+	// bundles not referenced by any operator config, such as one about to be referenced
+	for _, manifest := range manifests {
+		if checkedBundles[manifest.File+"/"+manifest.resource()] || !isCustomizationBundle(manifest.Object) {
+			continue
+		}
+		report(manifest, checkCustomizationBundle(manifest))
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].File < problems[j].File })
+	return problems
+}
+
+func isCustomizationBundle(obj runtime.Object) bool {
+	switch o := obj.(type) {
+	case *corev1.ConfigMap:
+		_, ok := o.Data[customizationBundleFile]
+		return ok
+	case *corev1.Secret:
+		_, ok := o.Data[customizationBundleFile]
+		if !ok {
+			_, ok = o.StringData[customizationBundleFile]
+		}
+		return ok
+	}
+	return false
+}
+
+func checkCustomizationBundle(manifest Manifest) error {
+	data := map[string][]byte{}
+	switch o := manifest.Object.(type) {
+	case *corev1.ConfigMap:
+		for key, value := range o.Data {
+			data[key] = []byte(value)
+		}
+		for key, value := range o.BinaryData {
+			data[key] = value
+		}
+		_, err := configmapsub.ParseCustomizationBundle(configmapsub.CustomizationBundleConfigMapKind, o.Name, data)
+		return err
+	case *corev1.Secret:
+		for key, value := range o.Data {
+			data[key] = value
+		}
+		for key, value := range o.StringData {
+			data[key] = []byte(value)
+		}
+		_, err := configmapsub.ParseCustomizationBundle(configmapsub.CustomizationBundleSecretKind, o.Name, data)
+		return err
+	}
+	return nil
+}
+
+// checkCustomLogo mirrors the check of the operator before it mounts the logo.
+func checkCustomLogo(configMap *corev1.ConfigMap, key string) error {
+	if _, ok := configMap.BinaryData[key]; ok {
+		return nil
+	}
+	if _, ok := configMap.Data[key]; ok {
+		return nil
+	}
+	return fmt.Errorf("custom logo file exists but no image provided under key %q", key)
+}
+
+// checkConsolePlugin reports what the operator ignores when it adds the plugin to console-config,
+// along with a console-version-range the pre-upgrade checks cannot parse.
+func checkConsolePlugin(plugin *consolev1.ConsolePlugin) []error {
+	errs := []error{}
+	if policy, ok := plugin.Annotations[api.PluginCSPAnnotation]; ok {
+		if _, err := utilsub.ParseContentSecurityPolicy(policy); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", api.PluginCSPAnnotation, err))
+		}
+	}
+	if versionRange, ok := plugin.Annotations[api.PluginConsoleVersionAnnotation]; ok {
+		if _, err := semver.ParseRange(versionRange); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid version range %q", api.PluginConsoleVersionAnnotation, versionRange))
+		}
+	}
+	if plugin.Spec.Backend.Type != consolev1.Service {
+		errs = append(errs, fmt.Errorf("spec.backend.type: unsupported backend type %q, only %q is supported", plugin.Spec.Backend.Type, consolev1.Service))
+	}
+	for i, proxy := range plugin.Spec.Proxy {
+		if proxy.Endpoint.Type != consolev1.ProxyTypeService {
+			errs = append(errs, fmt.Errorf("spec.proxy[%d].endpoint.type: unsupported proxy endpoint type %q, only %q is supported", i, proxy.Endpoint.Type, consolev1.ProxyTypeService))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,168 @@
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+const (
+	validOperatorConfig = `apiVersion: operator.openshift.io/v1
+kind: Console
+metadata:
+  name: cluster
+  annotations:
+    console.operator.openshift.io/customization-bundle: configmap/branding
+spec:
+  managementState: Managed
+  customization:
+    customLogoFile:
+      name: custom-logo
+      key: logo.svg
+`
+	brandingBundle = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: branding
+  namespace: openshift-config
+data:
+  customization.yaml: |
+    brand: okd
+`
+	customLogo = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: custom-logo
+  namespace: openshift-config
+data:
+  logo.png: ""
+`
+	plugin = `apiVersion: console.openshift.io/v1
+kind: ConsolePlugin
+metadata:
+  name: acm
+  annotations:
+    console.openshift.io/console-version-range: ">=4.14.0 <4.17.0"
+spec:
+  displayName: ACM
+  backend:
+    type: Service
+    service:
+      name: acm
+      namespace: acm
+      port: 9443
+`
+	invalidPlugin = `apiVersion: console.openshift.io/v1
+kind: ConsolePlugin
+metadata:
+  name: acm
+  annotations:
+    console.openshift.io/content-security-policy: "script-src *"
+    console.openshift.io/console-version-range: "4.14"
+spec:
+  displayName: ACM
+  backend:
+    type: Service
+    service:
+      name: acm
+      namespace: acm
+      port: 9443
+`
+	consoleLinkWithTypo = `apiVersion: console.openshift.io/v1
+kind: ConsoleLink
+metadata:
+  name: docs
+spec:
+  href: https://docs.example.com
+  text: Docs
+  location: HelpMenu
+  hreff: https://docs.example.com
+`
+	unrelated = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: unrelated
+`
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			name: "Test valid customization",
+			files: map[string]string{
+				"console.yaml": validOperatorConfig + "---\n" + brandingBundle,
+				"logo.yaml":    customLogo + "---\n" + unrelated,
+				"plugin.json":  plugin,
+			},
+			want: []string{
+				`logo.yaml: ConfigMap openshift-config/custom-logo: custom logo file exists but no image provided under key "logo.svg"`,
+			},
+		},
+		{
+			name: "Test invalid plugin",
+			files: map[string]string{
+				"plugin.yaml": invalidPlugin,
+			},
+			want: []string{
+				`plugin.yaml: ConsolePlugin acm: console.openshift.io/content-security-policy: invalid content security policy: script-src: source "*" allows any host`,
+				`plugin.yaml: ConsolePlugin acm: console.openshift.io/console-version-range: invalid version range "4.14"`,
+			},
+		},
+		{
+			name: "Test unknown field",
+			files: map[string]string{
+				"link.yaml": consoleLinkWithTypo,
+			},
+			want: []string{
+				`link.yaml: ConsoleLink docs: strict decoding error: unknown field "spec.hreff"`,
+			},
+		},
+		{
+			name: "Test invalid unreferenced bundle",
+			files: map[string]string{
+				"bundle.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: other-branding
+  namespace: openshift-config
+data:
+  customization.yaml: |
+    brand: acme
+`,
+			},
+			want: []string{
+				`bundle.yaml: ConfigMap openshift-config/other-branding: invalid customization bundle configmap/other-branding: customization.yaml: brand "acme" is not one of Azure, Dedicated, OCP, OKD, Online, OpenShift, ROSA, azure, dedicated, ocp, okd, online, openshift`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			manifests, problems, err := ReadManifests([]string{dir})
+			if err != nil {
+				t.Fatal(err)
+			}
+			problems = append(problems, Check(manifests)...)
+			got := []string{}
+			for _, problem := range problems {
+				rel, _ := filepath.Rel(dir, problem.File)
+				problem.File = rel
+				got = append(got, problem.String())
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
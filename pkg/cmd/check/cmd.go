@@ -0,0 +1,53 @@
+package check
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+var (
+	filenames []string
+)
+
+func NewCheck() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Validate console customization manifests offline",
+		Long: `Validate the console customization resources in the manifest files given with --filename,
+without a cluster, the same way the operator does when it reconciles them: the operator config
+along with the openshift-config ConfigMaps and Secrets it references, ConsolePlugins and
+customization bundles. ConsoleLinks, ConsoleQuickStarts and the other console resources are
+checked for unknown fields. Every problem is printed and the command fails if there is any,
+so it can gate the changes of a GitOps repository.`,
+		Run: func(command *cobra.Command, args []string) {
+			problems, err := check(os.Stdout)
+			if err != nil {
+				klog.Fatalf("Error checking console manifests: %v", err)
+			}
+			if problems > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().StringSliceVarP(&filenames, "filename", "f", nil, "Manifest files or directories to check, directories are walked recursively.")
+	cmd.MarkFlagRequired("filename")
+
+	return cmd
+}
+
+func check(out io.Writer) (int, error) {
+	manifests, problems, err := ReadManifests(filenames)
+	if err != nil {
+		return 0, err
+	}
+	problems = append(problems, Check(manifests)...)
+	for _, problem := range problems {
+		fmt.Fprintln(out, problem)
+	}
+	fmt.Fprintf(out, "checked %d resources, found %d problems\n", len(manifests), len(problems))
+	return len(problems), nil
+}
@@ -12,6 +12,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	converter "github.com/openshift/console-operator/pkg/cmd/crdconversionwebhook/converter"
+	"github.com/openshift/console-operator/pkg/cmd/crdconversionwebhook/validator"
 )
 
 var (
@@ -23,7 +24,7 @@ var (
 func NewConverter() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "crdconvert",
-		Short: "Start server for CRD conversion",
+		Short: "Start server for CRD conversion and console validation",
 		Run: func(command *cobra.Command, args []string) {
 			startServer()
 		},
@@ -77,6 +78,7 @@ func startServer() {
 
 	// Setup handlers and server
 	http.HandleFunc("/crdconvert", converter.ServeConsolePluginConvert)
+	http.HandleFunc("/validate-console", validator.ServeConsoleValidate)
 	http.HandleFunc("/readyz", func(w http.ResponseWriter, req *http.Request) { w.Write([]byte("ok")) })
 	server := &http.Server{}
 
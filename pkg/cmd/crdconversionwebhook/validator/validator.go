@@ -0,0 +1,175 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+
+	"k8s.io/klog/v2"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+// builtInPerspectives are the perspectives shipped with the console, plugins can add more
+var builtInPerspectives = sets.NewString("admin", "dev")
+
+// ValidateConsole checks the customization and the route of the operator config for values
+// that are otherwise only caught deep in reconciliation. Everything the CRD schema already
+// enforces is left to the API server.
+func ValidateConsole(console *operatorv1.Console) field.ErrorList {
+	specPath := field.NewPath("spec")
+	errs := field.ErrorList{}
+	errs = append(errs, validateCustomLogoFile(console.Spec.Customization.CustomLogoFile, specPath.Child("customization", "customLogoFile"))...)
+	errs = append(errs, validatePerspectives(console.Spec.Customization.Perspectives, len(console.Spec.Plugins) > 0, specPath.Child("customization", "perspectives"))...)
+	errs = append(errs, validateRoute(console.Spec.Route, specPath.Child("route"))...)
+	return errs
+}
+
+func validateCustomLogoFile(logo configv1.ConfigMapFileReference, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if len(logo.Name) == 0 && len(logo.Key) == 0 {
+		return errs
+	}
+	if len(logo.Name) == 0 {
+		return append(errs, field.Required(fldPath.Child("name"), "the name of the ConfigMap holding the logo in openshift-config is required when key is set"))
+	}
+	if len(logo.Key) == 0 {
+		return append(errs, field.Required(fldPath.Child("key"), "the ConfigMap key of the logo is required when name is set"))
+	}
+	for _, msg := range validation.IsDNS1123Subdomain(logo.Name) {
+		errs = append(errs, field.Invalid(fldPath.Child("name"), logo.Name, msg))
+	}
+	for _, msg := range validation.IsConfigMapKey(logo.Key) {
+		errs = append(errs, field.Invalid(fldPath.Child("key"), logo.Key, msg))
+	}
+	if len(filepath.Ext(logo.Key)) == 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("key"), logo.Key, "must have a file extension, the console serves the logo with the matching MIME type"))
+	}
+	return errs
+}
+
+// validatePerspectives rejects the ids of unknown perspectives, unless plugins, which can
+// contribute perspectives, are enabled.
+func validatePerspectives(perspectives []operatorv1.Perspective, pluginsEnabled bool, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if pluginsEnabled {
+		return errs
+	}
+	for i, perspective := range perspectives {
+		if !builtInPerspectives.Has(perspective.ID) {
+			errs = append(errs, field.NotSupported(fldPath.Index(i).Child("id"), perspective.ID, builtInPerspectives.List()))
+		}
+	}
+	return errs
+}
+
+func validateRoute(route operatorv1.ConsoleConfigRoute, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if len(route.Hostname) == 0 {
+		if len(route.Secret.Name) != 0 {
+			errs = append(errs, field.Required(fldPath.Child("hostname"), "the serving certificate secret is only used for a custom hostname"))
+		}
+		return errs
+	}
+	for _, msg := range validation.IsDNS1123Subdomain(route.Hostname) {
+		errs = append(errs, field.Invalid(fldPath.Child("hostname"), route.Hostname, msg))
+	}
+	if len(route.Secret.Name) != 0 {
+		for _, msg := range validation.IsDNS1123Subdomain(route.Secret.Name) {
+			errs = append(errs, field.Invalid(fldPath.Child("secret", "name"), route.Secret.Name, msg))
+		}
+	}
+	return errs
+}
+
+// newErrors drops the errors old already has, so that an update is only rejected for what it
+// changes and objects that were admitted before the webhook existed stay editable.
+func newErrors(errs, old field.ErrorList) field.ErrorList {
+	existing := sets.NewString()
+	for _, err := range old {
+		existing.Insert(err.Error())
+	}
+	filtered := field.ErrorList{}
+	for _, err := range errs {
+		if !existing.Has(err.Error()) {
+			filtered = append(filtered, err)
+		}
+	}
+	return filtered
+}
+
+func review(request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	response := &admissionv1.AdmissionResponse{UID: request.UID, Allowed: true}
+
+	console := &operatorv1.Console{}
+	if err := json.Unmarshal(request.Object.Raw, console); err != nil {
+		response.Allowed = false
+		response.Result = &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusBadRequest,
+			Reason:  metav1.StatusReasonBadRequest,
+			Message: fmt.Sprintf("failed to decode console: %v", err),
+		}
+		return response
+	}
+	errs := ValidateConsole(console)
+	if request.Operation == admissionv1.Update && len(request.OldObject.Raw) != 0 {
+		old := &operatorv1.Console{}
+		if err := json.Unmarshal(request.OldObject.Raw, old); err == nil {
+			errs = newErrors(errs, ValidateConsole(old))
+		}
+	}
+	if len(errs) == 0 {
+		return response
+	}
+
+	response.Allowed = false
+	response.Result = &metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusUnprocessableEntity,
+		Reason:  metav1.StatusReasonInvalid,
+		Message: fmt.Sprintf("consoles.operator.openshift.io %q is invalid: %v", console.Name, errs.ToAggregate()),
+	}
+	return response
+}
+
+// ServeConsoleValidate serves the AdmissionReviews of the consoles.operator.openshift.io
+// validating webhook.
+func ServeConsoleValidate(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		klog.Error(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	admissionReview := &admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(body, admissionReview); err != nil || admissionReview.Request == nil {
+		msg := fmt.Sprintf("failed to deserialize AdmissionReview (%v): %v", string(body), err)
+		klog.Error(msg)
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	admissionReview.Response = review(admissionReview.Request)
+	admissionReview.Request = nil
+	if !admissionReview.Response.Allowed {
+		klog.V(4).Infof("Rejecting console: %s", admissionReview.Response.Result.Message)
+	}
+	responseBody, err := json.Marshal(admissionReview)
+	if err != nil {
+		klog.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(responseBody)
+}
@@ -0,0 +1,175 @@
+package validator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-test/deep"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+func TestValidateConsole(t *testing.T) {
+	tests := []struct {
+		name string
+		spec operatorv1.ConsoleSpec
+		want []string
+	}{
+		{
+			name: "Test empty spec",
+			want: []string{},
+		},
+		{
+			name: "Test valid customization",
+			spec: operatorv1.ConsoleSpec{
+				Customization: operatorv1.ConsoleCustomization{
+					CustomLogoFile: configv1.ConfigMapFileReference{Name: "custom-logo", Key: "logo.svg"},
+					Perspectives:   []operatorv1.Perspective{{ID: "dev"}, {ID: "admin"}},
+				},
+				Route: operatorv1.ConsoleConfigRoute{Hostname: "console.example.com", Secret: configv1.SecretNameReference{Name: "console-tls"}},
+			},
+			want: []string{},
+		},
+		{
+			name: "Test custom logo key without name",
+			spec: operatorv1.ConsoleSpec{
+				Customization: operatorv1.ConsoleCustomization{
+					CustomLogoFile: configv1.ConfigMapFileReference{Key: "logo.svg"},
+				},
+			},
+			want: []string{
+				"spec.customization.customLogoFile.name: Required value: the name of the ConfigMap holding the logo in openshift-config is required when key is set",
+			},
+		},
+		{
+			name: "Test custom logo invalid reference",
+			spec: operatorv1.ConsoleSpec{
+				Customization: operatorv1.ConsoleCustomization{
+					CustomLogoFile: configv1.ConfigMapFileReference{Name: "Custom_Logo", Key: "logo"},
+				},
+			},
+			want: []string{
+				`spec.customization.customLogoFile.name: Invalid value: "Custom_Logo": a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')`,
+				`spec.customization.customLogoFile.key: Invalid value: "logo": must have a file extension, the console serves the logo with the matching MIME type`,
+			},
+		},
+		{
+			name: "Test unknown perspective",
+			spec: operatorv1.ConsoleSpec{
+				Customization: operatorv1.ConsoleCustomization{
+					Perspectives: []operatorv1.Perspective{{ID: "dev"}, {ID: "developer"}},
+				},
+			},
+			want: []string{
+				`spec.customization.perspectives[1].id: Unsupported value: "developer": supported values: "admin", "dev"`,
+			},
+		},
+		{
+			name: "Test unknown perspective with plugins enabled",
+			spec: operatorv1.ConsoleSpec{
+				Customization: operatorv1.ConsoleCustomization{
+					Perspectives: []operatorv1.Perspective{{ID: "acm"}},
+				},
+				Plugins: []string{"acm"},
+			},
+			want: []string{},
+		},
+		{
+			name: "Test malformed route hostname",
+			spec: operatorv1.ConsoleSpec{
+				Route: operatorv1.ConsoleConfigRoute{Hostname: "https://console.example.com"},
+			},
+			want: []string{
+				`spec.route.hostname: Invalid value: "https://console.example.com": a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')`,
+			},
+		},
+		{
+			name: "Test route secret without hostname",
+			spec: operatorv1.ConsoleSpec{
+				Route: operatorv1.ConsoleConfigRoute{Secret: configv1.SecretNameReference{Name: "console-tls"}},
+			},
+			want: []string{
+				"spec.route.hostname: Required value: the serving certificate secret is only used for a custom hostname",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, err := range ValidateConsole(&operatorv1.Console{Spec: tt.spec}) {
+				got = append(got, err.Error())
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestReview(t *testing.T) {
+	invalid := &operatorv1.Console{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: operatorv1.ConsoleSpec{
+			Customization: operatorv1.ConsoleCustomization{Perspectives: []operatorv1.Perspective{{ID: "developer"}}},
+		},
+	}
+	updated := invalid.DeepCopy()
+	updated.Spec.Customization.CustomProductName = "Console"
+	updatedInvalid := updated.DeepCopy()
+	updatedInvalid.Spec.Route.Hostname = "console_example"
+
+	tests := []struct {
+		name        string
+		operation   admissionv1.Operation
+		object      *operatorv1.Console
+		oldObject   *operatorv1.Console
+		wantAllowed bool
+	}{
+		{
+			name:      "Test invalid console is rejected on create",
+			operation: admissionv1.Create,
+			object:    invalid,
+		},
+		{
+			name:        "Test update keeping an error the console already had is allowed",
+			operation:   admissionv1.Update,
+			object:      updated,
+			oldObject:   invalid,
+			wantAllowed: true,
+		},
+		{
+			name:      "Test update adding an error is rejected",
+			operation: admissionv1.Update,
+			object:    updatedInvalid,
+			oldObject: invalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &admissionv1.AdmissionRequest{UID: "uid", Operation: tt.operation, Object: rawExtension(t, tt.object)}
+			if tt.oldObject != nil {
+				request.OldObject = rawExtension(t, tt.oldObject)
+			}
+			response := review(request)
+			if diff := deep.Equal(response.Allowed, tt.wantAllowed); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(response.UID, request.UID); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func rawExtension(t *testing.T, console *operatorv1.Console) runtime.RawExtension {
+	raw, err := json.Marshal(console)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return runtime.RawExtension{Raw: raw}
+}
@@ -0,0 +1,103 @@
+package diff
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/console-operator/pkg/cmd/render"
+)
+
+var (
+	kubeconfig  string
+	diffOnly    bool
+	changedOnly bool
+)
+
+func NewDiff() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare the console resources the operator wants with the live ones",
+		Long: `Render the console resources from the configs of a live cluster, the same way the operator
+does, and print per resource the desired state, the live state and the fields the operator would
+update. Run it against a cluster whose console keeps being rolled out or rewritten to find out
+which field the operator and another actor disagree on.`,
+		Run: func(command *cobra.Command, args []string) {
+			if err := diff(context.Background(), os.Stdout); err != nil {
+				klog.Fatalf("Error comparing console resources: %v", err)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", os.Getenv("KUBECONFIG"), "Path to the kubeconfig of the cluster, the in-cluster config is used when empty.")
+	cmd.Flags().BoolVar(&diffOnly, "diff-only", false, "Only print the field differences, not the desired and live states.")
+	cmd.Flags().BoolVar(&changedOnly, "changed-only", false, "Skip the resources that match their desired state.")
+
+	return cmd
+}
+
+func diff(ctx context.Context, out io.Writer) error {
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return err
+	}
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	inputs, err := LiveInputs(ctx, client)
+	if err != nil {
+		return err
+	}
+	desired, err := render.Render(inputs)
+	if err != nil {
+		return err
+	}
+	diffs, err := Diff(ctx, client, desired)
+	if err != nil {
+		return err
+	}
+	for _, resourceDiff := range diffs {
+		if changedOnly && resourceDiff.Live != nil && len(resourceDiff.Fields) == 0 {
+			continue
+		}
+		if err := printDiff(out, resourceDiff); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printDiff(out io.Writer, resourceDiff ResourceDiff) error {
+	fmt.Fprintf(out, "=== %s %s/%s\n", resourceDiff.Kind, resourceDiff.Namespace, resourceDiff.Name)
+	if !diffOnly {
+		desired, err := yaml.Marshal(resourceDiff.Desired)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "--- desired\n%s", desired)
+		if resourceDiff.Live == nil {
+			fmt.Fprintln(out, "--- live\n<not found>")
+		} else {
+			live, err := yaml.Marshal(resourceDiff.Live)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "--- live\n%s", live)
+		}
+	}
+	fmt.Fprintln(out, "--- diff (live -> desired)")
+	if len(resourceDiff.Fields) == 0 {
+		fmt.Fprintln(out, "<none>")
+	}
+	for _, field := range resourceDiff.Fields {
+		fmt.Fprintln(out, field)
+	}
+	return nil
+}
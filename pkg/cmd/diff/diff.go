@@ -0,0 +1,247 @@
+package diff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	// kube
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	// openshift
+	configv1 "github.com/openshift/api/config/v1"
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	// us
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/cmd/render"
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
+)
+
+// operatorEnv are the environment variables of the operator the rendered resources depend on
+var operatorEnv = []string{"CONSOLE_IMAGE", "DOWNLOADS_IMAGE", "RELEASE_VERSION"}
+
+// ResourceDiff is the desired and live state of a resource managed by the operator, along with the
+// fields of the desired state the live one does not match. Live is nil when the resource does not
+// exist.
+type ResourceDiff struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Desired   map[string]interface{}
+	Live      map[string]interface{}
+	Fields    []FieldDiff
+}
+
+// FieldDiff is a field set by the operator, Live is nil when it is missing from the live resource.
+type FieldDiff struct {
+	Path    string
+	Desired interface{}
+	Live    interface{}
+}
+
+func (d FieldDiff) String() string {
+	return fmt.Sprintf("%s: %s -> %s", d.Path, toJSON(d.Live), toJSON(d.Desired))
+}
+
+func toJSON(value interface{}) string {
+	if value == nil {
+		return "<missing>"
+	}
+	out, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(out)
+}
+
+// LiveInputs reads the inputs the operator renders the console from out of the cluster, and
+// exports the environment of the running operator so the console images and release version
+// match the ones it renders.
+func LiveInputs(ctx context.Context, client dynamic.Interface) (*render.Inputs, error) {
+	inputs := render.NewInputs()
+	add := func(gvr schema.GroupVersionResource, namespace, name string, required bool) error {
+		obj, err := client.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) && !required {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		content, err := obj.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		return inputs.Add(content)
+	}
+
+	if err := add(operatorv1.GroupVersion.WithResource("consoles"), "", api.ConfigResourceName, true); err != nil {
+		return nil, err
+	}
+	for _, resource := range []string{"consoles", "infrastructures", "ingresses", "proxies", "authentications", "oauths"} {
+		if err := add(configv1.GroupVersion.WithResource(resource), "", api.ConfigResourceName, false); err != nil {
+			return nil, err
+		}
+	}
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	for _, configMap := range []struct{ namespace, name string }{
+		{api.OpenShiftConfigManagedNamespace, api.OpenShiftConsoleConfigMapName},
+		{api.OpenShiftConfigManagedNamespace, api.OpenShiftMonitoringConfigMapName},
+		{api.OpenShiftConfigManagedNamespace, api.OAuthServingCertConfigMapName},
+		{api.TargetNamespace, api.TrustedCAConfigMapName},
+	} {
+		if err := add(configMaps, configMap.namespace, configMap.name, false); err != nil {
+			return nil, err
+		}
+	}
+	if inputs.Authentication != nil {
+		for _, caName := range configmapsub.OIDCCATrustSources(inputs.OperatorConfig, inputs.Authentication, inputs.Proxy) {
+			if err := add(configMaps, api.OpenShiftConfigNamespace, caName, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, pluginName := range inputs.OperatorConfig.Spec.Plugins {
+		if err := add(consolev1.GroupVersion.WithResource("consoleplugins"), "", pluginName, false); err != nil {
+			return nil, err
+		}
+	}
+
+	operator, err := client.Resource(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}).
+		Namespace(api.OpenShiftConsoleOperatorNamespace).Get(ctx, api.OpenShiftConsoleOperator, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	containers, _, _ := unstructured.NestedSlice(operator.Object, "spec", "template", "spec", "containers")
+	for _, container := range containers {
+		env, _, _ := unstructured.NestedSlice(container.(map[string]interface{}), "env")
+		for _, envVar := range env {
+			name, _, _ := unstructured.NestedString(envVar.(map[string]interface{}), "name")
+			value, _, _ := unstructured.NestedString(envVar.(map[string]interface{}), "value")
+			for _, wanted := range operatorEnv {
+				if name == wanted {
+					os.Setenv(name, value)
+				}
+			}
+		}
+	}
+	return inputs, nil
+}
+
+// Diff fetches the live state of every desired resource and compares it with the desired one.
+func Diff(ctx context.Context, client dynamic.Interface, desired []runtime.Object) ([]ResourceDiff, error) {
+	diffs := []ResourceDiff{}
+	for _, obj := range desired {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return nil, err
+		}
+		desiredObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+		gvk := obj.GetObjectKind().GroupVersionKind()
+		gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+		resourceDiff := ResourceDiff{
+			Kind:      gvk.Kind,
+			Namespace: accessor.GetNamespace(),
+			Name:      accessor.GetName(),
+			Desired:   desiredObj,
+		}
+		live, err := client.Resource(gvr).Namespace(accessor.GetNamespace()).Get(ctx, accessor.GetName(), metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+		case err != nil:
+			return nil, err
+		default:
+			resourceDiff.Live = live.Object
+		}
+		resourceDiff.Fields = CompareFields(desiredObj, resourceDiff.Live)
+		diffs = append(diffs, resourceDiff)
+	}
+	return diffs, nil
+}
+
+// CompareFields returns the fields of desired that live does not match. Like the operator when it
+// applies a resource, fields only set in live are not compared, and neither are status and the
+// deployment annotations that only change to roll the console out.
+func CompareFields(desired, live map[string]interface{}) []FieldDiff {
+	diffs := []FieldDiff{}
+	compareValue(nil, desired, live, &diffs)
+	return diffs
+}
+
+func compareValue(path []string, desired, live interface{}, diffs *[]FieldDiff) {
+	if desired == nil || ignored(path) {
+		return
+	}
+	switch desiredValue := desired.(type) {
+	case map[string]interface{}:
+		liveValue, _ := live.(map[string]interface{})
+		keys := make([]string, 0, len(desiredValue))
+		for key := range desiredValue {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			var liveField interface{}
+			if liveValue != nil {
+				liveField = liveValue[key]
+			}
+			compareValue(append(append([]string{}, path...), key), desiredValue[key], liveField, diffs)
+		}
+	case []interface{}:
+		liveValue, ok := live.([]interface{})
+		if !ok || len(liveValue) != len(desiredValue) {
+			*diffs = append(*diffs, FieldDiff{Path: formatPath(path), Desired: desired, Live: live})
+			return
+		}
+		for i := range desiredValue {
+			compareValue(append(append([]string{}, path...), fmt.Sprintf("[%d]", i)), desiredValue[i], liveValue[i], diffs)
+		}
+	default:
+		if !reflect.DeepEqual(desired, live) {
+			*diffs = append(*diffs, FieldDiff{Path: formatPath(path), Desired: desired, Live: live})
+		}
+	}
+}
+
+func ignored(path []string) bool {
+	switch {
+	case len(path) == 1 && path[0] == "status":
+		return true
+	case len(path) == 3 && path[0] == "metadata" && path[1] == "annotations",
+		len(path) == 5 && strings.Join(path[:4], ".") == "spec.template.metadata.annotations":
+		return deploymentsub.IsResourceAnnotation(path[len(path)-1])
+	}
+	return false
+}
+
+func formatPath(path []string) string {
+	out := ""
+	for _, segment := range path {
+		switch {
+		case strings.HasPrefix(segment, "["):
+			out += segment
+		case strings.ContainsAny(segment, "./"):
+			out += fmt.Sprintf("[%q]", segment)
+		case len(out) == 0:
+			out = segment
+		default:
+			out += "." + segment
+		}
+	}
+	return out
+}
@@ -0,0 +1,126 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestCompareFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		desired map[string]interface{}
+		live    map[string]interface{}
+		want    []string
+	}{
+		{
+			name: "Test fields only set in live are not compared",
+			desired: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "console"},
+				"data":     map[string]interface{}{"key": "value"},
+			},
+			live: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "console", "resourceVersion": "10"},
+				"data":     map[string]interface{}{"key": "value"},
+			},
+			want: []string{},
+		},
+		{
+			name: "Test changed and missing fields",
+			desired: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{"app": "console"},
+				},
+				"spec": map[string]interface{}{
+					"replicas": int64(2),
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"containers": []interface{}{
+								map[string]interface{}{"name": "console", "image": "console:v2"},
+							},
+						},
+					},
+				},
+			},
+			live: map[string]interface{}{
+				"metadata": map[string]interface{}{},
+				"spec": map[string]interface{}{
+					"replicas": int64(1),
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"containers": []interface{}{
+								map[string]interface{}{"name": "console", "image": "console:v1"},
+							},
+						},
+					},
+				},
+			},
+			want: []string{
+				`metadata.labels.app: <missing> -> "console"`,
+				`spec.replicas: 1 -> 2`,
+				`spec.template.spec.containers[0].image: "console:v1" -> "console:v2"`,
+			},
+		},
+		{
+			name: "Test lists of a different length",
+			desired: map[string]interface{}{
+				"spec": map[string]interface{}{"args": []interface{}{"--a", "--b"}},
+			},
+			live: map[string]interface{}{
+				"spec": map[string]interface{}{"args": []interface{}{"--a"}},
+			},
+			want: []string{`spec.args: ["--a"] -> ["--a","--b"]`},
+		},
+		{
+			name: "Test rollout annotations and status are ignored",
+			desired: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{
+						"console.openshift.io/console-config-version": "",
+						"example.com/owner":                           "console",
+					},
+				},
+				"spec": map[string]interface{}{
+					"template": map[string]interface{}{
+						"metadata": map[string]interface{}{
+							"annotations": map[string]interface{}{"console.openshift.io/console-config-version": ""},
+						},
+					},
+				},
+				"status": map[string]interface{}{"replicas": int64(0)},
+			},
+			live: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{"console.openshift.io/console-config-version": "10"},
+				},
+				"spec": map[string]interface{}{
+					"template": map[string]interface{}{
+						"metadata": map[string]interface{}{
+							"annotations": map[string]interface{}{"console.openshift.io/console-config-version": "10"},
+						},
+					},
+				},
+				"status": map[string]interface{}{"replicas": int64(2)},
+			},
+			want: []string{`metadata.annotations["example.com/owner"]: <missing> -> "console"`},
+		},
+		{
+			name: "Test missing live resource",
+			desired: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "console"},
+			},
+			want: []string{`metadata.name: <missing> -> "console"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, field := range CompareFields(tt.desired, tt.live) {
+				got = append(got, field.String())
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
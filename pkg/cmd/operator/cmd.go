@@ -3,22 +3,34 @@ package operator
 import (
 	// golang
 	"context"
+	"time"
 	// 3rd party
 	"github.com/spf13/cobra"
 	// kube / openshift
 	"github.com/openshift/library-go/pkg/controller/controllercmd"
 	// us
+	"github.com/openshift/console-operator/pkg/console/clientwrapper"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
 	"github.com/openshift/console-operator/pkg/console/starter"
 	"github.com/openshift/console-operator/pkg/console/version"
 )
 
+var (
+	dryRun bool
+	budget clientwrapper.BudgetOptions
+
+	oidcBackoff util.RequeueBackoffOptions
+)
+
 func NewOperator() *cobra.Command {
 
 	cmd := controllercmd.
 		NewControllerCommandConfig(
 			"console-operator",
 			version.Get(),
-			starter.RunOperator).
+			func(ctx context.Context, controllerContext *controllercmd.ControllerContext) error {
+				return starter.RunOperator(ctx, controllerContext, dryRun, budget, oidcBackoff)
+			}).
 		NewCommandWithContext(context.TODO())
 	cmd.Use = "operator"
 	cmd.Short = "Start the Console Operator"
@@ -27,5 +39,13 @@ func NewOperator() *cobra.Command {
 	// https://github.com/spf13/cobra#create-rootcmd
 	cmd.Long = `An Operator for a web console for OpenShift.
 				`
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Compute and report the changes the operator would make as events, without persisting them.")
+	cmd.Flags().Float32Var(&budget.QPS, "kube-api-qps", 0, "QPS shared by the clients of the operator to talk to the API server, client-go gives each client 5 QPS when unset.")
+	cmd.Flags().IntVar(&budget.Burst, "kube-api-burst", 0, "Burst shared by the clients of the operator to talk to the API server, client-go gives each client a burst of 10 when unset.")
+	cmd.Flags().IntVar(&budget.MaxWatches, "kube-api-max-watches", 0, "Maximum number of watches the operator keeps open at once, the watches over it are backed off and retried. Unlimited when unset.")
+	cmd.Flags().IntVar(&budget.Retries, "kube-api-retries", 0, "Number of times the reads throttled or failed by the API server are retried, on top of the retries of the responses with a Retry-After header.")
+	cmd.Flags().DurationVar(&budget.RetryBackoff, "kube-api-retry-backoff", 500*time.Millisecond, "Backoff before the first retry of a read, doubled on every retry.")
+	cmd.Flags().DurationVar(&oidcBackoff.Base, "oidc-requeue-backoff", time.Second, "Backoff before the OIDC setup is synced again after a failure, doubled on every failure.")
+	cmd.Flags().DurationVar(&oidcBackoff.Max, "oidc-requeue-max-backoff", 5*time.Minute, "Maximum backoff before the OIDC setup is synced again after a failure.")
 	return cmd
 }
@@ -0,0 +1,52 @@
+package render
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+)
+
+var (
+	assetInputDir  string
+	assetOutputDir string
+	manifestsDir   string
+)
+
+func NewRender() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "render",
+		Short: "Render the console manifests the operator would apply, offline",
+		Long: `Render the console manifests the operator would apply for the operator and cluster configs
+found in --asset-input-dir, without a cluster. Every resource is written to its own file in
+--asset-output-dir so the output of two releases can be diffed.`,
+		Run: func(command *cobra.Command, args []string) {
+			if err := render(); err != nil {
+				klog.Fatalf("Error rendering console manifests: %v", err)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&assetInputDir, "asset-input-dir", "", "Directory of the operator config, cluster configs, ConfigMaps and ConsolePlugins to render from.")
+	cmd.Flags().StringVar(&assetOutputDir, "asset-output-dir", "", "Directory the rendered manifests are written to.")
+	cmd.Flags().StringVar(&manifestsDir, "manifests-dir", "", "Directory of the operator manifests the RBAC manifests are copied from, none are copied when empty.")
+	cmd.MarkFlagRequired("asset-input-dir")
+	cmd.MarkFlagRequired("asset-output-dir")
+
+	return cmd
+}
+
+func render() error {
+	inputs, err := ReadInputs(assetInputDir)
+	if err != nil {
+		return err
+	}
+	objects, err := Render(inputs)
+	if err != nil {
+		return err
+	}
+	if err := WriteObjects(assetOutputDir, objects); err != nil {
+		return err
+	}
+	if len(manifestsDir) == 0 {
+		return nil
+	}
+	return CopyRBACManifests(manifestsDir, assetOutputDir)
+}
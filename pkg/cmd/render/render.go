@@ -0,0 +1,430 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	// kube
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/runtime/serializer/json"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	// openshift
+	configv1 "github.com/openshift/api/config/v1"
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+
+	// us
+	"github.com/openshift/console-operator/bindata"
+	"github.com/openshift/console-operator/pkg/api"
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
+	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
+	secretsub "github.com/openshift/console-operator/pkg/console/subresource/secret"
+	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+var (
+	scheme = runtime.NewScheme()
+	codecs = serializer.NewCodecFactory(scheme)
+)
+
+func init() {
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		appsv1.AddToScheme,
+		corev1.AddToScheme,
+		policyv1.AddToScheme,
+		configv1.AddToScheme,
+		consolev1.AddToScheme,
+		operatorv1.AddToScheme,
+		routev1.AddToScheme,
+	} {
+		if err := addToScheme(scheme); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Inputs are the resources the operator reads from the cluster to render the console. Cluster
+// scoped configs default to an empty "cluster" instance, ConfigMaps are looked up by namespace
+// and name the same way the operator looks them up in its listers.
+type Inputs struct {
+	OperatorConfig *operatorv1.Console
+	ConsoleConfig  *configv1.Console
+	Infrastructure *configv1.Infrastructure
+	Ingress        *configv1.Ingress
+	Proxy          *configv1.Proxy
+	Authentication *configv1.Authentication
+	OAuth          *configv1.OAuth
+	ConfigMaps     map[string]*corev1.ConfigMap
+	ConsolePlugins map[string]*consolev1.ConsolePlugin
+}
+
+// ReadInputs decodes every YAML or JSON document of the files in dir. Each config has to be
+// given at most once, unknown kinds are rejected so a typo does not silently render defaults.
+func ReadInputs(dir string) (*Inputs, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	inputs := NewInputs()
+	for _, file := range files {
+		if file.IsDir() || !isManifest(file.Name()) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if err := inputs.Add(content); err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Name(), err)
+		}
+	}
+	if inputs.OperatorConfig == nil {
+		return nil, fmt.Errorf("no %s operator config found in %s", api.ConfigResourceName, dir)
+	}
+	return inputs, nil
+}
+
+func isManifest(name string) bool {
+	switch filepath.Ext(name) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// NewInputs returns empty inputs, see Add.
+func NewInputs() *Inputs {
+	return &Inputs{
+		ConfigMaps:     map[string]*corev1.ConfigMap{},
+		ConsolePlugins: map[string]*consolev1.ConsolePlugin{},
+	}
+}
+
+// Add decodes the YAML or JSON documents of content into inputs.
+func (i *Inputs) Add(content []byte) error {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), 4096)
+	for {
+		raw := runtime.RawExtension{}
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if len(bytes.TrimSpace(raw.Raw)) == 0 || string(bytes.TrimSpace(raw.Raw)) == "null" {
+			continue
+		}
+		obj, _, err := codecs.UniversalDeserializer().Decode(raw.Raw, nil, nil)
+		if err != nil {
+			return err
+		}
+		if err := i.addObject(obj); err != nil {
+			return err
+		}
+	}
+}
+
+func (i *Inputs) addObject(obj runtime.Object) error {
+	duplicate := func(kind string) error {
+		return fmt.Errorf("%s given more than once", kind)
+	}
+	switch o := obj.(type) {
+	case *operatorv1.Console:
+		if i.OperatorConfig != nil {
+			return duplicate("consoles.operator.openshift.io")
+		}
+		i.OperatorConfig = o
+	case *configv1.Console:
+		if i.ConsoleConfig != nil {
+			return duplicate("consoles.config.openshift.io")
+		}
+		i.ConsoleConfig = o
+	case *configv1.Infrastructure:
+		if i.Infrastructure != nil {
+			return duplicate("infrastructures.config.openshift.io")
+		}
+		i.Infrastructure = o
+	case *configv1.Ingress:
+		if i.Ingress != nil {
+			return duplicate("ingresses.config.openshift.io")
+		}
+		i.Ingress = o
+	case *configv1.Proxy:
+		if i.Proxy != nil {
+			return duplicate("proxies.config.openshift.io")
+		}
+		i.Proxy = o
+	case *configv1.Authentication:
+		if i.Authentication != nil {
+			return duplicate("authentications.config.openshift.io")
+		}
+		i.Authentication = o
+	case *configv1.OAuth:
+		if i.OAuth != nil {
+			return duplicate("oauths.config.openshift.io")
+		}
+		i.OAuth = o
+	case *corev1.ConfigMap:
+		i.ConfigMaps[o.Namespace+"/"+o.Name] = o
+	case *consolev1.ConsolePlugin:
+		i.ConsolePlugins[o.Name] = o
+	default:
+		return fmt.Errorf("unsupported input %s", obj.GetObjectKind().GroupVersionKind())
+	}
+	return nil
+}
+
+func (i *Inputs) configMap(namespace, name string) *corev1.ConfigMap {
+	return i.ConfigMaps[namespace+"/"+name]
+}
+
+func (i *Inputs) withDefaults() {
+	clusterMeta := metav1.ObjectMeta{Name: api.ConfigResourceName}
+	if i.ConsoleConfig == nil {
+		i.ConsoleConfig = &configv1.Console{ObjectMeta: clusterMeta}
+	}
+	if i.Infrastructure == nil {
+		i.Infrastructure = &configv1.Infrastructure{ObjectMeta: clusterMeta}
+	}
+	if i.Ingress == nil {
+		i.Ingress = &configv1.Ingress{ObjectMeta: clusterMeta}
+	}
+	if i.Proxy == nil {
+		i.Proxy = &configv1.Proxy{ObjectMeta: clusterMeta}
+	}
+	if i.Authentication == nil {
+		i.Authentication = &configv1.Authentication{ObjectMeta: clusterMeta}
+	}
+	if i.OAuth == nil {
+		i.OAuth = &configv1.OAuth{ObjectMeta: clusterMeta}
+	}
+}
+
+// Render returns the resources the operator would apply in openshift-console for inputs, in a
+// stable order. Values only known in a live cluster, like resource versions, Secrets contents
+// and node architectures, are left empty. Invalid inputs the operator would report as degraded
+// fail the render instead.
+func Render(inputs *Inputs) ([]runtime.Object, error) {
+	inputs.withDefaults()
+	operatorConfig := inputs.OperatorConfig
+
+	consoleRouteConfig := routesub.NewRouteConfig(operatorConfig, inputs.Ingress, api.OpenShiftConsoleRouteName)
+	consoleRoutes := routes(consoleRouteConfig, inputs.Ingress, api.OpenShiftConsoleRouteName)
+	downloadsRouteConfig := routesub.NewRouteConfig(operatorConfig, inputs.Ingress, api.OpenShiftConsoleDownloadsRouteName)
+	downloadsRoutes := routes(downloadsRouteConfig, inputs.Ingress, api.OpenShiftConsoleDownloadsRouteName)
+	// the custom route is the active one when there is one
+	activeConsoleRoute := consoleRoutes[len(consoleRoutes)-1]
+
+	var (
+		authServerCAConfig *corev1.ConfigMap
+		sessionSecret      *corev1.Secret
+		oauthServingCert   *corev1.ConfigMap
+	)
+	switch inputs.Authentication.Spec.Type {
+	case configv1.AuthenticationTypeOIDC:
+		caTrustSources := []*corev1.ConfigMap{}
+		for _, name := range configmapsub.OIDCCATrustSources(operatorConfig, inputs.Authentication, inputs.Proxy) {
+			caTrustSources = append(caTrustSources, inputs.configMap(api.OpenShiftConfigNamespace, name))
+		}
+		var err error
+		authServerCAConfig, err = configmapsub.DefaultOIDCCATrustConfigMap(operatorConfig, caTrustSources...)
+		if err != nil {
+			return nil, err
+		}
+		sessionSecret = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: api.SessionSecretName, Namespace: api.TargetNamespace}}
+	case "", configv1.AuthenticationTypeIntegratedOAuth:
+		oauthServingCert = inputs.configMap(api.OpenShiftConfigManagedNamespace, api.OAuthServingCertConfigMapName)
+		if oauthServingCert == nil {
+			oauthServingCert = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: api.OAuthServingCertConfigMapName, Namespace: api.OpenShiftConfigManagedNamespace}}
+		}
+	}
+
+	// the console OAuth client is created by the operator with the cluster token policy
+	sessionPolicy := utilsub.GetSessionPolicy(inputs.Authentication, inputs.OAuth, nil)
+	groupInactivityTimeouts, err := utilsub.GetGroupInactivityTimeouts(operatorConfig)
+	if err != nil {
+		return nil, err
+	}
+	contentSecurityPolicy, err := utilsub.GetContentSecurityPolicy(operatorConfig)
+	if err != nil {
+		return nil, err
+	}
+	statusProvider, err := utilsub.GetStatusProviderConfig(operatorConfig, contentSecurityPolicy)
+	if err != nil {
+		return nil, err
+	}
+	availablePlugins := []*consolev1.ConsolePlugin{}
+	for _, pluginName := range utilsub.RemoveDuplicateStr(operatorConfig.Spec.Plugins) {
+		if plugin, ok := inputs.ConsolePlugins[pluginName]; ok {
+			availablePlugins = append(availablePlugins, plugin)
+		}
+	}
+	managedConfig := inputs.configMap(api.OpenShiftConfigManagedNamespace, api.OpenShiftConsoleConfigMapName)
+	if managedConfig == nil {
+		managedConfig = &corev1.ConfigMap{}
+	}
+	monitoringSharedConfig := inputs.configMap(api.OpenShiftConfigManagedNamespace, api.OpenShiftMonitoringConfigMapName)
+	if monitoringSharedConfig == nil {
+		monitoringSharedConfig = &corev1.ConfigMap{}
+	}
+
+	consoleConfigMap, _, err := configmapsub.DefaultConfigMap(
+		operatorConfig,
+		inputs.ConsoleConfig,
+		inputs.Authentication,
+		authServerCAConfig,
+		sessionSecret,
+		managedConfig,
+		monitoringSharedConfig,
+		inputs.Infrastructure,
+		activeConsoleRoute,
+		sessionPolicy,
+		groupInactivityTimeouts,
+		contentSecurityPolicy,
+		nil,
+		nil,
+		nil,
+		statusProvider,
+		nil,
+		availablePlugins,
+		nil,
+		nil,
+		false,
+	)
+	if err != nil {
+		return nil, err
+	}
+	serviceCAConfigMap := configmapsub.DefaultServiceCAConfigMap(operatorConfig)
+	trustedCAConfigMap := configmapsub.DefaultTrustedCAConfigMap(operatorConfig)
+	if injected := inputs.configMap(api.TargetNamespace, api.TrustedCAConfigMapName); injected != nil {
+		trustedCAConfigMap.Data = injected.Data
+	}
+
+	oidcSecretProviderClass := utilsub.GetOIDCSecretProviderClass(operatorConfig, inputs.Authentication)
+	noClientSecret := utilsub.IsOIDCPublicClient(operatorConfig, inputs.Authentication) || utilsub.IsAuthenticationDisabled(inputs.Authentication)
+	var clientSecret *corev1.Secret
+	if len(oidcSecretProviderClass) == 0 && !noClientSecret {
+		clientSecret = secretsub.Stub()
+	}
+
+	consoleDeployment := deploymentsub.DefaultDeployment(
+		operatorConfig,
+		consoleConfigMap,
+		serviceCAConfigMap,
+		oauthServingCert,
+		authServerCAConfig,
+		trustedCAConfigMap,
+		clientSecret,
+		oidcSecretProviderClass,
+		noClientSecret,
+		sessionSecret,
+		nil,
+		nil,
+		inputs.Proxy,
+		inputs.Infrastructure,
+		len(operatorConfig.Spec.Customization.CustomLogoFile.Name) != 0,
+	)
+
+	objects := []runtime.Object{
+		consoleConfigMap,
+		configmapsub.DefaultPublicConfig(consoleURL(activeConsoleRoute)),
+		serviceCAConfigMap,
+		trustedCAConfigMap,
+		consoleDeployment,
+		deploymentsub.DefaultDownloadsDeployment(operatorConfig, inputs.Infrastructure),
+		resourceread.ReadServiceV1OrDie(bindata.MustAsset("assets/services/console-service.yaml")),
+		resourceread.ReadServiceV1OrDie(bindata.MustAsset("assets/services/downloads-service.yaml")),
+	}
+	// the redirect service is only used by the console route
+	if consoleRouteConfig.IsCustomHostnameSet() {
+		objects = append(objects, resourceread.ReadServiceV1OrDie(bindata.MustAsset("assets/services/console-redirect-service.yaml")))
+	}
+	for _, route := range append(consoleRoutes, downloadsRoutes...) {
+		objects = append(objects, route)
+	}
+	objects = append(objects,
+		resourceread.ReadPodDisruptionBudgetV1OrDie(bindata.MustAsset("assets/pdb/console-pdb.yaml")),
+		resourceread.ReadPodDisruptionBudgetV1OrDie(bindata.MustAsset("assets/pdb/downloads-pdb.yaml")),
+	)
+	for _, obj := range objects {
+		gvks, _, err := scheme.ObjectKinds(obj)
+		if err != nil {
+			return nil, err
+		}
+		obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+	}
+	return objects, nil
+}
+
+// routes returns the default route, followed by the custom route when a custom hostname other
+// than the default one is set. Custom TLS certificates live in Secrets, so none is rendered.
+func routes(routeConfig *routesub.RouteConfig, ingressConfig *configv1.Ingress, routeName string) []*routev1.Route {
+	routes := []*routev1.Route{routeConfig.DefaultRoute(nil, ingressConfig)}
+	if routeConfig.IsCustomHostnameSet() && !routeConfig.HostnameMatch() {
+		routes = append(routes, routeConfig.CustomRoute(nil, routeName))
+	}
+	return routes
+}
+
+func consoleURL(route *routev1.Route) string {
+	return fmt.Sprintf("https://%s", route.Spec.Host)
+}
+
+// WriteObjects writes every object to its own <kind>-<name>.yaml file in dir.
+func WriteObjects(dir string, objects []runtime.Object) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	encoder := json.NewSerializerWithOptions(json.DefaultMetaFactory, scheme, scheme, json.SerializerOptions{Yaml: true})
+	for _, obj := range objects {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		buf := &bytes.Buffer{}
+		if err := encoder.Encode(obj, buf); err != nil {
+			return err
+		}
+		fileName := fmt.Sprintf("%s-%s.yaml", strings.ToLower(obj.GetObjectKind().GroupVersionKind().Kind), accessor.GetName())
+		if err := os.WriteFile(filepath.Join(dir, fileName), buf.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CopyRBACManifests copies the RBAC manifests installed next to the operator, which the operator
+// relies on but does not apply itself, from manifestsDir to dir.
+func CopyRBACManifests(manifestsDir, dir string) error {
+	files, err := filepath.Glob(filepath.Join(manifestsDir, "*-rbac-*.yaml"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(file)), content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+const (
+	operatorConfigInput = `apiVersion: operator.openshift.io/v1
+kind: Console
+metadata:
+  name: cluster
+spec:
+  managementState: Managed
+`
+	customHostnameOperatorConfigInput = `apiVersion: operator.openshift.io/v1
+kind: Console
+metadata:
+  name: cluster
+spec:
+  managementState: Managed
+  route:
+    hostname: my-console.example.com
+`
+	ingressInput = `apiVersion: config.openshift.io/v1
+kind: Ingress
+metadata:
+  name: cluster
+spec:
+  domain: apps.example.com
+`
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		inputs  []string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:   "Test default console",
+			inputs: []string{operatorConfigInput, ingressInput},
+			want: []string{
+				"ConfigMap/console-config",
+				"ConfigMap/console-public",
+				"ConfigMap/service-ca",
+				"ConfigMap/trusted-ca-bundle",
+				"Deployment/console",
+				"Deployment/downloads",
+				"Service/console",
+				"Service/downloads",
+				"Route/console",
+				"Route/downloads",
+				"PodDisruptionBudget/console",
+				"PodDisruptionBudget/downloads",
+			},
+		},
+		{
+			name:   "Test custom console hostname",
+			inputs: []string{customHostnameOperatorConfigInput + "---\n" + ingressInput},
+			want: []string{
+				"ConfigMap/console-config",
+				"ConfigMap/console-public",
+				"ConfigMap/service-ca",
+				"ConfigMap/trusted-ca-bundle",
+				"Deployment/console",
+				"Deployment/downloads",
+				"Service/console",
+				"Service/downloads",
+				"Service/console-redirect",
+				"Route/console",
+				"Route/console-custom",
+				"Route/downloads",
+				"PodDisruptionBudget/console",
+				"PodDisruptionBudget/downloads",
+			},
+		},
+		{
+			name:    "Test missing operator config",
+			inputs:  []string{ingressInput},
+			wantErr: "no cluster operator config found",
+		},
+		{
+			name:    "Test duplicate config",
+			inputs:  []string{operatorConfigInput, ingressInput, ingressInput},
+			wantErr: "ingresses.config.openshift.io given more than once",
+		},
+		{
+			name:    "Test unsupported input",
+			inputs:  []string{operatorConfigInput, "apiVersion: v1\nkind: Secret\nmetadata:\n  name: console\n"},
+			wantErr: "unsupported input",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for i, input := range tt.inputs {
+				if err := os.WriteFile(filepath.Join(dir, string(rune('a'+i))+".yaml"), []byte(input), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			inputs, err := ReadInputs(dir)
+			if err != nil {
+				if len(tt.wantErr) == 0 || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if len(tt.wantErr) != 0 {
+				t.Fatalf("expected error %q", tt.wantErr)
+			}
+			objects, err := Render(inputs)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := []string{}
+			for _, obj := range objects {
+				accessor, err := meta.Accessor(obj)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, obj.GetObjectKind().GroupVersionKind().Kind+"/"+accessor.GetName())
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestWriteObjects(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "inputs.yaml"), []byte(operatorConfigInput+"---\n"+ingressInput), 0644); err != nil {
+		t.Fatal(err)
+	}
+	inputs, err := ReadInputs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects, err := Render(inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputDir := filepath.Join(dir, "output")
+	if err := WriteObjects(outputDir, objects); err != nil {
+		t.Fatal(err)
+	}
+	route, err := os.ReadFile(filepath.Join(outputDir, "route-console.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"apiVersion: route.openshift.io/v1", "kind: Route", "host: console-openshift-console.apps.example.com"} {
+		if !strings.Contains(string(route), want) {
+			t.Errorf("expected %q in rendered route:\n%s", want, route)
+		}
+	}
+}
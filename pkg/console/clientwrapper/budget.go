@@ -0,0 +1,237 @@
+package clientwrapper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/console-operator/pkg/console/metrics"
+)
+
+const defaultRetryBackoff = 500 * time.Millisecond
+
+// BudgetOptions is the share of the API server the clients of the operator may use. The zero
+// value leaves the defaults of client-go in place.
+type BudgetOptions struct {
+	// QPS and Burst are shared by every client of the operator, where client-go gives each
+	// client its own
+	QPS   float32
+	Burst int
+	// MaxWatches caps the watches open at once, a watch over it is rejected with 429 Too Many
+	// Requests for its informer to back off and retry
+	MaxWatches int
+	// Retries of the reads the API server throttled or failed, on top of the retries client-go
+	// makes when the response has a Retry-After header. The backoff doubles on every retry.
+	Retries      int
+	RetryBackoff time.Duration
+}
+
+// Budget enforces BudgetOptions across the clients of every config it wraps, and reports
+// the consumption of the budget as metrics.
+type Budget struct {
+	options     BudgetOptions
+	rateLimiter flowcontrol.RateLimiter
+	watches     atomic.Int64
+}
+
+func NewBudget(options BudgetOptions) *Budget {
+	budget := &Budget{options: options}
+	limits := map[string]float64{}
+	if options.QPS > 0 || options.Burst > 0 {
+		qps, burst := options.QPS, options.Burst
+		if qps <= 0 {
+			qps = rest.DefaultQPS
+		}
+		if burst <= 0 {
+			burst = rest.DefaultBurst
+		}
+		// a burst lower than the QPS would never let the QPS be reached
+		if minBurst := int(math.Ceil(float64(qps))); burst < minBurst {
+			burst = minBurst
+		}
+		budget.rateLimiter = &meteredRateLimiter{RateLimiter: flowcontrol.NewTokenBucketRateLimiter(qps, burst)}
+		limits["qps"] = float64(qps)
+		limits["burst"] = float64(burst)
+	}
+	if options.MaxWatches > 0 {
+		limits["watches"] = float64(options.MaxWatches)
+	}
+	if options.Retries > 0 {
+		limits["retries"] = float64(options.Retries)
+	}
+	metrics.HandleAPIClientBudget(limits)
+	return budget
+}
+
+// Apply returns a copy of config whose clients share the budget with the clients of every
+// other config the budget was applied to.
+func (b *Budget) Apply(config *rest.Config) *rest.Config {
+	budgetConfig := rest.CopyConfig(config)
+	if b.rateLimiter != nil {
+		budgetConfig.RateLimiter = b.rateLimiter
+	}
+	budgetConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &budgetRoundTripper{delegate: rt, budget: b}
+	})
+	return budgetConfig
+}
+
+type budgetRoundTripper struct {
+	delegate http.RoundTripper
+	budget   *Budget
+}
+
+func (rt *budgetRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if isWatch(req) {
+		return rt.watch(req)
+	}
+	resp, err := rt.send(req)
+	if req.Method != http.MethodGet {
+		return resp, err
+	}
+	for retry := 0; retry < rt.budget.options.Retries && isRetriable(resp, err) && req.Context().Err() == nil; retry++ {
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if waitErr := rt.backoff(req, retry); waitErr != nil {
+			return nil, waitErr
+		}
+		klog.V(4).Infof("retrying %s %s, attempt %d of %d", req.Method, req.URL.Path, retry+1, rt.budget.options.Retries)
+		metrics.HandleAPIClientRetry()
+		resp, err = rt.send(req)
+	}
+	return resp, err
+}
+
+func (rt *budgetRoundTripper) send(req *http.Request) (*http.Response, error) {
+	resp, err := rt.delegate.RoundTrip(req)
+	code := "<error>"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	metrics.HandleAPIClientRequest(req.Method, code)
+	return resp, err
+}
+
+// backoff waits before a retry, and for the rate limiter since the retries are not rate limited
+// by the client.
+func (rt *budgetRoundTripper) backoff(req *http.Request, retry int) error {
+	backoff := rt.budget.options.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+	timer := time.NewTimer(backoff << retry)
+	defer timer.Stop()
+	select {
+	case <-req.Context().Done():
+		return req.Context().Err()
+	case <-timer.C:
+	}
+	if rt.budget.rateLimiter != nil {
+		return rt.budget.rateLimiter.Wait(req.Context())
+	}
+	return nil
+}
+
+func (rt *budgetRoundTripper) watch(req *http.Request) (*http.Response, error) {
+	maxWatches := int64(rt.budget.options.MaxWatches)
+	if open := rt.budget.watches.Add(1); maxWatches > 0 && open > maxWatches {
+		rt.closeWatch()
+		metrics.HandleAPIClientWatchRejected()
+		klog.V(2).Infof("watch budget of %d used up, rejecting watch %s", maxWatches, req.URL.Path)
+		return tooManyWatches(req, maxWatches), nil
+	}
+	metrics.HandleAPIClientWatches(rt.budget.watches.Load())
+
+	resp, err := rt.send(req)
+	if err != nil {
+		rt.closeWatch()
+		return resp, err
+	}
+	var once sync.Once
+	resp.Body = &watchBody{ReadCloser: resp.Body, close: func() { once.Do(rt.closeWatch) }}
+	return resp, nil
+}
+
+func (rt *budgetRoundTripper) closeWatch() {
+	metrics.HandleAPIClientWatches(rt.budget.watches.Add(-1))
+}
+
+type watchBody struct {
+	io.ReadCloser
+	close func()
+}
+
+func (b *watchBody) Close() error {
+	defer b.close()
+	return b.ReadCloser.Close()
+}
+
+func isWatch(req *http.Request) bool {
+	watch := req.URL.Query().Get("watch")
+	return req.Method == http.MethodGet && (watch == "true" || watch == "1")
+}
+
+func isRetriable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func tooManyWatches(req *http.Request, maxWatches int64) *http.Response {
+	status := apierrors.NewTooManyRequests(fmt.Sprintf("the console operator has %d watches open, its watch budget", maxWatches), 1).ErrStatus
+	status.Kind = "Status"
+	status.APIVersion = "v1"
+	body, _ := json.Marshal(status)
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", http.StatusTooManyRequests, http.StatusText(http.StatusTooManyRequests)),
+		StatusCode: http.StatusTooManyRequests,
+		Proto:      req.Proto,
+		ProtoMajor: req.ProtoMajor,
+		ProtoMinor: req.ProtoMinor,
+		Header: http.Header{
+			"Content-Type": []string{"application/json"},
+			"Retry-After":  []string{"1"},
+		},
+		Body:          io.NopCloser(strings.NewReader(string(body))),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// meteredRateLimiter reports the time the clients wait for the rate limiter.
+type meteredRateLimiter struct {
+	flowcontrol.RateLimiter
+}
+
+func (l *meteredRateLimiter) Accept() {
+	start := time.Now()
+	l.RateLimiter.Accept()
+	metrics.HandleAPIClientRateLimited(time.Since(start))
+}
+
+func (l *meteredRateLimiter) Wait(ctx context.Context) error {
+	start := time.Now()
+	err := l.RateLimiter.Wait(ctx)
+	metrics.HandleAPIClientRateLimited(time.Since(start))
+	return err
+}
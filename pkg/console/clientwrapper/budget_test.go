@@ -0,0 +1,115 @@
+package clientwrapper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+	"k8s.io/client-go/rest"
+)
+
+func TestBudget(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   BudgetOptions
+		method    string
+		path      string
+		responses []int
+		want      []int
+	}{
+		{
+			name:      "Test failed reads are not retried by default",
+			method:    http.MethodGet,
+			path:      "/api/v1/namespaces/openshift-console/configmaps/console-config",
+			responses: []int{http.StatusServiceUnavailable},
+			want:      []int{http.StatusServiceUnavailable},
+		},
+		{
+			name:      "Test throttled reads are retried",
+			options:   BudgetOptions{Retries: 2, RetryBackoff: time.Millisecond},
+			method:    http.MethodGet,
+			path:      "/api/v1/namespaces/openshift-console/configmaps/console-config",
+			responses: []int{http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusOK},
+			want:      []int{http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusOK},
+		},
+		{
+			name:      "Test reads are retried up to the budget",
+			options:   BudgetOptions{Retries: 1, RetryBackoff: time.Millisecond},
+			method:    http.MethodGet,
+			path:      "/api/v1/namespaces/openshift-console/configmaps/console-config",
+			responses: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			want:      []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+		},
+		{
+			name:      "Test missing resources are not retried",
+			options:   BudgetOptions{Retries: 2, RetryBackoff: time.Millisecond},
+			method:    http.MethodGet,
+			path:      "/api/v1/namespaces/openshift-console/configmaps/console-config",
+			responses: []int{http.StatusNotFound},
+			want:      []int{http.StatusNotFound},
+		},
+		{
+			name:      "Test writes are not retried",
+			options:   BudgetOptions{Retries: 2, RetryBackoff: time.Millisecond},
+			method:    http.MethodPut,
+			path:      "/api/v1/namespaces/openshift-console/configmaps/console-config",
+			responses: []int{http.StatusServiceUnavailable, http.StatusOK},
+			want:      []int{http.StatusServiceUnavailable},
+		},
+		{
+			name:      "Test watches within the budget are sent",
+			options:   BudgetOptions{MaxWatches: 1},
+			method:    http.MethodGet,
+			path:      "/api/v1/namespaces/openshift-console/configmaps?watch=true",
+			responses: []int{http.StatusOK},
+			want:      []int{http.StatusOK},
+		},
+		{
+			name:      "Test watches over the budget are rejected",
+			options:   BudgetOptions{MaxWatches: 1},
+			method:    http.MethodGet,
+			path:      "/api/v1/namespaces/openshift-console/configmaps?watch=true",
+			responses: []int{http.StatusOK},
+			want:      []int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []int{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				code := tt.responses[len(got)]
+				got = append(got, code)
+				w.WriteHeader(code)
+			}))
+			defer server.Close()
+
+			budget := NewBudget(tt.options)
+			config := budget.Apply(&rest.Config{Host: server.URL})
+			client, err := rest.HTTPClientFor(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.options.MaxWatches > 0 && len(tt.want) == 0 {
+				// another watch of the operator uses up the budget
+				budget.watches.Add(int64(tt.options.MaxWatches))
+			}
+			req, err := http.NewRequest(tt.method, server.URL+tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+			if len(tt.want) == 0 && resp.StatusCode != http.StatusTooManyRequests {
+				t.Errorf("expected a rejected watch to get %d, got %d", http.StatusTooManyRequests, resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package clientwrapper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"sync"
+
+	"github.com/google/go-cmp/cmp"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+// maxDryRunDiff bounds the size of the diffs included in DryRunWrite events
+const maxDryRunDiff = 1024
+
+// persistedInDryRun are the writes still persisted in dry-run mode, the status of the operator
+// config and of the ClusterOperator, for the operator to report what it would change.
+var persistedInDryRun = regexp.MustCompile(`^/apis/(operator\.openshift\.io/v1/consoles|config\.openshift\.io/v1/clusteroperators)/[^/]+/status$`)
+
+// secretPath matches the secrets API, the data of a secret never ends up in an event.
+var secretPath = regexp.MustCompile(`^/api/v1/(namespaces/[^/]+/)?secrets(/|$)`)
+
+// WithDryRun returns a copy of config whose write requests are sent with dryRun=All, so that
+// the API server validates and admits them without persisting anything. Every write that is not
+// persisted is reported as a DryRunWrite event on recorder, which must not use config, with the
+// diff between the live object and the object the API server would have persisted. Nothing is
+// persisted, so the controllers repeat the same writes on every resync: a write is only reported
+// again once its diff changes.
+func WithDryRun(config *rest.Config, recorder events.Recorder) *rest.Config {
+	dryRunConfig := rest.CopyConfig(config)
+	dryRunConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &dryRunRoundTripper{delegate: rt, recorder: recorder, reported: map[string]string{}}
+	})
+	return dryRunConfig
+}
+
+type dryRunRoundTripper struct {
+	delegate http.RoundTripper
+	recorder events.Recorder
+
+	lock sync.Mutex
+	// reported is the hash of the last change reported per write and object
+	reported map[string]string
+}
+
+func (rt *dryRunRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return rt.delegate.RoundTrip(req)
+	}
+	if persistedInDryRun.MatchString(req.URL.Path) {
+		return rt.delegate.RoundTrip(req)
+	}
+
+	dryRunReq := req.Clone(req.Context())
+	query := dryRunReq.URL.Query()
+	query.Set("dryRun", metav1.DryRunAll)
+	dryRunReq.URL.RawQuery = query.Encode()
+	// the object the API server would persist is diffed, protobuf cannot be read without its scheme
+	dryRunReq.Header.Set("Accept", "application/json")
+	klog.V(4).Infof("dry-run: %s %s", req.Method, req.URL.Path)
+	resp, err := rt.delegate.RoundTrip(dryRunReq)
+	if err != nil || resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	rt.report(req, body)
+	return resp, nil
+}
+
+// report records a DryRunWrite event for the write, unless the same change was reported for the
+// same object already.
+func (rt *dryRunRoundTripper) report(req *http.Request, dryRunBody []byte) {
+	objectPath := req.URL.Path
+	// change identifies what the write would change, diff is what the event shows of it
+	var change, diff string
+	redactedOnly := false
+	dryRun := decodeObject(dryRunBody)
+	switch {
+	case req.Method == http.MethodDelete:
+		change = "deleted"
+	case dryRun == nil:
+		// not an object, eg. the response of a subresource, only its hash is kept
+		change = string(dryRunBody)
+	default:
+		if req.Method == http.MethodPost {
+			objectPath = path.Join(objectPath, nestedString(dryRun, "metadata", "name"))
+		}
+		live, err := rt.getLive(req, objectPath)
+		if err != nil {
+			klog.V(4).Infof("dry-run: failed to get %s: %v", objectPath, err)
+		}
+		live, dryRun = withoutServerFields(live), withoutServerFields(dryRun)
+		change = cmp.Diff(live, dryRun)
+		if len(change) == 0 {
+			return
+		}
+		diff = change
+		if secretPath.MatchString(objectPath) {
+			diff = cmp.Diff(redactedSecret(live), redactedSecret(dryRun))
+			redactedOnly = len(diff) == 0
+		}
+	}
+
+	key := req.Method + " " + objectPath
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(change)))
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+	if rt.reported[key] == hash {
+		return
+	}
+	rt.reported[key] = hash
+
+	switch {
+	case redactedOnly:
+		rt.recorder.Eventf("DryRunWrite", "dry-run mode, not persisted: %s %s, only redacted secret data differs", req.Method, objectPath)
+		return
+	case len(diff) == 0:
+		rt.recorder.Eventf("DryRunWrite", "dry-run mode, not persisted: %s %s", req.Method, objectPath)
+		return
+	}
+	rt.recorder.Eventf("DryRunWrite", "dry-run mode, not persisted: %s %s:\n%s", req.Method, objectPath, truncate(diff))
+}
+
+// getLive returns the object at objectPath, nil if there is none.
+func (rt *dryRunRoundTripper) getLive(req *http.Request, objectPath string) (map[string]interface{}, error) {
+	getReq := req.Clone(req.Context())
+	getReq.Method = http.MethodGet
+	getReq.Body = nil
+	getReq.GetBody = nil
+	getReq.ContentLength = 0
+	getReq.URL.Path = objectPath
+	getReq.URL.RawPath = ""
+	getReq.URL.RawQuery = ""
+	getReq.Header.Del("Content-Type")
+	getReq.Header.Set("Accept", "application/json")
+	resp, err := rt.delegate.RoundTrip(getReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return decodeObject(body), nil
+}
+
+// decodeObject returns the JSON object of body, nil if it is not one.
+func decodeObject(body []byte) map[string]interface{} {
+	object := map[string]interface{}{}
+	if err := json.Unmarshal(body, &object); err != nil || len(object) == 0 {
+		return nil
+	}
+	return object
+}
+
+// withoutServerFields drops the fields the API server sets on every write, they would show
+// up in every diff.
+func withoutServerFields(object map[string]interface{}) map[string]interface{} {
+	if object == nil {
+		return nil
+	}
+	metadata, ok := object["metadata"].(map[string]interface{})
+	if !ok {
+		return object
+	}
+	copied := map[string]interface{}{}
+	for k, v := range object {
+		copied[k] = v
+	}
+	copiedMetadata := map[string]interface{}{}
+	for k, v := range metadata {
+		switch k {
+		case "resourceVersion", "generation", "managedFields", "uid", "creationTimestamp":
+		default:
+			copiedMetadata[k] = v
+		}
+	}
+	copied["metadata"] = copiedMetadata
+	return copied
+}
+
+// redactedSecret returns a copy of secret without the values of its data.
+func redactedSecret(secret map[string]interface{}) map[string]interface{} {
+	if secret == nil {
+		return nil
+	}
+	redacted := map[string]interface{}{}
+	for k, v := range secret {
+		redacted[k] = v
+	}
+	for _, field := range []string{"data", "stringData"} {
+		data, ok := secret[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		redactedData := map[string]interface{}{}
+		for key := range data {
+			redactedData[key] = "<redacted>"
+		}
+		redacted[field] = redactedData
+	}
+	return redacted
+}
+
+func nestedString(object map[string]interface{}, fields ...string) string {
+	for _, field := range fields[:len(fields)-1] {
+		object, _ = object[field].(map[string]interface{})
+	}
+	value, _ := object[fields[len(fields)-1]].(string)
+	return value
+}
+
+func truncate(diff string) string {
+	if len(diff) > maxDryRunDiff {
+		return diff[:maxDryRunDiff] + "\n... (truncated)"
+	}
+	return diff
+}
@@ -0,0 +1,168 @@
+package clientwrapper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	"k8s.io/client-go/rest"
+
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestWithDryRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   string
+	}{
+		{
+			name:   "Test reads are sent as is",
+			method: http.MethodGet,
+			path:   "/api/v1/namespaces/openshift-console/configmaps/console-config",
+		},
+		{
+			name:   "Test writes are sent with dryRun",
+			method: http.MethodPut,
+			path:   "/api/v1/namespaces/openshift-console/configmaps/console-config",
+			want:   "All",
+		},
+		{
+			name:   "Test deletes are sent with dryRun",
+			method: http.MethodDelete,
+			path:   "/apis/apps/v1/namespaces/openshift-console/deployments/console",
+			want:   "All",
+		},
+		{
+			name:   "Test operator config status updates are persisted",
+			method: http.MethodPut,
+			path:   "/apis/operator.openshift.io/v1/consoles/cluster/status",
+		},
+		{
+			name:   "Test operator config spec updates are sent with dryRun",
+			method: http.MethodPut,
+			path:   "/apis/operator.openshift.io/v1/consoles/cluster",
+			want:   "All",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dryRun string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				dryRun = r.URL.Query().Get("dryRun")
+			}))
+			defer server.Close()
+
+			config := WithDryRun(&rest.Config{Host: server.URL}, events.NewInMemoryRecorder("test"))
+			client, err := rest.HTTPClientFor(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req, err := http.NewRequest(tt.method, server.URL+tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if diff := deep.Equal(dryRun, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestDryRunWriteEvents(t *testing.T) {
+	configMap := func(resourceVersion, value string) string {
+		return `{"kind":"ConfigMap","metadata":{"name":"console-config","resourceVersion":"` + resourceVersion + `"},"data":{"key":"` + value + `"}}`
+	}
+	secret := func(resourceVersion, value string) string {
+		return `{"kind":"Secret","metadata":{"name":"session-secret","resourceVersion":"` + resourceVersion + `"},"data":{"key":"` + value + `"}}`
+	}
+
+	tests := []struct {
+		name string
+		path string
+		live string
+		// dry-run responses of the writes made in turn
+		dryRuns []string
+		want    []string
+	}{
+		{
+			name:    "Test write is reported with its diff",
+			path:    "/api/v1/namespaces/openshift-console/configmaps/console-config",
+			live:    configMap("1", "old"),
+			dryRuns: []string{configMap("1", "new")},
+			want:    []string{"new"},
+		},
+		{
+			name:    "Test repeated write is reported once",
+			path:    "/api/v1/namespaces/openshift-console/configmaps/console-config",
+			live:    configMap("1", "old"),
+			dryRuns: []string{configMap("1", "new"), configMap("1", "new"), configMap("1", "newer"), configMap("1", "newer")},
+			want:    []string{"new", "newer"},
+		},
+		{
+			name:    "Test write without changes is not reported",
+			path:    "/api/v1/namespaces/openshift-console/configmaps/console-config",
+			live:    configMap("1", "old"),
+			dryRuns: []string{configMap("2", "old")},
+			want:    []string{},
+		},
+		{
+			name:    "Test secret data is redacted",
+			path:    "/api/v1/namespaces/openshift-console/secrets/session-secret",
+			live:    secret("1", "old"),
+			dryRuns: []string{secret("1", "new")},
+			want:    []string{"only redacted secret data differs"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dryRun := ""
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					w.Write([]byte(tt.live))
+					return
+				}
+				w.Write([]byte(dryRun))
+			}))
+			defer server.Close()
+
+			recorder := events.NewInMemoryRecorder("test")
+			client, err := rest.HTTPClientFor(WithDryRun(&rest.Config{Host: server.URL}, recorder))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, dryRun = range tt.dryRuns {
+				req, err := http.NewRequest(http.MethodPut, server.URL+tt.path, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp, err := client.Do(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+			}
+
+			got := recorder.Events()
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d events, got %d: %v", len(tt.want), len(got), got)
+			}
+			for i, event := range got {
+				if !strings.Contains(event.Message, tt.want[i]) {
+					t.Errorf("expected event %q to contain %q", event.Message, tt.want[i])
+				}
+				if strings.Contains(tt.path, "secrets") && strings.Contains(event.Message, "new") {
+					t.Errorf("expected event %q without the secret data", event.Message)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package bluegreen
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	// kube
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	appsinformersv1 "k8s.io/client-go/informers/apps/v1"
+	coreinformersv1 "k8s.io/client-go/informers/core/v1"
+	appsclientv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	appslistersv1 "k8s.io/client-go/listers/apps/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorv1 "github.com/openshift/api/operator/v1"
+	operatorinformerv1 "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorlistersv1 "github.com/openshift/client-go/operator/listers/operator/v1"
+	routesinformersv1 "github.com/openshift/client-go/route/informers/externalversions/route/v1"
+	routev1listers "github.com/openshift/client-go/route/listers/route/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+	bluegreensub "github.com/openshift/console-operator/pkg/console/subresource/bluegreen"
+	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
+	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
+	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+// BlueGreenSyncController rolls console-config changes out blue/green when the rollout-strategy
+// annotation asks for it. The console operator holds the candidate config back from console-config
+// in the candidate ConfigMap, the green console serves it next to the console, and the console
+// route is cut over to it in a single update once it stays healthy for the blue/green duration.
+// After it served the console traffic for the same duration the candidate config is promoted to
+// console-config; until then a new value of the rollback annotation sends the traffic back to the
+// console. Either way the green console is removed and the outcome is kept in the green ConfigMap,
+// a new candidate config starts a new rollout.
+//
+//	writes:
+//	- configmaps/console-green-config -n openshift-console
+//	- services/console-green -n openshift-console
+//	- deployments/console-green -n openshift-console
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=BlueGreenSyncProgressing
+//		- type=BlueGreenSyncDegraded
+//		- type=BlueGreenProgressing
+//		- type=BlueGreenDegraded
+type BlueGreenSyncController struct {
+	operatorClient v1helpers.OperatorClient
+	// configs
+	operatorConfigLister operatorlistersv1.ConsoleLister
+	// core kube
+	configMapClient  coreclientv1.ConfigMapsGetter
+	configMapLister  corev1listers.ConfigMapLister
+	serviceClient    coreclientv1.ServicesGetter
+	deploymentClient appsclientv1.DeploymentsGetter
+	deploymentLister appslistersv1.DeploymentLister
+	// routes
+	routeLister routev1listers.RouteLister
+}
+
+func NewBlueGreenSyncController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	serviceClient coreclientv1.ServicesGetter,
+	deploymentClient appsclientv1.DeploymentsGetter,
+	// informers
+	operatorConfigInformer operatorinformerv1.ConsoleInformer,
+	configMapInformer coreinformersv1.ConfigMapInformer,
+	deploymentInformer appsinformersv1.DeploymentInformer,
+	routeInformer routesinformersv1.RouteInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &BlueGreenSyncController{
+		operatorClient:       operatorClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		configMapClient:      configMapClient,
+		configMapLister:      configMapInformer.Lister(),
+		serviceClient:        serviceClient,
+		deploymentClient:     deploymentClient,
+		deploymentLister:     deploymentInformer.Lister(),
+		routeLister:          routeInformer.Lister(),
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+		).WithFilteredEventsInformers( // the candidate config and the rollout state
+		util.IncludeNamesFilter(api.OpenShiftConsoleCandidateConfigMapName, api.OpenShiftConsoleGreenConfigMapName),
+		configMapInformer.Informer(),
+	).WithFilteredEventsInformers( // console and green deployments
+		util.IncludeNamesFilter(api.OpenShiftConsoleDeploymentName, api.OpenShiftConsoleGreenName),
+		deploymentInformer.Informer(),
+	).WithFilteredEventsInformers( // console routes sending the traffic back to the console
+		util.IncludeNamesFilter(api.OpenShiftConsoleRouteName, routesub.GetCustomRouteName(api.OpenShiftConsoleRouteName)),
+		routeInformer.Informer(),
+	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController("ConsoleBlueGreenSyncController", recorder.WithComponentSuffix("console-blue-green-controller"))
+}
+
+func (c *BlueGreenSyncController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+	operatorConfigCopy := operatorConfig.DeepCopy()
+
+	switch consolecapability.ManagementState(operatorConfigCopy) {
+	case operatorv1.Managed:
+		klog.V(4).Infoln("console is in a managed state: syncing blue/green rollout")
+	case operatorv1.Unmanaged:
+		klog.V(4).Infoln("console is in an unmanaged state: skipping blue/green rollout sync")
+		return nil
+	case operatorv1.Removed:
+		klog.V(4).Infoln("console is in a removed state: the teardown controller deletes the green console")
+		return nil
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfigCopy.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+
+	state, greenDeployment, reason, err := c.SyncBlueGreen(ctx, operatorConfigCopy, controllerContext.Recorder())
+	statusHandler.AddConditions(status.HandleProgressingOrDegraded("BlueGreenSync", reason, err))
+	statusHandler.AddCondition(status.HandleProgressing("BlueGreen", "InProgress", state.ProgressingErr()))
+	statusHandler.AddCondition(status.HandleDegraded("BlueGreen", "RolledBack", state.RolledBackErr()))
+	if greenDeployment != nil {
+		statusHandler.UpdateDeploymentGeneration(greenDeployment)
+	}
+	return statusHandler.FlushAndReturn(err)
+}
+
+// SyncBlueGreen runs the green console of the candidate config next to the console deployment,
+// the rollout waits for the console deployment to exist.
+func (c *BlueGreenSyncController) SyncBlueGreen(
+	ctx context.Context,
+	operatorConfig *operatorv1.Console,
+	recorder events.Recorder,
+) (state bluegreensub.State, greenDeployment *appsv1.Deployment, reason string, err error) {
+	blueGreenConfig := utilsub.GetBlueGreenConfig(operatorConfig)
+	if blueGreenConfig == nil {
+		if err := c.removeBlueGreen(ctx, true); err != nil {
+			return state, nil, "FailedDelete", err
+		}
+		return state, nil, "", nil
+	}
+
+	consoleDeployment, err := c.deploymentLister.Deployments(api.TargetNamespace).Get(api.OpenShiftConsoleDeploymentName)
+	if apierrors.IsNotFound(err) {
+		klog.V(4).Infof("waiting for the console deployment before rolling out blue/green")
+		return state, nil, "", nil
+	}
+	if err != nil {
+		return state, nil, "FailedGet", err
+	}
+	candidateConfigMap, err := c.configMapLister.ConfigMaps(api.TargetNamespace).Get(api.OpenShiftConsoleCandidateConfigMapName)
+	if apierrors.IsNotFound(err) {
+		candidateConfigMap = nil
+	} else if err != nil {
+		return state, nil, "FailedGet", err
+	}
+	existingConfigMap, err := c.configMapLister.ConfigMaps(api.TargetNamespace).Get(api.OpenShiftConsoleGreenConfigMapName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return state, nil, "FailedGet", err
+	}
+	previousState := bluegreensub.GetState(existingConfigMap)
+	state = previousState
+	var candidateData map[string]string
+	switch {
+	case candidateConfigMap != nil:
+		candidateConfig := bluegreensub.CandidateConfig(candidateConfigMap)
+		if state.Candidate != bluegreensub.CandidateHash(candidateConfig) {
+			state = bluegreensub.NewState(candidateConfig, blueGreenConfig.Rollback, time.Now())
+		}
+		candidateData = candidateConfigMap.Data
+	case state.Phase == bluegreensub.PhasePromoting:
+		// console-config holds the promoted config, the green console keeps serving it
+		candidateData = existingConfigMap.Data
+	case state.Serving():
+		// console-config went back to the config the console serves, there is nothing to roll out
+		state = bluegreensub.State{Rollback: state.Rollback}
+	}
+
+	greenConfigMap, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, recorder, bluegreensub.DefaultConfigMap(operatorConfig, candidateData, state))
+	if err != nil {
+		return previousState, nil, "FailedApply", err
+	}
+
+	if state.Serving() {
+		if _, _, err := resourceapply.ApplyService(ctx, c.serviceClient, recorder, bluegreensub.DefaultService(operatorConfig)); err != nil {
+			return state, nil, "FailedApplyService", err
+		}
+		requiredDeployment := deploymentsub.GreenDeployment(consoleDeployment, greenConfigMap)
+		greenDeployment, _, err = resourceapply.ApplyDeployment(
+			ctx,
+			c.deploymentClient,
+			recorder,
+			requiredDeployment,
+			resourcemerge.ExpectedDeploymentGeneration(requiredDeployment, operatorConfig.Status.Generations),
+		)
+		if err != nil {
+			return state, nil, "FailedApplyDeployment", err
+		}
+		nextState := bluegreensub.NextState(state, greenDeployment, consoleDeployment, blueGreenConfig.Rollback, blueGreenConfig.Duration, time.Now())
+		if nextState.Phase != state.Phase {
+			if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, recorder, bluegreensub.DefaultConfigMap(operatorConfig, candidateData, nextState)); err != nil {
+				return state, greenDeployment, "FailedApply", err
+			}
+		}
+		state = nextState
+	}
+
+	if state.Phase != previousState.Phase || state.Candidate != previousState.Candidate {
+		switch state.Phase {
+		case bluegreensub.PhaseVerifying:
+			recorder.Eventf("ConsoleBlueGreenStarted", "green console serving the candidate console config is verified for %s", blueGreenConfig.Duration)
+		case bluegreensub.PhaseCutOver:
+			recorder.Eventf("ConsoleBlueGreenCutOver", "console route cut over to the green console: %s", state.Message)
+		case bluegreensub.PhasePromoting:
+			recorder.Eventf("ConsoleBlueGreenPromoting", "candidate console config promoted to console-config: %s", state.Message)
+		case bluegreensub.PhaseCompleted:
+			recorder.Eventf("ConsoleBlueGreenCompleted", "blue/green rollout of the candidate console config completed: %s", state.Message)
+		case bluegreensub.PhaseRolledBack:
+			recorder.Warningf("ConsoleBlueGreenRolledBack", "candidate console config rolled back: %s", state.Message)
+		}
+	}
+
+	if state.Serving() {
+		return state, greenDeployment, "", nil
+	}
+	// the green console keeps running until the route controller sent the traffic back to the console
+	if c.routesToGreen() {
+		return state, nil, "", nil
+	}
+	if err := c.removeBlueGreen(ctx, false); err != nil {
+		return state, nil, "FailedDelete", err
+	}
+	return state, nil, "", nil
+}
+
+// removeBlueGreen deletes the green deployment and service. The green ConfigMap holding the outcome
+// of the last rollout is only deleted along with the rollout-strategy annotation.
+func (c *BlueGreenSyncController) removeBlueGreen(ctx context.Context, includeConfigMap bool) error {
+	errs := []error{
+		c.deploymentClient.Deployments(api.TargetNamespace).Delete(ctx, api.OpenShiftConsoleGreenName, metav1.DeleteOptions{}),
+		c.serviceClient.Services(api.TargetNamespace).Delete(ctx, api.OpenShiftConsoleGreenName, metav1.DeleteOptions{}),
+	}
+	if includeConfigMap {
+		errs = append(errs, c.configMapClient.ConfigMaps(api.TargetNamespace).Delete(ctx, api.OpenShiftConsoleGreenConfigMapName, metav1.DeleteOptions{}))
+	}
+	return utilerrors.FilterOut(utilerrors.NewAggregate(errs), apierrors.IsNotFound)
+}
+
+// routesToGreen is true while a console route still sends the traffic to the green console.
+func (c *BlueGreenSyncController) routesToGreen() bool {
+	for _, routeName := range []string{api.OpenShiftConsoleRouteName, routesub.GetCustomRouteName(api.OpenShiftConsoleRouteName)} {
+		route, err := c.routeLister.Routes(api.TargetNamespace).Get(routeName)
+		if err == nil && route.Spec.To.Name == api.OpenShiftConsoleGreenName {
+			return true
+		}
+	}
+	return false
+}
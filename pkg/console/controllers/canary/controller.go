@@ -0,0 +1,258 @@
+package canary
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	// kube
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	appsinformersv1 "k8s.io/client-go/informers/apps/v1"
+	coreinformersv1 "k8s.io/client-go/informers/core/v1"
+	appsclientv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	appslistersv1 "k8s.io/client-go/listers/apps/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorv1 "github.com/openshift/api/operator/v1"
+	operatorinformerv1 "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorlistersv1 "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+	canarysub "github.com/openshift/console-operator/pkg/console/subresource/canary"
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
+	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
+	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+// CanarySyncController serves the candidate config of the canary-config-overrides annotation from
+// a single replica canary deployment, which the console route sends a share of the traffic to once
+// its pods are ready. The canary is promoted once it stays healthy for the canary duration and
+// rolled back otherwise. Either way the canary deployment is removed and the outcome is kept in the
+// canary ConfigMap, the console operator renders promoted overrides into console-config and a new
+// candidate config starts a new canary.
+//
+//	writes:
+//	- configmaps/console-canary-config -n openshift-console
+//	- services/console-canary -n openshift-console
+//	- deployments/console-canary -n openshift-console
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=CanarySyncProgressing
+//		- type=CanarySyncDegraded
+//		- type=CanaryProgressing
+//		- type=CanaryDegraded
+type CanarySyncController struct {
+	operatorClient v1helpers.OperatorClient
+	// configs
+	operatorConfigLister operatorlistersv1.ConsoleLister
+	// core kube
+	configMapClient  coreclientv1.ConfigMapsGetter
+	configMapLister  corev1listers.ConfigMapLister
+	serviceClient    coreclientv1.ServicesGetter
+	deploymentClient appsclientv1.DeploymentsGetter
+	deploymentLister appslistersv1.DeploymentLister
+}
+
+func NewCanarySyncController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	serviceClient coreclientv1.ServicesGetter,
+	deploymentClient appsclientv1.DeploymentsGetter,
+	// informers
+	operatorConfigInformer operatorinformerv1.ConsoleInformer,
+	configMapInformer coreinformersv1.ConfigMapInformer,
+	deploymentInformer appsinformersv1.DeploymentInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &CanarySyncController{
+		operatorClient:       operatorClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		configMapClient:      configMapClient,
+		configMapLister:      configMapInformer.Lister(),
+		serviceClient:        serviceClient,
+		deploymentClient:     deploymentClient,
+		deploymentLister:     deploymentInformer.Lister(),
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+		).WithFilteredEventsInformers( // console-config and the canary state
+		util.IncludeNamesFilter(api.OpenShiftConsoleConfigMapName, api.OpenShiftConsoleCanaryConfigMapName),
+		configMapInformer.Informer(),
+	).WithFilteredEventsInformers( // console and canary deployments
+		util.IncludeNamesFilter(api.OpenShiftConsoleDeploymentName, api.OpenShiftConsoleCanaryName),
+		deploymentInformer.Informer(),
+	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController("ConsoleCanarySyncController", recorder.WithComponentSuffix("console-canary-controller"))
+}
+
+func (c *CanarySyncController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+	operatorConfigCopy := operatorConfig.DeepCopy()
+
+	switch consolecapability.ManagementState(operatorConfigCopy) {
+	case operatorv1.Managed:
+		klog.V(4).Infoln("console is in a managed state: syncing canary")
+	case operatorv1.Unmanaged:
+		klog.V(4).Infoln("console is in an unmanaged state: skipping canary sync")
+		return nil
+	case operatorv1.Removed:
+		klog.V(4).Infoln("console is in a removed state: the teardown controller deletes the canary")
+		return nil
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfigCopy.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+
+	// the canary never blocks the console, a failed canary only discards the candidate config
+	state, canaryDeployment, reason, err := c.SyncCanary(ctx, operatorConfigCopy, controllerContext.Recorder())
+	statusHandler.AddConditions(status.HandleProgressingOrDegraded("CanarySync", reason, err))
+	statusHandler.AddCondition(status.HandleProgressing("Canary", "InProgress", state.ProgressingErr()))
+	statusHandler.AddCondition(status.HandleDegraded("Canary", "RolledBack", state.RolledBackErr()))
+	if canaryDeployment != nil {
+		statusHandler.UpdateDeploymentGeneration(canaryDeployment)
+	}
+	return statusHandler.FlushAndReturn(err)
+}
+
+// SyncCanary runs the canary of the candidate config next to the console deployment, the canary
+// waits for console-config and the console deployment to exist.
+func (c *CanarySyncController) SyncCanary(
+	ctx context.Context,
+	operatorConfig *operatorv1.Console,
+	recorder events.Recorder,
+) (state canarysub.State, canaryDeployment *appsv1.Deployment, reason string, err error) {
+	canaryConfig := utilsub.GetCanaryConfig(operatorConfig)
+	if canaryConfig == nil {
+		if err := c.removeCanary(ctx, true); err != nil {
+			return state, nil, "FailedDelete", err
+		}
+		return state, nil, "", nil
+	}
+
+	consoleConfigMap, err := c.configMapLister.ConfigMaps(api.TargetNamespace).Get(api.OpenShiftConsoleConfigMapName)
+	if apierrors.IsNotFound(err) {
+		klog.V(4).Infof("waiting for the console config before starting the canary")
+		return state, nil, "", nil
+	}
+	if err != nil {
+		return state, nil, "FailedGet", err
+	}
+	consoleDeployment, err := c.deploymentLister.Deployments(api.TargetNamespace).Get(api.OpenShiftConsoleDeploymentName)
+	if apierrors.IsNotFound(err) {
+		klog.V(4).Infof("waiting for the console deployment before starting the canary")
+		return state, nil, "", nil
+	}
+	if err != nil {
+		return state, nil, "FailedGet", err
+	}
+
+	existingConfigMap, err := c.configMapLister.ConfigMaps(api.TargetNamespace).Get(api.OpenShiftConsoleCanaryConfigMapName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return state, nil, "FailedGet", err
+	}
+	previousState := canarysub.GetState(existingConfigMap)
+	state = previousState
+	if state.Candidate != canarysub.CandidateHash(canaryConfig.Overrides) {
+		state = canarysub.NewState(canaryConfig.Overrides, time.Now())
+	}
+	state.Weight = canaryConfig.Weight
+
+	var candidateConfig []byte
+	if state.Phase == canarysub.PhaseProgressing {
+		merger := &consoleserver.ConsoleYAMLMerger{}
+		candidateConfig, _, err = merger.MergeOverrides([]byte(consoleConfigMap.Data["console-config.yaml"]), canaryConfig.Overrides)
+		if err == nil {
+			err = consoleserver.ValidateConfigYAML(candidateConfig)
+		}
+		if err != nil {
+			state.Phase = canarysub.PhaseRolledBack
+			state.Message = fmt.Sprintf("invalid candidate config: %v", err)
+		}
+	}
+
+	canaryConfigMap, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, recorder, canarysub.DefaultConfigMap(operatorConfig, candidateConfig, state))
+	if err != nil {
+		return previousState, nil, "FailedApply", err
+	}
+
+	if state.Phase == canarysub.PhaseProgressing {
+		if _, _, err := resourceapply.ApplyService(ctx, c.serviceClient, recorder, canarysub.DefaultService(operatorConfig)); err != nil {
+			return state, nil, "FailedApplyService", err
+		}
+		requiredDeployment := deploymentsub.CanaryDeployment(consoleDeployment, canaryConfigMap)
+		canaryDeployment, _, err = resourceapply.ApplyDeployment(
+			ctx,
+			c.deploymentClient,
+			recorder,
+			requiredDeployment,
+			resourcemerge.ExpectedDeploymentGeneration(requiredDeployment, operatorConfig.Status.Generations),
+		)
+		if err != nil {
+			return state, nil, "FailedApplyDeployment", err
+		}
+		nextState := canarysub.NextState(state, canaryDeployment, canaryConfig.Duration, time.Now())
+		// the readiness of the canary is recorded too, the route controller only sends traffic to
+		// a ready canary and a canary losing its ready pods is rolled back
+		if nextState.Phase != state.Phase || nextState.Ready != state.Ready {
+			if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, recorder, canarysub.DefaultConfigMap(operatorConfig, candidateConfig, nextState)); err != nil {
+				return previousState, canaryDeployment, "FailedApply", err
+			}
+		}
+		state = nextState
+	}
+
+	if state.Phase != previousState.Phase || state.Candidate != previousState.Candidate {
+		switch state.Phase {
+		case canarysub.PhaseProgressing:
+			recorder.Eventf("ConsoleCanaryStarted", "canary of the candidate console config is serving %d%% of the console traffic", state.Weight)
+		case canarysub.PhasePromoted:
+			recorder.Eventf("ConsoleCanaryPromoted", "candidate console config promoted: %s", state.Message)
+		case canarysub.PhaseRolledBack:
+			recorder.Warningf("ConsoleCanaryRolledBack", "candidate console config rolled back: %s", state.Message)
+		}
+	}
+
+	if state.Phase != canarysub.PhaseProgressing {
+		if err := c.removeCanary(ctx, false); err != nil {
+			return state, nil, "FailedDelete", err
+		}
+		return state, nil, "", nil
+	}
+	return state, canaryDeployment, "", nil
+}
+
+// removeCanary deletes the canary deployment and service. The canary ConfigMap holding the outcome
+// of the last canary is only deleted along with the canary annotations.
+func (c *CanarySyncController) removeCanary(ctx context.Context, includeConfigMap bool) error {
+	errs := []error{
+		c.deploymentClient.Deployments(api.TargetNamespace).Delete(ctx, api.OpenShiftConsoleCanaryName, metav1.DeleteOptions{}),
+		c.serviceClient.Services(api.TargetNamespace).Delete(ctx, api.OpenShiftConsoleCanaryName, metav1.DeleteOptions{}),
+	}
+	if includeConfigMap {
+		errs = append(errs, c.configMapClient.ConfigMaps(api.TargetNamespace).Delete(ctx, api.OpenShiftConsoleCanaryConfigMapName, metav1.DeleteOptions{}))
+	}
+	return utilerrors.FilterOut(utilerrors.NewAggregate(errs), apierrors.IsNotFound)
+}
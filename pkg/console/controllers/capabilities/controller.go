@@ -0,0 +1,147 @@
+package capabilities
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	// kube
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authorizationclientv1 "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+)
+
+const (
+	// conditions are informative only, a disabled controller does not degrade the operator
+	conditionTypeSuffix      = "Disabled"
+	missingPermissionsReason = "MissingPermissions"
+)
+
+// OptionalController is a controller the operator can run without. It is only started if
+// the operator service account holds every permission in Requires.
+type OptionalController struct {
+	// Name is used as the condition type prefix, eg. PodDisruptionBudgetControllerDisabled
+	Name     string
+	Requires []authorizationv1.ResourceAttributes
+}
+
+// Result maps each optional controller name to the permissions it is missing.
+// A controller with no missing permissions is enabled.
+type Result map[string][]string
+
+func (r Result) Enabled(name string) bool {
+	return len(r[name]) == 0
+}
+
+// CheckPermissions asks the API server which of the permissions needed by the optional
+// controllers are granted to the operator. Errors while checking are treated as granted
+// so that a flaky API server cannot silently switch controllers off.
+func CheckPermissions(ctx context.Context, client authorizationclientv1.SelfSubjectAccessReviewInterface, controllers []OptionalController) Result {
+	result := Result{}
+	for _, controller := range controllers {
+		missing := []string{}
+		for i := range controller.Requires {
+			attributes := controller.Requires[i]
+			review, err := client.Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+				Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attributes},
+			}, metav1.CreateOptions{})
+			if err != nil {
+				klog.Warningf("unable to check permission %s for %s, assuming it is granted: %v", describe(attributes), controller.Name, err)
+				continue
+			}
+			if !review.Status.Allowed {
+				missing = append(missing, describe(attributes))
+			}
+		}
+		sort.Strings(missing)
+		result[controller.Name] = missing
+		if len(missing) > 0 {
+			klog.Infof("disabling %s, missing permissions: %s", controller.Name, strings.Join(missing, ", "))
+		}
+	}
+	return result
+}
+
+func describe(attributes authorizationv1.ResourceAttributes) string {
+	resource := attributes.Resource
+	if len(attributes.Group) > 0 {
+		resource = fmt.Sprintf("%s.%s", attributes.Resource, attributes.Group)
+	}
+	if len(attributes.Namespace) > 0 {
+		return fmt.Sprintf("%s %s in %s", attributes.Verb, resource, attributes.Namespace)
+	}
+	return fmt.Sprintf("%s %s", attributes.Verb, resource)
+}
+
+// CapabilitiesController reports which optional controllers were disabled at startup
+// because the operator runs under a restricted role.
+//
+//	writes:
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=<OptionalController.Name>Disabled
+type CapabilitiesController struct {
+	operatorClient v1helpers.OperatorClient
+	result         Result
+}
+
+func NewCapabilitiesController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	// informers
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	// capabilities detected at startup
+	result Result,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &CapabilitiesController{
+		operatorClient: operatorClient,
+		result:         result,
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+		).ResyncEvery(10*time.Minute).WithSync(ctrl.Sync).
+		ToController("CapabilitiesController", recorder.WithComponentSuffix("capabilities-controller"))
+}
+
+func (c *CapabilitiesController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	for name, missing := range c.result {
+		statusHandler.AddCondition(handleDisabled(name, missing))
+	}
+	return statusHandler.FlushAndReturn(nil)
+}
+
+func handleDisabled(name string, missing []string) status.ConditionUpdate {
+	condition := operatorsv1.OperatorCondition{
+		Type:   name + conditionTypeSuffix,
+		Status: operatorsv1.ConditionFalse,
+	}
+	if len(missing) > 0 {
+		condition.Status = operatorsv1.ConditionTrue
+		condition.Reason = missingPermissionsReason
+		condition.Message = fmt.Sprintf("the operator is missing permissions: %s", strings.Join(missing, ", "))
+	}
+	return status.ConditionUpdate{
+		ConditionType:  condition.Type,
+		StatusUpdateFn: v1helpers.UpdateConditionFn(condition),
+	}
+}
@@ -0,0 +1,93 @@
+package capabilities
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-test/deep"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestCheckPermissions(t *testing.T) {
+	controllers := []OptionalController{
+		{
+			Name: "FooController",
+			Requires: []authorizationv1.ResourceAttributes{
+				{Group: "console.openshift.io", Resource: "foos", Verb: "list"},
+				{Group: "console.openshift.io", Resource: "foos", Verb: "create"},
+			},
+		},
+		{
+			Name: "BarController",
+			Requires: []authorizationv1.ResourceAttributes{
+				{Group: "policy", Resource: "bars", Verb: "delete", Namespace: "openshift-console"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		allowed func(attributes *authorizationv1.ResourceAttributes) (bool, error)
+		want    Result
+	}{
+		{
+			name: "Test all permissions granted",
+			allowed: func(attributes *authorizationv1.ResourceAttributes) (bool, error) {
+				return true, nil
+			},
+			want: Result{
+				"FooController": {},
+				"BarController": {},
+			},
+		},
+		{
+			name: "Test missing permissions",
+			allowed: func(attributes *authorizationv1.ResourceAttributes) (bool, error) {
+				return attributes.Verb == "list", nil
+			},
+			want: Result{
+				"FooController": {"create foos.console.openshift.io"},
+				"BarController": {"delete bars.policy in openshift-console"},
+			},
+		},
+		{
+			name: "Test failed review is treated as granted",
+			allowed: func(attributes *authorizationv1.ResourceAttributes) (bool, error) {
+				return false, errors.New("the server is currently unable to handle the request")
+			},
+			want: Result{
+				"FooController": {},
+				"BarController": {},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			client.PrependReactor("create", "selfsubjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+				allowed, err := tt.allowed(review.Spec.ResourceAttributes)
+				if err != nil {
+					return true, nil, err
+				}
+				review.Status.Allowed = allowed
+				return true, review, nil
+			})
+
+			got := CheckPermissions(context.TODO(), client.AuthorizationV1().SelfSubjectAccessReviews(), controllers)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+			for name, missing := range tt.want {
+				if diff := deep.Equal(got.Enabled(name), len(missing) == 0); diff != nil {
+					t.Error(diff)
+				}
+			}
+		})
+	}
+}
@@ -37,6 +37,7 @@ import (
 
 	// operator
 	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
 	controllersutil "github.com/openshift/console-operator/pkg/console/controllers/util"
 	"github.com/openshift/console-operator/pkg/console/status"
 	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
@@ -100,7 +101,7 @@ func (c *CLIDownloadsSyncController) Sync(ctx context.Context, controllerContext
 	}
 	updatedOperatorConfig := operatorConfig.DeepCopy()
 
-	switch updatedOperatorConfig.Spec.ManagementState {
+	switch consolecapability.ManagementState(updatedOperatorConfig) {
 	case operatorsv1.Managed:
 		klog.V(4).Infoln("console is in a managed state: syncing ConsoleCliDownloads custom resources")
 	case operatorsv1.Unmanaged:
@@ -0,0 +1,276 @@
+package clusterproxy
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	// kube
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/metrics"
+	"github.com/openshift/console-operator/pkg/console/status"
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+)
+
+const (
+	// probeTimeout bounds the probe of a single endpoint, endpoints are probed in parallel
+	probeTimeout = 5 * time.Second
+	// unhealthyAfter is how long an endpoint fails its probes before its cluster is left out of
+	// console-config, so that a blip does not roll out the console twice
+	unhealthyAfter = 3 * time.Minute
+)
+
+// ClusterProxyHealthController probes the cluster-proxy websocket endpoints of the managed
+// clusters configured by the cluster-proxy-config annotation, so that an unreachable managed
+// cluster is reported before users open it. The outcome is kept in the cluster-proxy-health
+// ConfigMap, clusters failing for longer than unhealthyAfter are left out of the switcher when
+// console-config is rendered and listed as unhealthy instead.
+//
+//	writes:
+//	- configmaps openshift-console/cluster-proxy-health
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=ClusterProxyHealthDegraded
+type ClusterProxyHealthController struct {
+	operatorClient          v1helpers.OperatorClient
+	operatorConfigLister    operatorv1listers.ConsoleLister
+	configMapClient         coreclientv1.ConfigMapsGetter
+	configNSConfigMapLister corev1listers.ConfigMapLister
+	targetNSConfigMapLister corev1listers.ConfigMapLister
+}
+
+func NewClusterProxyHealthController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	// informers
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	configNSConfigMapInformer corev1informers.ConfigMapInformer,
+	targetNSConfigMapInformer corev1informers.ConfigMapInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &ClusterProxyHealthController{
+		operatorClient:          operatorClient,
+		operatorConfigLister:    operatorConfigInformer.Lister(),
+		configMapClient:         configMapClient,
+		configNSConfigMapLister: configNSConfigMapInformer.Lister(),
+		targetNSConfigMapLister: targetNSConfigMapInformer.Lister(),
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+			// the probes run on resync, writing cluster-proxy-health must not trigger another round
+		).WithBareInformers(
+		configNSConfigMapInformer.Informer(),
+		targetNSConfigMapInformer.Informer(),
+	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController("ClusterProxyHealthController", recorder.WithComponentSuffix("cluster-proxy-health-controller"))
+}
+
+func (c *ClusterProxyHealthController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch consolecapability.ManagementState(operatorConfig) {
+	case operatorsv1.Managed:
+		klog.V(4).Infoln("console-operator is in a managed state: probing cluster-proxy endpoints")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infoln("console-operator is in an unmanaged state: skipping cluster-proxy probes")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infoln("console-operator is in a removed state: deleting cluster-proxy health")
+		return c.removeClusterProxyHealth(ctx)
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	reason, healthErr := c.checkClusterProxies(ctx, operatorConfig, controllerContext.Recorder())
+	statusHandler.AddCondition(status.HandleDegraded("ClusterProxyHealth", reason, healthErr))
+	return statusHandler.FlushAndReturn(nil)
+}
+
+func (c *ClusterProxyHealthController) checkClusterProxies(ctx context.Context, operatorConfig *operatorsv1.Console, recorder events.Recorder) (string, error) {
+	name, ok := operatorConfig.Annotations[api.ClusterProxyConfigAnnotation]
+	if !ok {
+		return "", c.removeClusterProxyHealth(ctx)
+	}
+	clusterProxyConfigMap, err := c.configNSConfigMapLister.ConfigMaps(api.OpenShiftConfigNamespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		// reported by the ClusterProxyConfig condition of the console sync loop
+		return "", c.removeClusterProxyHealth(ctx)
+	}
+	if err != nil {
+		return "FailedGet", err
+	}
+	// invalid clusters are reported by the console sync loop as well, only the valid ones are rendered
+	clusters, _ := configmapsub.ValidateClusterProxyConfig(clusterProxyConfigMap.Data)
+
+	probeErrs := make([]error, len(clusters))
+	var wg sync.WaitGroup
+	for i, cluster := range clusters {
+		client, err := clientWithCA(cluster.CABundle())
+		if err != nil {
+			probeErrs[i] = err
+			continue
+		}
+		wg.Add(1)
+		go func(i int, cluster configmapsub.ClusterProxyCluster) {
+			defer wg.Done()
+			probeErrs[i] = probeEndpoint(ctx, client, cluster.Endpoint)
+		}(i, cluster)
+	}
+	wg.Wait()
+
+	health := nextClusterProxyHealth(c.previousClusterProxyHealth(), clusters, probeErrs, time.Now())
+	required, err := configmapsub.DefaultClusterProxyHealthConfigMap(health)
+	if err != nil {
+		return "FailedRender", err
+	}
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, recorder, required); err != nil {
+		return "FailedApply", err
+	}
+
+	healthy := map[string]bool{}
+	unreachable := []string{}
+	for i, cluster := range clusters {
+		healthy[cluster.Name] = probeErrs[i] == nil
+		if probeErrs[i] != nil {
+			unreachable = append(unreachable, fmt.Sprintf("%s: %v", cluster.Name, probeErrs[i]))
+		}
+	}
+	metrics.HandleClusterProxyHealth(healthy)
+	if len(unreachable) > 0 {
+		return "UnreachableEndpoints", fmt.Errorf("cluster-proxy endpoints of %d of %d managed clusters are unreachable: %s", len(unreachable), len(clusters), strings.Join(unreachable, "; "))
+	}
+	return "", nil
+}
+
+// previousClusterProxyHealth returns the outcome of the previous probes, nothing if there is none
+// or it cannot be read, the grace period of failing endpoints starts over then.
+func (c *ClusterProxyHealthController) previousClusterProxyHealth() []configmapsub.ClusterProxyHealth {
+	healthConfigMap, err := c.targetNSConfigMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(api.ClusterProxyHealthConfigMapName)
+	if err != nil {
+		return nil
+	}
+	health, err := configmapsub.ReadClusterProxyHealth(healthConfigMap.Data)
+	if err != nil {
+		klog.V(4).Infof("ignoring previous cluster-proxy health: %v", err)
+		return nil
+	}
+	return health
+}
+
+func (c *ClusterProxyHealthController) removeClusterProxyHealth(ctx context.Context) error {
+	metrics.HandleClusterProxyHealth(nil)
+	err := c.configMapClient.ConfigMaps(api.OpenShiftConsoleNamespace).Delete(ctx, api.ClusterProxyHealthConfigMapName, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// nextClusterProxyHealth folds the probe results of clusters into their previous health. An
+// endpoint turns unhealthy once it has been failing for unhealthyAfter, and healthy again on its
+// first successful probe. The previous results of a cluster only count for the same endpoint.
+func nextClusterProxyHealth(previous []configmapsub.ClusterProxyHealth, clusters []configmapsub.ClusterProxyCluster, probeErrs []error, now time.Time) []configmapsub.ClusterProxyHealth {
+	previousByName := map[string]configmapsub.ClusterProxyHealth{}
+	for _, clusterHealth := range previous {
+		previousByName[clusterHealth.Name] = clusterHealth
+	}
+
+	health := make([]configmapsub.ClusterProxyHealth, 0, len(clusters))
+	for i, cluster := range clusters {
+		clusterHealth := configmapsub.ClusterProxyHealth{Name: cluster.Name, Endpoint: cluster.Endpoint, Healthy: true}
+		if probeErrs[i] != nil {
+			failingSince := metav1.NewTime(now)
+			if last, ok := previousByName[cluster.Name]; ok && last.Endpoint == cluster.Endpoint && last.FailingSince != nil {
+				failingSince = *last.FailingSince
+			}
+			clusterHealth.FailingSince = &failingSince
+			clusterHealth.Healthy = now.Sub(failingSince.Time) < unhealthyAfter
+			clusterHealth.Message = probeErrs[i].Error()
+		}
+		health = append(health, clusterHealth)
+	}
+	return health
+}
+
+// probeEndpoint checks that a cluster-proxy websocket endpoint answers over TLS. A plain GET is
+// not a websocket upgrade, any answer but a server error means the endpoint is up.
+func probeEndpoint(ctx context.Context, client *http.Client, endpoint string) error {
+	probeURL, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	switch probeURL.Scheme {
+	case "wss":
+		probeURL.Scheme = "https"
+	case "ws":
+		probeURL.Scheme = "http"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probeURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%s returns '%s'", probeURL, resp.Status)
+	}
+	return nil
+}
+
+// clientWithCA trusts caBundle, or the system roots if it is empty.
+func clientWithCA(caBundle string) (*http.Client, error) {
+	tlsConfig := &tls.Config{}
+	if len(caBundle) > 0 {
+		caPool := x509.NewCertPool()
+		if ok := caPool.AppendCertsFromPEM([]byte(caBundle)); !ok {
+			return nil, fmt.Errorf("failed to parse CA bundle")
+		}
+		tlsConfig.RootCAs = caPool
+	}
+	return &http.Client{
+		Timeout: probeTimeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+	}, nil
+}
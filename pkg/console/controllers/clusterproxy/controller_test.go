@@ -0,0 +1,95 @@
+package clusterproxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+)
+
+func TestProbeEndpoint(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		closed     bool
+		wantErr    bool
+	}{
+		{
+			name:       "Test endpoint refusing a plain GET is up",
+			statusCode: http.StatusBadRequest,
+			wantErr:    false,
+		},
+		{
+			name:       "Test endpoint returning a server error",
+			statusCode: http.StatusServiceUnavailable,
+			wantErr:    true,
+		},
+		{
+			name:    "Test unreachable endpoint",
+			closed:  true,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer server.Close()
+			endpoint := "wss://" + strings.TrimPrefix(server.URL, "https://") + "/cluster-proxy/east"
+			if tt.closed {
+				server.Close()
+			}
+
+			err := probeEndpoint(context.TODO(), server.Client(), endpoint)
+			if diff := deep.Equal(err != nil, tt.wantErr); diff != nil {
+				t.Errorf("%v: %v", diff, err)
+			}
+		})
+	}
+}
+
+func TestNextClusterProxyHealth(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	since := func(d time.Duration) *metav1.Time {
+		failingSince := metav1.NewTime(now.Add(-d))
+		return &failingSince
+	}
+	clusters := []configmapsub.ClusterProxyCluster{
+		{Name: "east", Endpoint: "wss://proxy.east.example.com/cluster-proxy"},
+		{Name: "north", Endpoint: "wss://proxy.north.example.com/cluster-proxy"},
+		{Name: "south", Endpoint: "wss://proxy.south.example.com/cluster-proxy"},
+		{Name: "west", Endpoint: "wss://proxy.west.example.com/cluster-proxy"},
+	}
+	previous := []configmapsub.ClusterProxyHealth{
+		{Name: "east", Endpoint: "wss://proxy.east.example.com/cluster-proxy", FailingSince: since(10 * time.Minute), Message: "connection refused"},
+		{Name: "north", Endpoint: "wss://proxy.north.example.com/cluster-proxy", Healthy: true, FailingSince: since(time.Minute)},
+		// the endpoint moved, the grace period starts over
+		{Name: "south", Endpoint: "wss://proxy-old.south.example.com/cluster-proxy", FailingSince: since(10 * time.Minute)},
+		{Name: "west", Endpoint: "wss://proxy.west.example.com/cluster-proxy", FailingSince: since(10 * time.Minute)},
+	}
+	probeErrs := []error{
+		fmt.Errorf("connection refused"),
+		fmt.Errorf("i/o timeout"),
+		fmt.Errorf("no route to host"),
+		nil,
+	}
+
+	want := []configmapsub.ClusterProxyHealth{
+		{Name: "east", Endpoint: "wss://proxy.east.example.com/cluster-proxy", FailingSince: since(10 * time.Minute), Message: "connection refused"},
+		{Name: "north", Endpoint: "wss://proxy.north.example.com/cluster-proxy", Healthy: true, FailingSince: since(time.Minute), Message: "i/o timeout"},
+		{Name: "south", Endpoint: "wss://proxy.south.example.com/cluster-proxy", Healthy: true, FailingSince: since(0), Message: "no route to host"},
+		{Name: "west", Endpoint: "wss://proxy.west.example.com/cluster-proxy", Healthy: true},
+	}
+	if diff := deep.Equal(nextClusterProxyHealth(previous, clusters, probeErrs, now), want); diff != nil {
+		t.Error(diff)
+	}
+}
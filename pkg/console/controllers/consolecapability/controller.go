@@ -0,0 +1,134 @@
+package consolecapability
+
+import (
+	"context"
+	"time"
+
+	// kube
+
+	// openshift
+	configv1 "github.com/openshift/api/config/v1"
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	configinformer "github.com/openshift/client-go/config/informers/externalversions"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+)
+
+const (
+	conditionType            = "ConsoleCapabilityDisabled"
+	capabilityDisabledReason = "CapabilityDisabled"
+)
+
+// ConsoleCapabilityController follows the Console cluster capability and reports it in the
+// ConsoleCapabilityDisabled condition. While the capability is disabled every controller follows
+// the Removed management state, see ManagementState, and the teardown controller removes the
+// console: the deployment and config, the routes, the oauth client, the plugins config... The
+// management state of the operator config is left to the cluster admin, everything is re-created
+// once the capability is enabled again.
+//
+//	writes:
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=ConsoleCapabilityDisabled
+type ConsoleCapabilityController struct {
+	operatorClient       v1helpers.OperatorClient
+	clusterVersionLister configlistersv1.ClusterVersionLister
+}
+
+func NewConsoleCapabilityController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	// informers
+	configInformer configinformer.SharedInformerFactory,
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	configV1Informers := configInformer.Config().V1()
+
+	ctrl := &ConsoleCapabilityController{
+		operatorClient:       operatorClient,
+		clusterVersionLister: configV1Informers.ClusterVersions().Lister(),
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+		).WithFilteredEventsInformers( // cluster version
+		util.IncludeNamesFilter(api.VersionResourceName),
+		configV1Informers.ClusterVersions().Informer(),
+	).ResyncEvery(10*time.Minute).WithSync(ctrl.Sync).
+		ToController("ConsoleCapabilityController", recorder.WithComponentSuffix("console-capability-controller"))
+}
+
+func (c *ConsoleCapabilityController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	clusterVersion, err := c.clusterVersionLister.Get(api.VersionResourceName)
+	if err != nil {
+		return err
+	}
+	enabled := IsEnabled(clusterVersion)
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	statusHandler.AddCondition(handleCapability(enabled))
+	return statusHandler.FlushAndReturn(nil)
+}
+
+// IsEnabled is true unless the cluster knows of the Console capability and does not enable
+// it, clusters predating the capabilities always run the console.
+func IsEnabled(clusterVersion *configv1.ClusterVersion) bool {
+	return IsCapabilityEnabled(clusterVersion, configv1.ClusterVersionCapabilityConsole)
+}
+
+// IsCapabilityEnabled is true unless the cluster knows of capability and does not enable it.
+func IsCapabilityEnabled(clusterVersion *configv1.ClusterVersion, capability configv1.ClusterVersionCapability) bool {
+	known := false
+	for _, knownCapability := range clusterVersion.Status.Capabilities.KnownCapabilities {
+		if knownCapability == capability {
+			known = true
+		}
+	}
+	for _, enabledCapability := range clusterVersion.Status.Capabilities.EnabledCapabilities {
+		if enabledCapability == capability {
+			return true
+		}
+	}
+	return !known
+}
+
+// ManagementState is the management state the controllers follow: Removed while the Console
+// capability is disabled, the management state of the operator config otherwise.
+func ManagementState(operatorConfig *operatorsv1.Console) operatorsv1.ManagementState {
+	return OperatorManagementState(&operatorConfig.Spec.OperatorSpec, &operatorConfig.Status.OperatorStatus)
+}
+
+// OperatorManagementState is ManagementState for the operator state of an operator client.
+func OperatorManagementState(operatorSpec *operatorsv1.OperatorSpec, operatorStatus *operatorsv1.OperatorStatus) operatorsv1.ManagementState {
+	if v1helpers.IsOperatorConditionTrue(operatorStatus.Conditions, conditionType) {
+		return operatorsv1.Removed
+	}
+	return operatorSpec.ManagementState
+}
+
+func handleCapability(enabled bool) status.ConditionUpdate {
+	condition := operatorsv1.OperatorCondition{
+		Type:   conditionType,
+		Status: operatorsv1.ConditionFalse,
+	}
+	if !enabled {
+		condition.Status = operatorsv1.ConditionTrue
+		condition.Reason = capabilityDisabledReason
+		condition.Message = "the Console capability is disabled, the console and the resources the operator manages for it are removed"
+	}
+	return status.ConditionUpdate{
+		ConditionType:  condition.Type,
+		StatusUpdateFn: v1helpers.UpdateConditionFn(condition),
+	}
+}
@@ -0,0 +1,93 @@
+package consolecapability
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorsv1 "github.com/openshift/api/operator/v1"
+)
+
+func TestIsEnabled(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities configv1.ClusterVersionCapabilitiesStatus
+		want         bool
+	}{
+		{
+			name:         "Test cluster predating the capabilities",
+			capabilities: configv1.ClusterVersionCapabilitiesStatus{},
+			want:         true,
+		},
+		{
+			name: "Test enabled capability",
+			capabilities: configv1.ClusterVersionCapabilitiesStatus{
+				EnabledCapabilities: []configv1.ClusterVersionCapability{configv1.ClusterVersionCapabilityConsole},
+				KnownCapabilities:   []configv1.ClusterVersionCapability{configv1.ClusterVersionCapabilityConsole},
+			},
+			want: true,
+		},
+		{
+			name: "Test disabled capability",
+			capabilities: configv1.ClusterVersionCapabilitiesStatus{
+				EnabledCapabilities: []configv1.ClusterVersionCapability{configv1.ClusterVersionCapabilityInsights},
+				KnownCapabilities:   []configv1.ClusterVersionCapability{configv1.ClusterVersionCapabilityConsole, configv1.ClusterVersionCapabilityInsights},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusterVersion := &configv1.ClusterVersion{Status: configv1.ClusterVersionStatus{Capabilities: tt.capabilities}}
+			if diff := deep.Equal(IsEnabled(clusterVersion), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestManagementState(t *testing.T) {
+	tests := []struct {
+		name      string
+		state     operatorsv1.ManagementState
+		condition operatorsv1.ConditionStatus
+		want      operatorsv1.ManagementState
+	}{
+		{
+			name:      "Test enabled capability",
+			state:     operatorsv1.Managed,
+			condition: operatorsv1.ConditionFalse,
+			want:      operatorsv1.Managed,
+		},
+		{
+			name:      "Test disabled capability removes a managed console",
+			state:     operatorsv1.Managed,
+			condition: operatorsv1.ConditionTrue,
+			want:      operatorsv1.Removed,
+		},
+		{
+			name:      "Test disabled capability removes an unmanaged console",
+			state:     operatorsv1.Unmanaged,
+			condition: operatorsv1.ConditionTrue,
+			want:      operatorsv1.Removed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorsv1.Console{
+				Spec: operatorsv1.ConsoleSpec{
+					OperatorSpec: operatorsv1.OperatorSpec{ManagementState: tt.state},
+				},
+				Status: operatorsv1.ConsoleStatus{
+					OperatorStatus: operatorsv1.OperatorStatus{
+						Conditions: []operatorsv1.OperatorCondition{{Type: conditionType, Status: tt.condition}},
+					},
+				},
+			}
+			if diff := deep.Equal(ManagementState(operatorConfig), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package consolequickstart
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	consolev1 "github.com/openshift/api/console/v1"
+)
+
+var (
+	// directiveRegexp matches the {{...}} extensions of the quick start markdown
+	directiveRegexp = regexp.MustCompile(`(\])?\{\{([^{}]*)\}\}`)
+	// the console highlights the elements of its data-quickstart-id attribute, which all have
+	// the qs- prefix
+	highlightIDRegexp = regexp.MustCompile(`^qs-[a-z0-9-]+$`)
+	admonitionTypes   = sets.NewString("note", "tip", "important", "caution", "warning")
+)
+
+// checkConformance returns the problems of a quick start that the console renders broken or not
+// at all: missing fields, markdown it does not parse, next quick starts that do not exist and
+// access reviews of resources the cluster does not serve.
+func checkConformance(quickStart *consolev1.ConsoleQuickStart, quickStarts sets.String, served func(schema.GroupResource) bool) []string {
+	spec := quickStart.Spec
+	problems := []string{}
+	if len(spec.DisplayName) == 0 {
+		problems = append(problems, "displayName: is required")
+	}
+	if len(spec.Icon) == 0 {
+		problems = append(problems, "icon: is required, the catalog shows a placeholder without one")
+	}
+	if spec.DurationMinutes < 1 {
+		problems = append(problems, "durationMinutes: must be at least 1")
+	}
+	if len(spec.Description) == 0 {
+		problems = append(problems, "description: is required")
+	}
+	if len(spec.Description) > 256 {
+		problems = append(problems, "description: is longer than 256 characters")
+	}
+	if len(spec.Introduction) == 0 {
+		problems = append(problems, "introduction: is required")
+	}
+	if len(spec.Tasks) == 0 {
+		problems = append(problems, "tasks: at least one task is required")
+	}
+
+	problems = append(problems, lintMarkdown("introduction", spec.Introduction)...)
+	problems = append(problems, lintMarkdown("conclusion", spec.Conclusion)...)
+	for i, task := range spec.Tasks {
+		field := fmt.Sprintf("tasks[%d]", i)
+		if len(task.Title) == 0 {
+			problems = append(problems, field+".title: is required")
+		}
+		if len(task.Description) == 0 {
+			problems = append(problems, field+".description: is required")
+		}
+		problems = append(problems, lintMarkdown(field+".description", task.Description)...)
+		if task.Review != nil {
+			if len(task.Review.Instructions) == 0 || len(task.Review.FailedTaskHelp) == 0 {
+				problems = append(problems, field+".review: instructions and failedTaskHelp are required")
+			}
+			problems = append(problems, lintMarkdown(field+".review.instructions", task.Review.Instructions)...)
+		}
+		if task.Summary != nil && (len(task.Summary.Success) == 0 || len(task.Summary.Failed) == 0) {
+			problems = append(problems, field+".summary: success and failed are required")
+		}
+	}
+
+	for _, next := range spec.NextQuickStart {
+		if !quickStarts.Has(next) {
+			problems = append(problems, fmt.Sprintf("nextQuickStart: ConsoleQuickStart %q does not exist", next))
+		}
+	}
+	for i, resource := range spec.AccessReviewResources {
+		groupResource := schema.GroupResource{Group: resource.Group, Resource: resource.Resource}
+		if len(resource.Resource) > 0 && !served(groupResource) {
+			problems = append(problems, fmt.Sprintf("accessReviewResources[%d]: %s is not served by the cluster", i, groupResource))
+		}
+	}
+	return problems
+}
+
+// lintMarkdown checks the markdown extensions of the console: highlights, admonitions and the
+// copy and execute snippets, and that code blocks are closed.
+func lintMarkdown(field, markdown string) []string {
+	problems := []string{}
+	fences := 0
+	for _, line := range strings.Split(markdown, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fences++
+		}
+	}
+	if fences%2 != 0 {
+		problems = append(problems, field+": a code block is not closed")
+	}
+
+	for _, match := range directiveRegexp.FindAllStringSubmatch(markdown, -1) {
+		directive, afterLink := "{{"+match[2]+"}}", len(match[1]) > 0
+		args := strings.Fields(match[2])
+		if len(args) == 0 {
+			problems = append(problems, fmt.Sprintf("%s: empty %s", field, directive))
+			continue
+		}
+		switch args[0] {
+		case "highlight":
+			if !afterLink {
+				problems = append(problems, fmt.Sprintf("%s: %s must follow the [text] it highlights", field, directive))
+			}
+			if len(args) != 2 || !highlightIDRegexp.MatchString(args[1]) {
+				problems = append(problems, fmt.Sprintf("%s: %s does not name a qs- element of the console", field, directive))
+			}
+		case "admonition":
+			if len(args) != 2 || !admonitionTypes.Has(strings.ToLower(args[1])) {
+				problems = append(problems, fmt.Sprintf("%s: %s is not one of the %s admonitions", field, directive, strings.Join(admonitionTypes.List(), ", ")))
+			}
+		case "copy", "execute":
+			if len(args) != 1 {
+				problems = append(problems, fmt.Sprintf("%s: %s takes no arguments", field, directive))
+			}
+		default:
+			problems = append(problems, fmt.Sprintf("%s: unknown %s", field, directive))
+		}
+	}
+	return problems
+}
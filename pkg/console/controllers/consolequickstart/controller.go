@@ -0,0 +1,184 @@
+package consolequickstart
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	// kube
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	consoleinformersv1 "github.com/openshift/client-go/console/informers/externalversions/console/v1"
+	consolelistersv1 "github.com/openshift/client-go/console/listers/console/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+)
+
+const (
+	conditionType        = "ConsoleQuickStartsNonConformant"
+	nonConformantReason  = "NonConformantQuickStarts"
+	discoveryFailedEvent = "ConsoleQuickStartDiscoveryFailed"
+)
+
+// ConsoleQuickStartController checks that the ConsoleQuickStarts of the cluster conform to what
+// the console renders: the schema, the markdown extensions of task highlights, admonitions and
+// snippets, an icon, and the quick starts and resources they reference. The quick starts are
+// shipped by other components, a non conformant one does not degrade the console. Its problems
+// are reported to its owner with a warning event when they change, and the quick starts with
+// problems are listed in the ConsoleQuickStartsNonConformant condition.
+//
+//	writes:
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=ConsoleQuickStartsNonConformant
+type ConsoleQuickStartController struct {
+	operatorClient       v1helpers.OperatorClient
+	operatorConfigLister operatorv1listers.ConsoleLister
+	quickStartLister     consolelistersv1.ConsoleQuickStartLister
+	discoveryClient      discovery.DiscoveryInterface
+	// reported are the problems last reported for each quick start, by name
+	reported map[string]string
+}
+
+func NewConsoleQuickStartController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	discoveryClient discovery.DiscoveryInterface,
+	// informers
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	quickStartInformer consoleinformersv1.ConsoleQuickStartInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &ConsoleQuickStartController{
+		operatorClient:       operatorClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		quickStartLister:     quickStartInformer.Lister(),
+		discoveryClient:      discoveryClient,
+		reported:             map[string]string{},
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+		).WithInformers(
+		quickStartInformer.Informer(),
+		// resources served by CRDs come and go without an event, resync to notice them
+	).ResyncEvery(10*time.Minute).WithSync(ctrl.Sync).
+		ToController("ConsoleQuickStartController", recorder.WithComponentSuffix("console-quickstart-controller"))
+}
+
+func (c *ConsoleQuickStartController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch consolecapability.ManagementState(operatorConfig) {
+	case operatorsv1.Managed:
+		klog.V(4).Infoln("console-operator is in a managed state: checking console quick starts")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infoln("console-operator is in an unmanaged state: skipping console quick starts checks")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infoln("console-operator is in a removed state: skipping console quick starts checks")
+		return nil
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	quickStarts, err := c.quickStartLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	sort.Slice(quickStarts, func(i, j int) bool { return quickStarts[i].Name < quickStarts[j].Name })
+	names := sets.NewString()
+	for _, quickStart := range quickStarts {
+		names.Insert(quickStart.Name)
+	}
+	served := c.servedResources(controllerContext.Recorder())
+
+	nonConformant := []string{}
+	reported := map[string]string{}
+	for _, quickStart := range quickStarts {
+		problems := strings.Join(checkConformance(quickStart, names, served), "; ")
+		if len(problems) > 0 {
+			nonConformant = append(nonConformant, quickStart.Name)
+			if c.reported[quickStart.Name] != problems {
+				controllerContext.Recorder().Warningf("ConsoleQuickStartNonConformant", "ConsoleQuickStart %s: %s", quickStart.Name, problems)
+			}
+			reported[quickStart.Name] = problems
+		} else if len(c.reported[quickStart.Name]) > 0 {
+			controllerContext.Recorder().Eventf("ConsoleQuickStartConformant", "ConsoleQuickStart %s conforms", quickStart.Name)
+		}
+	}
+	c.reported = reported
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	statusHandler.AddCondition(handleConformance(nonConformant, len(quickStarts)))
+	return statusHandler.FlushAndReturn(nil)
+}
+
+// servedResources returns whether the cluster serves a resource. When discovery fails for some
+// groups their resources are taken as served, a flaky aggregated API does not make quick starts
+// non conformant.
+func (c *ConsoleQuickStartController) servedResources(recorder events.Recorder) func(schema.GroupResource) bool {
+	_, resourceLists, err := c.discoveryClient.ServerGroupsAndResources()
+	failedGroups := map[string]bool{}
+	if err != nil {
+		groupErr, ok := err.(*discovery.ErrGroupDiscoveryFailed)
+		if !ok {
+			recorder.Warningf(discoveryFailedEvent, "skipping the access review resources checks: %v", err)
+			return func(schema.GroupResource) bool { return true }
+		}
+		for groupVersion := range groupErr.Groups {
+			failedGroups[groupVersion.Group] = true
+		}
+	}
+	served := map[schema.GroupResource]bool{}
+	for _, resourceList := range resourceLists {
+		groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range resourceList.APIResources {
+			served[schema.GroupResource{Group: groupVersion.Group, Resource: resource.Name}] = true
+		}
+	}
+	return func(groupResource schema.GroupResource) bool {
+		return served[groupResource] || failedGroups[groupResource.Group]
+	}
+}
+
+func handleConformance(nonConformant []string, total int) status.ConditionUpdate {
+	condition := operatorsv1.OperatorCondition{
+		Type:   conditionType,
+		Status: operatorsv1.ConditionFalse,
+	}
+	if len(nonConformant) > 0 {
+		condition.Status = operatorsv1.ConditionTrue
+		condition.Reason = nonConformantReason
+		condition.Message = fmt.Sprintf("%d of %d console quick starts do not conform, see the ConsoleQuickStartNonConformant events: %s", len(nonConformant), total, strings.Join(nonConformant, ", "))
+	}
+	return status.ConditionUpdate{
+		ConditionType:  condition.Type,
+		StatusUpdateFn: v1helpers.UpdateConditionFn(condition),
+	}
+}
@@ -0,0 +1,106 @@
+package consolequickstart
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	consolev1 "github.com/openshift/api/console/v1"
+)
+
+func quickStart(mutate func(spec *consolev1.ConsoleQuickStartSpec)) *consolev1.ConsoleQuickStart {
+	quickStart := &consolev1.ConsoleQuickStart{
+		ObjectMeta: metav1.ObjectMeta{Name: "explore-pipelines"},
+		Spec: consolev1.ConsoleQuickStartSpec{
+			DisplayName:     "Explore pipelines",
+			Icon:            "data:image/svg+xml;base64,PHN2Zz48L3N2Zz4=",
+			DurationMinutes: 10,
+			Description:     "Install the Pipelines operator",
+			Introduction:    "Pipelines run your builds.",
+			Tasks: []consolev1.ConsoleQuickStartTask{{
+				Title:       "Install the operator",
+				Description: "Open the [OperatorHub]{{highlight qs-nav-operatorhub}} and run:\n```\noc get pods\n```{{copy}}",
+			}},
+		},
+	}
+	if mutate != nil {
+		mutate(&quickStart.Spec)
+	}
+	return quickStart
+}
+
+func TestCheckConformance(t *testing.T) {
+	served := func(groupResource schema.GroupResource) bool {
+		return groupResource == schema.GroupResource{Group: "operators.coreos.com", Resource: "subscriptions"}
+	}
+
+	tests := []struct {
+		name       string
+		quickStart *consolev1.ConsoleQuickStart
+		want       []string
+	}{
+		{
+			name:       "Test conformant quick start",
+			quickStart: quickStart(nil),
+			want:       []string{},
+		},
+		{
+			name: "Test missing icon and tasks",
+			quickStart: quickStart(func(spec *consolev1.ConsoleQuickStartSpec) {
+				spec.Icon = ""
+				spec.Tasks = nil
+			}),
+			want: []string{"icon: is required, the catalog shows a placeholder without one", "tasks: at least one task is required"},
+		},
+		{
+			name: "Test broken task highlights",
+			quickStart: quickStart(func(spec *consolev1.ConsoleQuickStartSpec) {
+				spec.Tasks[0].Description = "Open {{highlight qs-nav-operatorhub}} and the [masthead]{{highlight}}"
+			}),
+			want: []string{
+				"tasks[0].description: {{highlight qs-nav-operatorhub}} must follow the [text] it highlights",
+				"tasks[0].description: {{highlight}} does not name a qs- element of the console",
+			},
+		},
+		{
+			name: "Test markdown lints",
+			quickStart: quickStart(func(spec *consolev1.ConsoleQuickStartSpec) {
+				spec.Introduction = "[Careful]{{admonition danger}}\n```\noc delete"
+				spec.Conclusion = "Done {{execute now}} {{pin}}"
+			}),
+			want: []string{
+				"introduction: a code block is not closed",
+				"introduction: {{admonition danger}} is not one of the caution, important, note, tip, warning admonitions",
+				"conclusion: {{execute now}} takes no arguments",
+				"conclusion: unknown {{pin}}",
+			},
+		},
+		{
+			name: "Test referenced resources",
+			quickStart: quickStart(func(spec *consolev1.ConsoleQuickStartSpec) {
+				spec.NextQuickStart = []string{"explore-serverless"}
+				spec.AccessReviewResources = []authorizationv1.ResourceAttributes{
+					{Group: "operators.coreos.com", Resource: "subscriptions", Verb: "create"},
+					{Group: "tekton.dev", Resource: "pipelines", Verb: "create"},
+				}
+			}),
+			want: []string{
+				`nextQuickStart: ConsoleQuickStart "explore-serverless" does not exist`,
+				"accessReviewResources[1]: pipelines.tekton.dev is not served by the cluster",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkConformance(tt.quickStart, sets.NewString(tt.quickStart.Name), served)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package consolesample
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	// kube
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	configv1 "github.com/openshift/api/config/v1"
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	configinformer "github.com/openshift/client-go/config/informers/externalversions"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	consoleclientv1 "github.com/openshift/client-go/console/clientset/versioned/typed/console/v1"
+	consoleinformersv1 "github.com/openshift/client-go/console/informers/externalversions/console/v1"
+	consolelistersv1 "github.com/openshift/client-go/console/listers/console/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+)
+
+const (
+	// probeTimeout bounds the probe of a single repository, repositories are probed in parallel
+	probeTimeout = 10 * time.Second
+
+	// connectedPolicy probes the git repositories of the samples, the default
+	connectedPolicy = "Connected"
+	// disconnectedPolicy skips the probes, git samples cannot be imported without the internet
+	disconnectedPolicy = "Disconnected"
+)
+
+// ConsoleSampleController checks the ConsoleSamples of the cluster, which the console lists in
+// its samples catalog without checking them. The spec of every sample is validated, the git
+// repositories are probed unless the sample-connectivity-policy annotation says the cluster is
+// disconnected, and the outcome for each sample is kept in the console-samples-status ConfigMap.
+// Samples targeting a disabled cluster capability, by their capability annotation or by needing
+// the Build capability to import a git repository, are deleted.
+//
+//	writes:
+//	- consolesamples.console.openshift.io (deletes)
+//	- configmaps openshift-console/console-samples-status
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=ConsoleSamplesDegraded
+type ConsoleSampleController struct {
+	operatorClient       v1helpers.OperatorClient
+	operatorConfigLister operatorv1listers.ConsoleLister
+	consoleSampleClient  consoleclientv1.ConsoleSampleInterface
+	consoleSampleLister  consolelistersv1.ConsoleSampleLister
+	clusterVersionLister configlistersv1.ClusterVersionLister
+	configMapClient      coreclientv1.ConfigMapsGetter
+	httpClient           *http.Client
+}
+
+func NewConsoleSampleController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	consoleSampleClient consoleclientv1.ConsoleSampleInterface,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	// informers
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	consoleSampleInformer consoleinformersv1.ConsoleSampleInformer,
+	configInformer configinformer.SharedInformerFactory,
+	targetNSConfigMapInformer corev1informers.ConfigMapInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	configV1Informers := configInformer.Config().V1()
+
+	ctrl := &ConsoleSampleController{
+		operatorClient:       operatorClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		consoleSampleClient:  consoleSampleClient,
+		consoleSampleLister:  consoleSampleInformer.Lister(),
+		clusterVersionLister: configV1Informers.ClusterVersions().Lister(),
+		configMapClient:      configMapClient,
+		httpClient: &http.Client{
+			Timeout:   probeTimeout,
+			Transport: &http.Transport{Proxy: http.ProxyFromEnvironment},
+		},
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+		).WithFilteredEventsInformers( // cluster version
+		util.IncludeNamesFilter(api.VersionResourceName),
+		configV1Informers.ClusterVersions().Informer(),
+	).WithInformers(
+		consoleSampleInformer.Informer(),
+	).WithFilteredEventsInformers( // console-samples-status
+		util.IncludeNamesFilter(api.ConsoleSamplesStatusConfigMapName),
+		targetNSConfigMapInformer.Informer(),
+	).ResyncEvery(10*time.Minute).WithSync(ctrl.Sync).
+		ToController("ConsoleSampleController", recorder.WithComponentSuffix("console-sample-controller"))
+}
+
+func (c *ConsoleSampleController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch consolecapability.ManagementState(operatorConfig) {
+	case operatorsv1.Managed:
+		klog.V(4).Infoln("console-operator is in a managed state: checking console samples")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infoln("console-operator is in an unmanaged state: skipping console samples checks")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infoln("console-operator is in a removed state: deleting console samples status")
+		return c.removeSamplesStatus(ctx)
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	reason, samplesErr := c.checkSamples(ctx, operatorConfig, controllerContext.Recorder())
+	statusHandler.AddCondition(status.HandleDegraded("ConsoleSamples", reason, samplesErr))
+	return statusHandler.FlushAndReturn(nil)
+}
+
+func (c *ConsoleSampleController) checkSamples(ctx context.Context, operatorConfig *operatorsv1.Console, recorder events.Recorder) (string, error) {
+	policy, err := connectivityPolicy(operatorConfig)
+	if err != nil {
+		return "InvalidConnectivityPolicy", err
+	}
+	clusterVersion, err := c.clusterVersionLister.Get(api.VersionResourceName)
+	if err != nil {
+		return "FailedGetClusterVersion", err
+	}
+	samples, err := c.consoleSampleLister.List(labels.Everything())
+	if err != nil {
+		return "FailedList", err
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Name < samples[j].Name })
+
+	kept := []*consolev1.ConsoleSample{}
+	for _, sample := range samples {
+		capability := disabledCapability(clusterVersion, sample)
+		if len(capability) == 0 {
+			kept = append(kept, sample)
+			continue
+		}
+		err := c.consoleSampleClient.Delete(ctx, sample.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return "FailedDelete", err
+		}
+		recorder.Eventf("ConsoleSamplePruned", "deleted ConsoleSample %s, the %s capability is disabled", sample.Name, capability)
+	}
+
+	statuses := c.sampleStatuses(ctx, kept, policy)
+	required, err := configmapsub.DefaultConsoleSamplesStatusConfigMap(statuses)
+	if err != nil {
+		return "FailedRender", err
+	}
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, recorder, required); err != nil {
+		return "FailedApply", err
+	}
+
+	// unreachable repositories are reported in console-samples-status only, they come and go
+	// with the network while an invalid sample needs fixing
+	invalid := []string{}
+	for _, sampleStatus := range statuses {
+		if !sampleStatus.Valid {
+			invalid = append(invalid, fmt.Sprintf("%s: %s", sampleStatus.Name, sampleStatus.Message))
+		}
+	}
+	if len(invalid) > 0 {
+		return "InvalidSamples", fmt.Errorf("%d of %d console samples are invalid: %s", len(invalid), len(statuses), strings.Join(invalid, "; "))
+	}
+	return "", nil
+}
+
+// sampleStatuses validates samples and probes the git repositories of the valid ones, following
+// the connectivity policy.
+func (c *ConsoleSampleController) sampleStatuses(ctx context.Context, samples []*consolev1.ConsoleSample, policy string) []configmapsub.ConsoleSampleStatus {
+	statuses := make([]configmapsub.ConsoleSampleStatus, len(samples))
+	var wg sync.WaitGroup
+	for i, sample := range samples {
+		statuses[i] = configmapsub.ConsoleSampleStatus{Name: sample.Name, Valid: true, Reachable: true}
+		if err := validateConsoleSample(sample); err != nil {
+			statuses[i].Valid = false
+			statuses[i].Message = err.Error()
+			continue
+		}
+		if sample.Spec.Source.Type != consolev1.GitImport {
+			continue
+		}
+		if policy == disconnectedPolicy {
+			statuses[i].Reachable = false
+			statuses[i].Message = "git repositories are not probed in a disconnected cluster"
+			continue
+		}
+		wg.Add(1)
+		go func(i int, repositoryURL string) {
+			defer wg.Done()
+			if err := probeRepository(ctx, c.httpClient, repositoryURL); err != nil {
+				statuses[i].Reachable = false
+				statuses[i].Message = err.Error()
+			}
+		}(i, sample.Spec.Source.GitImport.Repository.URL)
+	}
+	wg.Wait()
+	return statuses
+}
+
+func (c *ConsoleSampleController) removeSamplesStatus(ctx context.Context) error {
+	err := c.configMapClient.ConfigMaps(api.OpenShiftConsoleNamespace).Delete(ctx, api.ConsoleSamplesStatusConfigMapName, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func connectivityPolicy(operatorConfig *operatorsv1.Console) (string, error) {
+	policy, ok := operatorConfig.Annotations[api.SampleConnectivityPolicyAnnotation]
+	if !ok {
+		return connectedPolicy, nil
+	}
+	switch policy {
+	case connectedPolicy, disconnectedPolicy:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid %s annotation %q, must be %s or %s", api.SampleConnectivityPolicyAnnotation, policy, connectedPolicy, disconnectedPolicy)
+	}
+}
+
+// disabledCapability returns the disabled cluster capability sample targets, if any. Git samples
+// are imported with a BuildConfig and need the Build capability.
+func disabledCapability(clusterVersion *configv1.ClusterVersion, sample *consolev1.ConsoleSample) configv1.ClusterVersionCapability {
+	if capability, ok := sample.Annotations[api.CapabilityAnnotation]; ok && len(capability) > 0 {
+		for _, name := range strings.Split(capability, "+") {
+			if !consolecapability.IsCapabilityEnabled(clusterVersion, configv1.ClusterVersionCapability(name)) {
+				return configv1.ClusterVersionCapability(name)
+			}
+		}
+	}
+	if sample.Spec.Source.Type == consolev1.GitImport && !consolecapability.IsCapabilityEnabled(clusterVersion, configv1.ClusterVersionCapabilityBuild) {
+		return configv1.ClusterVersionCapabilityBuild
+	}
+	return ""
+}
+
+// probeRepository checks that a git repository can be cloned over the smart HTTP protocol,
+// private and missing repositories answer with an error.
+func probeRepository(ctx context.Context, client *http.Client, repositoryURL string) error {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	probeURL := strings.TrimSuffix(repositoryURL, "/") + "/info/refs?service=git-upload-pack"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probeURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returns '%s'", repositoryURL, resp.Status)
+	}
+	return nil
+}
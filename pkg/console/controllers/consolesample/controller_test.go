@@ -0,0 +1,161 @@
+package consolesample
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	consolev1 "github.com/openshift/api/console/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func gitSample(url string) *consolev1.ConsoleSample {
+	return &consolev1.ConsoleSample{
+		ObjectMeta: metav1.ObjectMeta{Name: "sample"},
+		Spec: consolev1.ConsoleSampleSpec{
+			Title:       "Sample",
+			Abstract:    "A sample",
+			Description: "A sample application",
+			Source: consolev1.ConsoleSampleSource{
+				Type: consolev1.GitImport,
+				GitImport: &consolev1.ConsoleSampleGitImportSource{
+					Repository: consolev1.ConsoleSampleGitImportSourceRepository{URL: url},
+				},
+			},
+		},
+	}
+}
+
+func containerSample(image string) *consolev1.ConsoleSample {
+	sample := gitSample("")
+	sample.Spec.Source = consolev1.ConsoleSampleSource{
+		Type:            consolev1.ContainerImport,
+		ContainerImport: &consolev1.ConsoleSampleContainerImportSource{Image: image},
+	}
+	return sample
+}
+
+func TestValidateConsoleSample(t *testing.T) {
+	tests := []struct {
+		name   string
+		sample func() *consolev1.ConsoleSample
+		want   string
+	}{
+		{
+			name:   "Test valid git sample",
+			sample: func() *consolev1.ConsoleSample { return gitSample("https://github.com/openshift/console.git") },
+			want:   "",
+		},
+		{
+			name:   "Test valid container sample",
+			sample: func() *consolev1.ConsoleSample { return containerSample("quay.io/openshift/console:latest") },
+			want:   "",
+		},
+		{
+			name:   "Test git sample on an unsupported forge",
+			sample: func() *consolev1.ConsoleSample { return gitSample("https://example.com/openshift/console") },
+			want:   `source.gitImport.repository.url: "https://example.com/openshift/console" is not a public GitHub, GitLab or Bitbucket repository`,
+		},
+		{
+			name: "Test git sample with a relative context dir",
+			sample: func() *consolev1.ConsoleSample {
+				sample := gitSample("https://github.com/openshift/console")
+				sample.Spec.Source.GitImport.Repository.ContextDir = "frontend"
+				return sample
+			},
+			want: `source.gitImport.repository.contextDir: "frontend" must start with /`,
+		},
+		{
+			name:   "Test container sample without an image reference",
+			sample: func() *consolev1.ConsoleSample { return containerSample("Not An Image") },
+			want:   `source.containerImport.image: "Not An Image" is not an image reference`,
+		},
+		{
+			name: "Test source type without its source",
+			sample: func() *consolev1.ConsoleSample {
+				sample := gitSample("https://github.com/openshift/console")
+				sample.Spec.Source.Type = consolev1.ContainerImport
+				return sample
+			},
+			want: "source: ContainerImport needs containerImport only",
+		},
+		{
+			name: "Test missing title and oversized icon",
+			sample: func() *consolev1.ConsoleSample {
+				sample := gitSample("https://github.com/openshift/console")
+				sample.Spec.Title = ""
+				sample.Spec.Icon = "data:image/png;base64," + strings.Repeat("AAAA", 4000)
+				return sample
+			},
+			want: "[title: is required, icon: is larger than 10240 bytes]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if err := validateConsoleSample(tt.sample()); err != nil {
+				got = err.Error()
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestDisabledCapability(t *testing.T) {
+	capabilities := configv1.ClusterVersionCapabilitiesStatus{
+		EnabledCapabilities: []configv1.ClusterVersionCapability{configv1.ClusterVersionCapabilityConsole},
+		KnownCapabilities:   []configv1.ClusterVersionCapability{configv1.ClusterVersionCapabilityConsole, configv1.ClusterVersionCapabilityBuild, configv1.ClusterVersionCapabilityInsights},
+	}
+
+	tests := []struct {
+		name         string
+		capabilities configv1.ClusterVersionCapabilitiesStatus
+		sample       *consolev1.ConsoleSample
+		annotation   string
+		want         configv1.ClusterVersionCapability
+	}{
+		{
+			name:         "Test cluster predating the capabilities",
+			capabilities: configv1.ClusterVersionCapabilitiesStatus{},
+			sample:       gitSample("https://github.com/openshift/console"),
+			want:         "",
+		},
+		{
+			name:         "Test container sample of enabled capabilities",
+			capabilities: capabilities,
+			sample:       containerSample("quay.io/openshift/console"),
+			want:         "",
+		},
+		{
+			name:         "Test git sample without the Build capability",
+			capabilities: capabilities,
+			sample:       gitSample("https://github.com/openshift/console"),
+			want:         configv1.ClusterVersionCapabilityBuild,
+		},
+		{
+			name:         "Test sample annotated with a disabled capability",
+			capabilities: capabilities,
+			sample:       containerSample("quay.io/openshift/console"),
+			annotation:   "Console+Insights",
+			want:         configv1.ClusterVersionCapabilityInsights,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusterVersion := &configv1.ClusterVersion{Status: configv1.ClusterVersionStatus{Capabilities: tt.capabilities}}
+			if len(tt.annotation) > 0 {
+				tt.sample.Annotations = map[string]string{api.CapabilityAnnotation: tt.annotation}
+			}
+			if diff := deep.Equal(disabledCapability(clusterVersion, tt.sample), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package consolesample
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	consolev1 "github.com/openshift/api/console/v1"
+)
+
+const (
+	// maxIconBytes is the size of the decoded icon the console shows
+	maxIconBytes = 10 * 1024
+)
+
+var (
+	iconRegexp = regexp.MustCompile(`^data:([a-z/\.+0-9]*;(([-a-zA-Z0-9=])*;)?)?base64,`)
+	// only public repositories of the forges the console imports from are supported
+	gitURLRegexp = regexp.MustCompile(`^https://(github\.com|gitlab\.com|bitbucket\.org)/[a-zA-Z0-9-]+/[a-zA-Z0-9-]+(\.git)?$`)
+	// [registry[:port]/]path[:tag][@digest]
+	imageRegexp = regexp.MustCompile(`^([a-zA-Z0-9.-]+(:[0-9]+)?/)?[a-z0-9]+([._/-][a-z0-9]+)*(:[\w][\w.-]{0,127})?(@[a-z0-9]+:[a-fA-F0-9]{32,})?$`)
+)
+
+// validateConsoleSample checks the spec of a ConsoleSample the way the console imports it,
+// samples created before their CRD validated them or through a looser schema are caught too.
+func validateConsoleSample(sample *consolev1.ConsoleSample) error {
+	spec := sample.Spec
+	errs := []error{}
+	errs = append(errs, validateLength("title", spec.Title, 1, 50))
+	errs = append(errs, validateLength("abstract", spec.Abstract, 1, 100))
+	errs = append(errs, validateLength("description", spec.Description, 1, 4096))
+	errs = append(errs, validateLength("type", spec.Type, 0, 20))
+	errs = append(errs, validateLength("provider", spec.Provider, 0, 50))
+	errs = append(errs, validateIcon(spec.Icon))
+
+	source := spec.Source
+	switch source.Type {
+	case consolev1.GitImport:
+		if source.GitImport == nil || source.ContainerImport != nil {
+			errs = append(errs, fmt.Errorf("source: %s needs gitImport only", source.Type))
+			break
+		}
+		repository := source.GitImport.Repository
+		if len(repository.URL) > 256 || !gitURLRegexp.MatchString(repository.URL) {
+			errs = append(errs, fmt.Errorf("source.gitImport.repository.url: %q is not a public GitHub, GitLab or Bitbucket repository", repository.URL))
+		}
+		errs = append(errs, validateLength("source.gitImport.repository.revision", repository.Revision, 0, 256))
+		errs = append(errs, validateLength("source.gitImport.repository.contextDir", repository.ContextDir, 0, 256))
+		if len(repository.ContextDir) > 0 && !strings.HasPrefix(repository.ContextDir, "/") {
+			errs = append(errs, fmt.Errorf("source.gitImport.repository.contextDir: %q must start with /", repository.ContextDir))
+		}
+		errs = append(errs, validateTargetPort("source.gitImport.service.targetPort", source.GitImport.Service.TargetPort))
+	case consolev1.ContainerImport:
+		if source.ContainerImport == nil || source.GitImport != nil {
+			errs = append(errs, fmt.Errorf("source: %s needs containerImport only", source.Type))
+			break
+		}
+		if image := source.ContainerImport.Image; len(image) == 0 || len(image) > 256 || !imageRegexp.MatchString(image) {
+			errs = append(errs, fmt.Errorf("source.containerImport.image: %q is not an image reference", image))
+		}
+		errs = append(errs, validateTargetPort("source.containerImport.service.targetPort", source.ContainerImport.Service.TargetPort))
+	default:
+		errs = append(errs, fmt.Errorf("source.type: %q must be %s or %s", source.Type, consolev1.GitImport, consolev1.ContainerImport))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func validateLength(field, value string, min, max int) error {
+	if len(value) < min {
+		return fmt.Errorf("%s: is required", field)
+	}
+	if len(value) > max {
+		return fmt.Errorf("%s: is longer than %d characters", field, max)
+	}
+	return nil
+}
+
+func validateIcon(icon string) error {
+	if len(icon) == 0 {
+		return nil
+	}
+	prefix := iconRegexp.FindString(icon)
+	if len(prefix) == 0 {
+		return fmt.Errorf("icon: is not a base64 data: URL")
+	}
+	decoded, err := base64.StdEncoding.DecodeString(icon[len(prefix):])
+	if err != nil {
+		return fmt.Errorf("icon: %w", err)
+	}
+	if len(decoded) > maxIconBytes {
+		return fmt.Errorf("icon: is larger than %d bytes", maxIconBytes)
+	}
+	return nil
+}
+
+// validateTargetPort accepts an unset port, the console then uses 8080.
+func validateTargetPort(field string, port int32) error {
+	if port < 0 || port > 65535 {
+		return fmt.Errorf("%s: %d is not a port", field, port)
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package crashloop
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	// kube
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+const (
+	// logTailLines is how much of the log of the crashed container is read when it left no
+	// termination message
+	logTailLines = 20
+	// maxExcerpt bounds the output of the crashed container quoted in the condition and event
+	maxExcerpt = 512
+)
+
+// failure signatures of the console container, checked in order
+var (
+	invalidConfigRegexp = regexp.MustCompile(`(?i)(unknown field|field \S+ not found|cannot unmarshal|yaml: |invalid (config|value)|failed to (parse|load|read) config|flag provided but not defined)`)
+	// the config key is named by the YAML and flag parsers in one of these ways
+	configKeyRegexp = regexp.MustCompile(`(?:unknown field|field|flag provided but not defined:) "?-*([A-Za-z0-9_.\-]+)"?`)
+	tlsErrorRegexp  = regexp.MustCompile(`(?i)(x509: |tls: |certificate (signed by unknown authority|has expired|is not valid)|failed to load (x509|key pair))`)
+)
+
+// Diagnosis is the classification of the last crash of a console container.
+type Diagnosis struct {
+	Pod       string
+	Container string
+	// Reason is the failure signature: InvalidConfig, TLSError, OutOfMemory or CrashLoop when
+	// none is recognized
+	Reason string
+	// ConfigKey is the offending console-config key or flag of an InvalidConfig failure
+	ConfigKey string
+	ExitCode  int32
+	Excerpt   string
+}
+
+func (d Diagnosis) String() string {
+	summary := map[string]string{
+		"InvalidConfig": "rejects its configuration",
+		"TLSError":      "fails to set up TLS",
+		"OutOfMemory":   "runs out of memory",
+		"CrashLoop":     "keeps crashing",
+	}[d.Reason]
+	message := fmt.Sprintf("console container %s of pod %s %s (exit code %d)", d.Container, d.Pod, summary, d.ExitCode)
+	if len(d.ConfigKey) > 0 {
+		message += fmt.Sprintf(", offending key %q", d.ConfigKey)
+	}
+	if len(d.Excerpt) > 0 {
+		message += ": " + d.Excerpt
+	}
+	return message
+}
+
+// CrashLoopDiagnosisController diagnoses crash-looping console pods, so that admins do not have
+// to dig through their logs. The termination message of the last crash, or the tail of the log
+// of the previous container when there is none, is matched against the known failure signatures
+// of the console: an invalid config key, a TLS error or running out of memory. The diagnosis
+// is reported in the ConsoleCrashLoopDegraded condition, and with an event once per crash.
+//
+//	writes:
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=ConsoleCrashLoopDegraded
+type CrashLoopDiagnosisController struct {
+	operatorClient       v1helpers.OperatorClient
+	operatorConfigLister operatorv1listers.ConsoleLister
+	podClient            coreclientv1.PodsGetter
+	podLister            corev1listers.PodLister
+	// diagnosed are the diagnoses of the crashes already reported, by container and restart count
+	diagnosed map[string]Diagnosis
+}
+
+func NewCrashLoopDiagnosisController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	podClient coreclientv1.PodsGetter,
+	// informers
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	targetNSPodInformer corev1informers.PodInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &CrashLoopDiagnosisController{
+		operatorClient:       operatorClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		podClient:            podClient,
+		podLister:            targetNSPodInformer.Lister(),
+		diagnosed:            map[string]Diagnosis{},
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+		).WithFilteredEventsInformers( // console pods
+		isConsolePod,
+		targetNSPodInformer.Informer(),
+	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController("CrashLoopDiagnosisController", recorder.WithComponentSuffix("crash-loop-diagnosis-controller"))
+}
+
+func (c *CrashLoopDiagnosisController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch consolecapability.ManagementState(operatorConfig) {
+	case operatorsv1.Managed:
+		klog.V(4).Infoln("console-operator is in a managed state: diagnosing console pods")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infoln("console-operator is in an unmanaged state: skipping console pods diagnosis")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infoln("console-operator is in a removed state: skipping console pods diagnosis")
+		return nil
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	reason, err := c.diagnoseCrashLoops(ctx, controllerContext.Recorder())
+	statusHandler.AddCondition(status.HandleDegraded("ConsoleCrashLoop", reason, err))
+	return statusHandler.FlushAndReturn(nil)
+}
+
+func (c *CrashLoopDiagnosisController) diagnoseCrashLoops(ctx context.Context, recorder events.Recorder) (string, error) {
+	pods, err := c.podLister.Pods(api.OpenShiftConsoleNamespace).List(labels.SelectorFromSet(utilsub.LabelsForConsole()))
+	if err != nil {
+		return "FailedListPods", err
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+
+	diagnoses := []Diagnosis{}
+	diagnosed := map[string]Diagnosis{}
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			terminated := containerStatus.LastTerminationState.Terminated
+			if containerStatus.State.Waiting == nil || containerStatus.State.Waiting.Reason != "CrashLoopBackOff" || terminated == nil {
+				continue
+			}
+			// the log of a crash is only read once, the diagnosis holds until the next crash
+			key := fmt.Sprintf("%s/%s/%d", pod.Name, containerStatus.Name, containerStatus.RestartCount)
+			diagnosis, ok := c.diagnosed[key]
+			if !ok {
+				output := terminated.Message
+				if len(strings.TrimSpace(output)) == 0 {
+					output = c.previousLogTail(ctx, pod.Name, containerStatus.Name)
+				}
+				diagnosis = diagnose(terminated, output)
+				diagnosis.Pod, diagnosis.Container = pod.Name, containerStatus.Name
+				recorder.Warningf("ConsoleCrashLoopDiagnosed", "%s", diagnosis)
+			}
+			diagnosed[key] = diagnosis
+			diagnoses = append(diagnoses, diagnosis)
+		}
+	}
+	c.diagnosed = diagnosed
+
+	if len(diagnoses) == 0 {
+		return "", nil
+	}
+	messages := make([]string, 0, len(diagnoses))
+	for _, diagnosis := range diagnoses {
+		messages = append(messages, diagnosis.String())
+	}
+	// the first failure signature names the condition, the console pods share their config
+	return diagnoses[0].Reason, fmt.Errorf("%s", strings.Join(messages, "\n"))
+}
+
+// previousLogTail returns the end of the log of the crashed container, nothing if it cannot be read.
+func (c *CrashLoopDiagnosisController) previousLogTail(ctx context.Context, podName, containerName string) string {
+	tailLines := int64(logTailLines)
+	logs, err := c.podClient.Pods(api.OpenShiftConsoleNamespace).GetLogs(podName, &corev1.PodLogOptions{
+		Container: containerName,
+		Previous:  true,
+		TailLines: &tailLines,
+	}).DoRaw(ctx)
+	if err != nil {
+		klog.V(4).Infof("failed to read the previous log of %s/%s: %v", podName, containerName, err)
+		return ""
+	}
+	return string(logs)
+}
+
+// diagnose classifies the last termination of a container and its output.
+func diagnose(terminated *corev1.ContainerStateTerminated, output string) Diagnosis {
+	diagnosis := Diagnosis{Reason: "CrashLoop", ExitCode: terminated.ExitCode}
+	line := lastLine(output)
+	switch {
+	case terminated.Reason == "OOMKilled":
+		diagnosis.Reason = "OutOfMemory"
+		line = ""
+	case invalidConfigRegexp.MatchString(output):
+		diagnosis.Reason = "InvalidConfig"
+		line = matchingLine(output, invalidConfigRegexp)
+		if match := configKeyRegexp.FindStringSubmatch(line); match != nil {
+			diagnosis.ConfigKey = match[1]
+		}
+	case tlsErrorRegexp.MatchString(output):
+		diagnosis.Reason = "TLSError"
+		line = matchingLine(output, tlsErrorRegexp)
+	}
+	if len(line) > maxExcerpt {
+		line = line[:maxExcerpt] + "..."
+	}
+	diagnosis.Excerpt = line
+	return diagnosis
+}
+
+func matchingLine(output string, signature *regexp.Regexp) string {
+	lines := strings.Split(output, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if signature.MatchString(lines[i]) {
+			return strings.TrimSpace(lines[i])
+		}
+	}
+	return ""
+}
+
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+func isConsolePod(obj interface{}) bool {
+	pod, ok := obj.(metav1.Object)
+	if !ok {
+		return false
+	}
+	return labels.SelectorFromSet(utilsub.LabelsForConsole()).Matches(labels.Set(pod.GetLabels()))
+}
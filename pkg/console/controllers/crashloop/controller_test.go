@@ -0,0 +1,77 @@
+package crashloop
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestDiagnose(t *testing.T) {
+	tests := []struct {
+		name       string
+		terminated *corev1.ContainerStateTerminated
+		output     string
+		want       Diagnosis
+	}{
+		{
+			name:       "Test unknown config key",
+			terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"},
+			output:     "I1015 10:00:00.000000 1 main.go:200] starting\nF1015 10:00:00.000001 1 config.go:42] error unmarshaling JSON: while decoding JSON: json: unknown field \"sesion\"\n",
+			want: Diagnosis{
+				Reason:    "InvalidConfig",
+				ConfigKey: "sesion",
+				ExitCode:  1,
+				Excerpt:   `F1015 10:00:00.000001 1 config.go:42] error unmarshaling JSON: while decoding JSON: json: unknown field "sesion"`,
+			},
+		},
+		{
+			name:       "Test config value of the wrong type",
+			terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"},
+			output:     "json: cannot unmarshal string into Go struct field Session.session.cookieMaxAge of type int",
+			want: Diagnosis{
+				Reason:    "InvalidConfig",
+				ConfigKey: "Session.session.cookieMaxAge",
+				ExitCode:  1,
+				Excerpt:   "json: cannot unmarshal string into Go struct field Session.session.cookieMaxAge of type int",
+			},
+		},
+		{
+			name:       "Test TLS error",
+			terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"},
+			output:     "F1015 auth.go:100] error contacting auth provider: Get \"https://oauth-openshift.apps.example.com\": x509: certificate signed by unknown authority\n",
+			want: Diagnosis{
+				Reason:   "TLSError",
+				ExitCode: 1,
+				Excerpt:  `F1015 auth.go:100] error contacting auth provider: Get "https://oauth-openshift.apps.example.com": x509: certificate signed by unknown authority`,
+			},
+		},
+		{
+			name:       "Test out of memory",
+			terminated: &corev1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"},
+			output:     "I1015 main.go:200] serving\n",
+			want: Diagnosis{
+				Reason:   "OutOfMemory",
+				ExitCode: 137,
+			},
+		},
+		{
+			name:       "Test unknown failure",
+			terminated: &corev1.ContainerStateTerminated{ExitCode: 2, Reason: "Error"},
+			output:     "panic: runtime error: invalid memory address or nil pointer dereference\n",
+			want: Diagnosis{
+				Reason:   "CrashLoop",
+				ExitCode: 2,
+				Excerpt:  "panic: runtime error: invalid memory address or nil pointer dereference",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(diagnose(tt.terminated, tt.output), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -6,10 +6,14 @@ import (
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	appsinformersv1 "k8s.io/client-go/informers/apps/v1"
+	coreinformersv1 "k8s.io/client-go/informers/core/v1"
 	appsclientv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 
 	configv1 "github.com/openshift/api/config/v1"
@@ -19,6 +23,7 @@ import (
 	operatorinformerv1 "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
 	operatorlistersv1 "github.com/openshift/client-go/operator/listers/operator/v1"
 	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
 	"github.com/openshift/console-operator/pkg/console/status"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
@@ -37,6 +42,7 @@ type DownloadsDeploymentSyncController struct {
 	infrastructureLister  configlistersv1.InfrastructureLister
 	// core kube
 	deploymentClient appsclientv1.DeploymentsGetter
+	nodeLister       corev1listers.NodeLister
 }
 
 func NewDownloadsDeploymentSyncController(
@@ -48,6 +54,7 @@ func NewDownloadsDeploymentSyncController(
 	// core kube
 	deploymentClient appsclientv1.DeploymentsGetter,
 	deploymentInformer appsinformersv1.DeploymentInformer,
+	nodeInformer coreinformersv1.NodeInformer,
 	// events
 	recorder events.Recorder,
 ) factory.Controller {
@@ -60,6 +67,7 @@ func NewDownloadsDeploymentSyncController(
 		infrastructureLister:  configInformer.Config().V1().Infrastructures().Lister(),
 		// client
 		deploymentClient: deploymentClient,
+		nodeLister:       nodeInformer.Lister(),
 	}
 
 	configNameFilter := util.IncludeNamesFilter(api.ConfigResourceName)
@@ -73,6 +81,9 @@ func NewDownloadsDeploymentSyncController(
 		).WithFilteredEventsInformers( // downloads deployment
 		downloadsNameFilter,
 		deploymentInformer.Informer(),
+	).WithFilteredEventsInformers( // infra nodes
+		ctrl.infraNodeFilter,
+		nodeInformer.Informer(),
 	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
 		ToController("ConsoleDownloadsDeploymentSyncController", recorder.WithComponentSuffix("console-downloads-deployment-controller"))
 }
@@ -84,7 +95,7 @@ func (c *DownloadsDeploymentSyncController) Sync(ctx context.Context, controller
 	}
 	operatorConfigCopy := operatorConfig.DeepCopy()
 
-	switch operatorConfigCopy.Spec.ManagementState {
+	switch consolecapability.ManagementState(operatorConfigCopy) {
 	case operatorv1.Managed:
 		klog.V(4).Infoln("console is in a managed state: syncing downloads deployment")
 	case operatorv1.Unmanaged:
@@ -104,7 +115,24 @@ func (c *DownloadsDeploymentSyncController) Sync(ctx context.Context, controller
 		return statusHandler.FlushAndReturn(err)
 	}
 
-	actualDownloadsDownloadsDeployment, _, downloadsDeploymentErr := c.SyncDownloadsDeployment(ctx, operatorConfigCopy, infrastructureConfig, controllerContext)
+	// invalid resources keep the default resources
+	_, resourcesErr := deploymentsub.DownloadsResources(operatorConfigCopy)
+	statusHandler.AddCondition(status.HandleDegraded("DownloadsResources", "InvalidResources", resourcesErr))
+
+	// infra nodes are only looked for once the downloads are opted in to run on them
+	var nodes []*corev1.Node
+	var infraNodePlacementErr error
+	if deploymentsub.IsInfraNodePlacementEnabled(operatorConfigCopy) {
+		nodes, err = c.nodeLister.List(labels.Everything())
+		statusHandler.AddCondition(status.HandleDegraded("DownloadsDeploymentSync", "FailedListNodes", err))
+		if err != nil {
+			return statusHandler.FlushAndReturn(err)
+		}
+		_, infraNodePlacementErr = deploymentsub.GetInfraNodePlacement(operatorConfigCopy, deploymentsub.DefaultDownloadsDeployment(operatorConfigCopy, infrastructureConfig), nodes)
+	}
+	statusHandler.AddCondition(status.HandleDegraded("DownloadsInfraNodePlacement", "InsufficientInfraNodes", infraNodePlacementErr))
+
+	actualDownloadsDownloadsDeployment, _, downloadsDeploymentErr := c.SyncDownloadsDeployment(ctx, operatorConfigCopy, infrastructureConfig, nodes, controllerContext)
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded("DownloadsDeploymentSync", "FailedApply", downloadsDeploymentErr))
 	if downloadsDeploymentErr != nil {
 		return statusHandler.FlushAndReturn(downloadsDeploymentErr)
@@ -114,9 +142,21 @@ func (c *DownloadsDeploymentSyncController) Sync(ctx context.Context, controller
 	return statusHandler.FlushAndReturn(nil)
 }
 
-func (c *DownloadsDeploymentSyncController) SyncDownloadsDeployment(ctx context.Context, operatorConfigCopy *operatorv1.Console, infrastructureConfig *configv1.Infrastructure, controllerContext factory.SyncContext) (*appsv1.Deployment, bool, error) {
+// infraNodeFilter passes the events of the infra nodes once the downloads are opted in to run on
+// them, the other nodes play no part in their placement.
+func (c *DownloadsDeploymentSyncController) infraNodeFilter(obj interface{}) bool {
+	node, ok := obj.(*corev1.Node)
+	if !ok || !deploymentsub.IsInfraNode(node) {
+		return false
+	}
+	operatorConfig, err := c.consoleOperatorLister.Get(api.ConfigResourceName)
+	return err == nil && deploymentsub.IsInfraNodePlacementEnabled(operatorConfig)
+}
+
+func (c *DownloadsDeploymentSyncController) SyncDownloadsDeployment(ctx context.Context, operatorConfigCopy *operatorv1.Console, infrastructureConfig *configv1.Infrastructure, nodes []*corev1.Node, controllerContext factory.SyncContext) (*appsv1.Deployment, bool, error) {
 
 	requiredDownloadsDeployment := deploymentsub.DefaultDownloadsDeployment(operatorConfigCopy, infrastructureConfig)
+	deploymentsub.WithInfraNodePlacement(requiredDownloadsDeployment, operatorConfigCopy, nodes)
 
 	return resourceapply.ApplyDeployment(ctx,
 		c.deploymentClient,
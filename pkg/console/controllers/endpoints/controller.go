@@ -0,0 +1,172 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	// kube
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	configinformer "github.com/openshift/client-go/config/informers/externalversions"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	consoleinformersv1 "github.com/openshift/client-go/console/informers/externalversions/console/v1"
+	consolelistersv1 "github.com/openshift/client-go/console/listers/console/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	routesinformersv1 "github.com/openshift/client-go/route/informers/externalversions/route/v1"
+	routev1listers "github.com/openshift/client-go/route/listers/route/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
+	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+// ConsoleEndpointsController publishes the console URL, the downloads URL, the custom hostnames
+// of their routes and the version of the plugin catalog in the console-endpoints ConfigMap of
+// openshift-config-managed, the canonical place for other operators to read them from. It
+// follows the routes as they are admitted and the enabled plugins as they change.
+//
+//	writes:
+//	- configmaps openshift-config-managed/console-endpoints
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=ConsoleEndpointsDegraded
+type ConsoleEndpointsController struct {
+	operatorClient       v1helpers.OperatorClient
+	operatorConfigLister operatorv1listers.ConsoleLister
+	ingressConfigLister  configlistersv1.IngressLister
+	routeLister          routev1listers.RouteLister
+	consolePluginLister  consolelistersv1.ConsolePluginLister
+	configMapClient      coreclientv1.ConfigMapsGetter
+}
+
+func NewConsoleEndpointsController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	// informers
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	configInformer configinformer.SharedInformerFactory,
+	routeInformer routesinformersv1.RouteInformer,
+	consolePluginInformer consoleinformersv1.ConsolePluginInformer,
+	managedNSConfigMapInformer corev1informers.ConfigMapInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	configV1Informers := configInformer.Config().V1()
+
+	ctrl := &ConsoleEndpointsController{
+		operatorClient:       operatorClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		ingressConfigLister:  configV1Informers.Ingresses().Lister(),
+		routeLister:          routeInformer.Lister(),
+		consolePluginLister:  consolePluginInformer.Lister(),
+		configMapClient:      configMapClient,
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+			configV1Informers.Ingresses().Informer(),
+		).WithFilteredEventsInformers( // routes
+		util.IncludeNamesFilter(
+			api.OpenShiftConsoleRouteName,
+			api.OpenshiftConsoleCustomRouteName,
+			api.OpenShiftConsoleDownloadsRouteName,
+			api.OpenshiftDownloadsCustomRouteName,
+		),
+		routeInformer.Informer(),
+	).WithInformers(
+		consolePluginInformer.Informer(),
+	).WithFilteredEventsInformers( // console-endpoints
+		util.IncludeNamesFilter(api.ConsoleEndpointsConfigMapName),
+		managedNSConfigMapInformer.Informer(),
+	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController("ConsoleEndpointsController", recorder.WithComponentSuffix("console-endpoints-controller"))
+}
+
+func (c *ConsoleEndpointsController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch consolecapability.ManagementState(operatorConfig) {
+	case operatorsv1.Managed:
+		klog.V(4).Infoln("console-operator is in a managed state: publishing console endpoints")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infoln("console-operator is in an unmanaged state: skipping console endpoints publishing")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infoln("console-operator is in a removed state: the teardown controller deletes the console endpoints")
+		return nil
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	reason, endpointsErr := c.publishEndpoints(ctx, operatorConfig, controllerContext.Recorder())
+	statusHandler.AddCondition(status.HandleDegraded("ConsoleEndpoints", reason, endpointsErr))
+	return statusHandler.FlushAndReturn(endpointsErr)
+}
+
+func (c *ConsoleEndpointsController) publishEndpoints(ctx context.Context, operatorConfig *operatorsv1.Console, recorder events.Recorder) (string, error) {
+	ingressConfig, err := c.ingressConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return "FailedGetIngressConfig", err
+	}
+
+	endpoints := configmapsub.ConsoleEndpoints{}
+	for _, routeName := range []string{api.OpenShiftConsoleRouteName, api.OpenShiftConsoleDownloadsRouteName} {
+		activeRouteName := routeName
+		routeConfig := routesub.NewRouteConfig(operatorConfig, ingressConfig, routeName)
+		if routeConfig.IsCustomHostnameSet() {
+			activeRouteName = routesub.GetCustomRouteName(routeName)
+			endpoints.CustomHostnames = append(endpoints.CustomHostnames, routeConfig.GetCustomRouteHostname())
+		}
+		// the endpoints are published once the routes are admitted, the route controllers report why they are not
+		_, routeURL, reason, err := routesub.GetActiveRouteInfo(c.routeLister, activeRouteName)
+		if err != nil {
+			return reason, fmt.Errorf("route %s: %w", activeRouteName, err)
+		}
+		if routeName == api.OpenShiftConsoleRouteName {
+			endpoints.ConsoleURL = routeURL.String()
+		} else {
+			endpoints.DownloadsURL = routeURL.String()
+		}
+	}
+
+	plugins := []*consolev1.ConsolePlugin{}
+	for _, name := range utilsub.RemoveDuplicateStr(operatorConfig.Spec.Plugins) {
+		plugin, err := c.consolePluginLister.Get(name)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "FailedGetPlugin", err
+		}
+		plugins = append(plugins, plugin)
+	}
+	endpoints.PluginCatalogVersion = configmapsub.PluginCatalogVersion(plugins)
+
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, recorder, configmapsub.DefaultConsoleEndpointsConfigMap(endpoints)); err != nil {
+		return "FailedApply", err
+	}
+	return "", nil
+}
@@ -0,0 +1,199 @@
+package featuregates
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	// kube
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	// openshift
+	configv1 "github.com/openshift/api/config/v1"
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+)
+
+const (
+	// the condition is informative only, it lists the active feature gates and gated controllers
+	conditionType = "FeatureGatesObserved"
+
+	// FeatureGateExternalOIDC enables the authentication with an external OIDC provider, the
+	// vendored openshift/api does not define it yet
+	FeatureGateExternalOIDC = configv1.FeatureGateName("ExternalOIDC")
+)
+
+// GatedController is a controller only run while its feature gate is enabled.
+type GatedController struct {
+	Name        string
+	FeatureGate configv1.FeatureGateName
+	// New builds the controller each time the gate is enabled, a stopped controller
+	// cannot be started again
+	New func() factory.Controller
+}
+
+// FeatureGateController starts and stops the gated controllers as the feature gates of the
+// operator version are enabled and disabled in featuregates.config.openshift.io/cluster, so
+// that flipping a gate does not need an operator restart. The gated controllers run until the
+// operator stops or their gate is disabled.
+//
+//	writes:
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=FeatureGatesObserved
+//		- type=FeatureGatesProgressing
+type FeatureGateController struct {
+	operatorClient    v1helpers.OperatorClient
+	featureGateLister configv1listers.FeatureGateLister
+	version           string
+	controllers       []GatedController
+
+	lock    sync.Mutex
+	running map[string]context.CancelFunc
+}
+
+func NewFeatureGateController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	// informers
+	featureGateInformer configv1informers.FeatureGateInformer,
+	// the payload version the feature gates are read for
+	version string,
+	controllers []GatedController,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &FeatureGateController{
+		operatorClient:    operatorClient,
+		featureGateLister: featureGateInformer.Lister(),
+		version:           version,
+		controllers:       controllers,
+		running:           map[string]context.CancelFunc{},
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			featureGateInformer.Informer(),
+		).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController("FeatureGateController", recorder.WithComponentSuffix("feature-gate-controller"))
+}
+
+// Sync runs the gated controllers on ctx, the context of the FeatureGateController itself.
+func (c *FeatureGateController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+
+	// an operator run without a release version, eg. locally, has no feature gates to follow
+	if len(c.version) == 0 {
+		klog.V(4).Infoln("feature gates: no operator version, the gated controllers are not run")
+		statusHandler.AddCondition(status.HandleProgressing("FeatureGates", "", nil))
+		statusHandler.AddCondition(handleUnversioned())
+		return statusHandler.FlushAndReturn(nil)
+	}
+
+	featureGate, err := c.featureGateLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+	enabled, err := EnabledFeatureGates(featureGate, c.version)
+	// the feature gates of a new version may not be rendered yet during an upgrade, the
+	// gated controllers keep running as they are meanwhile
+	statusHandler.AddCondition(status.HandleProgressing("FeatureGates", "VersionNotFound", err))
+	if err != nil {
+		klog.V(4).Infof("feature gates: %v", err)
+		return statusHandler.FlushAndReturn(nil)
+	}
+
+	running := c.reconcile(ctx, enabled, controllerContext.Recorder())
+	statusHandler.AddCondition(handleObserved(c.version, enabled, running))
+	return statusHandler.FlushAndReturn(nil)
+}
+
+// reconcile starts the gated controllers whose gate is enabled and stops the others, it
+// returns the names of the running controllers.
+func (c *FeatureGateController) reconcile(ctx context.Context, enabled sets.String, recorder events.Recorder) []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, controller := range c.controllers {
+		cancel, isRunning := c.running[controller.Name]
+		switch {
+		case enabled.Has(string(controller.FeatureGate)) && !isRunning:
+			controllerCtx, cancel := context.WithCancel(ctx)
+			c.running[controller.Name] = cancel
+			go controller.New().Run(controllerCtx, 1)
+			recorder.Eventf("FeatureGateControllerStarted", "started %s, feature gate %s is enabled", controller.Name, controller.FeatureGate)
+		case !enabled.Has(string(controller.FeatureGate)) && isRunning:
+			cancel()
+			delete(c.running, controller.Name)
+			recorder.Eventf("FeatureGateControllerStopped", "stopped %s, feature gate %s is disabled", controller.Name, controller.FeatureGate)
+		}
+	}
+
+	running := []string{}
+	for name := range c.running {
+		running = append(running, name)
+	}
+	sort.Strings(running)
+	return running
+}
+
+// EnabledFeatureGates returns the feature gates enabled for version.
+func EnabledFeatureGates(featureGate *configv1.FeatureGate, version string) (sets.String, error) {
+	for _, details := range featureGate.Status.FeatureGates {
+		if details.Version != version {
+			continue
+		}
+		enabled := sets.NewString()
+		for _, attributes := range details.Enabled {
+			enabled.Insert(string(attributes.Name))
+		}
+		return enabled, nil
+	}
+	return nil, fmt.Errorf("no feature gates for version %q in featuregates.config.openshift.io/%s", version, featureGate.Name)
+}
+
+func handleObserved(version string, enabled sets.String, running []string) status.ConditionUpdate {
+	condition := operatorsv1.OperatorCondition{
+		Type:    conditionType,
+		Status:  operatorsv1.ConditionTrue,
+		Reason:  "AsExpected",
+		Message: fmt.Sprintf("version %s enables feature gates: %s; running gated controllers: %s", version, describe(enabled.List()), describe(running)),
+	}
+	return status.ConditionUpdate{
+		ConditionType:  condition.Type,
+		StatusUpdateFn: v1helpers.UpdateConditionFn(condition),
+	}
+}
+
+func handleUnversioned() status.ConditionUpdate {
+	condition := operatorsv1.OperatorCondition{
+		Type:    conditionType,
+		Status:  operatorsv1.ConditionFalse,
+		Reason:  "NoOperatorVersion",
+		Message: "the operator runs without RELEASE_VERSION, the feature gates are not read and no gated controller is run",
+	}
+	return status.ConditionUpdate{
+		ConditionType:  condition.Type,
+		StatusUpdateFn: v1helpers.UpdateConditionFn(condition),
+	}
+}
+
+func describe(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
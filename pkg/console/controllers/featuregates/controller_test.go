@@ -0,0 +1,123 @@
+package featuregates
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-test/deep"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestEnabledFeatureGates(t *testing.T) {
+	featureGate := &configv1.FeatureGate{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status: configv1.FeatureGateStatus{
+			FeatureGates: []configv1.FeatureGateDetails{
+				{
+					Version:  "4.15.0",
+					Enabled:  []configv1.FeatureGateAttributes{{Name: "Foo"}},
+					Disabled: []configv1.FeatureGateAttributes{{Name: "Bar"}},
+				},
+				{
+					Version:  "4.16.0",
+					Enabled:  []configv1.FeatureGateAttributes{{Name: "Foo"}, {Name: "Bar"}},
+					Disabled: []configv1.FeatureGateAttributes{},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		version string
+		want    sets.String
+		wantErr bool
+	}{
+		{
+			name:    "Test feature gates of the operator version",
+			version: "4.15.0",
+			want:    sets.NewString("Foo"),
+		},
+		{
+			name:    "Test feature gates of another version",
+			version: "4.16.0",
+			want:    sets.NewString("Foo", "Bar"),
+		},
+		{
+			name:    "Test version without feature gates",
+			version: "4.17.0",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EnabledFeatureGates(featureGate, tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+type fakeController struct {
+	factory.Controller
+	stopped chan struct{}
+}
+
+func (c *fakeController) Run(ctx context.Context, workers int) {
+	<-ctx.Done()
+	close(c.stopped)
+}
+
+func TestReconcile(t *testing.T) {
+	started := []*fakeController{}
+	c := &FeatureGateController{
+		controllers: []GatedController{
+			{
+				Name:        "FooController",
+				FeatureGate: "Foo",
+				New: func() factory.Controller {
+					controller := &fakeController{stopped: make(chan struct{})}
+					started = append(started, controller)
+					return controller
+				},
+			},
+		},
+		running: map[string]context.CancelFunc{},
+	}
+	recorder := events.NewInMemoryRecorder("test")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if diff := deep.Equal(c.reconcile(ctx, sets.NewString("Bar"), recorder), []string{}); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(c.reconcile(ctx, sets.NewString("Foo"), recorder), []string{"FooController"}); diff != nil {
+		t.Error(diff)
+	}
+	// an enabled gate does not start the controller twice
+	c.reconcile(ctx, sets.NewString("Foo"), recorder)
+	if len(started) != 1 {
+		t.Fatalf("expected the controller to be started once, got %d", len(started))
+	}
+
+	if diff := deep.Equal(c.reconcile(ctx, sets.NewString(), recorder), []string{}); diff != nil {
+		t.Error(diff)
+	}
+	<-started[0].stopped
+
+	// a controller is built again when its gate is enabled again
+	c.reconcile(ctx, sets.NewString("Foo"), recorder)
+	if len(started) != 2 {
+		t.Fatalf("expected the controller to be started again, got %d starts", len(started))
+	}
+}
@@ -0,0 +1,312 @@
+package fipscompliance
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	// kube
+	"gopkg.in/yaml.v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	appsv1informers "k8s.io/client-go/informers/apps/v1"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/metrics"
+	"github.com/openshift/console-operator/pkg/console/status"
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
+	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+// fipsProbeTimeout bounds the TLS handshakes made with the console service
+const fipsProbeTimeout = 5 * time.Second
+
+// consoleServiceAddress is where the console pods serve TLS, probed for the TLS parameters they
+// actually accept.
+var consoleServiceAddress = fmt.Sprintf("%s.%s.svc:%d", api.OpenShiftConsoleServiceName, api.OpenShiftConsoleNamespace, api.ConsoleContainerPort)
+
+var (
+	// TLS versions below 1.2 are not FIPS 140-2/140-3 approved.
+	nonCompliantTLSVersions = map[string]bool{
+		"VersionTLS10": true,
+		"VersionTLS11": true,
+	}
+
+	// FIPS approved cipher suites, named as in the console servingInfo.
+	compliantCipherSuites = map[string]bool{
+		"TLS_AES_128_GCM_SHA256":                  true,
+		"TLS_AES_256_GCM_SHA384":                  true,
+		"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256": true,
+		"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384": true,
+		"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256":   true,
+		"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384":   true,
+	}
+)
+
+// FIPSComplianceController verifies, when the cluster is installed in FIPS mode, that the console
+// is FIPS conformant:
+//
+//   - the console deployment runs the console image of the release payload, which is built for
+//     FIPS mode, rather than an image the payload does not vouch for
+//
+//   - the console TLS configuration only allows FIPS approved TLS versions and cipher suites
+//
+//   - the running console pods refuse handshakes with TLS versions and cipher suites that are
+//     not approved, whatever their configuration says. Parameters the operator itself cannot
+//     offer are taken as refused.
+//
+//     writes:
+//
+//   - consoles.operator.openshift.io/cluster .status.conditions:
+//
+//   - type=FIPSComplianceDegraded
+//
+//   - metric console_operator_fips_compliance
+type FIPSComplianceController struct {
+	operatorClient            v1helpers.OperatorClient
+	operatorConfigLister      operatorv1listers.ConsoleLister
+	targetNSConfigMapLister   corev1listers.ConfigMapLister
+	kubeSystemConfigMapLister corev1listers.ConfigMapLister
+	targetNSDeploymentLister  appsv1listers.DeploymentLister
+}
+
+func NewFIPSComplianceController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	// informers
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	targetNSConfigMapInformer corev1informers.ConfigMapInformer,
+	kubeSystemConfigMapInformer corev1informers.ConfigMapInformer,
+	targetNSDeploymentInformer appsv1informers.DeploymentInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &FIPSComplianceController{
+		operatorClient:            operatorClient,
+		operatorConfigLister:      operatorConfigInformer.Lister(),
+		targetNSConfigMapLister:   targetNSConfigMapInformer.Lister(),
+		kubeSystemConfigMapLister: kubeSystemConfigMapInformer.Lister(),
+		targetNSDeploymentLister:  targetNSDeploymentInformer.Lister(),
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+		).WithFilteredEventsInformers( // console-config and the service CA bundle
+		util.IncludeNamesFilter(api.OpenShiftConsoleConfigMapName, api.ServiceCAConfigMapName),
+		targetNSConfigMapInformer.Informer(),
+	).WithFilteredEventsInformers( // install-config
+		util.IncludeNamesFilter(api.InstallConfigConfigMapName),
+		kubeSystemConfigMapInformer.Informer(),
+	).WithFilteredEventsInformers( // console deployment
+		util.IncludeNamesFilter(api.OpenShiftConsoleDeploymentName),
+		targetNSDeploymentInformer.Informer(),
+	).ResyncEvery(10*time.Minute).WithSync(ctrl.Sync).
+		ToController("FIPSComplianceController", recorder.WithComponentSuffix("fips-compliance-controller"))
+}
+
+func (c *FIPSComplianceController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch consolecapability.ManagementState(operatorConfig) {
+	case operatorsv1.Managed:
+		klog.V(4).Infoln("console-operator is in a managed state: checking FIPS compliance")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infoln("console-operator is in an unmanaged state: skipping FIPS compliance check")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infoln("console-operator is in a removed state: skipping FIPS compliance check")
+		return nil
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+
+	fipsEnabled, reason, err := c.isFIPSEnabled()
+	if err != nil {
+		statusHandler.AddCondition(status.HandleDegraded("FIPSCompliance", reason, err))
+		return statusHandler.FlushAndReturn(err)
+	}
+	if !fipsEnabled {
+		metrics.HandleFIPSCompliance(false, true)
+		statusHandler.AddCondition(status.HandleDegraded("FIPSCompliance", "", nil))
+		return statusHandler.FlushAndReturn(nil)
+	}
+
+	reasons := []string{}
+	errs := []error{}
+	for _, check := range []func(context.Context) (string, error){c.checkConsoleImage, c.checkConsoleConfig, c.checkConsoleRuntime} {
+		if reason, err := check(ctx); err != nil {
+			reasons = append(reasons, reason)
+			errs = append(errs, err)
+		}
+	}
+	metrics.HandleFIPSCompliance(true, len(errs) == 0)
+	if len(errs) > 0 {
+		statusHandler.AddCondition(status.HandleDegraded("FIPSCompliance", reasons[0], errors.Join(errs...)))
+	} else {
+		statusHandler.AddCondition(status.HandleDegraded("FIPSCompliance", "", nil))
+	}
+	return statusHandler.FlushAndReturn(nil)
+}
+
+// isFIPSEnabled reads whether the cluster was installed in FIPS mode from its install-config.
+func (c *FIPSComplianceController) isFIPSEnabled() (bool, string, error) {
+	installConfigMap, err := c.kubeSystemConfigMapLister.ConfigMaps(api.KubeSystemNamespace).Get(api.InstallConfigConfigMapName)
+	if apierrors.IsNotFound(err) {
+		// clusters not installed by the installer, eg. hosted control planes, have none
+		return false, "", nil
+	}
+	if err != nil {
+		return false, "FailedGetInstallConfig", err
+	}
+	return parseInstallConfigFIPS(installConfigMap.Data[api.InstallConfigKey])
+}
+
+func parseInstallConfigFIPS(installConfig string) (bool, string, error) {
+	parsed := struct {
+		FIPS bool `yaml:"fips"`
+	}{}
+	if err := yaml.Unmarshal([]byte(installConfig), &parsed); err != nil {
+		return false, "FailedParseInstallConfig", fmt.Errorf("failed to parse %s/%s: %w", api.KubeSystemNamespace, api.InstallConfigConfigMapName, err)
+	}
+	return parsed.FIPS, "", nil
+}
+
+// checkConsoleImage reports a console deployment running another image than the console image
+// of the release payload.
+func (c *FIPSComplianceController) checkConsoleImage(ctx context.Context) (string, error) {
+	deployment, err := c.targetNSDeploymentLister.Deployments(api.OpenShiftConsoleNamespace).Get(api.OpenShiftConsoleDeploymentName)
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "FailedGet", err
+	}
+	releaseImage := utilsub.GetImageEnv("CONSOLE_IMAGE")
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if container.Name == api.OpenShiftConsoleName && container.Image != releaseImage {
+			return "NonReleaseImage", fmt.Errorf("console container runs image %s instead of the release image %s built for FIPS mode", container.Image, releaseImage)
+		}
+	}
+	return "", nil
+}
+
+func (c *FIPSComplianceController) checkConsoleConfig(ctx context.Context) (string, error) {
+	consoleConfigMap, err := c.targetNSConfigMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(api.OpenShiftConsoleConfigMapName)
+	if apierrors.IsNotFound(err) {
+		// nothing rendered yet, the console sync loop has not caught up
+		return "", nil
+	}
+	if err != nil {
+		return "FailedGet", err
+	}
+
+	var consoleConfig consoleserver.Config
+	if err := yaml.Unmarshal([]byte(consoleConfigMap.Data["console-config.yaml"]), &consoleConfig); err != nil {
+		return "FailedParse", fmt.Errorf("failed to parse console-config.yaml: %w", err)
+	}
+
+	return validateServingInfo(consoleConfig.ServingInfo)
+}
+
+// checkConsoleRuntime reports the TLS parameters that are not FIPS approved the console pods accept
+// a handshake with.
+func (c *FIPSComplianceController) checkConsoleRuntime(ctx context.Context) (string, error) {
+	serviceCA, err := c.targetNSConfigMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(api.ServiceCAConfigMapName)
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "FailedGet", err
+	}
+	rootCAs := x509.NewCertPool()
+	rootCAs.AppendCertsFromPEM([]byte(serviceCA.Data["service-ca.crt"]))
+
+	accepted := acceptedNonApprovedTLS(ctx, consoleServiceAddress, rootCAs)
+	if len(accepted) > 0 {
+		return "NonCompliantTLSRuntime", fmt.Errorf("console pods accept TLS parameters that are not FIPS approved: %s", strings.Join(accepted, ", "))
+	}
+	return "", nil
+}
+
+// nonApprovedCipherSuites are TLS 1.2 cipher suites that are not FIPS approved
+var nonApprovedCipherSuites = []uint16{
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA,
+	tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+}
+
+// acceptedNonApprovedTLS handshakes with the TLS server at address offering only TLS versions
+// below 1.2, then only cipher suites that are not FIPS approved, and lists what it accepted. A
+// server that cannot be reached at all accepts nothing.
+func acceptedNonApprovedTLS(ctx context.Context, address string, rootCAs *x509.CertPool) []string {
+	serverName, _, _ := strings.Cut(address, ":")
+	accepted := []string{}
+	for _, config := range []*tls.Config{
+		{MinVersion: tls.VersionTLS10, MaxVersion: tls.VersionTLS11},
+		{MinVersion: tls.VersionTLS12, MaxVersion: tls.VersionTLS12, CipherSuites: nonApprovedCipherSuites},
+	} {
+		config.RootCAs = rootCAs
+		config.ServerName = serverName
+		dialer := &tls.Dialer{NetDialer: &net.Dialer{Timeout: fipsProbeTimeout}, Config: config}
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			klog.V(4).Infof("console refused a TLS handshake that is not FIPS approved: %v", err)
+			continue
+		}
+		state := conn.(*tls.Conn).ConnectionState()
+		conn.Close()
+		if state.Version < tls.VersionTLS12 {
+			accepted = append(accepted, tls.VersionName(state.Version))
+		} else {
+			accepted = append(accepted, fmt.Sprintf("cipher suite %s", tls.CipherSuiteName(state.CipherSuite)))
+		}
+	}
+	return accepted
+}
+
+// validateServingInfo returns an error naming every TLS parameter that is not FIPS approved.
+func validateServingInfo(servingInfo consoleserver.ServingInfo) (string, error) {
+	violations := []string{}
+	if nonCompliantTLSVersions[servingInfo.MinTLSVersion] {
+		violations = append(violations, fmt.Sprintf("minTLSVersion %s", servingInfo.MinTLSVersion))
+	}
+	for _, cipher := range servingInfo.CipherSuites {
+		if !compliantCipherSuites[cipher] {
+			violations = append(violations, fmt.Sprintf("cipher suite %s", cipher))
+		}
+	}
+	if len(violations) > 0 {
+		return "NonCompliantTLSConfig", fmt.Errorf("console TLS configuration allows TLS parameters that are not FIPS approved: %s", strings.Join(violations, ", "))
+	}
+	return "", nil
+}
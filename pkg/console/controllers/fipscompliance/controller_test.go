@@ -0,0 +1,155 @@
+package fipscompliance
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-test/deep"
+
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
+)
+
+func TestValidateServingInfo(t *testing.T) {
+	tests := []struct {
+		name        string
+		servingInfo consoleserver.ServingInfo
+		wantReason  string
+		wantErr     bool
+	}{
+		{
+			name:        "Test default serving info",
+			servingInfo: consoleserver.ServingInfo{},
+			wantReason:  "",
+			wantErr:     false,
+		},
+		{
+			name: "Test compliant TLS configuration",
+			servingInfo: consoleserver.ServingInfo{
+				MinTLSVersion: "VersionTLS12",
+				CipherSuites: []string{
+					"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+					"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+					"TLS_AES_128_GCM_SHA256",
+				},
+			},
+			wantReason: "",
+			wantErr:    false,
+		},
+		{
+			name: "Test non compliant TLS version",
+			servingInfo: consoleserver.ServingInfo{
+				MinTLSVersion: "VersionTLS11",
+			},
+			wantReason: "NonCompliantTLSConfig",
+			wantErr:    true,
+		},
+		{
+			name: "Test non compliant cipher suite",
+			servingInfo: consoleserver.ServingInfo{
+				MinTLSVersion: "VersionTLS12",
+				CipherSuites: []string{
+					"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+					"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256",
+				},
+			},
+			wantReason: "NonCompliantTLSConfig",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, err := validateServingInfo(tt.servingInfo)
+			if diff := deep.Equal(reason, tt.wantReason); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateServingInfo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseInstallConfigFIPS(t *testing.T) {
+	tests := []struct {
+		name          string
+		installConfig string
+		want          bool
+		wantErr       bool
+	}{
+		{
+			name:          "Test FIPS mode enabled",
+			installConfig: "apiVersion: v1\nbaseDomain: example.com\nfips: true\n",
+			want:          true,
+		},
+		{
+			name:          "Test FIPS mode disabled",
+			installConfig: "apiVersion: v1\nbaseDomain: example.com\nfips: false\n",
+			want:          false,
+		},
+		{
+			name:          "Test FIPS mode unset",
+			installConfig: "apiVersion: v1\nbaseDomain: example.com\n",
+			want:          false,
+		},
+		{
+			name:          "Test invalid install-config",
+			installConfig: "fips: [",
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := parseInstallConfigFIPS(tt.installConfig)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseInstallConfigFIPS() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAcceptedNonApprovedTLS(t *testing.T) {
+	tests := []struct {
+		name      string
+		tlsConfig *tls.Config
+		want      []string
+	}{
+		{
+			name: "Test server only accepting FIPS approved parameters",
+			tlsConfig: &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+			},
+			want: []string{},
+		},
+		{
+			name: "Test server accepting old TLS versions and cipher suites",
+			tlsConfig: &tls.Config{
+				MinVersion:   tls.VersionTLS11,
+				CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256, tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA, tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA},
+			},
+			want: []string{"TLS 1.1", "cipher suite TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewUnstartedServer(http.NotFoundHandler())
+			server.TLS = tt.tlsConfig
+			server.StartTLS()
+			defer server.Close()
+
+			rootCAs := x509.NewCertPool()
+			rootCAs.AddCert(server.Certificate())
+			got := acceptedNonApprovedTLS(context.TODO(), server.Listener.Addr().String(), rootCAs)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
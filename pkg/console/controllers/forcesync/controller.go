@@ -0,0 +1,83 @@
+package forcesync
+
+import (
+	"context"
+	"time"
+
+	// kube
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorclientv1 "github.com/openshift/client-go/operator/clientset/versioned/typed/operator/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+)
+
+// ForceSyncController clears the console.operator.openshift.io/force-sync annotation of the
+// operator config. Setting the annotation, to a timestamp for instance, is an update of the
+// operator config every controller watches, so each of them syncs right away instead of
+// waiting for its resync interval; clearing it makes the trigger one-shot, the next value
+// set triggers a new sync.
+//
+//	writes:
+//	- consoles.operator.openshift.io/cluster .metadata.annotations:
+//		- console.operator.openshift.io/force-sync
+type ForceSyncController struct {
+	operatorConfigClient operatorclientv1.ConsoleInterface
+	operatorConfigLister operatorv1listers.ConsoleLister
+}
+
+func NewForceSyncController(
+	// clients
+	operatorConfigClient operatorclientv1.ConsoleInterface,
+	// informers
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &ForceSyncController{
+		operatorConfigClient: operatorConfigClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+		).ResyncEvery(10*time.Minute).WithSync(ctrl.Sync).
+		ToController("ForceSyncController", recorder.WithComponentSuffix("force-sync-controller"))
+}
+
+func (c *ForceSyncController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+	value, ok := operatorConfig.Annotations[api.ForceSyncAnnotation]
+	if !ok {
+		return nil
+	}
+
+	// the update carries the resourceVersion read, a newer value set meanwhile is not lost
+	updated := operatorConfig.DeepCopy()
+	delete(updated.Annotations, api.ForceSyncAnnotation)
+	_, err = c.operatorConfigClient.Update(ctx, updated, metav1.UpdateOptions{})
+	if apierrors.IsConflict(err) {
+		// the operator config changed meanwhile, retry with the latest version
+		klog.V(4).Infof("operator config changed while clearing the force-sync annotation, retrying")
+		return factory.SyntheticRequeueError
+	}
+	if err != nil {
+		return err
+	}
+	controllerContext.Recorder().Eventf("ForceSync", "synced all controllers on %s=%q", api.ForceSyncAnnotation, value)
+	return nil
+}
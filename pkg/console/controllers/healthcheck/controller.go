@@ -33,8 +33,10 @@ import (
 
 	// console-operator
 	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
 	"github.com/openshift/console-operator/pkg/console/controllers/util"
 	"github.com/openshift/console-operator/pkg/console/status"
+	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
 	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
 )
 
@@ -101,7 +103,7 @@ func (c *HealthCheckController) Sync(ctx context.Context, controllerContext fact
 
 	updatedOperatorConfig := operatorConfig.DeepCopy()
 
-	switch updatedOperatorConfig.Spec.ManagementState {
+	switch consolecapability.ManagementState(updatedOperatorConfig) {
 	case operatorsv1.Managed:
 		klog.V(4).Infoln("console-operator is in a managed state: starting health checks")
 	case operatorsv1.Unmanaged:
@@ -113,6 +115,12 @@ func (c *HealthCheckController) Sync(ctx context.Context, controllerContext fact
 	default:
 		return fmt.Errorf("unknown state: %v", updatedOperatorConfig.Spec.ManagementState)
 	}
+	// the route of a paused console has no endpoints, the health checks hold until it is resumed
+	if deploymentsub.IsPaused(updatedOperatorConfig) {
+		klog.V(4).Infoln("console is paused: skipping health checks")
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("RouteHealth", "", nil))
+		return statusHandler.FlushAndReturn(nil)
+	}
 	ingressConfig, err := c.ingressClient.Get(ctx, api.ConfigResourceName, metav1.GetOptions{})
 	if err != nil {
 		klog.Errorf("ingress config error: %v", err)
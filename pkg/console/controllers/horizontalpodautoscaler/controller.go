@@ -0,0 +1,176 @@
+package hpa
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	// k8s
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	autoscalingv2informers "k8s.io/client-go/informers/autoscaling/v2"
+	autoscalingv2client "k8s.io/client-go/kubernetes/typed/autoscaling/v2"
+	autoscalingv2listers "k8s.io/client-go/listers/autoscaling/v2"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	configinformer "github.com/openshift/client-go/config/informers/externalversions"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
+)
+
+// HorizontalPodAutoscalerController scales the console deployment with a HorizontalPodAutoscaler
+// once the autoscaling annotation of the operator config opts in, on the CPU or memory
+// utilization or on custom metrics of the console pods. The console deployment then keeps the
+// replicas the HorizontalPodAutoscaler scaled it to. A single replica topology is not
+// autoscaled, the HorizontalPodAutoscaler is removed with the annotation.
+//
+//	writes:
+//	- horizontalpodautoscalers.autoscaling/console -n openshift-console
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=AutoscalingDegraded
+//		- type=HPASyncProgressing
+//		- type=HPASyncDegraded
+type HorizontalPodAutoscalerController struct {
+	operatorClient       v1helpers.OperatorClient
+	hpaClient            autoscalingv2client.HorizontalPodAutoscalersGetter
+	operatorConfigLister operatorv1listers.ConsoleLister
+	infrastructureLister configlistersv1.InfrastructureLister
+	hpaLister            autoscalingv2listers.HorizontalPodAutoscalerLister
+}
+
+func NewHorizontalPodAutoscalerController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	hpaClient autoscalingv2client.HorizontalPodAutoscalersGetter,
+	// informers
+	configInformer configinformer.SharedInformerFactory,
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	hpaInformer autoscalingv2informers.HorizontalPodAutoscalerInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	infrastructureInformer := configInformer.Config().V1().Infrastructures()
+
+	ctrl := &HorizontalPodAutoscalerController{
+		operatorClient:       operatorClient,
+		hpaClient:            hpaClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		infrastructureLister: infrastructureInformer.Lister(),
+		hpaLister:            hpaInformer.Lister(),
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+			infrastructureInformer.Informer(),
+		).WithFilteredEventsInformers(
+		util.IncludeNamesFilter(api.OpenShiftConsoleDeploymentName),
+		hpaInformer.Informer(),
+	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController("HorizontalPodAutoscalerController", recorder.WithComponentSuffix("console-hpa-controller"))
+}
+
+func (c *HorizontalPodAutoscalerController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch consolecapability.ManagementState(operatorConfig) {
+	case operatorsv1.Managed:
+		klog.V(4).Infoln("console-operator is in a managed state: syncing the console hpa")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infoln("console-operator is in an unmanaged state: skipping the console hpa sync")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infoln("console-operator is in a removed state: deleting the console hpa")
+		return c.removeHorizontalPodAutoscaler(ctx)
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+
+	infrastructureConfig, err := c.infrastructureLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	_, requested := operatorConfig.Annotations[api.AutoscalingAnnotation]
+	if !deploymentsub.IsAutoscaled(operatorConfig, infrastructureConfig) {
+		var unsupportedErr error
+		if requested {
+			unsupportedErr = fmt.Errorf("%s is ignored, a single replica topology runs %d console replica", api.AutoscalingAnnotation, deploymentsub.SingleNodeConsoleReplicas)
+		}
+		statusHandler.AddCondition(status.HandleDegraded("Autoscaling", "AutoscalingUnsupported", unsupportedErr))
+		removeErr := c.removeHorizontalPodAutoscaler(ctx)
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("HPASync", "FailedDelete", removeErr))
+		return statusHandler.FlushAndReturn(removeErr)
+	}
+
+	// the valid autoscaling entries are applied even if some are not
+	autoscalingConfig, autoscalingErr := deploymentsub.GetAutoscalingConfig(operatorConfig, infrastructureConfig)
+	statusHandler.AddCondition(status.HandleDegraded("Autoscaling", "InvalidAutoscaling", autoscalingErr))
+
+	required := deploymentsub.DefaultHorizontalPodAutoscaler(operatorConfig, autoscalingConfig)
+	hpaErr := c.applyHorizontalPodAutoscaler(ctx, controllerContext.Recorder(), required)
+	statusHandler.AddConditions(status.HandleProgressingOrDegraded("HPASync", "FailedApply", hpaErr))
+	return statusHandler.FlushAndReturn(hpaErr)
+}
+
+// applyHorizontalPodAutoscaler creates the HorizontalPodAutoscaler, or updates its metadata and
+// spec when they differ from required.
+func (c *HorizontalPodAutoscalerController) applyHorizontalPodAutoscaler(ctx context.Context, recorder events.Recorder, required *autoscalingv2.HorizontalPodAutoscaler) error {
+	existing, err := c.hpaLister.HorizontalPodAutoscalers(required.Namespace).Get(required.Name)
+	if apierrors.IsNotFound(err) {
+		_, err = c.hpaClient.HorizontalPodAutoscalers(required.Namespace).Create(ctx, required, metav1.CreateOptions{})
+		if err == nil {
+			recorder.Eventf("HorizontalPodAutoscalerCreated", "Created HorizontalPodAutoscaler %s/%s", required.Namespace, required.Name)
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	modified := resourcemerge.BoolPtr(false)
+	existingCopy := existing.DeepCopy()
+	resourcemerge.EnsureObjectMeta(modified, &existingCopy.ObjectMeta, required.ObjectMeta)
+	if !*modified && equality.Semantic.DeepEqual(existingCopy.Spec, required.Spec) {
+		return nil
+	}
+	existingCopy.Spec = required.Spec
+	_, err = c.hpaClient.HorizontalPodAutoscalers(required.Namespace).Update(ctx, existingCopy, metav1.UpdateOptions{})
+	if err == nil {
+		recorder.Eventf("HorizontalPodAutoscalerUpdated", "Updated HorizontalPodAutoscaler %s/%s", required.Namespace, required.Name)
+	}
+	return err
+}
+
+func (c *HorizontalPodAutoscalerController) removeHorizontalPodAutoscaler(ctx context.Context) error {
+	if _, err := c.hpaLister.HorizontalPodAutoscalers(api.OpenShiftConsoleNamespace).Get(api.OpenShiftConsoleDeploymentName); apierrors.IsNotFound(err) {
+		return nil
+	}
+	err := c.hpaClient.HorizontalPodAutoscalers(api.OpenShiftConsoleNamespace).Delete(ctx, api.OpenShiftConsoleDeploymentName, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,169 @@
+package inspection
+
+import (
+	"context"
+	"time"
+
+	// kube
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	// openshift
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configinformer "github.com/openshift/client-go/config/informers/externalversions"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
+)
+
+// InspectionController dumps the state of the operator into the console-operator-inspection
+// ConfigMap, collected by must-gather along with the rest of the operator namespace: the
+// redacted operator config, the console-config in use, the metadata of the custom route TLS
+// secrets and the recent degraded conditions. The dump is kept in every management state, the
+// state is what support needs to know.
+//
+//	writes:
+//	- configmaps openshift-console-operator/console-operator-inspection
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=InspectionConfigMapDegraded
+type InspectionController struct {
+	operatorClient          v1helpers.OperatorClient
+	operatorConfigLister    operatorv1listers.ConsoleLister
+	ingressConfigLister     configlistersv1.IngressLister
+	targetNSConfigMapLister corev1listers.ConfigMapLister
+	configMapClient         coreclientv1.ConfigMapsGetter
+	secretClient            coreclientv1.SecretsGetter
+}
+
+func NewInspectionController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	secretClient coreclientv1.SecretsGetter,
+	// informers
+	configInformer configinformer.SharedInformerFactory,
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	targetNSConfigMapInformer corev1informers.ConfigMapInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	configV1Informers := configInformer.Config().V1()
+
+	ctrl := &InspectionController{
+		operatorClient:          operatorClient,
+		operatorConfigLister:    operatorConfigInformer.Lister(),
+		ingressConfigLister:     configV1Informers.Ingresses().Lister(),
+		targetNSConfigMapLister: targetNSConfigMapInformer.Lister(),
+		configMapClient:         configMapClient,
+		secretClient:            secretClient,
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs, the operator config status carries the sync errors
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+			configV1Informers.Ingresses().Informer(),
+		).WithFilteredEventsInformers( // console-config
+		util.IncludeNamesFilter(api.OpenShiftConsoleConfigMapName),
+		targetNSConfigMapInformer.Informer(),
+	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController("InspectionController", recorder.WithComponentSuffix("inspection-controller"))
+}
+
+func (c *InspectionController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	reason, err := c.syncInspection(ctx, operatorConfig, controllerContext.Recorder())
+	statusHandler.AddCondition(status.HandleDegraded("InspectionConfigMap", reason, err))
+	return statusHandler.FlushAndReturn(err)
+}
+
+func (c *InspectionController) syncInspection(ctx context.Context, operatorConfig *operatorv1.Console, recorder events.Recorder) (string, error) {
+	state := configmapsub.InspectionState{
+		ManagementState:            operatorConfig.Spec.ManagementState,
+		Generation:                 operatorConfig.Generation,
+		ObservedGeneration:         operatorConfig.Status.ObservedGeneration,
+		ReadyReplicas:              operatorConfig.Status.ReadyReplicas,
+		Annotations:                configmapsub.RedactAnnotations(operatorConfig.Annotations),
+		UnsupportedConfigOverrides: configmapsub.RedactUnsupportedConfigOverrides(operatorConfig.Spec.UnsupportedConfigOverrides.Raw),
+		Plugins:                    operatorConfig.Spec.Plugins,
+		Conditions:                 operatorConfig.Status.Conditions,
+		Generations:                operatorConfig.Status.Generations,
+	}
+
+	consoleConfigMap, err := c.targetNSConfigMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(api.OpenShiftConsoleConfigMapName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "FailedGetConsoleConfig", err
+	}
+	if err == nil {
+		state.ConsoleConfig = &configmapsub.InspectionConsoleConfig{
+			ResourceVersion: consoleConfigMap.ResourceVersion,
+			ChangeChain:     consoleConfigMap.Annotations[api.ConfigMapChangeChainAnnotation],
+		}
+	}
+
+	ingressConfig, err := c.ingressConfigLister.Get(api.ConfigResourceName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "FailedGetIngressConfig", err
+	}
+	if apierrors.IsNotFound(err) {
+		ingressConfig = &configv1.Ingress{}
+	}
+	state.RouteSecrets, err = c.getRouteSecrets(ctx, operatorConfig, ingressConfig)
+	if err != nil {
+		return "FailedGetRouteSecrets", err
+	}
+
+	existing, err := c.configMapClient.ConfigMaps(api.OpenShiftConsoleOperatorNamespace).Get(ctx, api.InspectionConfigMapName, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "FailedGet", err
+	}
+	if apierrors.IsNotFound(err) {
+		existing = nil
+	}
+	state.SyncErrors = configmapsub.WithSyncErrors(configmapsub.GetInspectionSyncErrors(existing), operatorConfig.Status.Conditions)
+
+	required, err := configmapsub.DefaultInspectionConfigMap(state)
+	if err != nil {
+		return "FailedRender", err
+	}
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, recorder, required); err != nil {
+		return "FailedApply", err
+	}
+	return "", nil
+}
+
+// getRouteSecrets describes the custom TLS secrets of the console and downloads routes.
+func (c *InspectionController) getRouteSecrets(ctx context.Context, operatorConfig *operatorv1.Console, ingressConfig *configv1.Ingress) ([]configmapsub.InspectionSecret, error) {
+	secrets := []configmapsub.InspectionSecret{}
+	for _, routeSecret := range routesub.GetTLSSecrets(operatorConfig, ingressConfig) {
+		secret, err := c.secretClient.Secrets(api.OpenShiftConfigNamespace).Get(ctx, routeSecret.SecretName, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		if apierrors.IsNotFound(err) {
+			secret = nil
+		}
+		secrets = append(secrets, configmapsub.NewInspectionSecret(routeSecret.RouteName, api.OpenShiftConfigNamespace, routeSecret.SecretName, secret))
+	}
+	return secrets, nil
+}
@@ -0,0 +1,164 @@
+package maintenancewindow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	// kube
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	consoleclientv1 "github.com/openshift/client-go/console/clientset/versioned/typed/console/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+)
+
+// MaintenanceWindowController shows a banner ahead of and during every maintenance window
+// declared in the openshift-config/console-maintenance-windows ConfigMap, and removes it
+// once the window is over. Banners it owns carry the maintenance-window label.
+//
+//	writes:
+//	- consolenotifications.console.openshift.io maintenance-*
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=MaintenanceWindowsDegraded
+type MaintenanceWindowController struct {
+	operatorClient          v1helpers.OperatorClient
+	operatorConfigLister    operatorv1listers.ConsoleLister
+	configNSConfigMapLister corev1listers.ConfigMapLister
+
+	consoleNotificationClient consoleclientv1.ConsoleNotificationInterface
+}
+
+func NewMaintenanceWindowController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	consoleNotificationClient consoleclientv1.ConsoleNotificationInterface,
+	// informers
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	configNSConfigMapInformer corev1informers.ConfigMapInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &MaintenanceWindowController{
+		operatorClient:            operatorClient,
+		operatorConfigLister:      operatorConfigInformer.Lister(),
+		configNSConfigMapLister:   configNSConfigMapInformer.Lister(),
+		consoleNotificationClient: consoleNotificationClient,
+	}
+
+	// windows open and close with the clock, the resync is what moves the banners along
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+		).WithFilteredEventsInformers( // console-maintenance-windows
+		util.IncludeNamesFilter(api.MaintenanceWindowsConfigMapName),
+		configNSConfigMapInformer.Informer(),
+	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController("MaintenanceWindowController", recorder.WithComponentSuffix("maintenance-window-controller"))
+}
+
+func (c *MaintenanceWindowController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch consolecapability.ManagementState(operatorConfig) {
+	case operatorsv1.Managed:
+		klog.V(4).Infoln("console-operator is in a managed state: syncing maintenance window notifications")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infoln("console-operator is in an unmanaged state: skipping maintenance window notifications sync")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infoln("console-operator is in a removed state: deleting maintenance window notifications")
+		_, err := c.syncNotifications(ctx, nil)
+		return err
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	reason, err := c.syncMaintenanceWindows(ctx, time.Now())
+	statusHandler.AddCondition(status.HandleDegraded("MaintenanceWindows", reason, err))
+	return statusHandler.FlushAndReturn(err)
+}
+
+func (c *MaintenanceWindowController) syncMaintenanceWindows(ctx context.Context, now time.Time) (string, error) {
+	windows := []window{}
+	configMap, err := c.configNSConfigMapLister.ConfigMaps(api.OpenShiftConfigNamespace).Get(api.MaintenanceWindowsConfigMapName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "FailedGet", err
+	}
+	if err == nil {
+		windows, err = parseMaintenanceSchedule(configMap.Data[scheduleKey])
+		if err != nil {
+			// leave the banners as they are rather than acting on a half valid schedule
+			return "InvalidSchedule", fmt.Errorf("invalid %s/%s: %w", api.OpenShiftConfigNamespace, api.MaintenanceWindowsConfigMapName, err)
+		}
+	}
+
+	desired := []*consolev1.ConsoleNotification{}
+	for _, w := range windows {
+		if notification := w.notification(now); notification != nil {
+			desired = append(desired, notification)
+		}
+	}
+	return c.syncNotifications(ctx, desired)
+}
+
+// syncNotifications creates or updates the desired notifications and deletes every other
+// notification carrying the maintenance-window label.
+func (c *MaintenanceWindowController) syncNotifications(ctx context.Context, desired []*consolev1.ConsoleNotification) (string, error) {
+	existing, err := c.consoleNotificationClient.List(ctx, metav1.ListOptions{LabelSelector: api.MaintenanceWindowLabel})
+	if err != nil {
+		return "FailedList", err
+	}
+	existingByName := map[string]consolev1.ConsoleNotification{}
+	for _, notification := range existing.Items {
+		existingByName[notification.Name] = notification
+	}
+
+	for _, notification := range desired {
+		current, ok := existingByName[notification.Name]
+		delete(existingByName, notification.Name)
+		if !ok {
+			if _, err := c.consoleNotificationClient.Create(ctx, notification, metav1.CreateOptions{}); err != nil {
+				return "FailedCreate", err
+			}
+			continue
+		}
+		if equality.Semantic.DeepEqual(current.Spec, notification.Spec) {
+			continue
+		}
+		current.Spec = notification.Spec
+		if _, err := c.consoleNotificationClient.Update(ctx, &current, metav1.UpdateOptions{}); err != nil {
+			return "FailedUpdate", err
+		}
+	}
+
+	for name := range existingByName {
+		err := c.consoleNotificationClient.Delete(ctx, name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return "FailedDelete", err
+		}
+	}
+	return "", nil
+}
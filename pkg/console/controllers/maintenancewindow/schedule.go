@@ -0,0 +1,178 @@
+package maintenancewindow
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron"
+	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	consolev1 "github.com/openshift/api/console/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+const (
+	scheduleKey = "windows.yaml"
+
+	notificationPrefix = "maintenance-"
+	timeLayout         = "2006-01-02 15:04 MST"
+)
+
+// maintenanceSchedule is the content of the console-maintenance-windows ConfigMap, eg.
+//
+//	timeZone: Europe/Prague
+//	windows:
+//	- name: weekly-patching
+//	  schedule: "0 22 * * SAT"
+//	  duration: 4h
+//	  notice: 24h
+//	  text: Nodes are drained and rebooted one at a time.
+type maintenanceSchedule struct {
+	// TimeZone is the IANA time zone schedules are evaluated in, UTC if unset
+	TimeZone string              `yaml:"timeZone"`
+	Windows  []maintenanceWindow `yaml:"windows"`
+}
+
+type maintenanceWindow struct {
+	Name string `yaml:"name"`
+	// Schedule is a standard five field cron expression of when the window starts
+	Schedule string `yaml:"schedule"`
+	Duration string `yaml:"duration"`
+	// Notice is how long before the window starts the banner is shown
+	Notice string `yaml:"notice"`
+	// TimeZone overrides the time zone of the schedule for this window
+	TimeZone string `yaml:"timeZone"`
+	Text     string `yaml:"text"`
+}
+
+type window struct {
+	name     string
+	schedule cron.Schedule
+	duration time.Duration
+	notice   time.Duration
+	location *time.Location
+	text     string
+}
+
+// parseMaintenanceSchedule parses and validates every window of the schedule, reporting all
+// invalid windows at once so admins do not have to fix them one sync at a time.
+func parseMaintenanceSchedule(data string) ([]window, error) {
+	schedule := maintenanceSchedule{}
+	if err := yaml.UnmarshalStrict([]byte(data), &schedule); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", scheduleKey, err)
+	}
+	defaultLocation, err := loadLocation(schedule.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	windows := []window{}
+	names := map[string]bool{}
+	errs := []error{}
+	for _, w := range schedule.Windows {
+		parsed, err := parseWindow(w, defaultLocation)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("window %q: %w", w.Name, err))
+			continue
+		}
+		if names[w.Name] {
+			errs = append(errs, fmt.Errorf("window %q: duplicate name", w.Name))
+			continue
+		}
+		names[w.Name] = true
+		windows = append(windows, parsed)
+	}
+	return windows, errors.Join(errs...)
+}
+
+func parseWindow(w maintenanceWindow, defaultLocation *time.Location) (window, error) {
+	if msgs := validation.IsDNS1123Label(w.Name); len(msgs) > 0 {
+		return window{}, fmt.Errorf("invalid name: %s", strings.Join(msgs, ", "))
+	}
+	if len(strings.TrimSpace(w.Text)) == 0 {
+		return window{}, errors.New("text is required")
+	}
+	schedule, err := cron.ParseStandard(w.Schedule)
+	if err != nil {
+		return window{}, fmt.Errorf("invalid schedule %q: %w", w.Schedule, err)
+	}
+	duration, err := time.ParseDuration(w.Duration)
+	if err != nil || duration <= 0 {
+		return window{}, fmt.Errorf("invalid duration %q, expected a positive duration such as 4h", w.Duration)
+	}
+	var notice time.Duration
+	if len(w.Notice) > 0 {
+		notice, err = time.ParseDuration(w.Notice)
+		if err != nil || notice < 0 {
+			return window{}, fmt.Errorf("invalid notice %q, expected a duration such as 24h", w.Notice)
+		}
+	}
+	location := defaultLocation
+	if len(w.TimeZone) > 0 {
+		if location, err = loadLocation(w.TimeZone); err != nil {
+			return window{}, err
+		}
+	}
+	return window{
+		name:     w.Name,
+		schedule: schedule,
+		duration: duration,
+		notice:   notice,
+		location: location,
+		text:     strings.TrimSpace(w.Text),
+	}, nil
+}
+
+func loadLocation(timeZone string) (*time.Location, error) {
+	if len(timeZone) == 0 {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", timeZone, err)
+	}
+	return location, nil
+}
+
+// occurrence returns the start and end of the occurrence of the window whose banner is shown
+// at now, if any. The schedule is evaluated in the time zone of the window, so that windows
+// follow daylight saving time changes.
+func (w window) occurrence(now time.Time) (time.Time, time.Time, bool) {
+	// the earliest start that does not end before now
+	start := w.schedule.Next(now.Add(-w.duration).In(w.location))
+	if start.IsZero() || now.Before(start.Add(-w.notice)) {
+		return time.Time{}, time.Time{}, false
+	}
+	return start, start.Add(w.duration), true
+}
+
+// notification returns the banner of the window at now, or nil if none is shown.
+func (w window) notification(now time.Time) *consolev1.ConsoleNotification {
+	start, end, ok := w.occurrence(now)
+	if !ok {
+		return nil
+	}
+	spec := consolev1.ConsoleNotificationSpec{
+		Text:            fmt.Sprintf("Scheduled maintenance from %s to %s: %s", start.Format(timeLayout), end.Format(timeLayout), w.text),
+		Location:        consolev1.BannerTop,
+		Color:           "#FFFFFF",
+		BackgroundColor: "#0066CC",
+	}
+	if !now.Before(start) {
+		spec.Text = fmt.Sprintf("Maintenance in progress until %s: %s", end.Format(timeLayout), w.text)
+		spec.Color = "#000000"
+		spec.BackgroundColor = "#F0AB00"
+	}
+	return &consolev1.ConsoleNotification{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   notificationPrefix + w.name,
+			Labels: map[string]string{api.MaintenanceWindowLabel: w.name},
+		},
+		Spec: spec,
+	}
+}
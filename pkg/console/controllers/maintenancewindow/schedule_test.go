@@ -0,0 +1,199 @@
+package maintenancewindow
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+
+	consolev1 "github.com/openshift/api/console/v1"
+)
+
+const testSchedule = `
+timeZone: Europe/Prague
+windows:
+- name: weekly-patching
+  schedule: "0 22 * * SAT"
+  duration: 4h
+  notice: 24h
+  text: Nodes are drained and rebooted one at a time.
+- name: storage-upgrade
+  schedule: "30 2 1 * *"
+  duration: 1h
+  timeZone: America/New_York
+  text: Persistent volumes may be briefly unavailable.
+`
+
+func TestParseMaintenanceSchedule(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantNames []string
+		wantErr   []string
+	}{
+		{
+			name:      "Test valid schedule",
+			data:      testSchedule,
+			wantNames: []string{"weekly-patching", "storage-upgrade"},
+		},
+		{
+			name:      "Test empty schedule",
+			data:      "",
+			wantNames: []string{},
+		},
+		{
+			name: "Test invalid windows are all reported",
+			data: `
+windows:
+- name: Weekly
+  schedule: "0 22 * * SAT"
+  duration: 4h
+  text: patching
+- name: nightly
+  schedule: "every night"
+  duration: 4h
+  text: patching
+- name: backup
+  schedule: "0 1 * * *"
+  duration: 0s
+  text: backups
+- name: dst
+  schedule: "0 1 * * *"
+  duration: 1h
+  timeZone: Mars/Olympus_Mons
+  text: backups
+- name: ok
+  schedule: "0 1 * * *"
+  duration: 1h
+  text: backups
+`,
+			wantNames: []string{"ok"},
+			wantErr: []string{
+				`window "Weekly": invalid name`,
+				`window "nightly": invalid schedule "every night"`,
+				`window "backup": invalid duration "0s"`,
+				`window "dst": invalid time zone "Mars/Olympus_Mons"`,
+			},
+		},
+		{
+			name: "Test duplicate names",
+			data: `
+windows:
+- name: weekly
+  schedule: "0 22 * * SAT"
+  duration: 4h
+  text: patching
+- name: weekly
+  schedule: "0 22 * * SUN"
+  duration: 4h
+  text: patching
+`,
+			wantNames: []string{"weekly"},
+			wantErr:   []string{`window "weekly": duplicate name`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			windows, err := parseMaintenanceSchedule(tt.data)
+			names := []string{}
+			for _, w := range windows {
+				names = append(names, w.name)
+			}
+			if diff := deep.Equal(names, tt.wantNames); diff != nil {
+				t.Error(diff)
+			}
+			if len(tt.wantErr) == 0 && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, want := range tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), want) {
+					t.Errorf("expected error containing %q, got %v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestWindowNotification(t *testing.T) {
+	windows, err := parseMaintenanceSchedule(testSchedule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weekly, storage := windows[0], windows[1]
+
+	tests := []struct {
+		name   string
+		window window
+		now    time.Time
+		want   *consolev1.ConsoleNotification
+	}{
+		{
+			name:   "Test before the notice period",
+			window: weekly,
+			// Friday 19:00 UTC, Friday 21:00 in Prague
+			now:  time.Date(2024, 6, 14, 19, 0, 0, 0, time.UTC),
+			want: nil,
+		},
+		{
+			name:   "Test within the notice period",
+			window: weekly,
+			now:    time.Date(2024, 6, 14, 21, 0, 0, 0, time.UTC),
+			want: &consolev1.ConsoleNotification{
+				Spec: consolev1.ConsoleNotificationSpec{
+					Text:            "Scheduled maintenance from 2024-06-15 22:00 CEST to 2024-06-16 02:00 CEST: Nodes are drained and rebooted one at a time.",
+					Location:        consolev1.BannerTop,
+					Color:           "#FFFFFF",
+					BackgroundColor: "#0066CC",
+				},
+			},
+		},
+		{
+			name:   "Test during the window",
+			window: weekly,
+			now:    time.Date(2024, 6, 15, 23, 0, 0, 0, time.UTC),
+			want: &consolev1.ConsoleNotification{
+				Spec: consolev1.ConsoleNotificationSpec{
+					Text:            "Maintenance in progress until 2024-06-16 02:00 CEST: Nodes are drained and rebooted one at a time.",
+					Location:        consolev1.BannerTop,
+					Color:           "#000000",
+					BackgroundColor: "#F0AB00",
+				},
+			},
+		},
+		{
+			name:   "Test after the window",
+			window: weekly,
+			now:    time.Date(2024, 6, 16, 0, 0, 0, 0, time.UTC),
+			want:   nil,
+		},
+		{
+			name:   "Test window in its own time zone",
+			window: storage,
+			// 02:45 in New York
+			now: time.Date(2024, 7, 1, 6, 45, 0, 0, time.UTC),
+			want: &consolev1.ConsoleNotification{
+				Spec: consolev1.ConsoleNotificationSpec{
+					Text:            "Maintenance in progress until 2024-07-01 03:30 EDT: Persistent volumes may be briefly unavailable.",
+					Location:        consolev1.BannerTop,
+					Color:           "#000000",
+					BackgroundColor: "#F0AB00",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.window.notification(tt.now)
+			if got != nil && tt.want != nil {
+				if got.Name != notificationPrefix+tt.window.name {
+					t.Errorf("unexpected notification name %s", got.Name)
+				}
+				got.ObjectMeta = tt.want.ObjectMeta
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
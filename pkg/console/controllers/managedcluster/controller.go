@@ -0,0 +1,261 @@
+package managedcluster
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	// kube
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+)
+
+// managedClusterGVR is served by the hub components, clusters that are not a hub have no
+// ManagedClusters and are polled instead of watched.
+var managedClusterGVR = schema.GroupVersionResource{
+	Group:    "cluster.open-cluster-management.io",
+	Version:  "v1",
+	Resource: "managedclusters",
+}
+
+const (
+	// localClusterLabel is set on the ManagedCluster of the hub itself, the console already
+	// runs against it
+	localClusterLabel = "local-cluster"
+	// managedClusterConditionAvailable is set by the registration agent of the cluster
+	managedClusterConditionAvailable = "ManagedClusterConditionAvailable"
+)
+
+// ManagedClusterController detects ACM and MCE hubs and aggregates the API servers of their
+// ManagedClusters into the managed-clusters ConfigMap in openshift-console, rendered into
+// console-config by the operator to enable the cluster switcher. Clusters the console cannot
+// reach are left out and listed in the status.yaml key of the ConfigMap along with the reason.
+// The API server CAs of the clusters are aggregated into the managed-cluster-ca-bundle ConfigMap,
+// trusted by the console next to the cluster trust bundle, and follow clusters joining, leaving
+// and rotating their CA on the next resync.
+//
+//	writes:
+//	- configmaps openshift-console/managed-clusters
+//	- configmaps openshift-console/managed-cluster-ca-bundle
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=ManagedClusterConfigMapDegraded
+type ManagedClusterController struct {
+	operatorClient       v1helpers.OperatorClient
+	operatorConfigLister operatorv1listers.ConsoleLister
+	configMapClient      coreclientv1.ConfigMapsGetter
+	dynamicClient        dynamic.Interface
+}
+
+func NewManagedClusterController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	dynamicClient dynamic.Interface,
+	// informers
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	targetNSConfigMapInformer corev1informers.ConfigMapInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &ManagedClusterController{
+		operatorClient:       operatorClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		configMapClient:      configMapClient,
+		dynamicClient:        dynamicClient,
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+		).WithFilteredEventsInformers( // managed-clusters and its CA bundle, to revert out of band changes
+		util.IncludeNamesFilter(api.ManagedClustersConfigMapName, api.ManagedClusterCABundleConfigMapName),
+		targetNSConfigMapInformer.Informer(),
+	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController("ManagedClusterController", recorder.WithComponentSuffix("managed-cluster-controller"))
+}
+
+func (c *ManagedClusterController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch consolecapability.ManagementState(operatorConfig) {
+	case operatorsv1.Managed:
+		klog.V(4).Infoln("console-operator is in a managed state: syncing managed clusters")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infoln("console-operator is in an unmanaged state: skipping managed clusters sync")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infoln("console-operator is in a removed state: deleting managed clusters")
+		return c.removeManagedClustersConfigMap(ctx)
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	reason, err := c.syncManagedClusters(ctx, controllerContext.Recorder())
+	statusHandler.AddCondition(status.HandleDegraded("ManagedClusterConfigMap", reason, err))
+	return statusHandler.FlushAndReturn(err)
+}
+
+func (c *ManagedClusterController) syncManagedClusters(ctx context.Context, recorder events.Recorder) (string, error) {
+	hub, err := c.detectHub(ctx)
+	if err != nil {
+		return "FailedDetectHub", err
+	}
+	managedClusters, err := c.dynamicClient.Resource(managedClusterGVR).List(ctx, metav1.ListOptions{})
+	if len(hub) == 0 || apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		// not a hub, or not anymore: the cluster switcher goes away with the ConfigMap
+		if err := c.removeManagedClustersConfigMap(ctx); err != nil {
+			return "FailedDelete", err
+		}
+		return "", nil
+	}
+	if err != nil {
+		return "FailedListManagedClusters", err
+	}
+
+	clusters, statuses := aggregateManagedClusters(managedClusters.Items)
+	required, err := configmapsub.DefaultManagedClustersConfigMap(configmapsub.MulticlusterConfig{Hub: hub, Clusters: clusters}, statuses)
+	if err != nil {
+		return "FailedRender", err
+	}
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, recorder, required); err != nil {
+		return "FailedApply", err
+	}
+	if err := c.syncCABundle(ctx, recorder, clusters); err != nil {
+		return "FailedApplyCABundle", err
+	}
+
+	invalid := []string{}
+	for _, clusterStatus := range statuses {
+		if !clusterStatus.Valid {
+			invalid = append(invalid, fmt.Sprintf("%s: %s", clusterStatus.Name, clusterStatus.Message))
+		}
+	}
+	if len(invalid) > 0 {
+		return "InvalidManagedClusters", fmt.Errorf("managed clusters left out of the console: %s", strings.Join(invalid, ", "))
+	}
+	return "", nil
+}
+
+// syncCABundle applies the CA bundle of clusters, or deletes it once no cluster has its own CA
+// anymore and the cluster trust bundle is enough.
+func (c *ManagedClusterController) syncCABundle(ctx context.Context, recorder events.Recorder, clusters []configmapsub.ManagedCluster) error {
+	required := configmapsub.DefaultManagedClusterCABundleConfigMap(clusters)
+	if required == nil {
+		return c.removeConfigMap(ctx, api.ManagedClusterCABundleConfigMapName)
+	}
+	_, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, recorder, required)
+	return err
+}
+
+func (c *ManagedClusterController) removeManagedClustersConfigMap(ctx context.Context) error {
+	if err := c.removeConfigMap(ctx, api.ManagedClusterCABundleConfigMapName); err != nil {
+		return err
+	}
+	return c.removeConfigMap(ctx, api.ManagedClustersConfigMapName)
+}
+
+func (c *ManagedClusterController) removeConfigMap(ctx context.Context, name string) error {
+	err := c.configMapClient.ConfigMaps(api.OpenShiftConsoleNamespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// aggregateManagedClusters returns the clusters the console can reach, and the status of every
+// cluster but the hub itself, both sorted by name.
+func aggregateManagedClusters(managedClusters []unstructured.Unstructured) ([]configmapsub.ManagedCluster, []configmapsub.ManagedClusterStatus) {
+	sort.Slice(managedClusters, func(i, j int) bool { return managedClusters[i].GetName() < managedClusters[j].GetName() })
+	clusters := []configmapsub.ManagedCluster{}
+	statuses := []configmapsub.ManagedClusterStatus{}
+	for _, managedCluster := range managedClusters {
+		if managedCluster.GetLabels()[localClusterLabel] == "true" {
+			continue
+		}
+		clusterStatus := configmapsub.ManagedClusterStatus{
+			Name:      managedCluster.GetName(),
+			Available: isAvailable(managedCluster),
+		}
+		cluster, err := managedClusterConfig(managedCluster)
+		if err != nil {
+			clusterStatus.Message = err.Error()
+			statuses = append(statuses, clusterStatus)
+			continue
+		}
+		clusterStatus.Valid = true
+		if !clusterStatus.Available {
+			clusterStatus.Message = "the cluster is not available"
+		}
+		statuses = append(statuses, clusterStatus)
+		clusters = append(clusters, cluster)
+	}
+	return clusters, statuses
+}
+
+func managedClusterConfig(managedCluster unstructured.Unstructured) (configmapsub.ManagedCluster, error) {
+	accepted, _, _ := unstructured.NestedBool(managedCluster.Object, "spec", "hubAcceptsClient")
+	if !accepted {
+		return configmapsub.ManagedCluster{}, fmt.Errorf("the cluster is not accepted by the hub")
+	}
+	clientConfigs, _, _ := unstructured.NestedSlice(managedCluster.Object, "spec", "managedClusterClientConfigs")
+	if len(clientConfigs) == 0 {
+		return configmapsub.ManagedCluster{}, fmt.Errorf("no API server URL is reported")
+	}
+	// the registration agent reports the URL the cluster is reached on first
+	clientConfig, _ := clientConfigs[0].(map[string]interface{})
+	apiServerURL, _, _ := unstructured.NestedString(clientConfig, "url")
+	encodedCABundle, _, _ := unstructured.NestedString(clientConfig, "caBundle")
+	caBundle, err := base64.StdEncoding.DecodeString(encodedCABundle)
+	if err != nil {
+		return configmapsub.ManagedCluster{}, fmt.Errorf("invalid caBundle: %v", err)
+	}
+
+	displayName := managedCluster.GetAnnotations()[api.ManagedClusterDisplayNameAnnotation]
+	cluster := configmapsub.NewManagedCluster(managedCluster.GetName(), displayName, apiServerURL, string(caBundle))
+	if err := configmapsub.ValidateManagedCluster(cluster); err != nil {
+		return configmapsub.ManagedCluster{}, err
+	}
+	return cluster, nil
+}
+
+func isAvailable(managedCluster unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(managedCluster.Object, "status", "conditions")
+	for _, condition := range conditions {
+		condition, _ := condition.(map[string]interface{})
+		if condition["type"] == managedClusterConditionAvailable {
+			return condition["status"] == string(metav1.ConditionTrue)
+		}
+	}
+	return false
+}
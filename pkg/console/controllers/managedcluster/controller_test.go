@@ -0,0 +1,92 @@
+package managedcluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+)
+
+func TestAggregateManagedClusters(t *testing.T) {
+	managedCluster := func(name string, labels, annotations map[string]interface{}, spec map[string]interface{}, available string) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name, "labels": labels, "annotations": annotations},
+			"spec":     spec,
+			"status": map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"type": "ManagedClusterJoined", "status": "True"},
+				map[string]interface{}{"type": managedClusterConditionAvailable, "status": available},
+			}},
+		}}
+	}
+	accepted := func(url, caBundle string) map[string]interface{} {
+		return map[string]interface{}{
+			"hubAcceptsClient": true,
+			"managedClusterClientConfigs": []interface{}{
+				map[string]interface{}{"url": url, "caBundle": caBundle},
+			},
+		}
+	}
+	managedClusters := []unstructured.Unstructured{
+		managedCluster("west", nil, nil, accepted("https://api.west.example.com:6443", ""), "Unknown"),
+		managedCluster("local-cluster", map[string]interface{}{"local-cluster": "true"}, nil, accepted("https://api.hub.example.com:6443", ""), "True"),
+		managedCluster("east", nil, map[string]interface{}{"console.openshift.io/display-name": "East (production)"}, accepted("https://api.east.example.com:6443", ""), "True"),
+		managedCluster("pending", nil, nil, map[string]interface{}{"hubAcceptsClient": false}, "Unknown"),
+		managedCluster("joining", nil, nil, map[string]interface{}{"hubAcceptsClient": true}, "Unknown"),
+		managedCluster("north", nil, nil, accepted("http://api.north.example.com:6443", ""), "True"),
+		managedCluster("south", nil, nil, accepted("https://api.south.example.com:6443", "bm90IGEgY2VydGlmaWNhdGU="), "True"),
+	}
+
+	clusters, statuses := aggregateManagedClusters(managedClusters)
+	wantClusters := []configmapsub.ManagedCluster{
+		configmapsub.NewManagedCluster("east", "East (production)", "https://api.east.example.com:6443", ""),
+		configmapsub.NewManagedCluster("west", "", "https://api.west.example.com:6443", ""),
+	}
+	if diff := deep.Equal(clusters, wantClusters); diff != nil {
+		t.Error(diff)
+	}
+	wantStatuses := []configmapsub.ManagedClusterStatus{
+		{Name: "east", Valid: true, Available: true},
+		{Name: "joining", Message: "no API server URL is reported"},
+		{Name: "north", Available: true, Message: `API server URL "http://api.north.example.com:6443" must be an https:// URL`},
+		{Name: "pending", Message: "the cluster is not accepted by the hub"},
+		{Name: "south", Available: true, Message: `caKey "managed-cluster-ca-south.crt" holds no PEM certificates`},
+		{Name: "west", Valid: true, Message: "the cluster is not available"},
+	}
+	if diff := deep.Equal(statuses, wantStatuses); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestIsReady(t *testing.T) {
+	hub := func(phase string, deleting bool) unstructured.Unstructured {
+		item := unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "multiclusterhub"},
+			"status":   map[string]interface{}{"phase": phase},
+		}}
+		if deleting {
+			item.SetDeletionTimestamp(&metav1.Time{Time: time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)})
+		}
+		return item
+	}
+	tests := []struct {
+		name  string
+		items []unstructured.Unstructured
+		want  bool
+	}{
+		{name: "Test no hub", want: false},
+		{name: "Test hub installing", items: []unstructured.Unstructured{hub("Installing", false)}, want: false},
+		{name: "Test hub running", items: []unstructured.Unstructured{hub("Running", false)}, want: true},
+		{name: "Test hub uninstalling", items: []unstructured.Unstructured{hub("Running", true)}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(isReady(tt.items, "Running"), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package managedcluster
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+)
+
+// hubComponent is an operator that turns a cluster into a hub, ready once one of its resources
+// reaches readyPhase.
+type hubComponent struct {
+	hub        string
+	gvr        schema.GroupVersionResource
+	readyPhase string
+}
+
+// hubComponents are detected in order, Advanced Cluster Management installs the multicluster
+// engine along with its own MultiClusterHub.
+var hubComponents = []hubComponent{
+	{
+		hub:        configmapsub.MulticlusterHubACM,
+		gvr:        schema.GroupVersionResource{Group: "operator.open-cluster-management.io", Version: "v1", Resource: "multiclusterhubs"},
+		readyPhase: "Running",
+	},
+	{
+		hub:        configmapsub.MulticlusterHubMCE,
+		gvr:        schema.GroupVersionResource{Group: "multicluster.openshift.io", Version: "v1", Resource: "multiclusterengines"},
+		readyPhase: "Available",
+	},
+}
+
+// detectHub returns the hub component the cluster runs, or an empty string once the hub
+// components are removed, along with their CRDs or not.
+func (c *ManagedClusterController) detectHub(ctx context.Context) (string, error) {
+	for _, component := range hubComponents {
+		list, err := c.dynamicClient.Resource(component.gvr).List(ctx, metav1.ListOptions{})
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if isReady(list.Items, component.readyPhase) {
+			return component.hub, nil
+		}
+	}
+	return "", nil
+}
+
+// isReady is false while the hub is installed or uninstalled, so that the cluster switcher does
+// not list clusters the hub cannot serve yet, or anymore.
+func isReady(items []unstructured.Unstructured, readyPhase string) bool {
+	for _, item := range items {
+		if item.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if phase, _, _ := unstructured.NestedString(item.Object, "status", "phase"); phase == readyPhase {
+			return true
+		}
+	}
+	return false
+}
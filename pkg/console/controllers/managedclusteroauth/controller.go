@@ -0,0 +1,298 @@
+package managedclusteroauth
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	// kube
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/dynamic"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	configinformer "github.com/openshift/client-go/config/informers/externalversions"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+	"github.com/openshift/console-operator/pkg/crypto"
+)
+
+// ManagedClusterOAuthController registers an OAuth client for the hub console on every managed
+// cluster listed in the managed-clusters ConfigMap, through a ManifestWork in the namespace of
+// the cluster. The client secrets are generated once per cluster and kept in the
+// managed-cluster-oauth-clients secret mounted by the console, the ManifestWork and the secret
+// key of a cluster are removed along with the cluster.
+//
+//	writes:
+//	- manifestworks.work.open-cluster-management.io <cluster>/console-oauth-client
+//	- secrets openshift-console/managed-cluster-oauth-clients
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=ManagedClusterOAuthClientSyncDegraded
+//		- type=ManagedClusterOAuthClientSyncProgressing
+type ManagedClusterOAuthController struct {
+	operatorClient          v1helpers.OperatorClient
+	operatorConfigLister    operatorv1listers.ConsoleLister
+	consoleConfigLister     configlistersv1.ConsoleLister
+	targetNSConfigMapLister corev1listers.ConfigMapLister
+	targetNSSecretLister    corev1listers.SecretLister
+	secretsClient           coreclientv1.SecretsGetter
+	dynamicClient           dynamic.Interface
+}
+
+func NewManagedClusterOAuthController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	secretsClient coreclientv1.SecretsGetter,
+	dynamicClient dynamic.Interface,
+	// informers
+	configInformer configinformer.SharedInformerFactory,
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	targetNSConfigMapInformer corev1informers.ConfigMapInformer,
+	targetNSSecretInformer corev1informers.SecretInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	configV1Informers := configInformer.Config().V1()
+
+	ctrl := &ManagedClusterOAuthController{
+		operatorClient:          operatorClient,
+		operatorConfigLister:    operatorConfigInformer.Lister(),
+		consoleConfigLister:     configV1Informers.Consoles().Lister(),
+		targetNSConfigMapLister: targetNSConfigMapInformer.Lister(),
+		targetNSSecretLister:    targetNSSecretInformer.Lister(),
+		secretsClient:           secretsClient,
+		dynamicClient:           dynamicClient,
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+			configV1Informers.Consoles().Informer(),
+		).WithFilteredEventsInformers( // managed-clusters
+		util.IncludeNamesFilter(api.ManagedClustersConfigMapName),
+		targetNSConfigMapInformer.Informer(),
+	).WithFilteredEventsInformers( // managed-cluster-oauth-clients, to revert out of band changes
+		util.IncludeNamesFilter(api.ManagedClusterOAuthSecretName),
+		targetNSSecretInformer.Informer(),
+	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController("ManagedClusterOAuthController", recorder.WithComponentSuffix("managed-cluster-oauth-controller"))
+}
+
+func (c *ManagedClusterOAuthController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch consolecapability.ManagementState(operatorConfig) {
+	case operatorsv1.Managed:
+		klog.V(4).Infoln("console-operator is in a managed state: syncing managed cluster oauth clients")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infoln("console-operator is in an unmanaged state: skipping managed cluster oauth clients sync")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infoln("console-operator is in a removed state: deleting managed cluster oauth clients")
+		return c.removeOAuthClients(ctx, sets.NewString())
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	statuses, reason, err := c.syncOAuthClients(ctx, controllerContext.Recorder())
+	if err == nil {
+		err = statusError(statuses, clientFailed)
+		reason = "FailedProvisionOAuthClients"
+	}
+	statusHandler.AddCondition(status.HandleDegraded("ManagedClusterOAuthClientSync", reason, err))
+	statusHandler.AddCondition(status.HandleProgressing("ManagedClusterOAuthClientSync", "WaitingForOAuthClients", statusError(statuses, clientPending)))
+	return statusHandler.FlushAndReturn(err)
+}
+
+func (c *ManagedClusterOAuthController) syncOAuthClients(ctx context.Context, recorder events.Recorder) ([]clientStatus, string, error) {
+	managedClustersConfigMap, err := c.targetNSConfigMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(api.ManagedClustersConfigMapName)
+	if apierrors.IsNotFound(err) {
+		if err := c.removeOAuthClients(ctx, sets.NewString()); err != nil {
+			return nil, "FailedDelete", err
+		}
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "FailedGetManagedClusters", err
+	}
+	// invalid clusters are left out, they are reported by the console-operator
+	clusters := sets.NewString()
+	if multicluster, _ := configmapsub.ValidateManagedClustersConfig(managedClustersConfigMap.Data); multicluster != nil {
+		for _, cluster := range multicluster.Clusters {
+			clusters.Insert(cluster.Name)
+		}
+	}
+
+	consoleConfig, err := c.consoleConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return nil, "FailedGetConsoleConfig", err
+	}
+	consoleURL := consoleConfig.Status.ConsoleURL
+	if len(consoleURL) == 0 {
+		// the redirect URIs are not known until the console route is admitted
+		statuses := []clientStatus{}
+		for _, cluster := range clusters.List() {
+			statuses = append(statuses, clientStatus{cluster: cluster, state: clientPending, message: "waiting for the console URL"})
+		}
+		return statuses, "", nil
+	}
+
+	existingSecret, err := c.targetNSSecretLister.Secrets(api.OpenShiftConsoleNamespace).Get(api.ManagedClusterOAuthSecretName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, "FailedGetSecret", err
+	}
+	existingData := map[string][]byte{}
+	if existingSecret != nil && err == nil {
+		existingData = existingSecret.Data
+	}
+	clientSecretData := clientSecrets(existingData, clusters.List())
+	if _, _, err := resourceapply.ApplySecret(ctx, c.secretsClient, recorder, oauthClientsSecret(clientSecretData)); err != nil {
+		return nil, "FailedApplySecret", err
+	}
+
+	statuses := []clientStatus{}
+	for _, cluster := range clusters.List() {
+		manifestWork, err := c.applyManifestWork(ctx, newManifestWork(cluster, consoleURL, string(clientSecretData[cluster])))
+		if err != nil {
+			statuses = append(statuses, clientStatus{cluster: cluster, state: clientFailed, message: fmt.Sprintf("failed to apply the ManifestWork: %v", err)})
+			continue
+		}
+		statuses = append(statuses, manifestWorkStatus(cluster, manifestWork))
+	}
+
+	if err := c.removeManifestWorks(ctx, clusters); err != nil {
+		return statuses, "FailedDelete", err
+	}
+	return statuses, "", nil
+}
+
+func (c *ManagedClusterOAuthController) applyManifestWork(ctx context.Context, required *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	client := c.dynamicClient.Resource(manifestWorkGVR).Namespace(required.GetNamespace())
+	existing, err := client.Get(ctx, required.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return client.Create(ctx, required, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	requiredWorkload, _, _ := unstructured.NestedMap(required.Object, "spec", "workload")
+	existingWorkload, _, _ := unstructured.NestedMap(existing.Object, "spec", "workload")
+	if equality.Semantic.DeepEqual(existingWorkload, requiredWorkload) && existing.GetLabels()[oauthClientLabel] == "true" {
+		return existing, nil
+	}
+	updated := existing.DeepCopy()
+	if err := unstructured.SetNestedMap(updated.Object, requiredWorkload, "spec", "workload"); err != nil {
+		return nil, err
+	}
+	labels := updated.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[oauthClientLabel] = "true"
+	updated.SetLabels(labels)
+	return client.Update(ctx, updated, metav1.UpdateOptions{})
+}
+
+// removeManifestWorks deletes the ManifestWorks of the clusters that are not listed anymore, the
+// work agents delete the OAuthClients from the clusters.
+func (c *ManagedClusterOAuthController) removeManifestWorks(ctx context.Context, clusters sets.String) error {
+	manifestWorks, err := c.dynamicClient.Resource(manifestWorkGVR).List(ctx, metav1.ListOptions{LabelSelector: oauthClientLabel + "=true"})
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, manifestWork := range manifestWorks.Items {
+		if clusters.Has(manifestWork.GetNamespace()) {
+			continue
+		}
+		err := c.dynamicClient.Resource(manifestWorkGVR).Namespace(manifestWork.GetNamespace()).Delete(ctx, manifestWork.GetName(), metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *ManagedClusterOAuthController) removeOAuthClients(ctx context.Context, clusters sets.String) error {
+	if err := c.removeManifestWorks(ctx, clusters); err != nil {
+		return err
+	}
+	err := c.secretsClient.Secrets(api.OpenShiftConsoleNamespace).Delete(ctx, api.ManagedClusterOAuthSecretName, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// clientSecrets keeps the secret of every listed cluster that has one and generates the missing
+// ones, the secrets of clusters that are not listed anymore are dropped.
+func clientSecrets(existing map[string][]byte, clusters []string) map[string][]byte {
+	secrets := map[string][]byte{}
+	for _, cluster := range clusters {
+		if secret := existing[cluster]; len(secret) > 0 {
+			secrets[cluster] = secret
+			continue
+		}
+		secrets[cluster] = []byte(crypto.Random256BitsString())
+	}
+	return secrets
+}
+
+func oauthClientsSecret(data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      api.ManagedClusterOAuthSecretName,
+			Namespace: api.OpenShiftConsoleNamespace,
+			Labels:    map[string]string{"app": api.OpenShiftConsoleName},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+}
+
+// statusError lists the clusters in state along with why, or returns nil.
+func statusError(statuses []clientStatus, state clientState) error {
+	messages := []string{}
+	for _, clientStatus := range statuses {
+		if clientStatus.state == state {
+			messages = append(messages, fmt.Sprintf("%s: %s", clientStatus.cluster, clientStatus.message))
+		}
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	sort.Strings(messages)
+	return fmt.Errorf("managed cluster OAuth clients: %s", strings.Join(messages, ", "))
+}
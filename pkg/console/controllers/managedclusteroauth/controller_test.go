@@ -0,0 +1,47 @@
+package managedclusteroauth
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestClientSecrets(t *testing.T) {
+	existing := map[string][]byte{
+		"east":    []byte("east-secret"),
+		"west":    {},
+		"removed": []byte("removed-secret"),
+	}
+	got := clientSecrets(existing, []string{"east", "west", "north"})
+
+	if diff := deep.Equal(string(got["east"]), "east-secret"); diff != nil {
+		t.Error(diff)
+	}
+	for _, cluster := range []string{"west", "north"} {
+		// 256 bits, base64 encoded
+		if len(got[cluster]) != 43 {
+			t.Errorf("expected a generated secret for %s, got %q", cluster, got[cluster])
+		}
+	}
+	if diff := deep.Equal(string(got["west"]) == string(got["north"]), false); diff != nil {
+		t.Error(diff)
+	}
+	if _, ok := got["removed"]; ok {
+		t.Error("expected the secret of a removed cluster to be dropped")
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	statuses := []clientStatus{
+		{cluster: "west", state: clientFailed, message: "failed to apply the ManifestWork: forbidden"},
+		{cluster: "east", state: clientProvisioned},
+		{cluster: "north", state: clientPending, message: "waiting for the console URL"},
+		{cluster: "central", state: clientFailed, message: "the OAuth client is not applied: timeout"},
+	}
+	if diff := deep.Equal(statusError(statuses, clientFailed).Error(), "managed cluster OAuth clients: central: the OAuth client is not applied: timeout, west: failed to apply the ManifestWork: forbidden"); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(statusError(statuses[1:2], clientPending), nil); diff != nil {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,106 @@
+package managedclusteroauth
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+// manifestWorkGVR is served by the hub, a ManifestWork in the namespace of a managed cluster is
+// applied to that cluster by its work agent.
+var manifestWorkGVR = schema.GroupVersionResource{
+	Group:    "work.open-cluster-management.io",
+	Version:  "v1",
+	Resource: "manifestworks",
+}
+
+const (
+	manifestWorkName = "console-oauth-client"
+	// oauthClientLabel selects the ManifestWorks owned by the operator across cluster namespaces
+	oauthClientLabel = "console.operator.openshift.io/managed-cluster-oauth-client"
+
+	manifestWorkApplied   = "Applied"
+	manifestWorkAvailable = "Available"
+)
+
+// clientState is how far the provisioning of the console client of a cluster went.
+type clientState int
+
+const (
+	clientProvisioned clientState = iota
+	clientPending
+	clientFailed
+)
+
+// clientStatus is the per-cluster outcome of a sync, reported in the operator conditions.
+type clientStatus struct {
+	cluster string
+	state   clientState
+	message string
+}
+
+// oauthClientRedirectURI is where the managed cluster OAuth server sends users back to once
+// they logged in, the hub console tells the clusters apart by the last path segment.
+func oauthClientRedirectURI(consoleURL, cluster string) string {
+	return fmt.Sprintf("%s/auth/callback/%s", strings.TrimSuffix(consoleURL, "/"), cluster)
+}
+
+// newManifestWork renders the ManifestWork registering the console client on cluster. Deleting
+// the ManifestWork deletes the OAuthClient from the cluster.
+func newManifestWork(cluster, consoleURL, clientSecret string) *unstructured.Unstructured {
+	oauthClient := map[string]interface{}{
+		"apiVersion": "oauth.openshift.io/v1",
+		"kind":       "OAuthClient",
+		"metadata": map[string]interface{}{
+			"name": api.ManagedClusterOAuthClientName,
+		},
+		"grantMethod":  "auto",
+		"redirectURIs": []interface{}{oauthClientRedirectURI(consoleURL, cluster)},
+		"secret":       clientSecret,
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": manifestWorkGVR.GroupVersion().String(),
+		"kind":       "ManifestWork",
+		"metadata": map[string]interface{}{
+			"name":      manifestWorkName,
+			"namespace": cluster,
+			"labels": map[string]interface{}{
+				oauthClientLabel: "true",
+			},
+		},
+		"spec": map[string]interface{}{
+			"workload": map[string]interface{}{
+				"manifests": []interface{}{oauthClient},
+			},
+		},
+	}}
+}
+
+// manifestWorkStatus reads the conditions the work agent of the cluster reports.
+func manifestWorkStatus(cluster string, manifestWork *unstructured.Unstructured) clientStatus {
+	conditions, _, _ := unstructured.NestedSlice(manifestWork.Object, "status", "conditions")
+	found := map[string]map[string]interface{}{}
+	for _, condition := range conditions {
+		if condition, ok := condition.(map[string]interface{}); ok {
+			if conditionType, ok := condition["type"].(string); ok {
+				found[conditionType] = condition
+			}
+		}
+	}
+
+	applied, available := found[manifestWorkApplied], found[manifestWorkAvailable]
+	switch {
+	case applied != nil && applied["status"] == string(metav1.ConditionFalse):
+		return clientStatus{cluster: cluster, state: clientFailed, message: fmt.Sprintf("the OAuth client is not applied: %v", applied["message"])}
+	case applied == nil || applied["status"] != string(metav1.ConditionTrue):
+		return clientStatus{cluster: cluster, state: clientPending, message: "waiting for the work agent to apply the OAuth client"}
+	case available == nil || available["status"] != string(metav1.ConditionTrue):
+		return clientStatus{cluster: cluster, state: clientPending, message: "waiting for the OAuth client to become available"}
+	}
+	return clientStatus{cluster: cluster, state: clientProvisioned}
+}
@@ -0,0 +1,77 @@
+package managedclusteroauth
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestManifestWorkStatus(t *testing.T) {
+	withConditions := func(conditions ...map[string]interface{}) *unstructured.Unstructured {
+		manifestWork := newManifestWork("east", "https://console.example.com/", "secret")
+		items := []interface{}{}
+		for _, condition := range conditions {
+			items = append(items, condition)
+		}
+		manifestWork.Object["status"] = map[string]interface{}{"conditions": items}
+		return manifestWork
+	}
+	condition := func(conditionType, status, message string) map[string]interface{} {
+		return map[string]interface{}{"type": conditionType, "status": status, "message": message}
+	}
+	tests := []struct {
+		name         string
+		manifestWork *unstructured.Unstructured
+		want         clientStatus
+	}{
+		{
+			name:         "Test new ManifestWork",
+			manifestWork: newManifestWork("east", "https://console.example.com/", "secret"),
+			want:         clientStatus{cluster: "east", state: clientPending, message: "waiting for the work agent to apply the OAuth client"},
+		},
+		{
+			name:         "Test applied ManifestWork",
+			manifestWork: withConditions(condition("Applied", "True", ""), condition("Available", "Unknown", "")),
+			want:         clientStatus{cluster: "east", state: clientPending, message: "waiting for the OAuth client to become available"},
+		},
+		{
+			name:         "Test available OAuth client",
+			manifestWork: withConditions(condition("Applied", "True", ""), condition("Available", "True", "")),
+			want:         clientStatus{cluster: "east", state: clientProvisioned},
+		},
+		{
+			name:         "Test failed ManifestWork",
+			manifestWork: withConditions(condition("Applied", "False", "the server could not find the requested resource")),
+			want:         clientStatus{cluster: "east", state: clientFailed, message: "the OAuth client is not applied: the server could not find the requested resource"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(manifestWorkStatus("east", tt.manifestWork), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestNewManifestWork(t *testing.T) {
+	manifestWork := newManifestWork("east", "https://console.example.com/", "secret")
+	if diff := deep.Equal(manifestWork.GetNamespace(), "east"); diff != nil {
+		t.Error(diff)
+	}
+	manifests, _, _ := unstructured.NestedSlice(manifestWork.Object, "spec", "workload", "manifests")
+	want := []interface{}{
+		map[string]interface{}{
+			"apiVersion":   "oauth.openshift.io/v1",
+			"kind":         "OAuthClient",
+			"metadata":     map[string]interface{}{"name": "multicluster-console"},
+			"grantMethod":  "auto",
+			"redirectURIs": []interface{}{"https://console.example.com/auth/callback/east"},
+			"secret":       "secret",
+		},
+	}
+	if diff := deep.Equal(manifests, want); diff != nil {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,257 @@
+package nodeupdates
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	// kube
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	consoleclientv1 "github.com/openshift/client-go/console/clientset/versioned/typed/console/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+)
+
+// annotations the machine-config-daemon keeps up to date on every node it manages
+const (
+	mcoCurrentConfigAnnotation    = "machineconfiguration.openshift.io/currentConfig"
+	mcoDesiredConfigAnnotation    = "machineconfiguration.openshift.io/desiredConfig"
+	mcoStateAnnotation            = "machineconfiguration.openshift.io/state"
+	mcoDesiredDrainAnnotation     = "machineconfiguration.openshift.io/desiredDrain"
+	mcoLastAppliedDrainAnnotation = "machineconfiguration.openshift.io/lastAppliedDrain"
+
+	mcoStateWorking = "Working"
+	mcoDrainPrefix  = "drain-"
+
+	// maxListedNodes keeps the banner readable on large clusters
+	maxListedNodes = 3
+)
+
+// NodeUpdateNotificationController summarizes the node drains and reboots of a disruptive
+// update in an informational banner, so that users know why the cluster is slow. The banner is
+// opt-in through the node-update-notifications annotation of the operator config and goes away
+// on its own once every node runs its desired config.
+//
+//	writes:
+//	- consolenotifications.console.openshift.io node-updates
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=NodeUpdateNotificationSyncDegraded
+type NodeUpdateNotificationController struct {
+	operatorClient       v1helpers.OperatorClient
+	operatorConfigLister operatorv1listers.ConsoleLister
+	nodeLister           corev1listers.NodeLister
+
+	consoleNotificationClient consoleclientv1.ConsoleNotificationInterface
+}
+
+func NewNodeUpdateNotificationController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	consoleNotificationClient consoleclientv1.ConsoleNotificationInterface,
+	// informers
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	nodeInformer corev1informers.NodeInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &NodeUpdateNotificationController{
+		operatorClient:            operatorClient,
+		operatorConfigLister:      operatorConfigInformer.Lister(),
+		nodeLister:                nodeInformer.Lister(),
+		consoleNotificationClient: consoleNotificationClient,
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+		).WithInformers( // nodes
+		nodeInformer.Informer(),
+	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController("NodeUpdateNotificationController", recorder.WithComponentSuffix("node-update-notification-controller"))
+}
+
+func (c *NodeUpdateNotificationController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch consolecapability.ManagementState(operatorConfig) {
+	case operatorsv1.Managed:
+		klog.V(4).Info("console-operator is in a managed state: syncing node update notification")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Info("console-operator is in an unmanaged state: skipping node update notification sync")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Info("console-operator is in a removed state: deleting node update notification")
+		return c.removeNodeUpdateNotification(ctx)
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+
+	reason, err := c.syncNodeUpdateNotification(ctx, isEnabled(operatorConfig))
+	if err != nil {
+		klog.V(4).Infof("error syncing %s consolenotification custom resource: %s", api.NodeUpdateConsoleNotification, err)
+	}
+	statusHandler.AddCondition(status.HandleDegraded("NodeUpdateNotificationSync", reason, err))
+	return statusHandler.FlushAndReturn(err)
+}
+
+func (c *NodeUpdateNotificationController) syncNodeUpdateNotification(ctx context.Context, enabled bool) (string, error) {
+	text := ""
+	if enabled {
+		nodes, err := c.nodeLister.List(labels.Everything())
+		if err != nil {
+			return "FailedListNodes", err
+		}
+		text = nodeUpdateNotificationText(summarizeNodeUpdates(nodes))
+	}
+	if len(text) == 0 {
+		if err := c.removeNodeUpdateNotification(ctx); err != nil {
+			return "FailedDelete", err
+		}
+		return "", nil
+	}
+
+	notification := &consolev1.ConsoleNotification{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: api.NodeUpdateConsoleNotification,
+		},
+		Spec: consolev1.ConsoleNotificationSpec{
+			Text:            text,
+			Location:        "BannerTop",
+			Color:           "#FFFFFF",
+			BackgroundColor: "#0066CC",
+		},
+	}
+	_, err := c.consoleNotificationClient.Create(ctx, notification, metav1.CreateOptions{})
+	if err == nil {
+		return "", nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return "FailedCreate", err
+	}
+	existing, err := c.consoleNotificationClient.Get(ctx, api.NodeUpdateConsoleNotification, metav1.GetOptions{})
+	if err != nil {
+		return "FailedGet", err
+	}
+	if equality.Semantic.DeepEqual(existing.Spec, notification.Spec) {
+		return "", nil
+	}
+	existing.Spec = notification.Spec
+	if _, err := c.consoleNotificationClient.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return "FailedUpdate", err
+	}
+	return "", nil
+}
+
+func (c *NodeUpdateNotificationController) removeNodeUpdateNotification(ctx context.Context) error {
+	err := c.consoleNotificationClient.Delete(ctx, api.NodeUpdateConsoleNotification, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func isEnabled(operatorConfig *operatorsv1.Console) bool {
+	enabled, err := strconv.ParseBool(operatorConfig.Annotations[api.NodeUpdateNotificationsAnnotation])
+	return err == nil && enabled
+}
+
+// nodeUpdates groups the nodes that do not run their desired config yet by what they are
+// doing, as reported by the machine-config-daemon.
+type nodeUpdates struct {
+	draining  []string
+	rebooting []string
+	updating  []string
+	waiting   []string
+}
+
+func summarizeNodeUpdates(nodes []*corev1.Node) nodeUpdates {
+	updates := nodeUpdates{}
+	for _, node := range nodes {
+		annotations := node.Annotations
+		desiredDrain := annotations[mcoDesiredDrainAnnotation]
+		switch {
+		case strings.HasPrefix(desiredDrain, mcoDrainPrefix) && desiredDrain != annotations[mcoLastAppliedDrainAnnotation]:
+			updates.draining = append(updates.draining, node.Name)
+		case annotations[mcoCurrentConfigAnnotation] == annotations[mcoDesiredConfigAnnotation]:
+			// up to date, or not managed by the machine-config-operator
+			continue
+		case !isReady(node):
+			updates.rebooting = append(updates.rebooting, node.Name)
+		case annotations[mcoStateAnnotation] == mcoStateWorking:
+			updates.updating = append(updates.updating, node.Name)
+		default:
+			updates.waiting = append(updates.waiting, node.Name)
+		}
+	}
+	for _, names := range [][]string{updates.draining, updates.rebooting, updates.updating, updates.waiting} {
+		sort.Strings(names)
+	}
+	return updates
+}
+
+func isReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// nodeUpdateNotificationText returns an empty string once no node is draining, rebooting or
+// waiting for its update.
+func nodeUpdateNotificationText(updates nodeUpdates) string {
+	parts := []string{}
+	if len(updates.draining) > 0 {
+		parts = append(parts, fmt.Sprintf("%d draining (%s)", len(updates.draining), listNodes(updates.draining)))
+	}
+	if len(updates.rebooting) > 0 {
+		parts = append(parts, fmt.Sprintf("%d rebooting (%s)", len(updates.rebooting), listNodes(updates.rebooting)))
+	}
+	if len(updates.updating) > 0 {
+		parts = append(parts, fmt.Sprintf("%d updating", len(updates.updating)))
+	}
+	if len(updates.waiting) > 0 {
+		parts = append(parts, fmt.Sprintf("%d waiting", len(updates.waiting)))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Nodes are being updated: %s. Workloads are moved off updating nodes, the cluster may be slower until the update completes.", strings.Join(parts, ", "))
+}
+
+func listNodes(names []string) string {
+	if len(names) <= maxListedNodes {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:maxListedNodes], ", "), len(names)-maxListedNodes)
+}
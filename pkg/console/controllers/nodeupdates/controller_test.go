@@ -0,0 +1,79 @@
+package nodeupdates
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testNode(name, current, desired, state string, ready bool, drain ...string) *corev1.Node {
+	readyStatus := corev1.ConditionTrue
+	if !ready {
+		readyStatus = corev1.ConditionUnknown
+	}
+	annotations := map[string]string{
+		mcoCurrentConfigAnnotation: current,
+		mcoDesiredConfigAnnotation: desired,
+		mcoStateAnnotation:         state,
+	}
+	if len(drain) == 2 {
+		annotations[mcoDesiredDrainAnnotation] = drain[0]
+		annotations[mcoLastAppliedDrainAnnotation] = drain[1]
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: readyStatus}},
+		},
+	}
+}
+
+func TestNodeUpdateNotificationText(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes []*corev1.Node
+		want  string
+	}{
+		{
+			name: "Test all nodes up to date",
+			nodes: []*corev1.Node{
+				testNode("master-0", "rendered-master-1", "rendered-master-1", "Done", true, "uncordon-rendered-master-1", "uncordon-rendered-master-1"),
+				testNode("worker-0", "rendered-worker-1", "rendered-worker-1", "Done", true),
+				{ObjectMeta: metav1.ObjectMeta{Name: "unmanaged"}},
+			},
+			want: "",
+		},
+		{
+			name: "Test update in progress",
+			nodes: []*corev1.Node{
+				testNode("worker-0", "rendered-worker-1", "rendered-worker-2", "Working", true, "drain-rendered-worker-2", "uncordon-rendered-worker-1"),
+				testNode("worker-1", "rendered-worker-1", "rendered-worker-2", "Working", false, "drain-rendered-worker-2", "drain-rendered-worker-2"),
+				testNode("worker-2", "rendered-worker-1", "rendered-worker-2", "Working", true, "drain-rendered-worker-2", "drain-rendered-worker-2"),
+				testNode("worker-3", "rendered-worker-1", "rendered-worker-2", "Done", true),
+				testNode("worker-4", "rendered-worker-1", "rendered-worker-2", "Done", true),
+				testNode("worker-5", "rendered-worker-2", "rendered-worker-2", "Done", true),
+			},
+			want: "Nodes are being updated: 1 draining (worker-0), 1 rebooting (worker-1), 1 updating, 2 waiting. Workloads are moved off updating nodes, the cluster may be slower until the update completes.",
+		},
+		{
+			name: "Test many nodes rebooting",
+			nodes: []*corev1.Node{
+				testNode("worker-e", "rendered-worker-1", "rendered-worker-2", "Working", false),
+				testNode("worker-d", "rendered-worker-1", "rendered-worker-2", "Working", false),
+				testNode("worker-c", "rendered-worker-1", "rendered-worker-2", "Working", false),
+				testNode("worker-b", "rendered-worker-1", "rendered-worker-2", "Working", false),
+				testNode("worker-a", "rendered-worker-1", "rendered-worker-2", "Working", false),
+			},
+			want: "Nodes are being updated: 5 rebooting (worker-a, worker-b, worker-c and 2 more). Workloads are moved off updating nodes, the cluster may be slower until the update completes.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(nodeUpdateNotificationText(summarizeNodeUpdates(tt.nodes)), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
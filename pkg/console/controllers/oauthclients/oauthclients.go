@@ -3,17 +3,23 @@ package oauthclients
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
+	appsv1informers "k8s.io/client-go/informers/apps/v1"
 	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1clients "k8s.io/client-go/kubernetes/typed/core/v1"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
 	configv1 "github.com/openshift/api/config/v1"
+	oauthv1 "github.com/openshift/api/oauth/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
 	configv1lister "github.com/openshift/client-go/config/listers/config/v1"
@@ -26,65 +32,104 @@ import (
 	routev1listers "github.com/openshift/client-go/route/listers/route/v1"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 
 	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
 	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	customerrors "github.com/openshift/console-operator/pkg/console/errors"
+	"github.com/openshift/console-operator/pkg/console/metrics"
 	"github.com/openshift/console-operator/pkg/console/status"
+	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
 	oauthsub "github.com/openshift/console-operator/pkg/console/subresource/oauthclient"
 	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
 	secretsub "github.com/openshift/console-operator/pkg/console/subresource/secret"
+	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
 )
 
-// oauthClientsController:
+const rollingOutMessage = "waiting for the console pods to be rolled out with the rotated client secret"
+
+// oauthClientsController registers the console with its OAuthClient. The client secret is
+// rotated on the interval of the rotation annotation of the operator config, or when the rotate
+// annotation is set to a new value. Whenever the client secret changes, the previous one is kept
+// in console-oauth-config and the OAuthClient keeps accepting it until every console pod was
+// rolled out with the new one, so that no login fails during the rollout. The redirect URIs of the redirect URIs annotation are accepted on top of
+// the callback of the console route. Out of band changes to the OAuthClient are reverted and
+// reported with an event and the OAuthClient drift metric; a deleted OAuthClient is restored by
+// the CVO, the operator is not allowed to create it. The access token annotations override the
+// token policy of the cluster for the console tokens, the session policy is progressing until the
+// console pods are rolled out with it. The grant annotations set whether the users consent to the
+// console grants, eg. for compliance, and whether the console client answers with challenges.
 //
 //	updates:
 //	- oauthclient.oauth.openshift.io/console (created by CVO)
 //	writes:
+//	- secrets.console-oauth-config -n openshift-console .Data['clientSecret'], when rotated
 //	- consoles.operator.openshift.io/cluster .status.conditions:
 //		- type=OAuthClientSyncProgressing
 //		- type=OAuthClientSyncDegraded
+//		- type=OAuthClientSecretRotationProgressing, with the last rotation time once settled
+//		- type=OAuthClientSecretRotationDegraded
+//		- type=OAuthRedirectURIsDegraded
+//		- type=OAuthClientTokenPolicyDegraded
+//		- type=OAuthClientGrantPolicyDegraded
+//		- type=SessionPolicyProgressing
 type oauthClientsController struct {
 	oauthClient    oauthv1client.OAuthClientsGetter
 	operatorClient v1helpers.OperatorClient
+	secretsClient  corev1clients.SecretsGetter
 
 	oauthClientLister           oauthv1lister.OAuthClientLister
 	oauthClientSwitchedInformer *util.InformerWithSwitch
 	authnLister                 configv1lister.AuthenticationLister
+	oauthConfigLister           configv1lister.OAuthLister
 	consoleOperatorLister       operatorv1listers.ConsoleLister
 	routesLister                routev1listers.RouteLister
 	ingressConfigLister         configv1lister.IngressLister
 	targetNSSecretsLister       corev1listers.SecretLister
+	targetNSDeploymentsLister   appsv1listers.DeploymentLister
+
+	// oauthClientDeleted is set once the deletion of the OAuthClient was reported, until the
+	// console is registered to the restored one
+	oauthClientDeleted bool
 }
 
 func NewOAuthClientsController(
 	operatorClient v1helpers.OperatorClient,
 	oauthClient oauthclient.Interface,
+	secretsClient corev1clients.SecretsGetter,
 	authnInformer configv1informers.AuthenticationInformer,
+	oauthConfigInformer configv1informers.OAuthInformer,
 	consoleOperatorInformer operatorv1informers.ConsoleInformer,
 	routeInformer routev1informers.RouteInformer,
 	ingressConfigInformer configv1informers.IngressInformer,
 	targetNSsecretsInformer corev1informers.SecretInformer,
+	targetNSDeploymentsInformer appsv1informers.DeploymentInformer,
 	oauthClientSwitchedInformer *util.InformerWithSwitch,
 	recorder events.Recorder,
 ) factory.Controller {
 	c := oauthClientsController{
 		oauthClient:    oauthClient.OauthV1(),
 		operatorClient: operatorClient,
+		secretsClient:  secretsClient,
 
 		oauthClientLister:           oauthClientSwitchedInformer.Lister(),
 		oauthClientSwitchedInformer: oauthClientSwitchedInformer,
 		authnLister:                 authnInformer.Lister(),
+		oauthConfigLister:           oauthConfigInformer.Lister(),
 		consoleOperatorLister:       consoleOperatorInformer.Lister(),
 		routesLister:                routeInformer.Lister(),
 		ingressConfigLister:         ingressConfigInformer.Lister(),
 		targetNSSecretsLister:       targetNSsecretsInformer.Lister(),
+		targetNSDeploymentsLister:   targetNSDeploymentsInformer.Lister(),
 	}
 
 	return factory.New().
 		WithSync(c.sync).
 		WithInformers(
 			authnInformer.Informer(),
+			oauthConfigInformer.Informer(),
 			consoleOperatorInformer.Informer(),
 			routeInformer.Informer(),
 			ingressConfigInformer.Informer(),
@@ -94,6 +139,10 @@ func NewOAuthClientsController(
 			factory.NamesFilter(api.OAuthClientName),
 			oauthClientSwitchedInformer.Informer(),
 		).
+		WithFilteredEventsInformers(
+			factory.NamesFilter(api.OpenShiftConsoleDeploymentName),
+			targetNSDeploymentsInformer.Informer(),
+		).
 		WithSyncDegradedOnError(operatorClient).
 		ResyncEvery(wait.Jitter(time.Minute, 1.0)).
 		ToController("OAuthClientsController", recorder.WithComponentSuffix("oauth-clients-controller"))
@@ -118,6 +167,11 @@ func (c *oauthClientsController) sync(ctx context.Context, controllerContext fac
 	default:
 		// if we're not using integrated oauth, reset all degraded conditions
 		statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSync", "", nil))
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretRotation", "", nil))
+		statusHandler.AddCondition(status.HandleDegraded("OAuthRedirectURIs", "", nil))
+		statusHandler.AddCondition(status.HandleDegraded("OAuthClientTokenPolicy", "", nil))
+		statusHandler.AddCondition(status.HandleDegraded("OAuthClientGrantPolicy", "", nil))
+		statusHandler.AddCondition(status.HandleProgressing("SessionPolicy", "", nil))
 		return statusHandler.FlushAndReturn(nil)
 	}
 
@@ -153,25 +207,122 @@ func (c *oauthClientsController) sync(ctx context.Context, controllerContext fac
 		return err
 	}
 
-	oauthErrReason, err := c.syncOAuthClient(ctx, clientSecret, consoleURL.String())
+	rotated, rotationErrReason, err := c.syncClientSecretRotation(ctx, operatorConfig, clientSecret, controllerContext.Recorder())
+	if err != nil {
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretRotation", rotationErrReason, err))
+		return statusHandler.FlushAndReturn(err)
+	}
+	if rotated {
+		// the OAuthClient lister does not see the rotated client secret yet, the OAuthClient is
+		// synced again once console-oauth-config is updated
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretRotation", "RollingOut", customerrors.NewSyncError(rollingOutMessage)))
+		return statusHandler.FlushAndReturn(nil)
+	}
+
+	deployment, err := c.targetNSDeploymentsLister.Deployments(api.OpenShiftConsoleNamespace).Get(api.OpenShiftConsoleDeploymentName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return statusHandler.FlushAndReturn(err)
+	}
+	rolledOut := isRolledOutWithClientSecret(deployment, clientSecret)
+
+	// the valid redirect URIs are registered even if some are not
+	redirectURIs, redirectURIsErr := utilsub.GetOAuthRedirectURIs(operatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("OAuthRedirectURIs", "InvalidRedirectURIs", redirectURIsErr))
+
+	tokenPolicy, tokenPolicyErr := utilsub.GetOAuthClientTokenPolicy(operatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("OAuthClientTokenPolicy", "InvalidTokenPolicy", tokenPolicyErr))
+
+	grantPolicy, grantPolicyErr := utilsub.GetOAuthClientGrantPolicy(operatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("OAuthClientGrantPolicy", "InvalidGrantPolicy", grantPolicyErr))
+
+	registeredClient, oauthErrReason, err := c.syncOAuthClient(ctx, clientSecret, consoleURL.String(), redirectURIs, tokenPolicy, grantPolicy, rolledOut, controllerContext.Recorder())
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSync", oauthErrReason, err))
 	if err != nil {
 		return statusHandler.FlushAndReturn(err)
 	}
 
+	// console-config picks up the token policy of the OAuthClient and of the cluster on the next
+	// sync of the operator, the console pods once they are rolled out with it
+	oauthConfig, err := c.oauthConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return statusHandler.FlushAndReturn(err)
+	}
+	sessionPolicy := utilsub.GetSessionPolicy(authnConfig, oauthConfig, registeredClient)
+	statusHandler.AddCondition(status.HandleProgressing("SessionPolicy", "RollingOut", sessionPolicyRolloutErr(deployment, sessionPolicy)))
+
+	rotatedAt, everRotated := secretsub.OAuthClientSecretRotatedAt(clientSecret)
+	switch {
+	case everRotated && !rolledOut:
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretRotation", "RollingOut", customerrors.NewSyncError(rollingOutMessage)))
+	case everRotated:
+		statusHandler.AddCondition(status.HandleDegraded("OAuthClientSecretRotation", "", nil))
+		statusHandler.AddCondition(status.HandleCompleted("OAuthClientSecretRotation", "Rotated", fmt.Sprintf("the client secret was last rotated at %s", rotatedAt.Format(time.RFC3339))))
+	default:
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretRotation", "", nil))
+	}
+
 	return statusHandler.FlushAndReturn(nil)
 }
 
+// syncClientSecretRotation rotates the client secret in console-oauth-config when its rotation is
+// due and returns whether it did. The OAuthClient is registered with the new client secret first,
+// the console pods still running with the previous one keep logging users in until they are
+// rolled out.
+func (c *oauthClientsController) syncClientSecretRotation(ctx context.Context, operatorConfig *operatorv1.Console, clientSecret *corev1.Secret, recorder events.Recorder) (bool, string, error) {
+	now := time.Now()
+	if !secretsub.IsOAuthClientSecretRotationDue(operatorConfig, clientSecret, now) {
+		return false, "", nil
+	}
+
+	oauthClient, err := c.oauthClientLister.Get(oauthsub.Stub().Name)
+	if err != nil {
+		return false, "FailedGet", err
+	}
+	required := secretsub.WithPreviousClientSecret(secretsub.RotatedOAuthClientSecret(operatorConfig, now), clientSecret)
+	clientCopy := oauthClient.DeepCopy()
+	oauthsub.SetSecretString(clientCopy, secretsub.GetSecretString(required))
+	clientCopy.AdditionalSecrets = []string{secretsub.GetPreviousSecretString(required)}
+	if _, _, err := oauthsub.CustomApplyOAuth(c.oauthClient, clientCopy, ctx); err != nil {
+		return false, "FailedRegister", err
+	}
+
+	if _, _, err := resourceapply.ApplySecret(ctx, c.secretsClient, recorder, required); err != nil {
+		return false, "FailedApply", err
+	}
+	recorder.Eventf("OAuthClientSecretRotated", "Rotated the client secret of the %s OAuthClient", api.OAuthClientName)
+	return true, "", nil
+}
+
+// isRolledOutWithClientSecret is true once every console pod runs with the client secret in
+// console-oauth-config.
+func isRolledOutWithClientSecret(deployment *appsv1.Deployment, clientSecret *corev1.Secret) bool {
+	return deployment != nil &&
+		deploymentsub.SecretVersion(clientSecret) == deployment.ObjectMeta.Annotations["console.openshift.io/oauth-secret-version"] &&
+		deploymentsub.IsAvailableAndUpdated(deployment)
+}
+
+// sessionPolicyRolloutErr returns an error until every console pod runs with the session policy.
+func sessionPolicyRolloutErr(deployment *appsv1.Deployment, sessionPolicy utilsub.SessionPolicy) error {
+	// the console is not deployed yet, it is deployed with the current policy
+	if deployment == nil {
+		return nil
+	}
+	if deployment.ObjectMeta.Annotations[api.SessionPolicyAnnotation] != sessionPolicy.String() || !deploymentsub.IsAvailableAndUpdated(deployment) {
+		return fmt.Errorf("waiting for the console pods to be rolled out with the session policy %s", sessionPolicy)
+	}
+	return nil
+}
+
 // handleStatus returns whether sync should happen and any error encountering
 // determining the operator's management state
 // TODO: extract this logic to where it can be used for all controllers
 func (c *oauthClientsController) handleManaged(ctx context.Context) (bool, error) {
-	operatorSpec, _, _, err := c.operatorClient.GetOperatorState()
+	operatorSpec, operatorStatus, _, err := c.operatorClient.GetOperatorState()
 	if err != nil {
 		return false, fmt.Errorf("failed to retrieve operator config: %w", err)
 	}
 
-	switch managementState := operatorSpec.ManagementState; managementState {
+	switch managementState := consolecapability.OperatorManagementState(operatorSpec, operatorStatus); managementState {
 	case operatorv1.Managed:
 		klog.V(4).Infoln("console is in a managed state.")
 		return true, nil
@@ -179,8 +330,8 @@ func (c *oauthClientsController) handleManaged(ctx context.Context) (bool, error
 		klog.V(4).Infoln("console is in an unmanaged state.")
 		return false, nil
 	case operatorv1.Removed:
-		klog.V(4).Infoln("console has been removed.")
-		return false, c.deregisterClient(ctx)
+		klog.V(4).Infoln("console has been removed: the teardown controller deregisters the console from the oauth client")
+		return false, nil
 	default:
 		return false, fmt.Errorf("console is in an unknown state: %v", managementState)
 	}
@@ -192,34 +343,43 @@ func (c *oauthClientsController) syncOAuthClient(
 	ctx context.Context,
 	sec *corev1.Secret,
 	consoleURL string,
-) (reason string, err error) {
+	redirectURIs []string,
+	tokenPolicy utilsub.OAuthClientTokenPolicy,
+	grantPolicy utilsub.OAuthClientGrantPolicy,
+	rolledOut bool,
+	recorder events.Recorder,
+) (registered *oauthv1.OAuthClient, reason string, err error) {
 	oauthClient, err := c.oauthClientLister.Get(oauthsub.Stub().Name)
+	if apierrors.IsNotFound(err) && !c.oauthClientDeleted {
+		c.oauthClientDeleted = true
+		recorder.Warningf("OAuthClientDeleted", "the %s OAuthClient was deleted, the console is registered again once the CVO restores it", api.OAuthClientName)
+		metrics.HandleOAuthClientDrift([]string{"deleted"})
+	}
 	if err != nil {
 		// at this point we must die & wait for someone to fix the lack of an outhclient. there is nothing we can do.
-		return "FailedGet", fmt.Errorf("oauth client for console does not exist and cannot be created (%w)", err)
+		return nil, "FailedGet", fmt.Errorf("oauth client for console does not exist and cannot be created (%w)", err)
 	}
 	clientCopy := oauthClient.DeepCopy()
 	oauthsub.RegisterConsoleToOAuthClient(clientCopy, consoleURL, secretsub.GetSecretString(sec))
+	oauthsub.AddRedirectURIs(clientCopy, redirectURIs)
+	oauthsub.SetTokenPolicy(clientCopy, tokenPolicy)
+	oauthsub.SetGrantPolicy(clientCopy, grantPolicy)
+	// the previous client secret is only accepted until the console is rolled out with the current one
+	clientCopy.AdditionalSecrets = nil
+	if previous := secretsub.GetPreviousSecretString(sec); len(previous) > 0 && !rolledOut {
+		clientCopy.AdditionalSecrets = []string{previous}
+	}
+	if fields, diff := oauthsub.DataDrift(clientCopy, oauthClient); len(fields) > 0 {
+		recorder.Warningf("OAuthClientDriftReverted", "reverting out of band changes to the %s OAuthClient: %s\n%s", api.OAuthClientName, strings.Join(fields, ", "), diff)
+		metrics.HandleOAuthClientDrift(fields)
+	}
 	_, _, oauthErr := oauthsub.CustomApplyOAuth(c.oauthClient, clientCopy, ctx)
 	if oauthErr != nil {
-		return "FailedRegister", oauthErr
+		return nil, "FailedRegister", oauthErr
 	}
-	return "", nil
-}
-
-func (c *oauthClientsController) deregisterClient(ctx context.Context) error {
-	// existingOAuthClient is not a delete, it is a deregister/neutralize
-	existingOAuthClient, err := c.oauthClientLister.Get(oauthsub.Stub().Name)
-	if err != nil {
-		return err
-	}
-
-	if len(existingOAuthClient.RedirectURIs) == 0 {
-		return nil
+	if c.oauthClientDeleted {
+		c.oauthClientDeleted = false
+		recorder.Eventf("OAuthClientRestored", "registered the console to the restored %s OAuthClient", api.OAuthClientName)
 	}
-
-	updated := oauthsub.DeRegisterConsoleFromOAuthClient(existingOAuthClient.DeepCopy())
-	_, err = c.oauthClient.OAuthClients().Update(ctx, updated, metav1.UpdateOptions{})
-	return err
-
+	return clientCopy, "", nil
 }
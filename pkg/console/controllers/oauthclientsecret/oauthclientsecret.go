@@ -3,8 +3,11 @@ package oauthclientsecret
 import (
 	"context"
 	"fmt"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	corev1informers "k8s.io/client-go/informers/core/v1"
 	corev1clients "k8s.io/client-go/kubernetes/typed/core/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
@@ -17,6 +20,8 @@ import (
 	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
 	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
 	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
 	"github.com/openshift/console-operator/pkg/console/status"
 	"github.com/openshift/console-operator/pkg/crypto"
 	"github.com/openshift/library-go/pkg/controller/factory"
@@ -30,20 +35,28 @@ import (
 
 // oauthClientSecretController behaves differently based on authentication/cluster .spec.type:
 //
-//   - IntegratedOAuth - self-manage the client secret string
+//   - IntegratedOAuth - self-manage the client secret string, unless the operator config names
+//     an openshift-config secret to source it from, eg. synced by an external secret manager,
+//     then its 'clientSecret' is copied and reported stale once it outlives its max age
 //   - OIDC - lookup our client in the authentication/cluster .spec.oidcProviders[x].oidcClients
 //     slice and use the 'clientSecret' from the secret referred to by .clientSecret.name
-//   - None - do nothing
+//     unless the operator config names a SecretProviderClass to mount it from, then
+//     the secret is never copied and any existing copy is removed
+//   - None - do nothing, the console runs without a login and mounts no client secret
 //
-// The secret written is 'openshift-console/console-oauth-config' in .Data['clientSecret']
+// The secret written is 'openshift-console/console-oauth-config' in .Data['clientSecret'],
+// an OIDC client secret is annotated with the openshift-config secret and version it was
+// copied from, the console deployment tracks it.
 //
 // ==========
 //
 //	writes:
 //	- secrets.console-oauth-config -n openshift-console .Data['clientSecret']
+//	- secrets.console-oauth-config -n openshift-console .metadata.annotations['console.openshift.io/oidc-client-secret-source']
 //	- consoles.operator.openshift.io/cluster .status.conditions:
 //		- type=OAuthClientSecretSyncProgressing
 //		- type=OAuthClientSecretSyncDegraded
+//		- type=OAuthClientSecretSourceDegraded
 type oauthClientSecretController struct {
 	operatorClient v1helpers.OperatorClient
 	secretsClient  corev1clients.SecretsGetter
@@ -78,11 +91,18 @@ func NewOAuthClientSecretController(
 		WithInformers(
 			authnInformer.Informer(),
 			consoleOperatorInformer.Informer(),
-			configSecretsInformer.Informer(),
+		).
+		WithFilteredEventsInformers(
+			util.OIDCClientSecretFilter(authnInformer.Lister()), configSecretsInformer.Informer(),
+		).
+		WithFilteredEventsInformers(
+			oauthClientSecretRefFilter(consoleOperatorInformer.Lister()), configSecretsInformer.Informer(),
 		).
 		WithFilteredEventsInformers(
 			factory.NamesFilter("console-oauth-config"), targetNSsecretsInformer.Informer(),
 		).
+		// a scheduled client secret rotation is picked up on resync
+		ResyncEvery(time.Minute).
 		ToController("OAuthClientSecretController", recorder.WithComponentSuffix("oauthclient-secret-controller"))
 }
 
@@ -105,9 +125,35 @@ func (c *oauthClientSecretController) sync(ctx context.Context, syncCtx factory.
 		return fmt.Errorf("failed to retrieve authentication config: %w", err)
 	}
 
-	var secretString string
+	// only a client secret sourced from an openshift-config secret goes stale
+	statusHandler.AddCondition(status.HandleDegraded("OAuthClientSecretSource", "", nil))
+
+	var (
+		secretString string
+		// the openshift-config secret the OIDC client secret is copied from
+		configSecret *corev1.Secret
+	)
 	switch authConfig.Spec.Type {
 	case "", configv1.AuthenticationTypeIntegratedOAuth:
+		operatorConfig, err := c.consoleOperatorLister.Get(api.ConfigResourceName)
+		if err != nil {
+			return err
+		}
+		if secretRef := secretsub.GetOAuthClientSecretRef(operatorConfig); len(secretRef) > 0 {
+			configSecret, err = c.configSecretsLister.Secrets(api.OpenShiftConfigNamespace).Get(secretRef)
+			if err != nil {
+				statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretSync", "FailedClientSecretGet", err))
+				return statusHandler.FlushAndReturn(err)
+			}
+			statusHandler.AddCondition(status.HandleDegraded("OAuthClientSecretSource", "StaleClientSecret", secretsub.OAuthClientSecretStalenessErr(operatorConfig, configSecret, time.Now())))
+			secretString = secretsub.GetSecretString(configSecret)
+			// keep the console running with the last client secret until a valid one is synced
+			if err := secretsub.ValidateOIDCClientSecret(secretString); err != nil {
+				statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretSync", "ClientSecretMalformed", fmt.Errorf("client secret secret %q: %w", secretRef, err)))
+				return statusHandler.FlushAndReturn(nil)
+			}
+			break
+		}
 		// in OpenShift controlled world, we generate the client secret ourselves
 		if clientSecret != nil {
 			secretString = secretsub.GetSecretString(clientSecret)
@@ -123,42 +169,112 @@ func (c *oauthClientSecretController) sync(ctx context.Context, syncCtx factory.
 			return statusHandler.FlushAndReturn(nil)
 		}
 
+		operatorConfig, err := c.consoleOperatorLister.Get(api.ConfigResourceName)
+		if err != nil {
+			return err
+		}
+
+		// a public client logs in with PKCE, there is no client secret to sync
+		if utilsub.IsOIDCPublicClient(operatorConfig, authConfig) {
+			err = c.removeSecret(ctx, clientSecret)
+			statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretSync", "FailedDelete", err))
+			return statusHandler.FlushAndReturn(err)
+		}
+
 		if len(clientConfig.ClientSecret.Name) == 0 {
 			statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretSync", "MissingClientSecretConfig", fmt.Errorf("missing client secret name reference in config")))
 			return statusHandler.FlushAndReturn(nil)
 		}
+		// the console mounts the secret straight from the external secret store,
+		// make sure no copy of it is left behind in etcd
+		if len(utilsub.GetOIDCSecretProviderClass(operatorConfig, authConfig)) > 0 {
+			err = c.removeSecret(ctx, clientSecret)
+			statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretSync", "FailedDelete", err))
+			return statusHandler.FlushAndReturn(err)
+		}
 
-		conficClientSecret, err := c.configSecretsLister.Secrets(api.OpenShiftConfigNamespace).Get(clientConfig.ClientSecret.Name)
+		configSecret, err = c.configSecretsLister.Secrets(api.OpenShiftConfigNamespace).Get(clientConfig.ClientSecret.Name)
 		if err != nil {
 			statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretSync", "FailedClientSecretGet", err))
 			return statusHandler.FlushAndReturn(err)
 		}
 
-		secretString = secretsub.GetSecretString(conficClientSecret)
+		// a staged next client secret replaces the current one once its rotation is due
+		secretString = secretsub.GetOIDCClientSecret(operatorConfig, configSecret, time.Now())
 		if len(secretString) == 0 {
 			statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretSync", "ClientSecretKeyMissing", fmt.Errorf("missing the 'clientSecret' key in the client secret secret %q", clientConfig.ClientSecret.Name)))
 			return statusHandler.FlushAndReturn(nil)
 		}
+		// keep the console running with the last client secret rather than crashlooping on this one
+		if err := secretsub.ValidateOIDCClientSecret(secretString); err != nil {
+			statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretSync", "OIDCClientSecretMalformed", fmt.Errorf("client secret secret %q: %w", clientConfig.ClientSecret.Name, err)))
+			return statusHandler.FlushAndReturn(nil)
+		}
+	case configv1.AuthenticationTypeNone:
+		// the console runs without a login, there is no client secret to sync
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretSync", "", nil))
+		return statusHandler.FlushAndReturn(nil)
 	default:
 		klog.V(2).Infof("unknown authentication type: %s", authConfig.Spec.Type)
 		statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretSync", "", nil))
 		return statusHandler.FlushAndReturn(nil)
 	}
 
-	err = c.syncSecret(ctx, secretString, syncCtx.Recorder())
+	err = c.syncSecret(ctx, secretString, configSecret, syncCtx.Recorder())
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretSync", "FailedApply", err))
 	return statusHandler.FlushAndReturn(err)
 }
 
-func (c *oauthClientSecretController) syncSecret(ctx context.Context, clientSecret string, recorder events.Recorder) error {
+// oauthClientSecretRefFilter returns true if obj is the secret the client secret of the console
+// OAuthClient is sourced from.
+func oauthClientSecretRefFilter(consoleOperatorLister operatorv1listers.ConsoleLister) factory.EventFilterFunc {
+	return func(obj interface{}) bool {
+		operatorConfig, err := consoleOperatorLister.Get(api.ConfigResourceName)
+		if err != nil {
+			return false
+		}
+		secretRef := secretsub.GetOAuthClientSecretRef(operatorConfig)
+		return len(secretRef) > 0 && util.IncludeNamesFilter(secretRef)(obj)
+	}
+}
+
+// syncSecret writes clientSecret to console-oauth-config. An OIDC client secret copied from
+// configSecret is only written again when the client secret or the secret it is copied from
+// changes, other updates of configSecret do not roll out the console. The client secret it
+// replaces is kept as the previous one.
+func (c *oauthClientSecretController) syncSecret(ctx context.Context, clientSecret string, configSecret *corev1.Secret, recorder events.Recorder) error {
 	operatorConfig, err := c.consoleOperatorLister.Get(api.ConfigResourceName)
 	if err != nil {
 		return err
 	}
 
+	required := secretsub.DefaultSecret(operatorConfig, clientSecret)
+	if configSecret != nil {
+		required = secretsub.DefaultOIDCClientSecret(operatorConfig, clientSecret, configSecret)
+	}
+
 	secret, err := c.targetNSSecretsLister.Secrets(api.TargetNamespace).Get("console-oauth-config")
-	if apierrors.IsNotFound(err) || secretsub.GetSecretString(secret) != clientSecret {
-		_, _, err = resourceapply.ApplySecret(ctx, c.secretsClient, recorder, secretsub.DefaultSecret(operatorConfig, clientSecret))
+	if apierrors.IsNotFound(err) {
+		_, _, err = resourceapply.ApplySecret(ctx, c.secretsClient, recorder, required)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if secretsub.GetSecretString(secret) != clientSecret ||
+		(configSecret != nil && !secretsub.IsOIDCClientSecretCopyOf(secret, configSecret.Name)) {
+		_, _, err = resourceapply.ApplySecret(ctx, c.secretsClient, recorder, secretsub.WithPreviousClientSecret(required, secret))
+	}
+	return err
+}
+
+func (c *oauthClientSecretController) removeSecret(ctx context.Context, clientSecret *corev1.Secret) error {
+	if clientSecret == nil {
+		return nil
+	}
+	err := c.secretsClient.Secrets(api.TargetNamespace).Delete(ctx, clientSecret.Name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
 	}
 	return err
 }
@@ -167,12 +283,12 @@ func (c *oauthClientSecretController) syncSecret(ctx context.Context, clientSecr
 // determining the operator's management state
 // TODO: extract this logic to where it can be used for all controllers
 func (c *oauthClientSecretController) handleManaged(ctx context.Context) (bool, error) {
-	operatorSpec, _, _, err := c.operatorClient.GetOperatorState()
+	operatorSpec, operatorStatus, _, err := c.operatorClient.GetOperatorState()
 	if err != nil {
 		return false, fmt.Errorf("failed to retrieve operator config: %w", err)
 	}
 
-	switch managementState := operatorSpec.ManagementState; managementState {
+	switch managementState := consolecapability.OperatorManagementState(operatorSpec, operatorStatus); managementState {
 	case operatorv1.Managed:
 		klog.V(4).Infoln("console is in a managed state.")
 		return true, nil
@@ -0,0 +1,229 @@
+package oauthserverprobe
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	// kube
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	configv1 "github.com/openshift/api/config/v1"
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	configinformer "github.com/openshift/client-go/config/informers/externalversions"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+const (
+	probeTimeout = 5 * time.Second
+	// unreachableGracePeriod is how long the token endpoint may be unreachable, eg. while the
+	// OAuth server rolls out, before the operator is degraded
+	unreachableGracePeriod = 3 * time.Minute
+)
+
+// OAuthServerProbeController checks that the token endpoint of the integrated OAuth server,
+// discovered from its OAuth authorization server metadata, is reachable from the operator. The
+// console exchanges the authorization codes of its users there: an unreachable token endpoint
+// breaks the console login while the console itself is available. The probe is progressing
+// while the token endpoint is unreachable and degraded once it stays unreachable.
+//
+//	writes:
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=OAuthServerTokenEndpointProgressing
+//		- type=OAuthServerTokenEndpointDegraded
+type OAuthServerProbeController struct {
+	operatorClient       v1helpers.OperatorClient
+	operatorConfigLister operatorv1listers.ConsoleLister
+	authnConfigLister    configlistersv1.AuthenticationLister
+	ingressConfigLister  configlistersv1.IngressLister
+	configMapLister      corev1listers.ConfigMapLister
+
+	// unreachableSince is when the token endpoint was first found unreachable, zero if it is reachable
+	unreachableSince time.Time
+}
+
+func NewOAuthServerProbeController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	// informers
+	configInformer configinformer.SharedInformerFactory,
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	targetNSConfigMapInformer corev1informers.ConfigMapInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	configV1Informers := configInformer.Config().V1()
+
+	ctrl := &OAuthServerProbeController{
+		operatorClient:       operatorClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		authnConfigLister:    configV1Informers.Authentications().Lister(),
+		ingressConfigLister:  configV1Informers.Ingresses().Lister(),
+		configMapLister:      targetNSConfigMapInformer.Lister(),
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+			configV1Informers.Authentications().Informer(),
+			configV1Informers.Ingresses().Informer(),
+		).WithFilteredEventsInformers( // oauth server CA
+		util.IncludeNamesFilter(api.OAuthServingCertConfigMapName),
+		targetNSConfigMapInformer.Informer(),
+	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController("OAuthServerProbeController", recorder.WithComponentSuffix("oauth-server-probe-controller"))
+}
+
+func (c *OAuthServerProbeController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch consolecapability.ManagementState(operatorConfig) {
+	case operatorsv1.Managed:
+		klog.V(4).Infoln("console-operator is in a managed state: probing the OAuth server token endpoint")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infoln("console-operator is in an unmanaged state: skipping the OAuth server probe")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infoln("console-operator is in a removed state: skipping the OAuth server probe")
+		return nil
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	reason, err := c.probe(ctx)
+
+	now := time.Now()
+	switch {
+	case err == nil:
+		c.unreachableSince = time.Time{}
+	case c.unreachableSince.IsZero():
+		c.unreachableSince = now
+	}
+	progressingErr, degradedErr := err, error(nil)
+	if err != nil && now.Sub(c.unreachableSince) >= unreachableGracePeriod {
+		progressingErr, degradedErr = nil, err
+	}
+	statusHandler.AddCondition(status.HandleProgressing("OAuthServerTokenEndpoint", reason, progressingErr))
+	statusHandler.AddCondition(status.HandleDegraded("OAuthServerTokenEndpoint", reason, degradedErr))
+	return statusHandler.FlushAndReturn(nil)
+}
+
+// probe runs the token endpoint probe if the cluster authenticates with the integrated OAuth server.
+func (c *OAuthServerProbeController) probe(ctx context.Context) (string, error) {
+	authnConfig, err := c.authnConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return "FailedGetAuthConfig", err
+	}
+	switch authnConfig.Spec.Type {
+	case "", configv1.AuthenticationTypeIntegratedOAuth:
+	default:
+		return "", nil
+	}
+
+	ingressConfig, err := c.ingressConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return "FailedGetIngressConfig", err
+	}
+
+	client, err := c.probeClient()
+	if err != nil {
+		return "FailedGetCA", err
+	}
+	return probeTokenEndpoint(ctx, client, "https://"+utilsub.GetOAuthServerHost(ingressConfig))
+}
+
+// probeClient returns the client of the probe, trusting the OAuth server like the console does.
+func (c *OAuthServerProbeController) probeClient() (*http.Client, error) {
+	tlsConfig := &tls.Config{}
+	cm, err := c.configMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(api.OAuthServingCertConfigMapName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if cm != nil && len(cm.Data[api.TrustedCABundleKey]) > 0 {
+		caPool := x509.NewCertPool()
+		if ok := caPool.AppendCertsFromPEM([]byte(cm.Data[api.TrustedCABundleKey])); !ok {
+			klog.V(4).Infof("failed to parse %s ca-bundle.crt", api.OAuthServingCertConfigMapName)
+		}
+		tlsConfig.RootCAs = caPool
+	}
+
+	return &http.Client{
+		Timeout: probeTimeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+	}, nil
+}
+
+// probeTokenEndpoint discovers the token endpoint from the OAuth authorization server metadata
+// of the issuer and checks that it answers. An empty token request is rejected by a healthy
+// token endpoint, only server errors and unanswered requests fail the probe.
+func probeTokenEndpoint(ctx context.Context, client *http.Client, issuerURL string) (string, error) {
+	metadataURL := strings.TrimSuffix(issuerURL, "/") + "/.well-known/oauth-authorization-server"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataURL, nil)
+	if err != nil {
+		return "AuthServerUnreachable", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "AuthServerUnreachable", fmt.Errorf("failed to GET %s: %v", metadataURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "AuthServerUnreachable", fmt.Errorf("%s returns '%s'", metadataURL, resp.Status)
+	}
+
+	metadata := struct {
+		TokenEndpoint string `json:"token_endpoint"`
+	}{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&metadata); err != nil {
+		return "InvalidMetadata", fmt.Errorf("failed to decode %s: %v", metadataURL, err)
+	}
+	if tokenURL, err := url.Parse(metadata.TokenEndpoint); err != nil || tokenURL.Scheme != "https" || len(tokenURL.Host) == 0 {
+		return "InvalidMetadata", fmt.Errorf("%s advertises the token endpoint %q, expected an https URL", metadataURL, metadata.TokenEndpoint)
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(url.Values{}.Encode()))
+	if err != nil {
+		return "AuthServerUnreachable", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	tokenResp, err := client.Do(req)
+	if err != nil {
+		return "AuthServerUnreachable", fmt.Errorf("failed to POST %s: %v", metadata.TokenEndpoint, err)
+	}
+	tokenResp.Body.Close()
+	if tokenResp.StatusCode >= http.StatusInternalServerError {
+		return "AuthServerUnreachable", fmt.Errorf("%s returns '%s'", metadata.TokenEndpoint, tokenResp.Status)
+	}
+	return "", nil
+}
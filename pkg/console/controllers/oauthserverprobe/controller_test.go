@@ -0,0 +1,81 @@
+package oauthserverprobe
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestProbeTokenEndpoint(t *testing.T) {
+	tests := []struct {
+		name string
+		// metadata handles /.well-known/oauth-authorization-server, it is passed the URL of the test server
+		metadata func(w http.ResponseWriter, r *http.Request, serverURL string)
+		// token handles /oauth/token
+		token      func(w http.ResponseWriter, r *http.Request)
+		wantReason string
+	}{
+		{
+			name: "Test token endpoint answers",
+			metadata: func(w http.ResponseWriter, r *http.Request, serverURL string) {
+				fmt.Fprintf(w, `{"issuer": %q, "token_endpoint": %q}`, serverURL, serverURL+"/oauth/token")
+			},
+			token: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, `{"error": "invalid_request"}`, http.StatusBadRequest)
+			},
+			wantReason: "",
+		},
+		{
+			name: "Test metadata not served",
+			metadata: func(w http.ResponseWriter, r *http.Request, serverURL string) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			wantReason: "AuthServerUnreachable",
+		},
+		{
+			name: "Test metadata without a token endpoint",
+			metadata: func(w http.ResponseWriter, r *http.Request, serverURL string) {
+				fmt.Fprintf(w, `{"issuer": %q}`, serverURL)
+			},
+			wantReason: "InvalidMetadata",
+		},
+		{
+			name: "Test token endpoint failing",
+			metadata: func(w http.ResponseWriter, r *http.Request, serverURL string) {
+				fmt.Fprintf(w, `{"issuer": %q, "token_endpoint": %q}`, serverURL, serverURL+"/oauth/token")
+			},
+			token: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			wantReason: "AuthServerUnreachable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var server *httptest.Server
+			server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/.well-known/oauth-authorization-server":
+					tt.metadata(w, r, server.URL)
+				case "/oauth/token":
+					tt.token(w, r)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			reason, err := probeTokenEndpoint(context.TODO(), server.Client(), server.URL)
+			if diff := deep.Equal(tt.wantReason, reason); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != (len(tt.wantReason) > 0) {
+				t.Errorf("probeTokenEndpoint() error = %v, wantReason %q", err, tt.wantReason)
+			}
+		})
+	}
+}
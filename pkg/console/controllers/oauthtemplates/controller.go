@@ -0,0 +1,162 @@
+package oauthtemplates
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	// kube
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1clients "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+	oauthtemplatessub "github.com/openshift/console-operator/pkg/console/subresource/oauthtemplates"
+)
+
+var templateSecretNames = []string{
+	api.OAuthLoginTemplateSecretName,
+	api.OAuthProvidersTemplateSecretName,
+	api.OAuthErrorTemplateSecretName,
+}
+
+// OAuthTemplatesController renders the console customization, the product name, the custom logo
+// and the colors of the OAuth templates colors annotation, into the login, provider selection and
+// error templates of the OAuth server once the operator config enables it with the OAuth templates
+// annotation. The templates are not set on the cluster OAuth config, the admin references the
+// secrets in oauths.config.openshift.io/cluster .spec.templates. The secrets are removed once
+// the annotation is unset.
+//
+//	writes:
+//	- secrets.console-oauth-login-template -n openshift-config .Data['login.html']
+//	- secrets.console-oauth-providers-template -n openshift-config .Data['providers.html']
+//	- secrets.console-oauth-error-template -n openshift-config .Data['errors.html']
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=OAuthTemplatesDegraded
+type OAuthTemplatesController struct {
+	operatorClient       v1helpers.OperatorClient
+	secretsClient        corev1clients.SecretsGetter
+	operatorConfigLister operatorv1listers.ConsoleLister
+	configMapLister      corev1listers.ConfigMapLister
+	secretLister         corev1listers.SecretLister
+}
+
+func NewOAuthTemplatesController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	secretsClient corev1clients.SecretsGetter,
+	// informers
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	configNSConfigMapInformer corev1informers.ConfigMapInformer,
+	configNSSecretInformer corev1informers.SecretInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &OAuthTemplatesController{
+		operatorClient:       operatorClient,
+		secretsClient:        secretsClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		configMapLister:      configNSConfigMapInformer.Lister(),
+		secretLister:         configNSSecretInformer.Lister(),
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // operator config
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+		).WithInformers( // custom logo
+		configNSConfigMapInformer.Informer(),
+	).WithFilteredEventsInformers( // rendered templates
+		util.IncludeNamesFilter(templateSecretNames...),
+		configNSSecretInformer.Informer(),
+	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController("OAuthTemplatesController", recorder.WithComponentSuffix("oauth-templates-controller"))
+}
+
+func (c *OAuthTemplatesController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch consolecapability.ManagementState(operatorConfig) {
+	case operatorsv1.Managed:
+		klog.V(4).Infoln("console-operator is in a managed state: syncing the OAuth templates")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infoln("console-operator is in an unmanaged state: skipping the OAuth templates sync")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infoln("console-operator is in a removed state: removing the OAuth templates")
+		return c.removeTemplates(ctx)
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	if operatorConfig.Annotations[api.OAuthTemplatesAnnotation] != "true" {
+		statusHandler.AddCondition(status.HandleDegraded("OAuthTemplates", "", nil))
+		return statusHandler.FlushAndReturn(c.removeTemplates(ctx))
+	}
+
+	reason, err := c.syncTemplates(ctx, controllerContext.Recorder(), operatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("OAuthTemplates", reason, err))
+	return statusHandler.FlushAndReturn(err)
+}
+
+// syncTemplates renders the branding into the template secrets, invalid colors fall back to the
+// defaults and are reported once the templates are applied.
+func (c *OAuthTemplatesController) syncTemplates(ctx context.Context, recorder events.Recorder, operatorConfig *operatorsv1.Console) (string, error) {
+	var logoConfigMap *corev1.ConfigMap
+	if name := operatorConfig.Spec.Customization.CustomLogoFile.Name; len(name) > 0 {
+		cm, err := c.configMapLister.ConfigMaps(api.OpenShiftConfigNamespace).Get(name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return "FailedGetLogo", err
+		}
+		logoConfigMap = cm
+	}
+
+	branding, brandingErr := oauthtemplatessub.GetBranding(operatorConfig, logoConfigMap)
+	secrets, err := oauthtemplatessub.DefaultSecrets(branding)
+	if err != nil {
+		return "FailedRender", err
+	}
+	for _, secret := range secrets {
+		if _, _, err := resourceapply.ApplySecret(ctx, c.secretsClient, recorder, secret); err != nil {
+			return "FailedApply", err
+		}
+	}
+	if brandingErr != nil {
+		return "InvalidColors", brandingErr
+	}
+	return "", nil
+}
+
+func (c *OAuthTemplatesController) removeTemplates(ctx context.Context) error {
+	for _, name := range templateSecretNames {
+		if _, err := c.secretLister.Secrets(api.OpenShiftConfigNamespace).Get(name); apierrors.IsNotFound(err) {
+			continue
+		}
+		err := c.secretsClient.Secrets(api.OpenShiftConfigNamespace).Delete(ctx, name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package oidcloginprobe
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	// kube
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	configv1 "github.com/openshift/api/config/v1"
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	configinformer "github.com/openshift/client-go/config/informers/externalversions"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
+	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+const probeTimeout = 5 * time.Second
+
+// OIDCLoginProbeController walks the first steps of the OIDC authorization code flow against
+// the console route when the login probe annotation of the operator config is set: the console
+// login has to redirect to the OIDC provider with the console client and callback, and the
+// provider has to accept that authorization request. A redirect URI or client the provider does
+// not know about is caught here rather than by the first user logging in.
+//
+//	writes:
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=OIDCLoginFlowDegraded
+type OIDCLoginProbeController struct {
+	operatorClient       v1helpers.OperatorClient
+	operatorConfigLister operatorv1listers.ConsoleLister
+	authnConfigLister    configlistersv1.AuthenticationLister
+	consoleConfigLister  configlistersv1.ConsoleLister
+	configMapLister      corev1listers.ConfigMapLister
+}
+
+func NewOIDCLoginProbeController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	// informers
+	configInformer configinformer.SharedInformerFactory,
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	targetNSConfigMapInformer corev1informers.ConfigMapInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	configV1Informers := configInformer.Config().V1()
+
+	ctrl := &OIDCLoginProbeController{
+		operatorClient:       operatorClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		authnConfigLister:    configV1Informers.Authentications().Lister(),
+		consoleConfigLister:  configV1Informers.Consoles().Lister(),
+		configMapLister:      targetNSConfigMapInformer.Lister(),
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+			configV1Informers.Authentications().Informer(),
+			configV1Informers.Consoles().Informer(),
+		).WithFilteredEventsInformers( // CA bundles
+		util.IncludeNamesFilter(api.OIDCCATrustConfigMapName, api.TrustedCAConfigMapName, api.DefaultIngressCertConfigMapName),
+		targetNSConfigMapInformer.Informer(),
+	).ResyncEvery(5*time.Minute).WithSync(ctrl.Sync).
+		ToController("OIDCLoginProbeController", recorder.WithComponentSuffix("oidc-login-probe-controller"))
+}
+
+func (c *OIDCLoginProbeController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch consolecapability.ManagementState(operatorConfig) {
+	case operatorsv1.Managed:
+		klog.V(4).Infoln("console-operator is in a managed state: probing the OIDC login flow")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infoln("console-operator is in an unmanaged state: skipping the OIDC login probe")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infoln("console-operator is in a removed state: skipping the OIDC login probe")
+		return nil
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	reason, err := c.probe(operatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("OIDCLoginFlow", reason, err))
+	return statusHandler.FlushAndReturn(nil)
+}
+
+// probe runs the login probe if it is enabled and the console logs in with an OIDC provider.
+func (c *OIDCLoginProbeController) probe(operatorConfig *operatorsv1.Console) (string, error) {
+	if enabled, err := strconv.ParseBool(operatorConfig.Annotations[api.OIDCLoginProbeAnnotation]); err != nil || !enabled {
+		return "", nil
+	}
+	// a paused console has no endpoints to log in with
+	if deploymentsub.IsPaused(operatorConfig) {
+		return "", nil
+	}
+
+	authnConfig, err := c.authnConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return "FailedGetAuthConfig", err
+	}
+	if authnConfig.Spec.Type != configv1.AuthenticationTypeOIDC {
+		return "", nil
+	}
+	clientID := ""
+	if clientConfig := utilsub.GetOIDCClientConfig(authnConfig); clientConfig != nil {
+		clientID = clientConfig.ClientID
+	}
+
+	consoleConfig, err := c.consoleConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return "FailedGetConsoleConfig", err
+	}
+	// the route health check reports a console URL that is not admitted yet
+	if len(consoleConfig.Status.ConsoleURL) == 0 {
+		return "", nil
+	}
+
+	client, err := c.probeClient()
+	if err != nil {
+		return "FailedLoadCA", err
+	}
+	return probeLoginFlow(client, consoleConfig.Status.ConsoleURL, clientID)
+}
+
+// probeClient returns the client of the probe, trusting the router and the OIDC providers like
+// the console does. It does not follow redirects, each step of the flow is checked on its own.
+func (c *OIDCLoginProbeController) probeClient() (*http.Client, error) {
+	caPool := x509.NewCertPool()
+	for _, cmName := range []string{api.DefaultIngressCertConfigMapName, api.TrustedCAConfigMapName, api.OIDCCATrustConfigMapName} {
+		cm, err := c.configMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(cmName)
+		if apierrors.IsNotFound(err) {
+			klog.V(4).Infof("CA configmap %s/%s not found, not trusted for the OIDC login probe", api.OpenShiftConsoleNamespace, cmName)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ok := caPool.AppendCertsFromPEM([]byte(cm.Data[api.TrustedCABundleKey])); !ok {
+			klog.V(4).Infof("failed to parse %s ca-bundle.crt", cmName)
+		}
+	}
+
+	return &http.Client{
+		Timeout: probeTimeout,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{
+				RootCAs: caPool,
+			},
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}, nil
+}
+
+// probeLoginFlow checks that the login of the console at consoleURL redirects to an OIDC
+// provider with the console callback as redirect URI, and clientID if set, and that the provider
+// accepts the authorization request. The login itself is not completed.
+func probeLoginFlow(client *http.Client, consoleURL, clientID string) (string, error) {
+	consoleURL = strings.TrimSuffix(consoleURL, "/")
+	loginURL := consoleURL + "/auth/login"
+	resp, err := client.Get(loginURL)
+	if err != nil {
+		return "LoginUnreachable", fmt.Errorf("failed to GET %s: %v", loginURL, err)
+	}
+	resp.Body.Close()
+	authorizeURL, err := resp.Location()
+	if !isRedirect(resp.StatusCode) || err != nil {
+		return "LoginNotRedirected", fmt.Errorf("%s returns '%s' instead of a redirect to the OIDC provider", loginURL, resp.Status)
+	}
+
+	query := authorizeURL.Query()
+	if redirectURI, callbackURL := query.Get("redirect_uri"), consoleURL+"/auth/callback"; redirectURI != callbackURL {
+		return "RedirectURIMismatch", fmt.Errorf("%s redirects to the OIDC provider with the redirect URI %q instead of %q", loginURL, redirectURI, callbackURL)
+	}
+	if len(clientID) > 0 && query.Get("client_id") != clientID {
+		return "ClientIDMismatch", fmt.Errorf("%s redirects to the OIDC provider with the client %q instead of %q", loginURL, query.Get("client_id"), clientID)
+	}
+
+	resp, err = client.Get(authorizeURL.String())
+	if err != nil {
+		return "AuthorizationUnreachable", fmt.Errorf("failed to GET %s: %v", endpointOf(authorizeURL), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		// the error page of the provider carries the reason of the rejection, eg. an unknown redirect URI
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "AuthorizationRejected", fmt.Errorf("%s returns '%s': %s", endpointOf(authorizeURL), resp.Status, strings.TrimSpace(string(message)))
+	}
+	// a provider that trusts the redirect URI sends the errors of the request back to it
+	if location, err := resp.Location(); isRedirect(resp.StatusCode) && err == nil && len(location.Query().Get("error")) > 0 {
+		return "AuthorizationRejected", fmt.Errorf("%s rejects the authorization request: %s %s", endpointOf(authorizeURL), location.Query().Get("error"), location.Query().Get("error_description"))
+	}
+	return "", nil
+}
+
+func isRedirect(statusCode int) bool {
+	return statusCode >= http.StatusMultipleChoices && statusCode < http.StatusBadRequest
+}
+
+// endpointOf strips the query of the authorization request, it carries the state and nonce of
+// the probe.
+func endpointOf(authorizeURL *url.URL) string {
+	endpoint := *authorizeURL
+	endpoint.RawQuery = ""
+	return endpoint.String()
+}
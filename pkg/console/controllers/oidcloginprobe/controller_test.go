@@ -0,0 +1,114 @@
+package oidcloginprobe
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestProbeLoginFlow(t *testing.T) {
+	tests := []struct {
+		name string
+		// login handles /auth/login of the console, it is passed the URL of the test server
+		login func(w http.ResponseWriter, r *http.Request, serverURL string)
+		// authorize handles /authorize of the OIDC provider
+		authorize  func(w http.ResponseWriter, r *http.Request)
+		clientID   string
+		wantReason string
+	}{
+		{
+			name: "Test login flow accepted by the OIDC provider",
+			login: func(w http.ResponseWriter, r *http.Request, serverURL string) {
+				http.Redirect(w, r, serverURL+"/authorize?client_id=console&redirect_uri="+url.QueryEscape(serverURL+"/auth/callback"), http.StatusSeeOther)
+			},
+			authorize: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			},
+			clientID:   "console",
+			wantReason: "",
+		},
+		{
+			name: "Test login not redirected to the OIDC provider",
+			login: func(w http.ResponseWriter, r *http.Request, serverURL string) {
+				w.WriteHeader(http.StatusOK)
+			},
+			wantReason: "LoginNotRedirected",
+		},
+		{
+			name: "Test login redirected with another redirect URI",
+			login: func(w http.ResponseWriter, r *http.Request, serverURL string) {
+				http.Redirect(w, r, serverURL+"/authorize?client_id=console&redirect_uri="+url.QueryEscape("https://console.old.example.com/auth/callback"), http.StatusSeeOther)
+			},
+			wantReason: "RedirectURIMismatch",
+		},
+		{
+			name: "Test login redirected with another client",
+			login: func(w http.ResponseWriter, r *http.Request, serverURL string) {
+				http.Redirect(w, r, serverURL+"/authorize?client_id=other&redirect_uri="+url.QueryEscape(serverURL+"/auth/callback"), http.StatusSeeOther)
+			},
+			clientID:   "console",
+			wantReason: "ClientIDMismatch",
+		},
+		{
+			name: "Test authorization request rejected by the OIDC provider",
+			login: func(w http.ResponseWriter, r *http.Request, serverURL string) {
+				http.Redirect(w, r, serverURL+"/authorize?client_id=console&redirect_uri="+url.QueryEscape(serverURL+"/auth/callback"), http.StatusSeeOther)
+			},
+			authorize: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "Invalid parameter: redirect_uri", http.StatusBadRequest)
+			},
+			wantReason: "AuthorizationRejected",
+		},
+		{
+			name: "Test authorization request error sent back to the console",
+			login: func(w http.ResponseWriter, r *http.Request, serverURL string) {
+				http.Redirect(w, r, serverURL+"/authorize?client_id=console&redirect_uri="+url.QueryEscape(serverURL+"/auth/callback"), http.StatusSeeOther)
+			},
+			authorize: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, r.URL.Query().Get("redirect_uri")+"?error=unauthorized_client", http.StatusFound)
+			},
+			wantReason: "AuthorizationRejected",
+		},
+		{
+			name: "Test authorization request redirected to the provider login page",
+			login: func(w http.ResponseWriter, r *http.Request, serverURL string) {
+				http.Redirect(w, r, serverURL+"/authorize?client_id=console&redirect_uri="+url.QueryEscape(serverURL+"/auth/callback"), http.StatusSeeOther)
+			},
+			authorize: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "/login?session=1", http.StatusFound)
+			},
+			wantReason: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var server *httptest.Server
+			server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/auth/login":
+					tt.login(w, r, server.URL)
+				case "/authorize":
+					tt.authorize(w, r)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			client := server.Client()
+			client.CheckRedirect = func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			}
+			reason, err := probeLoginFlow(client, server.URL, tt.clientID)
+			if diff := deep.Equal(tt.wantReason, reason); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != (len(tt.wantReason) > 0) {
+				t.Errorf("probeLoginFlow() error = %v, wantReason %q", err, tt.wantReason)
+			}
+		})
+	}
+}
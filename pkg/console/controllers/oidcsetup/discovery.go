@@ -0,0 +1,149 @@
+package oidcsetup
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/console-operator/pkg/api"
+	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+const discoveryTimeout = 5 * time.Second
+
+// discoveryDocument holds the fields of the OpenID provider metadata the console login relies on
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	// RegistrationEndpoint is only listed by providers supporting dynamic client registration
+	RegistrationEndpoint string `json:"registration_endpoint,omitempty"`
+}
+
+// probeIssuers fetches the discovery document of every OIDC provider and returns the reason
+// and error of the first one the console could not log in with.
+func (c *oidcSetupController) probeIssuers(providerClients []utilsub.OIDCProviderClient) (string, error) {
+	client, reason, err := c.issuerClient()
+	if err != nil {
+		return reason, err
+	}
+	for _, providerClient := range providerClients {
+		if reason, err := probeIssuerDiscovery(client, providerClient.Provider.Issuer.URL, providerClient.Endpoint); err != nil {
+			return reason, fmt.Errorf("OIDC provider %q: %w", providerClient.Provider.Name, err)
+		}
+	}
+	return "", nil
+}
+
+// issuerClient returns a client trusting the merged OIDC CA trust and the cluster trusted CA
+// bundle like the console does.
+func (c *oidcSetupController) issuerClient() (*http.Client, string, error) {
+	caBundles := []string{}
+	for _, caConfigName := range []string{api.OIDCCATrustConfigMapName, api.TrustedCAConfigMapName} {
+		caConfig, err := c.targetNSConfigMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(caConfigName)
+		if apierrors.IsNotFound(err) {
+			klog.V(4).Infof("CA configmap %s/%s not found, not trusted for the OIDC issuer discovery", api.OpenShiftConsoleNamespace, caConfigName)
+			continue
+		}
+		if err != nil {
+			return nil, "FailedGetCA", err
+		}
+		if caBundle := caConfig.Data[api.AuthServerCAFileName]; len(caBundle) > 0 {
+			caBundles = append(caBundles, caBundle)
+		}
+	}
+
+	client, err := discoveryClient(caBundles)
+	if err != nil {
+		return nil, "InvalidIssuerCA", err
+	}
+	return client, "", nil
+}
+
+// probeIssuerDiscovery fetches the discovery document of issuerURL, from endpoint if the issuer is
+// reached at another URL than the advertised one, and checks that it describes the issuer and
+// lists the endpoints of the authorization code flow.
+func probeIssuerDiscovery(client *http.Client, issuerURL, endpoint string) (string, error) {
+	if len(endpoint) == 0 {
+		endpoint = issuerURL
+	}
+	document, reason, err := getDiscoveryDocument(client, endpoint)
+	if err != nil {
+		return reason, err
+	}
+	discoveryURL := discoveryDocumentURL(endpoint)
+	if document.Issuer != issuerURL {
+		return "IssuerMismatch", fmt.Errorf("%s describes the issuer %q instead of %q", discoveryURL, document.Issuer, issuerURL)
+	}
+
+	missing := []string{}
+	for field, value := range map[string]string{
+		"authorization_endpoint": document.AuthorizationEndpoint,
+		"token_endpoint":         document.TokenEndpoint,
+		"jwks_uri":               document.JWKSURI,
+	} {
+		if len(value) == 0 {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return "IssuerMissingEndpoints", fmt.Errorf("%s does not list %s", discoveryURL, strings.Join(missing, ", "))
+	}
+	return "", nil
+}
+
+// getDiscoveryDocument fetches and decodes the discovery document of the issuer at issuerURL.
+func getDiscoveryDocument(client *http.Client, issuerURL string) (*discoveryDocument, string, error) {
+	discoveryURL := discoveryDocumentURL(issuerURL)
+	resp, err := client.Get(discoveryURL)
+	if err != nil {
+		return nil, "IssuerUnreachable", fmt.Errorf("failed to GET %s: %v", discoveryURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "IssuerUnreachable", fmt.Errorf("%s returns '%s'", discoveryURL, resp.Status)
+	}
+
+	document := &discoveryDocument{}
+	if err := json.NewDecoder(resp.Body).Decode(document); err != nil {
+		return nil, "InvalidIssuerDiscovery", fmt.Errorf("failed to decode %s: %v", discoveryURL, err)
+	}
+	return document, "", nil
+}
+
+func discoveryDocumentURL(issuerURL string) string {
+	return strings.TrimSuffix(issuerURL, "/") + "/.well-known/openid-configuration"
+}
+
+// discoveryClient trusts the given CA bundles, or the system roots if there is none. Requests go
+// through the cluster proxy set in the operator environment.
+func discoveryClient(caBundles []string) (*http.Client, error) {
+	tlsConfig := &tls.Config{}
+	if len(caBundles) > 0 {
+		caPool := x509.NewCertPool()
+		for _, caBundle := range caBundles {
+			if ok := caPool.AppendCertsFromPEM([]byte(caBundle)); !ok {
+				return nil, fmt.Errorf("failed to parse CA bundle")
+			}
+		}
+		tlsConfig.RootCAs = caPool
+	}
+	return &http.Client{
+		Timeout: discoveryTimeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+	}, nil
+}
@@ -3,16 +3,23 @@ package oidcsetup
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	configv1client "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
+	"gopkg.in/yaml.v2"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	apiexensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
-	apiexensionsv1informers "k8s.io/apiextensions-apiserver/pkg/client/informers/externalversions/apiextensions/v1"
-	apiexensionsv1listers "k8s.io/apiextensions-apiserver/pkg/client/listers/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	appsv1informers "k8s.io/client-go/informers/apps/v1"
 	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1clients "k8s.io/client-go/kubernetes/typed/core/v1"
 	appsv1listers "k8s.io/client-go/listers/apps/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
@@ -24,19 +31,33 @@ import (
 	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
 	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
 	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/metrics"
 	"github.com/openshift/console-operator/pkg/console/status"
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
 
+	customerrors "github.com/openshift/console-operator/pkg/console/errors"
 	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
+	secretsub "github.com/openshift/console-operator/pkg/console/subresource/secret"
 	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
 )
 
+// secretProviderClassPodStatusGVR is reported by the Secrets Store CSI driver for every pod
+// that mounts a SecretProviderClass volume
+var secretProviderClassPodStatusGVR = schema.GroupVersionResource{
+	Group:    "secrets-store.csi.x-k8s.io",
+	Version:  "v1",
+	Resource: "secretproviderclasspodstatuses",
+}
+
 // oidcSetupController:
 //
 //	writes:
-//	- authentication.config.openshift.io/cluster .status.oidcClients:
+//	- authentication.config.openshift.io/cluster .status.oidcClients, pruned unless Type=OIDC:
 //		- componentName=console
 //		- componentNamespace=openshift-console
 //		- currentOIDCClients
@@ -49,51 +70,79 @@ import (
 //		- type=OIDCClientConfigDegraded
 //		- type=AuthStatusHandlerProgressing
 //		- type=AuthStatusHandlerDegraded
+//		- type=OIDCIssuerDiscoveryDegraded
+//		- type=OIDCIssuerEndpointsDegraded
+//	- secrets/console-oidc-registered-client -n openshift-console:
+//		- client ID and secret registered with the OIDC provider, when enabled
 type oidcSetupController struct {
 	operatorClient v1helpers.OperatorClient
+	dynamicClient  dynamic.Interface
+	secretsClient  corev1clients.SecretsGetter
 
 	authnLister               configv1listers.AuthenticationLister
-	crdLister                 apiexensionsv1listers.CustomResourceDefinitionLister
+	consoleConfigLister       configv1listers.ConsoleLister
 	consoleOperatorLister     operatorv1listers.ConsoleLister
+	configSecretsLister       corev1listers.SecretLister
 	targetNSSecretsLister     corev1listers.SecretLister
 	targetNSConfigMapLister   corev1listers.ConfigMapLister
 	targetNSDeploymentsLister appsv1listers.DeploymentLister
+	targetNSPodsLister        corev1listers.PodLister
 
+	crdSchema         *util.CRDSchemaEvaluator
 	authStatusHandler *status.AuthStatusHandler
+	// backs off the requeues while the OIDC provider or the API server fails the sync
+	backoff *util.RequeueBackoff
 }
 
 func NewOIDCSetupController(
 	operatorClient v1helpers.OperatorClient,
+	dynamicClient dynamic.Interface,
+	secretsClient corev1clients.SecretsGetter,
 	authnInformer configv1informers.AuthenticationInformer,
 	authenticationClient configv1client.AuthenticationInterface,
+	consoleConfigInformer configv1informers.ConsoleInformer,
 	consoleOperatorInformer operatorv1informers.ConsoleInformer,
-	crdInformer apiexensionsv1informers.CustomResourceDefinitionInformer,
+	crdSchema *util.CRDSchemaEvaluator,
+	configSecretsInformer corev1informers.SecretInformer,
 	targetNSsecretsInformer corev1informers.SecretInformer,
 	targetNSConfigMapInformer corev1informers.ConfigMapInformer,
 	targetNSDeploymentsInformer appsv1informers.DeploymentInformer,
+	targetNSPodsInformer corev1informers.PodInformer,
+	backoffOptions util.RequeueBackoffOptions,
 	recorder events.Recorder,
 ) factory.Controller {
 	c := &oidcSetupController{
 		operatorClient: operatorClient,
+		dynamicClient:  dynamicClient,
+		secretsClient:  secretsClient,
 
 		authnLister:               authnInformer.Lister(),
+		consoleConfigLister:       consoleConfigInformer.Lister(),
 		consoleOperatorLister:     consoleOperatorInformer.Lister(),
-		crdLister:                 crdInformer.Lister(),
+		configSecretsLister:       configSecretsInformer.Lister(),
 		targetNSSecretsLister:     targetNSsecretsInformer.Lister(),
 		targetNSDeploymentsLister: targetNSDeploymentsInformer.Lister(),
+		targetNSPodsLister:        targetNSPodsInformer.Lister(),
 		targetNSConfigMapLister:   targetNSConfigMapInformer.Lister(),
 
+		crdSchema:         crdSchema,
 		authStatusHandler: status.NewAuthStatusHandler(authenticationClient, api.OpenShiftConsoleName, api.TargetNamespace, api.OpenShiftConsoleOperator),
+		backoff:           util.NewRequeueBackoff(backoffOptions),
 	}
 	return factory.New().
 		WithSync(c.sync).
 		ResyncEvery(wait.Jitter(time.Minute, 1.0)).
 		WithFilteredEventsInformers(
 			factory.NamesFilter("authentications.config.openshift.io"),
-			crdInformer.Informer(),
+			crdSchema.Informer(),
+		).
+		WithFilteredEventsInformers(
+			util.OIDCClientSecretFilter(authnInformer.Lister()),
+			configSecretsInformer.Informer(),
 		).
 		WithInformers(
 			authnInformer.Informer(),
+			consoleConfigInformer.Informer(),
 			consoleOperatorInformer.Informer(),
 			targetNSsecretsInformer.Informer(),
 			targetNSDeploymentsInformer.Informer(),
@@ -102,7 +151,12 @@ func NewOIDCSetupController(
 		ToController("OIDCSetupController", recorder.WithComponentSuffix("oidc-setup-controller"))
 }
 
-func (c *oidcSetupController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+func (c *oidcSetupController) sync(ctx context.Context, syncCtx factory.SyncContext) (err error) {
+	start := time.Now()
+	defer func() {
+		metrics.HandleOIDCSetupSync(time.Since(start), err)
+		err = c.backoff.Handle(syncCtx, err)
+	}()
 	statusHandler := status.NewStatusHandler(c.operatorClient)
 
 	if shouldSync, err := c.handleManaged(ctx); err != nil {
@@ -111,7 +165,7 @@ func (c *oidcSetupController) sync(ctx context.Context, syncCtx factory.SyncCont
 		return nil
 	}
 
-	oidcClientsSchema, err := authnConfigHasOIDCFields(c.crdLister)
+	oidcClientsSchema, err := c.crdSchema.HasField("authentications.config.openshift.io", "status", "oidcClients")
 	if err != nil {
 		return statusHandler.FlushAndReturn(err)
 	}
@@ -123,6 +177,8 @@ func (c *oidcSetupController) sync(ctx context.Context, syncCtx factory.SyncCont
 		// reset all conditions set by this controller
 		statusHandler.AddConditions(status.HandleProgressingOrDegraded("OIDCClientConfig", "", nil))
 		statusHandler.AddConditions(status.HandleProgressingOrDegraded("AuthStatusHandler", "", nil))
+		statusHandler.AddCondition(status.HandleDegraded("OIDCIssuerDiscovery", "", nil))
+		statusHandler.AddCondition(status.HandleDegraded("OIDCIssuerEndpoints", "", nil))
 		return statusHandler.FlushAndReturn(nil)
 	}
 
@@ -136,13 +192,18 @@ func (c *oidcSetupController) sync(ctx context.Context, syncCtx factory.SyncCont
 		return err
 	}
 
+	metrics.HandleAuthenticationType(string(authenticationType(authnConfig)))
+
 	if authnConfig.Spec.Type != configv1.AuthenticationTypeOIDC {
-		applyErr := c.authStatusHandler.Apply(ctx, authnConfig)
-		statusHandler.AddConditions(status.HandleProgressingOrDegraded("AuthStatusHandler", "FailedApply", applyErr))
+		metrics.HandleOIDCClientState("")
+		removeErr := c.removeOIDCClientStatus(ctx, syncCtx.Recorder(), authnConfig)
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("AuthStatusHandler", "FailedRemove", removeErr))
 
-		// reset the other condition set by this controller
+		// reset the other conditions set by this controller
 		statusHandler.AddConditions(status.HandleProgressingOrDegraded("OIDCClientConfig", "", nil))
-		return statusHandler.FlushAndReturn(applyErr)
+		statusHandler.AddCondition(status.HandleDegraded("OIDCIssuerDiscovery", "", nil))
+		statusHandler.AddCondition(status.HandleDegraded("OIDCIssuerEndpoints", "", nil))
+		return statusHandler.FlushAndReturn(removeErr)
 	}
 
 	// we need to keep track of errors during the sync so that we can requeue
@@ -159,10 +220,19 @@ func (c *oidcSetupController) sync(ctx context.Context, syncCtx factory.SyncCont
 		errs = append(errs, syncErr)
 	}
 
+	from, to, reason, transitionErr := c.authStatusHandler.Transition(authnConfig)
+	if transitionErr != nil {
+		klog.V(4).Infof("failed to determine the OIDC client state transition: %v", transitionErr)
+	}
 	applyErr := c.authStatusHandler.Apply(ctx, authnConfig)
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded("AuthStatusHandler", "FailedApply", applyErr))
 	if applyErr != nil {
 		errs = append(errs, applyErr)
+	} else if transitionErr == nil {
+		metrics.HandleOIDCClientState(to)
+	}
+	if applyErr == nil && transitionErr == nil && from != to {
+		syncCtx.Recorder().Eventf("OIDCClient"+to, "Console OIDC client moved from %s to %s (%s): %s", from, to, reason, describeOIDCClients(authnConfig))
 	}
 
 	if len(errs) > 0 {
@@ -178,31 +248,96 @@ func (c *oidcSetupController) syncAuthTypeOIDC(
 	operatorConfig *operatorv1.Console,
 	authnConfig *configv1.Authentication,
 ) error {
+	// only set once the deployment runs with the current client config
+	statusHandler.AddCondition(status.HandleDegraded("OIDCIssuerDiscovery", "", nil))
 
-	clientConfig := utilsub.GetOIDCClientConfig(authnConfig)
-	if clientConfig == nil {
-		c.authStatusHandler.WithCurrentOIDCClient("")
+	// invalid endpoints are left out of console-config, the console reaches those issuers at
+	// their advertised URL
+	issuerEndpoints, endpointsErr := utilsub.GetOIDCIssuerEndpoints(operatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("OIDCIssuerEndpoints", "InvalidOIDCIssuerEndpoints", endpointsErr))
+
+	providerClients := utilsub.GetOIDCProviderClients(authnConfig)
+	for i := range providerClients {
+		providerClients[i].Endpoint = issuerEndpoints[providerClients[i].Provider.Name]
+	}
+	if len(providerClients) == 0 {
+		c.authStatusHandler.WithCurrentOIDCClients(nil)
+		clientID, reason, err := c.syncClientRegistration(ctx, controllerContext.Recorder(), operatorConfig, authnConfig)
+		if customerrors.IsSyncError(err) {
+			c.authStatusHandler.Progressing(reason, err.Error())
+			return nil
+		} else if err != nil {
+			c.authStatusHandler.Degraded(reason, err.Error())
+			return err
+		}
+		if len(clientID) > 0 {
+			// the console is deployed with the client once it is set in the authentication config
+			c.authStatusHandler.Unavailable("OIDCClientRegistered", fmt.Sprintf(
+				"console client %q registered with the OIDC provider, its client secret is stored in secret %s/%s",
+				clientID, api.TargetNamespace, api.OIDCRegisteredClientSecretName,
+			))
+			return nil
+		}
 		c.authStatusHandler.Unavailable("OIDCClientConfig", "no OIDC client found")
 		return nil
 	}
 
-	if len(clientConfig.ClientID) == 0 {
-		return fmt.Errorf("no ID set on console's OIDC client")
+	currentClients := []configv1.OIDCClientReference{}
+	for _, providerClient := range providerClients {
+		if len(providerClient.Client.ClientID) == 0 {
+			return fmt.Errorf("no ID set on console's OIDC client of provider %q", providerClient.Provider.Name)
+		}
+		currentClients = append(currentClients, configv1.OIDCClientReference{
+			OIDCProviderName: providerClient.Provider.Name,
+			IssuerURL:        providerClient.Provider.Issuer.URL,
+			ClientID:         providerClient.Client.ClientID,
+		})
 	}
-	c.authStatusHandler.WithCurrentOIDCClient(clientConfig.ClientID)
+	c.authStatusHandler.WithCurrentOIDCClients(currentClients)
 
-	if len(clientConfig.ClientSecret.Name) == 0 {
+	// the console is deployed with the client secret of the primary provider, unless it
+	// logs in as a public client
+	oidcPublicClient := utilsub.IsOIDCPublicClient(operatorConfig, authnConfig)
+	if len(providerClients[0].Client.ClientSecret.Name) == 0 && !oidcPublicClient {
 		c.authStatusHandler.Degraded("OIDCClientMissingSecret", "no client secret in the OIDC client config")
 		return nil
 	}
 
-	clientSecret, err := c.targetNSSecretsLister.Secrets(api.TargetNamespace).Get("console-oauth-config")
-	if err != nil {
-		c.authStatusHandler.Degraded("OIDCClientSecretGet", err.Error())
-		return err
+	var (
+		valid bool
+		msg   string
+		err   error
+	)
+	if secretProviderClass := utilsub.GetOIDCSecretProviderClass(operatorConfig, authnConfig); len(secretProviderClass) > 0 {
+		valid, msg, err = c.checkMountedClientConfigStatus(ctx, providerClients, secretProviderClass)
+	} else if oidcPublicClient {
+		valid, msg, err = c.checkClientConfigStatus(providerClients, nil)
+	} else {
+		// the client secret is copied from the secret referenced by the client config
+		clientSecretName := providerClients[0].Client.ClientSecret.Name
+		configSecret, getErr := c.configSecretsLister.Secrets(api.OpenShiftConfigNamespace).Get(clientSecretName)
+		if getErr != nil {
+			c.authStatusHandler.Degraded("OIDCClientSecretGet", getErr.Error())
+			return getErr
+		}
+		// a malformed client secret is not copied, the console keeps running with the last one
+		if validationErr := secretsub.ValidateOIDCClientSecret(secretsub.GetOIDCClientSecret(operatorConfig, configSecret, time.Now())); validationErr != nil {
+			c.authStatusHandler.Degraded("OIDCClientSecretMalformed", fmt.Sprintf("secret %s/%s: %v", api.OpenShiftConfigNamespace, clientSecretName, validationErr))
+			return nil
+		}
+		clientSecret, getErr := c.targetNSSecretsLister.Secrets(api.TargetNamespace).Get(deploymentsub.ConsoleOauthConfigName)
+		if getErr != nil {
+			c.authStatusHandler.Degraded("OIDCClientSecretGet", getErr.Error())
+			return getErr
+		}
+		if !secretsub.IsOIDCClientSecretCopyOf(clientSecret, clientSecretName) {
+			c.authStatusHandler.Progressing("DeploymentOIDCConfig", fmt.Sprintf("client secret not yet synced from %s/%s", api.OpenShiftConfigNamespace, clientSecretName))
+			return nil
+		}
+		valid, msg, err = c.checkClientConfigStatus(providerClients, clientSecret)
 	}
 
-	if valid, msg, err := c.checkClientConfigStatus(authnConfig, clientSecret); err != nil {
+	if err != nil {
 		c.authStatusHandler.Degraded("DeploymentOIDCConfig", err.Error())
 		return err
 
@@ -211,15 +346,39 @@ func (c *oidcSetupController) syncAuthTypeOIDC(
 		return nil
 	}
 
+	// the login page is broken if the console cannot discover the endpoints of an issuer
+	if reason, err := c.probeIssuers(providerClients); err != nil {
+		statusHandler.AddCondition(status.HandleDegraded("OIDCIssuerDiscovery", reason, err))
+		c.authStatusHandler.Degraded(reason, err.Error())
+		return nil
+	}
+
 	c.authStatusHandler.Available("OIDCConfigAvailable", "")
 	return nil
 }
 
+// removeOIDCClientStatus prunes the console entry of the authentication status once the
+// authentication type is no longer OIDC, rather than leaving the last OIDC clients reported.
+func (c *oidcSetupController) removeOIDCClientStatus(ctx context.Context, recorder events.Recorder, authnConfig *configv1.Authentication) error {
+	c.authStatusHandler.WithCurrentOIDCClients(nil)
+	reported, err := c.authStatusHandler.HasOIDCClientStatus(authnConfig)
+	if err != nil || !reported {
+		return err
+	}
+	if err := c.authStatusHandler.Remove(ctx, authnConfig); err != nil {
+		return err
+	}
+
+	recorder.Eventf("OIDCClientStatusRemoved", "Authentication type changed from OIDC to %s, removed the console client from the OIDC clients status", authenticationType(authnConfig))
+	return nil
+}
+
 // checkClientConfigStatus checks whether the current client configuration is being currently in use,
 // by looking at the deployment status. It checks whether the deployment is available and updated,
-// and also whether the resource versions for the oauth secret and server CA trust configmap match
-// the deployment.
-func (c *oidcSetupController) checkClientConfigStatus(authnConfig *configv1.Authentication, clientSecret *corev1.Secret) (bool, string, error) {
+// whether the versions of the oauth secret and server CA trust configmap match the
+// deployment, and whether the console config of the deployment lists every OIDC provider.
+// A public client has no client secret to check.
+func (c *oidcSetupController) checkClientConfigStatus(providerClients []utilsub.OIDCProviderClient, clientSecret *corev1.Secret) (bool, string, error) {
 	depl, err := c.targetNSDeploymentsLister.Deployments(api.OpenShiftConsoleNamespace).Get(api.OpenShiftConsoleDeploymentName)
 	if err != nil {
 		return false, "", err
@@ -230,39 +389,187 @@ func (c *oidcSetupController) checkClientConfigStatus(authnConfig *configv1.Auth
 		return false, "deployment unavailable or outdated", nil
 	}
 
-	if clientSecret.GetResourceVersion() != depl.ObjectMeta.Annotations["console.openshift.io/oauth-secret-version"] {
+	if clientSecret != nil && deploymentsub.SecretVersion(clientSecret) != depl.ObjectMeta.Annotations["console.openshift.io/oauth-secret-version"] {
 		return false, "client secret version not up to date in current deployment", nil
 	}
 
-	if len(authnConfig.Spec.OIDCProviders) > 0 {
-		serverCAConfigName := authnConfig.Spec.OIDCProviders[0].Issuer.CertificateAuthority.Name
-		if len(serverCAConfigName) == 0 {
-			return deplAvailableUpdated, "", nil
-		}
+	if valid, msg, err := c.checkProvidersStatus(depl, providerClients); err != nil || !valid {
+		return false, msg, err
+	}
 
-		serverCAConfig, err := c.targetNSConfigMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(serverCAConfigName)
-		if err != nil {
-			return false, "", err
-		}
+	return deplAvailableUpdated, "", nil
+}
 
-		if serverCAConfig.GetResourceVersion() != depl.ObjectMeta.Annotations["console.openshift.io/authn-ca-trust-config-version"] {
-			return false, "OIDC provider CA version not up to date in current deployment", nil
+// checkMountedClientConfigStatus is the counterpart of checkClientConfigStatus for a client secret
+// mounted from an external secret store. There is no Secret whose resource version could be compared
+// with the deployment, so instead it checks that the deployment refers to the configured
+// SecretProviderClass and that the CSI driver reports the secret as mounted in every updated pod.
+func (c *oidcSetupController) checkMountedClientConfigStatus(ctx context.Context, providerClients []utilsub.OIDCProviderClient, secretProviderClass string) (bool, string, error) {
+	depl, err := c.targetNSDeploymentsLister.Deployments(api.OpenShiftConsoleNamespace).Get(api.OpenShiftConsoleDeploymentName)
+	if err != nil {
+		return false, "", err
+	}
+
+	if secretProviderClass != depl.ObjectMeta.Annotations[deploymentsub.OIDCSecretProviderClassAnnotation] {
+		return false, "client secret provider class not up to date in current deployment", nil
+	}
+
+	podStatuses, err := c.dynamicClient.Resource(secretProviderClassPodStatusGVR).Namespace(api.OpenShiftConsoleNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return false, "", fmt.Errorf("failed to list secret provider class pod statuses: %w", err)
+	}
+
+	// the canary and green consoles mount the same SecretProviderClass, only the pods selected by
+	// the console deployment count
+	selector, err := metav1.LabelSelectorAsSelector(depl.Spec.Selector)
+	if err != nil {
+		return false, "", err
+	}
+	pods, err := c.targetNSPodsLister.Pods(api.OpenShiftConsoleNamespace).List(selector)
+	if err != nil {
+		return false, "", err
+	}
+
+	if mounted := countMountedPods(podStatuses.Items, secretProviderClass, pods); int32(mounted) < depl.Status.UpdatedReplicas {
+		return false, fmt.Sprintf("client secret mounted in %d of %d console pods", mounted, depl.Status.UpdatedReplicas), nil
+	}
+
+	// the CA trust configmap and the console config are still regular resources, reuse the resource version checks
+	if valid, msg, err := c.checkProvidersStatus(depl, providerClients); err != nil || !valid {
+		return false, msg, err
+	}
+
+	return deploymentsub.IsAvailableAndUpdated(depl), "", nil
+}
+
+// checkProvidersStatus checks that the console config the deployment runs with lists the issuer
+// of every OIDC provider, and that the deployment trusts the current merged OIDC CA trust.
+func (c *oidcSetupController) checkProvidersStatus(depl *appsv1.Deployment, providerClients []utilsub.OIDCProviderClient) (bool, string, error) {
+	consoleConfigMap, err := c.targetNSConfigMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(api.OpenShiftConsoleConfigMapName)
+	if err != nil {
+		return false, "", err
+	}
+	if deploymentsub.ConfigMapVersion(consoleConfigMap) != depl.ObjectMeta.Annotations["console.openshift.io/console-config-version"] {
+		return false, "console config version not up to date in current deployment", nil
+	}
+	if missing, staleClaimMappings, err := missingIssuers(consoleConfigMap, providerClients); err != nil {
+		return false, "", err
+	} else if len(missing) > 0 {
+		return false, fmt.Sprintf("OIDC providers not in the current console config: %s", strings.Join(missing, ", ")), nil
+	} else if len(staleClaimMappings) > 0 {
+		// the console impersonates and reviews access as the prefixed users and groups
+		return false, fmt.Sprintf("claim mappings of OIDC providers not in the current console config, users and groups are not prefixed as by the API server: %s", strings.Join(staleClaimMappings, ", ")), nil
+	}
+
+	// the CA of the primary provider is merged with the other trusted CAs into a single configmap
+	serverCAConfig, err := c.targetNSConfigMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(api.OIDCCATrustConfigMapName)
+	if apierrors.IsNotFound(err) {
+		return true, "", nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	if deploymentsub.CATrustVersion(serverCAConfig) != depl.ObjectMeta.Annotations["console.openshift.io/authn-ca-trust-config-version"] {
+		return false, "OIDC provider CA version not up to date in current deployment", nil
+	}
+	return true, "", nil
+}
+
+// missingIssuers returns the names of the OIDC providers whose issuer, with the endpoint it is
+// reached at, and console client, with its extra scopes, are not in the console config, and the
+// names of the providers whose claim mappings, with the username and groups prefixes, are not.
+func missingIssuers(consoleConfigMap *corev1.ConfigMap, providerClients []utilsub.OIDCProviderClient) ([]string, []string, error) {
+	var consoleConfig consoleserver.Config
+	if err := yaml.Unmarshal([]byte(consoleConfigMap.Data["console-config.yaml"]), &consoleConfig); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse console-config.yaml: %w", err)
+	}
+	configured := map[string]consoleserver.OIDCProvider{
+		consoleConfig.Auth.OIDCIssuer: {
+			Issuer:        consoleConfig.Auth.OIDCIssuer,
+			ClientID:      consoleConfig.Auth.ClientID,
+			ExtraScopes:   consoleConfig.Auth.OIDCExtraScopes,
+			ClaimMappings: consoleConfig.Auth.OIDCClaimMappings,
+			Endpoint:      consoleConfig.Auth.OIDCIssuerEndpoint,
+		},
+	}
+	for _, provider := range consoleConfig.Auth.OIDCProviders {
+		provider.Name = ""
+		configured[provider.Issuer] = provider
+	}
+	missing, staleClaimMappings := []string{}, []string{}
+	for _, providerClient := range providerClients {
+		expected := consoleserver.OIDCProvider{
+			Issuer:      providerClient.Provider.Issuer.URL,
+			ClientID:    providerClient.Client.ClientID,
+			ExtraScopes: providerClient.Client.ExtraScopes,
+			Endpoint:    providerClient.Endpoint,
+		}
+		provider, ok := configured[expected.Issuer]
+		claimMappings := provider.ClaimMappings
+		provider.ClaimMappings = nil
+		// the login command hints do not change the clients the console logs in with
+		provider.Audiences, provider.CLIClientID = nil, ""
+		switch {
+		case !ok || !equality.Semantic.DeepEqual(provider, expected):
+			missing = append(missing, providerClient.Provider.Name)
+		case !equality.Semantic.DeepEqual(claimMappings, consoleserver.GetOIDCClaimMappings(providerClient.Provider)):
+			staleClaimMappings = append(staleClaimMappings, providerClient.Provider.Name)
 		}
 	}
+	return missing, staleClaimMappings, nil
+}
 
-	return deplAvailableUpdated, "", nil
+// authenticationType returns the authentication type of the cluster, IntegratedOAuth if unset
+func authenticationType(authnConfig *configv1.Authentication) configv1.AuthenticationType {
+	if len(authnConfig.Spec.Type) == 0 {
+		return configv1.AuthenticationTypeIntegratedOAuth
+	}
+	return authnConfig.Spec.Type
+}
+
+// describeOIDCClients lists the issuer and client ID of the console client of every OIDC provider
+func describeOIDCClients(authnConfig *configv1.Authentication) string {
+	providerClients := utilsub.GetOIDCProviderClients(authnConfig)
+	if len(providerClients) == 0 {
+		return "no console client configured"
+	}
+	clients := []string{}
+	for _, providerClient := range providerClients {
+		clients = append(clients, fmt.Sprintf("issuer %s client %q", providerClient.Provider.Issuer.URL, providerClient.Client.ClientID))
+	}
+	return strings.Join(clients, ", ")
+}
+
+// countMountedPods counts the console pods in which the CSI driver mounted at least one object
+// from the given SecretProviderClass
+func countMountedPods(podStatuses []unstructured.Unstructured, secretProviderClass string, consolePods []*corev1.Pod) int {
+	isConsolePod := map[string]bool{}
+	for _, pod := range consolePods {
+		isConsolePod[pod.Name] = true
+	}
+	mounted := 0
+	for _, podStatus := range podStatuses {
+		className, _, _ := unstructured.NestedString(podStatus.Object, "status", "secretProviderClassName")
+		podName, _, _ := unstructured.NestedString(podStatus.Object, "status", "podName")
+		isMounted, _, _ := unstructured.NestedBool(podStatus.Object, "status", "mounted")
+		objects, _, _ := unstructured.NestedSlice(podStatus.Object, "status", "objects")
+		if className == secretProviderClass && isConsolePod[podName] && isMounted && len(objects) > 0 {
+			mounted++
+		}
+	}
+	return mounted
 }
 
 // handleStatus returns whether sync should happen and any error encountering
 // determining the operator's management state
 // TODO: extract this logic to where it can be used for all controllers
 func (c *oidcSetupController) handleManaged(ctx context.Context) (bool, error) {
-	operatorSpec, _, _, err := c.operatorClient.GetOperatorState()
+	operatorSpec, operatorStatus, _, err := c.operatorClient.GetOperatorState()
 	if err != nil {
 		return false, fmt.Errorf("failed to retrieve operator config: %w", err)
 	}
 
-	switch managementState := operatorSpec.ManagementState; managementState {
+	switch managementState := consolecapability.OperatorManagementState(operatorSpec, operatorStatus); managementState {
 	case operatorv1.Managed:
 		klog.V(4).Infoln("console is in a managed state.")
 		return true, nil
@@ -276,28 +583,3 @@ func (c *oidcSetupController) handleManaged(ctx context.Context) (bool, error) {
 		return false, fmt.Errorf("console is in an unknown state: %v", managementState)
 	}
 }
-
-func authnConfigHasOIDCFields(crdLister apiexensionsv1listers.CustomResourceDefinitionLister) (bool, error) {
-	authnCRD, err := crdLister.Get("authentications.config.openshift.io")
-	if err != nil {
-		return false, err
-	}
-
-	var authnV1Config *apiexensionsv1.CustomResourceDefinitionVersion
-	for _, version := range authnCRD.Spec.Versions {
-		if version.Name == "v1" && version.Served && version.Storage {
-			authnV1Config = &version
-			break
-		}
-	}
-
-	if authnV1Config == nil {
-		return false, fmt.Errorf("authentications.config.openshift.io is not served or stored as v1")
-	}
-
-	schema := authnV1Config.Schema.OpenAPIV3Schema
-	_, clientsExist := schema.Properties["status"].Properties["oidcClients"]
-
-	return clientsExist, nil
-
-}
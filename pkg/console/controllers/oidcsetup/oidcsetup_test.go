@@ -0,0 +1,323 @@
+package oidcsetup
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+func TestMissingIssuers(t *testing.T) {
+	providerClient := func(name, issuer, clientID string) utilsub.OIDCProviderClient {
+		return utilsub.OIDCProviderClient{
+			Provider: configv1.OIDCProvider{Name: name, Issuer: configv1.TokenIssuer{URL: issuer}},
+			Client:   configv1.OIDCClientConfig{ClientID: clientID},
+		}
+	}
+	providerClients := []utilsub.OIDCProviderClient{
+		providerClient("corporate", "https://sso.example.com", "corporate-id"),
+		providerClient("partners", "https://partners.example.com", "partners-id"),
+	}
+	consoleConfig := func(configYAML string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{Data: map[string]string{"console-config.yaml": configYAML}}
+	}
+
+	tests := []struct {
+		name          string
+		consoleConfig *corev1.ConfigMap
+		want          []string
+		wantStale     []string
+	}{
+		{
+			name: "Test every provider rendered",
+			consoleConfig: consoleConfig(`auth:
+  oidcIssuer: https://sso.example.com
+  clientID: corporate-id
+  oidcProviders:
+  - name: corporate
+    issuer: https://sso.example.com
+    clientID: corporate-id
+  - name: partners
+    issuer: https://partners.example.com
+    clientID: partners-id
+`),
+			want:      []string{},
+			wantStale: []string{},
+		},
+		{
+			name: "Test provider added since the last rollout",
+			consoleConfig: consoleConfig(`auth:
+  oidcIssuer: https://sso.example.com
+  clientID: corporate-id
+`),
+			want:      []string{"partners"},
+			wantStale: []string{},
+		},
+		{
+			name: "Test client ID changed since the last rollout",
+			consoleConfig: consoleConfig(`auth:
+  oidcIssuer: https://sso.example.com
+  clientID: old-id
+  oidcProviders:
+  - name: corporate
+    issuer: https://sso.example.com
+    clientID: old-id
+  - name: partners
+    issuer: https://partners.example.com
+    clientID: partners-id
+`),
+			want:      []string{"corporate"},
+			wantStale: []string{},
+		},
+		{
+			name: "Test claim mappings changed since the last rollout",
+			consoleConfig: consoleConfig(`auth:
+  oidcIssuer: https://sso.example.com
+  clientID: corporate-id
+  oidcProviders:
+  - name: corporate
+    issuer: https://sso.example.com
+    clientID: corporate-id
+  - name: partners
+    issuer: https://partners.example.com
+    clientID: partners-id
+    claimMappings:
+      usernameClaim: email
+`),
+			want:      []string{},
+			wantStale: []string{"partners"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			missing, staleClaimMappings, err := missingIssuers(tt.consoleConfig, providerClients)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(missing, tt.want); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(staleClaimMappings, tt.wantStale); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestCountMountedPods(t *testing.T) {
+	podStatus := func(podName, className string, mounted bool) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]interface{}{
+			"status": map[string]interface{}{
+				"podName":                 podName,
+				"secretProviderClassName": className,
+				"mounted":                 mounted,
+				"objects":                 []interface{}{map[string]interface{}{"id": "secret/client-secret"}},
+			},
+		}}
+	}
+	pod := func(name string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
+	tests := []struct {
+		name        string
+		podStatuses []unstructured.Unstructured
+		consolePods []*corev1.Pod
+		want        int
+	}{
+		{
+			name: "Test console pods with the client secret mounted",
+			podStatuses: []unstructured.Unstructured{
+				podStatus("console-7d9f8-abcde", "console-oidc", true),
+				podStatus("console-7d9f8-fghij", "console-oidc", true),
+			},
+			consolePods: []*corev1.Pod{pod("console-7d9f8-abcde"), pod("console-7d9f8-fghij")},
+			want:        2,
+		},
+		{
+			name: "Test canary and green pods are not console pods",
+			podStatuses: []unstructured.Unstructured{
+				podStatus("console-7d9f8-abcde", "console-oidc", true),
+				podStatus("console-canary-5c6b7-klmno", "console-oidc", true),
+				podStatus("console-green-4a3b2-pqrst", "console-oidc", true),
+			},
+			consolePods: []*corev1.Pod{pod("console-7d9f8-abcde")},
+			want:        1,
+		},
+		{
+			name: "Test console pods of another secret provider class or not mounted",
+			podStatuses: []unstructured.Unstructured{
+				podStatus("console-7d9f8-abcde", "other", true),
+				podStatus("console-7d9f8-fghij", "console-oidc", false),
+			},
+			consolePods: []*corev1.Pod{pod("console-7d9f8-abcde"), pod("console-7d9f8-fghij")},
+			want:        0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(countMountedPods(tt.podStatuses, "console-oidc", tt.consolePods), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestProbeIssuerDiscovery(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		// document is formatted with the URL of the test server
+		document string
+		// issuer advertised by the provider, the test server is its endpoint if set
+		issuerURL  string
+		wantReason string
+	}{
+		{
+			name:       "Test valid discovery document",
+			statusCode: http.StatusOK,
+			document:   `{"issuer": "%s", "authorization_endpoint": "%[1]s/auth", "token_endpoint": "%[1]s/token", "jwks_uri": "%[1]s/keys"}`,
+			wantReason: "",
+		},
+		{
+			name:       "Test discovery document not found",
+			statusCode: http.StatusNotFound,
+			wantReason: "IssuerUnreachable",
+		},
+		{
+			name:       "Test invalid discovery document",
+			statusCode: http.StatusOK,
+			document:   `<html>login</html>`,
+			wantReason: "InvalidIssuerDiscovery",
+		},
+		{
+			name:       "Test discovery document of another issuer",
+			statusCode: http.StatusOK,
+			document:   `{"issuer": "https://other.example.com", "authorization_endpoint": "%s/auth", "token_endpoint": "%[1]s/token", "jwks_uri": "%[1]s/keys"}`,
+			wantReason: "IssuerMismatch",
+		},
+		{
+			name:       "Test discovery document fetched from the issuer endpoint",
+			statusCode: http.StatusOK,
+			document:   `{"issuer": "https://idp.example.com", "authorization_endpoint": "https://idp.example.com/auth", "token_endpoint": "%s/token", "jwks_uri": "%[1]s/keys"}`,
+			issuerURL:  "https://idp.example.com",
+			wantReason: "",
+		},
+		{
+			name:       "Test discovery document fetched from the issuer endpoint of another issuer",
+			statusCode: http.StatusOK,
+			document:   `{"issuer": "%s", "authorization_endpoint": "%[1]s/auth", "token_endpoint": "%[1]s/token", "jwks_uri": "%[1]s/keys"}`,
+			issuerURL:  "https://idp.example.com",
+			wantReason: "IssuerMismatch",
+		},
+		{
+			name:       "Test discovery document without the token endpoints",
+			statusCode: http.StatusOK,
+			document:   `{"issuer": "%s", "authorization_endpoint": "%[1]s/auth"}`,
+			wantReason: "IssuerMissingEndpoints",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var server *httptest.Server
+			server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/.well-known/openid-configuration" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.WriteHeader(tt.statusCode)
+				if strings.Contains(tt.document, "%") {
+					fmt.Fprintf(w, tt.document, server.URL)
+				} else {
+					fmt.Fprint(w, tt.document)
+				}
+			}))
+			defer server.Close()
+
+			issuerURL, endpoint := server.URL, ""
+			if len(tt.issuerURL) > 0 {
+				issuerURL, endpoint = tt.issuerURL, server.URL
+			}
+
+			reason, err := probeIssuerDiscovery(server.Client(), issuerURL, endpoint)
+			if diff := deep.Equal(tt.wantReason, reason); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != (len(tt.wantReason) > 0) {
+				t.Errorf("probeIssuerDiscovery() error = %v, wantReason %q", err, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestRegisterClient(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		response   string
+		want       *clientRegistrationResponse
+		wantErr    bool
+	}{
+		{
+			name:       "Test client registered",
+			statusCode: http.StatusCreated,
+			response:   `{"client_id": "console-id", "client_secret": "s3cr3t", "registration_access_token": "token", "registration_client_uri": "https://sso.example.com/register/console-id"}`,
+			want: &clientRegistrationResponse{
+				ClientID:                "console-id",
+				ClientSecret:            "s3cr3t",
+				RegistrationAccessToken: "token",
+				RegistrationClientURI:   "https://sso.example.com/register/console-id",
+			},
+		},
+		{
+			name:       "Test registration rejected",
+			statusCode: http.StatusBadRequest,
+			response:   `{"error": "invalid_redirect_uri"}`,
+			wantErr:    true,
+		},
+		{
+			name:       "Test registration without a client secret",
+			statusCode: http.StatusCreated,
+			response:   `{"client_id": "console-id"}`,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				request := clientRegistrationRequest{}
+				if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+					t.Error(err)
+				}
+				if diff := deep.Equal([]string{"https://console.example.com/auth/callback"}, request.RedirectURIs); diff != nil {
+					t.Error(diff)
+				}
+				if diff := deep.Equal("Bearer initial-token", r.Header.Get("Authorization")); diff != nil {
+					t.Error(diff)
+				}
+				w.WriteHeader(tt.statusCode)
+				fmt.Fprint(w, tt.response)
+			}))
+			defer server.Close()
+
+			got, err := registerClient(server.Client(), server.URL+"/register", "initial-token", "https://console.example.com/auth/callback")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("registerClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := deep.Equal(tt.want, got); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
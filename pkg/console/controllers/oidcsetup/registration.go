@@ -0,0 +1,177 @@
+package oidcsetup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+
+	customerrors "github.com/openshift/console-operator/pkg/console/errors"
+	secretsub "github.com/openshift/console-operator/pkg/console/subresource/secret"
+	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+const (
+	// initialAccessTokenKey holds the token the OIDC provider authorizes client registrations with
+	initialAccessTokenKey = "initialAccessToken"
+
+	registeredClientIDKey      = "clientID"
+	registrationAccessTokenKey = "registrationAccessToken"
+	registrationClientURIKey   = "registrationClientURI"
+)
+
+// clientRegistrationRequest is the client metadata of the console, as defined by RFC 7591
+type clientRegistrationRequest struct {
+	ClientName              string   `json:"client_name"`
+	RedirectURIs            []string `json:"redirect_uris"`
+	GrantTypes              []string `json:"grant_types"`
+	ResponseTypes           []string `json:"response_types"`
+	TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method"`
+}
+
+// clientRegistrationResponse holds the fields of the client information response the console
+// client is configured with, or managed with later on.
+type clientRegistrationResponse struct {
+	ClientID                string `json:"client_id"`
+	ClientSecret            string `json:"client_secret"`
+	RegistrationAccessToken string `json:"registration_access_token"`
+	RegistrationClientURI   string `json:"registration_client_uri"`
+}
+
+// syncClientRegistration registers a console client with the primary OIDC provider when the
+// authentication config has none and the client registration annotation of the operator config
+// names the openshift-config secret holding the initial access token. The registered client is
+// stored in the console-oidc-registered-client secret and is registered once per issuer. It
+// returns the ID of the registered client, or an empty string if registration is not enabled.
+func (c *oidcSetupController) syncClientRegistration(ctx context.Context, recorder events.Recorder, operatorConfig *operatorv1.Console, authnConfig *configv1.Authentication) (string, string, error) {
+	credentialsName := operatorConfig.Annotations[api.OIDCClientRegistrationAnnotation]
+	provider := utilsub.GetPrimaryOIDCProvider(authnConfig)
+	if len(credentialsName) == 0 || provider == nil {
+		return "", "", nil
+	}
+
+	registered, err := c.targetNSSecretsLister.Secrets(api.TargetNamespace).Get(api.OIDCRegisteredClientSecretName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "", "FailedGet", err
+	}
+	if clientID := registeredClientID(registered, provider.Issuer.URL); len(clientID) > 0 {
+		return clientID, "", nil
+	}
+
+	credentials, err := c.configSecretsLister.Secrets(api.OpenShiftConfigNamespace).Get(credentialsName)
+	if err != nil {
+		return "", "OIDCClientRegistrationCredentials", err
+	}
+
+	consoleConfig, err := c.consoleConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return "", "FailedGetConsoleConfig", err
+	}
+	// the redirect URI is not known until the console route is admitted
+	consoleURL := consoleConfig.Status.ConsoleURL
+	if len(consoleURL) == 0 {
+		return "", "OIDCClientRegistrationPending", customerrors.NewSyncError("waiting for the console URL to register the OIDC client")
+	}
+
+	client, reason, err := c.issuerClient()
+	if err != nil {
+		return "", reason, err
+	}
+	document, reason, err := getDiscoveryDocument(client, provider.Issuer.URL)
+	if err != nil {
+		return "", reason, fmt.Errorf("OIDC provider %q: %w", provider.Name, err)
+	}
+	if len(document.RegistrationEndpoint) == 0 {
+		return "", "OIDCClientRegistrationUnsupported", fmt.Errorf("OIDC provider %q does not list a registration_endpoint", provider.Name)
+	}
+
+	redirectURI := strings.TrimSuffix(consoleURL, "/") + "/auth/callback"
+	response, err := registerClient(client, document.RegistrationEndpoint, string(credentials.Data[initialAccessTokenKey]), redirectURI)
+	if err != nil {
+		return "", "OIDCClientRegistrationFailed", fmt.Errorf("OIDC provider %q: %w", provider.Name, err)
+	}
+
+	if _, _, err := resourceapply.ApplySecret(ctx, c.secretsClient, recorder, registeredClientSecret(operatorConfig, provider.Issuer.URL, response)); err != nil {
+		return "", "FailedApply", err
+	}
+	recorder.Eventf("OIDCClientRegistered", "Registered the console client %q with OIDC provider %q", response.ClientID, provider.Name)
+	return response.ClientID, "", nil
+}
+
+// registerClient posts the console client metadata to the registration endpoint, authorized with
+// the initial access token if there is one.
+func registerClient(client *http.Client, registrationEndpoint, initialAccessToken, redirectURI string) (*clientRegistrationResponse, error) {
+	body, err := json.Marshal(clientRegistrationRequest{
+		ClientName:              "OpenShift console",
+		RedirectURIs:            []string{redirectURI},
+		GrantTypes:              []string{"authorization_code", "refresh_token"},
+		ResponseTypes:           []string{"code"},
+		TokenEndpointAuthMethod: "client_secret_basic",
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, registrationEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if len(initialAccessToken) > 0 {
+		req.Header.Set("Authorization", "Bearer "+initialAccessToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to POST %s: %v", registrationEndpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		// the error response carries the reason of the rejection, eg. invalid_redirect_uri
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%s returns '%s': %s", registrationEndpoint, resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	response := &clientRegistrationResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return nil, fmt.Errorf("failed to decode the response of %s: %v", registrationEndpoint, err)
+	}
+	if len(response.ClientID) == 0 || len(response.ClientSecret) == 0 {
+		return nil, fmt.Errorf("%s returned no client_id or client_secret", registrationEndpoint)
+	}
+	return response, nil
+}
+
+// registeredClientID returns the ID of the client stored in secret if it was registered with
+// the given issuer.
+func registeredClientID(secret *corev1.Secret, issuerURL string) string {
+	if secret == nil || secret.Annotations[api.OIDCRegisteredIssuerAnnotation] != issuerURL {
+		return ""
+	}
+	return string(secret.Data[registeredClientIDKey])
+}
+
+func registeredClientSecret(operatorConfig *operatorv1.Console, issuerURL string, response *clientRegistrationResponse) *corev1.Secret {
+	secret := secretsub.DefaultSecret(operatorConfig, response.ClientSecret)
+	secret.Name = api.OIDCRegisteredClientSecretName
+	secret.Annotations = map[string]string{
+		api.OIDCRegisteredIssuerAnnotation: issuerURL,
+	}
+	secret.Data[registeredClientIDKey] = []byte(response.ClientID)
+	secret.Data[registrationAccessTokenKey] = []byte(response.RegistrationAccessToken)
+	secret.Data[registrationClientURIKey] = []byte(response.RegistrationClientURI)
+	return secret
+}
@@ -0,0 +1,196 @@
+package oidcsetup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	appsv1informers "k8s.io/client-go/informers/apps/v1"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	customerrors "github.com/openshift/console-operator/pkg/console/errors"
+	"github.com/openshift/console-operator/pkg/console/status"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
+	secretsub "github.com/openshift/console-operator/pkg/console/subresource/secret"
+	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+// oidcClientSecretRotationController follows the rotation of the client secret of the console
+// OIDC client. The next client secret is staged under the nextClientSecret key of the secret
+// referenced by the client config, once the OIDC provider accepts both the current and the next
+// one. When the rotation is due, right away or at the time of the rotation annotation, the
+// OAuthClientSecretController copies the next client secret into console-oauth-config and the
+// console is rolled out with it, the pods still running with the current one keep working until
+// they are replaced. The rollout is reported until every console pod runs with the next client
+// secret, the current one can then be revoked with the OIDC provider and replaced in the secret.
+//
+//	writes:
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=OIDCClientSecretRotationProgressing
+//		- type=OIDCClientSecretRotationDegraded
+type oidcClientSecretRotationController struct {
+	operatorClient v1helpers.OperatorClient
+
+	authnLister               configv1listers.AuthenticationLister
+	consoleOperatorLister     operatorv1listers.ConsoleLister
+	configSecretsLister       corev1listers.SecretLister
+	targetNSSecretsLister     corev1listers.SecretLister
+	targetNSDeploymentsLister appsv1listers.DeploymentLister
+
+	// rotated is the next client secret version whose rollout was last reported complete
+	rotated string
+}
+
+func NewOIDCClientSecretRotationController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	// informers
+	authnInformer configv1informers.AuthenticationInformer,
+	consoleOperatorInformer operatorv1informers.ConsoleInformer,
+	configSecretsInformer corev1informers.SecretInformer,
+	targetNSSecretsInformer corev1informers.SecretInformer,
+	targetNSDeploymentsInformer appsv1informers.DeploymentInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	c := &oidcClientSecretRotationController{
+		operatorClient: operatorClient,
+
+		authnLister:               authnInformer.Lister(),
+		consoleOperatorLister:     consoleOperatorInformer.Lister(),
+		configSecretsLister:       configSecretsInformer.Lister(),
+		targetNSSecretsLister:     targetNSSecretsInformer.Lister(),
+		targetNSDeploymentsLister: targetNSDeploymentsInformer.Lister(),
+	}
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			authnInformer.Informer(),
+			consoleOperatorInformer.Informer(),
+		).WithFilteredEventsInformers( // referenced client secret
+		util.OIDCClientSecretFilter(c.authnLister),
+		configSecretsInformer.Informer(),
+	).WithFilteredEventsInformers( // console-oauth-config
+		util.IncludeNamesFilter(deploymentsub.ConsoleOauthConfigName),
+		targetNSSecretsInformer.Informer(),
+	).WithFilteredEventsInformers( // console deployment
+		util.IncludeNamesFilter(api.OpenShiftConsoleDeploymentName),
+		targetNSDeploymentsInformer.Informer(),
+	).ResyncEvery(time.Minute).WithSync(c.sync).
+		ToController("OIDCClientSecretRotationController", recorder.WithComponentSuffix("oidc-client-secret-rotation-controller"))
+}
+
+func (c *oidcClientSecretRotationController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	operatorConfig, err := c.consoleOperatorLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch consolecapability.ManagementState(operatorConfig) {
+	case operatorv1.Managed:
+		klog.V(4).Infoln("console is in a managed state: checking the OIDC client secret rotation")
+	case operatorv1.Unmanaged:
+		klog.V(4).Infoln("console is in an unmanaged state: skipping the OIDC client secret rotation")
+		return nil
+	case operatorv1.Removed:
+		klog.V(4).Infoln("console has been removed: skipping the OIDC client secret rotation")
+		return nil
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	reason, err := c.checkRotation(operatorConfig, syncCtx.Recorder())
+	statusHandler.AddConditions(status.HandleProgressingOrDegraded("OIDCClientSecretRotation", reason, err))
+	return statusHandler.FlushAndReturn(nil)
+}
+
+// checkRotation returns a sync error while the console is rolled out with the next client secret,
+// any other error is reported as degraded.
+func (c *oidcClientSecretRotationController) checkRotation(operatorConfig *operatorv1.Console, recorder events.Recorder) (string, error) {
+	authnConfig, err := c.authnLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return "FailedGetAuthConfig", err
+	}
+	clientConfig := utilsub.GetOIDCClientConfig(authnConfig)
+	// a client secret mounted from an external secret store is rotated by the store
+	if authnConfig.Spec.Type != configv1.AuthenticationTypeOIDC || clientConfig == nil || len(clientConfig.ClientSecret.Name) == 0 ||
+		len(utilsub.GetOIDCSecretProviderClass(operatorConfig, authnConfig)) > 0 {
+		return "", nil
+	}
+
+	configSecret, err := c.configSecretsLister.Secrets(api.OpenShiftConfigNamespace).Get(clientConfig.ClientSecret.Name)
+	if apierrors.IsNotFound(err) {
+		// the OAuthClientSecretController reports the missing secret
+		return "", nil
+	}
+	if err != nil {
+		return "FailedClientSecretGet", err
+	}
+
+	rotation, err := secretsub.GetOIDCClientSecretRotation(operatorConfig, configSecret, time.Now())
+	if err != nil {
+		return "InvalidSchedule", err
+	}
+	if !rotation.Staged {
+		return "", nil
+	}
+	if !rotation.Due {
+		klog.V(2).Infof("rotation of the OIDC client secret %s scheduled at %s", configSecret.Name, rotation.At.Format(time.RFC3339))
+		return "", nil
+	}
+
+	consoleSecret, err := c.targetNSSecretsLister.Secrets(api.TargetNamespace).Get(deploymentsub.ConsoleOauthConfigName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "FailedGet", err
+	}
+	deployment, err := c.targetNSDeploymentsLister.Deployments(api.OpenShiftConsoleNamespace).Get(api.OpenShiftConsoleDeploymentName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "FailedGet", err
+	}
+	if message := rolloutProgress(configSecret, consoleSecret, deployment); len(message) > 0 {
+		return "RollingOut", customerrors.NewSyncError(message)
+	}
+
+	rotated := fmt.Sprintf("%s/%s", configSecret.Name, configSecret.ResourceVersion)
+	if c.rotated != rotated {
+		recorder.Eventf("OIDCClientSecretRotated", "the console runs with the next client secret of %s/%s, revoke the previous one with the OIDC provider and move %s to %s",
+			api.OpenShiftConfigNamespace, configSecret.Name, secretsub.NextClientSecretKey, secretsub.ClientSecretKey)
+		c.rotated = rotated
+	}
+	return "", nil
+}
+
+// rolloutProgress returns why the console does not run with the next client secret of configSecret
+// yet, or an empty string once every pod of the deployment was rolled out with it.
+func rolloutProgress(configSecret, consoleSecret *corev1.Secret, deployment *appsv1.Deployment) string {
+	if consoleSecret == nil || secretsub.GetSecretString(consoleSecret) != string(configSecret.Data[secretsub.NextClientSecretKey]) {
+		return fmt.Sprintf("waiting for the next client secret of %s/%s to be synced", api.OpenShiftConfigNamespace, configSecret.Name)
+	}
+	if deployment == nil || deploymentsub.SecretVersion(consoleSecret) != deployment.ObjectMeta.Annotations["console.openshift.io/oauth-secret-version"] {
+		return "waiting for the console deployment to be updated with the next client secret"
+	}
+	if !deploymentsub.IsAvailableAndUpdated(deployment) {
+		return "waiting for the console pods to be rolled out with the next client secret"
+	}
+	return ""
+}
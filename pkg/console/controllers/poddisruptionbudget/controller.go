@@ -9,12 +9,17 @@ import (
 	v1 "k8s.io/api/policy/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	coreinformersv1 "k8s.io/client-go/informers/core/v1"
 	policyv1 "k8s.io/client-go/informers/policy/v1"
 	policyv1client "k8s.io/client-go/kubernetes/typed/policy/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 
 	// openshift
 	operatorsv1 "github.com/openshift/api/operator/v1"
+	configinformer "github.com/openshift/client-go/config/informers/externalversions"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
 	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
 	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
 	"github.com/openshift/console-operator/bindata"
@@ -26,15 +31,31 @@ import (
 
 	// console-operator
 	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
 	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
 
 	"github.com/openshift/library-go/pkg/operator/events"
 )
 
+// PodDisruptionBudgetController syncs the console or the downloads PodDisruptionBudget. The budget
+// of the console one is set by the pod-disruption-budget annotation of the operator config, and
+// relaxed on single node and compact clusters when it would keep node drains from evicting the
+// console pods.
+//
+//	writes:
+//	- poddisruptionbudgets.policy/console -n openshift-console
+//	- poddisruptionbudgets.policy/downloads -n openshift-console
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=PodDisruptionBudgetDegraded
+//		- type=PDBSyncProgressing
+//		- type=PDBSyncDegraded
 type PodDisruptionBudgetController struct {
 	pdbName              string
 	operatorClient       v1helpers.OperatorClient
 	operatorConfigLister operatorv1listers.ConsoleLister
+	infrastructureLister configlistersv1.InfrastructureLister
+	nodeLister           corev1listers.NodeLister
 	pdbClient            policyv1client.PodDisruptionBudgetsGetter
 }
 
@@ -46,15 +67,20 @@ func NewPodDisruptionBudgetController(
 	operatorConfigInformer operatorv1informers.ConsoleInformer,
 	pdbClient policyv1client.PodDisruptionBudgetsGetter,
 	// informer
+	configInformer configinformer.SharedInformerFactory,
 	pdbInformer policyv1.PodDisruptionBudgetInformer,
+	nodeInformer coreinformersv1.NodeInformer,
 	//events
 	recorder events.Recorder,
 ) factory.Controller {
+	infrastructureInformer := configInformer.Config().V1().Infrastructures()
 
 	ctrl := &PodDisruptionBudgetController{
 		pdbName:              pdbName,
 		operatorClient:       operatorClient,
 		operatorConfigLister: operatorConfigInformer.Lister(),
+		infrastructureLister: infrastructureInformer.Lister(),
+		nodeLister:           nodeInformer.Lister(),
 		pdbClient:            pdbClient,
 	}
 
@@ -66,7 +92,9 @@ func NewPodDisruptionBudgetController(
 		WithFilteredEventsInformers( // configs
 			util.IncludeNamesFilter(api.ConfigResourceName),
 			operatorConfigInformer.Informer(),
+			infrastructureInformer.Informer(),
 		).
+		WithInformers(nodeInformer.Informer()).
 		ResyncEvery(time.Minute).WithSync(ctrl.Sync).
 		ToController("PodDisruptionBudgetController", recorder.WithComponentSuffix(fmt.Sprintf("%s-pdb-controller", pdbName)))
 }
@@ -78,7 +106,7 @@ func (c *PodDisruptionBudgetController) Sync(ctx context.Context, controllerCont
 	}
 	updatedOperatorConfig := operatorConfig.DeepCopy()
 
-	switch updatedOperatorConfig.Spec.ManagementState {
+	switch consolecapability.ManagementState(updatedOperatorConfig) {
 	case operatorsv1.Managed:
 		klog.V(4).Infof("console-operator is in a managed state: syncing %q pdb", c.pdbName)
 	case operatorsv1.Unmanaged:
@@ -97,7 +125,26 @@ func (c *PodDisruptionBudgetController) Sync(ctx context.Context, controllerCont
 	statusHandler := status.NewStatusHandler(c.operatorClient)
 
 	requiredPDB := c.getDefaultPodDisruptionBudget()
-	_, _, pdbErr := resourceapply.ApplyPodDisruptionBudget(ctx, c.pdbClient, controllerContext.Recorder(), requiredPDB)
+	relaxed := false
+	if c.pdbName == api.OpenShiftConsoleName {
+		infrastructureConfig, err := c.infrastructureLister.Get(api.ConfigResourceName)
+		if err != nil {
+			return err
+		}
+		nodes, err := c.nodeLister.List(labels.Everything())
+		if err != nil {
+			return err
+		}
+		// an invalid budget keeps the default one
+		budget, budgetErr := deploymentsub.GetDisruptionBudget(updatedOperatorConfig, infrastructureConfig, nodes)
+		statusHandler.AddCondition(status.HandleDegraded("PodDisruptionBudget", "InvalidPodDisruptionBudget", budgetErr))
+		deploymentsub.WithDisruptionBudget(requiredPDB, budget)
+		relaxed = budget.Relaxed
+	}
+	_, modified, pdbErr := resourceapply.ApplyPodDisruptionBudget(ctx, c.pdbClient, controllerContext.Recorder(), requiredPDB)
+	if pdbErr == nil && modified && relaxed {
+		controllerContext.Recorder().Warningf("PodDisruptionBudgetRelaxed", "%s would keep node drains from evicting the console pods, a single console pod may be unavailable at a time", api.PodDisruptionBudgetAnnotation)
+	}
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded("PDBSync", "FailedApply", pdbErr))
 	if pdbErr != nil {
 		return statusHandler.FlushAndReturn(pdbErr)
@@ -0,0 +1,297 @@
+package preupgrade
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	// kube
+	"github.com/blang/semver"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	configv1 "github.com/openshift/api/config/v1"
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	configinformer "github.com/openshift/client-go/config/informers/externalversions"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	consoleinformersv1 "github.com/openshift/client-go/console/informers/externalversions/console/v1"
+	consolelistersv1 "github.com/openshift/client-go/console/listers/console/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
+	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+const preUpgradeChecksPassedCondition = "PreUpgradeChecksPassed"
+
+// the oidc-setup controller reports these as progressing while the console rolls out a change
+// of the cluster authentication type
+var authMigrationConditions = []string{
+	"OIDCClientConfig" + operatorsv1.OperatorStatusTypeProgressing,
+	"AuthStatusHandler" + operatorsv1.OperatorStatusTypeProgressing,
+}
+
+// PreUpgradeChecksController asserts that the console is ready for a cluster upgrade: the custom
+// route certificates stay valid past the upgrade window, the enabled plugins support the target
+// console version and no authentication migration is pending. A failed check blocks minor
+// upgrades through Upgradeable.
+//
+//	writes:
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=PreUpgradeChecksUpgradeable
+//		- type=PreUpgradeChecksPassed
+type PreUpgradeChecksController struct {
+	operatorClient       v1helpers.OperatorClient
+	operatorConfigLister operatorv1listers.ConsoleLister
+	ingressConfigLister  configlistersv1.IngressLister
+	clusterVersionLister configlistersv1.ClusterVersionLister
+	configNSSecretLister corev1listers.SecretLister
+	consolePluginLister  consolelistersv1.ConsolePluginLister
+}
+
+func NewPreUpgradeChecksController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	// informers
+	configInformer configinformer.SharedInformerFactory,
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	configNSSecretInformer corev1informers.SecretInformer,
+	consolePluginInformer consoleinformersv1.ConsolePluginInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	configV1Informers := configInformer.Config().V1()
+
+	ctrl := &PreUpgradeChecksController{
+		operatorClient:       operatorClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		ingressConfigLister:  configV1Informers.Ingresses().Lister(),
+		clusterVersionLister: configV1Informers.ClusterVersions().Lister(),
+		configNSSecretLister: configNSSecretInformer.Lister(),
+		consolePluginLister:  consolePluginInformer.Lister(),
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+			configV1Informers.Ingresses().Informer(),
+		).WithFilteredEventsInformers( // cluster version
+		util.IncludeNamesFilter(api.VersionResourceName),
+		configV1Informers.ClusterVersions().Informer(),
+	).WithInformers(
+		configNSSecretInformer.Informer(),
+		consolePluginInformer.Informer(),
+	).ResyncEvery(10*time.Minute).WithSync(ctrl.Sync).
+		ToController("PreUpgradeChecksController", recorder.WithComponentSuffix("pre-upgrade-checks-controller"))
+}
+
+func (c *PreUpgradeChecksController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch consolecapability.ManagementState(operatorConfig) {
+	case operatorsv1.Managed:
+		klog.V(4).Infoln("console-operator is in a managed state: running pre-upgrade checks")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infoln("console-operator is in an unmanaged state: skipping pre-upgrade checks")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infoln("console-operator is in a removed state: skipping pre-upgrade checks")
+		return nil
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	reason, checksErr := c.runChecks(operatorConfig)
+	statusHandler.AddCondition(status.HandleUpgradable("PreUpgradeChecks", reason, checksErr))
+	statusHandler.AddCondition(handlePassed(reason, checksErr))
+	return statusHandler.FlushAndReturn(nil)
+}
+
+func (c *PreUpgradeChecksController) runChecks(operatorConfig *operatorsv1.Console) (string, error) {
+	ingressConfig, err := c.ingressConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return "FailedGetIngressConfig", err
+	}
+	clusterVersion, err := c.clusterVersionLister.Get(api.VersionResourceName)
+	if err != nil {
+		return "FailedGetClusterVersion", err
+	}
+	_, operatorStatus, _, err := c.operatorClient.GetOperatorState()
+	if err != nil {
+		return "FailedGetOperatorStatus", err
+	}
+
+	reasons := []string{}
+	problems := []string{}
+
+	deadline := time.Now().Add(utilsub.GetPreUpgradeWindow(operatorConfig))
+	certProblems := []string{}
+	for _, secretName := range customRouteSecretNames(operatorConfig, ingressConfig) {
+		secret, err := c.configNSSecretLister.Secrets(api.OpenShiftConfigNamespace).Get(secretName)
+		if apierrors.IsNotFound(err) {
+			// a missing secret already degrades the route sync
+			continue
+		}
+		if err != nil {
+			return "FailedGetCustomTLSSecret", err
+		}
+		if problem := certificateExpiryProblem(secret, deadline); len(problem) > 0 {
+			certProblems = append(certProblems, problem)
+		}
+	}
+	if len(certProblems) > 0 {
+		reasons = append(reasons, "CertificateExpiresDuringUpgrade")
+		problems = append(problems, certProblems...)
+	}
+
+	target, err := targetConsoleVersion(clusterVersion)
+	if err != nil {
+		return "FailedGetTargetVersion", err
+	}
+	plugins := []*consolev1.ConsolePlugin{}
+	for _, name := range operatorConfig.Spec.Plugins {
+		plugin, err := c.consolePluginLister.Get(name)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "FailedGetConsolePlugin", err
+		}
+		plugins = append(plugins, plugin)
+	}
+	if pluginProblems := incompatiblePlugins(plugins, target); len(pluginProblems) > 0 {
+		reasons = append(reasons, "IncompatiblePlugins")
+		problems = append(problems, pluginProblems...)
+	}
+
+	if problem := authMigrationProblem(operatorStatus.Conditions); len(problem) > 0 {
+		reasons = append(reasons, "AuthMigrationPending")
+		problems = append(problems, problem)
+	}
+
+	if len(problems) > 0 {
+		return strings.Join(reasons, "And"), fmt.Errorf("pre-upgrade checks failed: %s", strings.Join(problems, "; "))
+	}
+	return "", nil
+}
+
+// customRouteSecretNames returns the openshift-config secrets holding the serving certificates of
+// the console and downloads routes.
+func customRouteSecretNames(operatorConfig *operatorsv1.Console, ingressConfig *configv1.Ingress) []string {
+	names := sets.NewString()
+	for _, routeName := range []string{api.OpenShiftConsoleRouteName, api.OpenShiftConsoleDownloadsRouteName} {
+		routeConfig := routesub.NewRouteConfig(operatorConfig, ingressConfig, routeName)
+		if routeConfig.IsCustomTLSSecretSet() {
+			names.Insert(routeConfig.GetCustomTLSSecretName())
+		}
+		if routeConfig.IsDefaultTLSSecretSet() {
+			names.Insert(routeConfig.GetDefaultTLSSecretName())
+		}
+	}
+	return names.List()
+}
+
+// certificateExpiryProblem reports a serving certificate expiring before deadline. A certificate
+// that cannot be parsed is left to the route sync, which rejects it.
+func certificateExpiryProblem(secret *corev1.Secret, deadline time.Time) string {
+	block, _ := pem.Decode(secret.Data[corev1.TLSCertKey])
+	if block == nil {
+		return ""
+	}
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return ""
+	}
+	if certificate.NotAfter.Before(deadline) {
+		return fmt.Sprintf("the certificate in secret %s/%s expires at %s, within the upgrade window", secret.Namespace, secret.Name, certificate.NotAfter.UTC().Format(time.RFC3339))
+	}
+	return ""
+}
+
+// targetConsoleVersion returns the version of the requested update, or the next minor version
+// since Upgradeable only gates minor updates.
+func targetConsoleVersion(clusterVersion *configv1.ClusterVersion) (semver.Version, error) {
+	desired := clusterVersion.Status.Desired.Version
+	if update := clusterVersion.Spec.DesiredUpdate; update != nil && len(update.Version) > 0 && update.Version != desired {
+		return semver.ParseTolerant(update.Version)
+	}
+	current, err := semver.ParseTolerant(desired)
+	if err != nil {
+		return semver.Version{}, err
+	}
+	return semver.Version{Major: current.Major, Minor: current.Minor + 1}, nil
+}
+
+// incompatiblePlugins reports the plugins whose console-version-range annotation, such as
+// ">=4.14.0 <4.17.0", excludes target. Plugins without the annotation are assumed compatible.
+func incompatiblePlugins(plugins []*consolev1.ConsolePlugin, target semver.Version) []string {
+	// pre-releases and builds of the target are compatible wherever the release is
+	target = semver.Version{Major: target.Major, Minor: target.Minor, Patch: target.Patch}
+	problems := []string{}
+	for _, plugin := range plugins {
+		versionRange, ok := plugin.Annotations[api.PluginConsoleVersionAnnotation]
+		if !ok {
+			continue
+		}
+		compatible, err := semver.ParseRange(versionRange)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("plugin %s has an invalid %s %q", plugin.Name, api.PluginConsoleVersionAnnotation, versionRange))
+			continue
+		}
+		if !compatible(target) {
+			problems = append(problems, fmt.Sprintf("plugin %s supports console versions %s, not %s", plugin.Name, versionRange, target))
+		}
+	}
+	return problems
+}
+
+func authMigrationProblem(conditions []operatorsv1.OperatorCondition) string {
+	for _, conditionType := range authMigrationConditions {
+		condition := v1helpers.FindOperatorCondition(conditions, conditionType)
+		if condition != nil && condition.Status == operatorsv1.ConditionTrue {
+			return fmt.Sprintf("an authentication migration is pending: %s", condition.Message)
+		}
+	}
+	return ""
+}
+
+// handlePassed reports the checks on their own in PreUpgradeChecksPassed, PreUpgradeChecksUpgradeable
+// is aggregated with the other Upgradeable conditions into the ClusterOperator.
+func handlePassed(reason string, err error) status.ConditionUpdate {
+	condition := operatorsv1.OperatorCondition{
+		Type:   preUpgradeChecksPassedCondition,
+		Status: operatorsv1.ConditionTrue,
+	}
+	if err != nil {
+		condition.Status = operatorsv1.ConditionFalse
+		condition.Reason = reason
+		condition.Message = err.Error()
+	}
+	return status.ConditionUpdate{
+		ConditionType:  preUpgradeChecksPassedCondition,
+		StatusUpdateFn: v1helpers.UpdateConditionFn(condition),
+	}
+}
@@ -0,0 +1,156 @@
+package preupgrade
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/go-test/deep"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func testCertificate(t *testing.T, notAfter time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "console.example.com"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestCertificateExpiryProblem(t *testing.T) {
+	deadline := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		notAfter time.Time
+		want     string
+	}{
+		{
+			name:     "Test certificate valid past the upgrade window",
+			notAfter: deadline.Add(time.Hour),
+			want:     "",
+		},
+		{
+			name:     "Test certificate expiring within the upgrade window",
+			notAfter: deadline.Add(-time.Hour),
+			want:     "the certificate in secret openshift-config/console-tls expires at 2024-05-31T23:00:00Z, within the upgrade window",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "console-tls", Namespace: api.OpenShiftConfigNamespace},
+				Data:       map[string][]byte{corev1.TLSCertKey: testCertificate(t, tt.notAfter)},
+			}
+			if diff := deep.Equal(certificateExpiryProblem(secret, deadline), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestTargetConsoleVersion(t *testing.T) {
+	tests := []struct {
+		name           string
+		clusterVersion *configv1.ClusterVersion
+		want           semver.Version
+	}{
+		{
+			name: "Test next minor version",
+			clusterVersion: &configv1.ClusterVersion{
+				Status: configv1.ClusterVersionStatus{Desired: configv1.Release{Version: "4.16.3"}},
+			},
+			want: semver.MustParse("4.17.0"),
+		},
+		{
+			name: "Test requested update",
+			clusterVersion: &configv1.ClusterVersion{
+				Spec:   configv1.ClusterVersionSpec{DesiredUpdate: &configv1.Update{Version: "4.16.5"}},
+				Status: configv1.ClusterVersionStatus{Desired: configv1.Release{Version: "4.16.3"}},
+			},
+			want: semver.MustParse("4.16.5"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := targetConsoleVersion(tt.clusterVersion)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestIncompatiblePlugins(t *testing.T) {
+	plugin := func(name string, annotations map[string]string) *consolev1.ConsolePlugin {
+		return &consolev1.ConsolePlugin{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations}}
+	}
+	plugins := []*consolev1.ConsolePlugin{
+		plugin("monitoring", nil),
+		plugin("kubevirt", map[string]string{api.PluginConsoleVersionAnnotation: ">=4.14.0 <4.18.0"}),
+		plugin("acm", map[string]string{api.PluginConsoleVersionAnnotation: ">=4.14.0 <4.17.0"}),
+		plugin("legacy", map[string]string{api.PluginConsoleVersionAnnotation: "latest"}),
+	}
+	want := []string{
+		"plugin acm supports console versions >=4.14.0 <4.17.0, not 4.17.0",
+		`plugin legacy has an invalid console.openshift.io/console-version-range "latest"`,
+	}
+	if diff := deep.Equal(incompatiblePlugins(plugins, semver.MustParse("4.17.0-rc.1")), want); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestAuthMigrationProblem(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []operatorsv1.OperatorCondition
+		want       string
+	}{
+		{
+			name: "Test no migration",
+			conditions: []operatorsv1.OperatorCondition{
+				{Type: "OIDCClientConfigProgressing", Status: operatorsv1.ConditionFalse},
+			},
+			want: "",
+		},
+		{
+			name: "Test pending migration",
+			conditions: []operatorsv1.OperatorCondition{
+				{Type: "OIDCClientConfigProgressing", Status: operatorsv1.ConditionTrue, Message: "waiting for the console deployment to pick up the OIDC config"},
+			},
+			want: "an authentication migration is pending: waiting for the console deployment to pick up the OIDC config",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(authMigrationProblem(tt.conditions), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
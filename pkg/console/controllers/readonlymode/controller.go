@@ -0,0 +1,139 @@
+package readonlymode
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	consolev1 "github.com/openshift/api/console/v1"
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	consoleclientv1 "github.com/openshift/client-go/console/clientset/versioned/typed/console/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+	"github.com/openshift/console-operator/pkg/console/subresource/configmap"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+)
+
+const readOnlyNotificationText = "The console is in read-only mode. Changes are disabled until an administrator turns read-only mode off."
+
+// ReadOnlyModeNotificationController shows a banner for as long as the console
+// is in read-only mode. The read-only switch itself is rendered into console-config
+// by the console operator.
+type ReadOnlyModeNotificationController struct {
+	operatorClient       v1helpers.OperatorClient
+	operatorConfigLister operatorv1listers.ConsoleLister
+
+	consoleNotificationClient consoleclientv1.ConsoleNotificationInterface
+}
+
+func NewReadOnlyModeNotificationController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	consoleNotificationClient consoleclientv1.ConsoleNotificationInterface,
+
+	recorder events.Recorder,
+) factory.Controller {
+
+	ctrl := &ReadOnlyModeNotificationController{
+		operatorClient:            operatorClient,
+		operatorConfigLister:      operatorConfigInformer.Lister(),
+		consoleNotificationClient: consoleNotificationClient,
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+		).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController("ReadOnlyModeNotificationController", recorder.WithComponentSuffix("read-only-mode-notification-controller"))
+}
+
+func (c *ReadOnlyModeNotificationController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch consolecapability.ManagementState(operatorConfig) {
+	case operatorsv1.Managed:
+		klog.V(4).Info("console-operator is in a managed state: syncing read-only mode notification")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Info("console-operator is in an unmanaged state: skipping read-only mode notification sync")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Info("console-operator is in a removed state: deleting read-only mode notification")
+		return c.removeReadOnlyNotification(ctx)
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+
+	reason, err := c.syncReadOnlyNotification(ctx, configmap.IsReadOnlyMode(operatorConfig))
+	if err != nil {
+		klog.V(4).Infof("error syncing %s consolenotification custom resource: %s", api.ReadOnlyConsoleNotification, err)
+	}
+	statusHandler.AddConditions(status.HandleProgressingOrDegraded("ReadOnlyModeNotificationSync", reason, err))
+	return statusHandler.FlushAndReturn(err)
+}
+
+func (c *ReadOnlyModeNotificationController) syncReadOnlyNotification(ctx context.Context, readOnly bool) (string, error) {
+	if !readOnly {
+		if err := c.removeReadOnlyNotification(ctx); err != nil {
+			return "FailedDelete", err
+		}
+		return "", nil
+	}
+
+	notification := &consolev1.ConsoleNotification{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: api.ReadOnlyConsoleNotification,
+		},
+		Spec: consolev1.ConsoleNotificationSpec{
+			Text:            readOnlyNotificationText,
+			Location:        "BannerTop",
+			Color:           "#FFFFFF",
+			BackgroundColor: "#C9190B",
+		},
+	}
+	// a banner edited or deleted out of band is put back while the console is in read-only mode
+	_, err := c.consoleNotificationClient.Create(ctx, notification, metav1.CreateOptions{})
+	if err == nil {
+		return "", nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return "FailedCreate", err
+	}
+	existing, err := c.consoleNotificationClient.Get(ctx, notification.Name, metav1.GetOptions{})
+	if err != nil {
+		return "FailedGet", err
+	}
+	if equality.Semantic.DeepEqual(existing.Spec, notification.Spec) {
+		return "", nil
+	}
+	existing.Spec = notification.Spec
+	if _, err := c.consoleNotificationClient.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return "FailedUpdate", err
+	}
+	return "", nil
+}
+
+func (c *ReadOnlyModeNotificationController) removeReadOnlyNotification(ctx context.Context) error {
+	err := c.consoleNotificationClient.Delete(ctx, api.ReadOnlyConsoleNotification, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
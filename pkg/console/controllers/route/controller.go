@@ -12,6 +12,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	coreinformersv1 "k8s.io/client-go/informers/core/v1"
 	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 
 	// openshift
@@ -31,8 +32,11 @@ import (
 
 	// console-operator
 	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
 	"github.com/openshift/console-operator/pkg/console/controllers/util"
 	"github.com/openshift/console-operator/pkg/console/status"
+	"github.com/openshift/console-operator/pkg/console/subresource/bluegreen"
+	"github.com/openshift/console-operator/pkg/console/subresource/canary"
 	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
 )
 
@@ -45,6 +49,7 @@ type RouteSyncController struct {
 	ingressClient        configclientv1.IngressInterface
 	routeClient          routeclientv1.RoutesGetter
 	secretClient         coreclientv1.SecretsGetter
+	configMapLister      corev1listers.ConfigMapLister
 }
 
 func NewRouteSyncController(
@@ -61,6 +66,8 @@ func NewRouteSyncController(
 	operatorConfigInformer v1.ConsoleInformer,
 	secretInformer coreinformersv1.SecretInformer,
 	routeInformer routesinformersv1.RouteInformer,
+	// the `openshift-console` ConfigMaps holding the blue/green rollout and canary states
+	configMapInformer coreinformersv1.ConfigMapInformer,
 	// events
 	recorder events.Recorder,
 ) factory.Controller {
@@ -72,6 +79,7 @@ func NewRouteSyncController(
 		ingressClient:        configClient.Ingresses(),
 		routeClient:          routev1Client,
 		secretClient:         secretClient,
+		configMapLister:      configMapInformer.Lister(),
 	}
 
 	configV1Informers := configInformer.Config().V1()
@@ -87,6 +95,9 @@ func NewRouteSyncController(
 	).WithFilteredEventsInformers( // route
 		util.IncludeNamesFilter(routeName, routesub.GetCustomRouteName(routeName)),
 		routeInformer.Informer(),
+	).WithFilteredEventsInformers( // blue/green rollout and canary states
+		util.IncludeNamesFilter(api.OpenShiftConsoleGreenConfigMapName, api.OpenShiftConsoleCanaryConfigMapName),
+		configMapInformer.Informer(),
 	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
 		ToController(fmt.Sprintf("%sRouteController", strings.Title(routeName)), recorder.WithComponentSuffix(fmt.Sprintf("%s-route-controller", routeName)))
 }
@@ -98,18 +109,15 @@ func (c *RouteSyncController) Sync(ctx context.Context, controllerContext factor
 	}
 	updatedOperatorConfig := operatorConfig.DeepCopy()
 
-	switch updatedOperatorConfig.Spec.ManagementState {
+	switch consolecapability.ManagementState(updatedOperatorConfig) {
 	case operatorsv1.Managed:
 		klog.V(4).Infof("console-operator is in a managed state: syncing %q route", c.routeName)
 	case operatorsv1.Unmanaged:
 		klog.V(4).Infof("console-operator is in an unmanaged state: skipping %q route sync", c.routeName)
 		return nil
 	case operatorsv1.Removed:
-		klog.V(4).Infof("console-operator is in a removed state: deleting %q route", c.routeName)
-		if err = c.removeRoute(ctx, routesub.GetCustomRouteName(c.routeName)); err != nil {
-			return err
-		}
-		return c.removeRoute(ctx, c.routeName)
+		klog.V(4).Infof("console-operator is in a removed state: the teardown controller deletes the %q route", c.routeName)
+		return nil
 	default:
 		return fmt.Errorf("unknown state: %v", updatedOperatorConfig.Spec.ManagementState)
 	}
@@ -121,6 +129,22 @@ func (c *RouteSyncController) Sync(ctx context.Context, controllerContext factor
 		return statusHandler.FlushAndReturn(err)
 	}
 	routeConfig := routesub.NewRouteConfig(updatedOperatorConfig, ingressConfig, c.routeName)
+	greenConfigMap, err := c.configMapLister.ConfigMaps(api.TargetNamespace).Get(api.OpenShiftConsoleGreenConfigMapName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return statusHandler.FlushAndReturn(err)
+	}
+	// the blue/green controller moves the rollout to the cut over once the green console is verified
+	routeConfig.SetGreenBackend(bluegreen.GetState(greenConfigMap).CutOver())
+	canaryConfigMap, err := c.configMapLister.ConfigMaps(api.TargetNamespace).Get(api.OpenShiftConsoleCanaryConfigMapName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return statusHandler.FlushAndReturn(err)
+	}
+	// the canary controller records once the canary pods are ready, the split ends with the canary
+	if canaryState := canary.GetState(canaryConfigMap); canaryState.Routed() {
+		routeConfig.SetCanaryBackend(canaryState.Weight)
+	}
+	// the green and canary backends are exclusive, a cut over route is not split with the canary
+	statusHandler.AddCondition(status.HandleDegraded(fmt.Sprintf("%sRouteBackend", strings.Title(c.routeName)), "ConflictingRollouts", routeConfig.BackendConflictErr()))
 
 	typePrefix := fmt.Sprintf("%sCustomRouteSync", strings.Title(c.routeName))
 	// try to sync the custom route first. If the sync fails for any reason, error
@@ -19,6 +19,7 @@ import (
 	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
 	"github.com/openshift/console-operator/bindata"
 	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
 	"github.com/openshift/console-operator/pkg/console/controllers/util"
 	"github.com/openshift/console-operator/pkg/console/status"
 	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
@@ -87,18 +88,15 @@ func (c *ServiceSyncController) Sync(ctx context.Context, controllerContext fact
 	}
 	updatedOperatorConfig := operatorConfig.DeepCopy()
 
-	switch updatedOperatorConfig.Spec.ManagementState {
+	switch consolecapability.ManagementState(updatedOperatorConfig) {
 	case operatorsv1.Managed:
 		klog.V(4).Infof("console-operator is in a managed state: syncing %q service", c.serviceName)
 	case operatorsv1.Unmanaged:
 		klog.V(4).Infof("console-operator is in an unmanaged state: skipping service %q sync", c.serviceName)
 		return nil
 	case operatorsv1.Removed:
-		klog.V(4).Infof("console-operator is in a removed state: deleting %q service", c.serviceName)
-		if err = c.removeService(ctx, c.getRedirectServiceName()); err != nil {
-			return err
-		}
-		return c.removeService(ctx, c.serviceName)
+		klog.V(4).Infof("console-operator is in a removed state: the teardown controller deletes the %q service", c.serviceName)
+		return nil
 	default:
 		return fmt.Errorf("unknown state: %v", updatedOperatorConfig.Spec.ManagementState)
 	}
@@ -0,0 +1,361 @@
+package teardown
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	// kube
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	appsv1informers "k8s.io/client-go/informers/apps/v1"
+	appsv1client "k8s.io/client-go/kubernetes/typed/apps/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	configclientv1 "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	oauthv1client "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
+	operatorclientv1 "github.com/openshift/client-go/operator/clientset/versioned/typed/operator/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	routeclientv1 "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
+	routev1informers "github.com/openshift/client-go/route/informers/externalversions/route/v1"
+	routev1listers "github.com/openshift/client-go/route/listers/route/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+	oauthsub "github.com/openshift/console-operator/pkg/console/subresource/oauthclient"
+	secretsub "github.com/openshift/console-operator/pkg/console/subresource/secret"
+)
+
+var (
+	routeNames = []string{
+		api.OpenShiftConsoleRouteName,
+		api.OpenshiftConsoleCustomRouteName,
+		api.OpenShiftConsoleDownloadsRouteName,
+		api.OpenshiftDownloadsCustomRouteName,
+	}
+	deploymentNames = []string{
+		api.OpenShiftConsoleDeploymentName,
+		api.OpenShiftConsoleCanaryName,
+		api.OpenShiftConsoleGreenName,
+	}
+	configMapNames = []string{
+		api.OpenShiftConsoleConfigMapName,
+		api.ServiceCAConfigMapName,
+		api.OpenShiftConsoleCanaryConfigMapName,
+		api.OpenShiftConsoleGreenConfigMapName,
+		api.OpenShiftConsoleCandidateConfigMapName,
+	}
+	// secretNames are the credentials of the console pods. The console-oidc-registered-client
+	// secret is kept, its registration access token is the only handle on the client registered
+	// with the OIDC provider and a console managed again reuses that client.
+	secretNames = []string{
+		secretsub.Stub().Name,
+		api.SessionSecretName,
+	}
+	serviceNames = []string{
+		api.OpenShiftConsoleServiceName,
+		api.OpenshiftConsoleRedirectServiceName,
+		api.DownloadsResourceName,
+		api.OpenShiftConsoleCanaryName,
+		api.OpenShiftConsoleGreenName,
+	}
+)
+
+// step of the teardown, its resources are removed once the resources of the previous steps are
+type step struct {
+	name        string
+	description string
+	// remove deletes or neutralizes the resources of the step, and returns whether they are gone
+	remove func(ctx context.Context, recorder events.Recorder) (bool, error)
+}
+
+// TeardownController removes the console when the operator config is in the Removed management
+// state, the Console capability is disabled, or the operator config is deleted. The resources are removed step by step, each step waits for the resources
+// of the previous one to be gone: the routes first so that no traffic reaches the console, then
+// the OAuthClient and the OIDC client status so that no login can complete, then the console
+// pods, their credentials and finally their configuration. The operator config carries a
+// finalizer while the console is managed, it is only dropped once the teardown is complete.
+//
+// The notifications, CLI downloads, PodDisruptionBudgets and other resources that play no part
+// in serving the console are removed by their own controllers.
+//
+//	writes:
+//	- consoles.operator.openshift.io/cluster .metadata.finalizers:
+//		- console.operator.openshift.io/teardown
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=TeardownProgressing
+//		- type=TeardownDegraded
+type TeardownController struct {
+	operatorClient       v1helpers.OperatorClient
+	operatorConfigClient operatorclientv1.ConsoleInterface
+	operatorConfigLister operatorv1listers.ConsoleLister
+	routeClient          routeclientv1.RoutesGetter
+	routeLister          routev1listers.RouteLister
+	oauthClient          oauthv1client.OAuthClientsGetter
+	authnLister          configv1listers.AuthenticationLister
+	clusterVersionLister configv1listers.ClusterVersionLister
+	authStatusHandler    *status.AuthStatusHandler
+	deploymentClient     appsv1client.DeploymentsGetter
+	deploymentLister     appsv1listers.DeploymentLister
+	coreClient           coreclientv1.CoreV1Interface
+
+	steps []step
+}
+
+func NewTeardownController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	operatorConfigClient operatorclientv1.ConsoleInterface,
+	routeClient routeclientv1.RoutesGetter,
+	oauthClient oauthv1client.OAuthClientsGetter,
+	authenticationClient configclientv1.AuthenticationInterface,
+	deploymentClient appsv1client.DeploymentsGetter,
+	coreClient coreclientv1.CoreV1Interface,
+	// informers
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	routeInformer routev1informers.RouteInformer,
+	authnInformer configv1informers.AuthenticationInformer,
+	clusterVersionInformer configv1informers.ClusterVersionInformer,
+	deploymentInformer appsv1informers.DeploymentInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &TeardownController{
+		operatorClient:       operatorClient,
+		operatorConfigClient: operatorConfigClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		routeClient:          routeClient,
+		routeLister:          routeInformer.Lister(),
+		oauthClient:          oauthClient,
+		authnLister:          authnInformer.Lister(),
+		clusterVersionLister: clusterVersionInformer.Lister(),
+		authStatusHandler:    status.NewAuthStatusHandler(authenticationClient, api.OpenShiftConsoleName, api.TargetNamespace, api.OpenShiftConsoleOperator),
+		deploymentClient:     deploymentClient,
+		deploymentLister:     deploymentInformer.Lister(),
+		coreClient:           coreClient,
+	}
+	ctrl.steps = []step{
+		{name: "Routes", description: "console and downloads routes", remove: ctrl.removeRoutes},
+		{name: "OAuthClient", description: "console OAuthClient redirect URIs", remove: ctrl.deregisterOAuthClient},
+		{name: "OIDCClientStatus", description: "console OIDC client status", remove: ctrl.removeOIDCClientStatus},
+		{name: "Deployments", description: "console deployments and their pods", remove: ctrl.removeDeployments},
+		{name: "Secrets", description: "console client and session secrets", remove: ctrl.removeSecrets},
+		{name: "Config", description: "console config, services and published endpoints", remove: ctrl.removeConfig},
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+			authnInformer.Informer(),
+		).WithFilteredEventsInformers( // cluster version
+		util.IncludeNamesFilter(api.VersionResourceName),
+		clusterVersionInformer.Informer(),
+	).WithFilteredEventsInformers( // routes
+		util.IncludeNamesFilter(routeNames...),
+		routeInformer.Informer(),
+	).WithFilteredEventsInformers( // deployments
+		util.IncludeNamesFilter(deploymentNames...),
+		deploymentInformer.Informer(),
+	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController("TeardownController", recorder.WithComponentSuffix("teardown-controller"))
+}
+
+func (c *TeardownController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+	clusterVersion, err := c.clusterVersionLister.Get(api.VersionResourceName)
+	if err != nil {
+		return err
+	}
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+
+	removed := operatorConfig.DeletionTimestamp != nil ||
+		consolecapability.ManagementState(operatorConfig) == operatorsv1.Removed ||
+		!consolecapability.IsEnabled(clusterVersion)
+	if !removed {
+		if err := c.updateFinalizer(ctx, operatorConfig, true); err != nil {
+			return err
+		}
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("Teardown", "", nil))
+		return statusHandler.FlushAndReturn(nil)
+	}
+
+	klog.V(4).Infoln("console has been removed: tearing down the console")
+	for i, step := range c.steps {
+		removed, err := step.remove(ctx, controllerContext.Recorder())
+		if err != nil {
+			statusHandler.AddCondition(status.HandleDegraded("Teardown", "FailedRemove"+step.name, fmt.Errorf("failed to remove the %s: %w", step.description, err)))
+			statusHandler.AddCondition(status.HandleProgressing("Teardown", "Remove"+step.name, progress(i, len(c.steps), step)))
+			return statusHandler.FlushAndReturn(err)
+		}
+		if !removed {
+			statusHandler.AddCondition(status.HandleDegraded("Teardown", "", nil))
+			statusHandler.AddCondition(status.HandleProgressing("Teardown", "Remove"+step.name, progress(i, len(c.steps), step)))
+			return statusHandler.FlushAndReturn(factory.SyntheticRequeueError)
+		}
+	}
+
+	if err := c.updateFinalizer(ctx, operatorConfig, false); err != nil {
+		return err
+	}
+	// the console is gone, the conditions it reported no longer apply
+	statusHandler.AddConditions(statusHandler.ResetConditions(operatorConfig.Status.Conditions))
+	statusHandler.AddConditions(status.HandleProgressingOrDegraded("Teardown", "", nil))
+	return statusHandler.FlushAndReturn(nil)
+}
+
+func progress(i, total int, step step) error {
+	return fmt.Errorf("step %d of %d: removing the %s", i+1, total, step.description)
+}
+
+// updateFinalizer adds or drops the teardown finalizer of the operator config.
+func (c *TeardownController) updateFinalizer(ctx context.Context, operatorConfig *operatorsv1.Console, present bool) error {
+	updated := operatorConfig.DeepCopy()
+	updated.Finalizers = withFinalizer(operatorConfig.Finalizers, present)
+	if len(updated.Finalizers) == len(operatorConfig.Finalizers) {
+		return nil
+	}
+	_, err := c.operatorConfigClient.Update(ctx, updated, metav1.UpdateOptions{})
+	if apierrors.IsConflict(err) {
+		klog.V(4).Infof("operator config changed while updating the %s finalizer, retrying", api.TeardownFinalizer)
+		return factory.SyntheticRequeueError
+	}
+	return err
+}
+
+func withFinalizer(finalizers []string, present bool) []string {
+	updated := []string{}
+	for _, finalizer := range finalizers {
+		if finalizer != api.TeardownFinalizer {
+			updated = append(updated, finalizer)
+		}
+	}
+	if present {
+		updated = append(updated, api.TeardownFinalizer)
+	}
+	return updated
+}
+
+func (c *TeardownController) removeRoutes(ctx context.Context, recorder events.Recorder) (bool, error) {
+	removed := true
+	for _, name := range routeNames {
+		if _, err := c.routeLister.Routes(api.TargetNamespace).Get(name); apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return false, err
+		}
+		removed = false
+		if err := c.routeClient.Routes(api.TargetNamespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return false, err
+		}
+	}
+	return removed, nil
+}
+
+// deregisterOAuthClient neutralizes the console OAuthClient, it is created by the CVO and
+// cannot be deleted.
+func (c *TeardownController) deregisterOAuthClient(ctx context.Context, recorder events.Recorder) (bool, error) {
+	oauthClient, err := c.oauthClient.OAuthClients().Get(ctx, oauthsub.Stub().Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		// the OAuth API is not served when the cluster authenticates with an external OIDC provider
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if len(oauthClient.RedirectURIs) == 0 && len(oauthClient.AdditionalSecrets) == 0 {
+		return true, nil
+	}
+	_, err = c.oauthClient.OAuthClients().Update(ctx, oauthsub.DeRegisterConsoleFromOAuthClient(oauthClient.DeepCopy()), metav1.UpdateOptions{})
+	return err == nil, err
+}
+
+func (c *TeardownController) removeOIDCClientStatus(ctx context.Context, recorder events.Recorder) (bool, error) {
+	authnConfig, err := c.authnLister.Get(api.ConfigResourceName)
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	reported, err := c.authStatusHandler.HasOIDCClientStatus(authnConfig)
+	if err != nil || !reported {
+		return !reported, err
+	}
+	return false, c.authStatusHandler.Remove(ctx, authnConfig)
+}
+
+// removeDeployments deletes the deployments in the foreground, they are gone once their pods are.
+func (c *TeardownController) removeDeployments(ctx context.Context, recorder events.Recorder) (bool, error) {
+	removed := true
+	foreground := metav1.DeletePropagationForeground
+	for _, name := range deploymentNames {
+		deployment, err := c.deploymentLister.Deployments(api.TargetNamespace).Get(name)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		removed = false
+		if deployment.DeletionTimestamp != nil {
+			continue
+		}
+		err = c.deploymentClient.Deployments(api.TargetNamespace).Delete(ctx, name, metav1.DeleteOptions{PropagationPolicy: &foreground})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return false, err
+		}
+	}
+	return removed, nil
+}
+
+func (c *TeardownController) removeSecrets(ctx context.Context, recorder events.Recorder) (bool, error) {
+	var errs []error
+	for _, name := range secretNames {
+		errs = append(errs, c.coreClient.Secrets(api.TargetNamespace).Delete(ctx, name, metav1.DeleteOptions{}))
+	}
+	// filter out 404 errors, which indicate that resource is already deleted
+	if err := utilerrors.FilterOut(utilerrors.NewAggregate(errs), apierrors.IsNotFound); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *TeardownController) removeConfig(ctx context.Context, recorder events.Recorder) (bool, error) {
+	var errs []error
+	for _, name := range configMapNames {
+		errs = append(errs, c.coreClient.ConfigMaps(api.TargetNamespace).Delete(ctx, name, metav1.DeleteOptions{}))
+	}
+	for _, name := range serviceNames {
+		errs = append(errs, c.coreClient.Services(api.TargetNamespace).Delete(ctx, name, metav1.DeleteOptions{}))
+	}
+	// clear the console URL from the public config map in openshift-config-managed
+	_, _, err := resourceapply.ApplyConfigMap(ctx, c.coreClient, recorder, configmapsub.EmptyPublicConfig())
+	errs = append(errs, err)
+	errs = append(errs, c.coreClient.ConfigMaps(api.OpenShiftConfigManagedNamespace).Delete(ctx, api.ConsoleEndpointsConfigMapName, metav1.DeleteOptions{}))
+
+	// filter out 404 errors, which indicate that resource is already deleted
+	if err := utilerrors.FilterOut(utilerrors.NewAggregate(errs), apierrors.IsNotFound); err != nil {
+		return false, err
+	}
+	return true, nil
+}
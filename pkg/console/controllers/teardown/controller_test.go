@@ -0,0 +1,198 @@
+package teardown
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-test/deep"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	fakeoauthclient "github.com/openshift/client-go/oauth/clientset/versioned/fake"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestWithFinalizer(t *testing.T) {
+	tests := []struct {
+		name       string
+		finalizers []string
+		present    bool
+		want       []string
+	}{
+		{
+			name:       "Test finalizer is added",
+			finalizers: nil,
+			present:    true,
+			want:       []string{api.TeardownFinalizer},
+		},
+		{
+			name:       "Test finalizer is kept",
+			finalizers: []string{"foo", api.TeardownFinalizer},
+			present:    true,
+			want:       []string{"foo", api.TeardownFinalizer},
+		},
+		{
+			name:       "Test finalizer is dropped",
+			finalizers: []string{api.TeardownFinalizer, "foo"},
+			present:    false,
+			want:       []string{"foo"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(withFinalizer(tt.finalizers, tt.present), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestRemoveDeployments(t *testing.T) {
+	now := metav1.Now()
+	deployment := func(name string, deletionTimestamp *metav1.Time) *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: api.TargetNamespace, DeletionTimestamp: deletionTimestamp}}
+	}
+
+	tests := []struct {
+		name        string
+		deployments []*appsv1.Deployment
+		wantRemoved bool
+		wantDeleted []string
+	}{
+		{
+			name:        "Test no deployments left",
+			deployments: []*appsv1.Deployment{deployment(api.DownloadsResourceName, nil)},
+			wantRemoved: true,
+			wantDeleted: []string{},
+		},
+		{
+			name:        "Test deployments are deleted",
+			deployments: []*appsv1.Deployment{deployment(api.OpenShiftConsoleDeploymentName, nil), deployment(api.OpenShiftConsoleGreenName, nil)},
+			wantRemoved: false,
+			wantDeleted: []string{api.OpenShiftConsoleDeploymentName, api.OpenShiftConsoleGreenName},
+		},
+		{
+			name:        "Test terminating deployments are waited for",
+			deployments: []*appsv1.Deployment{deployment(api.OpenShiftConsoleDeploymentName, &now)},
+			wantRemoved: false,
+			wantDeleted: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			client := fake.NewSimpleClientset()
+			for _, d := range tt.deployments {
+				indexer.Add(d)
+				client.Tracker().Add(d)
+			}
+			deleted := []string{}
+			client.PrependReactor("delete", "deployments", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				deleted = append(deleted, action.(clienttesting.DeleteAction).GetName())
+				return false, nil, nil
+			})
+
+			ctrl := &TeardownController{
+				deploymentClient: client.AppsV1(),
+				deploymentLister: appsv1listers.NewDeploymentLister(indexer),
+			}
+			removed, err := ctrl.removeDeployments(context.TODO(), events.NewInMemoryRecorder("test"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(removed, tt.wantRemoved); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(deleted, tt.wantDeleted); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestDeregisterOAuthClient(t *testing.T) {
+	tests := []struct {
+		name        string
+		oauthClient *oauthv1.OAuthClient
+		wantRemoved bool
+		wantUpdated bool
+	}{
+		{
+			name:        "Test OAuthClient not served",
+			oauthClient: nil,
+			wantRemoved: true,
+		},
+		{
+			name: "Test OAuthClient is deregistered",
+			oauthClient: &oauthv1.OAuthClient{
+				ObjectMeta:   metav1.ObjectMeta{Name: api.OAuthClientName},
+				Secret:       "secret",
+				RedirectURIs: []string{"https://console.example.com/auth/callback"},
+			},
+			wantRemoved: true,
+			wantUpdated: true,
+		},
+		{
+			name: "Test previous client secret of a rotation is dropped",
+			oauthClient: &oauthv1.OAuthClient{
+				ObjectMeta:        metav1.ObjectMeta{Name: api.OAuthClientName},
+				Secret:            "secret",
+				AdditionalSecrets: []string{"previous"},
+			},
+			wantRemoved: true,
+			wantUpdated: true,
+		},
+		{
+			name: "Test OAuthClient already deregistered",
+			oauthClient: &oauthv1.OAuthClient{
+				ObjectMeta: metav1.ObjectMeta{Name: api.OAuthClientName},
+				Secret:     "random",
+			},
+			wantRemoved: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := []runtime.Object{}
+			if tt.oauthClient != nil {
+				objects = append(objects, tt.oauthClient)
+			}
+			client := fakeoauthclient.NewSimpleClientset(objects...)
+
+			ctrl := &TeardownController{oauthClient: client.OauthV1()}
+			removed, err := ctrl.deregisterOAuthClient(context.TODO(), events.NewInMemoryRecorder("test"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(removed, tt.wantRemoved); diff != nil {
+				t.Error(diff)
+			}
+			updated := false
+			for _, action := range client.Actions() {
+				updated = updated || action.GetVerb() == "update"
+			}
+			if diff := deep.Equal(updated, tt.wantUpdated); diff != nil {
+				t.Error(diff)
+			}
+			if !tt.wantUpdated {
+				return
+			}
+			got, err := client.OauthV1().OAuthClients().Get(context.TODO(), api.OAuthClientName, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Secret == tt.oauthClient.Secret || len(got.RedirectURIs) > 0 || len(got.AdditionalSecrets) > 0 {
+				t.Errorf("OAuthClient still accepts the console: %+v", got)
+			}
+		})
+	}
+}
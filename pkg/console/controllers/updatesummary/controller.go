@@ -0,0 +1,133 @@
+package updatesummary
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	// kube
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	configv1 "github.com/openshift/api/config/v1"
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	configinformer "github.com/openshift/client-go/config/informers/externalversions"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+)
+
+// UpdateSummaryController keeps the console-update-summary ConfigMap in openshift-config-managed
+// in sync with the available and conditional updates of the ClusterVersion, so that the console
+// update UI works without reaching the update service from the console.
+//
+//	writes:
+//	- configmaps openshift-config-managed/console-update-summary
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=UpdateSummaryConfigMapDegraded
+type UpdateSummaryController struct {
+	operatorClient       v1helpers.OperatorClient
+	operatorConfigLister operatorv1listers.ConsoleLister
+	clusterVersionLister configlistersv1.ClusterVersionLister
+	proxyConfigLister    configlistersv1.ProxyLister
+	configMapClient      coreclientv1.ConfigMapsGetter
+}
+
+func NewUpdateSummaryController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	configMapClient coreclientv1.ConfigMapsGetter,
+	// informers
+	configInformer configinformer.SharedInformerFactory,
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	managedNSConfigMapInformer corev1informers.ConfigMapInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	configV1Informers := configInformer.Config().V1()
+
+	ctrl := &UpdateSummaryController{
+		operatorClient:       operatorClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		clusterVersionLister: configV1Informers.ClusterVersions().Lister(),
+		proxyConfigLister:    configV1Informers.Proxies().Lister(),
+		configMapClient:      configMapClient,
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+			configV1Informers.Proxies().Informer(),
+		).WithFilteredEventsInformers( // cluster version
+		util.IncludeNamesFilter(api.VersionResourceName),
+		configV1Informers.ClusterVersions().Informer(),
+	).WithFilteredEventsInformers( // console-update-summary, to revert out of band changes
+		util.IncludeNamesFilter(api.UpdateSummaryConfigMapName),
+		managedNSConfigMapInformer.Informer(),
+	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController("UpdateSummaryController", recorder.WithComponentSuffix("update-summary-controller"))
+}
+
+func (c *UpdateSummaryController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch consolecapability.ManagementState(operatorConfig) {
+	case operatorsv1.Managed:
+		klog.V(4).Infoln("console-operator is in a managed state: syncing update summary")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infoln("console-operator is in an unmanaged state: skipping update summary sync")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infoln("console-operator is in a removed state: clearing update summary")
+		_, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, controllerContext.Recorder(), configmapsub.EmptyUpdateSummaryConfigMap())
+		return err
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	reason, err := c.syncUpdateSummary(ctx, controllerContext.Recorder())
+	statusHandler.AddCondition(status.HandleDegraded("UpdateSummaryConfigMap", reason, err))
+	return statusHandler.FlushAndReturn(err)
+}
+
+func (c *UpdateSummaryController) syncUpdateSummary(ctx context.Context, recorder events.Recorder) (string, error) {
+	clusterVersion, err := c.clusterVersionLister.Get(api.VersionResourceName)
+	if err != nil {
+		return "FailedGetClusterVersion", err
+	}
+	proxyConfig, err := c.proxyConfigLister.Get(api.ConfigResourceName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "FailedGetProxyConfig", err
+	}
+	if apierrors.IsNotFound(err) {
+		proxyConfig = &configv1.Proxy{}
+	}
+
+	required, err := configmapsub.DefaultUpdateSummaryConfigMap(clusterVersion, proxyConfig)
+	if err != nil {
+		return "FailedRender", err
+	}
+	if _, _, err := resourceapply.ApplyConfigMap(ctx, c.configMapClient, recorder, required); err != nil {
+		return "FailedApply", err
+	}
+	return "", nil
+}
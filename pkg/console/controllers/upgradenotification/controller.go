@@ -2,11 +2,18 @@ package upgradenotification
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/blang/semver"
+	"golang.org/x/exp/slices"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/klog/v2"
 
 	v1 "github.com/openshift/api/config/v1"
@@ -19,6 +26,7 @@ import (
 	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
 	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
 	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
 	"github.com/openshift/console-operator/pkg/console/controllers/util"
 	"github.com/openshift/console-operator/pkg/console/status"
 	"github.com/openshift/library-go/pkg/controller/factory"
@@ -34,9 +42,11 @@ type UpgradeNotificationController struct {
 	operatorConfigLister operatorv1listers.ConsoleLister
 
 	consoleNotificationClient consoleclientv1.ConsoleNotificationInterface
+	dynamicClient             dynamic.Interface
 
 	// lister
-	clusterVersionLister configlistersv1.ClusterVersionLister
+	clusterVersionLister  configlistersv1.ClusterVersionLister
+	clusterOperatorLister configlistersv1.ClusterOperatorLister
 }
 
 // factory func needs clients and informers
@@ -49,6 +59,7 @@ func NewUpgradeNotificationController(
 	operatorClient v1helpers.OperatorClient,
 	operatorConfigInformer operatorv1informers.ConsoleInformer,
 	consoleNotificationClient consoleclientv1.ConsoleNotificationInterface,
+	dynamicClient dynamic.Interface,
 
 	recorder events.Recorder,
 ) factory.Controller {
@@ -57,7 +68,9 @@ func NewUpgradeNotificationController(
 		operatorClient:            operatorClient,
 		operatorConfigLister:      operatorConfigInformer.Lister(),
 		consoleNotificationClient: consoleNotificationClient,
+		dynamicClient:             dynamicClient,
 		clusterVersionLister:      configInformer.Config().V1().ClusterVersions().Lister(),
+		clusterOperatorLister:     configInformer.Config().V1().ClusterOperators().Lister(),
 	}
 
 	configV1Informers := configInformer.Config().V1()
@@ -66,7 +79,12 @@ func NewUpgradeNotificationController(
 		WithFilteredEventsInformers( // configs
 			util.IncludeNamesFilter(api.VersionResourceName),
 			configV1Informers.ClusterVersions().Informer(),
-		).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		).WithFilteredEventsInformers( // operator config
+		util.IncludeNamesFilter(api.ConfigResourceName),
+		operatorConfigInformer.Informer(),
+	).WithBareInformers( // cluster operators, their status changes too often to sync on every event
+		configV1Informers.ClusterOperators().Informer(),
+	).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
 		ToController("ClusterUpgradeNotificationController", recorder.WithComponentSuffix("cluster-upgrade-notification-controller"))
 }
 
@@ -77,15 +95,15 @@ func (c *UpgradeNotificationController) Sync(ctx context.Context, controllerCont
 	}
 	updatedOperatorConfig := operatorConfig.DeepCopy()
 
-	switch updatedOperatorConfig.Spec.ManagementState {
+	switch consolecapability.ManagementState(updatedOperatorConfig) {
 	case operatorsv1.Managed:
 		klog.V(4).Info("console-operator is in a managed state: syncing upgrade notification")
 	case operatorsv1.Unmanaged:
 		klog.V(4).Info("console-operator is in an unmanaged state: skipping upgrade notification sync")
 		return nil
 	case operatorsv1.Removed:
-		klog.V(4).Info("console-operator is in a removed state: deleting upgrade notification")
-		return c.removeUpgradeNotification(ctx)
+		klog.V(4).Info("console-operator is in a removed state: deleting upgrade notifications")
+		return errors.Join(c.removeUpgradeNotification(ctx), c.removePausedPoolsNotification(ctx), c.removeFailedUpdateNotification(ctx))
 	default:
 		return fmt.Errorf("unknown state: %v", updatedOperatorConfig.Spec.ManagementState)
 	}
@@ -97,7 +115,19 @@ func (c *UpgradeNotificationController) Sync(ctx context.Context, controllerCont
 		klog.V(4).Infof("error syncing %s consolenotification custom resource: %s", api.UpgradeConsoleNotification, err)
 	}
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded("ConsoleNotificationSync", reason, err))
-	return statusHandler.FlushAndReturn(err)
+
+	pausedPoolsReason, pausedPoolsErr := c.syncPausedPoolsNotification(ctx, time.Now())
+	if pausedPoolsErr != nil {
+		klog.V(4).Infof("error syncing %s consolenotification custom resource: %s", api.PausedPoolsConsoleNotification, pausedPoolsErr)
+	}
+	statusHandler.AddCondition(status.HandleDegraded("PausedPoolsNotificationSync", pausedPoolsReason, pausedPoolsErr))
+
+	failedUpdateReason, failedUpdateErr := c.syncFailedUpdateNotification(ctx, time.Now())
+	if failedUpdateErr != nil {
+		klog.V(4).Infof("error syncing %s consolenotification custom resource: %s", api.UpgradeFailedConsoleNotification, failedUpdateErr)
+	}
+	statusHandler.AddCondition(status.HandleDegraded("FailedUpdateNotificationSync", failedUpdateReason, failedUpdateErr))
+	return statusHandler.FlushAndReturn(errors.Join(err, pausedPoolsErr, failedUpdateErr))
 }
 
 func (c *UpgradeNotificationController) syncClusterUpgradeNotification(ctx context.Context) (string, error) {
@@ -106,45 +136,132 @@ func (c *UpgradeNotificationController) syncClusterUpgradeNotification(ctx conte
 		return "FailedGetClusterVersion", err
 	}
 
-	isUpdateProgressing := getClusterVersionCondition(*clusterVersionConfig, v1.ConditionTrue, v1.OperatorProgressing)
+	text := upgradeNotificationText(*clusterVersionConfig)
+	if len(text) == 0 {
+		err = c.removeUpgradeNotification(ctx)
+		if err != nil {
+			return "FailedDelete", err
+		}
+		return "", nil
+	}
+
+	notification := &consolev1.ConsoleNotification{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: api.UpgradeConsoleNotification,
+		},
+		Spec: consolev1.ConsoleNotificationSpec{
+			Text:            text,
+			Location:        "BannerTop",
+			Color:           "#000000",
+			BackgroundColor: "#F0AB00",
+		},
+	}
+	return c.applyNotification(ctx, notification)
+}
+
+// applyNotification creates the notification, or updates it if its spec changed, eg. when the
+// upgrade notification moves from an intermediate EUS step to the final one.
+func (c *UpgradeNotificationController) applyNotification(ctx context.Context, notification *consolev1.ConsoleNotification) (string, error) {
+	_, err := c.consoleNotificationClient.Create(ctx, notification, metav1.CreateOptions{})
+	if err == nil {
+		return "", nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return "FailedCreate", err
+	}
+	existing, err := c.consoleNotificationClient.Get(ctx, notification.Name, metav1.GetOptions{})
+	if err != nil {
+		return "FailedGet", err
+	}
+	if equality.Semantic.DeepEqual(existing.Spec, notification.Spec) {
+		return "", nil
+	}
+	existing.Spec = notification.Spec
+	_, err = c.consoleNotificationClient.Update(ctx, existing, metav1.UpdateOptions{})
+	if err != nil {
+		return "FailedUpdate", err
+	}
+	return "", nil
+}
+
+// upgradeNotificationText returns the text of the upgrade notification, or an empty string if
+// there is nothing to notify about. Besides updates in progress, admins of clusters on an EUS
+// channel are told about gated minor updates, and every admin is told when the channel does not
+// include the current version, since the cluster then receives no update recommendations.
+func upgradeNotificationText(clusterVersion v1.ClusterVersion) string {
+	channel := clusterVersion.Spec.Channel
+	desired := clusterVersion.Status.Desired
+	eusMinor, isEUS := eusChannelMinor(channel)
 
-	if isUpdateProgressing {
+	if getClusterVersionCondition(clusterVersion, v1.ConditionTrue, v1.OperatorProgressing) {
 		var currentVersion string
-		for _, version := range clusterVersionConfig.Status.History {
+		for _, version := range clusterVersion.Status.History {
 			if version.State == v1.CompletedUpdate {
 				currentVersion = version.Version
 				break
 			}
 		}
-		desiredVersion := clusterVersionConfig.Status.Desired.Version
-
-		if currentVersion == desiredVersion {
-			return "", nil
+		if currentVersion == desired.Version {
+			return ""
 		}
 
-		notification := &consolev1.ConsoleNotification{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: api.UpgradeConsoleNotification,
-			},
-			Spec: consolev1.ConsoleNotificationSpec{
-				Text:            fmt.Sprintf("This cluster is updating from %s to %s", currentVersion, desiredVersion),
-				Location:        "BannerTop",
-				Color:           "#000000",
-				BackgroundColor: "#F0AB00",
-			},
+		if risks := conditionalUpdateRisks(clusterVersion, desired.Version); len(risks) > 0 {
+			return fmt.Sprintf("This cluster is updating from %s to %s despite known risks: %s", currentVersion, desired.Version, strings.Join(risks, ", "))
 		}
-		_, err = c.consoleNotificationClient.Create(ctx, notification, metav1.CreateOptions{})
-		if err != nil && !apierrors.IsAlreadyExists(err) {
-			return "FailedCreate", err
+		current, currentErr := semver.ParseTolerant(currentVersion)
+		target, targetErr := semver.ParseTolerant(desired.Version)
+		if isEUS && currentErr == nil && targetErr == nil && current.Minor < target.Minor {
+			if target.Minor < eusMinor {
+				return fmt.Sprintf("This cluster is updating from %s to %s, an intermediate step of the EUS-to-EUS update to %d.%d", currentVersion, desired.Version, target.Major, eusMinor)
+			}
+			return fmt.Sprintf("This cluster is completing the EUS-to-EUS update from %s to %s", currentVersion, desired.Version)
 		}
-	} else {
-		err = c.removeUpgradeNotification(ctx)
-		if err != nil {
-			return "FailedDelete", err
+		return fmt.Sprintf("This cluster is updating from %s to %s", currentVersion, desired.Version)
+	}
+
+	if isEUS {
+		for _, condition := range clusterVersion.Status.Conditions {
+			if condition.Type == v1.OperatorUpgradeable && condition.Status == v1.ConditionFalse {
+				return fmt.Sprintf("Minor version updates in the %s channel are gated: %s", channel, condition.Message)
+			}
 		}
 	}
+	if len(channel) > 0 && len(desired.Channels) > 0 && !slices.Contains(desired.Channels, channel) {
+		return fmt.Sprintf("The %s channel does not include the current version %s, this cluster receives no update recommendations until the channel is changed", channel, desired.Version)
+	}
+	return ""
+}
+
+// eusChannelMinor returns the minor version an EUS channel such as eus-4.16 leads to.
+func eusChannelMinor(channel string) (uint64, bool) {
+	version, ok := strings.CutPrefix(channel, "eus-")
+	if !ok {
+		return 0, false
+	}
+	parsed, err := semver.ParseTolerant(version)
+	if err != nil {
+		return 0, false
+	}
+	return parsed.Minor, true
+}
 
-	return "", nil
+// conditionalUpdateRisks returns the names of the risks of the conditional update to version,
+// if the update is not recommended for this cluster.
+func conditionalUpdateRisks(clusterVersion v1.ClusterVersion, version string) []string {
+	for _, update := range clusterVersion.Status.ConditionalUpdates {
+		if update.Release.Version != version {
+			continue
+		}
+		if meta.IsStatusConditionTrue(update.Conditions, "Recommended") {
+			return nil
+		}
+		risks := []string{}
+		for _, risk := range update.Risks {
+			risks = append(risks, risk.Name)
+		}
+		return risks
+	}
+	return nil
 }
 
 func (c *UpgradeNotificationController) removeUpgradeNotification(ctx context.Context) error {
@@ -0,0 +1,154 @@
+package upgradenotification
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift/api/config/v1"
+)
+
+func TestUpgradeNotificationText(t *testing.T) {
+	progressing := v1.ClusterOperatorStatusCondition{Type: v1.OperatorProgressing, Status: v1.ConditionTrue}
+	history := func(versions ...string) []v1.UpdateHistory {
+		updates := []v1.UpdateHistory{}
+		for i, version := range versions {
+			state := v1.CompletedUpdate
+			if i == 0 {
+				state = v1.PartialUpdate
+			}
+			updates = append(updates, v1.UpdateHistory{Version: version, State: state})
+		}
+		return updates
+	}
+
+	tests := []struct {
+		name           string
+		clusterVersion v1.ClusterVersion
+		want           string
+	}{
+		{
+			name: "Test no update",
+			clusterVersion: v1.ClusterVersion{
+				Spec:   v1.ClusterVersionSpec{Channel: "stable-4.16"},
+				Status: v1.ClusterVersionStatus{Desired: v1.Release{Version: "4.16.3", Channels: []string{"stable-4.16"}}},
+			},
+			want: "",
+		},
+		{
+			name: "Test update",
+			clusterVersion: v1.ClusterVersion{
+				Spec: v1.ClusterVersionSpec{Channel: "stable-4.16"},
+				Status: v1.ClusterVersionStatus{
+					Desired:    v1.Release{Version: "4.16.3"},
+					History:    history("4.16.3", "4.15.20"),
+					Conditions: []v1.ClusterOperatorStatusCondition{progressing},
+				},
+			},
+			want: "This cluster is updating from 4.15.20 to 4.16.3",
+		},
+		{
+			name: "Test intermediate step of an EUS-to-EUS update",
+			clusterVersion: v1.ClusterVersion{
+				Spec: v1.ClusterVersionSpec{Channel: "eus-4.16"},
+				Status: v1.ClusterVersionStatus{
+					Desired:    v1.Release{Version: "4.15.20"},
+					History:    history("4.15.20", "4.14.30"),
+					Conditions: []v1.ClusterOperatorStatusCondition{progressing},
+				},
+			},
+			want: "This cluster is updating from 4.14.30 to 4.15.20, an intermediate step of the EUS-to-EUS update to 4.16",
+		},
+		{
+			name: "Test final step of an EUS-to-EUS update",
+			clusterVersion: v1.ClusterVersion{
+				Spec: v1.ClusterVersionSpec{Channel: "eus-4.16"},
+				Status: v1.ClusterVersionStatus{
+					Desired:    v1.Release{Version: "4.16.3"},
+					History:    history("4.16.3", "4.15.20", "4.14.30"),
+					Conditions: []v1.ClusterOperatorStatusCondition{progressing},
+				},
+			},
+			want: "This cluster is completing the EUS-to-EUS update from 4.15.20 to 4.16.3",
+		},
+		{
+			name: "Test z-stream update in an EUS channel",
+			clusterVersion: v1.ClusterVersion{
+				Spec: v1.ClusterVersionSpec{Channel: "eus-4.16"},
+				Status: v1.ClusterVersionStatus{
+					Desired:    v1.Release{Version: "4.16.4"},
+					History:    history("4.16.4", "4.16.3"),
+					Conditions: []v1.ClusterOperatorStatusCondition{progressing},
+				},
+			},
+			want: "This cluster is updating from 4.16.3 to 4.16.4",
+		},
+		{
+			name: "Test update with accepted risks",
+			clusterVersion: v1.ClusterVersion{
+				Spec: v1.ClusterVersionSpec{Channel: "stable-4.16"},
+				Status: v1.ClusterVersionStatus{
+					Desired:    v1.Release{Version: "4.16.3"},
+					History:    history("4.16.3", "4.15.20"),
+					Conditions: []v1.ClusterOperatorStatusCondition{progressing},
+					ConditionalUpdates: []v1.ConditionalUpdate{
+						{
+							Release:    v1.Release{Version: "4.16.3"},
+							Risks:      []v1.ConditionalUpdateRisk{{Name: "AzureDiskStall"}, {Name: "OVNRestart"}},
+							Conditions: []metav1.Condition{{Type: "Recommended", Status: metav1.ConditionFalse}},
+						},
+					},
+				},
+			},
+			want: "This cluster is updating from 4.15.20 to 4.16.3 despite known risks: AzureDiskStall, OVNRestart",
+		},
+		{
+			name: "Test recommended conditional update",
+			clusterVersion: v1.ClusterVersion{
+				Spec: v1.ClusterVersionSpec{Channel: "stable-4.16"},
+				Status: v1.ClusterVersionStatus{
+					Desired:    v1.Release{Version: "4.16.3"},
+					History:    history("4.16.3", "4.15.20"),
+					Conditions: []v1.ClusterOperatorStatusCondition{progressing},
+					ConditionalUpdates: []v1.ConditionalUpdate{
+						{
+							Release:    v1.Release{Version: "4.16.3"},
+							Risks:      []v1.ConditionalUpdateRisk{{Name: "AzureDiskStall"}},
+							Conditions: []metav1.Condition{{Type: "Recommended", Status: metav1.ConditionTrue}},
+						},
+					},
+				},
+			},
+			want: "This cluster is updating from 4.15.20 to 4.16.3",
+		},
+		{
+			name: "Test gated minor updates in an EUS channel",
+			clusterVersion: v1.ClusterVersion{
+				Spec: v1.ClusterVersionSpec{Channel: "eus-4.16"},
+				Status: v1.ClusterVersionStatus{
+					Desired: v1.Release{Version: "4.14.30", Channels: []string{"eus-4.16"}},
+					Conditions: []v1.ClusterOperatorStatusCondition{
+						{Type: v1.OperatorUpgradeable, Status: v1.ConditionFalse, Message: "Kubernetes 1.28 removes APIs, an admin ack is required"},
+					},
+				},
+			},
+			want: "Minor version updates in the eus-4.16 channel are gated: Kubernetes 1.28 removes APIs, an admin ack is required",
+		},
+		{
+			name: "Test channel without the current version",
+			clusterVersion: v1.ClusterVersion{
+				Spec:   v1.ClusterVersionSpec{Channel: "fast-4.17"},
+				Status: v1.ClusterVersionStatus{Desired: v1.Release{Version: "4.16.3", Channels: []string{"eus-4.16", "stable-4.16"}}},
+			},
+			want: "The fast-4.17 channel does not include the current version 4.16.3, this cluster receives no update recommendations until the channel is changed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(upgradeNotificationText(tt.clusterVersion), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package upgradenotification
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blang/semver"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	v1 "github.com/openshift/api/config/v1"
+	consolev1 "github.com/openshift/api/console/v1"
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/configmap"
+)
+
+// clusterVersionFailing is set by the cluster-version-operator when it cannot make progress
+// reconciling the desired release.
+const clusterVersionFailing v1.ClusterStatusConditionType = "Failing"
+
+// rollbackNoticePeriod is how long the banner stays up after a rollback has completed.
+const rollbackNoticePeriod = 24 * time.Hour
+
+func (c *UpgradeNotificationController) syncFailedUpdateNotification(ctx context.Context, now time.Time) (string, error) {
+	clusterVersionConfig, err := c.clusterVersionLister.Get(api.VersionResourceName)
+	if err != nil {
+		return "FailedGetClusterVersion", err
+	}
+	clusterOperators, err := c.clusterOperatorLister.List(labels.Everything())
+	if err != nil {
+		return "FailedListClusterOperators", err
+	}
+
+	text := failedUpdateNotificationText(*clusterVersionConfig, clusterOperators, now)
+	if len(text) == 0 {
+		if err := c.removeFailedUpdateNotification(ctx); err != nil {
+			return "FailedDelete", err
+		}
+		return "", nil
+	}
+
+	notification := &consolev1.ConsoleNotification{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: api.UpgradeFailedConsoleNotification,
+		},
+		Spec: consolev1.ConsoleNotificationSpec{
+			Text:            text,
+			Location:        "BannerTop",
+			Color:           "#FFFFFF",
+			BackgroundColor: "#C9190B",
+			Link: &consolev1.Link{
+				Text: "Troubleshooting cluster updates",
+				Href: configmap.UPDATE_TROUBLESHOOTING_DOC_URL,
+			},
+		},
+	}
+	return c.applyNotification(ctx, notification)
+}
+
+func (c *UpgradeNotificationController) removeFailedUpdateNotification(ctx context.Context) error {
+	err := c.consoleNotificationClient.Delete(ctx, api.UpgradeFailedConsoleNotification, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// failedUpdateNotificationText returns the text of the high severity banner shown while the
+// cluster is rolled back to an older release or the update is failing, or an empty string.
+func failedUpdateNotificationText(clusterVersion v1.ClusterVersion, clusterOperators []*v1.ClusterOperator, now time.Time) string {
+	parts := []string{}
+	desired := clusterVersion.Status.Desired.Version
+	if from := rolledBackFrom(clusterVersion, now); len(from) > 0 {
+		parts = append(parts, fmt.Sprintf("This cluster is rolled back from %s to %s.", from, desired))
+	}
+	for _, condition := range clusterVersion.Status.Conditions {
+		if condition.Type != clusterVersionFailing || condition.Status != v1.ConditionTrue {
+			continue
+		}
+		// the first line is enough for a banner, the full message is on the ClusterVersion
+		message, _, _ := strings.Cut(strings.TrimSpace(condition.Message), "\n")
+		parts = append(parts, fmt.Sprintf("The update to %s is failing: %s", desired, strings.TrimSuffix(message, ".")+"."))
+		if failing := failingClusterOperators(clusterOperators); len(failing) > 0 {
+			parts = append(parts, fmt.Sprintf("Failing cluster operators: %s.", strings.Join(failing, ", ")))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// rolledBackFrom returns the newest completed version in the update history if the desired
+// version is older than it, while the rollback is in progress and for the notice period after.
+func rolledBackFrom(clusterVersion v1.ClusterVersion, now time.Time) string {
+	history := clusterVersion.Status.History
+	if len(history) < 2 {
+		return ""
+	}
+	if completion := history[0].CompletionTime; history[0].State == v1.CompletedUpdate && completion != nil && now.Sub(completion.Time) > rollbackNoticePeriod {
+		return ""
+	}
+	desired, err := semver.ParseTolerant(clusterVersion.Status.Desired.Version)
+	if err != nil {
+		return ""
+	}
+	var newest *semver.Version
+	newestVersion := ""
+	for _, update := range history[1:] {
+		if update.State != v1.CompletedUpdate {
+			continue
+		}
+		version, err := semver.ParseTolerant(update.Version)
+		if err != nil {
+			continue
+		}
+		if newest == nil || version.GT(*newest) {
+			newest = &version
+			newestVersion = update.Version
+		}
+	}
+	if newest == nil || !desired.LT(*newest) {
+		return ""
+	}
+	return newestVersion
+}
+
+// failingClusterOperators returns the sorted names of the cluster operators that are degraded
+// or unavailable.
+func failingClusterOperators(clusterOperators []*v1.ClusterOperator) []string {
+	failing := []string{}
+	for _, clusterOperator := range clusterOperators {
+		for _, condition := range clusterOperator.Status.Conditions {
+			if (condition.Type == v1.OperatorDegraded && condition.Status == v1.ConditionTrue) ||
+				(condition.Type == v1.OperatorAvailable && condition.Status == v1.ConditionFalse) {
+				failing = append(failing, clusterOperator.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(failing)
+	return failing
+}
@@ -0,0 +1,106 @@
+package upgradenotification
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift/api/config/v1"
+)
+
+func TestFailedUpdateNotificationText(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	completed := func(version string, at time.Time) v1.UpdateHistory {
+		return v1.UpdateHistory{State: v1.CompletedUpdate, Version: version, CompletionTime: &metav1.Time{Time: at}}
+	}
+	failing := v1.ClusterOperatorStatusCondition{
+		Type:    clusterVersionFailing,
+		Status:  v1.ConditionTrue,
+		Message: "Cluster operators authentication, monitoring are degraded\nsee the cluster operators for details",
+	}
+	clusterOperators := []*v1.ClusterOperator{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "monitoring"},
+			Status: v1.ClusterOperatorStatus{Conditions: []v1.ClusterOperatorStatusCondition{
+				{Type: v1.OperatorDegraded, Status: v1.ConditionTrue},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "authentication"},
+			Status: v1.ClusterOperatorStatus{Conditions: []v1.ClusterOperatorStatusCondition{
+				{Type: v1.OperatorAvailable, Status: v1.ConditionFalse},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "console"},
+			Status: v1.ClusterOperatorStatus{Conditions: []v1.ClusterOperatorStatusCondition{
+				{Type: v1.OperatorAvailable, Status: v1.ConditionTrue},
+				{Type: v1.OperatorDegraded, Status: v1.ConditionFalse},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		clusterVersion v1.ClusterVersion
+		want           string
+	}{
+		{
+			name: "Test healthy update",
+			clusterVersion: v1.ClusterVersion{
+				Status: v1.ClusterVersionStatus{
+					Desired: v1.Release{Version: "4.16.3"},
+					History: []v1.UpdateHistory{{State: v1.PartialUpdate, Version: "4.16.3"}, completed("4.16.1", now.Add(-48*time.Hour))},
+				},
+			},
+			want: "",
+		},
+		{
+			name: "Test failing update",
+			clusterVersion: v1.ClusterVersion{
+				Status: v1.ClusterVersionStatus{
+					Desired:    v1.Release{Version: "4.16.3"},
+					History:    []v1.UpdateHistory{{State: v1.PartialUpdate, Version: "4.16.3"}, completed("4.16.1", now.Add(-48*time.Hour))},
+					Conditions: []v1.ClusterOperatorStatusCondition{failing},
+				},
+			},
+			want: "The update to 4.16.3 is failing: Cluster operators authentication, monitoring are degraded. Failing cluster operators: authentication, monitoring.",
+		},
+		{
+			name: "Test rollback in progress",
+			clusterVersion: v1.ClusterVersion{
+				Status: v1.ClusterVersionStatus{
+					Desired: v1.Release{Version: "4.16.1"},
+					History: []v1.UpdateHistory{
+						{State: v1.PartialUpdate, Version: "4.16.1"},
+						completed("4.16.3", now.Add(-time.Hour)),
+						completed("4.16.1", now.Add(-48*time.Hour)),
+					},
+				},
+			},
+			want: "This cluster is rolled back from 4.16.3 to 4.16.1.",
+		},
+		{
+			name: "Test rollback completed past the notice period",
+			clusterVersion: v1.ClusterVersion{
+				Status: v1.ClusterVersionStatus{
+					Desired: v1.Release{Version: "4.16.1"},
+					History: []v1.UpdateHistory{
+						completed("4.16.1", now.Add(-25*time.Hour)),
+						completed("4.16.3", now.Add(-30*time.Hour)),
+					},
+				},
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(failedUpdateNotificationText(tt.clusterVersion, clusterOperators, now), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package upgradenotification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	consolev1 "github.com/openshift/api/console/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+// machineConfigPoolGVR is served by the machine-config-operator, which is not part of every
+// topology, eg. hosted control planes have no MachineConfigPools.
+var machineConfigPoolGVR = schema.GroupVersionResource{
+	Group:    "machineconfiguration.openshift.io",
+	Version:  "v1",
+	Resource: "machineconfigpools",
+}
+
+// syncPausedPoolsNotification keeps a banner listing the paused MachineConfigPools for as long
+// as any pool is paused. MachineConfigPools do not record when they were paused, the time each
+// pool was first seen paused is kept in an annotation of the notification instead.
+func (c *UpgradeNotificationController) syncPausedPoolsNotification(ctx context.Context, now time.Time) (string, error) {
+	pools, err := c.dynamicClient.Resource(machineConfigPoolGVR).List(ctx, metav1.ListOptions{})
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return "", c.removePausedPoolsNotification(ctx)
+	}
+	if err != nil {
+		return "FailedListMachineConfigPools", err
+	}
+	paused := pausedPools(pools.Items)
+	if len(paused) == 0 {
+		if err := c.removePausedPoolsNotification(ctx); err != nil {
+			return "FailedDelete", err
+		}
+		return "", nil
+	}
+
+	existing, err := c.consoleNotificationClient.Get(ctx, api.PausedPoolsConsoleNotification, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		existing = nil
+	} else if err != nil {
+		return "FailedGet", err
+	}
+	previous := map[string]string{}
+	if existing != nil {
+		if annotation := existing.Annotations[api.PausedPoolsSinceAnnotation]; len(annotation) > 0 {
+			if err := json.Unmarshal([]byte(annotation), &previous); err != nil {
+				klog.V(4).Infof("ignoring invalid %s annotation: %v", api.PausedPoolsSinceAnnotation, err)
+			}
+		}
+	}
+	since := pausedSince(previous, paused, now)
+	sinceJSON, err := json.Marshal(since)
+	if err != nil {
+		return "FailedRender", err
+	}
+
+	notification := &consolev1.ConsoleNotification{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        api.PausedPoolsConsoleNotification,
+			Annotations: map[string]string{api.PausedPoolsSinceAnnotation: string(sinceJSON)},
+		},
+		Spec: consolev1.ConsoleNotificationSpec{
+			Text:            pausedPoolsNotificationText(paused, since, now),
+			Location:        "BannerTop",
+			Color:           "#000000",
+			BackgroundColor: "#F0AB00",
+		},
+	}
+	if existing == nil {
+		if _, err := c.consoleNotificationClient.Create(ctx, notification, metav1.CreateOptions{}); err != nil {
+			return "FailedCreate", err
+		}
+		return "", nil
+	}
+	if equality.Semantic.DeepEqual(existing.Spec, notification.Spec) && existing.Annotations[api.PausedPoolsSinceAnnotation] == string(sinceJSON) {
+		return "", nil
+	}
+	existing.Spec = notification.Spec
+	if existing.Annotations == nil {
+		existing.Annotations = map[string]string{}
+	}
+	existing.Annotations[api.PausedPoolsSinceAnnotation] = string(sinceJSON)
+	if _, err := c.consoleNotificationClient.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return "FailedUpdate", err
+	}
+	return "", nil
+}
+
+func (c *UpgradeNotificationController) removePausedPoolsNotification(ctx context.Context) error {
+	err := c.consoleNotificationClient.Delete(ctx, api.PausedPoolsConsoleNotification, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// pausedPools returns the sorted names of the paused pools.
+func pausedPools(pools []unstructured.Unstructured) []string {
+	paused := []string{}
+	for _, pool := range pools {
+		if isPaused, _, _ := unstructured.NestedBool(pool.Object, "spec", "paused"); isPaused {
+			paused = append(paused, pool.GetName())
+		}
+	}
+	sort.Strings(paused)
+	return paused
+}
+
+// pausedSince keeps the time a pool was first seen paused for pools that are still paused,
+// and starts the clock at now for newly paused pools.
+func pausedSince(previous map[string]string, paused []string, now time.Time) map[string]string {
+	since := map[string]string{}
+	for _, pool := range paused {
+		if timestamp, err := time.Parse(time.RFC3339, previous[pool]); err == nil && !timestamp.After(now) {
+			since[pool] = previous[pool]
+			continue
+		}
+		since[pool] = now.UTC().Format(time.RFC3339)
+	}
+	return since
+}
+
+func pausedPoolsNotificationText(paused []string, since map[string]string, now time.Time) string {
+	pools := []string{}
+	for _, pool := range paused {
+		timestamp, err := time.Parse(time.RFC3339, since[pool])
+		if err != nil {
+			pools = append(pools, pool)
+			continue
+		}
+		pools = append(pools, fmt.Sprintf("%s (for %s)", pool, pausedFor(now.Sub(timestamp))))
+	}
+	return fmt.Sprintf("The following MachineConfigPools are paused and do not receive configuration or cluster updates: %s", strings.Join(pools, ", "))
+}
+
+// pausedFor is coarse on purpose, the text is only rewritten once the hour or day changes.
+func pausedFor(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return "less than an hour"
+	case d < 2*time.Hour:
+		return "1 hour"
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%d days", int(d.Hours()/24))
+	}
+}
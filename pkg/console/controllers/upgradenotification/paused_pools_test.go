@@ -0,0 +1,52 @@
+package upgradenotification
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPausedPools(t *testing.T) {
+	pool := func(name string, paused bool) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name},
+			"spec":     map[string]interface{}{"paused": paused},
+		}}
+	}
+	pools := []unstructured.Unstructured{
+		pool("worker", true),
+		pool("master", false),
+		pool("infra", true),
+		{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "gpu"}}},
+	}
+	if diff := deep.Equal(pausedPools(pools), []string{"infra", "worker"}); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestPausedPoolsNotificationText(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	previous := map[string]string{
+		"worker":   "2024-06-07T09:00:00Z",
+		"infra":    "2024-06-10T07:30:00Z",
+		"unpaused": "2024-06-01T00:00:00Z",
+	}
+	paused := []string{"gpu", "infra", "worker"}
+
+	since := pausedSince(previous, paused, now)
+	wantSince := map[string]string{
+		"gpu":    "2024-06-10T12:00:00Z",
+		"infra":  "2024-06-10T07:30:00Z",
+		"worker": "2024-06-07T09:00:00Z",
+	}
+	if diff := deep.Equal(since, wantSince); diff != nil {
+		t.Error(diff)
+	}
+
+	want := "The following MachineConfigPools are paused and do not receive configuration or cluster updates: gpu (for less than an hour), infra (for 4 hours), worker (for 3 days)"
+	if diff := deep.Equal(pausedPoolsNotificationText(paused, since, now), want); diff != nil {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,50 @@
+package util
+
+import (
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+)
+
+// RequeueBackoffOptions tunes the backoff of the keys whose sync failed.
+type RequeueBackoffOptions struct {
+	// Base is the backoff after the first failed sync of a key, doubled on every failure
+	Base time.Duration
+	// Max caps the backoff of a key
+	Max time.Duration
+}
+
+// RequeueBackoff requeues the keys of a controller whose sync failed after a per-key exponential
+// backoff. The queue of the controller factory retries a failed key within milliseconds, which
+// turns an outage of a remote service the sync depends on into a hot reconcile loop.
+type RequeueBackoff struct {
+	limiter workqueue.RateLimiter
+}
+
+func NewRequeueBackoff(options RequeueBackoffOptions) *RequeueBackoff {
+	return &RequeueBackoff{
+		limiter: workqueue.NewItemExponentialFailureRateLimiter(options.Base, options.Max),
+	}
+}
+
+// Handle requeues the key being synced after its backoff if err is set, and returns nil so the
+// factory does not requeue it right away. The backoff of the key is reset once it syncs.
+func (b *RequeueBackoff) Handle(syncCtx factory.SyncContext, err error) error {
+	key := syncCtx.QueueKey()
+	if err == nil {
+		b.limiter.Forget(key)
+		return nil
+	}
+
+	delay := b.limiter.When(key)
+	if err == factory.SyntheticRequeueError {
+		klog.V(4).Infof("requeue of key %q requested, retrying in %s", key, delay)
+	} else {
+		klog.Errorf("sync of key %q failed, retrying in %s: %v", key, delay, err)
+	}
+	syncCtx.Queue().AddAfter(key, delay)
+	return nil
+}
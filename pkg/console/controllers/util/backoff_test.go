@@ -0,0 +1,49 @@
+package util
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+)
+
+func TestRequeueBackoff(t *testing.T) {
+	tests := []struct {
+		name         string
+		errs         []error
+		wantRequeues []int
+	}{
+		{
+			name:         "Test backoff grows with every failure",
+			errs:         []error{fmt.Errorf("provider unreachable"), factory.SyntheticRequeueError, fmt.Errorf("provider unreachable")},
+			wantRequeues: []int{1, 2, 3},
+		},
+		{
+			name:         "Test backoff reset once synced",
+			errs:         []error{fmt.Errorf("provider unreachable"), nil, fmt.Errorf("provider unreachable")},
+			wantRequeues: []int{1, 0, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			syncCtx := factory.NewSyncContext("test", events.NewInMemoryRecorder("test"))
+			defer syncCtx.Queue().ShutDown()
+			backoff := NewRequeueBackoff(RequeueBackoffOptions{Base: time.Millisecond, Max: time.Second})
+
+			requeues := []int{}
+			for _, err := range tt.errs {
+				if handledErr := backoff.Handle(syncCtx, err); handledErr != nil {
+					t.Errorf("Handle() error = %v, the key is requeued by the backoff", handledErr)
+				}
+				requeues = append(requeues, backoff.limiter.NumRequeues(syncCtx.QueueKey()))
+			}
+			if diff := deep.Equal(tt.wantRequeues, requeues); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
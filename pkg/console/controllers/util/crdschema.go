@@ -0,0 +1,121 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsv1informers "k8s.io/apiextensions-apiserver/pkg/client/informers/externalversions/apiextensions/v1"
+	apiextensionsv1listers "k8s.io/apiextensions-apiserver/pkg/client/listers/apiextensions/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// CRDSchemaEvaluator answers whether the served and stored schema of a CRD has a field, for the
+// controllers gated on fields an API only gets behind a feature gate. The answer is cached per
+// CRD and field until the CRD is updated, so the schema is not walked on every sync.
+type CRDSchemaEvaluator struct {
+	crdInformer cache.SharedIndexInformer
+	crdLister   apiextensionsv1listers.CustomResourceDefinitionLister
+
+	lock  sync.RWMutex
+	cache map[string]map[string]crdSchemaResult
+}
+
+type crdSchemaResult struct {
+	// resourceVersion of the CRD the field was looked up in
+	resourceVersion string
+	hasField        bool
+}
+
+func NewCRDSchemaEvaluator(crdInformer apiextensionsv1informers.CustomResourceDefinitionInformer) *CRDSchemaEvaluator {
+	e := &CRDSchemaEvaluator{
+		crdInformer: crdInformer.Informer(),
+		crdLister:   crdInformer.Lister(),
+		cache:       map[string]map[string]crdSchemaResult{},
+	}
+	if _, err := e.crdInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(_, obj interface{}) { e.invalidate(obj) },
+		DeleteFunc: e.invalidate,
+	}); err != nil {
+		klog.Errorf("failed to watch CRD updates, CRD schemas are re-evaluated once updated: %v", err)
+	}
+	return e
+}
+
+// Informer is the CRD informer the evaluator is invalidated by, for the controllers to be
+// synced on the same events.
+func (e *CRDSchemaEvaluator) Informer() cache.SharedIndexInformer {
+	return e.crdInformer
+}
+
+// HasField returns whether the v1 schema of the CRD named crdName has the field at path, eg.
+// "status", "oidcClients". It fails if the CRD does not exist or is not served and stored as v1.
+func (e *CRDSchemaEvaluator) HasField(crdName string, path ...string) (bool, error) {
+	crd, err := e.crdLister.Get(crdName)
+	if err != nil {
+		return false, err
+	}
+
+	key := strings.Join(path, ".")
+	e.lock.RLock()
+	result, ok := e.cache[crdName][key]
+	e.lock.RUnlock()
+	// the event handlers of the evaluator and of the controllers run in any order, a result
+	// is only reused for the version of the CRD it was looked up in
+	if ok && result.resourceVersion == crd.ResourceVersion {
+		return result.hasField, nil
+	}
+
+	hasField, err := crdSchemaHasField(crd, path)
+	if err != nil {
+		return false, err
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.cache[crdName] == nil {
+		e.cache[crdName] = map[string]crdSchemaResult{}
+	}
+	e.cache[crdName][key] = crdSchemaResult{resourceVersion: crd.ResourceVersion, hasField: hasField}
+	return hasField, nil
+}
+
+func (e *CRDSchemaEvaluator) invalidate(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition)
+	if !ok {
+		klog.Errorf("Unexpected type %T", obj)
+		return
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	delete(e.cache, crd.Name)
+}
+
+func crdSchemaHasField(crd *apiextensionsv1.CustomResourceDefinition, path []string) (bool, error) {
+	var v1Version *apiextensionsv1.CustomResourceDefinitionVersion
+	for i := range crd.Spec.Versions {
+		if version := crd.Spec.Versions[i]; version.Name == "v1" && version.Served && version.Storage {
+			v1Version = &crd.Spec.Versions[i]
+			break
+		}
+	}
+	if v1Version == nil || v1Version.Schema == nil || v1Version.Schema.OpenAPIV3Schema == nil {
+		return false, fmt.Errorf("%s is not served or stored as v1", crd.Name)
+	}
+
+	schema := *v1Version.Schema.OpenAPIV3Schema
+	for _, field := range path {
+		property, ok := schema.Properties[field]
+		if !ok {
+			return false, nil
+		}
+		schema = property
+	}
+	return true, nil
+}
@@ -0,0 +1,90 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsv1listers "k8s.io/apiextensions-apiserver/pkg/client/listers/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestCRDSchemaEvaluator(t *testing.T) {
+	const crdName = "authentications.config.openshift.io"
+	crd := func(resourceVersion string, served bool, statusFields ...string) *apiextensionsv1.CustomResourceDefinition {
+		status := apiextensionsv1.JSONSchemaProps{Properties: map[string]apiextensionsv1.JSONSchemaProps{}}
+		for _, field := range statusFields {
+			status.Properties[field] = apiextensionsv1.JSONSchemaProps{}
+		}
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: crdName, ResourceVersion: resourceVersion},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+					Name:    "v1",
+					Served:  served,
+					Storage: true,
+					Schema: &apiextensionsv1.CustomResourceValidation{
+						OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+							Properties: map[string]apiextensionsv1.JSONSchemaProps{"status": status},
+						},
+					},
+				}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		crds    []*apiextensionsv1.CustomResourceDefinition
+		want    []bool
+		wantErr bool
+	}{
+		{
+			name: "Test field in the schema",
+			crds: []*apiextensionsv1.CustomResourceDefinition{crd("1", true, "oidcClients")},
+			want: []bool{true},
+		},
+		{
+			name: "Test field added by a CRD update",
+			crds: []*apiextensionsv1.CustomResourceDefinition{crd("1", true), crd("2", true, "oidcClients")},
+			want: []bool{false, true},
+		},
+		{
+			name: "Test field removed by a CRD update",
+			crds: []*apiextensionsv1.CustomResourceDefinition{crd("1", true, "oidcClients"), crd("2", true)},
+			want: []bool{true, false},
+		},
+		{
+			name:    "Test v1 not served",
+			crds:    []*apiextensionsv1.CustomResourceDefinition{crd("1", false, "oidcClients")},
+			want:    []bool{false},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			evaluator := &CRDSchemaEvaluator{
+				crdLister: apiextensionsv1listers.NewCustomResourceDefinitionLister(indexer),
+				cache:     map[string]map[string]crdSchemaResult{},
+			}
+			got := []bool{}
+			for _, crd := range tt.crds {
+				if err := indexer.Update(crd); err != nil {
+					t.Fatal(err)
+				}
+				// the evaluator is not required to be notified before the next lookup
+				hasField, err := evaluator.HasField(crdName, "status", "oidcClients")
+				if (err != nil) != tt.wantErr {
+					t.Errorf("HasField() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				got = append(got, hasField)
+				evaluator.invalidate(crd)
+			}
+			if diff := deep.Equal(tt.want, got); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
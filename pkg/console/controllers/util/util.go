@@ -10,6 +10,9 @@ import (
 
 	//github
 
+	configv1lister "github.com/openshift/client-go/config/listers/config/v1"
+	"github.com/openshift/console-operator/pkg/api"
+	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
 	"github.com/openshift/library-go/pkg/controller/factory"
 )
 
@@ -36,6 +39,19 @@ func ExcludeNamesFilter(names ...string) factory.EventFilterFunc {
 	}
 }
 
+// OIDCClientSecretFilter returns a func which returns true if obj is the secret referenced
+// as the client secret of the console OIDC client
+func OIDCClientSecretFilter(authnLister configv1lister.AuthenticationLister) factory.EventFilterFunc {
+	return func(obj interface{}) bool {
+		authnConfig, err := authnLister.Get(api.ConfigResourceName)
+		if err != nil {
+			return false
+		}
+		clientConfig := utilsub.GetOIDCClientConfig(authnConfig)
+		return clientConfig != nil && len(clientConfig.ClientSecret.Name) > 0 && IncludeNamesFilter(clientConfig.ClientSecret.Name)(obj)
+	}
+}
+
 // Return a func which returns true if obj matches on every label in labels
 // (i.e for each key in labels map, obj.metadata.labels[key] is equal to labels[key])
 func LabelFilter(labels map[string]string) factory.EventFilterFunc {
@@ -0,0 +1,103 @@
+package validatingwebhook
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	// kube
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	admissionregistrationclientv1 "k8s.io/client-go/kubernetes/typed/admissionregistration/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorv1 "github.com/openshift/api/operator/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/bindata"
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/status"
+)
+
+// ValidatingWebhookController registers the webhook validating the customization of the
+// operator config, served by the console-conversion-webhook deployment. The webhook
+// configuration is cluster scoped and not watched, it is resynced every minute instead.
+//
+//	writes:
+//	- validatingwebhookconfigurations console-operator-validation
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=ValidatingWebhookSyncDegraded
+type ValidatingWebhookController struct {
+	operatorClient       v1helpers.OperatorClient
+	operatorConfigLister operatorv1listers.ConsoleLister
+	webhookClient        admissionregistrationclientv1.ValidatingWebhookConfigurationsGetter
+	resourceCache        resourceapply.ResourceCache
+}
+
+func NewValidatingWebhookController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	webhookClient admissionregistrationclientv1.ValidatingWebhookConfigurationsGetter,
+	// informers
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	ctrl := &ValidatingWebhookController{
+		operatorClient:       operatorClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		webhookClient:        webhookClient,
+		resourceCache:        resourceapply.NewResourceCache(),
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+		).ResyncEvery(time.Minute).WithSync(ctrl.Sync).
+		ToController("ValidatingWebhookController", recorder.WithComponentSuffix("validating-webhook-controller"))
+}
+
+func (c *ValidatingWebhookController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch consolecapability.ManagementState(operatorConfig) {
+	case operatorv1.Managed:
+		klog.V(4).Infoln("console is in a managed state: syncing validating webhook")
+	case operatorv1.Unmanaged:
+		klog.V(4).Infoln("console is in an unmanaged state: skipping validating webhook sync")
+		return nil
+	case operatorv1.Removed:
+		klog.V(4).Infoln("console is in a removed state: deleting validating webhook")
+		return c.removeValidatingWebhook(ctx)
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	required := resourceread.ReadValidatingWebhookConfigurationV1OrDie(bindata.MustAsset("assets/webhooks/console-operator-validation.yaml"))
+	_, _, applyErr := resourceapply.ApplyValidatingWebhookConfigurationImproved(ctx, c.webhookClient, controllerContext.Recorder(), required, c.resourceCache)
+	statusHandler.AddCondition(status.HandleDegraded("ValidatingWebhookSync", "FailedApply", applyErr))
+	return statusHandler.FlushAndReturn(applyErr)
+}
+
+func (c *ValidatingWebhookController) removeValidatingWebhook(ctx context.Context) error {
+	err := c.webhookClient.ValidatingWebhookConfigurations().Delete(ctx, api.ValidatingWebhookConfigurationName, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
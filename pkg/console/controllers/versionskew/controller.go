@@ -0,0 +1,187 @@
+package versionskew
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	// kube
+	"github.com/blang/semver"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	// openshift
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	configinformer "github.com/openshift/client-go/config/informers/externalversions"
+	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	operatorv1informers "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorv1listers "github.com/openshift/client-go/operator/listers/operator/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/operator/events"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	// console-operator
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/metrics"
+	"github.com/openshift/console-operator/pkg/console/status"
+	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+// supportedMinorSkew is how many minor versions the console may be away from the cluster,
+// the one minor step of an update in progress. Beyond that the console relies on APIs the
+// cluster no longer serves, or does not serve yet.
+const supportedMinorSkew = 1
+
+// VersionSkewController compares the release version the running console pods were rolled
+// out with against the cluster version, to catch consoles left behind by partial upgrades.
+//
+//	writes:
+//	- consoles.operator.openshift.io/cluster .status.conditions:
+//		- type=VersionSkewDegraded
+//	- metric console_operator_version_skew
+type VersionSkewController struct {
+	operatorClient       v1helpers.OperatorClient
+	operatorConfigLister operatorv1listers.ConsoleLister
+	clusterVersionLister configlistersv1.ClusterVersionLister
+	podLister            corev1listers.PodLister
+}
+
+func NewVersionSkewController(
+	// clients
+	operatorClient v1helpers.OperatorClient,
+	// informers
+	configInformer configinformer.SharedInformerFactory,
+	operatorConfigInformer operatorv1informers.ConsoleInformer,
+	targetNSPodInformer corev1informers.PodInformer,
+	// events
+	recorder events.Recorder,
+) factory.Controller {
+	configV1Informers := configInformer.Config().V1()
+
+	ctrl := &VersionSkewController{
+		operatorClient:       operatorClient,
+		operatorConfigLister: operatorConfigInformer.Lister(),
+		clusterVersionLister: configV1Informers.ClusterVersions().Lister(),
+		podLister:            targetNSPodInformer.Lister(),
+	}
+
+	return factory.New().
+		WithFilteredEventsInformers( // configs
+			util.IncludeNamesFilter(api.ConfigResourceName),
+			operatorConfigInformer.Informer(),
+		).WithFilteredEventsInformers( // cluster version
+		util.IncludeNamesFilter(api.VersionResourceName),
+		configV1Informers.ClusterVersions().Informer(),
+	).WithFilteredEventsInformers( // console pods
+		isConsolePod,
+		targetNSPodInformer.Informer(),
+	).ResyncEvery(10*time.Minute).WithSync(ctrl.Sync).
+		ToController("VersionSkewController", recorder.WithComponentSuffix("version-skew-controller"))
+}
+
+func (c *VersionSkewController) Sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	operatorConfig, err := c.operatorConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return err
+	}
+
+	switch consolecapability.ManagementState(operatorConfig) {
+	case operatorsv1.Managed:
+		klog.V(4).Infoln("console-operator is in a managed state: checking version skew")
+	case operatorsv1.Unmanaged:
+		klog.V(4).Infoln("console-operator is in an unmanaged state: skipping version skew check")
+		return nil
+	case operatorsv1.Removed:
+		klog.V(4).Infoln("console-operator is in a removed state: skipping version skew check")
+		return nil
+	default:
+		return fmt.Errorf("unknown state: %v", operatorConfig.Spec.ManagementState)
+	}
+
+	statusHandler := status.NewStatusHandler(c.operatorClient)
+	reason, err := c.checkVersionSkew()
+	statusHandler.AddCondition(status.HandleDegraded("VersionSkew", reason, err))
+	return statusHandler.FlushAndReturn(nil)
+}
+
+func (c *VersionSkewController) checkVersionSkew() (string, error) {
+	clusterVersionConfig, err := c.clusterVersionLister.Get(api.VersionResourceName)
+	if err != nil {
+		return "FailedGetClusterVersion", err
+	}
+	clusterVersion, err := semver.ParseTolerant(clusterVersionConfig.Status.Desired.Version)
+	if err != nil {
+		return "InvalidClusterVersion", fmt.Errorf("failed to parse cluster version %q: %w", clusterVersionConfig.Status.Desired.Version, err)
+	}
+
+	pods, err := c.podLister.Pods(api.OpenShiftConsoleNamespace).List(labels.SelectorFromSet(utilsub.LabelsForConsole()))
+	if err != nil {
+		return "FailedListPods", err
+	}
+	consoleVersion, skew, err := furthestConsoleVersion(pods, clusterVersion)
+	if err != nil {
+		return "UnsupportedVersionSkew", err
+	}
+	if consoleVersion == nil {
+		// no running pod was rolled out with a release version yet
+		return "", nil
+	}
+
+	metrics.HandleVersionSkew(consoleVersion.String(), clusterVersion.String(), skew)
+	if skew > supportedMinorSkew {
+		return "UnsupportedVersionSkew", fmt.Errorf("console pods run version %s, %d minor versions away from the cluster version %s, the supported skew is %d", consoleVersion, skew, clusterVersion, supportedMinorSkew)
+	}
+	return "", nil
+}
+
+// furthestConsoleVersion returns the version of the running console pods that is the most
+// minor versions away from clusterVersion. Pods that are terminating, not running or do not
+// carry a release version are ignored.
+func furthestConsoleVersion(pods []*corev1.Pod, clusterVersion semver.Version) (*semver.Version, uint64, error) {
+	var furthest *semver.Version
+	var furthestSkew uint64
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		annotation := pod.Annotations[api.ConsoleReleaseVersionAnnotation]
+		if len(annotation) == 0 {
+			continue
+		}
+		version, err := semver.ParseTolerant(annotation)
+		if err != nil {
+			klog.Warningf("ignoring pod %s with invalid %s %q: %v", pod.Name, api.ConsoleReleaseVersionAnnotation, annotation, err)
+			continue
+		}
+		if version.Major != clusterVersion.Major {
+			return nil, 0, fmt.Errorf("console pod %s runs version %s, a different major version than the cluster version %s", pod.Name, version, clusterVersion)
+		}
+		skew := minorSkew(version, clusterVersion)
+		if furthest == nil || skew > furthestSkew {
+			furthest = &version
+			furthestSkew = skew
+		}
+	}
+	return furthest, furthestSkew, nil
+}
+
+func minorSkew(a, b semver.Version) uint64 {
+	if a.Minor > b.Minor {
+		return a.Minor - b.Minor
+	}
+	return b.Minor - a.Minor
+}
+
+func isConsolePod(obj interface{}) bool {
+	pod, ok := obj.(metav1.Object)
+	if !ok {
+		return false
+	}
+	return labels.SelectorFromSet(utilsub.LabelsForConsole()).Matches(labels.Set(pod.GetLabels()))
+}
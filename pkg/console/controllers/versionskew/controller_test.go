@@ -0,0 +1,76 @@
+package versionskew
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/go-test/deep"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestFurthestConsoleVersion(t *testing.T) {
+	pod := func(name, version string, phase corev1.PodPhase) *corev1.Pod {
+		annotations := map[string]string{}
+		if len(version) > 0 {
+			annotations[api.ConsoleReleaseVersionAnnotation] = version
+		}
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	tests := []struct {
+		name        string
+		pods        []*corev1.Pod
+		wantVersion string
+		wantSkew    uint64
+		wantErr     bool
+	}{
+		{
+			name:        "Test pods on the cluster version",
+			pods:        []*corev1.Pod{pod("console-a", "4.16.3", corev1.PodRunning), pod("console-b", "4.16.3", corev1.PodRunning)},
+			wantVersion: "4.16.3",
+			wantSkew:    0,
+		},
+		{
+			name: "Test pod left behind by a partial upgrade",
+			pods: []*corev1.Pod{
+				pod("console-a", "4.16.3", corev1.PodRunning),
+				pod("console-b", "4.14.9", corev1.PodRunning),
+				pod("console-c", "4.13.0", corev1.PodPending),
+			},
+			wantVersion: "4.14.9",
+			wantSkew:    2,
+		},
+		{
+			name: "Test pods without a release version",
+			pods: []*corev1.Pod{pod("console-a", "", corev1.PodRunning), pod("console-b", "not-a-version", corev1.PodRunning)},
+		},
+		{
+			name:    "Test pod on another major version",
+			pods:    []*corev1.Pod{pod("console-a", "5.0.0", corev1.PodRunning)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, skew, err := furthestConsoleVersion(tt.pods, semver.MustParse("4.16.0"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("furthestConsoleVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			gotVersion := ""
+			if version != nil {
+				gotVersion = version.String()
+			}
+			if diff := deep.Equal(gotVersion, tt.wantVersion); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(skew, tt.wantSkew); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package errors
+
+// a config validation error means the rendered console-config would not be accepted by
+// the console. The configmap is not written, so the running pods keep the last valid config.
+type ConfigValidationError struct {
+	message string
+}
+
+// implement the error interface
+func (e *ConfigValidationError) Error() string {
+	return e.message
+}
+
+func NewConfigValidationError(msg string) *ConfigValidationError {
+	err := &ConfigValidationError{
+		message: msg,
+	}
+	return err
+}
+
+func IsConfigValidationError(err error) bool {
+	_, ok := err.(*ConfigValidationError)
+	return ok
+}
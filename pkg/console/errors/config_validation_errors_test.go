@@ -0,0 +1,37 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestIsConfigValidationError(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  error
+		output bool
+	}{
+		{
+			name:   "IsConfigValidationError returns true if passed a ConfigValidationError",
+			input:  NewConfigValidationError("servingInfo.bindAddress: Required value"),
+			output: true,
+		}, {
+			name:   "IsConfigValidationError returns false if passed a regular Error",
+			input:  fmt.Errorf("A regular error"),
+			output: false,
+		}, {
+			name:   "IsConfigValidationError returns false if passed nil",
+			input:  nil,
+			output: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(IsConfigValidationError(tt.input), tt.output); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package imageverification
+
+import (
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
+)
+
+// mirrorSource is a source repository of the ImageDigestMirrorSets and ImageContentSourcePolicies
+// matching an image, with the mirrors configured for it.
+type mirrorSource struct {
+	source             string
+	mirrors            []string
+	neverContactSource bool
+}
+
+// PullSpecs returns the pull specs the container runtime tries for image, a digest pinned reference,
+// on a cluster with the given ImageDigestMirrorSets and ImageContentSourcePolicies: the mirrors of the
+// most specific source matching the repository of the image, in order, then the image itself unless
+// a mirror set forbids contacting the source. An image that is not digest pinned is returned as is.
+func PullSpecs(image string, digestMirrorSets []*configv1.ImageDigestMirrorSet, contentSourcePolicies []*operatorv1alpha1.ImageContentSourcePolicy) []string {
+	ref, err := parseReference(image)
+	if err != nil {
+		return []string{image}
+	}
+
+	sources := []mirrorSource{}
+	for _, digestMirrorSet := range digestMirrorSets {
+		for _, m := range digestMirrorSet.Spec.ImageDigestMirrors {
+			mirrors := []string{}
+			for _, mirror := range m.Mirrors {
+				mirrors = append(mirrors, string(mirror))
+			}
+			sources = append(sources, mirrorSource{
+				source:             m.Source,
+				mirrors:            mirrors,
+				neverContactSource: m.MirrorSourcePolicy == configv1.NeverContactSource,
+			})
+		}
+	}
+	for _, contentSourcePolicy := range contentSourcePolicies {
+		for _, m := range contentSourcePolicy.Spec.RepositoryDigestMirrors {
+			sources = append(sources, mirrorSource{source: m.Source, mirrors: m.Mirrors})
+		}
+	}
+
+	name := ref.registry + "/" + ref.repository
+	best := ""
+	for _, s := range sources {
+		if _, ok := matchSource(name, s.source); ok && moreSpecific(s.source, best) {
+			best = s.source
+		}
+	}
+	if len(best) == 0 {
+		return []string{image}
+	}
+
+	pullSpecs := []string{}
+	contactSource := true
+	seen := map[string]bool{}
+	for _, s := range sources {
+		if s.source != best {
+			continue
+		}
+		matched, _ := matchSource(name, s.source)
+		for _, mirror := range s.mirrors {
+			pullSpec := mirror + strings.TrimPrefix(name, matched) + "@" + ref.digest
+			if !seen[pullSpec] {
+				seen[pullSpec] = true
+				pullSpecs = append(pullSpecs, pullSpec)
+			}
+		}
+		if s.neverContactSource && len(s.mirrors) > 0 {
+			contactSource = false
+		}
+	}
+	if contactSource {
+		pullSpecs = append(pullSpecs, image)
+	}
+	return pullSpecs
+}
+
+// matchSource returns the part of name, a registry/repository image name, that source matches:
+// the repository or one of its parent namespaces, or the registry host for a *.<domain> source.
+func matchSource(name, source string) (string, bool) {
+	if domain, ok := strings.CutPrefix(source, "*"); ok {
+		host, _, _ := strings.Cut(name, "/")
+		return host, strings.HasSuffix(host, domain)
+	}
+	return source, name == source || strings.HasPrefix(name, source+"/")
+}
+
+// moreSpecific is true when source is a more specific match than other: a repository or namespace
+// over a wildcard domain, and a longer one over a shorter one.
+func moreSpecific(source, other string) bool {
+	if len(other) == 0 {
+		return true
+	}
+	wildcard, otherWildcard := strings.HasPrefix(source, "*"), strings.HasPrefix(other, "*")
+	if wildcard != otherWildcard {
+		return otherWildcard
+	}
+	return len(source) > len(other)
+}
@@ -0,0 +1,122 @@
+package imageverification
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
+)
+
+func TestPullSpecs(t *testing.T) {
+	digest := "sha256:" + testImageHex
+	image := "quay.io/openshift-release-dev/ocp-v4.0-art-dev@" + digest
+	digestMirrorSet := func(policy configv1.MirrorSourcePolicy, source string, mirrors ...configv1.ImageMirror) *configv1.ImageDigestMirrorSet {
+		return &configv1.ImageDigestMirrorSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "mirrors"},
+			Spec: configv1.ImageDigestMirrorSetSpec{
+				ImageDigestMirrors: []configv1.ImageDigestMirrors{{Source: source, Mirrors: mirrors, MirrorSourcePolicy: policy}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name                  string
+		image                 string
+		digestMirrorSets      []*configv1.ImageDigestMirrorSet
+		contentSourcePolicies []*operatorv1alpha1.ImageContentSourcePolicy
+		want                  []string
+	}{
+		{
+			name:  "Test image without mirrors",
+			image: image,
+			want:  []string{image},
+		},
+		{
+			name:  "Test repository mirrors",
+			image: image,
+			digestMirrorSets: []*configv1.ImageDigestMirrorSet{
+				digestMirrorSet("", "quay.io/openshift-release-dev/ocp-v4.0-art-dev", "mirror.example.com/ocp/release", "backup.example.com/ocp/release"),
+			},
+			want: []string{
+				"mirror.example.com/ocp/release@" + digest,
+				"backup.example.com/ocp/release@" + digest,
+				image,
+			},
+		},
+		{
+			name:  "Test most specific namespace mirrors",
+			image: image,
+			digestMirrorSets: []*configv1.ImageDigestMirrorSet{
+				digestMirrorSet("", "*.io", "wildcard.example.com"),
+				digestMirrorSet("", "quay.io", "registry.example.com"),
+				digestMirrorSet("", "quay.io/openshift-release-dev", "mirror.example.com/ocp"),
+			},
+			want: []string{"mirror.example.com/ocp/ocp-v4.0-art-dev@" + digest, image},
+		},
+		{
+			name:  "Test wildcard domain mirrors",
+			image: image,
+			digestMirrorSets: []*configv1.ImageDigestMirrorSet{
+				digestMirrorSet("", "*.io", "mirror.example.com/io"),
+			},
+			want: []string{"mirror.example.com/io/openshift-release-dev/ocp-v4.0-art-dev@" + digest, image},
+		},
+		{
+			name:  "Test mirrors never contacting the source",
+			image: image,
+			digestMirrorSets: []*configv1.ImageDigestMirrorSet{
+				digestMirrorSet(configv1.NeverContactSource, "quay.io/openshift-release-dev", "mirror.example.com/ocp"),
+			},
+			want: []string{"mirror.example.com/ocp/ocp-v4.0-art-dev@" + digest},
+		},
+		{
+			name:  "Test image content source policy mirrors merged with the mirror sets",
+			image: image,
+			digestMirrorSets: []*configv1.ImageDigestMirrorSet{
+				digestMirrorSet("", "quay.io/openshift-release-dev", "mirror.example.com/ocp"),
+			},
+			contentSourcePolicies: []*operatorv1alpha1.ImageContentSourcePolicy{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "mirrors"},
+					Spec: operatorv1alpha1.ImageContentSourcePolicySpec{
+						RepositoryDigestMirrors: []operatorv1alpha1.RepositoryDigestMirrors{
+							{Source: "quay.io/openshift-release-dev", Mirrors: []string{"mirror.example.com/ocp", "backup.example.com/ocp"}},
+						},
+					},
+				},
+			},
+			want: []string{
+				"mirror.example.com/ocp/ocp-v4.0-art-dev@" + digest,
+				"backup.example.com/ocp/ocp-v4.0-art-dev@" + digest,
+				image,
+			},
+		},
+		{
+			name:  "Test mirrors of another repository",
+			image: image,
+			digestMirrorSets: []*configv1.ImageDigestMirrorSet{
+				digestMirrorSet("", "quay.io/openshift-release-dev/ocp-v4.0", "mirror.example.com/ocp"),
+			},
+			want: []string{image},
+		},
+		{
+			name:  "Test image referenced by tag",
+			image: "quay.io/openshift/console:latest",
+			digestMirrorSets: []*configv1.ImageDigestMirrorSet{
+				digestMirrorSet("", "quay.io", "mirror.example.com"),
+			},
+			want: []string{"quay.io/openshift/console:latest"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PullSpecs(tt.image, tt.digestMirrorSets, tt.contentSourcePolicies)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
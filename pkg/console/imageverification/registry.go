@@ -0,0 +1,227 @@
+package imageverification
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// signature manifests and payloads are small, anything bigger is not a cosign signature
+	maxResponseBytes = 4 << 20
+
+	manifestAcceptHeader = "application/vnd.oci.image.manifest.v1+json, application/vnd.docker.distribution.manifest.v2+json"
+)
+
+type httpDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// registryClient is a minimal read-only client of the registry v2 API, able to fetch
+// manifests and blobs anonymously or with the credentials of a pull secret.
+type registryClient struct {
+	client      httpDoer
+	credentials map[string]string
+}
+
+type manifest struct {
+	Layers []layer `json:"layers"`
+}
+
+type layer struct {
+	Digest      string            `json:"digest"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+type dockerConfigJSON struct {
+	Auths map[string]struct {
+		Auth string `json:"auth"`
+	} `json:"auths"`
+}
+
+func newRegistryClient(pullSecret []byte, client httpDoer) (*registryClient, error) {
+	credentials := map[string]string{}
+	if len(pullSecret) > 0 {
+		config := dockerConfigJSON{}
+		if err := json.Unmarshal(pullSecret, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse pull secret: %w", err)
+		}
+		for location, auth := range config.Auths {
+			location = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(location, "https://"), "http://"), "/")
+			credentials[location] = auth.Auth
+		}
+	}
+	return &registryClient{
+		client:      client,
+		credentials: credentials,
+	}, nil
+}
+
+// credentialsFor returns the base64 encoded user:password of the most specific pull secret
+// entry matching the repository, eg. quay.io/openshift over quay.io.
+func (r *registryClient) credentialsFor(ref reference) string {
+	location := ref.registry + "/" + ref.repository
+	for {
+		if auth, ok := r.credentials[location]; ok {
+			return auth
+		}
+		i := strings.LastIndex(location, "/")
+		if i < 0 {
+			return ""
+		}
+		location = location[:i]
+	}
+}
+
+func (r *registryClient) manifest(ctx context.Context, ref reference, tag string) (*manifest, error) {
+	body, err := r.get(ctx, ref, "manifests/"+tag, manifestAcceptHeader)
+	if err != nil {
+		return nil, err
+	}
+	m := &manifest{}
+	if err := json.Unmarshal(body, m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", tag, err)
+	}
+	return m, nil
+}
+
+func (r *registryClient) blob(ctx context.Context, ref reference, digest string) ([]byte, error) {
+	body, err := r.get(ctx, ref, "blobs/"+digest, "")
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(body)
+	if "sha256:"+hex.EncodeToString(sum[:]) != digest {
+		return nil, fmt.Errorf("blob %s does not match its digest", digest)
+	}
+	return body, nil
+}
+
+func (r *registryClient) get(ctx context.Context, ref reference, path, accept string) ([]byte, error) {
+	endpoint := fmt.Sprintf("https://%s/v2/%s/%s", ref.registry, ref.repository, path)
+	resp, err := r.do(ctx, endpoint, accept, "")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		authorization, err := r.authorize(ctx, ref, challenge)
+		if err != nil {
+			return nil, err
+		}
+		resp, err = r.do(ctx, endpoint, accept, authorization)
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned %s", endpoint, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+}
+
+func (r *registryClient) do(ctx context.Context, endpoint, accept, authorization string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(accept) > 0 {
+		req.Header.Set("Accept", accept)
+	}
+	if len(authorization) > 0 {
+		req.Header.Set("Authorization", authorization)
+	}
+	return r.client.Do(req)
+}
+
+// authorize answers a WWW-Authenticate challenge with an Authorization header value,
+// exchanging the pull secret credentials for a bearer token when the registry asks for one.
+func (r *registryClient) authorize(ctx context.Context, ref reference, challenge string) (string, error) {
+	credentials := r.credentialsFor(ref)
+	scheme, params, _ := strings.Cut(challenge, " ")
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if len(credentials) == 0 {
+			return "", fmt.Errorf("registry %s requires credentials", ref.registry)
+		}
+		return "Basic " + credentials, nil
+	case "bearer":
+	default:
+		return "", fmt.Errorf("registry %s requested unsupported authentication %q", ref.registry, scheme)
+	}
+
+	challengeParams := parseChallengeParams(params)
+	realm, err := url.Parse(challengeParams["realm"])
+	if err != nil || len(realm.Host) == 0 {
+		return "", fmt.Errorf("registry %s returned an invalid token realm %q", ref.registry, challengeParams["realm"])
+	}
+	query := realm.Query()
+	if service, ok := challengeParams["service"]; ok {
+		query.Set("service", service)
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:pull", ref.repository))
+	realm.RawQuery = query.Encode()
+
+	authorization := ""
+	if len(credentials) > 0 {
+		authorization = "Basic " + credentials
+	}
+	resp, err := r.do(ctx, realm.String(), "", authorization)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get a token for %s: %s", ref.registry, resp.Status)
+	}
+
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to parse token for %s: %w", ref.registry, err)
+	}
+	if len(token.Token) == 0 {
+		token.Token = token.AccessToken
+	}
+	if len(token.Token) == 0 {
+		return "", fmt.Errorf("registry %s returned an empty token", ref.registry)
+	}
+	return "Bearer " + token.Token, nil
+}
+
+// parseChallengeParams parses the comma separated key="value" pairs of a WWW-Authenticate challenge.
+func parseChallengeParams(params string) map[string]string {
+	parsed := map[string]string{}
+	for len(params) > 0 {
+		key, rest, found := strings.Cut(params, "=")
+		if !found {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(strings.TrimLeft(key, ", ")))
+		value := ""
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		parsed[key] = value
+		params = rest
+	}
+	return parsed
+}
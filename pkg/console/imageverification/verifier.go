@@ -0,0 +1,196 @@
+package imageverification
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// cosign stores the signatures of an image as layers of an OCI artifact tagged
+	// sha256-<image digest>.sig, next to the image in the same repository
+	cosignSignatureTagSuffix  = ".sig"
+	cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+)
+
+// Verifier checks the cosign signatures of digest pinned images against a set of public keys.
+type Verifier struct {
+	keys     []crypto.PublicKey
+	registry *registryClient
+}
+
+// NewVerifier returns a Verifier trusting keys. pullSecret is an optional .dockerconfigjson
+// used to authenticate against the registries hosting the signatures.
+func NewVerifier(keys []crypto.PublicKey, pullSecret []byte, client httpDoer) (*Verifier, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no public keys configured")
+	}
+	registry, err := newRegistryClient(pullSecret, client)
+	if err != nil {
+		return nil, err
+	}
+	return &Verifier{
+		keys:     keys,
+		registry: registry,
+	}, nil
+}
+
+// ParsePublicKeys parses every PEM encoded public key in data, typically the data of a ConfigMap.
+func ParsePublicKeys(data map[string]string) ([]crypto.PublicKey, error) {
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	keys := []crypto.PublicKey{}
+	for _, name := range names {
+		rest := []byte(data[name])
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			if block.Type != "PUBLIC KEY" {
+				continue
+			}
+			key, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse public key in %q: %w", name, err)
+			}
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no PEM encoded public keys found")
+	}
+	return keys, nil
+}
+
+// Verify succeeds if at least one cosign signature of image is signed by one of the
+// configured keys and covers the image digest. The signatures are read from the first of
+// pullSpecs serving them, the pull specs of image returned by PullSpecs, or from image itself
+// when there are none.
+func (v *Verifier) Verify(ctx context.Context, image string, pullSpecs []string) error {
+	if len(pullSpecs) == 0 {
+		pullSpecs = []string{image}
+	}
+
+	errs := []error{}
+	for _, pullSpec := range pullSpecs {
+		ref, err := parseReference(pullSpec)
+		if err != nil {
+			return err
+		}
+		signatureTag := strings.Replace(ref.digest, ":", "-", 1) + cosignSignatureTagSuffix
+		manifest, err := v.registry.manifest(ctx, ref, signatureTag)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		return v.verifySignatures(ctx, image, ref, manifest)
+	}
+	return fmt.Errorf("failed to get signatures of %s: %w", image, errors.Join(errs...))
+}
+
+func (v *Verifier) verifySignatures(ctx context.Context, image string, ref reference, manifest *manifest) error {
+	for _, layer := range manifest.Layers {
+		encodedSignature, ok := layer.Annotations[cosignSignatureAnnotation]
+		if !ok {
+			continue
+		}
+		signature, err := base64.StdEncoding.DecodeString(encodedSignature)
+		if err != nil {
+			continue
+		}
+		payload, err := v.registry.blob(ctx, ref, layer.Digest)
+		if err != nil {
+			return fmt.Errorf("failed to get signature payload of %s: %w", image, err)
+		}
+		if signedDigest(payload) != ref.digest {
+			continue
+		}
+		for _, key := range v.keys {
+			if verifySignature(key, payload, signature) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("no signature of %s is signed by a configured public key", image)
+}
+
+// simpleSigningPayload is the part of the cosign signature payload binding the signature to an image.
+type simpleSigningPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+func signedDigest(payload []byte) string {
+	signed := simpleSigningPayload{}
+	if err := json.Unmarshal(payload, &signed); err != nil {
+		return ""
+	}
+	return signed.Critical.Image.DockerManifestDigest
+}
+
+func verifySignature(key crypto.PublicKey, payload, signature []byte) bool {
+	digest := sha256.Sum256(payload)
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, digest[:], signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, payload, signature)
+	default:
+		return false
+	}
+}
+
+// reference is a digest pinned image reference, eg. quay.io/openshift/console@sha256:<hex>
+type reference struct {
+	registry   string
+	repository string
+	digest     string
+}
+
+func parseReference(image string) (reference, error) {
+	name, digest, found := strings.Cut(image, "@")
+	if !found {
+		return reference{}, fmt.Errorf("image %q is not referenced by digest", image)
+	}
+	hexDigest, found := strings.CutPrefix(digest, "sha256:")
+	if decoded, err := hex.DecodeString(hexDigest); !found || err != nil || len(decoded) != sha256.Size {
+		return reference{}, fmt.Errorf("image %q has an invalid sha256 digest", image)
+	}
+
+	registry, repository, found := strings.Cut(name, "/")
+	if !found || !strings.ContainsAny(registry, ".:") && registry != "localhost" {
+		return reference{}, fmt.Errorf("image %q does not include a registry host", image)
+	}
+	// a tag next to the digest is ignored, the digest is what gets pulled
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository = repository[:i]
+	}
+	return reference{
+		registry:   registry,
+		repository: repository,
+		digest:     digest,
+	}, nil
+}
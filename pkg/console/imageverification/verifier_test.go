@@ -0,0 +1,294 @@
+package imageverification
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+const (
+	testRepository = "openshift/console"
+	testImageHex   = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	testToken      = "test-token"
+)
+
+// fakeRegistry serves a cosign signature manifest and its payload blobs.
+type fakeRegistry struct {
+	manifest     []byte
+	blobs        map[string][]byte
+	requireToken bool
+	credentials  string
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/token" {
+		if r.Header.Get("Authorization") != "Basic "+f.credentials {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"token":%q}`, testToken)
+		return
+	}
+	if f.requireToken && r.Header.Get("Authorization") != "Bearer "+testToken {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="https://%s/token",service="fake"`, r.Host))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	prefix := "/v2/" + testRepository + "/"
+	switch {
+	case r.URL.Path == prefix+"manifests/sha256-"+testImageHex+".sig" && f.manifest != nil:
+		w.Write(f.manifest)
+	case strings.HasPrefix(r.URL.Path, prefix+"blobs/"):
+		blob, ok := f.blobs[strings.TrimPrefix(r.URL.Path, prefix+"blobs/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(blob)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func signedPayload(digest string) []byte {
+	return []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"quay.io/%s"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, testRepository, digest))
+}
+
+func signatureManifest(t *testing.T, key *ecdsa.PrivateKey, payloads ...[]byte) ([]byte, map[string][]byte) {
+	blobs := map[string][]byte{}
+	layers := []layer{}
+	for _, payload := range payloads {
+		digest := sha256.Sum256(payload)
+		signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		blobDigest := "sha256:" + hex.EncodeToString(digest[:])
+		blobs[blobDigest] = payload
+		layers = append(layers, layer{
+			Digest:      blobDigest,
+			Annotations: map[string]string{cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(signature)},
+		})
+	}
+	manifest, err := json.Marshal(map[string]interface{}{"schemaVersion": 2, "layers": layers})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return manifest, blobs
+}
+
+func generateKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func encodePublicKey(t *testing.T, key crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func TestVerify(t *testing.T) {
+	trustedKey := generateKey(t)
+	otherKey := generateKey(t)
+	imageDigest := "sha256:" + testImageHex
+	credentials := base64.StdEncoding.EncodeToString([]byte("user:password"))
+
+	tests := []struct {
+		name         string
+		signingKey   *ecdsa.PrivateKey
+		payloads     [][]byte
+		noSignatures bool
+		requireToken bool
+		pullSecret   string
+		image        func(host string) string
+		pullSpecs    func(host string) []string
+		wantErr      bool
+	}{
+		{
+			name:       "Test image signed by a trusted key",
+			signingKey: trustedKey,
+			payloads:   [][]byte{signedPayload(imageDigest)},
+			wantErr:    false,
+		},
+		{
+			name:       "Test one of several signatures signed by a trusted key",
+			signingKey: trustedKey,
+			payloads:   [][]byte{signedPayload("sha256:" + strings.Repeat("f", 64)), signedPayload(imageDigest)},
+			wantErr:    false,
+		},
+		{
+			name:       "Test image signed by an untrusted key",
+			signingKey: otherKey,
+			payloads:   [][]byte{signedPayload(imageDigest)},
+			wantErr:    true,
+		},
+		{
+			name:       "Test signature covering another image",
+			signingKey: trustedKey,
+			payloads:   [][]byte{signedPayload("sha256:" + strings.Repeat("f", 64))},
+			wantErr:    true,
+		},
+		{
+			name:         "Test unsigned image",
+			noSignatures: true,
+			wantErr:      true,
+		},
+		{
+			name:         "Test registry requiring a token",
+			signingKey:   trustedKey,
+			payloads:     [][]byte{signedPayload(imageDigest)},
+			requireToken: true,
+			pullSecret:   fmt.Sprintf(`{"auths":{"quay.io":{"auth":"invalid"},"%%s/openshift":{"auth":%q}}}`, credentials),
+			wantErr:      false,
+		},
+		{
+			name:         "Test registry requiring a token without credentials",
+			signingKey:   trustedKey,
+			payloads:     [][]byte{signedPayload(imageDigest)},
+			requireToken: true,
+			wantErr:      true,
+		},
+		{
+			name:       "Test image referenced by tag",
+			signingKey: trustedKey,
+			payloads:   [][]byte{signedPayload(imageDigest)},
+			image: func(host string) string {
+				return fmt.Sprintf("%s/%s:latest", host, testRepository)
+			},
+			wantErr: true,
+		},
+		{
+			name:       "Test signatures read from the first mirror serving them",
+			signingKey: trustedKey,
+			payloads:   [][]byte{signedPayload(imageDigest)},
+			image: func(host string) string {
+				return fmt.Sprintf("quay.io/%s@%s", testRepository, imageDigest)
+			},
+			pullSpecs: func(host string) []string {
+				return []string{
+					fmt.Sprintf("%s/unknown/console@%s", host, imageDigest),
+					fmt.Sprintf("%s/%s@%s", host, testRepository, imageDigest),
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name:       "Test signatures served by no mirror",
+			signingKey: trustedKey,
+			payloads:   [][]byte{signedPayload(imageDigest)},
+			pullSpecs: func(host string) []string {
+				return []string{fmt.Sprintf("%s/unknown/console@%s", host, imageDigest)}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := &fakeRegistry{
+				requireToken: tt.requireToken,
+				credentials:  credentials,
+			}
+			if !tt.noSignatures {
+				registry.manifest, registry.blobs = signatureManifest(t, tt.signingKey, tt.payloads...)
+			}
+			server := httptest.NewTLSServer(registry)
+			defer server.Close()
+			host := strings.TrimPrefix(server.URL, "https://")
+
+			keys, err := ParsePublicKeys(map[string]string{"key.pub": encodePublicKey(t, trustedKey.Public())})
+			if err != nil {
+				t.Fatal(err)
+			}
+			pullSecret := []byte(nil)
+			if len(tt.pullSecret) > 0 {
+				pullSecret = []byte(fmt.Sprintf(tt.pullSecret, host))
+			}
+			verifier, err := NewVerifier(keys, pullSecret, server.Client())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			image := fmt.Sprintf("%s/%s@%s", host, testRepository, imageDigest)
+			if tt.image != nil {
+				image = tt.image(host)
+			}
+			var pullSpecs []string
+			if tt.pullSpecs != nil {
+				pullSpecs = tt.pullSpecs(host)
+			}
+			err = verifier.Verify(context.TODO(), image, pullSpecs)
+			if diff := deep.Equal(err != nil, tt.wantErr); diff != nil {
+				t.Errorf("%v: %v", diff, err)
+			}
+		})
+	}
+}
+
+func TestParseReference(t *testing.T) {
+	digest := "sha256:" + testImageHex
+	tests := []struct {
+		name    string
+		image   string
+		want    reference
+		wantErr bool
+	}{
+		{
+			name:  "Test digest reference",
+			image: "quay.io/openshift-release-dev/ocp-v4.0-art-dev@" + digest,
+			want:  reference{registry: "quay.io", repository: "openshift-release-dev/ocp-v4.0-art-dev", digest: digest},
+		},
+		{
+			name:  "Test digest reference with a tag and a registry port",
+			image: "registry.example.com:5000/openshift/console:4.16@" + digest,
+			want:  reference{registry: "registry.example.com:5000", repository: "openshift/console", digest: digest},
+		},
+		{
+			name:    "Test tag reference",
+			image:   "quay.io/openshift/console:latest",
+			wantErr: true,
+		},
+		{
+			name:    "Test short digest",
+			image:   "quay.io/openshift/console@sha256:0123",
+			wantErr: true,
+		},
+		{
+			name:    "Test reference without registry host",
+			image:   "openshift/console@" + digest,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseReference(tt.image)
+			if diff := deep.Equal(err != nil, tt.wantErr); diff != nil {
+				t.Errorf("%v: %v", diff, err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package metrics
 
 import (
+	"strconv"
+	"time"
+
 	k8smetrics "k8s.io/component-base/metrics"
 	"k8s.io/component-base/metrics/legacyregistry"
 	"k8s.io/klog/v2"
@@ -22,10 +25,131 @@ var (
 		},
 		[]string{"major", "minor", "gitCommit", "gitVersion"},
 	)
+
+	consoleFIPSCompliance = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Name: "console_operator_fips_compliance",
+			Help: "Reports '1' if the console image, TLS configuration and running pods are FIPS compliant and '0' if not, labeled by whether the cluster is installed in FIPS mode.",
+		},
+		[]string{"fips_enabled"},
+	)
+
+	consoleVersionSkew = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Name: "console_operator_version_skew",
+			Help: "Number of minor versions between the running console pods and the cluster version, labeled by both versions.",
+		},
+		[]string{"console_version", "cluster_version"},
+	)
+
+	clusterProxyEndpointHealthy = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Name: "console_operator_cluster_proxy_endpoint_healthy",
+			Help: "Reports '1' if the cluster-proxy endpoint of a managed cluster answers the probes of the operator and '0' if not, labeled by cluster.",
+		},
+		[]string{"cluster"},
+	)
+
+	apiClientBudget = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Name: "console_operator_api_client_budget",
+			Help: "API budget configured for the clients of the operator, labeled by limit: qps, burst, watches or retries. Limits left to their defaults are not reported.",
+		},
+		[]string{"limit"},
+	)
+
+	apiClientRequests = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Name: "console_operator_api_client_requests_total",
+			Help: "Number of requests sent by the clients of the operator to the API server, labeled by method and response code.",
+		},
+		[]string{"method", "code"},
+	)
+
+	apiClientRateLimitedSeconds = k8smetrics.NewCounter(
+		&k8smetrics.CounterOpts{
+			Name: "console_operator_api_client_rate_limited_seconds_total",
+			Help: "Time the clients of the operator waited for the configured QPS and burst before sending their requests.",
+		},
+	)
+
+	apiClientRetries = k8smetrics.NewCounter(
+		&k8smetrics.CounterOpts{
+			Name: "console_operator_api_client_retries_total",
+			Help: "Number of requests the clients of the operator retried after the API server throttled or failed them.",
+		},
+	)
+
+	apiClientWatches = k8smetrics.NewGauge(
+		&k8smetrics.GaugeOpts{
+			Name: "console_operator_api_client_watches",
+			Help: "Number of watches the clients of the operator have open.",
+		},
+	)
+
+	apiClientWatchesRejected = k8smetrics.NewCounter(
+		&k8smetrics.CounterOpts{
+			Name: "console_operator_api_client_watches_rejected_total",
+			Help: "Number of watches the clients of the operator did not open because the configured watch budget was used up.",
+		},
+	)
+
+	oidcSetupSyncDuration = k8smetrics.NewHistogram(
+		&k8smetrics.HistogramOpts{
+			Name:    "console_operator_oidc_setup_sync_duration_seconds",
+			Help:    "Time the OIDC setup controller took to sync the console OIDC client.",
+			Buckets: k8smetrics.ExponentialBuckets(0.01, 2, 12),
+		},
+	)
+
+	oidcSetupSyncErrors = k8smetrics.NewCounter(
+		&k8smetrics.CounterOpts{
+			Name: "console_operator_oidc_setup_sync_errors_total",
+			Help: "Number of syncs of the OIDC setup controller that failed.",
+		},
+	)
+
+	authenticationType = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Name: "console_operator_authentication_type",
+			Help: "Reports '1' for the authentication type of the cluster: IntegratedOAuth, OIDC or None.",
+		},
+		[]string{"type"},
+	)
+
+	oidcClientState = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Name: "console_operator_oidc_client_state",
+			Help: "Reports '1' for the state of the console OIDC client: Available, Progressing, Degraded, Unavailable or Unknown. Not reported unless the authentication type is OIDC.",
+		},
+		[]string{"state"},
+	)
+
+	oauthClientDrift = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Name: "console_operator_oauth_client_drift_total",
+			Help: "Number of out of band changes to the console OAuthClient the operator detected, labeled by field: secret, additionalSecrets, redirectURIs, or deleted for the OAuthClient itself.",
+		},
+		[]string{"field"},
+	)
 )
 
 func init() {
 	legacyregistry.MustRegister(consoleURL)
+	legacyregistry.MustRegister(consoleFIPSCompliance)
+	legacyregistry.MustRegister(consoleVersionSkew)
+	legacyregistry.MustRegister(clusterProxyEndpointHealthy)
+	legacyregistry.MustRegister(apiClientBudget)
+	legacyregistry.MustRegister(apiClientRequests)
+	legacyregistry.MustRegister(apiClientRateLimitedSeconds)
+	legacyregistry.MustRegister(apiClientRetries)
+	legacyregistry.MustRegister(apiClientWatches)
+	legacyregistry.MustRegister(apiClientWatchesRejected)
+	legacyregistry.MustRegister(oidcSetupSyncDuration)
+	legacyregistry.MustRegister(oidcSetupSyncErrors)
+	legacyregistry.MustRegister(authenticationType)
+	legacyregistry.MustRegister(oidcClientState)
+	legacyregistry.MustRegister(oauthClientDrift)
 }
 
 func HandleConsoleURL(oldURL, newURL string) {
@@ -77,6 +201,101 @@ func RegisterVersion(major, minor, gitCommit, gitVersion string) {
 	consoleBuildInfo.WithLabelValues(major, minor, gitCommit, gitVersion).Set(1)
 }
 
+func HandleFIPSCompliance(fipsEnabled, compliant bool) {
+	defer recoverMetricPanic()
+	// only one series is exported at a time, drop the one for the other mode
+	consoleFIPSCompliance.Reset()
+	value := 0.0
+	if compliant {
+		value = 1
+	}
+	consoleFIPSCompliance.WithLabelValues(strconv.FormatBool(fipsEnabled)).Set(value)
+}
+
+func HandleVersionSkew(consoleVersion, clusterVersion string, skew uint64) {
+	defer recoverMetricPanic()
+	// drop the series of the versions we have rolled away from
+	consoleVersionSkew.Reset()
+	consoleVersionSkew.WithLabelValues(consoleVersion, clusterVersion).Set(float64(skew))
+}
+
+func HandleClusterProxyHealth(healthy map[string]bool) {
+	defer recoverMetricPanic()
+	// drop the series of the clusters removed from the cluster-proxy config
+	clusterProxyEndpointHealthy.Reset()
+	for cluster, isHealthy := range healthy {
+		value := 0.0
+		if isHealthy {
+			value = 1
+		}
+		clusterProxyEndpointHealthy.WithLabelValues(cluster).Set(value)
+	}
+}
+
+func HandleAPIClientBudget(limits map[string]float64) {
+	defer recoverMetricPanic()
+	apiClientBudget.Reset()
+	for limit, value := range limits {
+		apiClientBudget.WithLabelValues(limit).Set(value)
+	}
+}
+
+func HandleAPIClientRequest(method, code string) {
+	defer recoverMetricPanic()
+	apiClientRequests.WithLabelValues(method, code).Inc()
+}
+
+func HandleAPIClientRateLimited(waited time.Duration) {
+	defer recoverMetricPanic()
+	apiClientRateLimitedSeconds.Add(waited.Seconds())
+}
+
+func HandleAPIClientRetry() {
+	defer recoverMetricPanic()
+	apiClientRetries.Inc()
+}
+
+func HandleAPIClientWatches(open int64) {
+	defer recoverMetricPanic()
+	apiClientWatches.Set(float64(open))
+}
+
+func HandleAPIClientWatchRejected() {
+	defer recoverMetricPanic()
+	apiClientWatchesRejected.Inc()
+}
+
+func HandleOIDCSetupSync(duration time.Duration, err error) {
+	defer recoverMetricPanic()
+	oidcSetupSyncDuration.Observe(duration.Seconds())
+	if err != nil {
+		oidcSetupSyncErrors.Inc()
+	}
+}
+
+func HandleAuthenticationType(authType string) {
+	defer recoverMetricPanic()
+	// only one series is exported at a time, drop the one for the previous type
+	authenticationType.Reset()
+	authenticationType.WithLabelValues(authType).Set(1)
+}
+
+// HandleOIDCClientState reports the state of the console OIDC client, an empty state drops it.
+func HandleOIDCClientState(state string) {
+	defer recoverMetricPanic()
+	oidcClientState.Reset()
+	if len(state) > 0 {
+		oidcClientState.WithLabelValues(state).Set(1)
+	}
+}
+
+func HandleOAuthClientDrift(fields []string) {
+	defer recoverMetricPanic()
+	for _, field := range fields {
+		oauthClientDrift.WithLabelValues(field).Inc()
+	}
+}
+
 // We will never want to panic our operator because of metric saving.
 // Therefore, we will recover our panics here and error log them
 // for later diagnosis but will never fail the operator.
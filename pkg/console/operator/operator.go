@@ -4,6 +4,7 @@ import (
 	// standard lib
 	"context"
 	"fmt"
+	"net/http"
 	"syscall"
 	"time"
 
@@ -11,14 +12,15 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	corev1 "k8s.io/client-go/informers/core/v1"
 	appsclientv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
 	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
 	// openshift
@@ -32,16 +34,17 @@ import (
 	oauthlistersv1 "github.com/openshift/client-go/oauth/listers/oauth/v1"
 	operatorclientv1 "github.com/openshift/client-go/operator/clientset/versioned/typed/operator/v1"
 	operatorinformerv1 "github.com/openshift/client-go/operator/informers/externalversions/operator/v1"
+	operatorinformerv1alpha1 "github.com/openshift/client-go/operator/informers/externalversions/operator/v1alpha1"
 	operatorlistersv1 "github.com/openshift/client-go/operator/listers/operator/v1"
+	operatorlistersv1alpha1 "github.com/openshift/client-go/operator/listers/operator/v1alpha1"
 	routeclientv1 "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
 	routesinformersv1 "github.com/openshift/client-go/route/informers/externalversions/route/v1"
 	routev1listers "github.com/openshift/client-go/route/listers/route/v1"
 	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
 	"github.com/openshift/console-operator/pkg/console/controllers/util"
-	consolestatus "github.com/openshift/console-operator/pkg/console/status"
 	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/events"
-	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
 	"github.com/openshift/library-go/pkg/operator/resourcesynccontroller"
 	"github.com/openshift/library-go/pkg/operator/status"
 	"github.com/openshift/library-go/pkg/operator/v1helpers"
@@ -50,8 +53,9 @@ import (
 	// operator
 
 	"github.com/openshift/console-operator/pkg/console/subresource/configmap"
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
 	"github.com/openshift/console-operator/pkg/console/subresource/deployment"
-	"github.com/openshift/console-operator/pkg/console/subresource/secret"
+	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
 )
 
 type consoleOperator struct {
@@ -73,9 +77,13 @@ type consoleOperator struct {
 	managedNSConfigMapLister corev1listers.ConfigMapLister // for openshift-config-managed namespace
 	serviceClient            coreclientv1.ServicesGetter
 	nodeClient               coreclientv1.NodesGetter
+	nodeLister               corev1listers.NodeLister
+	podLister                corev1listers.PodLister
 	deploymentClient         appsclientv1.DeploymentsGetter
+	deploymentLister         appsv1listers.DeploymentLister
 	// openshift
 	configNSConfigMapLister corev1listers.ConfigMapLister //for openshift-config namespace
+	configNSSecretLister    corev1listers.SecretLister    //for openshift-config namespace
 	oauthClientLister       oauthlistersv1.OAuthClientLister
 	consoleOperatorLister   operatorlistersv1.ConsoleLister
 	routeClient             routeclientv1.RoutesGetter
@@ -83,11 +91,47 @@ type consoleOperator struct {
 	versionGetter           status.VersionGetter
 	// lister
 	consolePluginLister listerv1.ConsolePluginLister
+	// groups the group inactivity timeouts are validated against, nil when the cluster has no groups API
+	groupsLister cache.GenericLister
+	// mirrors the console image signatures are read from
+	imageDigestMirrorSetLister configlistersv1.ImageDigestMirrorSetLister
+	// nil when the cluster has no ImageContentSourcePolicy API
+	imageContentSourcePolicyLister operatorlistersv1alpha1.ImageContentSourcePolicyLister
 
 	resourceSyncer resourcesynccontroller.ResourceSyncer
 
 	// used to keep track of OLM capability
 	isOLMDisabled bool
+
+	// used to detect admission overriding the security contexts of the console pods
+	securityContextDrift      []string
+	securityContextDriftSyncs int
+
+	// client probing the auth server before a rollout and the CA bundles it trusts
+	authServerClient   *http.Client
+	authServerCABundle string
+
+	// last verification of the console image signature
+	imageVerification *imageVerificationResult
+
+	// what unsupportedConfigOverrides did to console-config on the last sync
+	configOverrides *consoleserver.OverridesResult
+}
+
+// imageVerificationKey is what a verification of the console image signature depends on
+type imageVerificationKey struct {
+	image             string
+	keysVersion       string
+	pullSecretVersion string
+	pullSpecs         string
+	caBundle          string
+}
+
+// imageVerificationResult is the outcome of a verification of the console image signature
+type imageVerificationResult struct {
+	key        imageVerificationKey
+	err        error
+	verifiedAt time.Time
 }
 
 func NewConsoleOperator(
@@ -101,6 +145,7 @@ func NewConsoleOperator(
 	operatorClient v1helpers.OperatorClient,
 	operatorConfigClient operatorclientv1.OperatorV1Interface,
 	operatorConfigInformer operatorinformerv1.ConsoleInformer,
+	imageContentSourcePolicyInformer operatorinformerv1alpha1.ImageContentSourcePolicyInformer,
 	// core resources
 	corev1Client coreclientv1.CoreV1Interface,
 	coreV1 corev1.Interface,
@@ -116,6 +161,7 @@ func NewConsoleOperator(
 	consolePluginInformer consoleinformersv1.ConsolePluginInformer,
 	// openshift
 	configNSConfigMapInformer corev1.ConfigMapInformer,
+	configNSSecretInformer corev1.SecretInformer,
 	// openshift managed
 	managedCoreV1 corev1.Interface,
 	// event handling
@@ -131,7 +177,7 @@ func NewConsoleOperator(
 	nodeInformer := coreV1.Nodes()
 	configV1Informers := configInformer.Config().V1()
 	configNameFilter := util.IncludeNamesFilter(api.ConfigResourceName)
-	targetNameFilter := util.IncludeNamesFilter(api.OpenShiftConsoleName)
+	targetNameFilter := util.IncludeNamesFilter(api.OpenShiftConsoleName, api.OpenShiftConsoleCanaryName, api.OpenShiftConsoleGreenName)
 
 	c := &consoleOperator{
 		// configs
@@ -144,6 +190,8 @@ func NewConsoleOperator(
 		proxyConfigLister:     configInformer.Config().V1().Proxies().Lister(),
 		oauthConfigLister:     configInformer.Config().V1().OAuths().Lister(),
 		authnConfigLister:     configV1Informers.Authentications().Lister(),
+		// image mirrors
+		imageDigestMirrorSetLister: configV1Informers.ImageDigestMirrorSets().Lister(),
 		// console resources
 		// core kube
 		secretsClient:   corev1Client,
@@ -152,11 +200,15 @@ func NewConsoleOperator(
 
 		targetNSConfigMapLister:  targetNSConfigMapInformer.Lister(),
 		configNSConfigMapLister:  configNSConfigMapInformer.Lister(),
+		configNSSecretLister:     configNSSecretInformer.Lister(),
 		managedNSConfigMapLister: managedNSConfigMapInformer.Lister(),
 
 		serviceClient:    corev1Client,
 		nodeClient:       corev1Client,
+		nodeLister:       nodeInformer.Lister(),
+		podLister:        coreV1.Pods().Lister(),
 		deploymentClient: deploymentClient,
+		deploymentLister: deploymentInformer.Lister(),
 		dynamicClient:    dynamicClient,
 		// openshift
 		oauthClientLister: oauthClientSwitchedInformer.Lister(),
@@ -193,6 +245,23 @@ func NewConsoleOperator(
 		c.startPollAndRestartIfResourceEnabled(olmGroupVersionResource)
 	}
 
+	groupInformers := []factory.Informer{}
+	if found, _ := isResourceEnabled(dynamicClient, groupsGroupVersionResource); found {
+		groupsInformer := dynamicInformers.ForResource(groupsGroupVersionResource)
+		c.groupsLister = groupsInformer.Lister()
+		groupInformers = append(groupInformers, groupsInformer.Informer())
+	} else {
+		klog.Info("groups resource does not exist in cluster, group inactivity timeouts are not validated")
+	}
+
+	mirrorInformers := []factory.Informer{configV1Informers.ImageDigestMirrorSets().Informer()}
+	if found, _ := isResourceEnabled(dynamicClient, imageContentSourcePoliciesGroupVersionResource); found {
+		c.imageContentSourcePolicyLister = imageContentSourcePolicyInformer.Lister()
+		mirrorInformers = append(mirrorInformers, imageContentSourcePolicyInformer.Informer())
+	} else {
+		klog.Info("imagecontentsourcepolicies resource does not exist in cluster, only image digest mirror sets are used to verify the console image")
+	}
+
 	return factory.New().
 		WithFilteredEventsInformers( // configs
 			configNameFilter,
@@ -214,8 +283,19 @@ func NewConsoleOperator(
 		factory.NamesFilter(api.OAuthClientName),
 		oauthClientSwitchedInformer.Informer(),
 	).WithFilteredEventsInformers(
-		util.IncludeNamesFilter(deployment.ConsoleOauthConfigName),
+		c.targetNSSecretFilter,
 		secretsInformer.Informer(),
+	).WithFilteredEventsInformers(
+		c.configNSConfigMapFilter,
+		configNSConfigMapInformer.Informer(),
+	).WithFilteredEventsInformers(
+		c.customizationBundleFilter(configmap.CustomizationBundleSecretKind),
+		configNSSecretInformer.Informer(),
+	).WithFilteredEventsInformers(
+		c.groupInactivityTimeoutsFilter,
+		groupInformers...,
+	).WithInformers(
+		mirrorInformers...,
 	).ResyncEvery(time.Minute).WithSync(c.Sync).
 		ToController("ConsoleOperator", recorder.WithComponentSuffix("console-operator"))
 }
@@ -261,6 +341,111 @@ func isResourceEnabled(client dynamic.Interface, resource schema.GroupVersionRes
 	return true, err
 }
 
+// telemetryConfigFilter passes the events of the openshift-config ConfigMap referenced by the
+// telemetry-config annotation, so that changes to it are rolled out without waiting for a resync.
+func (c *consoleOperator) telemetryConfigFilter(obj interface{}) bool {
+	operatorConfig, err := c.consoleOperatorLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return false
+	}
+	name, ok := operatorConfig.Annotations[api.TelemetryConfigAnnotation]
+	return ok && util.IncludeNamesFilter(name)(obj)
+}
+
+// customizationBundleFilter passes the events of the openshift-config ConfigMap or Secret, depending
+// on kind, referenced by the customization-bundle annotation.
+func (c *consoleOperator) customizationBundleFilter(kind string) factory.EventFilterFunc {
+	return func(obj interface{}) bool {
+		operatorConfig, err := c.consoleOperatorLister.Get(api.ConfigResourceName)
+		if err != nil {
+			return false
+		}
+		bundleKind, name, err := configmap.CustomizationBundleReference(operatorConfig)
+		return err == nil && bundleKind == kind && util.IncludeNamesFilter(name)(obj)
+	}
+}
+
+// groupInactivityTimeoutsFilter passes the events of the groups the group-inactivity-timeouts
+// annotation sets a timeout for, so that a group created or deleted later is picked up.
+func (c *consoleOperator) groupInactivityTimeoutsFilter(obj interface{}) bool {
+	operatorConfig, err := c.consoleOperatorLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return false
+	}
+	timeouts, _ := utilsub.GetGroupInactivityTimeouts(operatorConfig)
+	groups := []string{}
+	for group := range timeouts {
+		groups = append(groups, group)
+	}
+	return util.IncludeNamesFilter(groups...)(obj)
+}
+
+// clusterProxyConfigFilter passes the events of the openshift-config ConfigMap referenced by the
+// cluster-proxy-config annotation.
+func (c *consoleOperator) clusterProxyConfigFilter(obj interface{}) bool {
+	operatorConfig, err := c.consoleOperatorLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return false
+	}
+	name, ok := operatorConfig.Annotations[api.ClusterProxyConfigAnnotation]
+	return ok && util.IncludeNamesFilter(name)(obj)
+}
+
+// oidcCATrustFilter passes the events of the openshift-config ConfigMaps merged into the OIDC CA trust.
+func (c *consoleOperator) oidcCATrustFilter(obj interface{}) bool {
+	operatorConfig, err := c.consoleOperatorLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return false
+	}
+	authnConfig, err := c.authnConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return false
+	}
+	proxyConfig, err := c.proxyConfigLister.Get(api.ConfigResourceName)
+	if err != nil {
+		proxyConfig = nil
+	}
+	return util.IncludeNamesFilter(configmap.OIDCCATrustSources(operatorConfig, authnConfig, proxyConfig)...)(obj)
+}
+
+func (c *consoleOperator) configNSConfigMapFilter(obj interface{}) bool {
+	return c.telemetryConfigFilter(obj) ||
+		c.clusterProxyConfigFilter(obj) ||
+		c.oidcCATrustFilter(obj) ||
+		c.imageVerificationKeysFilter(obj) ||
+		c.customizationBundleFilter(configmap.CustomizationBundleConfigMapKind)(obj)
+}
+
+// imageVerificationKeysFilter passes the events of the openshift-config ConfigMap holding the keys
+// the console image signature is verified with.
+func (c *consoleOperator) imageVerificationKeysFilter(obj interface{}) bool {
+	operatorConfig, err := c.consoleOperatorLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return false
+	}
+	name, ok := operatorConfig.Annotations[api.ImageVerificationKeysAnnotation]
+	return ok && len(name) > 0 && util.IncludeNamesFilter(name)(obj)
+}
+
+// targetNSSecretFilter passes the events of the console namespace secrets the console is rolled
+// out with, including the ones mounted by the console-mounts annotation.
+func (c *consoleOperator) targetNSSecretFilter(obj interface{}) bool {
+	if util.IncludeNamesFilter(deployment.ConsoleOauthConfigName, api.ManagedClusterOAuthSecretName)(obj) {
+		return true
+	}
+	operatorConfig, err := c.consoleOperatorLister.Get(api.ConfigResourceName)
+	if err != nil {
+		return false
+	}
+	mounts, _ := deployment.GetConsoleMounts(operatorConfig)
+	for _, mount := range mounts {
+		if mount.Secret && util.IncludeNamesFilter(mount.Name)(obj) {
+			return true
+		}
+	}
+	return false
+}
+
 type configSet struct {
 	Console        *configv1.Console
 	Operator       *operatorsv1.Console
@@ -334,7 +519,7 @@ func (c *consoleOperator) Sync(ctx context.Context, controllerContext factory.Sy
 func (c *consoleOperator) handleSync(ctx context.Context, controllerContext factory.SyncContext, configs configSet) error {
 	updatedStatus := configs.Operator
 
-	switch updatedStatus.Spec.ManagementState {
+	switch consolecapability.ManagementState(updatedStatus) {
 	case operatorsv1.Managed:
 		klog.V(4).Infoln("console is in a managed state.")
 		// handled below
@@ -342,37 +527,11 @@ func (c *consoleOperator) handleSync(ctx context.Context, controllerContext fact
 		klog.V(4).Infoln("console is in an unmanaged state.")
 		return nil
 	case operatorsv1.Removed:
-		klog.V(4).Infoln("console has been removed.")
-		return c.removeConsole(ctx, updatedStatus, controllerContext.Recorder())
+		klog.V(4).Infoln("console has been removed: the teardown controller removes the console resources")
+		return nil
 	default:
 		return fmt.Errorf("console is in an unknown state: %v", updatedStatus.Spec.ManagementState)
 	}
 
 	return c.sync_v400(ctx, controllerContext, updatedStatus, configs)
 }
-
-// this may need to move to sync_v400 if versions ever have custom delete logic
-func (c *consoleOperator) removeConsole(ctx context.Context, operatorConfig *operatorsv1.Console, recorder events.Recorder) error {
-	klog.V(2).Info("deleting console resources")
-	defer klog.V(2).Info("finished deleting console resources")
-	var errs []error
-	// configmaps
-	errs = append(errs, c.configMapClient.ConfigMaps(api.TargetNamespace).Delete(ctx, configmap.Stub().Name, metav1.DeleteOptions{}))
-	errs = append(errs, c.configMapClient.ConfigMaps(api.TargetNamespace).Delete(ctx, configmap.ServiceCAStub().Name, metav1.DeleteOptions{}))
-	// secret
-	errs = append(errs, c.secretsClient.Secrets(api.TargetNamespace).Delete(ctx, secret.Stub().Name, metav1.DeleteOptions{}))
-
-	// deployment
-	// NOTE: CVO controls the deployment for downloads, console-operator cannot delete it.
-	errs = append(errs, c.deploymentClient.Deployments(api.TargetNamespace).Delete(ctx, deployment.Stub().Name, metav1.DeleteOptions{}))
-	// clear the console URL from the public config map in openshift-config-managed
-	_, _, updateConfigErr := resourceapply.ApplyConfigMap(ctx, c.configMapClient, recorder, configmap.EmptyPublicConfig())
-	errs = append(errs, updateConfigErr)
-
-	// filter out 404 errors, which indicate that resource is already deleted
-	err := utilerrors.FilterOut(utilerrors.NewAggregate(errs), apierrors.IsNotFound)
-
-	statusHandler := consolestatus.NewStatusHandler(c.operatorClient)
-	statusHandler.AddConditions(statusHandler.ResetConditions(operatorConfig.Status.Conditions))
-	return statusHandler.FlushAndReturn(err)
-}
@@ -2,9 +2,15 @@ package operator
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	// kube
 	appsv1 "k8s.io/api/apps/v1"
@@ -12,14 +18,19 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 
 	// openshift
 	configv1 "github.com/openshift/api/config/v1"
 	v1 "github.com/openshift/api/console/v1"
+	oauthv1 "github.com/openshift/api/oauth/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
 	routev1 "github.com/openshift/api/route/v1"
 	"github.com/openshift/console-operator/pkg/api"
 	"github.com/openshift/library-go/pkg/controller/factory"
@@ -27,12 +38,17 @@ import (
 	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
 	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
 	"github.com/openshift/library-go/pkg/operator/resourcesynccontroller"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
 
 	// operator
 	customerrors "github.com/openshift/console-operator/pkg/console/errors"
+	"github.com/openshift/console-operator/pkg/console/imageverification"
 	"github.com/openshift/console-operator/pkg/console/metrics"
 	"github.com/openshift/console-operator/pkg/console/status"
+	"github.com/openshift/console-operator/pkg/console/subresource/bluegreen"
+	"github.com/openshift/console-operator/pkg/console/subresource/canary"
 	configmapsub "github.com/openshift/console-operator/pkg/console/subresource/configmap"
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
 	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
 	oauthsub "github.com/openshift/console-operator/pkg/console/subresource/oauthclient"
 	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
@@ -40,6 +56,35 @@ import (
 	utilsub "github.com/openshift/console-operator/pkg/console/subresource/util"
 )
 
+// maxSecurityContextDriftSyncs is the number of consecutive syncs with console pods running other
+// security contexts than the rendered ones after which the operator reports the drift
+const maxSecurityContextDriftSyncs = 3
+
+// maxEventDiff bounds the size of the diffs included in events
+const maxEventDiff = 1024
+
+// imageVerificationTimeout bounds the registry requests made to verify the console image signature
+const imageVerificationTimeout = 30 * time.Second
+
+// imageVerificationRetryInterval is how long a failed verification of the console image is kept
+// before the registries are asked again for the same image, keys and pull secret
+const imageVerificationRetryInterval = 5 * time.Minute
+
+// authServerProbeTimeout bounds the probe of the auth server gating the console rollout
+const authServerProbeTimeout = 5 * time.Second
+
+var groupsGroupVersionResource = schema.GroupVersionResource{
+	Group:    "user.openshift.io",
+	Version:  "v1",
+	Resource: "groups",
+}
+
+var imageContentSourcePoliciesGroupVersionResource = schema.GroupVersionResource{
+	Group:    "operator.openshift.io",
+	Version:  "v1alpha1",
+	Resource: "imagecontentsourcepolicies",
+}
+
 // The sync loop starts from zero and works its way through the requirements for a running console.
 // If at any point something is missing, it creates/updates that piece and immediately dies.
 // The next loop will pick up where they previous left off and move the process forward one step.
@@ -80,14 +125,18 @@ func (co *consoleOperator) sync_v400(ctx context.Context, controllerContext fact
 		authServerCAConfig *corev1.ConfigMap
 		sessionSecret      *corev1.Secret
 	)
+	// only the OIDC issuer is trusted with the merged CA bundles
+	statusHandler.AddConditions(status.HandleProgressingOrDegraded("OIDCCATrustSync", "", nil))
 	switch authnConfig.Spec.Type {
 	case configv1.AuthenticationTypeOIDC:
-		if len(authnConfig.Spec.OIDCProviders) > 0 {
-			oidcProvider := authnConfig.Spec.OIDCProviders[0]
-			authServerCAConfig, err = co.configNSConfigMapLister.ConfigMaps(api.OpenShiftConfigNamespace).Get(oidcProvider.Issuer.CertificateAuthority.Name)
-			if err != nil && !apierrors.IsNotFound(err) {
-				return statusHandler.FlushAndReturn(err)
-			}
+		var (
+			oidcCATrustReason string
+			oidcCATrustErr    error
+		)
+		authServerCAConfig, oidcCATrustReason, oidcCATrustErr = co.SyncOIDCCATrustConfigMap(ctx, updatedOperatorConfig, authnConfig, set.Proxy, controllerContext.Recorder())
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("OIDCCATrustSync", oidcCATrustReason, oidcCATrustErr))
+		if oidcCATrustErr != nil {
+			return statusHandler.FlushAndReturn(oidcCATrustErr)
 		}
 
 		sessionSecret, err = co.syncSessionSecret(ctx, updatedOperatorConfig, controllerContext.Recorder())
@@ -96,18 +145,71 @@ func (co *consoleOperator) sync_v400(ctx context.Context, controllerContext fact
 		}
 	}
 
-	cm, cmChanged, cmErrReason, cmErr := co.SyncConfigMap(
+	// invalid overrides are left out of console-config, they do not block the sync
+	groupInactivityTimeouts, groupInactivityTimeoutsErr := co.GetGroupInactivityTimeouts(updatedOperatorConfig, authnConfig)
+	statusHandler.AddCondition(status.HandleDegraded("GroupInactivityTimeouts", "InvalidGroupInactivityTimeouts", groupInactivityTimeoutsErr))
+	contentSecurityPolicy, contentSecurityPolicyErr := utilsub.GetContentSecurityPolicy(updatedOperatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("ContentSecurityPolicy", "InvalidContentSecurityPolicy", contentSecurityPolicyErr))
+	_, accessLogErr := utilsub.GetAccessLogConfig(updatedOperatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("AccessLog", "InvalidAccessLog", accessLogErr))
+	_, rateLimitErr := utilsub.GetRateLimitConfig(updatedOperatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("RateLimit", "InvalidRateLimit", rateLimitErr))
+	_, apiClientErr := utilsub.GetAPIClientConfig(updatedOperatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("APIClient", "InvalidAPIClient", apiClientErr))
+	telemetryConfig, telemetryConfigErr := co.GetTelemetryConfig(updatedOperatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("TelemetryConfig", "InvalidTelemetryConfig", telemetryConfigErr))
+	clusterProxyClusters, clusterProxyConfigErr := co.GetClusterProxyConfig(updatedOperatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("ClusterProxyConfig", "InvalidClusterProxyConfig", clusterProxyConfigErr))
+	multicluster, managedClustersConfigErr := co.GetManagedClustersConfig()
+	statusHandler.AddCondition(status.HandleDegraded("ManagedClustersConfig", "InvalidManagedClustersConfig", managedClustersConfigErr))
+	managedClusterOAuthSecret, err := co.secretsLister.Secrets(api.TargetNamespace).Get(api.ManagedClusterOAuthSecretName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return statusHandler.FlushAndReturn(err)
+	}
+	if apierrors.IsNotFound(err) {
+		managedClusterOAuthSecret = nil
+	}
+	multicluster = configmapsub.WithManagedClusterOAuthClients(multicluster, managedClusterOAuthSecret)
+	managedClusterCABundle, err := co.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(api.ManagedClusterCABundleConfigMapName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return statusHandler.FlushAndReturn(err)
+	}
+	if apierrors.IsNotFound(err) {
+		managedClusterCABundle = nil
+	}
+	statusProvider, statusProviderErr := utilsub.GetStatusProviderConfig(updatedOperatorConfig, contentSecurityPolicy)
+	statusHandler.AddCondition(status.HandleDegraded("StatusProvider", "InvalidStatusProvider", statusProviderErr))
+	customizationBundle, customizationBundleErr := co.GetCustomizationBundle(updatedOperatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("CustomizationBundle", "InvalidCustomizationBundle", customizationBundleErr))
+	// spec.customization completed with the bundle, used to render the console resources only
+	renderedOperatorConfig := configmapsub.WithCustomizationBundle(set.Operator, customizationBundle)
+
+	cm, candidateConfigMap, cmChanged, cmErrReason, cmErr := co.SyncConfigMap(
 		ctx,
-		set.Operator,
+		renderedOperatorConfig,
 		set.Console,
 		set.Infrastructure,
 		set.OAuth,
 		authServerCAConfig,
+		sessionSecret,
 		authnConfig,
+		groupInactivityTimeouts,
+		contentSecurityPolicy,
+		telemetryConfig,
+		clusterProxyClusters,
+		multicluster,
+		statusProvider,
 		route,
 		controllerContext.Recorder(),
 	)
 	toUpdate = toUpdate || cmChanged
+	// an invalid config is not a sync failure, the current console-config is kept until it is fixed
+	if customerrors.IsConfigValidationError(cmErr) {
+		statusHandler.AddCondition(status.HandleDegraded("ConfigValidation", "InvalidConfig", cmErr))
+		return statusHandler.FlushAndReturn(cmErr)
+	}
+	statusHandler.AddCondition(status.HandleDegraded("ConfigValidation", "InvalidConfig", nil))
+	statusHandler.AddCondition(status.HandleDegraded("UnsupportedConfigOverrides", "ProtectedFieldsOverridden", co.configOverridesErr()))
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded("ConfigMapSync", cmErrReason, cmErr))
 	if cmErr != nil {
 		return statusHandler.FlushAndReturn(cmErr)
@@ -128,7 +230,7 @@ func (co *consoleOperator) sync_v400(ctx context.Context, controllerContext fact
 	}
 
 	// TODO: why is this missing a toUpdate change?
-	customLogoCanMount, customLogoErrReason, customLogoError := co.SyncCustomLogoConfigMap(ctx, updatedOperatorConfig)
+	customLogoCanMount, customLogoErrReason, customLogoError := co.SyncCustomLogoConfigMap(ctx, renderedOperatorConfig)
 	// If the custom logo sync fails for any reason, we are degraded, not progressing.
 	// The sync loop may not settle, we are unable to honor it in current state.
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded("CustomLogoSync", customLogoErrReason, customLogoError))
@@ -147,34 +249,148 @@ func (co *consoleOperator) sync_v400(ctx context.Context, controllerContext fact
 		if oauthServingCertErr != nil {
 			return statusHandler.FlushAndReturn(oauthServingCertErr)
 		}
+	default:
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthServingCertValidation", "", nil))
 	}
 
-	clientSecret, secErr := co.secretsLister.Secrets(api.TargetNamespace).Get(secretsub.Stub().Name)
-	statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretGet", "FailedGet", secErr))
-	if secErr != nil {
-		return statusHandler.FlushAndReturn(secErr)
+	// an OIDC client secret mounted from an external secret store is never copied into a Secret,
+	// a public OIDC client and a console without authentication have no client secret at all
+	oidcSecretProviderClass := utilsub.GetOIDCSecretProviderClass(updatedOperatorConfig, authnConfig)
+	noClientSecret := utilsub.IsOIDCPublicClient(updatedOperatorConfig, authnConfig) || utilsub.IsAuthenticationDisabled(authnConfig)
+	var clientSecret *corev1.Secret
+	if len(oidcSecretProviderClass) == 0 && !noClientSecret {
+		var secErr error
+		clientSecret, secErr = co.secretsLister.Secrets(api.TargetNamespace).Get(secretsub.Stub().Name)
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretGet", "FailedGet", secErr))
+		if secErr != nil {
+			return statusHandler.FlushAndReturn(secErr)
+		}
+	} else {
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("OAuthClientSecretGet", "", nil))
 	}
 
-	actualDeployment, depChanged, depErrReason, depErr := co.SyncDeployment(
-		ctx,
-		set.Operator,
+	// refuse to roll out a console image whose signature cannot be verified, the current pods keep running
+	imageVerificationErr := co.verifyConsoleImage(ctx, updatedOperatorConfig, trustedCAConfigMap)
+	statusHandler.AddCondition(status.HandleDegraded("ImageVerification", "ImageVerificationFailed", imageVerificationErr))
+	if imageVerificationErr != nil {
+		return statusHandler.FlushAndReturn(imageVerificationErr)
+	}
+
+	// impossible replicas keep the default replicas of the topology, invalid resources, probe,
+	// termination and rolling update parameters the default ones, invalid environment variables,
+	// topology spread constraints, node placement and pod template extensions are left out
+	_, replicasErr := deploymentsub.ConsoleReplicas(renderedOperatorConfig, set.Infrastructure)
+	statusHandler.AddCondition(status.HandleDegraded("ConsoleReplicas", "InvalidReplicas", replicasErr))
+	_, resourcesErr := deploymentsub.ConsoleResources(renderedOperatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("ConsoleResources", "InvalidResources", resourcesErr))
+	_, probesErr := deploymentsub.GetConsoleProbes(renderedOperatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("ConsoleProbes", "InvalidProbes", probesErr))
+	_, terminationErr := deploymentsub.GetGracefulTermination(renderedOperatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("ConsoleGracefulTermination", "InvalidGracefulTermination", terminationErr))
+	_, envErr := deploymentsub.GetConsoleEnv(renderedOperatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("ConsoleEnv", "InvalidConsoleEnv", envErr))
+	_, rollingUpdateErr := deploymentsub.GetRollingUpdate(renderedOperatorConfig, set.Infrastructure)
+	statusHandler.AddCondition(status.HandleDegraded("ConsoleRollingUpdate", "InvalidRollingUpdate", rollingUpdateErr))
+	_, topologySpreadErr := deploymentsub.GetTopologySpreadConstraints(renderedOperatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("ConsoleTopologySpread", "InvalidTopologySpread", topologySpreadErr))
+	// the node placement of the downloads pods is reported with the console one
+	_, nodePlacementErr := deploymentsub.GetNodePlacement(renderedOperatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("NodePlacement", "InvalidNodePlacement", nodePlacementErr))
+	// so is the priority class, an invalid one keeps the default priority
+	_, priorityClassErr := deploymentsub.GetPriorityClassName(renderedOperatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("PriorityClass", "InvalidPriorityClass", priorityClassErr))
+	_, podTemplateErr := deploymentsub.GetPodTemplateExtensions(renderedOperatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("PodTemplateExtensions", "InvalidPodTemplateExtensions", podTemplateErr))
+	// so are mounts of a missing configmap or secret, the console pods would not start
+	consoleMounts, consoleMountsErrReason, consoleMountsErr := co.GetConsoleMounts(renderedOperatorConfig)
+	statusHandler.AddCondition(status.HandleDegraded("ConsoleMounts", consoleMountsErrReason, consoleMountsErr))
+
+	// infra nodes are only looked for once the console is opted in to run on them
+	var nodes []*corev1.Node
+	if deploymentsub.IsInfraNodePlacementEnabled(renderedOperatorConfig) {
+		var nodesErr error
+		nodes, nodesErr = co.nodeLister.List(labels.Everything())
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("DeploymentSync", "FailedListNodes", nodesErr))
+		if nodesErr != nil {
+			return statusHandler.FlushAndReturn(nodesErr)
+		}
+	}
+
+	requiredDeployment := deploymentsub.DefaultDeployment(
+		renderedOperatorConfig,
 		cm,
 		serviceCAConfigMap,
 		oauthServingCertConfigMap,
 		authServerCAConfig,
 		trustedCAConfigMap,
 		clientSecret,
+		oidcSecretProviderClass,
+		noClientSecret,
 		sessionSecret,
+		managedClusterOAuthSecret,
+		managedClusterCABundle,
 		set.Proxy,
 		set.Infrastructure,
 		customLogoCanMount,
-		controllerContext.Recorder(),
 	)
+	deploymentsub.WithInfraNodePlacement(requiredDeployment, renderedOperatorConfig, nodes)
+	deploymentsub.WithConsoleMounts(requiredDeployment, consoleMounts)
+	_, infraNodePlacementErr := deploymentsub.GetInfraNodePlacement(renderedOperatorConfig, requiredDeployment, nodes)
+	statusHandler.AddCondition(status.HandleDegraded("InfraNodePlacement", "InsufficientInfraNodes", infraNodePlacementErr))
+	// the HorizontalPodAutoscaler owns the replicas of an autoscaled console
+	if deploymentsub.IsAutoscaled(renderedOperatorConfig, set.Infrastructure) {
+		existingDeployment, err := co.deploymentClient.Deployments(api.TargetNamespace).Get(ctx, api.OpenShiftConsoleDeploymentName, metav1.GetOptions{})
+		switch {
+		case err == nil:
+			deploymentsub.WithAutoscaledReplicas(requiredDeployment, existingDeployment, renderedOperatorConfig, set.Infrastructure)
+		case !apierrors.IsNotFound(err):
+			statusHandler.AddConditions(status.HandleProgressingOrDegraded("DeploymentSync", "FailedGet", err))
+			return statusHandler.FlushAndReturn(err)
+		}
+	}
+
+	// console pods crash-loop until they reach the auth server, after a cluster restart new pods
+	// wait for it to respond instead of flapping the operator status, the current ones keep running
+	liveDeployment, err := co.deploymentLister.Deployments(api.TargetNamespace).Get(api.OpenShiftConsoleDeploymentName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		statusHandler.AddConditions(status.HandleProgressingOrDegraded("DeploymentSync", "FailedGet", err))
+		return statusHandler.FlushAndReturn(err)
+	}
+	if apierrors.IsNotFound(err) {
+		liveDeployment = nil
+	}
+	var authServerReason string
+	var authServerErr error
+	if liveDeployment == nil || candidateConfigMap == nil && deploymentsub.PodTemplateChanged(requiredDeployment, liveDeployment) {
+		authServerReason, authServerErr = co.checkAuthServer(ctx, updatedOperatorConfig, set.Ingress, authnConfig, oauthServingCertConfigMap, authServerCAConfig, trustedCAConfigMap)
+	}
+	statusHandler.AddCondition(status.HandleProgressing("AuthServer", authServerReason, authServerErr))
+	if authServerErr != nil && liveDeployment == nil {
+		return statusHandler.FlushAndReturn(authServerErr)
+	}
+
+	var actualDeployment *appsv1.Deployment
+	var depChanged bool
+	var depErrReason string
+	var depErr error
+	switch {
+	case authServerErr != nil:
+		actualDeployment = liveDeployment
+	case candidateConfigMap == nil:
+		actualDeployment, depChanged, depErrReason, depErr = co.SyncDeployment(ctx, renderedOperatorConfig, requiredDeployment, controllerContext.Recorder())
+	default:
+		actualDeployment, depChanged, depErrReason, depErr = co.SyncHeldDeployment(ctx, renderedOperatorConfig, requiredDeployment, controllerContext.Recorder())
+	}
 	toUpdate = toUpdate || depChanged
 	statusHandler.AddConditions(status.HandleProgressingOrDegraded("DeploymentSync", depErrReason, depErr))
 	if depErr != nil {
 		return statusHandler.FlushAndReturn(depErr)
 	}
+	statusHandler.AddCondition(status.HandleDegraded("DeploymentSecurityContextDrift", "AdmissionMutation", co.securityContextDriftErr()))
+
+	// a paused console is scaled to zero on purpose, it is not reported as unavailable
+	paused := deploymentsub.IsPaused(updatedOperatorConfig)
+	statusHandler.AddCondition(handlePaused(paused))
 
 	statusHandler.UpdateDeploymentGeneration(actualDeployment)
 	statusHandler.UpdateReadyReplicas(actualDeployment.Status.ReadyReplicas)
@@ -189,7 +405,7 @@ func (co *consoleOperator) sync_v400(ctx context.Context, controllerContext fact
 			return errors.New("Changes made during sync updates, additional sync expected.")
 		}
 		version := os.Getenv("RELEASE_VERSION")
-		if !deploymentsub.IsAvailableAndUpdated(actualDeployment) {
+		if !paused && !deploymentsub.IsAvailableAndUpdated(actualDeployment) {
 			return errors.New(fmt.Sprintf("Working toward version %s, %v replicas available", version, actualDeployment.Status.AvailableReplicas))
 		}
 
@@ -201,7 +417,7 @@ func (co *consoleOperator) sync_v400(ctx context.Context, controllerContext fact
 
 	statusHandler.AddCondition(status.HandleAvailable(func() (prefix string, reason string, err error) {
 		prefix = "Deployment"
-		if !deploymentsub.IsAvailable(actualDeployment) {
+		if !paused && !deploymentsub.IsAvailable(actualDeployment) {
 			return prefix, "InsufficientReplicas", errors.New(fmt.Sprintf("%v replicas available for console deployment", actualDeployment.Status.ReadyReplicas))
 		}
 		return prefix, "", nil
@@ -256,38 +472,31 @@ func (co *consoleOperator) SyncConsoleConfig(ctx context.Context, consoleConfig
 
 func (co *consoleOperator) SyncConsolePublicConfig(ctx context.Context, consoleURL string, recorder events.Recorder) (*corev1.ConfigMap, bool, error) {
 	requiredConfigMap := configmapsub.DefaultPublicConfig(consoleURL)
-	return resourceapply.ApplyConfigMap(ctx, co.configMapClient, recorder, requiredConfigMap)
+	return co.applyConfigMap(ctx, co.managedNSConfigMapLister, requiredConfigMap, recorder)
+}
+
+// applyConfigMap applies a ConfigMap owned by the operator. Out of band changes it reverts are
+// reported with an event and recorded in the last-drift annotation, so that whoever keeps
+// fighting the operator can be found.
+func (co *consoleOperator) applyConfigMap(ctx context.Context, lister corev1listers.ConfigMapLister, required *corev1.ConfigMap, recorder events.Recorder) (*corev1.ConfigMap, bool, error) {
+	configmapsub.SetDataHash(required)
+	existing, err := lister.ConfigMaps(required.Namespace).Get(required.Name)
+	if err == nil {
+		if keys, diff := configmapsub.DataDrift(required, existing); len(keys) > 0 {
+			required.Annotations[api.ConfigMapLastDriftAnnotation] = fmt.Sprintf("%s %s", time.Now().UTC().Format(time.RFC3339), strings.Join(keys, ","))
+			recorder.Warningf("ConfigMapDriftReverted", "reverting out of band changes to %s/%s:\n%s", required.Namespace, required.Name, truncateDiff(diff))
+		}
+	}
+	return resourceapply.ApplyConfigMap(ctx, co.configMapClient, recorder, required)
 }
 
 func (co *consoleOperator) SyncDeployment(
 	ctx context.Context,
 	operatorConfig *operatorv1.Console,
-	cm *corev1.ConfigMap,
-	serviceCAConfigMap *corev1.ConfigMap,
-	oauthServingCertConfigMap *corev1.ConfigMap,
-	authServerCAConfigMap *corev1.ConfigMap,
-	trustedCAConfigMap *corev1.ConfigMap,
-	sec *corev1.Secret,
-	sessionSecret *corev1.Secret,
-	proxyConfig *configv1.Proxy,
-	infrastructureConfig *configv1.Infrastructure,
-	canMountCustomLogo bool,
+	requiredDeployment *appsv1.Deployment,
 	recorder events.Recorder,
 ) (consoleDeployment *appsv1.Deployment, changed bool, reason string, err error) {
 	updatedOperatorConfig := operatorConfig.DeepCopy()
-	requiredDeployment := deploymentsub.DefaultDeployment(
-		operatorConfig,
-		cm,
-		serviceCAConfigMap,
-		oauthServingCertConfigMap,
-		authServerCAConfigMap,
-		trustedCAConfigMap,
-		sec,
-		sessionSecret,
-		proxyConfig,
-		infrastructureConfig,
-		canMountCustomLogo,
-	)
 	genChanged := operatorConfig.ObjectMeta.Generation != operatorConfig.Status.ObservedGeneration
 
 	if genChanged {
@@ -306,9 +515,380 @@ func (co *consoleOperator) SyncDeployment(
 	if applyDepErr != nil {
 		return nil, false, "FailedApply", applyDepErr
 	}
+	co.trackSecurityContextDrift(requiredDeployment, deployment)
 	return deployment, deploymentChanged, "", nil
 }
 
+// SyncHeldDeployment leaves the console deployment as is while a blue/green rollout holds
+// console-config back, the console keeps serving the current config next to the green console.
+// A missing console deployment is created from the held console-config.
+func (co *consoleOperator) SyncHeldDeployment(
+	ctx context.Context,
+	operatorConfig *operatorv1.Console,
+	requiredDeployment *appsv1.Deployment,
+	recorder events.Recorder,
+) (consoleDeployment *appsv1.Deployment, changed bool, reason string, err error) {
+	consoleDeployment, err = co.deploymentClient.Deployments(api.TargetNamespace).Get(ctx, api.OpenShiftConsoleDeploymentName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return co.SyncDeployment(ctx, operatorConfig, requiredDeployment, recorder)
+	}
+	if err != nil {
+		return nil, false, "FailedGet", err
+	}
+	return consoleDeployment, false, "", nil
+}
+
+// removeCandidateConfigMap deletes the candidate console-config handed over to the blue/green
+// controller once console-config is no longer held back.
+func (co *consoleOperator) removeCandidateConfigMap(ctx context.Context) error {
+	if _, err := co.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(api.OpenShiftConsoleCandidateConfigMapName); err != nil {
+		return nil
+	}
+	err := co.configMapClient.ConfigMaps(api.TargetNamespace).Delete(ctx, api.OpenShiftConsoleCandidateConfigMapName, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// holdConsoleConfig is true while a blue/green rollout of the rendered console-config is not
+// promoted, the console keeps serving the existing console-config meanwhile.
+func (co *consoleOperator) holdConsoleConfig(operatorConfig *operatorv1.Console, existing, required *corev1.ConfigMap) bool {
+	if utilsub.GetBlueGreenConfig(operatorConfig) == nil || existing == nil {
+		return false
+	}
+	candidateConfig := bluegreen.CandidateConfig(required)
+	if string(candidateConfig) == string(bluegreen.CandidateConfig(existing)) {
+		return false
+	}
+	greenConfigMap, _ := co.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(api.OpenShiftConsoleGreenConfigMapName)
+	state := bluegreen.GetState(greenConfigMap)
+	return state.Phase != bluegreen.PhasePromoting || state.Candidate != bluegreen.CandidateHash(candidateConfig)
+}
+
+// promotedCanaryOverrides returns the candidate config overrides once their canary is promoted,
+// they are kept in console-config for as long as the canary annotations are set.
+func (co *consoleOperator) promotedCanaryOverrides(operatorConfig *operatorv1.Console) []byte {
+	canaryConfig := utilsub.GetCanaryConfig(operatorConfig)
+	if canaryConfig == nil {
+		return nil
+	}
+	canaryConfigMap, err := co.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(api.OpenShiftConsoleCanaryConfigMapName)
+	if err != nil {
+		return nil
+	}
+	state := canary.GetState(canaryConfigMap)
+	if state.Phase != canary.PhasePromoted || state.Candidate != canary.CandidateHash(canaryConfig.Overrides) {
+		return nil
+	}
+	return canaryConfig.Overrides
+}
+
+// trackSecurityContextDrift counts consecutive syncs in which the console pods run with security
+// contexts overriding the rendered ones. That means an SCC or an admission webhook mutates the
+// pods, which the deployment itself never shows. Pods are only compared once a rollout is done,
+// the pods of the previous revision were rendered from another deployment.
+func (co *consoleOperator) trackSecurityContextDrift(required, actual *appsv1.Deployment) {
+	if actual.Status.ObservedGeneration != actual.Generation || actual.Status.UpdatedReplicas != actual.Status.Replicas {
+		return
+	}
+	pods, err := co.podLister.Pods(api.OpenShiftConsoleNamespace).List(labels.SelectorFromSet(utilsub.LabelsForConsole()))
+	if err != nil {
+		klog.V(4).Infof("failed to list the console pods: %v", err)
+		return
+	}
+	drift := sets.NewString()
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, d := range deploymentsub.SecurityContextDrift(required, pod) {
+			drift.Insert(d)
+		}
+	}
+	if drift.Len() == 0 {
+		co.securityContextDrift = nil
+		co.securityContextDriftSyncs = 0
+		return
+	}
+	co.securityContextDrift = drift.List()
+	co.securityContextDriftSyncs++
+	klog.V(2).Infof("console pod security contexts overridden at admission (%d consecutive syncs): %s", co.securityContextDriftSyncs, strings.Join(co.securityContextDrift, ", "))
+}
+
+func (co *consoleOperator) securityContextDriftErr() error {
+	if co.securityContextDriftSyncs < maxSecurityContextDriftSyncs {
+		return nil
+	}
+	return fmt.Errorf("console pods run with security contexts other than the rendered ones, check the SCCs and admission webhooks matching the console pods: %s", strings.Join(co.securityContextDrift, ", "))
+}
+
+// trackConfigOverrides records an event with the rendered diff every time unsupportedConfigOverrides
+// change what ends up in console-config.
+func (co *consoleOperator) trackConfigOverrides(result *consoleserver.OverridesResult, recorder events.Recorder) {
+	if result == nil {
+		return
+	}
+	previousDiff := ""
+	if co.configOverrides != nil {
+		previousDiff = co.configOverrides.Diff
+	}
+	co.configOverrides = result
+	if result.Diff == previousDiff {
+		return
+	}
+	if len(result.Diff) == 0 {
+		recorder.Eventf("ConsoleConfigOverridesChanged", "unsupportedConfigOverrides no longer change console-config")
+		return
+	}
+	recorder.Eventf("ConsoleConfigOverridesChanged", "unsupportedConfigOverrides changed console-config:\n%s", truncateDiff(result.Diff))
+}
+
+// recordConfigChange reports a rewrite of console-config.yaml with its redacted diff, along with the
+// change chain link that identifies it in the console-config annotations.
+func recordConfigChange(recorder events.Recorder, cm *corev1.ConfigMap, diff string) {
+	link := cm.Annotations[api.ConfigMapChangeChainAnnotation]
+	if len(diff) == 0 {
+		recorder.Eventf("ConsoleConfigChanged", "console-config.yaml changed (change %s), only redacted fields differ", link)
+		return
+	}
+	recorder.Eventf("ConsoleConfigChanged", "console-config.yaml changed (change %s):\n%s", link, truncateDiff(diff))
+}
+
+func truncateDiff(diff string) string {
+	if len(diff) > maxEventDiff {
+		return diff[:maxEventDiff] + "\n... (truncated)"
+	}
+	return diff
+}
+
+func (co *consoleOperator) configOverridesErr() error {
+	if co.configOverrides == nil || len(co.configOverrides.ProtectedFields) == 0 {
+		return nil
+	}
+	return fmt.Errorf("unsupportedConfigOverrides of fields managed by the operator are ignored: %s", strings.Join(co.configOverrides.ProtectedFields, ", "))
+}
+
+// checkAuthServer probes the endpoints the console logs users in with, the reason it returns
+// names the auth server the rollout is waiting for. The OAuth server is trusted with the
+// oauth-serving-cert CA bundle, the OIDC providers like the console does, with the merged OIDC CA
+// trust and the trusted CA bundle.
+func (co *consoleOperator) checkAuthServer(ctx context.Context, operatorConfig *operatorv1.Console, ingressConfig *configv1.Ingress, authnConfig *configv1.Authentication, oauthServingCert, authServerCA, trustedCA *corev1.ConfigMap) (string, error) {
+	// invalid issuer endpoints are reported by the OIDC setup controller, those issuers are probed
+	// at their advertised URL like the console reaches them
+	issuerEndpoints, _ := utilsub.GetOIDCIssuerEndpoints(operatorConfig)
+	authServer, endpoints := authServerEndpoints(ingressConfig, authnConfig, issuerEndpoints)
+	if len(endpoints) == 0 {
+		return "", nil
+	}
+	caConfigMaps := []*corev1.ConfigMap{oauthServingCert}
+	if authnConfig.Spec.Type == configv1.AuthenticationTypeOIDC {
+		caConfigMaps = []*corev1.ConfigMap{authServerCA, trustedCA}
+	}
+	client := co.authServerProbeClient(caConfigMaps)
+
+	reason := "WaitingFor" + strings.ReplaceAll(authServer, " ", "")
+	for _, endpoint := range endpoints {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return reason, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return reason, fmt.Errorf("waiting for the %s at %s to respond: %w", authServer, endpoint, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return reason, fmt.Errorf("waiting for the %s at %s to respond: it returns '%s'", authServer, endpoint, resp.Status)
+		}
+	}
+	return "", nil
+}
+
+// authServerProbeClient returns the client probing the auth server, trusting the ca-bundle.crt of
+// caConfigMaps. It is kept across syncs so that its connections are reused, and only replaced when
+// the CA bundles change.
+func (co *consoleOperator) authServerProbeClient(caConfigMaps []*corev1.ConfigMap) *http.Client {
+	caBundles := []string{}
+	for _, caConfigMap := range caConfigMaps {
+		if caConfigMap != nil && len(caConfigMap.Data["ca-bundle.crt"]) > 0 {
+			caBundles = append(caBundles, caConfigMap.Data["ca-bundle.crt"])
+		}
+	}
+	caBundle := strings.Join(caBundles, "\n")
+	if co.authServerClient != nil && co.authServerCABundle == caBundle {
+		return co.authServerClient
+	}
+	if co.authServerClient != nil {
+		co.authServerClient.CloseIdleConnections()
+	}
+
+	tlsConfig := &tls.Config{}
+	if len(caBundle) > 0 {
+		caPool := x509.NewCertPool()
+		caPool.AppendCertsFromPEM([]byte(caBundle))
+		tlsConfig.RootCAs = caPool
+	}
+	co.authServerClient = &http.Client{
+		Timeout: authServerProbeTimeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+	}
+	co.authServerCABundle = caBundle
+	return co.authServerClient
+}
+
+// authServerEndpoints returns the auth server the console logs users in with and the endpoints
+// that answer once it is up, one per OIDC provider, none when the cluster has no auth server. An
+// OIDC provider is reached at its endpoint in issuerEndpoints, if any, rather than at its issuer URL.
+func authServerEndpoints(ingressConfig *configv1.Ingress, authnConfig *configv1.Authentication, issuerEndpoints map[string]string) (authServer string, endpoints []string) {
+	switch authnConfig.Spec.Type {
+	case "", configv1.AuthenticationTypeIntegratedOAuth:
+		return "OAuth Server", []string{fmt.Sprintf("https://%s/healthz", utilsub.GetOAuthServerHost(ingressConfig))}
+	case configv1.AuthenticationTypeOIDC:
+		for _, oidcProvider := range authnConfig.Spec.OIDCProviders {
+			endpoint := issuerEndpoints[oidcProvider.Name]
+			if len(endpoint) == 0 {
+				endpoint = oidcProvider.Issuer.URL
+			}
+			if len(endpoint) > 0 {
+				endpoints = append(endpoints, strings.TrimSuffix(endpoint, "/")+"/.well-known/openid-configuration")
+			}
+		}
+		if len(endpoints) == 0 {
+			return "", nil
+		}
+		return "OIDC Provider", endpoints
+	}
+	return "", nil
+}
+
+// verifyConsoleImage checks the cosign signature of the console image against the public keys in the
+// openshift-config ConfigMap named by the image-verification-keys annotation. Only images about to be
+// rolled out are checked, an image that is already deployed passes. The registries are trusted with
+// the trusted CA bundle, and the result is kept until the image, the keys, the pull secret, the
+// mirrors or the CA bundle change, a failure is retried after imageVerificationRetryInterval.
+func (co *consoleOperator) verifyConsoleImage(ctx context.Context, operatorConfig *operatorv1.Console, trustedCA *corev1.ConfigMap) error {
+	keysConfigMapName := operatorConfig.Annotations[api.ImageVerificationKeysAnnotation]
+	if len(keysConfigMapName) == 0 {
+		co.imageVerification = nil
+		return nil
+	}
+	image := utilsub.GetImageEnv("CONSOLE_IMAGE")
+
+	existingDeployment, err := co.deploymentLister.Deployments(api.TargetNamespace).Get(api.OpenShiftConsoleDeploymentName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err == nil && len(existingDeployment.Spec.Template.Spec.Containers) > 0 && existingDeployment.Spec.Template.Spec.Containers[0].Image == image {
+		return nil
+	}
+
+	keysConfigMap, err := co.configNSConfigMapLister.ConfigMaps(api.OpenShiftConfigNamespace).Get(keysConfigMapName)
+	if err != nil {
+		return fmt.Errorf("failed to get image verification keys: %w", err)
+	}
+	var pullSecret []byte
+	pullSecretVersion := ""
+	secret, err := co.configNSSecretLister.Secrets(api.OpenShiftConfigNamespace).Get(api.PullSecretName)
+	switch {
+	case err == nil:
+		pullSecret = secret.Data[corev1.DockerConfigJsonKey]
+		pullSecretVersion = secret.ResourceVersion
+	case !apierrors.IsNotFound(err):
+		return err
+	}
+	pullSpecs, err := co.consoleImagePullSpecs(image)
+	if err != nil {
+		return err
+	}
+	caBundle := ""
+	if trustedCA != nil {
+		caBundle = trustedCA.Data["ca-bundle.crt"]
+	}
+
+	key := imageVerificationKey{
+		image:             image,
+		keysVersion:       keysConfigMap.ResourceVersion,
+		pullSecretVersion: pullSecretVersion,
+		pullSpecs:         strings.Join(pullSpecs, ","),
+		caBundle:          caBundle,
+	}
+	if last := co.imageVerification; last != nil && last.key == key && (last.err == nil || time.Since(last.verifiedAt) < imageVerificationRetryInterval) {
+		return last.err
+	}
+
+	err = co.verifyImageSignature(ctx, image, pullSpecs, keysConfigMap, pullSecret, caBundle)
+	if err == nil {
+		klog.V(2).Infof("verified signature of console image %s", image)
+	}
+	co.imageVerification = &imageVerificationResult{key: key, err: err, verifiedAt: time.Now()}
+	return err
+}
+
+// verifyImageSignature verifies the signature of image, read through pullSpecs, with the keys of
+// keysConfigMap. The registries are reached through the cluster proxy and trusted with caBundle,
+// or with the system roots when it is empty.
+func (co *consoleOperator) verifyImageSignature(ctx context.Context, image string, pullSpecs []string, keysConfigMap *corev1.ConfigMap, pullSecret []byte, caBundle string) error {
+	keys, err := imageverification.ParsePublicKeys(keysConfigMap.Data)
+	if err != nil {
+		return fmt.Errorf("invalid image verification keys in configmap %s/%s: %w", keysConfigMap.Namespace, keysConfigMap.Name, err)
+	}
+	tlsConfig := &tls.Config{}
+	if len(caBundle) > 0 {
+		caPool := x509.NewCertPool()
+		caPool.AppendCertsFromPEM([]byte(caBundle))
+		tlsConfig.RootCAs = caPool
+	}
+	transport := &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+	}
+	defer transport.CloseIdleConnections()
+	verifier, err := imageverification.NewVerifier(keys, pullSecret, &http.Client{Timeout: imageVerificationTimeout, Transport: transport})
+	if err != nil {
+		return err
+	}
+	return verifier.Verify(ctx, image, pullSpecs)
+}
+
+// consoleImagePullSpecs returns the pull specs the console image is pulled through on the cluster,
+// its mirrors first, so that the signatures are read from where the image is.
+func (co *consoleOperator) consoleImagePullSpecs(image string) ([]string, error) {
+	digestMirrorSets, err := co.imageDigestMirrorSetLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	contentSourcePolicies := []*operatorv1alpha1.ImageContentSourcePolicy{}
+	if co.imageContentSourcePolicyLister != nil {
+		contentSourcePolicies, err = co.imageContentSourcePolicyLister.List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return imageverification.PullSpecs(image, digestMirrorSets, contentSourcePolicies), nil
+}
+
+// handlePaused reports in the ConsolePaused condition whether the console is scaled to zero by
+// the paused annotation.
+func handlePaused(paused bool) status.ConditionUpdate {
+	condition := operatorv1.OperatorCondition{
+		Type:   "ConsolePaused",
+		Status: operatorv1.ConditionFalse,
+	}
+	if paused {
+		condition.Status = operatorv1.ConditionTrue
+		condition.Reason = "ScaledToZero"
+		condition.Message = fmt.Sprintf("The console and downloads deployments are scaled to zero, remove the %s annotation to restore them.", api.PausedAnnotation)
+	}
+	return status.ConditionUpdate{
+		ConditionType:  condition.Type,
+		StatusUpdateFn: v1helpers.UpdateConditionFn(condition),
+	}
+}
+
 // apply configmap (needs route)
 // by the time we get to the configmap, we can assume the route exits & is configured properly
 // therefore no additional error handling is needed here.
@@ -319,48 +899,50 @@ func (co *consoleOperator) SyncConfigMap(
 	infrastructureConfig *configv1.Infrastructure,
 	oauthConfig *configv1.OAuth,
 	authServerCAConfig *corev1.ConfigMap,
+	sessionSecret *corev1.Secret,
 	authConfig *configv1.Authentication,
+	groupInactivityTimeouts map[string]int,
+	contentSecurityPolicy map[string][]string,
+	telemetryConfig map[string]string,
+	clusterProxyClusters []configmapsub.ClusterProxyCluster,
+	multicluster *configmapsub.MulticlusterConfig,
+	statusProvider *utilsub.StatusProviderConfig,
 	activeConsoleRoute *routev1.Route,
 	recorder events.Recorder,
-) (consoleConfigMap *corev1.ConfigMap, changed bool, reason string, err error) {
+) (consoleConfigMap *corev1.ConfigMap, candidateConfigMap *corev1.ConfigMap, changed bool, reason string, err error) {
 
 	managedConfig, mcErr := co.managedNSConfigMapLister.ConfigMaps(api.OpenShiftConfigManagedNamespace).Get(api.OpenShiftConsoleConfigMapName)
 	if mcErr != nil {
 		if !apierrors.IsNotFound(mcErr) {
-			return nil, false, "FailedGetManagedConfig", mcErr
+			return nil, nil, false, "FailedGetManagedConfig", mcErr
 		}
 		managedConfig = &corev1.ConfigMap{}
 	}
 
 	nodeList, nodeListErr := co.nodeClient.Nodes().List(ctx, metav1.ListOptions{})
 	if nodeListErr != nil {
-		return nil, false, "FailedListNodes", nodeListErr
+		return nil, nil, false, "FailedListNodes", nodeListErr
 	}
 	nodeArchitectures, nodeOperatingSystems := getNodeComputeEnvironments(nodeList)
 
 	// TODO: currently there's no way to get this for authentication type OIDC
-	inactivityTimeoutSeconds := 0
+	var oauthClient *oauthv1.OAuthClient
 	switch authConfig.Spec.Type {
 	case "", configv1.AuthenticationTypeIntegratedOAuth:
-		oauthClient, oacErr := co.oauthClientLister.Get(oauthsub.Stub().Name)
+		var oacErr error
+		oauthClient, oacErr = co.oauthClientLister.Get(oauthsub.Stub().Name)
 		if oacErr != nil {
-			return nil, false, "FailedGetOAuthClient", oacErr
-		}
-		if oauthClient.AccessTokenInactivityTimeoutSeconds != nil {
-			inactivityTimeoutSeconds = int(*oauthClient.AccessTokenInactivityTimeoutSeconds)
-		} else {
-			if oauthConfig.Spec.TokenConfig.AccessTokenInactivityTimeout != nil {
-				inactivityTimeoutSeconds = int(oauthConfig.Spec.TokenConfig.AccessTokenInactivityTimeout.Seconds())
-			}
+			return nil, nil, false, "FailedGetOAuthClient", oacErr
 		}
 	}
+	sessionPolicy := utilsub.GetSessionPolicy(authConfig, oauthConfig, oauthClient)
 
 	availablePlugins := co.GetAvailablePlugins(operatorConfig.Spec.Plugins)
 
 	monitoringSharedConfig, mscErr := co.managedNSConfigMapLister.ConfigMaps(api.OpenShiftConfigManagedNamespace).Get(api.OpenShiftMonitoringConfigMapName)
 	if mscErr != nil {
 		if !apierrors.IsNotFound(mscErr) {
-			return nil, false, "FailedGetMonitoringSharedConfig", mscErr
+			return nil, nil, false, "FailedGetMonitoringSharedConfig", mscErr
 		}
 		monitoringSharedConfig = &corev1.ConfigMap{}
 	}
@@ -372,37 +954,183 @@ func (co *consoleOperator) SyncConfigMap(
 	if !co.isOLMDisabled {
 		copiedCSVsDisabled, ccdErr = co.isCopiedCSVsDisabled(ctx)
 		if ccdErr != nil {
-			return nil, false, "FailedGetOLMConfig", ccdErr
+			return nil, nil, false, "FailedGetOLMConfig", ccdErr
 		}
 	}
 
-	defaultConfigmap, _, err := configmapsub.DefaultConfigMap(
+	defaultConfigmap, overridesResult, err := configmapsub.DefaultConfigMap(
 		operatorConfig,
 		consoleConfig,
 		authConfig,
 		authServerCAConfig,
+		sessionSecret,
 		managedConfig,
 		monitoringSharedConfig,
 		infrastructureConfig,
 		activeConsoleRoute,
-		inactivityTimeoutSeconds,
+		sessionPolicy,
+		groupInactivityTimeouts,
+		contentSecurityPolicy,
+		telemetryConfig,
+		clusterProxyClusters,
+		multicluster,
+		statusProvider,
+		co.promotedCanaryOverrides(operatorConfig),
 		availablePlugins,
 		nodeArchitectures,
 		nodeOperatingSystems,
 		copiedCSVsDisabled,
 	)
+	co.trackConfigOverrides(overridesResult, recorder)
 	if err != nil {
-		return nil, false, "FailedConsoleConfigBuilder", err
+		return nil, nil, false, "FailedConsoleConfigBuilder", err
+	}
+	existingConfigMap, _ := co.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(api.OpenShiftConsoleConfigMapName)
+	// a blue/green rollout keeps console-config as is until the candidate config is promoted
+	if co.holdConsoleConfig(operatorConfig, existingConfigMap, defaultConfigmap) {
+		klog.V(4).Infof("console-config is held back by a blue/green rollout")
+		if _, _, err := co.applyConfigMap(ctx, co.targetNSConfigMapLister, bluegreen.CandidateConfigMap(operatorConfig, defaultConfigmap), recorder); err != nil {
+			return nil, nil, false, "FailedApply", err
+		}
+		return existingConfigMap, defaultConfigmap, false, "", nil
+	}
+	if err := co.removeCandidateConfigMap(ctx); err != nil {
+		return nil, nil, false, "FailedDelete", err
 	}
-	cm, cmChanged, cmErr := resourceapply.ApplyConfigMap(ctx, co.configMapClient, recorder, defaultConfigmap)
+	configDiff, configChanged := configmapsub.ConfigChange(existingConfigMap, defaultConfigmap)
+	if existingConfigMap != nil {
+		if previous, ok := existingConfigMap.Annotations[api.SessionPolicyAnnotation]; ok && previous != sessionPolicy.String() {
+			recorder.Eventf("ConsoleSessionPolicyChanged", "OAuth token policy changed from %s to %s, rolling out the console", previous, sessionPolicy)
+		}
+	}
+	cm, cmChanged, cmErr := co.applyConfigMap(ctx, co.targetNSConfigMapLister, defaultConfigmap, recorder)
 	if cmErr != nil {
-		return nil, false, "FailedApply", cmErr
+		return nil, nil, false, "FailedApply", cmErr
+	}
+	if configChanged {
+		recordConfigChange(recorder, cm, configDiff)
 	}
 	if cmChanged {
 		klog.V(4).Infoln("new console config yaml:")
 		klog.V(4).Infof("%s", cm.Data)
 	}
-	return cm, cmChanged, "ConsoleConfigBuilder", cmErr
+	return cm, nil, cmChanged, "ConsoleConfigBuilder", cmErr
+}
+
+// GetTelemetryConfig returns the valid entries of the openshift-config ConfigMap referenced by
+// the telemetry-config annotation. Changes to the ConfigMap end up in console-config and roll
+// out the console.
+func (co *consoleOperator) GetTelemetryConfig(operatorConfig *operatorv1.Console) (map[string]string, error) {
+	name, ok := operatorConfig.Annotations[api.TelemetryConfigAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	telemetryConfigMap, err := co.configNSConfigMapLister.ConfigMaps(api.OpenShiftConfigNamespace).Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get telemetry config %s/%s: %w", api.OpenShiftConfigNamespace, name, err)
+	}
+	return configmapsub.ValidateTelemetryConfig(telemetryConfigMap.Data)
+}
+
+// GetClusterProxyConfig returns the valid managed clusters of the openshift-config ConfigMap
+// referenced by the cluster-proxy-config annotation, along with the health the cluster proxy
+// health controller last probed them with.
+func (co *consoleOperator) GetClusterProxyConfig(operatorConfig *operatorv1.Console) ([]configmapsub.ClusterProxyCluster, error) {
+	name, ok := operatorConfig.Annotations[api.ClusterProxyConfigAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	clusterProxyConfigMap, err := co.configNSConfigMapLister.ConfigMaps(api.OpenShiftConfigNamespace).Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster-proxy config %s/%s: %w", api.OpenShiftConfigNamespace, name, err)
+	}
+	clusters, err := configmapsub.ValidateClusterProxyConfig(clusterProxyConfigMap.Data)
+	healthConfigMap, healthErr := co.targetNSConfigMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(api.ClusterProxyHealthConfigMapName)
+	if healthErr != nil {
+		// not probed yet, every cluster is rendered
+		return clusters, err
+	}
+	health, healthErr := configmapsub.ReadClusterProxyHealth(healthConfigMap.Data)
+	if healthErr != nil {
+		klog.V(4).Infof("ignoring cluster-proxy health: %v", healthErr)
+		return clusters, err
+	}
+	return configmapsub.WithClusterProxyHealth(clusters, health), err
+}
+
+// GetManagedClustersConfig returns the hub and the valid clusters of the managed-clusters ConfigMap
+// kept up to date by the managed cluster controller, nil on clusters that are not a hub.
+func (co *consoleOperator) GetManagedClustersConfig() (*configmapsub.MulticlusterConfig, error) {
+	managedClustersConfigMap, err := co.targetNSConfigMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(api.ManagedClustersConfigMapName)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get managed clusters config %s/%s: %w", api.OpenShiftConsoleNamespace, api.ManagedClustersConfigMapName, err)
+	}
+	return configmapsub.ValidateManagedClustersConfig(managedClustersConfigMap.Data)
+}
+
+// GetCustomizationBundle unpacks the openshift-config ConfigMap or Secret referenced by the
+// customization-bundle annotation. An invalid bundle is left out, spec.customization still applies.
+func (co *consoleOperator) GetCustomizationBundle(operatorConfig *operatorv1.Console) (*configmapsub.CustomizationBundle, error) {
+	kind, name, err := configmapsub.CustomizationBundleReference(operatorConfig)
+	if err != nil || len(name) == 0 {
+		return nil, err
+	}
+	data := map[string][]byte{}
+	switch kind {
+	case configmapsub.CustomizationBundleSecretKind:
+		secret, err := co.configNSSecretLister.Secrets(api.OpenShiftConfigNamespace).Get(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get customization bundle secret %s/%s: %w", api.OpenShiftConfigNamespace, name, err)
+		}
+		data = secret.Data
+	default:
+		configMap, err := co.configNSConfigMapLister.ConfigMaps(api.OpenShiftConfigNamespace).Get(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get customization bundle configmap %s/%s: %w", api.OpenShiftConfigNamespace, name, err)
+		}
+		for key, value := range configMap.Data {
+			data[key] = []byte(value)
+		}
+		for key, value := range configMap.BinaryData {
+			data[key] = value
+		}
+	}
+	return configmapsub.ParseCustomizationBundle(kind, name, data)
+}
+
+// GetGroupInactivityTimeouts returns the per group inactivity timeout overrides. With the
+// integrated OAuth server groups are validated against the cached cluster groups, unknown groups
+// are dropped. OIDC groups come from token claims and cannot be validated.
+func (co *consoleOperator) GetGroupInactivityTimeouts(operatorConfig *operatorv1.Console, authConfig *configv1.Authentication) (map[string]int, error) {
+	timeouts, err := utilsub.GetGroupInactivityTimeouts(operatorConfig)
+	if authConfig.Spec.Type == configv1.AuthenticationTypeOIDC || co.groupsLister == nil {
+		return timeouts, err
+	}
+
+	errs := []error{}
+	if err != nil {
+		errs = append(errs, err)
+	}
+	unknownGroups := []string{}
+	for group := range timeouts {
+		_, getErr := co.groupsLister.Get(group)
+		switch {
+		case apierrors.IsNotFound(getErr):
+			unknownGroups = append(unknownGroups, group)
+			delete(timeouts, group)
+		case getErr != nil:
+			// keep the override, the group may well exist
+			klog.V(4).Infof("failed to get group %q: %v", group, getErr)
+		}
+	}
+	if len(unknownGroups) > 0 {
+		sort.Strings(unknownGroups)
+		errs = append(errs, fmt.Errorf("inactivity timeouts set for groups that do not exist: %s", strings.Join(unknownGroups, ", ")))
+	}
+	return timeouts, utilerrors.NewAggregate(errs)
 }
 
 // apply service-ca configmap
@@ -469,6 +1197,47 @@ func (co *consoleOperator) SyncTrustedCAConfigMap(ctx context.Context, operatorC
 	return actual, true, "", err
 }
 
+// SyncOIDCCATrustConfigMap merges the CA bundles the OIDC issuer is trusted with into the
+// oidc-ca-trust-bundle configmap, which is removed once none of them holds a certificate.
+func (co *consoleOperator) SyncOIDCCATrustConfigMap(ctx context.Context, operatorConfig *operatorv1.Console, authnConfig *configv1.Authentication, proxyConfig *configv1.Proxy, recorder events.Recorder) (*corev1.ConfigMap, string, error) {
+	sources := []*corev1.ConfigMap{}
+	for _, name := range configmapsub.OIDCCATrustSources(operatorConfig, authnConfig, proxyConfig) {
+		source, err := co.configNSConfigMapLister.ConfigMaps(api.OpenShiftConfigNamespace).Get(name)
+		if apierrors.IsNotFound(err) {
+			klog.V(4).Infof("OIDC CA trust source %s/%s not found", api.OpenShiftConfigNamespace, name)
+			continue
+		}
+		if err != nil {
+			return nil, "FailedGet", err
+		}
+		sources = append(sources, source)
+	}
+
+	required, err := configmapsub.DefaultOIDCCATrustConfigMap(operatorConfig, sources...)
+	if err != nil {
+		return nil, "InvalidCABundle", err
+	}
+	if required == nil {
+		_, err := co.targetNSConfigMapLister.ConfigMaps(api.OpenShiftConsoleNamespace).Get(api.OIDCCATrustConfigMapName)
+		if apierrors.IsNotFound(err) {
+			return nil, "", nil
+		}
+		if err == nil {
+			err = co.configMapClient.ConfigMaps(api.OpenShiftConsoleNamespace).Delete(ctx, api.OIDCCATrustConfigMapName, metav1.DeleteOptions{})
+		}
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, "FailedDelete", err
+		}
+		return nil, "", nil
+	}
+
+	configMap, _, err := co.applyConfigMap(ctx, co.targetNSConfigMapLister, required, recorder)
+	if err != nil {
+		return nil, "FailedApply", err
+	}
+	return configMap, "", nil
+}
+
 func (co *consoleOperator) SyncCustomLogoConfigMap(ctx context.Context, operatorConfig *operatorv1.Console) (okToMount bool, reason string, err error) {
 	// validate first, to avoid a broken volume mount & a crashlooping console
 	okToMount, reason, err = co.ValidateCustomLogo(ctx, operatorConfig)
@@ -495,6 +1264,44 @@ func (co *consoleOperator) ValidateOAuthServingCertConfigMap(ctx context.Context
 	return oauthServingCertConfigMap, "", nil
 }
 
+// GetConsoleMounts returns the mounts of the console-mounts annotation of the operator config
+// whose configmap or secret is found in the console namespace, with the content hash of each.
+// Invalid mounts and mounts of a missing configmap or secret are left out and reported.
+func (co *consoleOperator) GetConsoleMounts(operatorConfig *operatorv1.Console) ([]deploymentsub.ConsoleMount, string, error) {
+	requested, invalidErr := deploymentsub.GetConsoleMounts(operatorConfig)
+	mounts := []deploymentsub.ConsoleMount{}
+	missing := []string{}
+	for _, mount := range requested {
+		if mount.Secret {
+			secret, err := co.secretsLister.Secrets(api.TargetNamespace).Get(mount.Name)
+			if err != nil {
+				missing = append(missing, fmt.Sprintf("secret %q", mount.Name))
+				continue
+			}
+			mount.Version = deploymentsub.SecretVersion(secret)
+		} else {
+			configMap, err := co.targetNSConfigMapLister.ConfigMaps(api.TargetNamespace).Get(mount.Name)
+			if err != nil {
+				missing = append(missing, fmt.Sprintf("configmap %q", mount.Name))
+				continue
+			}
+			mount.Version = deploymentsub.ConfigMapVersion(configMap)
+		}
+		mounts = append(mounts, mount)
+	}
+	var missingErr error
+	if len(missing) > 0 {
+		missingErr = fmt.Errorf("%s sources not found in the %s namespace: %s", api.ConsoleMountsAnnotation, api.TargetNamespace, strings.Join(missing, ", "))
+	}
+	switch {
+	case invalidErr != nil:
+		return mounts, "InvalidConsoleMounts", utilerrors.NewAggregate([]error{invalidErr, missingErr})
+	case missingErr != nil:
+		return mounts, "MissingConsoleMountSource", missingErr
+	}
+	return mounts, "", nil
+}
+
 // on each pass of the operator sync loop, we need to check the
 // operator config for a custom logo.  If this has been set, then
 // we notify the resourceSyncer that it needs to start watching this
@@ -614,7 +1421,8 @@ func (co *consoleOperator) syncSessionSecret(
 	} else {
 		required = sessionSecret.DeepCopy()
 		changed := secretsub.ResetSessionSecretKeysIfNeeded(required)
-		if !changed {
+		rotated := secretsub.RotateSessionSecretKeysIfNeeded(required, secretsub.GetSessionKeyRotationInterval(operatorConfig), time.Now())
+		if !changed && !rotated {
 			return required, nil
 		}
 	}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/go-test/deep"
+	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/console-operator/pkg/api"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -226,3 +227,85 @@ func TestGetNodeComputeEnvironments(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthServerEndpoints(t *testing.T) {
+	ingressConfig := &configv1.Ingress{Spec: configv1.IngressSpec{Domain: "apps.example.com"}}
+	customIngressConfig := &configv1.Ingress{
+		Spec: configv1.IngressSpec{
+			Domain: "apps.example.com",
+			ComponentRoutes: []configv1.ComponentRouteSpec{
+				{Namespace: api.OpenShiftAuthenticationNamespace, Name: api.OAuthRouteName, Hostname: "login.example.com"},
+			},
+		},
+	}
+	oidcProviders := []configv1.OIDCProvider{
+		{Name: "sso", Issuer: configv1.TokenIssuer{URL: "https://sso.example.com/realms/ocp/"}},
+		{Name: "login", Issuer: configv1.TokenIssuer{URL: "https://login.example.com"}},
+	}
+
+	tests := []struct {
+		name              string
+		ingressConfig     *configv1.Ingress
+		authnSpec         configv1.AuthenticationSpec
+		issuerEndpoints   map[string]string
+		expectedServer    string
+		expectedEndpoints []string
+	}{
+		{
+			name:              "Test integrated OAuth server",
+			ingressConfig:     ingressConfig,
+			authnSpec:         configv1.AuthenticationSpec{Type: configv1.AuthenticationTypeIntegratedOAuth},
+			expectedServer:    "OAuth Server",
+			expectedEndpoints: []string{"https://oauth-openshift.apps.example.com/healthz"},
+		},
+		{
+			name:              "Test integrated OAuth server with a custom hostname",
+			ingressConfig:     customIngressConfig,
+			authnSpec:         configv1.AuthenticationSpec{},
+			expectedServer:    "OAuth Server",
+			expectedEndpoints: []string{"https://login.example.com/healthz"},
+		},
+		{
+			name:           "Test OIDC providers",
+			ingressConfig:  ingressConfig,
+			authnSpec:      configv1.AuthenticationSpec{Type: configv1.AuthenticationTypeOIDC, OIDCProviders: oidcProviders},
+			expectedServer: "OIDC Provider",
+			expectedEndpoints: []string{
+				"https://sso.example.com/realms/ocp/.well-known/openid-configuration",
+				"https://login.example.com/.well-known/openid-configuration",
+			},
+		},
+		{
+			name:            "Test OIDC providers reached at their issuer endpoints",
+			ingressConfig:   ingressConfig,
+			authnSpec:       configv1.AuthenticationSpec{Type: configv1.AuthenticationTypeOIDC, OIDCProviders: oidcProviders},
+			issuerEndpoints: map[string]string{"sso": "https://sso.internal.example.com/realms/ocp"},
+			expectedServer:  "OIDC Provider",
+			expectedEndpoints: []string{
+				"https://sso.internal.example.com/realms/ocp/.well-known/openid-configuration",
+				"https://login.example.com/.well-known/openid-configuration",
+			},
+		},
+		{
+			name:          "Test OIDC without a provider",
+			ingressConfig: ingressConfig,
+			authnSpec:     configv1.AuthenticationSpec{Type: configv1.AuthenticationTypeOIDC},
+		},
+		{
+			name:          "Test no auth server",
+			ingressConfig: ingressConfig,
+			authnSpec:     configv1.AuthenticationSpec{Type: configv1.AuthenticationTypeNone},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authServer, endpoints := authServerEndpoints(tt.ingressConfig, &configv1.Authentication{Spec: tt.authnSpec}, tt.issuerEndpoints)
+			if diff := deep.Equal(authServer, tt.expectedServer); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(endpoints, tt.expectedEndpoints); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
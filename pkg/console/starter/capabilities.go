@@ -0,0 +1,79 @@
+package starter
+
+import (
+	authorizationv1 "k8s.io/api/authorization/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/controllers/capabilities"
+)
+
+const (
+	cliDownloadsControllerName           = "CLIDownloadsController"
+	maintenanceWindowControllerName      = "MaintenanceWindowController"
+	nodeUpdateNotificationControllerName = "NodeUpdateNotificationController"
+	podDisruptionBudgetControllerName    = "PodDisruptionBudgetController"
+	readOnlyNotificationControllerName   = "ReadOnlyModeNotificationController"
+	upgradeNotificationControllerName    = "UpgradeNotificationController"
+	validatingWebhookControllerName      = "ValidatingWebhookController"
+)
+
+var consoleNotificationPermissions = []authorizationv1.ResourceAttributes{
+	{Group: "console.openshift.io", Resource: "consolenotifications", Verb: "get"},
+	{Group: "console.openshift.io", Resource: "consolenotifications", Verb: "create"},
+	{Group: "console.openshift.io", Resource: "consolenotifications", Verb: "update"},
+	{Group: "console.openshift.io", Resource: "consolenotifications", Verb: "delete"},
+}
+
+// optionalControllers can be switched off when the operator runs under a restricted role,
+// see examples/restricted-rbac-role-cluster.yaml. Everything else is required and the
+// operator is expected to fail loudly without it.
+var optionalControllers = []capabilities.OptionalController{
+	{
+		Name: cliDownloadsControllerName,
+		Requires: []authorizationv1.ResourceAttributes{
+			{Group: "console.openshift.io", Resource: "consoleclidownloads", Verb: "list"},
+			{Group: "console.openshift.io", Resource: "consoleclidownloads", Verb: "watch"},
+			{Group: "console.openshift.io", Resource: "consoleclidownloads", Verb: "create"},
+			{Group: "console.openshift.io", Resource: "consoleclidownloads", Verb: "update"},
+		},
+	},
+	{
+		Name: maintenanceWindowControllerName,
+		// banners of windows that are over are found by label
+		Requires: append([]authorizationv1.ResourceAttributes{
+			{Group: "console.openshift.io", Resource: "consolenotifications", Verb: "list"},
+		}, consoleNotificationPermissions...),
+	},
+	{
+		Name:     nodeUpdateNotificationControllerName,
+		Requires: consoleNotificationPermissions,
+	},
+	{
+		Name: podDisruptionBudgetControllerName,
+		Requires: []authorizationv1.ResourceAttributes{
+			{Group: "policy", Resource: "poddisruptionbudgets", Verb: "list", Namespace: api.OpenShiftConsoleNamespace},
+			{Group: "policy", Resource: "poddisruptionbudgets", Verb: "watch", Namespace: api.OpenShiftConsoleNamespace},
+			{Group: "policy", Resource: "poddisruptionbudgets", Verb: "create", Namespace: api.OpenShiftConsoleNamespace},
+			{Group: "policy", Resource: "poddisruptionbudgets", Verb: "update", Namespace: api.OpenShiftConsoleNamespace},
+			{Group: "policy", Resource: "poddisruptionbudgets", Verb: "delete", Namespace: api.OpenShiftConsoleNamespace},
+		},
+	},
+	{
+		Name:     readOnlyNotificationControllerName,
+		Requires: consoleNotificationPermissions,
+	},
+	{
+		Name: upgradeNotificationControllerName,
+		Requires: append([]authorizationv1.ResourceAttributes{
+			{Group: "machineconfiguration.openshift.io", Resource: "machineconfigpools", Verb: "list"},
+		}, consoleNotificationPermissions...),
+	},
+	{
+		Name: validatingWebhookControllerName,
+		Requires: []authorizationv1.ResourceAttributes{
+			{Group: "admissionregistration.k8s.io", Resource: "validatingwebhookconfigurations", Verb: "create"},
+			{Group: "admissionregistration.k8s.io", Resource: "validatingwebhookconfigurations", Verb: "update", Name: api.ValidatingWebhookConfigurationName},
+			{Group: "admissionregistration.k8s.io", Resource: "validatingwebhookconfigurations", Verb: "delete", Name: api.ValidatingWebhookConfigurationName},
+		},
+	},
+}
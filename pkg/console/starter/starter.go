@@ -11,11 +11,13 @@ import (
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	apiexensionsinformers "k8s.io/apiextensions-apiserver/pkg/client/informers/externalversions"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	policyv1client "k8s.io/client-go/kubernetes/typed/policy/v1"
+	"k8s.io/klog/v2"
 
 	// openshift
 	configv1 "github.com/openshift/api/config/v1"
@@ -23,19 +25,48 @@ import (
 	operatorv1 "github.com/openshift/api/operator"
 	"github.com/openshift/console-operator/pkg/api"
 	"github.com/openshift/console-operator/pkg/console/clientwrapper"
+	"github.com/openshift/console-operator/pkg/console/controllers/bluegreen"
+	"github.com/openshift/console-operator/pkg/console/controllers/canary"
+	"github.com/openshift/console-operator/pkg/console/controllers/capabilities"
 	"github.com/openshift/console-operator/pkg/console/controllers/clidownloads"
+	"github.com/openshift/console-operator/pkg/console/controllers/clusterproxy"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolecapability"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolequickstart"
+	"github.com/openshift/console-operator/pkg/console/controllers/consolesample"
+	"github.com/openshift/console-operator/pkg/console/controllers/crashloop"
 	"github.com/openshift/console-operator/pkg/console/controllers/downloadsdeployment"
+	"github.com/openshift/console-operator/pkg/console/controllers/endpoints"
+	"github.com/openshift/console-operator/pkg/console/controllers/featuregates"
+	"github.com/openshift/console-operator/pkg/console/controllers/fipscompliance"
+	"github.com/openshift/console-operator/pkg/console/controllers/forcesync"
 	"github.com/openshift/console-operator/pkg/console/controllers/healthcheck"
+	hpa "github.com/openshift/console-operator/pkg/console/controllers/horizontalpodautoscaler"
+	"github.com/openshift/console-operator/pkg/console/controllers/inspection"
+	"github.com/openshift/console-operator/pkg/console/controllers/maintenancewindow"
+	"github.com/openshift/console-operator/pkg/console/controllers/managedcluster"
+	"github.com/openshift/console-operator/pkg/console/controllers/managedclusteroauth"
+	"github.com/openshift/console-operator/pkg/console/controllers/nodeupdates"
 	"github.com/openshift/console-operator/pkg/console/controllers/oauthclients"
 	"github.com/openshift/console-operator/pkg/console/controllers/oauthclientsecret"
+	"github.com/openshift/console-operator/pkg/console/controllers/oauthserverprobe"
+	"github.com/openshift/console-operator/pkg/console/controllers/oauthtemplates"
+	"github.com/openshift/console-operator/pkg/console/controllers/oidcloginprobe"
 	"github.com/openshift/console-operator/pkg/console/controllers/oidcsetup"
 	pdb "github.com/openshift/console-operator/pkg/console/controllers/poddisruptionbudget"
+	"github.com/openshift/console-operator/pkg/console/controllers/preupgrade"
+	"github.com/openshift/console-operator/pkg/console/controllers/readonlymode"
 	"github.com/openshift/console-operator/pkg/console/controllers/route"
 	"github.com/openshift/console-operator/pkg/console/controllers/service"
+	"github.com/openshift/console-operator/pkg/console/controllers/teardown"
+	"github.com/openshift/console-operator/pkg/console/controllers/updatesummary"
 	upgradenotification "github.com/openshift/console-operator/pkg/console/controllers/upgradenotification"
 	"github.com/openshift/console-operator/pkg/console/controllers/util"
+	"github.com/openshift/console-operator/pkg/console/controllers/validatingwebhook"
+	"github.com/openshift/console-operator/pkg/console/controllers/versionskew"
 	"github.com/openshift/console-operator/pkg/console/operatorclient"
+	routesub "github.com/openshift/console-operator/pkg/console/subresource/route"
 	"github.com/openshift/library-go/pkg/controller/controllercmd"
+	"github.com/openshift/library-go/pkg/controller/factory"
 	"github.com/openshift/library-go/pkg/operator/managementstatecontroller"
 	"github.com/openshift/library-go/pkg/operator/resourcesynccontroller"
 	"github.com/openshift/library-go/pkg/operator/staleconditions"
@@ -62,7 +93,20 @@ import (
 	"github.com/openshift/library-go/pkg/operator/loglevel"
 )
 
-func RunOperator(ctx context.Context, controllerContext *controllercmd.ControllerContext) error {
+// RunOperator starts the controllers of the operator. In dry-run mode every controller runs as
+// usual, but the writes are sent with dryRun=All and reported as events, only the operator
+// status is persisted. The clients of the operator share budget. The OIDC setup controller backs
+// off its requeues with oidcBackoff.
+func RunOperator(ctx context.Context, controllerContext *controllercmd.ControllerContext, dryRun bool, budgetOptions clientwrapper.BudgetOptions, oidcBackoff util.RequeueBackoffOptions) error {
+	budget := clientwrapper.NewBudget(budgetOptions)
+	controllerContext.KubeConfig = budget.Apply(controllerContext.KubeConfig)
+	controllerContext.ProtoKubeConfig = budget.Apply(controllerContext.ProtoKubeConfig)
+
+	if dryRun {
+		klog.Warning("running in dry-run mode: changes are reported as events and not persisted")
+		controllerContext.KubeConfig = clientwrapper.WithDryRun(controllerContext.KubeConfig, controllerContext.EventRecorder)
+		controllerContext.ProtoKubeConfig = clientwrapper.WithDryRun(controllerContext.ProtoKubeConfig, controllerContext.EventRecorder)
+	}
 
 	kubeClient, err := kubernetes.NewForConfig(controllerContext.ProtoKubeConfig)
 	if err != nil {
@@ -119,6 +163,16 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		informers.WithNamespace(api.OpenShiftConfigNamespace),
 	)
 
+	// only the install-config of kube-system is readable by the operator
+	kubeInformersInstallConfig := informers.NewSharedInformerFactoryWithOptions(
+		kubeClient,
+		resync,
+		informers.WithNamespace(api.KubeSystemNamespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", api.InstallConfigConfigMapName).String()
+		}),
+	)
+
 	//configs are all named "cluster", but our clusteroperator is named "console"
 	configInformers := configinformers.NewSharedInformerFactoryWithOptions(
 		configClient,
@@ -182,6 +236,7 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		operatorClient,
 		operatorConfigClient.OperatorV1(),
 		operatorConfigInformers.Operator().V1().Consoles(), // OperatorConfig
+		operatorConfigInformers.Operator().V1alpha1().ImageContentSourcePolicies(),
 
 		// core resources
 		kubeClient.CoreV1(),                 // Secrets, ConfigMaps, Service
@@ -198,6 +253,7 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		consoleInformers.Console().V1().ConsolePlugins(),
 		// openshift
 		kubeInformersConfigNamespaced.Core().V1().ConfigMaps(), // openshift-config configMaps
+		kubeInformersConfigNamespaced.Core().V1().Secrets(),    // openshift-config secrets
 		// openshift managed
 		kubeInformersManagedNamespaced.Core().V1(), // Managed ConfigMaps
 		// event handling
@@ -211,15 +267,19 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		return err
 	}
 	apiextensionsInformers := apiexensionsinformers.NewSharedInformerFactory(apiextensionsClient, resync)
+	crdSchemaEvaluator := util.NewCRDSchemaEvaluator(apiextensionsInformers.Apiextensions().V1().CustomResourceDefinitions())
 
 	oauthClientController := oauthclients.NewOAuthClientsController(
 		operatorClient,
 		oauthClient,
+		kubeClient.CoreV1(),
 		configInformers.Config().V1().Authentications(),
+		configInformers.Config().V1().OAuths(),
 		operatorConfigInformers.Operator().V1().Consoles(),
 		routesInformersNamespaced.Route().V1().Routes(),
 		configInformers.Config().V1().Ingresses(),
 		kubeInformersNamespaced.Core().V1().Secrets(),
+		kubeInformersNamespaced.Apps().V1().Deployments(),
 		oauthClientsSwitchedInformer,
 		recorder,
 	)
@@ -236,13 +296,29 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 
 	oidcSetupController := oidcsetup.NewOIDCSetupController(
 		operatorClient,
+		dynamicClient,
+		kubeClient.CoreV1(),
 		configInformers.Config().V1().Authentications(),
 		configClient.ConfigV1().Authentications(),
+		configInformers.Config().V1().Consoles(),
 		operatorConfigInformers.Operator().V1().Consoles(),
-		apiextensionsInformers.Apiextensions().V1().CustomResourceDefinitions(),
+		crdSchemaEvaluator,
+		kubeInformersConfigNamespaced.Core().V1().Secrets(),
 		kubeInformersNamespaced.Core().V1().Secrets(),
 		kubeInformersNamespaced.Core().V1().ConfigMaps(),
 		kubeInformersNamespaced.Apps().V1().Deployments(),
+		kubeInformersNamespaced.Core().V1().Pods(),
+		oidcBackoff,
+		recorder,
+	)
+
+	oidcClientSecretRotationController := oidcsetup.NewOIDCClientSecretRotationController(
+		operatorClient,
+		configInformers.Config().V1().Authentications(),
+		operatorConfigInformers.Operator().V1().Consoles(),
+		kubeInformersConfigNamespaced.Core().V1().Secrets(),
+		kubeInformersNamespaced.Core().V1().Secrets(),
+		kubeInformersNamespaced.Apps().V1().Deployments(),
 		recorder,
 	)
 
@@ -255,25 +331,63 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 
 		kubeClient.AppsV1(), // Deployments
 		kubeInformersNamespaced.Apps().V1().Deployments(), // Deployments
+		kubeInformersNamespaced.Core().V1().Nodes(),       // Nodes
 		recorder,
 	)
 
-	cliDownloadsController := clidownloads.NewCLIDownloadsSyncController(
-		// top level config
-		configClient.ConfigV1(),
+	canaryController := canary.NewCanarySyncController(
 		// clients
 		operatorClient,
-		consoleClient.ConsoleV1().ConsoleCLIDownloads(),
-		routesClient.RouteV1(),
+		kubeClient.CoreV1(), // ConfigMaps
+		kubeClient.CoreV1(), // Services
+		kubeClient.AppsV1(), // Deployments
 		// informers
-		operatorConfigInformers.Operator().V1().Consoles(), // OperatorConfig
-		configInformers, // Config
-		consoleInformers.Console().V1().ConsoleCLIDownloads(), // ConsoleCliDownloads
-		routesInformersNamespaced.Route().V1().Routes(),       // Routes
-		// events
+		operatorConfigInformers.Operator().V1().Consoles(),
+		kubeInformersNamespaced.Core().V1().ConfigMaps(),
+		kubeInformersNamespaced.Apps().V1().Deployments(),
+		recorder,
+	)
+
+	blueGreenController := bluegreen.NewBlueGreenSyncController(
+		// clients
+		operatorClient,
+		kubeClient.CoreV1(), // ConfigMaps
+		kubeClient.CoreV1(), // Services
+		kubeClient.AppsV1(), // Deployments
+		// informers
+		operatorConfigInformers.Operator().V1().Consoles(),
+		kubeInformersNamespaced.Core().V1().ConfigMaps(),
+		kubeInformersNamespaced.Apps().V1().Deployments(),
+		routesInformersNamespaced.Route().V1().Routes(),
 		recorder,
 	)
 
+	// optional controllers are only started if the operator holds the permissions they need,
+	// so the operator can run under a restricted role
+	optionalControllersResult := capabilities.CheckPermissions(ctx, kubeClient.AuthorizationV1().SelfSubjectAccessReviews(), optionalControllers)
+	enabledOptionalControllers := []interface {
+		Run(ctx context.Context, workers int)
+	}{}
+
+	if optionalControllersResult.Enabled(cliDownloadsControllerName) {
+		cliDownloadsController := clidownloads.NewCLIDownloadsSyncController(
+			// top level config
+			configClient.ConfigV1(),
+			// clients
+			operatorClient,
+			consoleClient.ConsoleV1().ConsoleCLIDownloads(),
+			routesClient.RouteV1(),
+			// informers
+			operatorConfigInformers.Operator().V1().Consoles(), // OperatorConfig
+			configInformers, // Config
+			consoleInformers.Console().V1().ConsoleCLIDownloads(), // ConsoleCliDownloads
+			routesInformersNamespaced.Route().V1().Routes(),       // Routes
+			// events
+			recorder,
+		)
+		enabledOptionalControllers = append(enabledOptionalControllers, cliDownloadsController)
+	}
+
 	consoleServiceController := service.NewServiceSyncController(
 		api.OpenShiftConsoleServiceName,
 		// top level config
@@ -319,6 +433,7 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		operatorConfigInformers.Operator().V1().Consoles(),
 		kubeInformersConfigNamespaced.Core().V1().Secrets(), // `openshift-config` namespace informers
 		routesInformersNamespaced.Route().V1().Routes(),
+		kubeInformersNamespaced.Core().V1().ConfigMaps(),
 		// events
 		recorder,
 	)
@@ -338,6 +453,7 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		operatorConfigInformers.Operator().V1().Consoles(),
 		kubeInformersConfigNamespaced.Core().V1().Secrets(), // `openshift-config` namespace informers
 		routesInformersNamespaced.Route().V1().Routes(),
+		kubeInformersNamespaced.Core().V1().ConfigMaps(),
 		// events
 		recorder,
 	)
@@ -358,14 +474,241 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		recorder,
 	)
 
-	upgradeNotificationController := upgradenotification.NewUpgradeNotificationController(
-		// top level config
-		configClient.ConfigV1(),
+	if optionalControllersResult.Enabled(upgradeNotificationControllerName) {
+		upgradeNotificationController := upgradenotification.NewUpgradeNotificationController(
+			// top level config
+			configClient.ConfigV1(),
+			configInformers,
+			// clients
+			operatorClient,
+			operatorConfigInformers.Operator().V1().Consoles(),
+			consoleClient.ConsoleV1().ConsoleNotifications(),
+			dynamicClient,
+			//events
+			recorder,
+		)
+		enabledOptionalControllers = append(enabledOptionalControllers, upgradeNotificationController)
+	}
+
+	if optionalControllersResult.Enabled(readOnlyNotificationControllerName) {
+		readOnlyNotificationController := readonlymode.NewReadOnlyModeNotificationController(
+			// clients
+			operatorClient,
+			operatorConfigInformers.Operator().V1().Consoles(),
+			consoleClient.ConsoleV1().ConsoleNotifications(),
+			//events
+			recorder,
+		)
+		enabledOptionalControllers = append(enabledOptionalControllers, readOnlyNotificationController)
+	}
+
+	if optionalControllersResult.Enabled(maintenanceWindowControllerName) {
+		maintenanceWindowController := maintenancewindow.NewMaintenanceWindowController(
+			// clients
+			operatorClient,
+			consoleClient.ConsoleV1().ConsoleNotifications(),
+			// informers
+			operatorConfigInformers.Operator().V1().Consoles(),
+			kubeInformersConfigNamespaced.Core().V1().ConfigMaps(), // openshift-config configMaps
+			//events
+			recorder,
+		)
+		enabledOptionalControllers = append(enabledOptionalControllers, maintenanceWindowController)
+	}
+
+	if optionalControllersResult.Enabled(nodeUpdateNotificationControllerName) {
+		nodeUpdateNotificationController := nodeupdates.NewNodeUpdateNotificationController(
+			// clients
+			operatorClient,
+			consoleClient.ConsoleV1().ConsoleNotifications(),
+			// informers
+			operatorConfigInformers.Operator().V1().Consoles(),
+			kubeInformersNamespaced.Core().V1().Nodes(),
+			//events
+			recorder,
+		)
+		enabledOptionalControllers = append(enabledOptionalControllers, nodeUpdateNotificationController)
+	}
+
+	updateSummaryController := updatesummary.NewUpdateSummaryController(
+		// clients
+		operatorClient,
+		kubeClient.CoreV1(),
+		// informers
+		configInformers,
+		operatorConfigInformers.Operator().V1().Consoles(),
+		kubeInformersManagedNamespaced.Core().V1().ConfigMaps(), // openshift-config-managed configMaps
+		//events
+		recorder,
+	)
+
+	preUpgradeChecksController := preupgrade.NewPreUpgradeChecksController(
+		// clients
+		operatorClient,
+		// informers
+		configInformers,
+		operatorConfigInformers.Operator().V1().Consoles(),
+		kubeInformersConfigNamespaced.Core().V1().Secrets(), // openshift-config secrets
+		consoleInformers.Console().V1().ConsolePlugins(),
+		//events
+		recorder,
+	)
+
+	versionSkewController := versionskew.NewVersionSkewController(
+		// clients
+		operatorClient,
+		// informers
+		configInformers,
+		operatorConfigInformers.Operator().V1().Consoles(),
+		kubeInformersNamespaced.Core().V1().Pods(), // `openshift-console` namespace informers
+		//events
+		recorder,
+	)
+
+	oauthServerProbeController := oauthserverprobe.NewOAuthServerProbeController(
+		// clients
+		operatorClient,
+		// informers
+		configInformers,
+		operatorConfigInformers.Operator().V1().Consoles(),
+		kubeInformersNamespaced.Core().V1().ConfigMaps(), // `openshift-console` namespace informers
+		//events
+		recorder,
+	)
+
+	consoleHPAController := hpa.NewHorizontalPodAutoscalerController(
+		// clients
+		operatorClient,
+		kubeClient.AutoscalingV2(),
+		// informers
 		configInformers,
+		operatorConfigInformers.Operator().V1().Consoles(),
+		kubeInformersNamespaced.Autoscaling().V2().HorizontalPodAutoscalers(), // `openshift-console` namespace informers
+		//events
+		recorder,
+	)
+
+	oauthTemplatesController := oauthtemplates.NewOAuthTemplatesController(
 		// clients
 		operatorClient,
+		kubeClient.CoreV1(),
+		// informers
+		operatorConfigInformers.Operator().V1().Consoles(),
+		kubeInformersConfigNamespaced.Core().V1().ConfigMaps(), // `openshift-config` namespace informers
+		kubeInformersConfigNamespaced.Core().V1().Secrets(),
+		//events
+		recorder,
+	)
+
+	crashLoopDiagnosisController := crashloop.NewCrashLoopDiagnosisController(
+		// clients
+		operatorClient,
+		kubeClient.CoreV1(),
+		// informers
+		operatorConfigInformers.Operator().V1().Consoles(),
+		kubeInformersNamespaced.Core().V1().Pods(), // `openshift-console` namespace informers
+		//events
+		recorder,
+	)
+
+	managedClusterController := managedcluster.NewManagedClusterController(
+		// clients
+		operatorClient,
+		kubeClient.CoreV1(),
+		dynamicClient,
+		// informers
 		operatorConfigInformers.Operator().V1().Consoles(),
-		consoleClient.ConsoleV1().ConsoleNotifications(),
+		kubeInformersNamespaced.Core().V1().ConfigMaps(), // `openshift-console` namespace informers
+		//events
+		recorder,
+	)
+
+	managedClusterOAuthController := managedclusteroauth.NewManagedClusterOAuthController(
+		// clients
+		operatorClient,
+		kubeClient.CoreV1(),
+		dynamicClient,
+		// informers
+		configInformers,
+		operatorConfigInformers.Operator().V1().Consoles(),
+		kubeInformersNamespaced.Core().V1().ConfigMaps(), // `openshift-console` namespace informers
+		kubeInformersNamespaced.Core().V1().Secrets(),
+		//events
+		recorder,
+	)
+
+	clusterProxyHealthController := clusterproxy.NewClusterProxyHealthController(
+		// clients
+		operatorClient,
+		kubeClient.CoreV1(),
+		// informers
+		operatorConfigInformers.Operator().V1().Consoles(),
+		kubeInformersConfigNamespaced.Core().V1().ConfigMaps(), // openshift-config configMaps
+		kubeInformersNamespaced.Core().V1().ConfigMaps(),       // `openshift-console` namespace informers
+		//events
+		recorder,
+	)
+
+	consoleEndpointsController := endpoints.NewConsoleEndpointsController(
+		// clients
+		operatorClient,
+		kubeClient.CoreV1(),
+		// informers
+		operatorConfigInformers.Operator().V1().Consoles(),
+		configInformers,
+		routesInformersNamespaced.Route().V1().Routes(),
+		consoleInformers.Console().V1().ConsolePlugins(),
+		kubeInformersManagedNamespaced.Core().V1().ConfigMaps(), // openshift-config-managed configMaps
+		//events
+		recorder,
+	)
+
+	consoleSampleController := consolesample.NewConsoleSampleController(
+		// clients
+		operatorClient,
+		consoleClient.ConsoleV1().ConsoleSamples(),
+		kubeClient.CoreV1(),
+		// informers
+		operatorConfigInformers.Operator().V1().Consoles(),
+		consoleInformers.Console().V1().ConsoleSamples(),
+		configInformers,
+		kubeInformersNamespaced.Core().V1().ConfigMaps(), // `openshift-console` namespace informers
+		//events
+		recorder,
+	)
+
+	consoleQuickStartController := consolequickstart.NewConsoleQuickStartController(
+		// clients
+		operatorClient,
+		kubeClient.Discovery(),
+		// informers
+		operatorConfigInformers.Operator().V1().Consoles(),
+		consoleInformers.Console().V1().ConsoleQuickStarts(),
+		//events
+		recorder,
+	)
+
+	fipsComplianceController := fipscompliance.NewFIPSComplianceController(
+		// clients
+		operatorClient,
+		// informers
+		operatorConfigInformers.Operator().V1().Consoles(),
+		kubeInformersNamespaced.Core().V1().ConfigMaps(), // `openshift-console` namespace informers
+		kubeInformersInstallConfig.Core().V1().ConfigMaps(),
+		kubeInformersNamespaced.Apps().V1().Deployments(),
+		//events
+		recorder,
+	)
+
+	inspectionController := inspection.NewInspectionController(
+		// clients
+		operatorClient,
+		kubeClient.CoreV1(),
+		kubeClient.CoreV1(),
+		// informers
+		configInformers,
+		operatorConfigInformers.Operator().V1().Consoles(),
+		kubeInformersNamespaced.Core().V1().ConfigMaps(), // `openshift-console` namespace informers
 		//events
 		recorder,
 	)
@@ -384,7 +727,11 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 			{Group: oauth.GroupName, Resource: "oauthclients", Name: api.OAuthClientName},
 			{Group: corev1.GroupName, Resource: "namespaces", Name: api.OpenShiftConsoleOperatorNamespace},
 			{Group: corev1.GroupName, Resource: "namespaces", Name: api.OpenShiftConsoleNamespace},
+			{Group: "admissionregistration.k8s.io", Resource: "validatingwebhookconfigurations", Name: api.ValidatingWebhookConfigurationName},
 			{Group: corev1.GroupName, Resource: "configmaps", Name: api.OpenShiftConsolePublicConfigMapName, Namespace: api.OpenShiftConfigManagedNamespace},
+			{Group: corev1.GroupName, Resource: "configmaps", Name: api.ConsoleEndpointsConfigMapName, Namespace: api.OpenShiftConfigManagedNamespace},
+			{Group: corev1.GroupName, Resource: "configmaps", Name: api.UpdateSummaryConfigMapName, Namespace: api.OpenShiftConfigManagedNamespace},
+			{Group: corev1.GroupName, Resource: "configmaps", Name: api.InspectionConfigMapName, Namespace: api.OpenShiftConsoleOperatorNamespace},
 		},
 		// clusteroperator client
 		configClient.ConfigV1(),
@@ -396,7 +743,8 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		controllerContext.EventRecorder,
 	)
 
-	// Show all ConsolePlugin instances as related objects
+	// Show all ConsolePlugin instances and the custom route TLS secrets as related objects,
+	// must-gather collects the secrets without their data
 	clusterOperatorStatus.WithRelatedObjectsFunc(func() (bool, []configv1.ObjectReference) {
 		relatedObjects := []configv1.ObjectReference{}
 		consolePlugins, err := consoleClient.ConsoleV1().ConsolePlugins().List(ctx, metav1.ListOptions{})
@@ -411,6 +759,22 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 			}
 			relatedObjects = append(relatedObjects, relatadPlugin)
 		}
+		operatorConfig, err := operatorConfigInformers.Operator().V1().Consoles().Lister().Get(api.ConfigResourceName)
+		if err != nil {
+			return false, nil
+		}
+		ingressConfig, err := configInformers.Config().V1().Ingresses().Lister().Get(api.ConfigResourceName)
+		if err != nil {
+			return false, nil
+		}
+		for _, secret := range routesub.GetTLSSecrets(operatorConfig, ingressConfig) {
+			relatedObjects = append(relatedObjects, configv1.ObjectReference{
+				Group:     corev1.GroupName,
+				Resource:  "secrets",
+				Name:      secret.SecretName,
+				Namespace: api.OpenShiftConfigNamespace,
+			})
+		}
 		return true, relatedObjects
 	})
 
@@ -448,26 +812,130 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		return err
 	}
 
-	consolePDBController := pdb.NewPodDisruptionBudgetController(
-		api.OpenShiftConsoleName,
+	if optionalControllersResult.Enabled(podDisruptionBudgetControllerName) {
+		consolePDBController := pdb.NewPodDisruptionBudgetController(
+			api.OpenShiftConsoleName,
+			// clients
+			operatorClient,
+			operatorConfigInformers.Operator().V1().Consoles(),
+			policyClient,
+			// informers
+			configInformers,
+			kubeInformersNamespaced.Policy().V1().PodDisruptionBudgets(),
+			kubeInformersNamespaced.Core().V1().Nodes(),
+			//events
+			recorder,
+		)
+
+		downloadsPDBController := pdb.NewPodDisruptionBudgetController(
+			api.DownloadsResourceName,
+			// clients
+			operatorClient,
+			operatorConfigInformers.Operator().V1().Consoles(),
+			policyClient,
+			// informers
+			configInformers,
+			kubeInformersNamespaced.Policy().V1().PodDisruptionBudgets(),
+			kubeInformersNamespaced.Core().V1().Nodes(),
+			//events
+			recorder,
+		)
+		enabledOptionalControllers = append(enabledOptionalControllers, consolePDBController, downloadsPDBController)
+	}
+
+	if optionalControllersResult.Enabled(validatingWebhookControllerName) {
+		validatingWebhookController := validatingwebhook.NewValidatingWebhookController(
+			// clients
+			operatorClient,
+			kubeClient.AdmissionregistrationV1(),
+			// informers
+			operatorConfigInformers.Operator().V1().Consoles(),
+			//events
+			recorder,
+		)
+		enabledOptionalControllers = append(enabledOptionalControllers, validatingWebhookController)
+	}
+
+	forceSyncController := forcesync.NewForceSyncController(
 		// clients
-		operatorClient,
+		operatorConfigClient.OperatorV1().Consoles(),
+		// informers
 		operatorConfigInformers.Operator().V1().Consoles(),
-		policyClient,
+		//events
+		recorder,
+	)
+
+	consoleCapabilityController := consolecapability.NewConsoleCapabilityController(
+		// clients
+		operatorClient,
 		// informers
-		kubeInformersNamespaced.Policy().V1().PodDisruptionBudgets(),
+		configInformers,
+		operatorConfigInformers.Operator().V1().Consoles(),
 		//events
 		recorder,
 	)
 
-	downloadsPDBController := pdb.NewPodDisruptionBudgetController(
-		api.DownloadsResourceName,
+	teardownController := teardown.NewTeardownController(
 		// clients
 		operatorClient,
+		operatorConfigClient.OperatorV1().Consoles(),
+		routesClient.RouteV1(),
+		oauthClient.OauthV1(),
+		configClient.ConfigV1().Authentications(),
+		kubeClient.AppsV1(),
+		kubeClient.CoreV1(),
+		// informers
 		operatorConfigInformers.Operator().V1().Consoles(),
-		policyClient,
+		routesInformersNamespaced.Route().V1().Routes(),
+		configInformers.Config().V1().Authentications(),
+		configInformers.Config().V1().ClusterVersions(),
+		kubeInformersNamespaced.Apps().V1().Deployments(),
+		//events
+		recorder,
+	)
+
+	// gated controllers are started and stopped by the featureGateController as their feature
+	// gate is enabled and disabled. Their informers have to be shared with other controllers,
+	// the informer factories are only started once.
+	gatedControllers := []featuregates.GatedController{
+		{
+			// the console only logs in with an OIDC provider once external OIDC is enabled
+			Name:        "OIDCLoginProbeController",
+			FeatureGate: featuregates.FeatureGateExternalOIDC,
+			New: func() factory.Controller {
+				return oidcloginprobe.NewOIDCLoginProbeController(
+					// clients
+					operatorClient,
+					// informers
+					configInformers,
+					operatorConfigInformers.Operator().V1().Consoles(),
+					kubeInformersNamespaced.Core().V1().ConfigMaps(), // `openshift-console` namespace informers
+					//events
+					recorder,
+				)
+			},
+		},
+	}
+
+	featureGateController := featuregates.NewFeatureGateController(
+		// clients
+		operatorClient,
 		// informers
-		kubeInformersNamespaced.Policy().V1().PodDisruptionBudgets(),
+		configInformers.Config().V1().FeatureGates(),
+		// the feature gates are rendered per payload version
+		os.Getenv("RELEASE_VERSION"),
+		gatedControllers,
+		//events
+		recorder,
+	)
+
+	capabilitiesController := capabilities.NewCapabilitiesController(
+		// clients
+		operatorClient,
+		// informers
+		operatorConfigInformers.Operator().V1().Consoles(),
+		// capabilities detected at startup
+		optionalControllersResult,
 		//events
 		recorder,
 	)
@@ -483,6 +951,7 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		kubeInformersNamespaced,
 		kubeInformersConfigNamespaced,
 		kubeInformersManagedNamespaced,
+		kubeInformersInstallConfig,
 		resourceSyncerInformers,
 		operatorConfigInformers,
 		consoleInformers,
@@ -507,20 +976,43 @@ func RunOperator(ctx context.Context, controllerContext *controllercmd.Controlle
 		downloadsServiceController,
 		downloadsRouteController,
 		consoleOperator,
-		cliDownloadsController,
 		downloadsDeploymentController,
+		canaryController,
+		blueGreenController,
 		consoleRouteHealthCheckController,
-		consolePDBController,
-		downloadsPDBController,
 		oauthClientController,
 		oauthClientSecretController,
 		oidcSetupController,
-		upgradeNotificationController,
+		oidcClientSecretRotationController,
+		oauthServerProbeController,
+		oauthTemplatesController,
+		consoleHPAController,
+		fipsComplianceController,
+		inspectionController,
+		clusterProxyHealthController,
+		consoleEndpointsController,
+		consoleSampleController,
+		consoleQuickStartController,
+		managedClusterController,
+		managedClusterOAuthController,
+		preUpgradeChecksController,
+		updateSummaryController,
+		versionSkewController,
+		crashLoopDiagnosisController,
+		capabilitiesController,
+		featureGateController,
+		forceSyncController,
+		consoleCapabilityController,
+		teardownController,
 		staleConditionsController,
 	} {
 		go controller.Run(ctx, 1)
 	}
 
+	for _, controller := range enabledOptionalControllers {
+		go controller.Run(ctx, 1)
+	}
+
 	<-ctx.Done()
 	return fmt.Errorf("stopped")
 }
@@ -24,7 +24,7 @@ type AuthStatusHandler struct {
 	componentNamespace string
 	fieldManager       string
 	conditionsToApply  map[string]*metav1.Condition
-	currentClientID    string
+	currentClients     []configv1.OIDCClientReference
 }
 
 // NewAuthStatusHandler creates a handler for updating the Authentication.config.openshift.io
@@ -81,8 +81,9 @@ func (c *AuthStatusHandler) setCondition(conditionType string, status metav1.Con
 	c.conditionsToApply[conditionType].LastTransitionTime = ts
 }
 
-func (c *AuthStatusHandler) WithCurrentOIDCClient(currentClientID string) {
-	c.currentClientID = currentClientID
+// WithCurrentOIDCClients sets the console clients currently in use, one per OIDC provider.
+func (c *AuthStatusHandler) WithCurrentOIDCClients(currentClients []configv1.OIDCClientReference) {
+	c.currentClients = currentClients
 }
 
 func (c *AuthStatusHandler) Apply(ctx context.Context, authnConfig *configv1.Authentication) error {
@@ -100,18 +101,13 @@ func (c *AuthStatusHandler) Apply(ctx context.Context, authnConfig *configv1.Aut
 		ComponentNamespace: &c.componentNamespace,
 	}
 
-	if len(c.currentClientID) > 0 {
-		var providerName, providerIssuerURL string
-		if len(authnConfig.Spec.OIDCProviders) > 0 {
-			providerName = authnConfig.Spec.OIDCProviders[0].Name
-			providerIssuerURL = authnConfig.Spec.OIDCProviders[0].Issuer.URL
-		}
-
+	for i := range c.currentClients {
+		currentClient := c.currentClients[i]
 		clientStatus.WithCurrentOIDCClients(
 			&configv1ac.OIDCClientReferenceApplyConfiguration{
-				OIDCProviderName: &providerName,
-				IssuerURL:        &providerIssuerURL,
-				ClientID:         &c.currentClientID,
+				OIDCProviderName: &currentClient.OIDCProviderName,
+				IssuerURL:        &currentClient.IssuerURL,
+				ClientID:         &currentClient.ClientID,
 			},
 		)
 	}
@@ -137,6 +133,61 @@ func (c *AuthStatusHandler) Apply(ctx context.Context, authnConfig *configv1.Aut
 	return err
 }
 
+// Transition returns the state the component is moved from and to by the conditions to apply,
+// and the reason of the state it is moved to. The state is the first of Degraded, Progressing and
+// Available set to True, Unavailable when Available is False, or Unknown.
+func (c *AuthStatusHandler) Transition(authnConfig *configv1.Authentication) (string, string, string, error) {
+	applyConfig, err := configv1ac.ExtractAuthenticationStatus(authnConfig, c.fieldManager)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	previous := map[string]metav1.Condition{}
+	current := map[string]metav1.Condition{}
+	for _, conditionType := range []string{conditionTypeDegraded, conditionTypeProgressing, conditionTypeAvailable} {
+		condition := existingOrNewCondition(applyConfig, conditionType)
+		previous[conditionType] = *condition
+		if toApply := c.conditionsToApply[conditionType]; toApply != nil {
+			condition = toApply
+		}
+		current[conditionType] = *condition
+	}
+
+	from, _ := authState(previous)
+	to, reason := authState(current)
+	return from, to, reason, nil
+}
+
+func authState(conditions map[string]metav1.Condition) (string, string) {
+	for _, conditionType := range []string{conditionTypeDegraded, conditionTypeProgressing, conditionTypeAvailable} {
+		if conditions[conditionType].Status == metav1.ConditionTrue {
+			return conditionType, conditions[conditionType].Reason
+		}
+	}
+	if conditions[conditionTypeAvailable].Status == metav1.ConditionFalse {
+		return "Unavailable", conditions[conditionTypeAvailable].Reason
+	}
+	return "Unknown", conditions[conditionTypeAvailable].Reason
+}
+
+// HasOIDCClientStatus is true while the status of the component applied by the handler is
+// reported in the Authentication.config.openshift.io status.
+func (c *AuthStatusHandler) HasOIDCClientStatus(authnConfig *configv1.Authentication) (bool, error) {
+	applyConfig, err := configv1ac.ExtractAuthenticationStatus(authnConfig, c.fieldManager)
+	if err != nil {
+		return false, err
+	}
+	return applyConfig.Status != nil && len(applyConfig.Status.OIDCClients) > 0, nil
+}
+
+// Remove gives up the status of the component applied by the handler, the API server drops
+// the OIDC client status entry once no other field manager owns it.
+func (c *AuthStatusHandler) Remove(ctx context.Context, authnConfig *configv1.Authentication) error {
+	applyConfig := configv1ac.Authentication(authnConfig.Name).WithStatus(configv1ac.AuthenticationStatus())
+	_, err := c.client.ApplyStatus(ctx, applyConfig, metav1.ApplyOptions{FieldManager: c.fieldManager, Force: true})
+	return err
+}
+
 func existingOrNewCondition(applyConfig *configv1ac.AuthenticationApplyConfiguration, conditionType string) *metav1.Condition {
 	var condition *metav1.Condition
 	if applyConfig.Status != nil && len(applyConfig.Status.OIDCClients) > 0 {
@@ -0,0 +1,63 @@
+package status
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAuthState(t *testing.T) {
+	conditions := func(degraded, progressing, available metav1.ConditionStatus) map[string]metav1.Condition {
+		return map[string]metav1.Condition{
+			conditionTypeDegraded:    {Type: conditionTypeDegraded, Status: degraded, Reason: "DegradedReason"},
+			conditionTypeProgressing: {Type: conditionTypeProgressing, Status: progressing, Reason: "ProgressingReason"},
+			conditionTypeAvailable:   {Type: conditionTypeAvailable, Status: available, Reason: "AvailableReason"},
+		}
+	}
+	tests := []struct {
+		name       string
+		conditions map[string]metav1.Condition
+		wantState  string
+		wantReason string
+	}{
+		{
+			name:       "Test degraded client",
+			conditions: conditions(metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionTrue),
+			wantState:  "Degraded",
+			wantReason: "DegradedReason",
+		},
+		{
+			name:       "Test progressing client",
+			conditions: conditions(metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionFalse),
+			wantState:  "Progressing",
+			wantReason: "ProgressingReason",
+		},
+		{
+			name:       "Test available client",
+			conditions: conditions(metav1.ConditionFalse, metav1.ConditionFalse, metav1.ConditionTrue),
+			wantState:  "Available",
+			wantReason: "AvailableReason",
+		},
+		{
+			name:       "Test unavailable client",
+			conditions: conditions(metav1.ConditionFalse, metav1.ConditionFalse, metav1.ConditionFalse),
+			wantState:  "Unavailable",
+			wantReason: "AvailableReason",
+		},
+		{
+			name:       "Test client never reported",
+			conditions: conditions(metav1.ConditionUnknown, metav1.ConditionUnknown, metav1.ConditionUnknown),
+			wantState:  "Unknown",
+			wantReason: "AvailableReason",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, reason := authState(tt.conditions)
+			if diff := deep.Equal([]string{tt.wantState, tt.wantReason}, []string{state, reason}); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
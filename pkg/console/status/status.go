@@ -76,6 +76,22 @@ func HandleUpgradable(typePrefix string, reason string, err error) ConditionUpda
 	}
 }
 
+// HandleCompleted sets the Progressing condition of typePrefix to false with the reason and
+// message of the last change it completed, eg. when a secret was last rotated.
+func HandleCompleted(typePrefix string, reason string, message string) ConditionUpdate {
+	conditionType := typePrefix + operatorsv1.OperatorStatusTypeProgressing
+	condition := operatorsv1.OperatorCondition{
+		Type:    conditionType,
+		Status:  operatorsv1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	}
+	return ConditionUpdate{
+		ConditionType:  conditionType,
+		StatusUpdateFn: v1helpers.UpdateConditionFn(condition),
+	}
+}
+
 func (c *StatusHandler) ResetConditions(conditions []operatorsv1.OperatorCondition) []ConditionUpdate {
 	updateStatusFuncs := []ConditionUpdate{}
 	for _, condition := range conditions {
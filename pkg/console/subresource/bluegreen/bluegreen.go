@@ -0,0 +1,234 @@
+package bluegreen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+
+	"github.com/openshift/console-operator/bindata"
+	"github.com/openshift/console-operator/pkg/api"
+	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
+	"github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+const (
+	// the green console serving the candidate config is verified, the console route still
+	// sends the traffic to the console
+	PhaseVerifying = "Verifying"
+	// the console route sends the traffic to the green console
+	PhaseCutOver = "CutOver"
+	// the candidate config is applied to console-config, the green console serves the traffic
+	// until the console deployment is updated
+	PhasePromoting = "Promoting"
+	// the console serves the candidate config
+	PhaseCompleted = "Completed"
+	// the candidate config is discarded, the console keeps serving the current config
+	PhaseRolledBack = "RolledBack"
+
+	serviceServingCertAnnotation = "service.beta.openshift.io/serving-cert-secret-name"
+	consoleConfigYamlFile        = "console-config.yaml"
+)
+
+// State of the blue/green rollout of a candidate console-config. It is kept in the green
+// ConfigMap so that a cut over or rollback survives operator restarts, and so that the route
+// controller knows which service the console route sends the traffic to.
+type State struct {
+	// Candidate is the hash of the candidate console-config.yaml
+	Candidate string `json:"candidate"`
+	Phase     string `json:"phase"`
+	// Started is when the current phase started
+	Started time.Time `json:"started"`
+	// Rollback is the value of the rollback annotation handled last, another value rolls back
+	Rollback string `json:"rollback,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// CandidateHash identifies a candidate console-config, a new candidate restarts the rollout.
+func CandidateHash(candidateConfig []byte) string {
+	sum := sha256.Sum256(candidateConfig)
+	return hex.EncodeToString(sum[:])
+}
+
+// NewState starts the rollout of the candidate console-config. The rollback annotation set
+// at that time is considered handled, only a new value rolls back the new rollout.
+func NewState(candidateConfig []byte, rollback string, now time.Time) State {
+	return State{
+		Candidate: CandidateHash(candidateConfig),
+		Phase:     PhaseVerifying,
+		Started:   now.UTC().Truncate(time.Second),
+		Rollback:  rollback,
+	}
+}
+
+// GetState reads the state recorded in the green ConfigMap, the zero State if there is none.
+func GetState(configMap *corev1.ConfigMap) State {
+	state := State{}
+	if configMap == nil {
+		return state
+	}
+	value, ok := configMap.Annotations[api.BlueGreenStateAnnotation]
+	if !ok {
+		return state
+	}
+	if err := json.Unmarshal([]byte(value), &state); err != nil {
+		klog.Warningf("ignoring invalid blue/green state %q: %v", value, err)
+		return State{}
+	}
+	return state
+}
+
+// Serving is true while the green console runs.
+func (s State) Serving() bool {
+	return s.Phase == PhaseVerifying || s.Phase == PhaseCutOver || s.Phase == PhasePromoting
+}
+
+// CutOver is true while the console route sends the traffic to the green console.
+func (s State) CutOver() bool {
+	return s.Phase == PhaseCutOver || s.Phase == PhasePromoting
+}
+
+// NextState moves the rollout forward: a green console that stays healthy for duration gets
+// the console traffic, and once it served it for duration its config is promoted to the
+// console. A new rollback value, or a green console failing its health probes, rolls back
+// the rollout until the config is promoted.
+func NextState(state State, greenDeployment, consoleDeployment *appsv1.Deployment, rollback string, duration time.Duration, now time.Time) State {
+	if !state.Serving() {
+		return state
+	}
+	if state.Phase != PhasePromoting && rollback != state.Rollback {
+		return rolledBack(state, rollback, fmt.Sprintf("rollback requested with %s=%q", api.BlueGreenRollbackAnnotation, rollback), now)
+	}
+
+	switch state.Phase {
+	case PhaseVerifying:
+		if greenDeployment == nil {
+			return state
+		}
+		for _, condition := range greenDeployment.Status.Conditions {
+			if condition.Type == appsv1.DeploymentProgressing && condition.Status == corev1.ConditionFalse && condition.Reason == "ProgressDeadlineExceeded" {
+				return rolledBack(state, rollback, fmt.Sprintf("green console pods did not become ready: %s", condition.Message), now)
+			}
+		}
+		if now.Sub(state.Started) < duration {
+			return state
+		}
+		if !isHealthy(greenDeployment) {
+			return rolledBack(state, rollback, fmt.Sprintf("green console pods were not ready after %s", duration), now)
+		}
+		return nextPhase(state, PhaseCutOver, fmt.Sprintf("green console was healthy for %s", duration), now)
+	case PhaseCutOver:
+		if greenDeployment == nil || !isHealthy(greenDeployment) {
+			return rolledBack(state, rollback, "green console pods became unavailable after the cut over", now)
+		}
+		if now.Sub(state.Started) < duration {
+			return state
+		}
+		return nextPhase(state, PhasePromoting, fmt.Sprintf("green console served the console traffic for %s", duration), now)
+	case PhasePromoting:
+		if consoleDeployment == nil || !isHealthy(consoleDeployment) {
+			return state
+		}
+		return nextPhase(state, PhaseCompleted, "console serves the promoted config", now)
+	}
+	return state
+}
+
+func nextPhase(state State, phase, message string, now time.Time) State {
+	state.Phase = phase
+	state.Started = now.UTC().Truncate(time.Second)
+	state.Message = message
+	return state
+}
+
+func rolledBack(state State, rollback, message string, now time.Time) State {
+	state = nextPhase(state, PhaseRolledBack, message, now)
+	state.Rollback = rollback
+	return state
+}
+
+func isHealthy(deployment *appsv1.Deployment) bool {
+	return deploymentsub.IsAvailableAndUpdated(deployment) && deployment.Status.UnavailableReplicas == 0
+}
+
+// DefaultConfigMap holds the candidate console-config served by the green console along with
+// the rollout state. The candidate config is left out once the green console is removed.
+func DefaultConfigMap(operatorConfig *operatorv1.Console, candidateData map[string]string, state State) *corev1.ConfigMap {
+	meta := util.SharedMeta()
+	meta.Name = api.OpenShiftConsoleGreenConfigMapName
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: meta,
+		Data:       map[string]string{},
+	}
+	if state.Serving() {
+		for k, v := range candidateData {
+			configMap.Data[k] = v
+		}
+	}
+	encodedState, _ := json.Marshal(state)
+	configMap.Annotations[api.BlueGreenStateAnnotation] = string(encodedState)
+	util.AddOwnerRef(configMap, util.OwnerRefFrom(operatorConfig))
+	return configMap
+}
+
+// CandidateConfigMap hands the candidate console-config held back by a blue/green rollout over
+// to the blue/green controller, it exists for as long as console-config is held back.
+func CandidateConfigMap(operatorConfig *operatorv1.Console, candidate *corev1.ConfigMap) *corev1.ConfigMap {
+	meta := util.SharedMeta()
+	meta.Name = api.OpenShiftConsoleCandidateConfigMapName
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: meta,
+		Data:       map[string]string{},
+	}
+	for k, v := range candidate.Data {
+		configMap.Data[k] = v
+	}
+	util.AddOwnerRef(configMap, util.OwnerRefFrom(operatorConfig))
+	return configMap
+}
+
+// CandidateConfig returns the console-config.yaml of a candidate console-config ConfigMap.
+func CandidateConfig(configMap *corev1.ConfigMap) []byte {
+	return []byte(configMap.Data[consoleConfigYamlFile])
+}
+
+// DefaultService selects the green console pods. It gets its own serving certificate, the
+// router verifies the service name when re-encrypting traffic to the green console.
+func DefaultService(operatorConfig *operatorv1.Console) *corev1.Service {
+	service := resourceread.ReadServiceV1OrDie(bindata.MustAsset("assets/services/console-service.yaml"))
+	service.Name = api.OpenShiftConsoleGreenName
+	service.Annotations[serviceServingCertAnnotation] = api.GreenServingCertName
+	service.Spec.Selector = util.LabelsForGreen()
+	util.AddOwnerRef(service, util.OwnerRefFrom(operatorConfig))
+	return service
+}
+
+// ProgressingErr describes a running rollout for the BlueGreenProgressing condition.
+func (s State) ProgressingErr() error {
+	switch s.Phase {
+	case PhaseVerifying:
+		return fmt.Errorf("green console serving the candidate console config is verified since %s", s.Started.Format(time.RFC3339))
+	case PhaseCutOver:
+		return fmt.Errorf("green console serving the candidate console config has the console traffic since %s, set %s to roll back", s.Started.Format(time.RFC3339), api.BlueGreenRollbackAnnotation)
+	case PhasePromoting:
+		return fmt.Errorf("candidate console config is promoted to the console since %s", s.Started.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// RolledBackErr describes a rolled back rollout for the BlueGreenDegraded condition. The
+// console keeps the current config until the configuration changes again.
+func (s State) RolledBackErr() error {
+	if s.Phase != PhaseRolledBack {
+		return nil
+	}
+	return fmt.Errorf("candidate console config was rolled back: %s", s.Message)
+}
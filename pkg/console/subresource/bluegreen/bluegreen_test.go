@@ -0,0 +1,194 @@
+package bluegreen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestNextState(t *testing.T) {
+	started := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	verifying := State{Candidate: "abc", Phase: PhaseVerifying, Started: started, Rollback: "1"}
+	cutOver := State{Candidate: "abc", Phase: PhaseCutOver, Started: started, Rollback: "1"}
+	promoting := State{Candidate: "abc", Phase: PhasePromoting, Started: started, Rollback: "1"}
+	replicas := int32(2)
+	healthy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Generation: 1},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 1,
+			Replicas:           2,
+			UpdatedReplicas:    2,
+			AvailableReplicas:  2,
+			ReadyReplicas:      2,
+		},
+	}
+	unavailable := healthy.DeepCopy()
+	unavailable.Status.AvailableReplicas = 0
+	unavailable.Status.ReadyReplicas = 0
+	unavailable.Status.UnavailableReplicas = 2
+	deadlineExceeded := unavailable.DeepCopy()
+	deadlineExceeded.Status.Conditions = []appsv1.DeploymentCondition{{
+		Type:    appsv1.DeploymentProgressing,
+		Status:  corev1.ConditionFalse,
+		Reason:  "ProgressDeadlineExceeded",
+		Message: `ReplicaSet "console-green-5d9c" has timed out progressing.`,
+	}}
+	updating := healthy.DeepCopy()
+	updating.Generation = 2
+
+	tests := []struct {
+		name              string
+		state             State
+		greenDeployment   *appsv1.Deployment
+		consoleDeployment *appsv1.Deployment
+		rollback          string
+		now               time.Time
+		want              State
+	}{
+		{
+			name:            "Test healthy green console before its duration",
+			state:           verifying,
+			greenDeployment: healthy,
+			rollback:        "1",
+			now:             started.Add(2 * time.Minute),
+			want:            verifying,
+		},
+		{
+			name:            "Test healthy green console is cut over after its duration",
+			state:           verifying,
+			greenDeployment: healthy,
+			rollback:        "1",
+			now:             started.Add(5 * time.Minute),
+			want:            State{Candidate: "abc", Phase: PhaseCutOver, Started: started.Add(5 * time.Minute), Rollback: "1", Message: "green console was healthy for 5m0s"},
+		},
+		{
+			name:            "Test unavailable green console is rolled back after its duration",
+			state:           verifying,
+			greenDeployment: unavailable,
+			rollback:        "1",
+			now:             started.Add(6 * time.Minute),
+			want:            State{Candidate: "abc", Phase: PhaseRolledBack, Started: started.Add(6 * time.Minute), Rollback: "1", Message: "green console pods were not ready after 5m0s"},
+		},
+		{
+			name:            "Test green console exceeding its progress deadline is rolled back early",
+			state:           verifying,
+			greenDeployment: deadlineExceeded,
+			rollback:        "1",
+			now:             started.Add(time.Minute),
+			want:            State{Candidate: "abc", Phase: PhaseRolledBack, Started: started.Add(time.Minute), Rollback: "1", Message: `green console pods did not become ready: ReplicaSet "console-green-5d9c" has timed out progressing.`},
+		},
+		{
+			name:            "Test rollback requested during the verification",
+			state:           verifying,
+			greenDeployment: healthy,
+			rollback:        "2",
+			now:             started.Add(time.Minute),
+			want:            State{Candidate: "abc", Phase: PhaseRolledBack, Started: started.Add(time.Minute), Rollback: "2", Message: `rollback requested with console.operator.openshift.io/blue-green-rollback="2"`},
+		},
+		{
+			name:            "Test rollback requested after the cut over",
+			state:           cutOver,
+			greenDeployment: healthy,
+			rollback:        "",
+			now:             started.Add(time.Minute),
+			want:            State{Candidate: "abc", Phase: PhaseRolledBack, Started: started.Add(time.Minute), Message: `rollback requested with console.operator.openshift.io/blue-green-rollback=""`},
+		},
+		{
+			name:            "Test green console becoming unavailable after the cut over is rolled back",
+			state:           cutOver,
+			greenDeployment: unavailable,
+			rollback:        "1",
+			now:             started.Add(time.Minute),
+			want:            State{Candidate: "abc", Phase: PhaseRolledBack, Started: started.Add(time.Minute), Rollback: "1", Message: "green console pods became unavailable after the cut over"},
+		},
+		{
+			name:            "Test cut over green console is promoted after its duration",
+			state:           cutOver,
+			greenDeployment: healthy,
+			rollback:        "1",
+			now:             started.Add(5 * time.Minute),
+			want:            State{Candidate: "abc", Phase: PhasePromoting, Started: started.Add(5 * time.Minute), Rollback: "1", Message: "green console served the console traffic for 5m0s"},
+		},
+		{
+			name:              "Test promotion waits for the console deployment",
+			state:             promoting,
+			greenDeployment:   healthy,
+			consoleDeployment: updating,
+			rollback:          "2",
+			now:               started.Add(time.Minute),
+			want:              promoting,
+		},
+		{
+			name:              "Test promotion completes once the console deployment is updated",
+			state:             promoting,
+			greenDeployment:   healthy,
+			consoleDeployment: healthy,
+			rollback:          "1",
+			now:               started.Add(time.Minute),
+			want:              State{Candidate: "abc", Phase: PhaseCompleted, Started: started.Add(time.Minute), Rollback: "1", Message: "console serves the promoted config"},
+		},
+		{
+			name:            "Test rolled back rollout stays rolled back",
+			state:           State{Candidate: "abc", Phase: PhaseRolledBack, Started: started, Rollback: "1"},
+			greenDeployment: healthy,
+			rollback:        "2",
+			now:             started.Add(time.Hour),
+			want:            State{Candidate: "abc", Phase: PhaseRolledBack, Started: started, Rollback: "1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NextState(tt.state, tt.greenDeployment, tt.consoleDeployment, tt.rollback, 5*time.Minute, tt.now)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestGetState(t *testing.T) {
+	tests := []struct {
+		name      string
+		configMap *corev1.ConfigMap
+		want      State
+	}{
+		{
+			name:      "Test missing ConfigMap",
+			configMap: nil,
+			want:      State{},
+		},
+		{
+			name: "Test recorded state",
+			configMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					api.BlueGreenStateAnnotation: `{"candidate":"abc","phase":"CutOver","started":"2024-01-01T12:00:00Z","rollback":"1"}`,
+				}},
+			},
+			want: State{Candidate: "abc", Phase: PhaseCutOver, Started: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), Rollback: "1"},
+		},
+		{
+			name: "Test invalid state is ignored",
+			configMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					api.BlueGreenStateAnnotation: `CutOver`,
+				}},
+			},
+			want: State{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(GetState(tt.configMap), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package canary
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+
+	"github.com/openshift/console-operator/bindata"
+	"github.com/openshift/console-operator/pkg/api"
+	deploymentsub "github.com/openshift/console-operator/pkg/console/subresource/deployment"
+	"github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+const (
+	// the candidate config is served by the canary deployment
+	PhaseProgressing = "Progressing"
+	// the canary stayed healthy, the candidate config is applied to the console
+	PhasePromoted = "Promoted"
+	// the canary failed, the candidate config is discarded
+	PhaseRolledBack = "RolledBack"
+
+	serviceServingCertAnnotation = "service.beta.openshift.io/serving-cert-secret-name"
+	consoleConfigYamlFile        = "console-config.yaml"
+)
+
+// State of the canary of a candidate config. It is kept in the canary ConfigMap so that
+// a promotion or rollback survives operator restarts.
+type State struct {
+	// Candidate is the hash of the candidate config overrides
+	Candidate string    `json:"candidate"`
+	Phase     string    `json:"phase"`
+	Started   time.Time `json:"started"`
+	// Weight is the percentage of the console traffic served by the canary
+	Weight int `json:"weight,omitempty"`
+	// Ready is set once the canary pods are ready, losing them afterwards rolls the canary back
+	Ready   bool   `json:"ready,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// CandidateHash identifies a candidate config, a new candidate restarts the canary.
+func CandidateHash(overrides []byte) string {
+	sum := sha256.Sum256(overrides)
+	return hex.EncodeToString(sum[:])
+}
+
+// NewState starts the canary of the candidate config overrides.
+func NewState(overrides []byte, now time.Time) State {
+	return State{
+		Candidate: CandidateHash(overrides),
+		Phase:     PhaseProgressing,
+		Started:   now.UTC().Truncate(time.Second),
+	}
+}
+
+// GetState reads the state recorded in the canary ConfigMap, the zero State if there is none.
+func GetState(configMap *corev1.ConfigMap) State {
+	state := State{}
+	if configMap == nil {
+		return state
+	}
+	value, ok := configMap.Annotations[api.CanaryStateAnnotation]
+	if !ok {
+		return state
+	}
+	if err := json.Unmarshal([]byte(value), &state); err != nil {
+		klog.Warningf("ignoring invalid canary state %q: %v", value, err)
+		return State{}
+	}
+	return state
+}
+
+// NextState moves a progressing canary to promoted once it has been available for duration,
+// or to rolled back if its pods fail their health probes until then. A canary whose pods stop
+// being ready after they have been ready is rolled back right away.
+func NextState(state State, deployment *appsv1.Deployment, duration time.Duration, now time.Time) State {
+	if state.Phase != PhaseProgressing || deployment == nil {
+		return state
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Status == corev1.ConditionFalse && condition.Reason == "ProgressDeadlineExceeded" {
+			state.Phase = PhaseRolledBack
+			state.Message = fmt.Sprintf("canary pods did not become ready: %s", condition.Message)
+			return state
+		}
+	}
+	ready := deploymentsub.IsAvailableAndUpdated(deployment) && deployment.Status.UnavailableReplicas == 0
+	if state.Ready && deployment.Status.ReadyReplicas == 0 {
+		state.Phase = PhaseRolledBack
+		state.Message = "canary pods stopped being ready"
+		return state
+	}
+	state.Ready = state.Ready || ready
+	if now.Sub(state.Started) < duration {
+		return state
+	}
+	if ready {
+		state.Phase = PhasePromoted
+		state.Message = fmt.Sprintf("canary was healthy for %s", duration)
+		return state
+	}
+	state.Phase = PhaseRolledBack
+	state.Message = fmt.Sprintf("canary pods were not ready after %s", duration)
+	return state
+}
+
+// DefaultConfigMap holds the candidate console-config served by the canary along with the
+// canary state. The candidate config is left out once the canary is over.
+func DefaultConfigMap(operatorConfig *operatorv1.Console, candidateConfig []byte, state State) *corev1.ConfigMap {
+	meta := util.SharedMeta()
+	meta.Name = api.OpenShiftConsoleCanaryConfigMapName
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: meta,
+		Data:       map[string]string{},
+	}
+	if state.Phase == PhaseProgressing {
+		configMap.Data[consoleConfigYamlFile] = string(candidateConfig)
+	}
+	encodedState, _ := json.Marshal(state)
+	configMap.Annotations[api.CanaryStateAnnotation] = string(encodedState)
+	util.AddOwnerRef(configMap, util.OwnerRefFrom(operatorConfig))
+	return configMap
+}
+
+// DefaultService selects the canary pods. It gets its own serving certificate, the router
+// verifies the service name when re-encrypting traffic to the canary.
+func DefaultService(operatorConfig *operatorv1.Console) *corev1.Service {
+	service := resourceread.ReadServiceV1OrDie(bindata.MustAsset("assets/services/console-service.yaml"))
+	service.Name = api.OpenShiftConsoleCanaryName
+	service.Annotations[serviceServingCertAnnotation] = api.CanaryServingCertName
+	service.Spec.Selector = util.LabelsForCanary()
+	util.AddOwnerRef(service, util.OwnerRefFrom(operatorConfig))
+	return service
+}
+
+// Routed is true while the console route splits the traffic with the canary: the canary is
+// progressing and its pods have been ready, so the canary deployment runs.
+func (s State) Routed() bool {
+	return s.Phase == PhaseProgressing && s.Ready
+}
+
+// ProgressingErr describes a running canary for the CanaryProgressing condition.
+func (s State) ProgressingErr() error {
+	if s.Phase != PhaseProgressing {
+		return nil
+	}
+	return fmt.Errorf("canary of the candidate console config started at %s is serving %d%% of the console traffic", s.Started.Format(time.RFC3339), s.Weight)
+}
+
+// RolledBackErr describes a failed canary for the CanaryDegraded condition.
+func (s State) RolledBackErr() error {
+	if s.Phase != PhaseRolledBack {
+		return nil
+	}
+	return fmt.Errorf("candidate console config was rolled back: %s", s.Message)
+}
@@ -0,0 +1,147 @@
+package canary
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestNextState(t *testing.T) {
+	started := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	progressing := State{Candidate: "abc", Phase: PhaseProgressing, Started: started}
+	replicas := int32(1)
+	healthy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Generation: 1},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 1,
+			Replicas:           1,
+			UpdatedReplicas:    1,
+			AvailableReplicas:  1,
+			ReadyReplicas:      1,
+		},
+	}
+	unavailable := healthy.DeepCopy()
+	unavailable.Status.AvailableReplicas = 0
+	unavailable.Status.ReadyReplicas = 0
+	unavailable.Status.UnavailableReplicas = 1
+	deadlineExceeded := unavailable.DeepCopy()
+	deadlineExceeded.Status.Conditions = []appsv1.DeploymentCondition{{
+		Type:    appsv1.DeploymentProgressing,
+		Status:  corev1.ConditionFalse,
+		Reason:  "ProgressDeadlineExceeded",
+		Message: `ReplicaSet "console-canary-5d9c" has timed out progressing.`,
+	}}
+
+	tests := []struct {
+		name       string
+		state      State
+		deployment *appsv1.Deployment
+		now        time.Time
+		want       State
+	}{
+		{
+			name:       "Test unavailable canary before its duration",
+			state:      progressing,
+			deployment: unavailable,
+			now:        started.Add(5 * time.Minute),
+			want:       progressing,
+		},
+		{
+			name:       "Test healthy canary before its duration",
+			state:      progressing,
+			deployment: healthy,
+			now:        started.Add(5 * time.Minute),
+			want:       State{Candidate: "abc", Phase: PhaseProgressing, Started: started, Ready: true},
+		},
+		{
+			name:       "Test healthy canary is promoted after its duration",
+			state:      progressing,
+			deployment: healthy,
+			now:        started.Add(15 * time.Minute),
+			want:       State{Candidate: "abc", Phase: PhasePromoted, Started: started, Ready: true, Message: "canary was healthy for 15m0s"},
+		},
+		{
+			name:       "Test canary losing its ready pods is rolled back early",
+			state:      State{Candidate: "abc", Phase: PhaseProgressing, Started: started, Ready: true},
+			deployment: unavailable,
+			now:        started.Add(10 * time.Minute),
+			want:       State{Candidate: "abc", Phase: PhaseRolledBack, Started: started, Ready: true, Message: "canary pods stopped being ready"},
+		},
+		{
+			name:       "Test unavailable canary is rolled back after its duration",
+			state:      progressing,
+			deployment: unavailable,
+			now:        started.Add(20 * time.Minute),
+			want:       State{Candidate: "abc", Phase: PhaseRolledBack, Started: started, Message: "canary pods were not ready after 15m0s"},
+		},
+		{
+			name:       "Test canary exceeding its progress deadline is rolled back early",
+			state:      progressing,
+			deployment: deadlineExceeded,
+			now:        started.Add(10 * time.Minute),
+			want:       State{Candidate: "abc", Phase: PhaseRolledBack, Started: started, Message: `canary pods did not become ready: ReplicaSet "console-canary-5d9c" has timed out progressing.`},
+		},
+		{
+			name:       "Test rolled back canary stays rolled back",
+			state:      State{Candidate: "abc", Phase: PhaseRolledBack, Started: started},
+			deployment: healthy,
+			now:        started.Add(time.Hour),
+			want:       State{Candidate: "abc", Phase: PhaseRolledBack, Started: started},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NextState(tt.state, tt.deployment, 15*time.Minute, tt.now)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestGetState(t *testing.T) {
+	tests := []struct {
+		name      string
+		configMap *corev1.ConfigMap
+		want      State
+	}{
+		{
+			name:      "Test missing ConfigMap",
+			configMap: nil,
+			want:      State{},
+		},
+		{
+			name: "Test recorded state",
+			configMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					api.CanaryStateAnnotation: `{"candidate":"abc","phase":"Promoted","started":"2024-01-01T12:00:00Z","weight":10}`,
+				}},
+			},
+			want: State{Candidate: "abc", Phase: PhasePromoted, Started: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), Weight: 10},
+		},
+		{
+			name: "Test invalid state is ignored",
+			configMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					api.CanaryStateAnnotation: `Promoted`,
+				}},
+			},
+			want: State{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(GetState(tt.configMap), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
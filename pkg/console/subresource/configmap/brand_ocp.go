@@ -4,6 +4,7 @@
 package configmap
 
 const (
-	DEFAULT_BRAND   = "ocp"
-	DEFAULT_DOC_URL = "https://access.redhat.com/documentation/en-us/openshift_container_platform/4.16/"
+	DEFAULT_BRAND                  = "ocp"
+	DEFAULT_DOC_URL                = "https://access.redhat.com/documentation/en-us/openshift_container_platform/4.16/"
+	UPDATE_TROUBLESHOOTING_DOC_URL = DEFAULT_DOC_URL + "html/updating_clusters/troubleshooting-a-cluster-update"
 )
@@ -4,6 +4,7 @@
 package configmap
 
 const (
-	DEFAULT_BRAND   = "okd"
-	DEFAULT_DOC_URL = "https://docs.okd.io/latest/"
+	DEFAULT_BRAND                  = "okd"
+	DEFAULT_DOC_URL                = "https://docs.okd.io/latest/"
+	UPDATE_TROUBLESHOOTING_DOC_URL = DEFAULT_DOC_URL + "updating/troubleshooting_updates/gathering-data-cluster-update.html"
 )
@@ -0,0 +1,88 @@
+package configmap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/ghodss/yaml"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+const redactedValue = "<redacted>"
+
+// values of fields matching sensitiveFieldRegexp never end up in events, eg. telemetry API keys.
+// Fields naming a file only hold a path and are kept.
+var (
+	sensitiveFieldRegexp = regexp.MustCompile(`(?i)(secret|token|password|api_?key)`)
+	fileFieldRegexp      = regexp.MustCompile(`File$`)
+)
+
+// ConfigChange compares the console-config.yaml about to be written with the existing one. If it changed,
+// it chains the change to the previous ones in the change chain annotation of required and returns a diff of
+// the config with sensitive values redacted. Each link hashes the previous link with the new config, so
+// a rewrite made without the operator breaks the chain.
+func ConfigChange(existing, required *corev1.ConfigMap) (diff string, changed bool) {
+	if required.Annotations == nil {
+		required.Annotations = map[string]string{}
+	}
+	previousConfig, previousChain := "", ""
+	if existing != nil {
+		previousConfig = existing.Data[consoleConfigYamlFile]
+		previousChain = existing.Annotations[api.ConfigMapChangeChainAnnotation]
+	}
+	requiredConfig := required.Data[consoleConfigYamlFile]
+	if existing != nil && previousConfig == requiredConfig {
+		if len(previousChain) > 0 {
+			required.Annotations[api.ConfigMapChangeChainAnnotation] = previousChain
+		}
+		return "", false
+	}
+
+	required.Annotations[api.ConfigMapChangeChainAnnotation] = chainHash(previousChain, requiredConfig)
+	return redactedConfigDiff(previousConfig, requiredConfig), true
+}
+
+// redactedConfigDiff is the diff between two console-config.yaml with sensitive values redacted.
+func redactedConfigDiff(previousConfig, requiredConfig string) string {
+	return cmp.Diff(redactedConfig(previousConfig), redactedConfig(requiredConfig))
+}
+
+func chainHash(previousChain, config string) string {
+	sum := sha256.Sum256([]byte(previousChain + "\n" + config))
+	return hex.EncodeToString(sum[:])
+}
+
+// redactedConfig parses a console-config.yaml, a config that does not parse is compared as a whole
+// but never shown.
+func redactedConfig(configYAML string) interface{} {
+	if len(configYAML) == 0 {
+		return map[string]interface{}{}
+	}
+	config := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(configYAML), &config); err != nil {
+		return redactedValue
+	}
+	return redact(config)
+}
+
+func redact(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range typed {
+			if sensitiveFieldRegexp.MatchString(key) && !fileFieldRegexp.MatchString(key) {
+				typed[key] = redactedValue
+				continue
+			}
+			typed[key] = redact(nested)
+		}
+	case []interface{}:
+		for i, nested := range typed {
+			typed[i] = redact(nested)
+		}
+	}
+	return value
+}
@@ -0,0 +1,105 @@
+package configmap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestConfigChange(t *testing.T) {
+	consoleConfig := func(config, chain string) *corev1.ConfigMap {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{}},
+			Data:       map[string]string{consoleConfigYamlFile: config},
+		}
+		if len(chain) > 0 {
+			configMap.Annotations[api.ConfigMapChangeChainAnnotation] = chain
+		}
+		return configMap
+	}
+	oldConfig := `kind: ConsoleConfig
+telemetry:
+  SEGMENT_API_KEY: old-key
+customization:
+  branding: ocp
+`
+	newConfig := `kind: ConsoleConfig
+telemetry:
+  SEGMENT_API_KEY: new-key
+customization:
+  branding: okd
+`
+	keyOnlyConfig := strings.Replace(oldConfig, "old-key", "rotated-key", 1)
+
+	tests := []struct {
+		name          string
+		existing      *corev1.ConfigMap
+		required      *corev1.ConfigMap
+		wantChanged   bool
+		wantChain     string
+		wantInDiff    []string
+		wantNotInDiff []string
+	}{
+		{
+			name:        "Test unchanged config keeps the chain",
+			existing:    consoleConfig(oldConfig, "previous"),
+			required:    consoleConfig(oldConfig, ""),
+			wantChanged: false,
+			wantChain:   "previous",
+		},
+		{
+			name:          "Test changed config extends the chain with a redacted diff",
+			existing:      consoleConfig(oldConfig, "previous"),
+			required:      consoleConfig(newConfig, ""),
+			wantChanged:   true,
+			wantChain:     chainHash("previous", newConfig),
+			wantInDiff:    []string{"ocp", "okd"},
+			wantNotInDiff: []string{"old-key", "new-key"},
+		},
+		{
+			name:          "Test change of redacted fields only",
+			existing:      consoleConfig(oldConfig, "previous"),
+			required:      consoleConfig(keyOnlyConfig, ""),
+			wantChanged:   true,
+			wantChain:     chainHash("previous", keyOnlyConfig),
+			wantNotInDiff: []string{"old-key", "rotated-key"},
+		},
+		{
+			name:        "Test new config map starts the chain",
+			existing:    nil,
+			required:    consoleConfig(newConfig, ""),
+			wantChanged: true,
+			wantChain:   chainHash("", newConfig),
+			wantInDiff:  []string{"okd"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, changed := ConfigChange(tt.existing, tt.required)
+			if diff := deep.Equal(changed, tt.wantChanged); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(tt.required.Annotations[api.ConfigMapChangeChainAnnotation], tt.wantChain); diff != nil {
+				t.Error(diff)
+			}
+			if tt.wantInDiff == nil && tt.wantNotInDiff == nil && len(diff) > 0 {
+				t.Errorf("unexpected diff %q", diff)
+			}
+			for _, want := range tt.wantInDiff {
+				if !strings.Contains(diff, want) {
+					t.Errorf("diff %q does not contain %q", diff, want)
+				}
+			}
+			for _, unwanted := range tt.wantNotInDiff {
+				if strings.Contains(diff, unwanted) {
+					t.Errorf("diff %q contains %q", diff, unwanted)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package configmap
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+
+	"github.com/openshift/console-operator/bindata"
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
+)
+
+const (
+	clusterProxyClustersKey = "clusters.yaml"
+	clusterProxyHealthKey   = "health.yaml"
+	// CA bundles are added to console-config next to console-config.yaml and mounted along with it
+	consoleConfigMountDir   = "/var/console-config"
+	clusterProxyCAKeyPrefix = "cluster-proxy-ca-"
+)
+
+// ClusterProxyCluster is a managed cluster listed in the clusters.yaml key of the ConfigMap
+// referenced by the cluster-proxy-config annotation.
+type ClusterProxyCluster struct {
+	Name string `json:"name"`
+	// Endpoint is the wss:// URL of the cluster-proxy websocket endpoint of the cluster
+	Endpoint string `json:"endpoint"`
+	// TokenAudience is the audience of the tokens the console sends to the endpoint
+	TokenAudience string `json:"tokenAudience"`
+	// CAKey is the key of the ConfigMap holding the PEM CA bundle of the endpoint,
+	// the console trusts the system roots without one
+	CAKey string `json:"caKey,omitempty"`
+
+	caBundle string
+	// unhealthy is why the endpoint is left out of console-config, if it is
+	unhealthy string
+}
+
+// CABundle is the PEM CA bundle referenced by CAKey.
+func (c ClusterProxyCluster) CABundle() string {
+	return c.caBundle
+}
+
+// ClusterProxyHealth is the outcome of the probes of the cluster-proxy endpoint of a cluster,
+// listed in the health.yaml key of the cluster-proxy-health ConfigMap.
+type ClusterProxyHealth struct {
+	Name     string `json:"name"`
+	Endpoint string `json:"endpoint"`
+	Healthy  bool   `json:"healthy"`
+	// FailingSince is the first failed probe of the endpoint since it last answered
+	FailingSince *metav1.Time `json:"failingSince,omitempty"`
+	Message      string       `json:"message,omitempty"`
+}
+
+// ValidateClusterProxyConfig checks the data of a cluster-proxy config ConfigMap. Invalid clusters
+// are left out and reported in the returned error, the valid ones are returned sorted by name.
+func ValidateClusterProxyConfig(data map[string]string) ([]ClusterProxyCluster, error) {
+	clustersYAML, ok := data[clusterProxyClustersKey]
+	if !ok {
+		return nil, fmt.Errorf("invalid cluster-proxy config: missing %s", clusterProxyClustersKey)
+	}
+	clusters := []ClusterProxyCluster{}
+	if err := yaml.Unmarshal([]byte(clustersYAML), &clusters); err != nil {
+		return nil, fmt.Errorf("invalid cluster-proxy config: %s: %w", clusterProxyClustersKey, err)
+	}
+
+	valid := []ClusterProxyCluster{}
+	invalid := []string{}
+	names := map[string]bool{}
+	for i, cluster := range clusters {
+		var err error
+		switch {
+		case len(validation.IsDNS1123Label(cluster.Name)) > 0:
+			err = fmt.Errorf("name %q must be a DNS label", cluster.Name)
+		case names[cluster.Name]:
+			err = fmt.Errorf("name %q is listed more than once", cluster.Name)
+		case len(cluster.TokenAudience) == 0:
+			err = fmt.Errorf("tokenAudience is required")
+		default:
+			err = validateClusterProxyEndpoint(cluster.Endpoint)
+		}
+		if err == nil && len(cluster.CAKey) > 0 {
+			cluster.caBundle = data[cluster.CAKey]
+			err = validateCABundle(cluster.CAKey, cluster.caBundle)
+		}
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("clusters[%d]: %v", i, err))
+			continue
+		}
+		names[cluster.Name] = true
+		valid = append(valid, cluster)
+	}
+	sort.Slice(valid, func(i, j int) bool { return valid[i].Name < valid[j].Name })
+	if len(invalid) > 0 {
+		return valid, fmt.Errorf("invalid cluster-proxy config: %s", strings.Join(invalid, ", "))
+	}
+	return valid, nil
+}
+
+func validateClusterProxyEndpoint(endpoint string) error {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Scheme != "wss" || len(parsed.Host) == 0 {
+		return fmt.Errorf("endpoint %q must be a wss:// URL", endpoint)
+	}
+	return nil
+}
+
+func validateCABundle(key, caBundle string) error {
+	if len(caBundle) == 0 {
+		return fmt.Errorf("caKey %q is missing from the ConfigMap", key)
+	}
+	rest := []byte(caBundle)
+	found := false
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return fmt.Errorf("caKey %q holds an invalid certificate: %v", key, err)
+		}
+		found = true
+	}
+	if !found {
+		return fmt.Errorf("caKey %q holds no PEM certificates", key)
+	}
+	return nil
+}
+
+// ReadClusterProxyHealth returns the probe results of the cluster-proxy-health ConfigMap.
+func ReadClusterProxyHealth(data map[string]string) ([]ClusterProxyHealth, error) {
+	health := []ClusterProxyHealth{}
+	if err := yaml.Unmarshal([]byte(data[clusterProxyHealthKey]), &health); err != nil {
+		return nil, fmt.Errorf("invalid cluster-proxy health: %s: %w", clusterProxyHealthKey, err)
+	}
+	return health, nil
+}
+
+// WithClusterProxyHealth returns clusters with the ones whose current endpoint is unhealthy
+// marked to be left out of console-config.
+func WithClusterProxyHealth(clusters []ClusterProxyCluster, health []ClusterProxyHealth) []ClusterProxyCluster {
+	unhealthy := map[string]ClusterProxyHealth{}
+	for _, clusterHealth := range health {
+		if !clusterHealth.Healthy {
+			unhealthy[clusterHealth.Name] = clusterHealth
+		}
+	}
+	withHealth := make([]ClusterProxyCluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		// the results of a previous endpoint do not apply
+		if clusterHealth, ok := unhealthy[cluster.Name]; ok && clusterHealth.Endpoint == cluster.Endpoint {
+			cluster.unhealthy = clusterHealth.Message
+			if len(cluster.unhealthy) == 0 {
+				cluster.unhealthy = "the endpoint is unreachable"
+			}
+		}
+		withHealth = append(withHealth, cluster)
+	}
+	return withHealth
+}
+
+func DefaultClusterProxyHealthConfigMap(health []ClusterProxyHealth) (*corev1.ConfigMap, error) {
+	healthYAML, err := yaml.Marshal(health)
+	if err != nil {
+		return nil, err
+	}
+	configMap := ClusterProxyHealthConfigMapStub()
+	configMap.Data = map[string]string{
+		clusterProxyHealthKey: string(healthYAML),
+	}
+	return configMap, nil
+}
+
+func ClusterProxyHealthConfigMapStub() *corev1.ConfigMap {
+	return resourceread.ReadConfigMapV1OrDie(bindata.MustAsset("assets/configmaps/cluster-proxy-health-configmap.yaml"))
+}
+
+func clusterProxyCAKey(cluster ClusterProxyCluster) string {
+	return clusterProxyCAKeyPrefix + cluster.Name + ".crt"
+}
+
+// getClusterProxyClusters renders the clusters for console-config, pointing at the CA bundles
+// returned by getClusterProxyCABundles.
+func getClusterProxyClusters(clusters []ClusterProxyCluster) []consoleserver.ClusterProxyCluster {
+	if len(clusters) == 0 {
+		return nil
+	}
+	rendered := make([]consoleserver.ClusterProxyCluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		if len(cluster.unhealthy) > 0 {
+			continue
+		}
+		renderedCluster := consoleserver.ClusterProxyCluster{
+			Name:          cluster.Name,
+			Endpoint:      cluster.Endpoint,
+			TokenAudience: cluster.TokenAudience,
+		}
+		if len(cluster.caBundle) > 0 {
+			renderedCluster.CAFile = path.Join(consoleConfigMountDir, clusterProxyCAKey(cluster))
+		}
+		rendered = append(rendered, renderedCluster)
+	}
+	return rendered
+}
+
+// getUnhealthyClusterProxyClusters lists the clusters left out by getClusterProxyClusters, so
+// that the console shows them as unavailable instead of not at all.
+func getUnhealthyClusterProxyClusters(clusters []ClusterProxyCluster) []consoleserver.UnhealthyClusterProxyCluster {
+	var unhealthy []consoleserver.UnhealthyClusterProxyCluster
+	for _, cluster := range clusters {
+		if len(cluster.unhealthy) > 0 {
+			unhealthy = append(unhealthy, consoleserver.UnhealthyClusterProxyCluster{Name: cluster.Name, Message: cluster.unhealthy})
+		}
+	}
+	return unhealthy
+}
+
+// getClusterProxyCABundles returns the console-config keys of the cluster CA bundles.
+func getClusterProxyCABundles(clusters []ClusterProxyCluster) map[string]string {
+	caBundles := map[string]string{}
+	for _, cluster := range clusters {
+		if len(cluster.caBundle) > 0 && len(cluster.unhealthy) == 0 {
+			caBundles[clusterProxyCAKey(cluster)] = cluster.caBundle
+		}
+	}
+	return caBundles
+}
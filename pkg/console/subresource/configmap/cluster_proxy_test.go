@@ -0,0 +1,150 @@
+package configmap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
+)
+
+func TestValidateClusterProxyConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		want    []ClusterProxyCluster
+		wantErr string
+	}{
+		{
+			name: "Test valid clusters are sorted by name",
+			data: map[string]string{
+				"clusters.yaml": `- name: west
+  endpoint: wss://proxy.west.example.com/cluster-proxy
+  tokenAudience: west
+- name: east
+  endpoint: wss://proxy.east.example.com/cluster-proxy
+  tokenAudience: east
+  caKey: east-ca.crt
+`,
+				"east-ca.crt": validCertificate,
+			},
+			want: []ClusterProxyCluster{
+				{Name: "east", Endpoint: "wss://proxy.east.example.com/cluster-proxy", TokenAudience: "east", CAKey: "east-ca.crt", caBundle: validCertificate},
+				{Name: "west", Endpoint: "wss://proxy.west.example.com/cluster-proxy", TokenAudience: "west"},
+			},
+		},
+		{
+			name:    "Test missing clusters.yaml",
+			data:    map[string]string{},
+			wantErr: "invalid cluster-proxy config: missing clusters.yaml",
+		},
+		{
+			name: "Test invalid clusters are left out",
+			data: map[string]string{
+				"clusters.yaml": `- name: east
+  endpoint: wss://proxy.east.example.com/cluster-proxy
+  tokenAudience: east
+- name: east
+  endpoint: wss://proxy.east.example.com/cluster-proxy
+  tokenAudience: east
+- name: West
+  endpoint: wss://proxy.west.example.com/cluster-proxy
+  tokenAudience: west
+- name: north
+  endpoint: https://proxy.north.example.com/cluster-proxy
+  tokenAudience: north
+- name: south
+  endpoint: wss://proxy.south.example.com/cluster-proxy
+- name: central
+  endpoint: wss://proxy.central.example.com/cluster-proxy
+  tokenAudience: central
+  caKey: central-ca.crt
+`,
+				"central-ca.crt": "not a certificate",
+			},
+			want: []ClusterProxyCluster{
+				{Name: "east", Endpoint: "wss://proxy.east.example.com/cluster-proxy", TokenAudience: "east"},
+			},
+			wantErr: `invalid cluster-proxy config: clusters[1]: name "east" is listed more than once, clusters[2]: name "West" must be a DNS label, clusters[3]: endpoint "https://proxy.north.example.com/cluster-proxy" must be a wss:// URL, clusters[4]: tokenAudience is required, clusters[5]: caKey "central-ca.crt" holds no PEM certificates`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateClusterProxyConfig(tt.data)
+			gotErr := ""
+			if err != nil {
+				gotErr = err.Error()
+			}
+			if diff := deep.Equal(gotErr, tt.wantErr); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestGetClusterProxyClusters(t *testing.T) {
+	clusters := []ClusterProxyCluster{
+		{Name: "east", Endpoint: "wss://proxy.east.example.com/cluster-proxy", TokenAudience: "east", CAKey: "east-ca.crt", caBundle: validCertificate},
+		{Name: "west", Endpoint: "wss://proxy.west.example.com/cluster-proxy", TokenAudience: "west"},
+	}
+	want := []consoleserver.ClusterProxyCluster{
+		{Name: "east", Endpoint: "wss://proxy.east.example.com/cluster-proxy", TokenAudience: "east", CAFile: "/var/console-config/cluster-proxy-ca-east.crt"},
+		{Name: "west", Endpoint: "wss://proxy.west.example.com/cluster-proxy", TokenAudience: "west"},
+	}
+	if diff := deep.Equal(getClusterProxyClusters(clusters), want); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(getClusterProxyCABundles(clusters), map[string]string{"cluster-proxy-ca-east.crt": validCertificate}); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestWithClusterProxyHealth(t *testing.T) {
+	clusters := []ClusterProxyCluster{
+		{Name: "east", Endpoint: "wss://proxy.east.example.com/cluster-proxy", CAKey: "east-ca.crt", caBundle: validCertificate},
+		{Name: "north", Endpoint: "wss://proxy.north.example.com/cluster-proxy"},
+		{Name: "west", Endpoint: "wss://proxy.west.example.com/cluster-proxy"},
+	}
+	failingSince := metav1.NewTime(time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC))
+	health := []ClusterProxyHealth{
+		{Name: "east", Endpoint: "wss://proxy.east.example.com/cluster-proxy", FailingSince: &failingSince, Message: "connection refused"},
+		// probed before the endpoint moved
+		{Name: "north", Endpoint: "wss://proxy-old.north.example.com/cluster-proxy", FailingSince: &failingSince},
+		{Name: "west", Endpoint: "wss://proxy.west.example.com/cluster-proxy", Healthy: true},
+	}
+	configMap, err := DefaultClusterProxyHealthConfigMap(health)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the console-config side reads back what the controller wrote
+	readHealth, err := ReadClusterProxyHealth(configMap.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(readHealth, health); diff != nil {
+		t.Error(diff)
+	}
+
+	withHealth := WithClusterProxyHealth(clusters, readHealth)
+	wantClusters := []consoleserver.ClusterProxyCluster{
+		{Name: "north", Endpoint: "wss://proxy.north.example.com/cluster-proxy"},
+		{Name: "west", Endpoint: "wss://proxy.west.example.com/cluster-proxy"},
+	}
+	if diff := deep.Equal(getClusterProxyClusters(withHealth), wantClusters); diff != nil {
+		t.Error(diff)
+	}
+	wantUnhealthy := []consoleserver.UnhealthyClusterProxyCluster{
+		{Name: "east", Message: "connection refused"},
+	}
+	if diff := deep.Equal(getUnhealthyClusterProxyClusters(withHealth), wantUnhealthy); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(getClusterProxyCABundles(withHealth), map[string]string{}); diff != nil {
+		t.Error(diff)
+	}
+}
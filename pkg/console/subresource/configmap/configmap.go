@@ -3,6 +3,9 @@ package configmap
 import (
 	"fmt"
 	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
@@ -14,6 +17,7 @@ import (
 	routev1 "github.com/openshift/api/route/v1"
 	"github.com/openshift/console-operator/bindata"
 	"github.com/openshift/console-operator/pkg/api"
+	customerrors "github.com/openshift/console-operator/pkg/console/errors"
 	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
 	"github.com/openshift/console-operator/pkg/console/subresource/util"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
@@ -24,8 +28,11 @@ const (
 	defaultLogoutURL          = ""
 	pluginProxyEndpoint       = "/api/proxy/plugin/"
 	telemetryAnnotationPrefix = "telemetry.console.openshift.io/"
+	telemetryDisabledKey      = "DISABLED"
 )
 
+var telemetryKeyRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
 func getApiUrl(infrastructureConfig *configv1.Infrastructure) string {
 	if infrastructureConfig != nil {
 		return infrastructureConfig.Status.APIServerURL
@@ -45,16 +52,32 @@ func DefaultConfigMap(
 	consoleConfig *configv1.Console,
 	authConfig *configv1.Authentication,
 	authServerCAConfig *corev1.ConfigMap,
+	sessionSecret *corev1.Secret,
 	managedConfig *corev1.ConfigMap,
 	monitoringSharedConfig *corev1.ConfigMap,
 	infrastructureConfig *configv1.Infrastructure,
 	activeConsoleRoute *routev1.Route,
-	inactivityTimeoutSeconds int,
+	sessionPolicy util.SessionPolicy,
+	groupInactivityTimeouts map[string]int,
+	contentSecurityPolicy map[string][]string,
+	telemetryConfig map[string]string,
+	clusterProxyClusters []ClusterProxyCluster,
+	multicluster *MulticlusterConfig,
+	statusProvider *util.StatusProviderConfig,
+	promotedCanaryOverrides []byte,
 	availablePlugins []*v1.ConsolePlugin,
 	nodeArchitectures []string,
 	nodeOperatingSystems []string,
 	copiedCSVsDisabled bool,
-) (consoleConfigMap *corev1.ConfigMap, unsupportedOverridesHaveMerged bool, err error) {
+) (consoleConfigMap *corev1.ConfigMap, overridesResult *consoleserver.OverridesResult, err error) {
+
+	oidcPublicClient := util.IsOIDCPublicClient(operatorConfig, authConfig)
+	// invalid issuer endpoints are reported by the OIDC setup controller
+	oidcIssuerEndpoints, _ := util.GetOIDCIssuerEndpoints(operatorConfig)
+	// so are invalid access log values, rate limits and API client tuning, by the operator
+	accessLog, _ := util.GetAccessLogConfig(operatorConfig)
+	rateLimit, _ := util.GetRateLimitConfig(operatorConfig)
+	apiClient, _ := util.GetAPIClientConfig(operatorConfig)
 
 	defaultBuilder := &consoleserver.ConsoleServerCLIConfigBuilder{}
 	defaultConfig, err := defaultBuilder.Host(activeConsoleRoute.Spec.Host).
@@ -63,7 +86,9 @@ func DefaultConfigMap(
 		DocURL(DEFAULT_DOC_URL).
 		APIServerURL(getApiUrl(infrastructureConfig)).
 		Monitoring(monitoringSharedConfig).
-		InactivityTimeout(inactivityTimeoutSeconds).
+		InactivityTimeout(sessionPolicy.InactivityTimeoutSeconds).
+		AccessTokenMaxAge(sessionPolicy.AccessTokenMaxAgeSeconds).
+		OIDCPublicClient(oidcPublicClient).
 		ReleaseVersion().
 		NodeArchitectures(nodeArchitectures).
 		NodeOperatingSystems(nodeOperatingSystems).
@@ -71,7 +96,7 @@ func DefaultConfigMap(
 		ConfigYAML()
 	if err != nil {
 		klog.Errorf("failed to generate default console-config config: %v", err)
-		return nil, false, err
+		return nil, nil, err
 	}
 
 	extractedManagedConfig := extractYAML(managedConfig)
@@ -95,21 +120,36 @@ func DefaultConfigMap(
 		AddPage(operatorConfig.Spec.Customization.AddPage).
 		Perspectives(operatorConfig.Spec.Customization.Perspectives).
 		StatusPageID(statusPageId(operatorConfig)).
-		InactivityTimeout(inactivityTimeoutSeconds).
-		TelemetryConfiguration(GetTelemetryConfiguration(operatorConfig)).
+		StatusProvider(statusProvider).
+		InactivityTimeout(sessionPolicy.InactivityTimeoutSeconds).
+		AccessTokenMaxAge(sessionPolicy.AccessTokenMaxAgeSeconds).
+		GroupInactivityTimeouts(groupInactivityTimeouts).
+		TelemetryConfiguration(GetTelemetryConfiguration(operatorConfig, telemetryConfig)).
 		ReleaseVersion().
 		NodeArchitectures(nodeArchitectures).
 		NodeOperatingSystems(nodeOperatingSystems).
 		AuthConfig(authConfig, authServerCAConfig).
+		OIDCPublicClient(oidcPublicClient).
+		OIDCTokenValidation(util.GetOIDCTokenValidationConfig(operatorConfig, authConfig)).
+		OIDCIssuerEndpoints(oidcIssuerEndpoints).
+		SessionSecret(sessionSecret).
+		ReadOnly(IsReadOnlyMode(operatorConfig)).
+		AccessLog(accessLog).
+		RateLimit(rateLimit).
+		ServerTuning(util.GetServerTuningConfig(operatorConfig)).
+		APIClient(apiClient).
+		ClusterProxy(getClusterProxyClusters(clusterProxyClusters), getUnhealthyClusterProxyClusters(clusterProxyClusters)).
+		Multicluster(getMulticluster(multicluster)).
+		ManagedClusters(getManagedClusters(multicluster)).
+		ContentSecurityPolicy(util.MergeContentSecurityPolicies(contentSecurityPolicy, getPluginsContentSecurityPolicy(availablePlugins))).
 		ConfigYAML()
 	if err != nil {
 		klog.Errorf("failed to generate user defined console-config config: %v", err)
-		return nil, false, err
+		return nil, nil, err
 	}
 
 	unsupportedConfigOverride := operatorConfig.Spec.UnsupportedConfigOverrides.Raw
-	willMergeConfigOverrides := len(unsupportedConfigOverride) != 0
-	if willMergeConfigOverrides {
+	if len(unsupportedConfigOverride) != 0 {
 		klog.V(4).Infoln(fmt.Sprintf("with UnsupportedConfigOverrides: %v", string(unsupportedConfigOverride)))
 	}
 
@@ -117,19 +157,52 @@ func DefaultConfigMap(
 	mergedConfig, err := merger.Merge(
 		defaultConfig,
 		extractedManagedConfig,
-		userDefinedConfig,
-		unsupportedConfigOverride)
+		userDefinedConfig)
 	if err != nil {
 		klog.Errorf("failed to generate configmap: %v", err)
-		return nil, false, err
+		return nil, nil, err
+	}
+	renderedConfig := mergedConfig
+	mergedConfig, overridesResult, err = merger.MergeOverrides(mergedConfig, unsupportedConfigOverride)
+	if err != nil {
+		klog.Errorf("failed to merge unsupportedConfigOverrides: %v", err)
+		return nil, nil, err
+	}
+	// the overrides diff is recorded in events, sensitive values are redacted like in the config changes
+	if len(overridesResult.Diff) > 0 {
+		overridesResult.Diff = redactedConfigDiff(string(renderedConfig), string(mergedConfig))
+		if len(overridesResult.Diff) == 0 {
+			overridesResult.Diff = redactedValue
+		}
+	}
+	// a promoted canary was rendered on top of unsupportedConfigOverrides, it is applied in the same order
+	mergedConfig, _, err = merger.MergeOverrides(mergedConfig, promotedCanaryOverrides)
+	if err != nil {
+		klog.Errorf("failed to merge promoted canary config: %v", err)
+		return nil, nil, err
+	}
+	// refuse to write a config the console would crash loop on
+	if err := consoleserver.ValidateConfigYAML(mergedConfig); err != nil {
+		klog.Errorf("invalid console-config: %v", err)
+		return nil, overridesResult, customerrors.NewConfigValidationError(err.Error())
 	}
 
 	configMap := Stub()
+	if configMap.Annotations == nil {
+		configMap.Annotations = map[string]string{}
+	}
+	configMap.Annotations[api.SessionPolicyAnnotation] = sessionPolicy.String()
 	configMap.Data = map[string]string{}
 	configMap.Data[consoleConfigYamlFile] = string(mergedConfig)
+	for key, caBundle := range getClusterProxyCABundles(clusterProxyClusters) {
+		configMap.Data[key] = caBundle
+	}
+	for key, caBundle := range getManagedClusterCABundles(multicluster) {
+		configMap.Data[key] = caBundle
+	}
 	util.AddOwnerRef(configMap, util.OwnerRefFrom(operatorConfig))
 
-	return configMap, willMergeConfigOverrides, nil
+	return configMap, overridesResult, nil
 }
 
 func pluginsWithI18nNamespace(availablePlugins []*v1.ConsolePlugin) []string {
@@ -142,6 +215,25 @@ func pluginsWithI18nNamespace(availablePlugins []*v1.ConsolePlugin) []string {
 	return i18nNamespaces
 }
 
+// getPluginsContentSecurityPolicy merges the CSP sources the plugins request with their
+// content-security-policy annotation. A plugin with an invalid policy contributes nothing.
+func getPluginsContentSecurityPolicy(availablePlugins []*v1.ConsolePlugin) map[string][]string {
+	policies := []map[string][]string{}
+	for _, plugin := range availablePlugins {
+		policy, ok := plugin.Annotations[api.PluginCSPAnnotation]
+		if !ok {
+			continue
+		}
+		parsed, err := util.ParseContentSecurityPolicy(policy)
+		if err != nil {
+			klog.Errorf("ignoring content security policy of %q plugin: %v", plugin.Name, err)
+			continue
+		}
+		policies = append(policies, parsed)
+	}
+	return util.MergeContentSecurityPolicies(policies...)
+}
+
 func getPluginsEndpointMap(availablePlugins []*v1.ConsolePlugin) map[string]string {
 	pluginsEndpointMap := map[string]string{}
 	for _, plugin := range availablePlugins {
@@ -177,8 +269,14 @@ func getPluginsProxyServices(availablePlugins []*v1.ConsolePlugin) []consoleserv
 	return proxyServices
 }
 
-func GetTelemetryConfiguration(operatorConfig *operatorv1.Console) map[string]string {
+// GetTelemetryConfiguration merges the telemetry config of the ConfigMap referenced by the
+// telemetry-config annotation with the telemetry.console.openshift.io/ annotations, which take
+// precedence. Telemetry is opted out with DISABLED=true.
+func GetTelemetryConfiguration(operatorConfig *operatorv1.Console, telemetryConfig map[string]string) map[string]string {
 	telemetry := make(map[string]string)
+	for k, v := range telemetryConfig {
+		telemetry[k] = v
+	}
 	if len(operatorConfig.Annotations) > 0 {
 		for k, v := range operatorConfig.Annotations {
 			if strings.HasPrefix(k, telemetryAnnotationPrefix) && len(k) > len(telemetryAnnotationPrefix) {
@@ -189,6 +287,48 @@ func GetTelemetryConfiguration(operatorConfig *operatorv1.Console) map[string]st
 	return telemetry
 }
 
+// ValidateTelemetryConfig checks the data of a telemetry config ConfigMap. Keys are environment
+// style identifiers, *_HOST keys hold a host[:port], *_URL keys an https URL and DISABLED a
+// boolean. Invalid entries are left out and reported in the returned error.
+func ValidateTelemetryConfig(data map[string]string) (map[string]string, error) {
+	valid := map[string]string{}
+	invalid := []string{}
+	for key, value := range data {
+		var err error
+		switch upperKey := strings.ToUpper(key); {
+		case !telemetryKeyRegexp.MatchString(key):
+			err = fmt.Errorf("must consist of letters, digits and underscores")
+		case upperKey == telemetryDisabledKey:
+			_, err = strconv.ParseBool(value)
+		case strings.HasSuffix(upperKey, "_HOST"):
+			if host, parseErr := url.Parse("https://" + value); parseErr != nil || host.Host != value {
+				err = fmt.Errorf("must be a host[:port]")
+			}
+		case strings.HasSuffix(upperKey, "_URL"):
+			if endpoint, parseErr := url.Parse(value); parseErr != nil || endpoint.Scheme != "https" || len(endpoint.Host) == 0 {
+				err = fmt.Errorf("must be an https URL")
+			}
+		}
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+		valid[key] = value
+	}
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return valid, fmt.Errorf("invalid telemetry config: %s", strings.Join(invalid, ", "))
+	}
+	return valid, nil
+}
+
+// IsReadOnlyMode reports whether the operator config asks for all mutating actions
+// in the console to be disabled.
+func IsReadOnlyMode(operatorConfig *operatorv1.Console) bool {
+	readOnly, err := strconv.ParseBool(operatorConfig.Annotations[api.ReadOnlyModeAnnotation])
+	return err == nil && readOnly
+}
+
 func getConsoleAPIPath(pluginName string, service *v1.ConsolePluginProxy) string {
 	return fmt.Sprintf("%s%s/%s/", pluginProxyEndpoint, pluginName, service.Alias)
 }
@@ -17,6 +17,7 @@ import (
 	operatorv1 "github.com/openshift/api/operator/v1"
 	routev1 "github.com/openshift/api/route/v1"
 	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/util"
 )
 
 const (
@@ -55,9 +56,17 @@ func TestDefaultConfigMap(t *testing.T) {
 		managedConfig            *corev1.ConfigMap
 		monitoringSharedConfig   *corev1.ConfigMap
 		authServerCAConfig       *corev1.ConfigMap
+		sessionSecret            *corev1.Secret
 		infrastructureConfig     *configv1.Infrastructure
 		rt                       *routev1.Route
 		inactivityTimeoutSeconds int
+		groupInactivityTimeouts  map[string]int
+		contentSecurityPolicy    map[string][]string
+		telemetryConfig          map[string]string
+		clusterProxyClusters     []ClusterProxyCluster
+		multicluster             *MulticlusterConfig
+		statusProvider           *util.StatusProviderConfig
+		promotedCanaryOverrides  []byte
 		availablePlugins         []*v1.ConsolePlugin
 		nodeArchitectures        []string
 		nodeOperatingSystems     []string
@@ -907,6 +916,583 @@ servingInfo:
   certFile: /var/serving-cert/tls.crt
   keyFile: /var/serving-cert/tls.key
 providers: {}
+`,
+				},
+			},
+		},
+		{
+			name: "Test operator config, with read-only mode annotation",
+			args: args{
+				authConfig: &configv1.Authentication{},
+				operatorConfig: &operatorv1.Console{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{api.ReadOnlyModeAnnotation: "true"},
+					},
+				},
+				consoleConfig: &configv1.Console{},
+				managedConfig: &corev1.ConfigMap{},
+				infrastructureConfig: &configv1.Infrastructure{
+					Status: configv1.InfrastructureStatus{
+						APIServerURL:         mockAPIServer,
+						ControlPlaneTopology: configv1.ExternalTopologyMode,
+					},
+				},
+				rt: &routev1.Route{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: api.OpenShiftConsoleName,
+					},
+					Spec: routev1.RouteSpec{
+						Host: host,
+					},
+				},
+				inactivityTimeoutSeconds: 0,
+			},
+			want: &corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "ConfigMap",
+					APIVersion: "v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        api.OpenShiftConsoleConfigMapName,
+					Namespace:   api.OpenShiftConsoleNamespace,
+					Labels:      map[string]string{"app": api.OpenShiftConsoleName},
+					Annotations: map[string]string{},
+				},
+				Data: map[string]string{configKey: `kind: ConsoleConfig
+apiVersion: console.openshift.io/v1
+auth:
+  authType: openshift
+  clientID: console
+  clientSecretFile: /var/oauth-config/clientSecret
+  oauthEndpointCAFile: /var/oauth-serving-cert/ca-bundle.crt
+clusterInfo:
+  consoleBaseAddress: https://` + host + `
+  masterPublicURL: ` + mockAPIServer + `
+  controlPlaneTopology: External
+  releaseVersion: ` + testReleaseVersion + `
+  readOnly: true
+session: {}
+customization:
+  branding: ` + DEFAULT_BRAND + `
+  documentationBaseURL: ` + DEFAULT_DOC_URL + `
+servingInfo:
+  bindAddress: https://[::]:8443
+  certFile: /var/serving-cert/tls.crt
+  keyFile: /var/serving-cert/tls.key
+providers: {}
+`,
+				},
+			},
+		},
+		{
+			name: "Test operator config, with access log annotations",
+			args: args{
+				authConfig: &configv1.Authentication{},
+				operatorConfig: &operatorv1.Console{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{
+							api.AccessLogFormatAnnotation:      "json",
+							api.AccessLogDestinationAnnotation: "file",
+							api.AccessLogClaimAnnotation:       "console-access-logs",
+							api.AccessLogSamplingAnnotation:    "0.25",
+						},
+					},
+				},
+				consoleConfig: &configv1.Console{},
+				managedConfig: &corev1.ConfigMap{},
+				infrastructureConfig: &configv1.Infrastructure{
+					Status: configv1.InfrastructureStatus{
+						APIServerURL:         mockAPIServer,
+						ControlPlaneTopology: configv1.ExternalTopologyMode,
+					},
+				},
+				rt: &routev1.Route{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: api.OpenShiftConsoleName,
+					},
+					Spec: routev1.RouteSpec{
+						Host: host,
+					},
+				},
+				inactivityTimeoutSeconds: 0,
+			},
+			want: &corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "ConfigMap",
+					APIVersion: "v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        api.OpenShiftConsoleConfigMapName,
+					Namespace:   api.OpenShiftConsoleNamespace,
+					Labels:      map[string]string{"app": api.OpenShiftConsoleName},
+					Annotations: map[string]string{},
+				},
+				Data: map[string]string{configKey: `kind: ConsoleConfig
+apiVersion: console.openshift.io/v1
+auth:
+  authType: openshift
+  clientID: console
+  clientSecretFile: /var/oauth-config/clientSecret
+  oauthEndpointCAFile: /var/oauth-serving-cert/ca-bundle.crt
+clusterInfo:
+  consoleBaseAddress: https://` + host + `
+  masterPublicURL: ` + mockAPIServer + `
+  controlPlaneTopology: External
+  releaseVersion: ` + testReleaseVersion + `
+session: {}
+customization:
+  branding: ` + DEFAULT_BRAND + `
+  documentationBaseURL: ` + DEFAULT_DOC_URL + `
+servingInfo:
+  bindAddress: https://[::]:8443
+  certFile: /var/serving-cert/tls.crt
+  keyFile: /var/serving-cert/tls.key
+providers: {}
+accessLog:
+  format: json
+  file: /var/log/console/access.log
+  samplingRatio: 0.25
+  maxEventsPerSecond: 100
+  maxFileSizeMB: 50
+  maxFileBackups: 3
+`,
+				},
+			},
+		},
+		{
+			name: "Test operator config, with rate limit annotations",
+			args: args{
+				authConfig: &configv1.Authentication{},
+				operatorConfig: &operatorv1.Console{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{
+							api.LoginRateLimitAnnotation:    "10",
+							api.LoginLockoutAnnotation:      "5/15m",
+							api.APIProxyRateLimitAnnotation: "50",
+						},
+					},
+				},
+				consoleConfig: &configv1.Console{},
+				managedConfig: &corev1.ConfigMap{},
+				infrastructureConfig: &configv1.Infrastructure{
+					Status: configv1.InfrastructureStatus{
+						APIServerURL:         mockAPIServer,
+						ControlPlaneTopology: configv1.ExternalTopologyMode,
+					},
+				},
+				rt: &routev1.Route{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: api.OpenShiftConsoleName,
+					},
+					Spec: routev1.RouteSpec{
+						Host: host,
+					},
+				},
+				inactivityTimeoutSeconds: 0,
+			},
+			want: &corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "ConfigMap",
+					APIVersion: "v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        api.OpenShiftConsoleConfigMapName,
+					Namespace:   api.OpenShiftConsoleNamespace,
+					Labels:      map[string]string{"app": api.OpenShiftConsoleName},
+					Annotations: map[string]string{},
+				},
+				Data: map[string]string{configKey: `kind: ConsoleConfig
+apiVersion: console.openshift.io/v1
+auth:
+  authType: openshift
+  clientID: console
+  clientSecretFile: /var/oauth-config/clientSecret
+  oauthEndpointCAFile: /var/oauth-serving-cert/ca-bundle.crt
+clusterInfo:
+  consoleBaseAddress: https://` + host + `
+  masterPublicURL: ` + mockAPIServer + `
+  controlPlaneTopology: External
+  releaseVersion: ` + testReleaseVersion + `
+session: {}
+customization:
+  branding: ` + DEFAULT_BRAND + `
+  documentationBaseURL: ` + DEFAULT_DOC_URL + `
+servingInfo:
+  bindAddress: https://[::]:8443
+  certFile: /var/serving-cert/tls.crt
+  keyFile: /var/serving-cert/tls.key
+providers: {}
+rateLimit:
+  loginRequestsPerMinute: 10
+  loginLockoutThreshold: 5
+  loginLockoutSeconds: 900
+  apiProxyRequestsPerSecond: 50
+`,
+				},
+			},
+		},
+		{
+			name: "Test operator config, with server tuning annotations",
+			args: args{
+				authConfig: &configv1.Authentication{},
+				operatorConfig: &operatorv1.Console{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{
+							api.ServerReadTimeoutAnnotation:    "30s",
+							api.ServerIdleTimeoutAnnotation:    "2m",
+							api.ServerMaxHeaderBytesAnnotation: "65536",
+							api.ServerCompressionAnnotation:    "true",
+						},
+					},
+				},
+				consoleConfig: &configv1.Console{},
+				managedConfig: &corev1.ConfigMap{},
+				infrastructureConfig: &configv1.Infrastructure{
+					Status: configv1.InfrastructureStatus{
+						APIServerURL:         mockAPIServer,
+						ControlPlaneTopology: configv1.ExternalTopologyMode,
+					},
+				},
+				rt: &routev1.Route{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: api.OpenShiftConsoleName,
+					},
+					Spec: routev1.RouteSpec{
+						Host: host,
+					},
+				},
+				inactivityTimeoutSeconds: 0,
+			},
+			want: &corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "ConfigMap",
+					APIVersion: "v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        api.OpenShiftConsoleConfigMapName,
+					Namespace:   api.OpenShiftConsoleNamespace,
+					Labels:      map[string]string{"app": api.OpenShiftConsoleName},
+					Annotations: map[string]string{},
+				},
+				Data: map[string]string{configKey: `kind: ConsoleConfig
+apiVersion: console.openshift.io/v1
+auth:
+  authType: openshift
+  clientID: console
+  clientSecretFile: /var/oauth-config/clientSecret
+  oauthEndpointCAFile: /var/oauth-serving-cert/ca-bundle.crt
+clusterInfo:
+  consoleBaseAddress: https://` + host + `
+  masterPublicURL: ` + mockAPIServer + `
+  controlPlaneTopology: External
+  releaseVersion: ` + testReleaseVersion + `
+session: {}
+customization:
+  branding: ` + DEFAULT_BRAND + `
+  documentationBaseURL: ` + DEFAULT_DOC_URL + `
+servingInfo:
+  bindAddress: https://[::]:8443
+  certFile: /var/serving-cert/tls.crt
+  keyFile: /var/serving-cert/tls.key
+providers: {}
+httpServer:
+  readTimeoutSeconds: 30
+  idleTimeoutSeconds: 120
+  maxHeaderBytes: 65536
+  compression: true
+`,
+				},
+			},
+		},
+		{
+			name: "Test operator config, with API client annotations",
+			args: args{
+				authConfig: &configv1.Authentication{},
+				operatorConfig: &operatorv1.Console{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{
+							api.APIClientQPSAnnotation:     "100",
+							api.APIClientBurstAnnotation:   "200",
+							api.APIClientTimeoutAnnotation: "1m",
+						},
+					},
+				},
+				consoleConfig: &configv1.Console{},
+				managedConfig: &corev1.ConfigMap{},
+				infrastructureConfig: &configv1.Infrastructure{
+					Status: configv1.InfrastructureStatus{
+						APIServerURL:         mockAPIServer,
+						ControlPlaneTopology: configv1.ExternalTopologyMode,
+					},
+				},
+				rt: &routev1.Route{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: api.OpenShiftConsoleName,
+					},
+					Spec: routev1.RouteSpec{
+						Host: host,
+					},
+				},
+				inactivityTimeoutSeconds: 0,
+			},
+			want: &corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "ConfigMap",
+					APIVersion: "v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        api.OpenShiftConsoleConfigMapName,
+					Namespace:   api.OpenShiftConsoleNamespace,
+					Labels:      map[string]string{"app": api.OpenShiftConsoleName},
+					Annotations: map[string]string{},
+				},
+				Data: map[string]string{configKey: `kind: ConsoleConfig
+apiVersion: console.openshift.io/v1
+auth:
+  authType: openshift
+  clientID: console
+  clientSecretFile: /var/oauth-config/clientSecret
+  oauthEndpointCAFile: /var/oauth-serving-cert/ca-bundle.crt
+clusterInfo:
+  consoleBaseAddress: https://` + host + `
+  masterPublicURL: ` + mockAPIServer + `
+  controlPlaneTopology: External
+  releaseVersion: ` + testReleaseVersion + `
+session: {}
+customization:
+  branding: ` + DEFAULT_BRAND + `
+  documentationBaseURL: ` + DEFAULT_DOC_URL + `
+servingInfo:
+  bindAddress: https://[::]:8443
+  certFile: /var/serving-cert/tls.crt
+  keyFile: /var/serving-cert/tls.key
+providers: {}
+apiClient:
+  qps: 100
+  burst: 200
+  timeoutSeconds: 60
+`,
+				},
+			},
+		},
+		{
+			name: "Test operator config, with a status provider",
+			args: args{
+				authConfig: &configv1.Authentication{},
+				operatorConfig: &operatorv1.Console{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{},
+					},
+				},
+				consoleConfig: &configv1.Console{},
+				managedConfig: &corev1.ConfigMap{},
+				infrastructureConfig: &configv1.Infrastructure{
+					Status: configv1.InfrastructureStatus{
+						APIServerURL:         mockAPIServer,
+						ControlPlaneTopology: configv1.ExternalTopologyMode,
+					},
+				},
+				rt: &routev1.Route{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: api.OpenShiftConsoleName,
+					},
+					Spec: routev1.RouteSpec{
+						Host: host,
+					},
+				},
+				inactivityTimeoutSeconds: 0,
+				statusProvider: &util.StatusProviderConfig{
+					Type:         util.StatusProviderTypeStatuspage,
+					URL:          "https://status.example.com/api/v2/summary.json",
+					PollInterval: util.StatusProviderDefaultInterval,
+				},
+			},
+			want: &corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "ConfigMap",
+					APIVersion: "v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        api.OpenShiftConsoleConfigMapName,
+					Namespace:   api.OpenShiftConsoleNamespace,
+					Labels:      map[string]string{"app": api.OpenShiftConsoleName},
+					Annotations: map[string]string{},
+				},
+				Data: map[string]string{configKey: `kind: ConsoleConfig
+apiVersion: console.openshift.io/v1
+auth:
+  authType: openshift
+  clientID: console
+  clientSecretFile: /var/oauth-config/clientSecret
+  oauthEndpointCAFile: /var/oauth-serving-cert/ca-bundle.crt
+clusterInfo:
+  consoleBaseAddress: https://` + host + `
+  masterPublicURL: ` + mockAPIServer + `
+  controlPlaneTopology: External
+  releaseVersion: ` + testReleaseVersion + `
+session: {}
+customization:
+  branding: ` + DEFAULT_BRAND + `
+  documentationBaseURL: ` + DEFAULT_DOC_URL + `
+servingInfo:
+  bindAddress: https://[::]:8443
+  certFile: /var/serving-cert/tls.crt
+  keyFile: /var/serving-cert/tls.key
+providers:
+  statusFeed:
+    type: statuspage
+    url: https://status.example.com/api/v2/summary.json
+    pollIntervalSeconds: 300
+`,
+				},
+			},
+		},
+		{
+			name: "Test operator config, with group inactivity timeouts",
+			args: args{
+				authConfig: &configv1.Authentication{},
+				operatorConfig: &operatorv1.Console{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{},
+					},
+				},
+				consoleConfig: &configv1.Console{},
+				managedConfig: &corev1.ConfigMap{},
+				infrastructureConfig: &configv1.Infrastructure{
+					Status: configv1.InfrastructureStatus{
+						APIServerURL:         mockAPIServer,
+						ControlPlaneTopology: configv1.ExternalTopologyMode,
+					},
+				},
+				rt: &routev1.Route{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: api.OpenShiftConsoleName,
+					},
+					Spec: routev1.RouteSpec{
+						Host: host,
+					},
+				},
+				inactivityTimeoutSeconds: 3600,
+				groupInactivityTimeouts:  map[string]int{"contractors": 900, "sre": 28800},
+			},
+			want: &corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "ConfigMap",
+					APIVersion: "v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        api.OpenShiftConsoleConfigMapName,
+					Namespace:   api.OpenShiftConsoleNamespace,
+					Labels:      map[string]string{"app": api.OpenShiftConsoleName},
+					Annotations: map[string]string{},
+				},
+				Data: map[string]string{configKey: `kind: ConsoleConfig
+apiVersion: console.openshift.io/v1
+auth:
+  authType: openshift
+  clientID: console
+  clientSecretFile: /var/oauth-config/clientSecret
+  oauthEndpointCAFile: /var/oauth-serving-cert/ca-bundle.crt
+  inactivityTimeoutSeconds: 3600
+  groupInactivityTimeoutSeconds:
+    contractors: 900
+    sre: 28800
+clusterInfo:
+  consoleBaseAddress: https://` + host + `
+  masterPublicURL: ` + mockAPIServer + `
+  controlPlaneTopology: External
+  releaseVersion: ` + testReleaseVersion + `
+session: {}
+customization:
+  branding: ` + DEFAULT_BRAND + `
+  documentationBaseURL: ` + DEFAULT_DOC_URL + `
+servingInfo:
+  bindAddress: https://[::]:8443
+  certFile: /var/serving-cert/tls.crt
+  keyFile: /var/serving-cert/tls.key
+providers: {}
+`,
+				},
+			},
+		},
+		{
+			name: "Test operator config, with content security policy merged with plugin policies",
+			args: args{
+				authConfig:     &configv1.Authentication{},
+				operatorConfig: &operatorv1.Console{},
+				consoleConfig:  &configv1.Console{},
+				managedConfig:  &corev1.ConfigMap{},
+				infrastructureConfig: &configv1.Infrastructure{
+					Status: configv1.InfrastructureStatus{
+						APIServerURL:         mockAPIServer,
+						ControlPlaneTopology: configv1.ExternalTopologyMode,
+					},
+				},
+				rt: &routev1.Route{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: api.OpenShiftConsoleName,
+					},
+					Spec: routev1.RouteSpec{
+						Host: host,
+					},
+				},
+				contentSecurityPolicy: map[string][]string{
+					"script-src": {"https://analytics.example.com"},
+					"frame-src":  {"https://analytics.example.com"},
+				},
+				availablePlugins: []*v1.ConsolePlugin{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:        "plugin1",
+							Annotations: map[string]string{api.PluginCSPAnnotation: "script-src https://cdn.example.com https://analytics.example.com"},
+						},
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:        "plugin2",
+							Annotations: map[string]string{api.PluginCSPAnnotation: "script-src 'unsafe-eval'"},
+						},
+					},
+				},
+			},
+			want: &corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "ConfigMap",
+					APIVersion: "v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        api.OpenShiftConsoleConfigMapName,
+					Namespace:   api.OpenShiftConsoleNamespace,
+					Labels:      map[string]string{"app": api.OpenShiftConsoleName},
+					Annotations: map[string]string{},
+				},
+				Data: map[string]string{configKey: `kind: ConsoleConfig
+apiVersion: console.openshift.io/v1
+auth:
+  authType: openshift
+  clientID: console
+  clientSecretFile: /var/oauth-config/clientSecret
+  oauthEndpointCAFile: /var/oauth-serving-cert/ca-bundle.crt
+clusterInfo:
+  consoleBaseAddress: https://` + host + `
+  masterPublicURL: ` + mockAPIServer + `
+  controlPlaneTopology: External
+  releaseVersion: ` + testReleaseVersion + `
+session: {}
+customization:
+  branding: ` + DEFAULT_BRAND + `
+  documentationBaseURL: ` + DEFAULT_DOC_URL + `
+servingInfo:
+  bindAddress: https://[::]:8443
+  certFile: /var/serving-cert/tls.crt
+  keyFile: /var/serving-cert/tls.key
+providers: {}
+contentSecurityPolicy:
+  frame-src:
+  - https://analytics.example.com
+  script-src:
+  - https://analytics.example.com
+  - https://cdn.example.com
 `,
 				},
 			},
@@ -987,11 +1573,19 @@ providers: {}
 				tt.args.consoleConfig,
 				tt.args.authConfig,
 				tt.args.authServerCAConfig,
+				tt.args.sessionSecret,
 				tt.args.managedConfig,
 				tt.args.monitoringSharedConfig,
 				tt.args.infrastructureConfig,
 				tt.args.rt,
-				tt.args.inactivityTimeoutSeconds,
+				util.SessionPolicy{InactivityTimeoutSeconds: tt.args.inactivityTimeoutSeconds},
+				tt.args.groupInactivityTimeouts,
+				tt.args.contentSecurityPolicy,
+				tt.args.telemetryConfig,
+				tt.args.clusterProxyClusters,
+				tt.args.multicluster,
+				tt.args.statusProvider,
+				tt.args.promotedCanaryOverrides,
 				tt.args.availablePlugins,
 				tt.args.nodeArchitectures,
 				tt.args.nodeOperatingSystems,
@@ -1030,6 +1624,16 @@ providers: {}
 			cm.Data = nil
 			tt.want.Data = nil
 
+			// the session policy is derived from the args, it is compared on its own
+			wantSessionPolicy := util.SessionPolicy{InactivityTimeoutSeconds: tt.args.inactivityTimeoutSeconds}.String()
+			if diff := deep.Equal(cm.Annotations[api.SessionPolicyAnnotation], wantSessionPolicy); diff != nil {
+				t.Error(diff)
+			}
+			delete(cm.Annotations, api.SessionPolicyAnnotation)
+			if len(cm.Annotations) == 0 {
+				cm.Annotations = tt.want.Annotations
+			}
+
 			// and then we can test the rest of the struct
 			if diff := deep.Equal(cm, tt.want); diff != nil {
 				t.Error(diff)
@@ -1088,6 +1692,7 @@ func TestTelemetryConfiguration(t *testing.T) {
 	tests := []struct {
 		name                  string
 		operatorConfig        *operatorv1.Console
+		telemetryConfig       map[string]string
 		expectedConfiguration map[string]string
 	}{
 		{
@@ -1128,10 +1733,100 @@ func TestTelemetryConfiguration(t *testing.T) {
 				"disabled":     "true",
 			},
 		},
+		{
+			name: "Should merge the telemetry config with the annotations taking precedence",
+			operatorConfig: &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						"telemetry.console.openshift.io/SEGMENT_API_KEY": "ANNOTATION_KEY",
+					},
+				},
+			},
+			telemetryConfig: map[string]string{
+				"SEGMENT_API_KEY":  "CONFIG_KEY",
+				"SEGMENT_API_HOST": "api.segment.example.com",
+			},
+			expectedConfiguration: map[string]string{
+				"SEGMENT_API_KEY":  "ANNOTATION_KEY",
+				"SEGMENT_API_HOST": "api.segment.example.com",
+			},
+		},
+		{
+			name: "Should let the annotations opt out of the telemetry config",
+			operatorConfig: &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						"telemetry.console.openshift.io/DISABLED": "true",
+					},
+				},
+			},
+			telemetryConfig: map[string]string{
+				"SEGMENT_API_KEY": "CONFIG_KEY",
+				"DISABLED":        "false",
+			},
+			expectedConfiguration: map[string]string{
+				"SEGMENT_API_KEY": "CONFIG_KEY",
+				"DISABLED":        "true",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(GetTelemetryConfiguration(tt.operatorConfig, tt.telemetryConfig), tt.expectedConfiguration); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestValidateTelemetryConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name: "Test valid telemetry config",
+			data: map[string]string{
+				"SEGMENT_API_KEY":  "KEY",
+				"SEGMENT_API_HOST": "api.segment.example.com:443",
+				"SEGMENT_JS_URL":   "https://cdn.segment.example.com/analytics.js",
+				"DISABLED":         "false",
+			},
+			want: map[string]string{
+				"SEGMENT_API_KEY":  "KEY",
+				"SEGMENT_API_HOST": "api.segment.example.com:443",
+				"SEGMENT_JS_URL":   "https://cdn.segment.example.com/analytics.js",
+				"DISABLED":         "false",
+			},
+		},
+		{
+			name: "Test invalid entries are left out",
+			data: map[string]string{
+				"SEGMENT_API_KEY":  "KEY",
+				"SEGMENT_API_HOST": "https://api.segment.example.com",
+				"SEGMENT_JS_URL":   "http://cdn.segment.example.com/analytics.js",
+				"DISABLED":         "maybe",
+				"segment.key":      "KEY",
+			},
+			want: map[string]string{
+				"SEGMENT_API_KEY": "KEY",
+			},
+			wantErr: "invalid telemetry config: DISABLED: strconv.ParseBool: parsing \"maybe\": invalid syntax, SEGMENT_API_HOST: must be a host[:port], SEGMENT_JS_URL: must be an https URL, segment.key: must consist of letters, digits and underscores",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if diff := deep.Equal(GetTelemetryConfiguration(tt.operatorConfig), tt.expectedConfiguration); diff != nil {
+			got, err := ValidateTelemetryConfig(tt.data)
+			gotErr := ""
+			if err != nil {
+				gotErr = err.Error()
+			}
+			if diff := deep.Equal(gotErr, tt.wantErr); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
 				t.Error(diff)
 			}
 		})
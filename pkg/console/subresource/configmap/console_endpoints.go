@@ -0,0 +1,53 @@
+package configmap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	consolev1 "github.com/openshift/api/console/v1"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+
+	"github.com/openshift/console-operator/bindata"
+)
+
+// ConsoleEndpoints are the endpoints of the console published in the console-endpoints
+// ConfigMap, so that other operators do not derive them from the routes.
+type ConsoleEndpoints struct {
+	ConsoleURL   string
+	DownloadsURL string
+	// CustomHostnames are the custom hostnames of the console and downloads routes
+	CustomHostnames []string
+	// PluginCatalogVersion changes whenever a plugin is enabled, disabled or updated
+	PluginCatalogVersion string
+}
+
+func DefaultConsoleEndpointsConfigMap(endpoints ConsoleEndpoints) *corev1.ConfigMap {
+	configMap := ConsoleEndpointsConfigMapStub()
+	configMap.Data = map[string]string{
+		"consoleURL":           endpoints.ConsoleURL,
+		"downloadsURL":         endpoints.DownloadsURL,
+		"customHostnames":      strings.Join(endpoints.CustomHostnames, ","),
+		"pluginCatalogVersion": endpoints.PluginCatalogVersion,
+	}
+	return configMap
+}
+
+func ConsoleEndpointsConfigMapStub() *corev1.ConfigMap {
+	return resourceread.ReadConfigMapV1OrDie(bindata.MustAsset("assets/configmaps/console-endpoints-configmap.yaml"))
+}
+
+// PluginCatalogVersion hashes the names and generations of the enabled plugins, in any order.
+func PluginCatalogVersion(plugins []*consolev1.ConsolePlugin) string {
+	entries := make([]string, 0, len(plugins))
+	for _, plugin := range plugins {
+		entries = append(entries, fmt.Sprintf("%s/%d", plugin.Name, plugin.Generation))
+	}
+	sort.Strings(entries)
+	sum := sha256.Sum256([]byte(strings.Join(entries, ",")))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,46 @@
+package configmap
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	consolev1 "github.com/openshift/api/console/v1"
+)
+
+func TestPluginCatalogVersion(t *testing.T) {
+	plugin := func(name string, generation int64) *consolev1.ConsolePlugin {
+		return &consolev1.ConsolePlugin{ObjectMeta: metav1.ObjectMeta{Name: name, Generation: generation}}
+	}
+	catalog := PluginCatalogVersion([]*consolev1.ConsolePlugin{plugin("monitoring", 1), plugin("pipelines", 2)})
+
+	tests := []struct {
+		name    string
+		plugins []*consolev1.ConsolePlugin
+		same    bool
+	}{
+		{
+			name:    "Test plugins in another order",
+			plugins: []*consolev1.ConsolePlugin{plugin("pipelines", 2), plugin("monitoring", 1)},
+			same:    true,
+		},
+		{
+			name:    "Test updated plugin",
+			plugins: []*consolev1.ConsolePlugin{plugin("monitoring", 1), plugin("pipelines", 3)},
+			same:    false,
+		},
+		{
+			name:    "Test disabled plugin",
+			plugins: []*consolev1.ConsolePlugin{plugin("monitoring", 1)},
+			same:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(PluginCatalogVersion(tt.plugins) == catalog, tt.same); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package configmap
+
+import (
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+
+	"github.com/openshift/console-operator/bindata"
+)
+
+const consoleSamplesStatusKey = "samples.yaml"
+
+// ConsoleSampleStatus is the outcome of the checks of a ConsoleSample, listed in the
+// samples.yaml key of the console-samples-status ConfigMap.
+type ConsoleSampleStatus struct {
+	Name string `json:"name"`
+	// Valid is false when the spec of the sample cannot be imported by the console
+	Valid bool `json:"valid"`
+	// Reachable is false when the git repository of the sample does not answer, it is always
+	// true for container samples
+	Reachable bool   `json:"reachable"`
+	Message   string `json:"message,omitempty"`
+}
+
+func DefaultConsoleSamplesStatusConfigMap(statuses []ConsoleSampleStatus) (*corev1.ConfigMap, error) {
+	statusesYAML, err := yaml.Marshal(statuses)
+	if err != nil {
+		return nil, err
+	}
+	configMap := ConsoleSamplesStatusConfigMapStub()
+	configMap.Data = map[string]string{
+		consoleSamplesStatusKey: string(statusesYAML),
+	}
+	return configMap, nil
+}
+
+func ConsoleSamplesStatusConfigMapStub() *corev1.ConfigMap {
+	return resourceread.ReadConfigMapV1OrDie(bindata.MustAsset("assets/configmaps/console-samples-status-configmap.yaml"))
+}
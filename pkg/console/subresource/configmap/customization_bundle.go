@@ -0,0 +1,187 @@
+package configmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+const (
+	CustomizationBundleConfigMapKind = "configmap"
+	CustomizationBundleSecretKind    = "secret"
+
+	customizationBundleFile = "customization.yaml"
+)
+
+var (
+	// a bundle holds customization.yaml and at most one logo, anything else is a mistake
+	customizationBundleLogoKeys = sets.NewString("logo.gif", "logo.jpeg", "logo.jpg", "logo.png", "logo.svg")
+
+	validBrands = sets.NewString(
+		string(operatorv1.BrandOpenShiftLegacy), string(operatorv1.BrandOKDLegacy), string(operatorv1.BrandOnlineLegacy),
+		string(operatorv1.BrandOCPLegacy), string(operatorv1.BrandDedicatedLegacy), string(operatorv1.BrandAzureLegacy),
+		string(operatorv1.BrandOpenShift), string(operatorv1.BrandOKD), string(operatorv1.BrandOnline),
+		string(operatorv1.BrandOCP), string(operatorv1.BrandDedicated), string(operatorv1.BrandAzure),
+		string(operatorv1.BrandROSA),
+	)
+)
+
+// CustomizationBundle is the unpacked content of the ConfigMap or Secret referenced by the
+// customization-bundle annotation.
+type CustomizationBundle struct {
+	// Name of the bundle in openshift-config
+	Name string
+	// Customization read from customization.yaml, the same fields as spec.customization
+	Customization operatorv1.ConsoleCustomization
+	// LogoKey is the key of the custom logo in a ConfigMap bundle, empty if there is none
+	LogoKey string
+}
+
+// CustomizationBundleReference parses the customization-bundle annotation, either configmap/<name>
+// or secret/<name> in openshift-config. A bare name refers to a ConfigMap. The name is empty if
+// there is no bundle.
+func CustomizationBundleReference(operatorConfig *operatorv1.Console) (kind, name string, err error) {
+	value, ok := operatorConfig.Annotations[api.CustomizationBundleAnnotation]
+	if !ok || len(value) == 0 {
+		return "", "", nil
+	}
+	kind, name, found := strings.Cut(value, "/")
+	if !found {
+		kind, name = CustomizationBundleConfigMapKind, value
+	}
+	kind = strings.ToLower(kind)
+	if kind != CustomizationBundleConfigMapKind && kind != CustomizationBundleSecretKind {
+		return "", "", fmt.Errorf("%s must reference a configmap or a secret, got %q", api.CustomizationBundleAnnotation, value)
+	}
+	if len(name) == 0 {
+		return "", "", fmt.Errorf("%s is missing a name, got %q", api.CustomizationBundleAnnotation, value)
+	}
+	return kind, name, nil
+}
+
+// ParseCustomizationBundle validates and unpacks the data of a bundle. The logo is synced to the
+// console namespace like a custom logo ConfigMap, so only ConfigMap bundles can hold one.
+func ParseCustomizationBundle(kind, name string, data map[string][]byte) (*CustomizationBundle, error) {
+	bundle := &CustomizationBundle{Name: name}
+	errs := []error{}
+
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch {
+		case key == customizationBundleFile:
+		case customizationBundleLogoKeys.Has(key):
+			if kind == CustomizationBundleSecretKind {
+				errs = append(errs, fmt.Errorf("%s: logos are only supported in configmap bundles", key))
+				continue
+			}
+			if len(bundle.LogoKey) > 0 {
+				errs = append(errs, fmt.Errorf("%s: the bundle already holds the logo %s", key, bundle.LogoKey))
+				continue
+			}
+			bundle.LogoKey = key
+		default:
+			errs = append(errs, fmt.Errorf("%s: unsupported key, expected %s or one of %s", key, customizationBundleFile, strings.Join(customizationBundleLogoKeys.List(), ", ")))
+		}
+	}
+
+	if customizationYAML, ok := data[customizationBundleFile]; ok {
+		customization, err := parseCustomization(customizationYAML)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", customizationBundleFile, err))
+		} else {
+			bundle.Customization = *customization
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid customization bundle %s/%s: %w", kind, name, utilerrors.NewAggregate(errs))
+	}
+	return bundle, nil
+}
+
+func parseCustomization(customizationYAML []byte) (*operatorv1.ConsoleCustomization, error) {
+	customizationJSON, err := yaml.YAMLToJSON(customizationYAML)
+	if err != nil {
+		return nil, err
+	}
+	customization := &operatorv1.ConsoleCustomization{}
+	decoder := json.NewDecoder(bytes.NewReader(customizationJSON))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(customization); err != nil {
+		return nil, err
+	}
+
+	errs := []error{}
+	if len(customization.CustomLogoFile.Name) > 0 || len(customization.CustomLogoFile.Key) > 0 {
+		errs = append(errs, fmt.Errorf("customLogoFile is not supported, add the logo to the bundle instead"))
+	}
+	if brand := string(customization.Brand); len(brand) > 0 && !validBrands.Has(brand) {
+		errs = append(errs, fmt.Errorf("brand %q is not one of %s", brand, strings.Join(validBrands.List(), ", ")))
+	}
+	if docURL := customization.DocumentationBaseURL; len(docURL) > 0 {
+		parsed, err := url.Parse(docURL)
+		if err != nil || parsed.Scheme != "https" || len(parsed.Host) == 0 || !strings.HasSuffix(docURL, "/") {
+			errs = append(errs, fmt.Errorf("documentationBaseURL %q must be an https URL ending with a slash", docURL))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+	return customization, nil
+}
+
+// WithCustomizationBundle returns a copy of operatorConfig whose customization is completed with
+// the bundle. Fields set in spec.customization keep precedence, the bundle only fills in the
+// unset ones, so the individual fields keep working alongside a bundle.
+func WithCustomizationBundle(operatorConfig *operatorv1.Console, bundle *CustomizationBundle) *operatorv1.Console {
+	if bundle == nil {
+		return operatorConfig
+	}
+	rendered := operatorConfig.DeepCopy()
+	customization := &rendered.Spec.Customization
+	fromBundle := bundle.Customization.DeepCopy()
+
+	if len(customization.Brand) == 0 {
+		customization.Brand = fromBundle.Brand
+	}
+	if len(customization.DocumentationBaseURL) == 0 {
+		customization.DocumentationBaseURL = fromBundle.DocumentationBaseURL
+	}
+	if len(customization.CustomProductName) == 0 {
+		customization.CustomProductName = fromBundle.CustomProductName
+	}
+	if len(customization.CustomLogoFile.Name) == 0 && len(customization.CustomLogoFile.Key) == 0 && len(bundle.LogoKey) > 0 {
+		customization.CustomLogoFile = configv1.ConfigMapFileReference{Name: bundle.Name, Key: bundle.LogoKey}
+	}
+	if reflect.DeepEqual(customization.DeveloperCatalog, operatorv1.DeveloperConsoleCatalogCustomization{}) {
+		customization.DeveloperCatalog = fromBundle.DeveloperCatalog
+	}
+	if reflect.DeepEqual(customization.ProjectAccess, operatorv1.ProjectAccess{}) {
+		customization.ProjectAccess = fromBundle.ProjectAccess
+	}
+	if reflect.DeepEqual(customization.QuickStarts, operatorv1.QuickStarts{}) {
+		customization.QuickStarts = fromBundle.QuickStarts
+	}
+	if reflect.DeepEqual(customization.AddPage, operatorv1.AddPage{}) {
+		customization.AddPage = fromBundle.AddPage
+	}
+	if len(customization.Perspectives) == 0 {
+		customization.Perspectives = fromBundle.Perspectives
+	}
+	return rendered
+}
@@ -0,0 +1,200 @@
+package configmap
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestCustomizationBundleReference(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		wantKind string
+		wantName string
+		wantErr  bool
+	}{
+		{
+			name:  "Test no bundle",
+			value: "",
+		},
+		{
+			name:     "Test bare name is a configmap",
+			value:    "branding",
+			wantKind: CustomizationBundleConfigMapKind,
+			wantName: "branding",
+		},
+		{
+			name:     "Test secret bundle",
+			value:    "Secret/branding",
+			wantKind: CustomizationBundleSecretKind,
+			wantName: "branding",
+		},
+		{
+			name:    "Test unsupported kind",
+			value:   "deployment/branding",
+			wantErr: true,
+		},
+		{
+			name:    "Test missing name",
+			value:   "configmap/",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{}}}
+			if len(tt.value) > 0 {
+				operatorConfig.Annotations[api.CustomizationBundleAnnotation] = tt.value
+			}
+			kind, name, err := CustomizationBundleReference(operatorConfig)
+			if diff := deep.Equal(err != nil, tt.wantErr); diff != nil {
+				t.Errorf("%v: %v", diff, err)
+			}
+			if diff := deep.Equal([]string{kind, name}, []string{tt.wantKind, tt.wantName}); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestParseCustomizationBundle(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		data    map[string][]byte
+		want    *CustomizationBundle
+		wantErr string
+	}{
+		{
+			name: "Test configmap bundle with a logo",
+			kind: CustomizationBundleConfigMapKind,
+			data: map[string][]byte{
+				"customization.yaml": []byte("brand: OKD\ncustomProductName: Acme Console\ndocumentationBaseURL: https://docs.example.com/\n"),
+				"logo.svg":           []byte("<svg/>"),
+			},
+			want: &CustomizationBundle{
+				Name: "branding",
+				Customization: operatorv1.ConsoleCustomization{
+					Brand:                operatorv1.BrandOKD,
+					CustomProductName:    "Acme Console",
+					DocumentationBaseURL: "https://docs.example.com/",
+				},
+				LogoKey: "logo.svg",
+			},
+		},
+		{
+			name: "Test secret bundle without a logo",
+			kind: CustomizationBundleSecretKind,
+			data: map[string][]byte{
+				"customization.yaml": []byte("customProductName: Acme Console\n"),
+			},
+			want: &CustomizationBundle{
+				Name:          "branding",
+				Customization: operatorv1.ConsoleCustomization{CustomProductName: "Acme Console"},
+			},
+		},
+		{
+			name: "Test secret bundle with a logo",
+			kind: CustomizationBundleSecretKind,
+			data: map[string][]byte{
+				"logo.png": []byte("png"),
+			},
+			wantErr: "invalid customization bundle secret/branding: logo.png: logos are only supported in configmap bundles",
+		},
+		{
+			name: "Test unsupported keys and fields",
+			kind: CustomizationBundleConfigMapKind,
+			data: map[string][]byte{
+				"customization.yaml": []byte("brand: OKD\ntheme: dark\n"),
+				"styles.css":         []byte("body {}"),
+			},
+			wantErr: `invalid customization bundle configmap/branding: [styles.css: unsupported key, expected customization.yaml or one of logo.gif, logo.jpeg, logo.jpg, logo.png, logo.svg, customization.yaml: json: unknown field "theme"]`,
+		},
+		{
+			name: "Test invalid values",
+			kind: CustomizationBundleConfigMapKind,
+			data: map[string][]byte{
+				"customization.yaml": []byte("brand: Acme\ndocumentationBaseURL: http://docs.example.com\ncustomLogoFile:\n  name: logo\n  key: logo.svg\n"),
+				"logo.png":           []byte("png"),
+				"logo.svg":           []byte("<svg/>"),
+			},
+			wantErr: `invalid customization bundle configmap/branding: [logo.svg: the bundle already holds the logo logo.png, customization.yaml: [customLogoFile is not supported, add the logo to the bundle instead, brand "Acme" is not one of Azure, Dedicated, OCP, OKD, Online, OpenShift, ROSA, azure, dedicated, ocp, okd, online, openshift, documentationBaseURL "http://docs.example.com" must be an https URL ending with a slash]]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCustomizationBundle(tt.kind, "branding", tt.data)
+			gotErr := ""
+			if err != nil {
+				gotErr = err.Error()
+			}
+			if diff := deep.Equal(gotErr, tt.wantErr); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestWithCustomizationBundle(t *testing.T) {
+	bundle := &CustomizationBundle{
+		Name: "branding",
+		Customization: operatorv1.ConsoleCustomization{
+			Brand:             operatorv1.BrandOKD,
+			CustomProductName: "Acme Console",
+			ProjectAccess:     operatorv1.ProjectAccess{AvailableClusterRoles: []string{"view"}},
+		},
+		LogoKey: "logo.svg",
+	}
+	tests := []struct {
+		name          string
+		customization operatorv1.ConsoleCustomization
+		want          operatorv1.ConsoleCustomization
+	}{
+		{
+			name:          "Test bundle fills in an empty customization",
+			customization: operatorv1.ConsoleCustomization{},
+			want: operatorv1.ConsoleCustomization{
+				Brand:             operatorv1.BrandOKD,
+				CustomProductName: "Acme Console",
+				CustomLogoFile:    configv1.ConfigMapFileReference{Name: "branding", Key: "logo.svg"},
+				ProjectAccess:     operatorv1.ProjectAccess{AvailableClusterRoles: []string{"view"}},
+			},
+		},
+		{
+			name: "Test individual fields take precedence",
+			customization: operatorv1.ConsoleCustomization{
+				CustomProductName: "Other Console",
+				CustomLogoFile:    configv1.ConfigMapFileReference{Name: "logo", Key: "logo.png"},
+			},
+			want: operatorv1.ConsoleCustomization{
+				Brand:             operatorv1.BrandOKD,
+				CustomProductName: "Other Console",
+				CustomLogoFile:    configv1.ConfigMapFileReference{Name: "logo", Key: "logo.png"},
+				ProjectAccess:     operatorv1.ProjectAccess{AvailableClusterRoles: []string{"view"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{
+				Spec: operatorv1.ConsoleSpec{Customization: tt.customization},
+			}
+			got := WithCustomizationBundle(operatorConfig, bundle)
+			if diff := deep.Equal(got.Spec.Customization, tt.want); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(operatorConfig.Spec.Customization, tt.customization); diff != nil {
+				t.Errorf("operator config was modified: %v", diff)
+			}
+		})
+	}
+}
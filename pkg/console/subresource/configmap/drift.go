@@ -0,0 +1,74 @@
+package configmap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+// SetDataHash records the hash of the data the operator writes, so that out of band
+// changes can be told apart from the operator's own on the next sync.
+func SetDataHash(required *corev1.ConfigMap) {
+	if required.Annotations == nil {
+		required.Annotations = map[string]string{}
+	}
+	required.Annotations[api.ConfigMapDataHashAnnotation] = dataHash(required.Data)
+}
+
+// DataDrift returns the keys of existing that were changed by someone other than the
+// operator and are about to be reverted to required, with a diff of the reverted data with
+// sensitive values redacted. A ConfigMap written before the data hash was recorded never drifts.
+func DataDrift(required, existing *corev1.ConfigMap) (keys []string, diff string) {
+	hash, ok := existing.Annotations[api.ConfigMapDataHashAnnotation]
+	if !ok || hash == dataHash(existing.Data) {
+		return nil, ""
+	}
+	for key, value := range existing.Data {
+		if requiredValue, ok := required.Data[key]; !ok || requiredValue != value {
+			keys = append(keys, key)
+		}
+	}
+	for key := range required.Data {
+		if _, ok := existing.Data[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, ""
+	}
+	sort.Strings(keys)
+	return keys, cmp.Diff(redactedData(existing.Data), redactedData(required.Data))
+}
+
+// redactedData parses each value of data like a console-config.yaml, a value that does not parse is
+// compared as a whole but never shown.
+func redactedData(data map[string]string) map[string]interface{} {
+	redacted := map[string]interface{}{}
+	for key, value := range data {
+		if sensitiveFieldRegexp.MatchString(key) && !fileFieldRegexp.MatchString(key) {
+			redacted[key] = redactedValue
+			continue
+		}
+		var parsed interface{}
+		if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+			redacted[key] = redactedValue
+			continue
+		}
+		redacted[key] = redact(parsed)
+	}
+	return redacted
+}
+
+func dataHash(data map[string]string) string {
+	// json.Marshal sorts map keys, the hash is stable
+	marshalled, _ := json.Marshal(data)
+	sum := sha256.Sum256(marshalled)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,90 @@
+package configmap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDataDrift(t *testing.T) {
+	applied := func(data map[string]string) *corev1.ConfigMap {
+		configMap := &corev1.ConfigMap{Data: data}
+		SetDataHash(configMap)
+		return configMap
+	}
+	edited := func(configMap *corev1.ConfigMap, data map[string]string) *corev1.ConfigMap {
+		configMap = configMap.DeepCopy()
+		configMap.Data = data
+		return configMap
+	}
+
+	tests := []struct {
+		name     string
+		required *corev1.ConfigMap
+		existing *corev1.ConfigMap
+		wantKeys []string
+		wantDiff bool
+		// hidden never shows up in the diff
+		hidden string
+	}{
+		{
+			name:     "Test operator change is not drift",
+			required: &corev1.ConfigMap{Data: map[string]string{"consoleURL": "https://new.example.com"}},
+			existing: applied(map[string]string{"consoleURL": "https://old.example.com"}),
+		},
+		{
+			name:     "Test config map written without a data hash is not drift",
+			required: &corev1.ConfigMap{Data: map[string]string{"consoleURL": "https://console.example.com"}},
+			existing: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{}},
+				Data:       map[string]string{"consoleURL": "https://edited.example.com"},
+			},
+		},
+		{
+			name:     "Test out of band change is drift",
+			required: &corev1.ConfigMap{Data: map[string]string{"consoleURL": "https://console.example.com", "kept": "value"}},
+			existing: edited(
+				applied(map[string]string{"consoleURL": "https://console.example.com", "kept": "value"}),
+				map[string]string{"consoleURL": "https://edited.example.com", "kept": "value", "added": "value"},
+			),
+			wantKeys: []string{"added", "consoleURL"},
+			wantDiff: true,
+		},
+		{
+			name:     "Test drift diff redacts sensitive values",
+			required: &corev1.ConfigMap{Data: map[string]string{"console-config.yaml": "telemetry:\n  SEGMENT_API_KEY: required-key\n"}},
+			existing: edited(
+				applied(map[string]string{"console-config.yaml": "telemetry:\n  SEGMENT_API_KEY: required-key\n"}),
+				map[string]string{"console-config.yaml": "telemetry:\n  SEGMENT_API_KEY: edited-key\n  extra: value\n"},
+			),
+			wantKeys: []string{"console-config.yaml"},
+			wantDiff: true,
+			hidden:   "edited-key",
+		},
+		{
+			name:     "Test out of band change matching the required data is not drift",
+			required: &corev1.ConfigMap{Data: map[string]string{"consoleURL": "https://new.example.com"}},
+			existing: edited(
+				applied(map[string]string{"consoleURL": "https://old.example.com"}),
+				map[string]string{"consoleURL": "https://new.example.com"},
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, diff := DataDrift(tt.required, tt.existing)
+			if d := deep.Equal(keys, tt.wantKeys); d != nil {
+				t.Error(d)
+			}
+			if gotDiff := len(diff) > 0; gotDiff != tt.wantDiff {
+				t.Errorf("got diff %q, want diff %v", diff, tt.wantDiff)
+			}
+			if len(tt.hidden) > 0 && strings.Contains(diff, tt.hidden) {
+				t.Errorf("got diff %q, want %q redacted", diff, tt.hidden)
+			}
+		})
+	}
+}
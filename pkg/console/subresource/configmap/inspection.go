@@ -0,0 +1,178 @@
+package configmap
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+
+	"github.com/openshift/console-operator/bindata"
+)
+
+const (
+	inspectionStateKey = "state.yaml"
+	// maxInspectionSyncErrors bounds the sync error history kept in the inspection ConfigMap
+	maxInspectionSyncErrors = 20
+)
+
+// InspectionState is the state of the operator dumped for must-gather, with sensitive values
+// redacted.
+type InspectionState struct {
+	ManagementState    operatorv1.ManagementState `json:"managementState"`
+	Generation         int64                      `json:"generation"`
+	ObservedGeneration int64                      `json:"observedGeneration"`
+	ReadyReplicas      int32                      `json:"readyReplicas"`
+	Annotations        map[string]string          `json:"annotations,omitempty"`
+	// UnsupportedConfigOverrides are the overrides of the operator config, redacted
+	UnsupportedConfigOverrides interface{}                    `json:"unsupportedConfigOverrides,omitempty"`
+	Plugins                    []string                       `json:"plugins,omitempty"`
+	ConsoleConfig              *InspectionConsoleConfig       `json:"consoleConfig,omitempty"`
+	RouteSecrets               []InspectionSecret             `json:"routeSecrets,omitempty"`
+	Conditions                 []operatorv1.OperatorCondition `json:"conditions,omitempty"`
+	Generations                []operatorv1.GenerationStatus  `json:"generations,omitempty"`
+	// SyncErrors are the last degraded conditions reported by the operator, oldest first
+	SyncErrors []InspectionSyncError `json:"syncErrors,omitempty"`
+}
+
+// InspectionConsoleConfig identifies the console-config the console runs with.
+type InspectionConsoleConfig struct {
+	ResourceVersion string `json:"resourceVersion"`
+	ChangeChain     string `json:"changeChain,omitempty"`
+}
+
+// InspectionSecret is the metadata of a Secret serving a custom TLS certificate of a route,
+// never its data.
+type InspectionSecret struct {
+	Route           string   `json:"route"`
+	Namespace       string   `json:"namespace"`
+	Name            string   `json:"name"`
+	Found           bool     `json:"found"`
+	Type            string   `json:"type,omitempty"`
+	Keys            []string `json:"keys,omitempty"`
+	ResourceVersion string   `json:"resourceVersion,omitempty"`
+}
+
+type InspectionSyncError struct {
+	Time      metav1.Time `json:"time"`
+	Condition string      `json:"condition"`
+	Reason    string      `json:"reason,omitempty"`
+	Message   string      `json:"message,omitempty"`
+}
+
+// NewInspectionSecret describes secret, nil when it does not exist.
+func NewInspectionSecret(route, namespace, name string, secret *corev1.Secret) InspectionSecret {
+	inspectionSecret := InspectionSecret{
+		Route:     route,
+		Namespace: namespace,
+		Name:      name,
+	}
+	if secret == nil {
+		return inspectionSecret
+	}
+	inspectionSecret.Found = true
+	inspectionSecret.Type = string(secret.Type)
+	inspectionSecret.ResourceVersion = secret.ResourceVersion
+	for key := range secret.Data {
+		inspectionSecret.Keys = append(inspectionSecret.Keys, key)
+	}
+	sort.Strings(inspectionSecret.Keys)
+	return inspectionSecret
+}
+
+// RedactAnnotations returns annotations with the values of sensitive ones redacted.
+func RedactAnnotations(annotations map[string]string) map[string]string {
+	if len(annotations) == 0 {
+		return nil
+	}
+	redacted := map[string]string{}
+	for key, value := range annotations {
+		if sensitiveFieldRegexp.MatchString(key) && !fileFieldRegexp.MatchString(key) {
+			value = redactedValue
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
+
+// RedactUnsupportedConfigOverrides parses the overrides and redacts their sensitive fields, the
+// overrides are not shown at all when they do not parse.
+func RedactUnsupportedConfigOverrides(raw []byte) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	overrides := map[string]interface{}{}
+	if err := yaml.Unmarshal(raw, &overrides); err != nil {
+		return redactedValue
+	}
+	return redact(overrides)
+}
+
+// GetInspectionSyncErrors returns the sync error history of the inspection ConfigMap, a history
+// that does not parse is started over.
+func GetInspectionSyncErrors(inspectionConfigMap *corev1.ConfigMap) []InspectionSyncError {
+	if inspectionConfigMap == nil {
+		return nil
+	}
+	state := InspectionState{}
+	if err := yaml.Unmarshal([]byte(inspectionConfigMap.Data[inspectionStateKey]), &state); err != nil {
+		return nil
+	}
+	return state.SyncErrors
+}
+
+// WithSyncErrors appends the degraded conditions to history unless the last entry of the same
+// condition already records them, and drops the oldest entries past maxInspectionSyncErrors.
+func WithSyncErrors(history []InspectionSyncError, conditions []operatorv1.OperatorCondition) []InspectionSyncError {
+	degraded := []operatorv1.OperatorCondition{}
+	for _, condition := range conditions {
+		if strings.HasSuffix(condition.Type, operatorv1.OperatorStatusTypeDegraded) && condition.Status == operatorv1.ConditionTrue {
+			degraded = append(degraded, condition)
+		}
+	}
+	sort.SliceStable(degraded, func(i, j int) bool {
+		return degraded[i].LastTransitionTime.Before(&degraded[j].LastTransitionTime)
+	})
+
+	for _, condition := range degraded {
+		if last := lastSyncError(history, condition.Type); last != nil &&
+			last.Reason == condition.Reason && last.Message == condition.Message && last.Time.Equal(&condition.LastTransitionTime) {
+			continue
+		}
+		history = append(history, InspectionSyncError{
+			Time:      condition.LastTransitionTime,
+			Condition: condition.Type,
+			Reason:    condition.Reason,
+			Message:   condition.Message,
+		})
+	}
+	if len(history) > maxInspectionSyncErrors {
+		history = history[len(history)-maxInspectionSyncErrors:]
+	}
+	return history
+}
+
+func lastSyncError(history []InspectionSyncError, condition string) *InspectionSyncError {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Condition == condition {
+			return &history[i]
+		}
+	}
+	return nil
+}
+
+func DefaultInspectionConfigMap(state InspectionState) (*corev1.ConfigMap, error) {
+	stateYAML, err := yaml.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	configMap := resourceread.ReadConfigMapV1OrDie(bindata.MustAsset("assets/configmaps/console-operator-inspection-configmap.yaml"))
+	configMap.Data = map[string]string{
+		inspectionStateKey: string(stateYAML),
+	}
+	return configMap, nil
+}
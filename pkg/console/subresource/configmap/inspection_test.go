@@ -0,0 +1,159 @@
+package configmap
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+func TestWithSyncErrors(t *testing.T) {
+	t1 := metav1.NewTime(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC))
+	t2 := metav1.NewTime(time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC))
+	routeDegraded := operatorv1.OperatorCondition{Type: "RouteHealthDegraded", Status: operatorv1.ConditionTrue, Reason: "FailedGet", Message: "route not found", LastTransitionTime: t2}
+	deploymentDegraded := operatorv1.OperatorCondition{Type: "DeploymentSyncDegraded", Status: operatorv1.ConditionTrue, Reason: "FailedApply", Message: "conflict", LastTransitionTime: t1}
+
+	fullHistory := []InspectionSyncError{}
+	for i := 0; i < maxInspectionSyncErrors; i++ {
+		fullHistory = append(fullHistory, InspectionSyncError{Time: t1, Condition: fmt.Sprintf("Sync%dDegraded", i)})
+	}
+
+	tests := []struct {
+		name       string
+		history    []InspectionSyncError
+		conditions []operatorv1.OperatorCondition
+		want       []InspectionSyncError
+	}{
+		{
+			name: "Test degraded conditions are recorded oldest first",
+			conditions: []operatorv1.OperatorCondition{
+				routeDegraded,
+				deploymentDegraded,
+				{Type: "DeploymentSyncProgressing", Status: operatorv1.ConditionTrue, Reason: "InProgress", LastTransitionTime: t1},
+				{Type: "ServiceSyncDegraded", Status: operatorv1.ConditionFalse, LastTransitionTime: t1},
+			},
+			want: []InspectionSyncError{
+				{Time: t1, Condition: "DeploymentSyncDegraded", Reason: "FailedApply", Message: "conflict"},
+				{Time: t2, Condition: "RouteHealthDegraded", Reason: "FailedGet", Message: "route not found"},
+			},
+		},
+		{
+			name: "Test recorded conditions are not recorded again",
+			history: []InspectionSyncError{
+				{Time: t1, Condition: "DeploymentSyncDegraded", Reason: "FailedApply", Message: "conflict"},
+			},
+			conditions: []operatorv1.OperatorCondition{deploymentDegraded},
+			want: []InspectionSyncError{
+				{Time: t1, Condition: "DeploymentSyncDegraded", Reason: "FailedApply", Message: "conflict"},
+			},
+		},
+		{
+			name:       "Test the oldest errors are dropped",
+			history:    fullHistory,
+			conditions: []operatorv1.OperatorCondition{routeDegraded},
+			want: append(append([]InspectionSyncError{}, fullHistory[1:]...),
+				InspectionSyncError{Time: t2, Condition: "RouteHealthDegraded", Reason: "FailedGet", Message: "route not found"},
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(WithSyncErrors(tt.history, tt.conditions), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestInspectionConfigMapRoundTrip(t *testing.T) {
+	syncErrors := []InspectionSyncError{
+		{Time: metav1.NewTime(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)), Condition: "DeploymentSyncDegraded", Reason: "FailedApply", Message: "conflict"},
+	}
+	configMap, err := DefaultInspectionConfigMap(InspectionState{
+		ManagementState: operatorv1.Managed,
+		Annotations:     RedactAnnotations(map[string]string{"example.com/api-token": "s3cr3t", "example.com/owner": "console"}),
+		SyncErrors:      syncErrors,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(GetInspectionSyncErrors(configMap), syncErrors); diff != nil {
+		t.Error(diff)
+	}
+	want := `annotations:
+  example.com/api-token: <redacted>
+  example.com/owner: console
+generation: 0
+managementState: Managed
+observedGeneration: 0
+readyReplicas: 0
+syncErrors:
+- condition: DeploymentSyncDegraded
+  message: conflict
+  reason: FailedApply
+  time: "2024-01-01T10:00:00Z"
+`
+	if diff := deep.Equal(configMap.Data["state.yaml"], want); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestRedactUnsupportedConfigOverrides(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  []byte
+		want interface{}
+	}{
+		{
+			name: "Test no overrides",
+		},
+		{
+			name: "Test sensitive fields are redacted",
+			raw:  []byte(`{"telemetry":{"SEGMENT_API_KEY":"key","enabled":"true"}}`),
+			want: map[string]interface{}{
+				"telemetry": map[string]interface{}{"SEGMENT_API_KEY": "<redacted>", "enabled": "true"},
+			},
+		},
+		{
+			name: "Test invalid overrides",
+			raw:  []byte(`{"telemetry":`),
+			want: "<redacted>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(RedactUnsupportedConfigOverrides(tt.raw), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestNewInspectionSecret(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "custom-tls", ResourceVersion: "7"},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{"tls.key": []byte("key"), "tls.crt": []byte("crt")},
+	}
+	want := InspectionSecret{
+		Route:           "console",
+		Namespace:       "openshift-config",
+		Name:            "custom-tls",
+		Found:           true,
+		Type:            "kubernetes.io/tls",
+		Keys:            []string{"tls.crt", "tls.key"},
+		ResourceVersion: "7",
+	}
+	if diff := deep.Equal(NewInspectionSecret("console", "openshift-config", "custom-tls", secret), want); diff != nil {
+		t.Error(diff)
+	}
+	missing := InspectionSecret{Route: "console", Namespace: "openshift-config", Name: "custom-tls"}
+	if diff := deep.Equal(NewInspectionSecret("console", "openshift-config", "custom-tls", nil), missing); diff != nil {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,258 @@
+package configmap
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+
+	"github.com/openshift/console-operator/bindata"
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
+)
+
+const (
+	multiclusterHubKey        = "hub"
+	managedClustersKey        = "managed-clusters.yaml"
+	managedClusterStatusKey   = "status.yaml"
+	managedClusterCAKeyPrefix = "managed-cluster-ca-"
+
+	// MulticlusterHubACM and MulticlusterHubMCE are the hub components the cluster switcher is
+	// enabled for, Advanced Cluster Management bundles the multicluster engine
+	MulticlusterHubACM = "ACM"
+	MulticlusterHubMCE = "MCE"
+)
+
+// MulticlusterConfig is what console-config needs from the managed-clusters ConfigMap.
+type MulticlusterConfig struct {
+	// Hub is the hub component detected on the cluster
+	Hub      string
+	Clusters []ManagedCluster
+}
+
+// ManagedCluster is a cluster of the fleet managed by the hub, aggregated from its
+// ManagedCluster resource into the managed-clusters.yaml key of the managed-clusters ConfigMap.
+type ManagedCluster struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName,omitempty"`
+	// APIServerURL is the https:// URL the hub reaches the cluster API server on
+	APIServerURL string `json:"apiServerURL"`
+	// CAKey is the key of the ConfigMap holding the PEM CA bundle of the API server,
+	// the console trusts the system roots without one
+	CAKey string `json:"caKey,omitempty"`
+
+	caBundle string
+	// oauthClient is set once a console client is provisioned on the cluster
+	oauthClient bool
+}
+
+// ManagedClusterStatus is the outcome of the validation of a single ManagedCluster, listed in
+// the status.yaml key of the managed-clusters ConfigMap.
+type ManagedClusterStatus struct {
+	Name      string `json:"name"`
+	Valid     bool   `json:"valid"`
+	Available bool   `json:"available"`
+	Message   string `json:"message,omitempty"`
+}
+
+func NewManagedCluster(name, displayName, apiServerURL, caBundle string) ManagedCluster {
+	cluster := ManagedCluster{
+		Name:         name,
+		DisplayName:  displayName,
+		APIServerURL: apiServerURL,
+		caBundle:     caBundle,
+	}
+	if len(caBundle) > 0 {
+		cluster.CAKey = managedClusterCAKey(cluster)
+	}
+	return cluster
+}
+
+// ValidateManagedCluster checks what the console needs to reach the API server of cluster.
+func ValidateManagedCluster(cluster ManagedCluster) error {
+	if len(validation.IsDNS1123Label(cluster.Name)) > 0 {
+		return fmt.Errorf("name %q must be a DNS label", cluster.Name)
+	}
+	parsed, err := url.Parse(cluster.APIServerURL)
+	if err != nil || parsed.Scheme != "https" || len(parsed.Host) == 0 {
+		return fmt.Errorf("API server URL %q must be an https:// URL", cluster.APIServerURL)
+	}
+	if len(cluster.CAKey) > 0 {
+		return validateCABundle(cluster.CAKey, cluster.caBundle)
+	}
+	return nil
+}
+
+// ValidateManagedClustersConfig reads back the data of the managed-clusters ConfigMap. Invalid
+// clusters are left out and reported in the returned error, the valid ones are returned sorted by name.
+func ValidateManagedClustersConfig(data map[string]string) (*MulticlusterConfig, error) {
+	hub := data[multiclusterHubKey]
+	if hub != MulticlusterHubACM && hub != MulticlusterHubMCE {
+		return nil, fmt.Errorf("invalid managed clusters config: unknown hub %q", hub)
+	}
+	clusters := []ManagedCluster{}
+	if err := yaml.Unmarshal([]byte(data[managedClustersKey]), &clusters); err != nil {
+		return nil, fmt.Errorf("invalid managed clusters config: %s: %w", managedClustersKey, err)
+	}
+
+	valid := []ManagedCluster{}
+	invalid := []string{}
+	names := map[string]bool{}
+	for i, cluster := range clusters {
+		if len(cluster.CAKey) > 0 {
+			cluster.caBundle = data[cluster.CAKey]
+		}
+		err := ValidateManagedCluster(cluster)
+		if err == nil && names[cluster.Name] {
+			err = fmt.Errorf("name %q is listed more than once", cluster.Name)
+		}
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("clusters[%d]: %v", i, err))
+			continue
+		}
+		names[cluster.Name] = true
+		valid = append(valid, cluster)
+	}
+	sort.Slice(valid, func(i, j int) bool { return valid[i].Name < valid[j].Name })
+	config := &MulticlusterConfig{Hub: hub, Clusters: valid}
+	if len(invalid) > 0 {
+		return config, fmt.Errorf("invalid managed clusters config: %s", strings.Join(invalid, ", "))
+	}
+	return config, nil
+}
+
+// WithManagedClusterOAuthClients returns config with the clusters that have a client secret in
+// the managed-cluster-oauth-clients secret rendered with their console client.
+func WithManagedClusterOAuthClients(config *MulticlusterConfig, oauthClientsSecret *corev1.Secret) *MulticlusterConfig {
+	if config == nil || oauthClientsSecret == nil {
+		return config
+	}
+	withClients := &MulticlusterConfig{Hub: config.Hub, Clusters: make([]ManagedCluster, 0, len(config.Clusters))}
+	for _, cluster := range config.Clusters {
+		cluster.oauthClient = len(oauthClientsSecret.Data[cluster.Name]) > 0
+		withClients.Clusters = append(withClients.Clusters, cluster)
+	}
+	return withClients
+}
+
+func managedClusterCAKey(cluster ManagedCluster) string {
+	return managedClusterCAKeyPrefix + cluster.Name + ".crt"
+}
+
+// getMulticluster enables the cluster switcher on hubs.
+func getMulticluster(config *MulticlusterConfig) consoleserver.Multicluster {
+	if config == nil {
+		return consoleserver.Multicluster{}
+	}
+	return consoleserver.Multicluster{ClusterSwitcher: true, Hub: config.Hub}
+}
+
+// getManagedClusters renders the clusters for console-config, pointing at the CA bundles
+// returned by getManagedClusterCABundles.
+func getManagedClusters(config *MulticlusterConfig) []consoleserver.ManagedCluster {
+	if config == nil || len(config.Clusters) == 0 {
+		return nil
+	}
+	rendered := make([]consoleserver.ManagedCluster, 0, len(config.Clusters))
+	for _, cluster := range config.Clusters {
+		renderedCluster := consoleserver.ManagedCluster{
+			Name:        cluster.Name,
+			DisplayName: cluster.DisplayName,
+			APIServer: consoleserver.ManagedClusterAPIServer{
+				URL: cluster.APIServerURL,
+			},
+		}
+		if len(cluster.caBundle) > 0 {
+			renderedCluster.APIServer.CAFile = path.Join(consoleConfigMountDir, managedClusterCAKey(cluster))
+		}
+		if cluster.oauthClient {
+			renderedCluster.OAuth = &consoleserver.ManagedClusterOAuth{
+				ClientID:         api.ManagedClusterOAuthClientName,
+				ClientSecretFile: path.Join(api.ManagedClusterOAuthMountDir, cluster.Name),
+			}
+		}
+		rendered = append(rendered, renderedCluster)
+	}
+	return rendered
+}
+
+// getManagedClusterCABundles returns the console-config keys of the cluster CA bundles.
+func getManagedClusterCABundles(config *MulticlusterConfig) map[string]string {
+	caBundles := map[string]string{}
+	if config == nil {
+		return caBundles
+	}
+	for _, cluster := range config.Clusters {
+		if len(cluster.caBundle) > 0 {
+			caBundles[managedClusterCAKey(cluster)] = cluster.caBundle
+		}
+	}
+	return caBundles
+}
+
+// DefaultManagedClustersConfigMap lists the detected hub and the valid clusters along with their
+// CA bundles, and the validation status of every cluster.
+func DefaultManagedClustersConfigMap(config MulticlusterConfig, statuses []ManagedClusterStatus) (*corev1.ConfigMap, error) {
+	clustersYAML, err := yaml.Marshal(config.Clusters)
+	if err != nil {
+		return nil, err
+	}
+	statusYAML, err := yaml.Marshal(statuses)
+	if err != nil {
+		return nil, err
+	}
+	configMap := ManagedClustersConfigMapStub()
+	configMap.Data = map[string]string{
+		multiclusterHubKey:      config.Hub,
+		managedClustersKey:      string(clustersYAML),
+		managedClusterStatusKey: string(statusYAML),
+	}
+	for key, caBundle := range getManagedClusterCABundles(&config) {
+		configMap.Data[key] = caBundle
+	}
+	return configMap, nil
+}
+
+// DefaultManagedClusterCABundleConfigMap aggregates the API server CAs of clusters into a single
+// trust bundle, nil when none of them has its own CA. Certificates shared by several clusters
+// are listed once, in the order of the first cluster trusting them.
+func DefaultManagedClusterCABundleConfigMap(clusters []ManagedCluster) *corev1.ConfigMap {
+	seen := map[string]bool{}
+	caBundle := []byte{}
+	for _, cluster := range clusters {
+		rest := []byte(cluster.caBundle)
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			if block.Type != "CERTIFICATE" || seen[string(block.Bytes)] {
+				continue
+			}
+			seen[string(block.Bytes)] = true
+			caBundle = append(caBundle, pem.EncodeToMemory(block)...)
+		}
+	}
+	if len(caBundle) == 0 {
+		return nil
+	}
+
+	configMap := resourceread.ReadConfigMapV1OrDie(bindata.MustAsset("assets/configmaps/managed-cluster-ca-bundle-configmap.yaml"))
+	configMap.Data = map[string]string{
+		api.TrustedCABundleKey: string(caBundle),
+	}
+	return configMap
+}
+
+func ManagedClustersConfigMapStub() *corev1.ConfigMap {
+	return resourceread.ReadConfigMapV1OrDie(bindata.MustAsset("assets/configmaps/managed-clusters-configmap.yaml"))
+}
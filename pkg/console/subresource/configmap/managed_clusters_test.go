@@ -0,0 +1,189 @@
+package configmap
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift/console-operator/pkg/console/subresource/consoleserver"
+)
+
+func TestValidateManagedClustersConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		want    *MulticlusterConfig
+		wantErr string
+	}{
+		{
+			name: "Test valid clusters are sorted by name",
+			data: map[string]string{
+				"hub": "ACM",
+				"managed-clusters.yaml": `- name: west
+  apiServerURL: https://api.west.example.com:6443
+- name: east
+  displayName: East (production)
+  apiServerURL: https://api.east.example.com:6443
+  caKey: managed-cluster-ca-east.crt
+`,
+				"managed-cluster-ca-east.crt": validCertificate,
+			},
+			want: &MulticlusterConfig{Hub: "ACM", Clusters: []ManagedCluster{
+				{Name: "east", DisplayName: "East (production)", APIServerURL: "https://api.east.example.com:6443", CAKey: "managed-cluster-ca-east.crt", caBundle: validCertificate},
+				{Name: "west", APIServerURL: "https://api.west.example.com:6443"},
+			}},
+		},
+		{
+			name: "Test hub without managed clusters",
+			data: map[string]string{"hub": "MCE"},
+			want: &MulticlusterConfig{Hub: "MCE", Clusters: []ManagedCluster{}},
+		},
+		{
+			name:    "Test unknown hub",
+			data:    map[string]string{"hub": "OCM", "managed-clusters.yaml": "[]"},
+			wantErr: `invalid managed clusters config: unknown hub "OCM"`,
+		},
+		{
+			name: "Test invalid clusters are left out",
+			data: map[string]string{
+				"hub": "ACM",
+				"managed-clusters.yaml": `- name: east
+  apiServerURL: https://api.east.example.com:6443
+- name: east
+  apiServerURL: https://api.east.example.com:6443
+- name: West
+  apiServerURL: https://api.west.example.com:6443
+- name: north
+  apiServerURL: api.north.example.com:6443
+- name: central
+  apiServerURL: https://api.central.example.com:6443
+  caKey: managed-cluster-ca-central.crt
+`,
+				"managed-cluster-ca-central.crt": "not a certificate",
+			},
+			want: &MulticlusterConfig{Hub: "ACM", Clusters: []ManagedCluster{
+				{Name: "east", APIServerURL: "https://api.east.example.com:6443"},
+			}},
+			wantErr: `invalid managed clusters config: clusters[1]: name "east" is listed more than once, clusters[2]: name "West" must be a DNS label, clusters[3]: API server URL "api.north.example.com:6443" must be an https:// URL, clusters[4]: caKey "managed-cluster-ca-central.crt" holds no PEM certificates`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateManagedClustersConfig(tt.data)
+			gotErr := ""
+			if err != nil {
+				gotErr = err.Error()
+			}
+			if diff := deep.Equal(gotErr, tt.wantErr); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestDefaultManagedClustersConfigMap(t *testing.T) {
+	clusters := []ManagedCluster{
+		NewManagedCluster("east", "East (production)", "https://api.east.example.com:6443", validCertificate),
+		NewManagedCluster("west", "", "https://api.west.example.com:6443", ""),
+	}
+	statuses := []ManagedClusterStatus{
+		{Name: "east", Valid: true, Available: true},
+		{Name: "north", Message: "no API server URL is reported"},
+		{Name: "west", Valid: true, Message: "the cluster is not available"},
+	}
+	configMap, err := DefaultManagedClustersConfigMap(MulticlusterConfig{Hub: MulticlusterHubMCE, Clusters: clusters}, statuses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the console-config side reads back what the controller wrote
+	got, err := ValidateManagedClustersConfig(configMap.Data)
+	if err != nil {
+		t.Error(err)
+	}
+	if diff := deep.Equal(got, &MulticlusterConfig{Hub: MulticlusterHubMCE, Clusters: clusters}); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(getMulticluster(got), consoleserver.Multicluster{ClusterSwitcher: true, Hub: "MCE"}); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(getMulticluster(nil), consoleserver.Multicluster{}); diff != nil {
+		t.Error(diff)
+	}
+
+	want := []consoleserver.ManagedCluster{
+		{Name: "east", DisplayName: "East (production)", APIServer: consoleserver.ManagedClusterAPIServer{URL: "https://api.east.example.com:6443", CAFile: "/var/console-config/managed-cluster-ca-east.crt"}},
+		{Name: "west", APIServer: consoleserver.ManagedClusterAPIServer{URL: "https://api.west.example.com:6443"}},
+	}
+	if diff := deep.Equal(getManagedClusters(got), want); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(getManagedClusterCABundles(got), map[string]string{"managed-cluster-ca-east.crt": validCertificate}); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestWithManagedClusterOAuthClients(t *testing.T) {
+	config := &MulticlusterConfig{Hub: MulticlusterHubACM, Clusters: []ManagedCluster{
+		NewManagedCluster("east", "", "https://api.east.example.com:6443", ""),
+		NewManagedCluster("west", "", "https://api.west.example.com:6443", ""),
+	}}
+	secret := &corev1.Secret{Data: map[string][]byte{"east": []byte("secret"), "north": []byte("secret")}}
+
+	want := []consoleserver.ManagedCluster{
+		{
+			Name:      "east",
+			APIServer: consoleserver.ManagedClusterAPIServer{URL: "https://api.east.example.com:6443"},
+			OAuth:     &consoleserver.ManagedClusterOAuth{ClientID: "multicluster-console", ClientSecretFile: "/var/managed-cluster-oauth/east"},
+		},
+		{Name: "west", APIServer: consoleserver.ManagedClusterAPIServer{URL: "https://api.west.example.com:6443"}},
+	}
+	if diff := deep.Equal(getManagedClusters(WithManagedClusterOAuthClients(config, secret)), want); diff != nil {
+		t.Error(diff)
+	}
+	// the config read from the lister cache is left untouched
+	if diff := deep.Equal(config.Clusters[0].oauthClient, false); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestDefaultManagedClusterCABundleConfigMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		clusters []ManagedCluster
+		want     map[string]string
+	}{
+		{
+			name: "Test no cluster with its own CA",
+			clusters: []ManagedCluster{
+				NewManagedCluster("west", "", "https://api.west.example.com:6443", ""),
+			},
+		},
+		{
+			name: "Test CAs shared by clusters are listed once",
+			clusters: []ManagedCluster{
+				NewManagedCluster("east", "", "https://api.east.example.com:6443", validCertificate),
+				NewManagedCluster("north", "", "https://api.north.example.com:6443", validCertificate),
+				NewManagedCluster("west", "", "https://api.west.example.com:6443", ""),
+			},
+			want: map[string]string{"ca-bundle.crt": validCertificate + "\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configMap := DefaultManagedClusterCABundleConfigMap(tt.clusters)
+			if tt.want == nil {
+				if configMap != nil {
+					t.Errorf("expected no CA bundle, got %v", configMap.Data)
+				}
+				return
+			}
+			if diff := deep.Equal(configMap.Data, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
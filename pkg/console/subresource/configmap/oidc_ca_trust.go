@@ -0,0 +1,100 @@
+package configmap
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+// DefaultOIDCCATrustConfigMap merges the ca-bundle.crt of the sources the OIDC issuer is trusted
+// with, eg. the provider CA, the cluster proxy CA and a user supplied bundle, into the single
+// ConfigMap the console mounts. Certificates found in several sources are kept once, in the order
+// of the sources. The hash of the merged bundle is set as an annotation, the console is rolled out
+// when it changes rather than whenever one of the sources is updated. It returns nil when none
+// of the sources holds a certificate.
+func DefaultOIDCCATrustConfigMap(operatorConfig *operatorv1.Console, sources ...*corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	merged := bytes.Buffer{}
+	seen := map[string]bool{}
+	for _, source := range sources {
+		if source == nil || len(source.Data[api.AuthServerCAFileName]) == 0 {
+			continue
+		}
+		certificates := 0
+		rest := []byte(source.Data[api.AuthServerCAFileName])
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			certificates++
+			if seen[string(block.Bytes)] {
+				continue
+			}
+			seen[string(block.Bytes)] = true
+			if err := pem.Encode(&merged, &pem.Block{Type: block.Type, Bytes: block.Bytes}); err != nil {
+				return nil, err
+			}
+		}
+		if certificates == 0 {
+			return nil, fmt.Errorf("no PEM certificate in %s of configmap %s/%s", api.AuthServerCAFileName, source.Namespace, source.Name)
+		}
+	}
+	if merged.Len() == 0 {
+		return nil, nil
+	}
+
+	hash := sha256.Sum256(merged.Bytes())
+	configMap := OIDCCATrustStub()
+	configMap.Annotations = map[string]string{
+		api.CABundleHashAnnotation: hex.EncodeToString(hash[:]),
+	}
+	configMap.Data = map[string]string{
+		api.AuthServerCAFileName: merged.String(),
+	}
+	util.AddOwnerRef(configMap, util.OwnerRefFrom(operatorConfig))
+	return configMap, nil
+}
+
+// OIDCCATrustSources returns the names of the openshift-config ConfigMaps merged into the OIDC
+// CA trust: the CA of the primary OIDC provider, the trusted CA of the cluster proxy and the
+// bundle referenced by the OIDC CA bundle annotation of the operator config.
+func OIDCCATrustSources(operatorConfig *operatorv1.Console, authnConfig *configv1.Authentication, proxyConfig *configv1.Proxy) []string {
+	if authnConfig.Spec.Type != configv1.AuthenticationTypeOIDC {
+		return []string{}
+	}
+	candidates := []string{operatorConfig.Annotations[api.OIDCCABundleAnnotation]}
+	if proxyConfig != nil {
+		candidates = append([]string{proxyConfig.Spec.TrustedCA.Name}, candidates...)
+	}
+	if oidcProvider := util.GetPrimaryOIDCProvider(authnConfig); oidcProvider != nil {
+		candidates = append([]string{oidcProvider.Issuer.CertificateAuthority.Name}, candidates...)
+	}
+	names := []string{}
+	for _, name := range util.RemoveDuplicateStr(candidates) {
+		if len(name) > 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func OIDCCATrustStub() *corev1.ConfigMap {
+	meta := util.SharedMeta()
+	meta.Name = api.OIDCCATrustConfigMapName
+	return &corev1.ConfigMap{
+		ObjectMeta: meta,
+	}
+}
@@ -0,0 +1,144 @@
+package configmap
+
+import (
+	"encoding/pem"
+	"testing"
+
+	"github.com/go-test/deep"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestDefaultOIDCCATrustConfigMap(t *testing.T) {
+	certificate := func(content string) string {
+		return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte(content)}))
+	}
+	source := func(name string, bundle string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: api.OpenShiftConfigNamespace},
+			Data:       map[string]string{api.AuthServerCAFileName: bundle},
+		}
+	}
+	tests := []struct {
+		name       string
+		sources    []*corev1.ConfigMap
+		wantBundle string
+		wantErr    bool
+	}{
+		{
+			name:       "Test no certificate in the sources",
+			sources:    []*corev1.ConfigMap{nil, source("provider-ca", "")},
+			wantBundle: "",
+		},
+		{
+			name: "Test certificates merged in the order of the sources",
+			sources: []*corev1.ConfigMap{
+				source("provider-ca", certificate("provider")),
+				source("user-ca-bundle", certificate("proxy")+certificate("corporate")),
+			},
+			wantBundle: certificate("provider") + certificate("proxy") + certificate("corporate"),
+		},
+		{
+			name: "Test certificates found in several sources kept once",
+			sources: []*corev1.ConfigMap{
+				source("provider-ca", certificate("provider")+"\n"),
+				source("user-ca-bundle", certificate("proxy")+certificate("provider")),
+			},
+			wantBundle: certificate("provider") + certificate("proxy"),
+		},
+		{
+			name: "Test source without a PEM certificate",
+			sources: []*corev1.ConfigMap{
+				source("provider-ca", certificate("provider")),
+				source("user-ca-bundle", "not a certificate"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configMap, err := DefaultOIDCCATrustConfigMap(&operatorv1.Console{}, tt.sources...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DefaultOIDCCATrustConfigMap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			bundle := ""
+			if configMap != nil {
+				bundle = configMap.Data[api.AuthServerCAFileName]
+				if len(configMap.Annotations[api.CABundleHashAnnotation]) == 0 {
+					t.Errorf("missing the %s annotation", api.CABundleHashAnnotation)
+				}
+			}
+			if diff := deep.Equal(bundle, tt.wantBundle); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+
+	// the hash only depends on the merged bundle
+	first, _ := DefaultOIDCCATrustConfigMap(&operatorv1.Console{}, source("provider-ca", certificate("provider")))
+	second, _ := DefaultOIDCCATrustConfigMap(&operatorv1.Console{}, source("provider-ca", certificate("provider")), source("user-ca-bundle", certificate("provider")))
+	if diff := deep.Equal(first.Annotations, second.Annotations); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestOIDCCATrustSources(t *testing.T) {
+	authnConfig := func(authType configv1.AuthenticationType, caName string) *configv1.Authentication {
+		return &configv1.Authentication{Spec: configv1.AuthenticationSpec{
+			Type: authType,
+			OIDCProviders: []configv1.OIDCProvider{{
+				Name:   "corporate",
+				Issuer: configv1.TokenIssuer{CertificateAuthority: configv1.ConfigMapNameReference{Name: caName}},
+			}},
+		}}
+	}
+	proxyConfig := &configv1.Proxy{Spec: configv1.ProxySpec{TrustedCA: configv1.ConfigMapNameReference{Name: "user-ca-bundle"}}}
+	userBundle := &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{api.OIDCCABundleAnnotation: "corporate-ca"}}}
+	tests := []struct {
+		name           string
+		operatorConfig *operatorv1.Console
+		authnConfig    *configv1.Authentication
+		proxyConfig    *configv1.Proxy
+		want           []string
+	}{
+		{
+			name:           "Test every source",
+			operatorConfig: userBundle,
+			authnConfig:    authnConfig(configv1.AuthenticationTypeOIDC, "provider-ca"),
+			proxyConfig:    proxyConfig,
+			want:           []string{"provider-ca", "user-ca-bundle", "corporate-ca"},
+		},
+		{
+			name:           "Test provider CA only",
+			operatorConfig: &operatorv1.Console{},
+			authnConfig:    authnConfig(configv1.AuthenticationTypeOIDC, "provider-ca"),
+			want:           []string{"provider-ca"},
+		},
+		{
+			name:           "Test source referenced twice",
+			operatorConfig: &operatorv1.Console{},
+			authnConfig:    authnConfig(configv1.AuthenticationTypeOIDC, "user-ca-bundle"),
+			proxyConfig:    proxyConfig,
+			want:           []string{"user-ca-bundle"},
+		},
+		{
+			name:           "Test no sources without OIDC",
+			operatorConfig: userBundle,
+			authnConfig:    authnConfig(configv1.AuthenticationTypeIntegratedOAuth, "provider-ca"),
+			proxyConfig:    proxyConfig,
+			want:           []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(OIDCCATrustSources(tt.operatorConfig, tt.authnConfig, tt.proxyConfig), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package configmap
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/blang/semver"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+
+	"github.com/openshift/console-operator/bindata"
+)
+
+const updateSummaryKey = "updates.json"
+
+// UpdateSummary is what the console update UI needs from the ClusterVersion, rendered by the
+// operator so that the console does not have to reach the update service itself, which fails
+// in clusters behind a proxy.
+type UpdateSummary struct {
+	CurrentVersion string `json:"currentVersion"`
+	Channel        string `json:"channel,omitempty"`
+	// Channels are the channels the current version is available in
+	Channels []string `json:"channels,omitempty"`
+	Upstream string   `json:"upstream,omitempty"`
+	// Proxied is true if the cluster reaches the update service through a proxy
+	Proxied            bool                   `json:"proxied"`
+	RetrievedUpdates   UpdateSummaryCondition `json:"retrievedUpdates"`
+	AvailableUpdates   []UpdateSummaryRelease `json:"availableUpdates"`
+	ConditionalUpdates []UpdateSummaryRelease `json:"conditionalUpdates"`
+}
+
+// UpdateSummaryCondition tells whether the available updates are fresh, and why not.
+type UpdateSummaryCondition struct {
+	Status  configv1.ConditionStatus `json:"status"`
+	Reason  string                   `json:"reason,omitempty"`
+	Message string                   `json:"message,omitempty"`
+}
+
+type UpdateSummaryRelease struct {
+	Version  string   `json:"version"`
+	Image    string   `json:"image"`
+	URL      string   `json:"url,omitempty"`
+	Channels []string `json:"channels,omitempty"`
+	// Recommended and Risks are only set for conditional updates
+	Recommended *bool               `json:"recommended,omitempty"`
+	Risks       []UpdateSummaryRisk `json:"risks,omitempty"`
+}
+
+type UpdateSummaryRisk struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+	URL     string `json:"url"`
+}
+
+// GetUpdateSummary summarizes the updates of clusterVersion, newest first.
+func GetUpdateSummary(clusterVersion *configv1.ClusterVersion, proxyConfig *configv1.Proxy) UpdateSummary {
+	summary := UpdateSummary{
+		CurrentVersion:     clusterVersion.Status.Desired.Version,
+		Channel:            clusterVersion.Spec.Channel,
+		Channels:           clusterVersion.Status.Desired.Channels,
+		Upstream:           string(clusterVersion.Spec.Upstream),
+		Proxied:            proxyConfig != nil && (len(proxyConfig.Status.HTTPSProxy) > 0 || len(proxyConfig.Status.HTTPProxy) > 0),
+		RetrievedUpdates:   UpdateSummaryCondition{Status: configv1.ConditionUnknown},
+		AvailableUpdates:   []UpdateSummaryRelease{},
+		ConditionalUpdates: []UpdateSummaryRelease{},
+	}
+	for _, condition := range clusterVersion.Status.Conditions {
+		if condition.Type == configv1.RetrievedUpdates {
+			summary.RetrievedUpdates = UpdateSummaryCondition{
+				Status:  condition.Status,
+				Reason:  condition.Reason,
+				Message: condition.Message,
+			}
+		}
+	}
+	for _, release := range clusterVersion.Status.AvailableUpdates {
+		summary.AvailableUpdates = append(summary.AvailableUpdates, updateSummaryRelease(release))
+	}
+	for _, update := range clusterVersion.Status.ConditionalUpdates {
+		release := updateSummaryRelease(update.Release)
+		recommended := meta.IsStatusConditionTrue(update.Conditions, "Recommended")
+		release.Recommended = &recommended
+		for _, risk := range update.Risks {
+			release.Risks = append(release.Risks, UpdateSummaryRisk{Name: risk.Name, Message: risk.Message, URL: risk.URL})
+		}
+		summary.ConditionalUpdates = append(summary.ConditionalUpdates, release)
+	}
+	sortReleases(summary.AvailableUpdates)
+	sortReleases(summary.ConditionalUpdates)
+	return summary
+}
+
+func updateSummaryRelease(release configv1.Release) UpdateSummaryRelease {
+	return UpdateSummaryRelease{
+		Version:  release.Version,
+		Image:    release.Image,
+		URL:      string(release.URL),
+		Channels: release.Channels,
+	}
+}
+
+// sortReleases sorts newest first, the update service returns them in no particular order and
+// applying a reordered list would churn the ConfigMap.
+func sortReleases(releases []UpdateSummaryRelease) {
+	sort.SliceStable(releases, func(i, j int) bool {
+		vi, erri := semver.ParseTolerant(releases[i].Version)
+		vj, errj := semver.ParseTolerant(releases[j].Version)
+		if erri != nil || errj != nil {
+			return releases[i].Version > releases[j].Version
+		}
+		return vi.GT(vj)
+	})
+}
+
+func DefaultUpdateSummaryConfigMap(clusterVersion *configv1.ClusterVersion, proxyConfig *configv1.Proxy) (*corev1.ConfigMap, error) {
+	summary, err := json.MarshalIndent(GetUpdateSummary(clusterVersion, proxyConfig), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	config := UpdateSummaryConfigMapStub()
+	config.Data = map[string]string{
+		updateSummaryKey: string(summary),
+	}
+	return config, nil
+}
+
+func EmptyUpdateSummaryConfigMap() *corev1.ConfigMap {
+	config := UpdateSummaryConfigMapStub()
+	config.Data = map[string]string{}
+	return config
+}
+
+func UpdateSummaryConfigMapStub() *corev1.ConfigMap {
+	return resourceread.ReadConfigMapV1OrDie(bindata.MustAsset("assets/configmaps/console-update-summary-configmap.yaml"))
+}
@@ -0,0 +1,102 @@
+package configmap
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func TestGetUpdateSummary(t *testing.T) {
+	recommended, notRecommended := true, false
+	tests := []struct {
+		name           string
+		clusterVersion *configv1.ClusterVersion
+		proxyConfig    *configv1.Proxy
+		want           UpdateSummary
+	}{
+		{
+			name: "Test updates are sorted newest first",
+			clusterVersion: &configv1.ClusterVersion{
+				Spec: configv1.ClusterVersionSpec{Channel: "stable-4.16"},
+				Status: configv1.ClusterVersionStatus{
+					Desired: configv1.Release{Version: "4.16.3", Channels: []string{"eus-4.16", "stable-4.16"}},
+					Conditions: []configv1.ClusterOperatorStatusCondition{
+						{Type: configv1.RetrievedUpdates, Status: configv1.ConditionTrue},
+					},
+					AvailableUpdates: []configv1.Release{
+						{Version: "4.16.5", Image: "quay.io/openshift-release-dev/ocp-release@sha256:5"},
+						{Version: "4.16.10", Image: "quay.io/openshift-release-dev/ocp-release@sha256:10"},
+					},
+					ConditionalUpdates: []configv1.ConditionalUpdate{
+						{
+							Release:    configv1.Release{Version: "4.16.7", Image: "quay.io/openshift-release-dev/ocp-release@sha256:7"},
+							Risks:      []configv1.ConditionalUpdateRisk{{Name: "AzureDiskStall", Message: "disks may stall", URL: "https://issues.example.com/1"}},
+							Conditions: []metav1.Condition{{Type: "Recommended", Status: metav1.ConditionFalse}},
+						},
+						{
+							Release:    configv1.Release{Version: "4.16.8", Image: "quay.io/openshift-release-dev/ocp-release@sha256:8"},
+							Risks:      []configv1.ConditionalUpdateRisk{{Name: "AzureDiskStall", Message: "disks may stall", URL: "https://issues.example.com/1"}},
+							Conditions: []metav1.Condition{{Type: "Recommended", Status: metav1.ConditionTrue}},
+						},
+					},
+				},
+			},
+			proxyConfig: &configv1.Proxy{},
+			want: UpdateSummary{
+				CurrentVersion:   "4.16.3",
+				Channel:          "stable-4.16",
+				Channels:         []string{"eus-4.16", "stable-4.16"},
+				RetrievedUpdates: UpdateSummaryCondition{Status: configv1.ConditionTrue},
+				AvailableUpdates: []UpdateSummaryRelease{
+					{Version: "4.16.10", Image: "quay.io/openshift-release-dev/ocp-release@sha256:10"},
+					{Version: "4.16.5", Image: "quay.io/openshift-release-dev/ocp-release@sha256:5"},
+				},
+				ConditionalUpdates: []UpdateSummaryRelease{
+					{
+						Version:     "4.16.8",
+						Image:       "quay.io/openshift-release-dev/ocp-release@sha256:8",
+						Recommended: &recommended,
+						Risks:       []UpdateSummaryRisk{{Name: "AzureDiskStall", Message: "disks may stall", URL: "https://issues.example.com/1"}},
+					},
+					{
+						Version:     "4.16.7",
+						Image:       "quay.io/openshift-release-dev/ocp-release@sha256:7",
+						Recommended: &notRecommended,
+						Risks:       []UpdateSummaryRisk{{Name: "AzureDiskStall", Message: "disks may stall", URL: "https://issues.example.com/1"}},
+					},
+				},
+			},
+		},
+		{
+			name: "Test proxied cluster failing to retrieve updates",
+			clusterVersion: &configv1.ClusterVersion{
+				Spec: configv1.ClusterVersionSpec{Channel: "stable-4.16"},
+				Status: configv1.ClusterVersionStatus{
+					Desired: configv1.Release{Version: "4.16.3"},
+					Conditions: []configv1.ClusterOperatorStatusCondition{
+						{Type: configv1.RetrievedUpdates, Status: configv1.ConditionFalse, Reason: "RemoteFailed", Message: "proxyconnect tcp: dial tcp: i/o timeout"},
+					},
+				},
+			},
+			proxyConfig: &configv1.Proxy{Status: configv1.ProxyStatus{HTTPSProxy: "https://proxy.example.com:3128"}},
+			want: UpdateSummary{
+				CurrentVersion:     "4.16.3",
+				Channel:            "stable-4.16",
+				Proxied:            true,
+				RetrievedUpdates:   UpdateSummaryCondition{Status: configv1.ConditionFalse, Reason: "RemoteFailed", Message: "proxyconnect tcp: dial tcp: i/o timeout"},
+				AvailableUpdates:   []UpdateSummaryRelease{},
+				ConditionalUpdates: []UpdateSummaryRelease{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(GetUpdateSummary(tt.clusterVersion, tt.proxyConfig), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -21,6 +21,8 @@ const (
 	// serving info
 	certFilePath = "/var/serving-cert/tls.crt"
 	keyFilePath  = "/var/serving-cert/tls.key"
+	// access log
+	accessLogFileName = "access.log"
 )
 
 // ConsoleServerCLIConfigBuilder
@@ -47,6 +49,7 @@ type ConsoleServerCLIConfigBuilder struct {
 	apiServerURL               string
 	controlPlaneToplogy        configv1.TopologyMode
 	statusPageID               string
+	statusProvider             *util.StatusProviderConfig
 	customProductName          string
 	devCatalogCustomization    operatorv1.DeveloperConsoleCatalogCustomization
 	projectAccess              operatorv1.ProjectAccess
@@ -58,6 +61,8 @@ type ConsoleServerCLIConfigBuilder struct {
 	monitoring                 map[string]string
 	customHostnameRedirectPort int
 	inactivityTimeoutSeconds   int
+	groupInactivityTimeouts    map[string]int
+	accessTokenMaxAgeSeconds   int
 	pluginsList                map[string]string
 	i18nNamespaceList          []string
 	proxyServices              []ProxyService
@@ -66,12 +71,32 @@ type ConsoleServerCLIConfigBuilder struct {
 	nodeArchitectures          []string
 	nodeOperatingSystems       []string
 	copiedCSVsDisabled         bool
+	readOnly                   bool
+	accessLog                  *util.AccessLogConfig
+	rateLimit                  util.RateLimitConfig
+	serverTuning               util.ServerTuningConfig
+	apiClient                  util.APIClientConfig
+	clusterProxyClusters       []ClusterProxyCluster
+	unhealthyClusterProxies    []UnhealthyClusterProxyCluster
+	multicluster               Multicluster
+	managedClusters            []ManagedCluster
+	contentSecurityPolicy      map[string][]string
 	oauthClientID              string
 	oidcExtraScopes            []string
 	oidcIssuerURL              string
+	oidcProviders              []OIDCProvider
+	oidcProviderName           string
+	oidcIssuerEndpoints        map[string]string
+	oidcClaimMappings          *OIDCClaimMappings
+	oidcAudiences              []string
+	oidcCLIClientID            string
+	oidcPublicClient           bool
+	oidcTokenValidation        util.OIDCTokenValidationConfig
 	authType                   string
+	authDisabled               bool
 	sessionEncryptionFile      string
 	sessionAuthenticationFile  string
+	previousSessionKeys        bool
 }
 
 func (b *ConsoleServerCLIConfigBuilder) Host(host string) *ConsoleServerCLIConfigBuilder {
@@ -142,6 +167,11 @@ func (b *ConsoleServerCLIConfigBuilder) StatusPageID(id string) *ConsoleServerCL
 	return b
 }
 
+func (b *ConsoleServerCLIConfigBuilder) StatusProvider(statusProvider *util.StatusProviderConfig) *ConsoleServerCLIConfigBuilder {
+	b.statusProvider = statusProvider
+	return b
+}
+
 func (b *ConsoleServerCLIConfigBuilder) AuthConfig(authnConfig *configv1.Authentication, caConfigMap *corev1.ConfigMap) *ConsoleServerCLIConfigBuilder {
 	switch authnConfig.Spec.Type {
 	case "", configv1.AuthenticationTypeIntegratedOAuth:
@@ -152,6 +182,7 @@ func (b *ConsoleServerCLIConfigBuilder) AuthConfig(authnConfig *configv1.Authent
 
 	case configv1.AuthenticationTypeNone:
 		b.authType = "disabled"
+		b.authDisabled = true
 		return b
 
 	case configv1.AuthenticationTypeOIDC:
@@ -161,26 +192,110 @@ func (b *ConsoleServerCLIConfigBuilder) AuthConfig(authnConfig *configv1.Authent
 			}
 		}
 
-		if len(authnConfig.Spec.OIDCProviders) == 0 {
+		providerClients := util.GetOIDCProviderClients(authnConfig)
+		if len(providerClients) == 0 {
 			b.authType = "disabled"
 			return b
 		}
 
-		oidcProvider := authnConfig.Spec.OIDCProviders[0]
-		oidcConfig := util.GetOIDCClientConfig(authnConfig)
-		if oidcConfig == nil {
-			b.authType = "disabled"
-			return b
+		// the first provider is the primary one, the console is deployed with its client secret and CA
+		primary := providerClients[0]
+		b.authType = "oidc"
+		b.oidcProviderName = primary.Provider.Name
+		b.oidcIssuerURL = primary.Provider.Issuer.URL
+		b.oauthClientID = primary.Client.ClientID
+		b.oidcExtraScopes = primary.Client.ExtraScopes
+		b.oidcClaimMappings = GetOIDCClaimMappings(primary.Provider)
+		b.oidcAudiences = getOIDCAudiences(primary.Provider)
+		b.oidcCLIClientID = getOIDCCLIClientID(primary.Provider)
+		if len(providerClients) > 1 {
+			for _, providerClient := range providerClients {
+				b.oidcProviders = append(b.oidcProviders, OIDCProvider{
+					Name:          providerClient.Provider.Name,
+					Issuer:        providerClient.Provider.Issuer.URL,
+					ClientID:      providerClient.Client.ClientID,
+					ExtraScopes:   providerClient.Client.ExtraScopes,
+					ClaimMappings: GetOIDCClaimMappings(providerClient.Provider),
+					Audiences:     getOIDCAudiences(providerClient.Provider),
+					CLIClientID:   getOIDCCLIClientID(providerClient.Provider),
+				})
+			}
 		}
+		b.sessionAuthenticationFile = path.Join(api.SessionSecretMountDir, api.SessionAuthenticationKey)
+		b.sessionEncryptionFile = path.Join(api.SessionSecretMountDir, api.SessionEncryptionKey)
+	}
 
-		b.authType = "oidc"
-		b.oidcIssuerURL = oidcProvider.Issuer.URL
-		b.oauthClientID = oidcConfig.ClientID
-		b.oidcExtraScopes = oidcConfig.ExtraScopes
-		b.sessionAuthenticationFile = "/var/session-secret/sessionAuthenticationKey"
-		b.sessionEncryptionFile = "/var/session-secret/sessionEncryptionKey"
+	return b
+}
+
+// getOIDCAudiences returns the audiences the tokens of an OIDC provider are issued for, for the
+// console to show in the login command of oc.
+func getOIDCAudiences(provider configv1.OIDCProvider) []string {
+	audiences := []string{}
+	for _, audience := range provider.Issuer.Audiences {
+		audiences = append(audiences, string(audience))
 	}
+	if len(audiences) == 0 {
+		return nil
+	}
+	return audiences
+}
+
+func getOIDCCLIClientID(provider configv1.OIDCProvider) string {
+	if cliClient := util.GetOIDCCLIClient(provider); cliClient != nil {
+		return cliClient.ClientID
+	}
+	return ""
+}
 
+// GetOIDCClaimMappings returns the claim mappings of an OIDC provider with the username prefix
+// resolved as the API server does: without a prefix policy, claims other than email are prefixed
+// with the issuer URL. It returns nil when the provider maps no claim.
+func GetOIDCClaimMappings(provider configv1.OIDCProvider) *OIDCClaimMappings {
+	usernameMapping := provider.ClaimMappings.Username
+	groupsMapping := provider.ClaimMappings.Groups
+	if len(usernameMapping.Claim) == 0 && len(groupsMapping.Claim) == 0 {
+		return nil
+	}
+
+	mappings := &OIDCClaimMappings{
+		UsernameClaim: usernameMapping.Claim,
+		GroupsClaim:   groupsMapping.Claim,
+		GroupsPrefix:  groupsMapping.Prefix,
+	}
+	switch usernameMapping.PrefixPolicy {
+	case configv1.Prefix:
+		if usernameMapping.Prefix != nil {
+			mappings.UsernamePrefix = usernameMapping.Prefix.PrefixString
+		}
+	case configv1.NoOpinion:
+		if len(usernameMapping.Claim) > 0 && usernameMapping.Claim != "email" {
+			mappings.UsernamePrefix = provider.Issuer.URL + "#"
+		}
+	}
+	return mappings
+}
+
+// OIDCIssuerEndpoints sets the URLs the issuers of the OIDC providers are reached at, by
+// provider name, for the providers whose advertised issuer URL is not reachable from the cluster.
+func (b *ConsoleServerCLIConfigBuilder) OIDCIssuerEndpoints(endpoints map[string]string) *ConsoleServerCLIConfigBuilder {
+	b.oidcIssuerEndpoints = endpoints
+	return b
+}
+
+// OIDCTokenValidation sets the leeway the OIDC tokens are validated with.
+func (b *ConsoleServerCLIConfigBuilder) OIDCTokenValidation(tokenValidation util.OIDCTokenValidationConfig) *ConsoleServerCLIConfigBuilder {
+	b.oidcTokenValidation = tokenValidation
+	return b
+}
+
+// SessionSecret enables the previous session key files when the session secret
+// carries a rotated-out key pair.
+func (b *ConsoleServerCLIConfigBuilder) SessionSecret(sessionSecret *corev1.Secret) *ConsoleServerCLIConfigBuilder {
+	if sessionSecret != nil {
+		b.previousSessionKeys = len(sessionSecret.Data[api.PreviousSessionEncryptionKey]) > 0 &&
+			len(sessionSecret.Data[api.PreviousSessionAuthenticationKey]) > 0
+	}
 	return b
 }
 
@@ -196,6 +311,22 @@ func (b *ConsoleServerCLIConfigBuilder) InactivityTimeout(timeout int) *ConsoleS
 	return b
 }
 
+// OIDCPublicClient logs in without a client secret, using PKCE.
+func (b *ConsoleServerCLIConfigBuilder) OIDCPublicClient(publicClient bool) *ConsoleServerCLIConfigBuilder {
+	b.oidcPublicClient = publicClient
+	return b
+}
+
+func (b *ConsoleServerCLIConfigBuilder) AccessTokenMaxAge(maxAge int) *ConsoleServerCLIConfigBuilder {
+	b.accessTokenMaxAgeSeconds = maxAge
+	return b
+}
+
+func (b *ConsoleServerCLIConfigBuilder) GroupInactivityTimeouts(timeouts map[string]int) *ConsoleServerCLIConfigBuilder {
+	b.groupInactivityTimeouts = timeouts
+	return b
+}
+
 func (b *ConsoleServerCLIConfigBuilder) Plugins(plugins map[string]string) *ConsoleServerCLIConfigBuilder {
 	b.pluginsList = plugins
 	return b
@@ -236,6 +367,54 @@ func (b *ConsoleServerCLIConfigBuilder) CopiedCSVsDisabled(copiedCSVsDisabled bo
 	return b
 }
 
+func (b *ConsoleServerCLIConfigBuilder) ReadOnly(readOnly bool) *ConsoleServerCLIConfigBuilder {
+	b.readOnly = readOnly
+	return b
+}
+
+func (b *ConsoleServerCLIConfigBuilder) AccessLog(accessLog *util.AccessLogConfig) *ConsoleServerCLIConfigBuilder {
+	b.accessLog = accessLog
+	return b
+}
+
+func (b *ConsoleServerCLIConfigBuilder) RateLimit(rateLimit util.RateLimitConfig) *ConsoleServerCLIConfigBuilder {
+	b.rateLimit = rateLimit
+	return b
+}
+
+func (b *ConsoleServerCLIConfigBuilder) ServerTuning(serverTuning util.ServerTuningConfig) *ConsoleServerCLIConfigBuilder {
+	b.serverTuning = serverTuning
+	return b
+}
+
+func (b *ConsoleServerCLIConfigBuilder) APIClient(apiClient util.APIClientConfig) *ConsoleServerCLIConfigBuilder {
+	b.apiClient = apiClient
+	return b
+}
+
+func (b *ConsoleServerCLIConfigBuilder) ClusterProxy(clusters []ClusterProxyCluster, unhealthy []UnhealthyClusterProxyCluster) *ConsoleServerCLIConfigBuilder {
+	b.clusterProxyClusters = clusters
+	b.unhealthyClusterProxies = unhealthy
+	return b
+}
+
+func (b *ConsoleServerCLIConfigBuilder) Multicluster(multicluster Multicluster) *ConsoleServerCLIConfigBuilder {
+	b.multicluster = multicluster
+	return b
+}
+
+func (b *ConsoleServerCLIConfigBuilder) ManagedClusters(clusters []ManagedCluster) *ConsoleServerCLIConfigBuilder {
+	b.managedClusters = clusters
+	return b
+}
+
+// ContentSecurityPolicy extends the console CSP with the sources of policy, typically the
+// operator config policy merged with the ones of the enabled plugins.
+func (b *ConsoleServerCLIConfigBuilder) ContentSecurityPolicy(policy map[string][]string) *ConsoleServerCLIConfigBuilder {
+	b.contentSecurityPolicy = policy
+	return b
+}
+
 func (b *ConsoleServerCLIConfigBuilder) Config() Config {
 	return Config{
 		Kind:           "ConsoleConfig",
@@ -251,6 +430,15 @@ func (b *ConsoleServerCLIConfigBuilder) Config() Config {
 		I18nNamespaces: b.i18nNamespaces(),
 		Proxy:          b.proxy(),
 		Telemetry:      b.telemetry,
+		AccessLog:      b.accessLogConfig(),
+		RateLimit:      b.rateLimitConfig(),
+		HTTPServer:     b.httpServerConfig(),
+		APIClient:      b.apiClientConfig(),
+		ClusterProxy:   ClusterProxy{Clusters: b.clusterProxyClusters, UnhealthyClusters: b.unhealthyClusterProxies},
+
+		ContentSecurityPolicy: b.contentSecurityPolicy,
+		Multicluster:          b.multicluster,
+		ManagedClusters:       b.managedClusters,
 	}
 }
 
@@ -264,6 +452,52 @@ func (b *ConsoleServerCLIConfigBuilder) ConfigYAML() (consoleConfigYAML []byte,
 	return yml, nil
 }
 
+func (b *ConsoleServerCLIConfigBuilder) accessLogConfig() AccessLog {
+	if b.accessLog == nil {
+		return AccessLog{}
+	}
+	conf := AccessLog{
+		Format:             b.accessLog.Format,
+		SamplingRatio:      b.accessLog.SamplingRatio,
+		MaxEventsPerSecond: util.AccessLogMaxEventsPerSecond,
+	}
+	if b.accessLog.Destination == util.AccessLogDestinationFile {
+		conf.File = path.Join(api.AccessLogMountDir, accessLogFileName)
+		conf.MaxFileSizeMB = util.AccessLogMaxFileSizeMB
+		conf.MaxFileBackups = util.AccessLogMaxFileBackups
+	}
+	return conf
+}
+
+// rateLimitConfig renders the limits enforced by the console backend, the per IP
+// HTTP limit is enforced by the router and set on the console routes instead.
+func (b *ConsoleServerCLIConfigBuilder) rateLimitConfig() RateLimit {
+	return RateLimit{
+		LoginRequestsPerMinute:    b.rateLimit.LoginRequestsPerMinute,
+		LoginLockoutThreshold:     b.rateLimit.LoginLockoutThreshold,
+		LoginLockoutSeconds:       int(b.rateLimit.LoginLockoutDuration.Seconds()),
+		APIProxyRequestsPerSecond: b.rateLimit.APIProxyRequestsPerSecond,
+	}
+}
+
+func (b *ConsoleServerCLIConfigBuilder) httpServerConfig() HTTPServer {
+	return HTTPServer{
+		ReadTimeoutSeconds:  int(b.serverTuning.ReadTimeout.Seconds()),
+		WriteTimeoutSeconds: int(b.serverTuning.WriteTimeout.Seconds()),
+		IdleTimeoutSeconds:  int(b.serverTuning.IdleTimeout.Seconds()),
+		MaxHeaderBytes:      b.serverTuning.MaxHeaderBytes,
+		Compression:         b.serverTuning.Compression,
+	}
+}
+
+func (b *ConsoleServerCLIConfigBuilder) apiClientConfig() APIClient {
+	return APIClient{
+		QPS:            b.apiClient.QPS,
+		Burst:          b.apiClient.Burst,
+		TimeoutSeconds: int(b.apiClient.Timeout.Seconds()),
+	}
+}
+
 func (b *ConsoleServerCLIConfigBuilder) servingInfo() ServingInfo {
 	conf := ServingInfo{
 		BindAddress: "https://[::]:8443",
@@ -303,6 +537,7 @@ func (b *ConsoleServerCLIConfigBuilder) clusterInfo() ClusterInfo {
 		conf.NodeOperatingSystems = b.nodeOperatingSystems
 	}
 	conf.CopiedCSVsDisabled = b.copiedCSVsDisabled
+	conf.ReadOnly = b.readOnly
 	return conf
 }
 
@@ -335,6 +570,10 @@ func (b *ConsoleServerCLIConfigBuilder) monitoringInfo() MonitoringInfo {
 }
 
 func (b *ConsoleServerCLIConfigBuilder) auth() Auth {
+	// a console without authentication has no client, issuer or login session to configure
+	if b.authDisabled {
+		return Auth{AuthType: b.authType}
+	}
 	clientID := api.OAuthClientName
 	if clientIDOverride := b.oauthClientID; len(clientIDOverride) > 0 {
 		clientID = clientIDOverride
@@ -346,7 +585,30 @@ func (b *ConsoleServerCLIConfigBuilder) auth() Auth {
 		ClientSecretFile:         clientSecretFilePath,
 		OAuthEndpointCAFile:      b.CAFile,
 		InactivityTimeoutSeconds: b.inactivityTimeoutSeconds,
+		AccessTokenMaxAgeSeconds: b.accessTokenMaxAgeSeconds,
 		OIDCExtraScopes:          b.oidcExtraScopes,
+		OIDCProviders:            b.oidcProviders,
+		OIDCIssuerEndpoint:       b.oidcIssuerEndpoints[b.oidcProviderName],
+		OIDCClaimMappings:        b.oidcClaimMappings,
+		OIDCAudiences:            b.oidcAudiences,
+		OIDCCLIClientID:          b.oidcCLIClientID,
+		OIDCClockSkewSeconds:     int(b.oidcTokenValidation.ClockSkew.Seconds()),
+		OIDCIDTokenLeewaySeconds: int(b.oidcTokenValidation.IDTokenLeeway.Seconds()),
+	}
+	// a public client has no client secret mounted
+	if b.oidcPublicClient {
+		conf.ClientSecretFile = ""
+		conf.OIDCPublicClient = true
+	}
+	if len(b.oidcIssuerEndpoints) > 0 && len(b.oidcProviders) > 0 {
+		conf.OIDCProviders = make([]OIDCProvider, len(b.oidcProviders))
+		for i, provider := range b.oidcProviders {
+			provider.Endpoint = b.oidcIssuerEndpoints[provider.Name]
+			conf.OIDCProviders[i] = provider
+		}
+	}
+	if len(b.groupInactivityTimeouts) > 0 {
+		conf.GroupInactivityTimeoutSeconds = b.groupInactivityTimeouts
 	}
 	if len(b.logoutRedirectURL) > 0 {
 		conf.LogoutRedirect = b.logoutRedirectURL
@@ -359,6 +621,11 @@ func (b *ConsoleServerCLIConfigBuilder) session() Session {
 		CookieAuthenticationKeyFile: b.sessionAuthenticationFile,
 		CookieEncryptionKeyFile:     b.sessionEncryptionFile,
 	}
+	// previous keys are only meaningful next to the current ones
+	if b.previousSessionKeys && len(b.sessionEncryptionFile) > 0 {
+		conf.PreviousCookieEncryptionKeyFile = path.Join(api.SessionSecretMountDir, api.PreviousSessionEncryptionKey)
+		conf.PreviousCookieAuthenticationKeyFile = path.Join(api.SessionSecretMountDir, api.PreviousSessionAuthenticationKey)
+	}
 	return conf
 }
 
@@ -495,12 +762,18 @@ func (b *ConsoleServerCLIConfigBuilder) customization() Customization {
 }
 
 func (b *ConsoleServerCLIConfigBuilder) providers() Providers {
+	providers := Providers{}
 	if len(b.statusPageID) > 0 {
-		return Providers{
-			StatuspageID: b.statusPageID,
+		providers.StatuspageID = b.statusPageID
+	}
+	if b.statusProvider != nil {
+		providers.StatusFeed = &StatusFeed{
+			Type:                b.statusProvider.Type,
+			URL:                 b.statusProvider.URL,
+			PollIntervalSeconds: int(b.statusProvider.PollInterval.Seconds()),
 		}
 	}
-	return Providers{}
+	return providers
 }
 
 func (b *ConsoleServerCLIConfigBuilder) plugins() map[string]string {
@@ -3,6 +3,7 @@ package consoleserver
 import (
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/go-test/deep"
 	"github.com/google/go-cmp/cmp"
@@ -10,6 +11,7 @@ import (
 	operatorv1 "github.com/openshift/api/operator/v1"
 	v1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/util"
 	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -833,6 +835,338 @@ session:
   cookieAuthenticationKeyFile: /var/session-secret/sessionAuthenticationKey
 customization: {}
 providers: {}
+`,
+		},
+		{
+			name: "Config builder should render every OIDC provider with a console client",
+			input: func() ([]byte, error) {
+				consoleClient := func(clientID string) []configv1.OIDCClientConfig {
+					return []configv1.OIDCClientConfig{{ComponentNamespace: "openshift-console", ComponentName: "console", ClientID: clientID}}
+				}
+				b := &ConsoleServerCLIConfigBuilder{}
+				return b.AuthConfig(
+					&configv1.Authentication{
+						Spec: configv1.AuthenticationSpec{
+							Type: configv1.AuthenticationTypeOIDC,
+							OIDCProviders: []configv1.OIDCProvider{
+								{Name: "corporate", Issuer: configv1.TokenIssuer{URL: "https://sso.example.com"}, OIDCClients: consoleClient("corporate-id")},
+								{Name: "no-console", Issuer: configv1.TokenIssuer{URL: "https://other.example.com"}},
+								{Name: "partners", Issuer: configv1.TokenIssuer{URL: "https://partners.example.com"}, OIDCClients: consoleClient("partners-id")},
+							},
+						},
+					}, nil,
+				).ConfigYAML()
+			},
+			output: `apiVersion: console.openshift.io/v1
+kind: ConsoleConfig
+servingInfo:
+  bindAddress: https://[::]:8443
+  certFile: /var/serving-cert/tls.crt
+  keyFile: /var/serving-cert/tls.key
+clusterInfo: {}
+auth:
+  authType: oidc
+  oidcIssuer: https://sso.example.com
+  clientID: corporate-id
+  clientSecretFile: /var/oauth-config/clientSecret
+  oidcProviders:
+  - name: corporate
+    issuer: https://sso.example.com
+    clientID: corporate-id
+  - name: partners
+    issuer: https://partners.example.com
+    clientID: partners-id
+session:
+  cookieEncryptionKeyFile: /var/session-secret/sessionEncryptionKey
+  cookieAuthenticationKeyFile: /var/session-secret/sessionAuthenticationKey
+customization: {}
+providers: {}
+`,
+		},
+		{
+			name: "Config builder should render a public OIDC client without a client secret",
+			input: func() ([]byte, error) {
+				b := &ConsoleServerCLIConfigBuilder{}
+				return b.AuthConfig(
+					&configv1.Authentication{
+						Spec: configv1.AuthenticationSpec{
+							Type: configv1.AuthenticationTypeOIDC,
+							OIDCProviders: []configv1.OIDCProvider{
+								{
+									Name:        "corporate",
+									Issuer:      configv1.TokenIssuer{URL: "https://sso.example.com"},
+									OIDCClients: []configv1.OIDCClientConfig{{ComponentNamespace: "openshift-console", ComponentName: "console", ClientID: "corporate-id"}},
+								},
+							},
+						},
+					}, nil,
+				).OIDCPublicClient(true).ConfigYAML()
+			},
+			output: `apiVersion: console.openshift.io/v1
+kind: ConsoleConfig
+servingInfo:
+  bindAddress: https://[::]:8443
+  certFile: /var/serving-cert/tls.crt
+  keyFile: /var/serving-cert/tls.key
+clusterInfo: {}
+auth:
+  authType: oidc
+  oidcIssuer: https://sso.example.com
+  clientID: corporate-id
+  oidcPublicClient: true
+session:
+  cookieEncryptionKeyFile: /var/session-secret/sessionEncryptionKey
+  cookieAuthenticationKeyFile: /var/session-secret/sessionAuthenticationKey
+customization: {}
+providers: {}
+`,
+		},
+		{
+			name: "Config builder should render only the auth type without authentication",
+			input: func() ([]byte, error) {
+				b := &ConsoleServerCLIConfigBuilder{}
+				return b.AuthConfig(
+					&configv1.Authentication{Spec: configv1.AuthenticationSpec{Type: configv1.AuthenticationTypeNone}}, nil,
+				).InactivityTimeout(600).ConfigYAML()
+			},
+			output: `apiVersion: console.openshift.io/v1
+kind: ConsoleConfig
+servingInfo:
+  bindAddress: https://[::]:8443
+  certFile: /var/serving-cert/tls.crt
+  keyFile: /var/serving-cert/tls.key
+clusterInfo: {}
+auth:
+  authType: disabled
+session: {}
+customization: {}
+providers: {}
+`,
+		},
+		{
+			name: "Config builder should render the OIDC audiences and CLI client",
+			input: func() ([]byte, error) {
+				b := &ConsoleServerCLIConfigBuilder{}
+				return b.AuthConfig(
+					&configv1.Authentication{
+						Spec: configv1.AuthenticationSpec{
+							Type: configv1.AuthenticationTypeOIDC,
+							OIDCProviders: []configv1.OIDCProvider{
+								{
+									Name:   "corporate",
+									Issuer: configv1.TokenIssuer{URL: "https://sso.example.com", Audiences: []configv1.TokenAudience{"openshift"}},
+									OIDCClients: []configv1.OIDCClientConfig{
+										{ComponentNamespace: "openshift-console", ComponentName: "console", ClientID: "corporate-id"},
+										{ComponentNamespace: "openshift-console", ComponentName: "cli", ClientID: "corporate-cli"},
+									},
+								},
+							},
+						},
+					}, nil,
+				).ConfigYAML()
+			},
+			output: `apiVersion: console.openshift.io/v1
+kind: ConsoleConfig
+servingInfo:
+  bindAddress: https://[::]:8443
+  certFile: /var/serving-cert/tls.crt
+  keyFile: /var/serving-cert/tls.key
+clusterInfo: {}
+auth:
+  authType: oidc
+  oidcIssuer: https://sso.example.com
+  clientID: corporate-id
+  clientSecretFile: /var/oauth-config/clientSecret
+  oidcAudiences:
+  - openshift
+  oidcCLIClientID: corporate-cli
+session:
+  cookieEncryptionKeyFile: /var/session-secret/sessionEncryptionKey
+  cookieAuthenticationKeyFile: /var/session-secret/sessionAuthenticationKey
+customization: {}
+providers: {}
+`,
+		},
+		{
+			name: "Config builder should render the OIDC token validation leeway",
+			input: func() ([]byte, error) {
+				b := &ConsoleServerCLIConfigBuilder{}
+				return b.AuthConfig(
+					&configv1.Authentication{
+						Spec: configv1.AuthenticationSpec{
+							Type: configv1.AuthenticationTypeOIDC,
+							OIDCProviders: []configv1.OIDCProvider{
+								{
+									Name:        "corporate",
+									Issuer:      configv1.TokenIssuer{URL: "https://sso.example.com"},
+									OIDCClients: []configv1.OIDCClientConfig{{ComponentNamespace: "openshift-console", ComponentName: "console", ClientID: "corporate-id"}},
+								},
+							},
+						},
+					}, nil,
+				).OIDCTokenValidation(util.OIDCTokenValidationConfig{ClockSkew: 30 * time.Second, IDTokenLeeway: 2 * time.Minute}).ConfigYAML()
+			},
+			output: `apiVersion: console.openshift.io/v1
+kind: ConsoleConfig
+servingInfo:
+  bindAddress: https://[::]:8443
+  certFile: /var/serving-cert/tls.crt
+  keyFile: /var/serving-cert/tls.key
+clusterInfo: {}
+auth:
+  authType: oidc
+  oidcIssuer: https://sso.example.com
+  clientID: corporate-id
+  clientSecretFile: /var/oauth-config/clientSecret
+  oidcClockSkewSeconds: 30
+  oidcIDTokenLeewaySeconds: 120
+session:
+  cookieEncryptionKeyFile: /var/session-secret/sessionEncryptionKey
+  cookieAuthenticationKeyFile: /var/session-secret/sessionAuthenticationKey
+customization: {}
+providers: {}
+`,
+		},
+		{
+			name: "Config builder should render the OIDC issuer endpoint",
+			input: func() ([]byte, error) {
+				b := &ConsoleServerCLIConfigBuilder{}
+				return b.AuthConfig(
+					&configv1.Authentication{
+						Spec: configv1.AuthenticationSpec{
+							Type: configv1.AuthenticationTypeOIDC,
+							OIDCProviders: []configv1.OIDCProvider{
+								{
+									Name:        "corporate",
+									Issuer:      configv1.TokenIssuer{URL: "https://sso.example.com"},
+									OIDCClients: []configv1.OIDCClientConfig{{ComponentNamespace: "openshift-console", ComponentName: "console", ClientID: "corporate-id"}},
+								},
+							},
+						},
+					}, nil,
+				).OIDCIssuerEndpoints(map[string]string{"corporate": "https://sso.idp.svc:8443"}).ConfigYAML()
+			},
+			output: `apiVersion: console.openshift.io/v1
+kind: ConsoleConfig
+servingInfo:
+  bindAddress: https://[::]:8443
+  certFile: /var/serving-cert/tls.crt
+  keyFile: /var/serving-cert/tls.key
+clusterInfo: {}
+auth:
+  authType: oidc
+  oidcIssuer: https://sso.example.com
+  clientID: corporate-id
+  clientSecretFile: /var/oauth-config/clientSecret
+  oidcIssuerEndpoint: https://sso.idp.svc:8443
+session:
+  cookieEncryptionKeyFile: /var/session-secret/sessionEncryptionKey
+  cookieAuthenticationKeyFile: /var/session-secret/sessionAuthenticationKey
+customization: {}
+providers: {}
+`,
+		},
+		{
+			name: "Config builder should render the OIDC claim mappings and extra scopes",
+			input: func() ([]byte, error) {
+				b := &ConsoleServerCLIConfigBuilder{}
+				return b.AuthConfig(
+					&configv1.Authentication{
+						Spec: configv1.AuthenticationSpec{
+							Type: configv1.AuthenticationTypeOIDC,
+							OIDCProviders: []configv1.OIDCProvider{
+								{
+									Name:   "corporate",
+									Issuer: configv1.TokenIssuer{URL: "https://sso.example.com"},
+									ClaimMappings: configv1.TokenClaimMappings{
+										Username: configv1.UsernameClaimMapping{TokenClaimMapping: configv1.TokenClaimMapping{Claim: "preferred_username"}},
+										Groups:   configv1.PrefixedClaimMapping{TokenClaimMapping: configv1.TokenClaimMapping{Claim: "groups"}, Prefix: "sso:"},
+									},
+									OIDCClients: []configv1.OIDCClientConfig{{
+										ComponentNamespace: "openshift-console",
+										ComponentName:      "console",
+										ClientID:           "corporate-id",
+										ExtraScopes:        []string{"email", "groups"},
+									}},
+								},
+							},
+						},
+					}, nil,
+				).ConfigYAML()
+			},
+			output: `apiVersion: console.openshift.io/v1
+kind: ConsoleConfig
+servingInfo:
+  bindAddress: https://[::]:8443
+  certFile: /var/serving-cert/tls.crt
+  keyFile: /var/serving-cert/tls.key
+clusterInfo: {}
+auth:
+  authType: oidc
+  oidcIssuer: https://sso.example.com
+  oidcExtraScopes:
+  - email
+  - groups
+  clientID: corporate-id
+  clientSecretFile: /var/oauth-config/clientSecret
+  oidcClaimMappings:
+    usernameClaim: preferred_username
+    usernamePrefix: https://sso.example.com#
+    groupsClaim: groups
+    groupsPrefix: 'sso:'
+session:
+  cookieEncryptionKeyFile: /var/session-secret/sessionEncryptionKey
+  cookieAuthenticationKeyFile: /var/session-secret/sessionAuthenticationKey
+customization: {}
+providers: {}
+`,
+		},
+		{
+			name: "Config builder should render previous session keys after a rotation",
+			input: func() ([]byte, error) {
+				b := &ConsoleServerCLIConfigBuilder{}
+				return b.AuthConfig(
+					&configv1.Authentication{
+						Spec: configv1.AuthenticationSpec{
+							Type: configv1.AuthenticationTypeOIDC,
+							OIDCProviders: []configv1.OIDCProvider{
+								{
+									OIDCClients: []configv1.OIDCClientConfig{
+										{
+											ComponentNamespace: "openshift-console",
+											ComponentName:      "console",
+											ClientID:           "testing-id",
+										},
+									},
+								},
+							},
+						},
+					}, nil,
+				).SessionSecret(&corev1.Secret{
+					Data: map[string][]byte{
+						api.PreviousSessionEncryptionKey:     []byte("previous-encryption"),
+						api.PreviousSessionAuthenticationKey: []byte("previous-authentication"),
+					},
+				}).ConfigYAML()
+			},
+			output: `apiVersion: console.openshift.io/v1
+kind: ConsoleConfig
+servingInfo:
+  bindAddress: https://[::]:8443
+  certFile: /var/serving-cert/tls.crt
+  keyFile: /var/serving-cert/tls.key
+clusterInfo: {}
+auth:
+  authType: oidc
+  clientID: testing-id
+  clientSecretFile: /var/oauth-config/clientSecret
+session:
+  cookieEncryptionKeyFile: /var/session-secret/sessionEncryptionKey
+  cookieAuthenticationKeyFile: /var/session-secret/sessionAuthenticationKey
+  previousCookieEncryptionKeyFile: /var/session-secret/previousSessionEncryptionKey
+  previousCookieAuthenticationKeyFile: /var/session-secret/previousSessionAuthenticationKey
+customization: {}
+providers: {}
 `,
 		},
 		{
@@ -1404,3 +1738,57 @@ telemetry:
 		})
 	}
 }
+
+func TestGetOIDCClaimMappings(t *testing.T) {
+	provider := func(username configv1.UsernameClaimMapping) configv1.OIDCProvider {
+		return configv1.OIDCProvider{
+			Issuer:        configv1.TokenIssuer{URL: "https://sso.example.com"},
+			ClaimMappings: configv1.TokenClaimMappings{Username: username},
+		}
+	}
+	claim := func(name string) configv1.TokenClaimMapping {
+		return configv1.TokenClaimMapping{Claim: name}
+	}
+	tests := []struct {
+		name     string
+		provider configv1.OIDCProvider
+		want     *OIDCClaimMappings
+	}{
+		{
+			name:     "Test no claim mapped",
+			provider: provider(configv1.UsernameClaimMapping{}),
+			want:     nil,
+		},
+		{
+			name:     "Test email claim not prefixed by default",
+			provider: provider(configv1.UsernameClaimMapping{TokenClaimMapping: claim("email")}),
+			want:     &OIDCClaimMappings{UsernameClaim: "email"},
+		},
+		{
+			name:     "Test other claims prefixed with the issuer by default",
+			provider: provider(configv1.UsernameClaimMapping{TokenClaimMapping: claim("sub")}),
+			want:     &OIDCClaimMappings{UsernameClaim: "sub", UsernamePrefix: "https://sso.example.com#"},
+		},
+		{
+			name:     "Test prefix disabled",
+			provider: provider(configv1.UsernameClaimMapping{TokenClaimMapping: claim("sub"), PrefixPolicy: configv1.NoPrefix}),
+			want:     &OIDCClaimMappings{UsernameClaim: "sub"},
+		},
+		{
+			name: "Test custom prefix",
+			provider: provider(configv1.UsernameClaimMapping{
+				TokenClaimMapping: claim("sub"),
+				PrefixPolicy:      configv1.Prefix,
+				Prefix:            &configv1.UsernamePrefix{PrefixString: "sso:"},
+			}),
+			want: &OIDCClaimMappings{UsernameClaim: "sub", UsernamePrefix: "sso:"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(GetOIDCClaimMappings(tt.provider), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -1,8 +1,15 @@
 package consoleserver
 
 import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
 	yaml2 "github.com/ghodss/yaml"
+	"github.com/google/go-cmp/cmp"
 	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 type ConsoleYAMLMerger struct{}
@@ -22,3 +29,176 @@ func (b *ConsoleYAMLMerger) combine(configYAMLs ...[]byte) (mergedConfig []byte,
 	}
 	return mergedConfig, nil
 }
+
+// protectedOverrideFields are rendered by the operator to match the deployment, eg. the paths
+// secrets are mounted at. unsupportedConfigOverrides may not change them.
+var protectedOverrideFields = [][]string{
+	{"apiVersion"},
+	{"kind"},
+	{"servingInfo", "bindAddress"},
+	{"servingInfo", "certFile"},
+	{"servingInfo", "keyFile"},
+	{"auth", "clientSecretFile"},
+	{"auth", "oidcPublicClient"},
+	{"auth", "oauthEndpointCAFile"},
+	{"session", "cookieEncryptionKeyFile"},
+	{"session", "cookieAuthenticationKeyFile"},
+	{"session", "previousCookieEncryptionKeyFile"},
+	{"session", "previousCookieAuthenticationKeyFile"},
+	{"customization", "customLogoFile"},
+	{"accessLog", "file"},
+}
+
+// overrideListMergeKeys identifies the elements of lists that are merged one by one,
+// any other list in the overrides replaces the rendered one.
+var overrideListMergeKeys = map[string]string{
+	"customization.perspectives": "id",
+	"proxy.services":             "consoleAPIPath",
+}
+
+// OverridesResult describes what unsupportedConfigOverrides did to the rendered config.
+type OverridesResult struct {
+	// ProtectedFields lists the operator owned fields the overrides tried to change, they are ignored
+	ProtectedFields []string
+	// Diff between the config rendered by the operator and the config with overrides applied
+	Diff string
+}
+
+// MergeOverrides applies unsupportedConfigOverrides on top of the config rendered by the operator
+// with strategic merge semantics:
+//   - maps are merged key by key, at any depth
+//   - a null value removes the key from the rendered config
+//   - lists with a merge key (customization.perspectives by id, proxy.services by consoleAPIPath)
+//     are merged element by element, other lists are replaced
+//   - changes to protected fields, or replacing the maps holding them, are dropped and reported
+//     in the result
+func (b *ConsoleYAMLMerger) MergeOverrides(configYAML []byte, overrides []byte) ([]byte, *OverridesResult, error) {
+	result := &OverridesResult{}
+	if len(overrides) == 0 {
+		return configYAML, result, nil
+	}
+
+	rendered, err := yamlToMap(configYAML)
+	if err != nil {
+		return nil, nil, err
+	}
+	overridesMap, err := yamlToMap(overrides)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse unsupportedConfigOverrides: %w", err)
+	}
+
+	for _, fields := range protectedOverrideFields {
+		// a null or a scalar in place of a map holding a protected field replaces the whole map
+		if ancestor := nonMapAncestor(overridesMap, fields); ancestor != nil {
+			result.ProtectedFields = append(result.ProtectedFields, strings.Join(ancestor, "."))
+			unstructured.RemoveNestedField(overridesMap, ancestor...)
+			continue
+		}
+		overridden, found, _ := unstructured.NestedFieldNoCopy(overridesMap, fields...)
+		if !found {
+			continue
+		}
+		current, _, _ := unstructured.NestedFieldNoCopy(rendered, fields...)
+		if !reflect.DeepEqual(overridden, current) {
+			result.ProtectedFields = append(result.ProtectedFields, strings.Join(fields, "."))
+		}
+		unstructured.RemoveNestedField(overridesMap, fields...)
+	}
+
+	merged, err := yamlToMap(configYAML)
+	if err != nil {
+		return nil, nil, err
+	}
+	strategicMerge(merged, overridesMap, "")
+	result.Diff = cmp.Diff(rendered, merged)
+
+	mergedJSON, err := json.Marshal(merged)
+	if err != nil {
+		return nil, nil, err
+	}
+	mergedYAML, err := yaml2.JSONToYAML(mergedJSON)
+	if err != nil {
+		return nil, nil, err
+	}
+	return mergedYAML, result, nil
+}
+
+// nonMapAncestor returns the first ancestor of the fields path the overrides set to something else
+// than a map, nil if there is none.
+func nonMapAncestor(overrides map[string]interface{}, fields []string) []string {
+	for i := 1; i < len(fields); i++ {
+		value, found, _ := unstructured.NestedFieldNoCopy(overrides, fields[:i]...)
+		if !found {
+			return nil
+		}
+		if _, ok := value.(map[string]interface{}); !ok {
+			return fields[:i]
+		}
+	}
+	return nil
+}
+
+func yamlToMap(configYAML []byte) (map[string]interface{}, error) {
+	config := map[string]interface{}{}
+	if err := yaml2.Unmarshal(configYAML, &config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func strategicMerge(current, patch map[string]interface{}, path string) {
+	for key, patchValue := range patch {
+		fullKey := key
+		if len(path) > 0 {
+			fullKey = path + "." + key
+		}
+
+		switch patchValue := patchValue.(type) {
+		case nil:
+			delete(current, key)
+		case map[string]interface{}:
+			currentMap, ok := current[key].(map[string]interface{})
+			if !ok {
+				currentMap = map[string]interface{}{}
+				current[key] = currentMap
+			}
+			strategicMerge(currentMap, patchValue, fullKey)
+		case []interface{}:
+			currentList, ok := current[key].([]interface{})
+			mergeKey, hasMergeKey := overrideListMergeKeys[fullKey]
+			if !ok || !hasMergeKey {
+				current[key] = patchValue
+				continue
+			}
+			current[key] = mergeList(currentList, patchValue, mergeKey)
+		default:
+			current[key] = patchValue
+		}
+	}
+}
+
+// mergeList merges the elements of patch into the elements of current with the same mergeKey
+// value. Elements without a match are appended.
+func mergeList(current, patch []interface{}, mergeKey string) []interface{} {
+	merged := append([]interface{}{}, current...)
+	for _, patchElement := range patch {
+		patchMap, ok := patchElement.(map[string]interface{})
+		if !ok {
+			merged = append(merged, patchElement)
+			continue
+		}
+		matched := false
+		for _, element := range merged {
+			elementMap, ok := element.(map[string]interface{})
+			if ok && patchMap[mergeKey] != nil && reflect.DeepEqual(elementMap[mergeKey], patchMap[mergeKey]) {
+				strategicMerge(elementMap, patchMap, "")
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			merged = append(merged, patchMap)
+		}
+	}
+	return merged
+}
@@ -71,3 +71,151 @@ session: {}
 		})
 	}
 }
+
+func TestMergeOverrides(t *testing.T) {
+	rendered := `apiVersion: console.openshift.io/v1
+kind: ConsoleConfig
+customization:
+  branding: ocp
+  perspectives:
+  - id: admin
+    visibility:
+      state: Enabled
+  - id: dev
+    visibility:
+      state: Enabled
+proxy:
+  services:
+  - consoleAPIPath: /api/proxy/plugin/foo/
+    endpoint: https://foo.example.com
+servingInfo:
+  bindAddress: https://[::]:8443
+  certFile: /var/serving-cert/tls.crt
+  keyFile: /var/serving-cert/tls.key
+`
+	// the rendered config with its keys sorted, as the merged config is written
+	unchanged := `apiVersion: console.openshift.io/v1
+customization:
+  branding: ocp
+  perspectives:
+  - id: admin
+    visibility:
+      state: Enabled
+  - id: dev
+    visibility:
+      state: Enabled
+kind: ConsoleConfig
+proxy:
+  services:
+  - consoleAPIPath: /api/proxy/plugin/foo/
+    endpoint: https://foo.example.com
+servingInfo:
+  bindAddress: https://[::]:8443
+  certFile: /var/serving-cert/tls.crt
+  keyFile: /var/serving-cert/tls.key
+`
+	tests := []struct {
+		name          string
+		overrides     string
+		output        string
+		wantProtected []string
+		wantDiff      bool
+	}{
+		{
+			name:      "Test without overrides",
+			overrides: "",
+			output:    rendered,
+		},
+		{
+			name:      "Test nested keys are merged and null removes a key",
+			overrides: `{"customization":{"developerCatalog":{"categories":null},"branding":null},"providers":{"statuspageID":"id"}}`,
+			output: `apiVersion: console.openshift.io/v1
+customization:
+  developerCatalog: {}
+  perspectives:
+  - id: admin
+    visibility:
+      state: Enabled
+  - id: dev
+    visibility:
+      state: Enabled
+kind: ConsoleConfig
+providers:
+  statuspageID: id
+proxy:
+  services:
+  - consoleAPIPath: /api/proxy/plugin/foo/
+    endpoint: https://foo.example.com
+servingInfo:
+  bindAddress: https://[::]:8443
+  certFile: /var/serving-cert/tls.crt
+  keyFile: /var/serving-cert/tls.key
+`,
+			wantDiff: true,
+		},
+		{
+			name:      "Test lists are merged by key",
+			overrides: `{"customization":{"perspectives":[{"id":"dev","visibility":{"state":"Disabled"}},{"id":"acme"}]},"proxy":{"services":[{"consoleAPIPath":"/api/proxy/plugin/foo/","authorize":true}]}}`,
+			output: `apiVersion: console.openshift.io/v1
+customization:
+  branding: ocp
+  perspectives:
+  - id: admin
+    visibility:
+      state: Enabled
+  - id: dev
+    visibility:
+      state: Disabled
+  - id: acme
+kind: ConsoleConfig
+proxy:
+  services:
+  - authorize: true
+    consoleAPIPath: /api/proxy/plugin/foo/
+    endpoint: https://foo.example.com
+servingInfo:
+  bindAddress: https://[::]:8443
+  certFile: /var/serving-cert/tls.crt
+  keyFile: /var/serving-cert/tls.key
+`,
+			wantDiff: true,
+		},
+		{
+			name:          "Test protected fields are ignored",
+			overrides:     `{"kind":"ConsoleConfig","servingInfo":{"bindAddress":"http://[::]:8080","certFile":null}}`,
+			output:        unchanged,
+			wantProtected: []string{"servingInfo.bindAddress", "servingInfo.certFile"},
+		},
+		{
+			name:          "Test null maps holding protected fields are ignored",
+			overrides:     `{"auth":null,"servingInfo":null}`,
+			output:        unchanged,
+			wantProtected: []string{"servingInfo", "auth"},
+		},
+		{
+			name:          "Test scalars in place of maps holding protected fields are ignored",
+			overrides:     `{"session":"x"}`,
+			output:        unchanged,
+			wantProtected: []string{"session"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merger := ConsoleYAMLMerger{}
+			output, result, err := merger.MergeOverrides([]byte(rendered), []byte(tt.overrides))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.output, string(output)); len(diff) > 0 {
+				t.Error(diff)
+			}
+			if diff := cmp.Diff(tt.wantProtected, result.ProtectedFields); len(diff) > 0 {
+				t.Error(diff)
+			}
+			if gotDiff := len(result.Diff) > 0; gotDiff != tt.wantDiff {
+				t.Errorf("got diff %q, want diff %v", result.Diff, tt.wantDiff)
+			}
+		})
+	}
+}
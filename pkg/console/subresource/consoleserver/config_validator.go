@@ -0,0 +1,293 @@
+package consoleserver
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/openshift/console-operator/pkg/console/subresource/util"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var (
+	// values accepted by the console for the corresponding flags, anything else makes it exit on start
+	validAuthTypes       = sets.NewString("", "openshift", "oidc", "disabled")
+	validBrandings       = sets.NewString("", "okd", "openshift", "ocp", "online", "dedicated", "azure", "rosa")
+	validAccessLogFormat = sets.NewString("", "common", "json")
+)
+
+// ValidateConfigYAML checks the fully merged console-config.yaml, including unsupportedConfigOverrides,
+// for values the console server would refuse to start with. Each problem is reported with its path,
+// eg. servingInfo.bindAddress. Fields the schema does not know, typically misspelled overrides, are
+// refused as well, the console would silently ignore them.
+func ValidateConfigYAML(configYAML []byte) error {
+	config := Config{}
+	if err := yaml.UnmarshalStrict(configYAML, &config); err != nil {
+		return fmt.Errorf("console-config.yaml does not match the console config schema: %w", err)
+	}
+
+	errs := field.ErrorList{}
+	if config.Kind != "ConsoleConfig" {
+		errs = append(errs, field.NotSupported(field.NewPath("kind"), config.Kind, []string{"ConsoleConfig"}))
+	}
+	if config.APIVersion != "console.openshift.io/v1" {
+		errs = append(errs, field.NotSupported(field.NewPath("apiVersion"), config.APIVersion, []string{"console.openshift.io/v1"}))
+	}
+	errs = append(errs, validateServingInfo(config.ServingInfo, field.NewPath("servingInfo"))...)
+	errs = append(errs, validateClusterInfo(config.ClusterInfo, field.NewPath("clusterInfo"))...)
+	errs = append(errs, validateAuth(config.Auth, field.NewPath("auth"))...)
+	if branding := config.Customization.Branding; !validBrandings.Has(branding) {
+		errs = append(errs, field.NotSupported(field.NewPath("customization", "branding"), branding, validBrandings.List()[1:]))
+	}
+	for name, endpoint := range config.Plugins {
+		errs = append(errs, validateURL(endpoint, field.NewPath("plugins").Key(name))...)
+	}
+	errs = append(errs, validateAccessLog(config.AccessLog, field.NewPath("accessLog"))...)
+	errs = append(errs, validateRateLimit(config.RateLimit, field.NewPath("rateLimit"))...)
+	errs = append(errs, validateHTTPServer(config.HTTPServer, field.NewPath("httpServer"))...)
+	errs = append(errs, validateAPIClient(config.APIClient, field.NewPath("apiClient"))...)
+	errs = append(errs, validateStatusFeed(config.Providers.StatusFeed, field.NewPath("providers", "statusFeed"))...)
+	errs = append(errs, validateClusterProxy(config.ClusterProxy, field.NewPath("clusterProxy"))...)
+	errs = append(errs, validateMulticluster(config.Multicluster, field.NewPath("multicluster"))...)
+	errs = append(errs, validateManagedClusters(config.ManagedClusters, field.NewPath("managedClusters"))...)
+	errs = append(errs, validateContentSecurityPolicy(config.ContentSecurityPolicy, field.NewPath("contentSecurityPolicy"))...)
+	// several fields are maps, keep the message stable between syncs
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errs.ToAggregate()
+}
+
+func validateServingInfo(servingInfo ServingInfo, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if len(servingInfo.BindAddress) == 0 {
+		errs = append(errs, field.Required(fldPath.Child("bindAddress"), ""))
+	} else if bindAddress, err := url.Parse(servingInfo.BindAddress); err != nil || (bindAddress.Scheme != "http" && bindAddress.Scheme != "https") {
+		errs = append(errs, field.Invalid(fldPath.Child("bindAddress"), servingInfo.BindAddress, "must be an http:// or https:// address"))
+	} else if bindAddress.Scheme == "https" && (len(servingInfo.CertFile) == 0 || len(servingInfo.KeyFile) == 0) {
+		errs = append(errs, field.Required(fldPath.Child("certFile"), "certFile and keyFile are required when serving https"))
+	}
+
+	// defined in HTTPServingInfo but not supported by the console
+	if len(servingInfo.BindNetwork) > 0 {
+		errs = append(errs, field.Forbidden(fldPath.Child("bindNetwork"), "not supported by the console"))
+	}
+	if len(servingInfo.ClientCA) > 0 {
+		errs = append(errs, field.Forbidden(fldPath.Child("clientCA"), "not supported by the console"))
+	}
+	if len(servingInfo.NamedCertificates) > 0 {
+		errs = append(errs, field.Forbidden(fldPath.Child("namedCertificates"), "not supported by the console"))
+	}
+	if servingInfo.MaxRequestsInFlight != 0 {
+		errs = append(errs, field.Forbidden(fldPath.Child("maxRequestsInFlight"), "not supported by the console"))
+	}
+	if servingInfo.RequestTimeoutSeconds != 0 {
+		errs = append(errs, field.Forbidden(fldPath.Child("requestTimeoutSeconds"), "not supported by the console"))
+	}
+	return errs
+}
+
+func validateClusterInfo(clusterInfo ClusterInfo, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	errs = append(errs, validateURL(clusterInfo.ConsoleBaseAddress, fldPath.Child("consoleBaseAddress"))...)
+	errs = append(errs, validateURL(clusterInfo.MasterPublicURL, fldPath.Child("masterPublicURL"))...)
+	return errs
+}
+
+func validateAuth(auth Auth, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if !validAuthTypes.Has(auth.AuthType) {
+		errs = append(errs, field.NotSupported(fldPath.Child("authType"), auth.AuthType, validAuthTypes.List()[1:]))
+	}
+	if auth.AuthType == "oidc" {
+		if len(auth.OIDCIssuer) == 0 {
+			errs = append(errs, field.Required(fldPath.Child("oidcIssuer"), "required for oidc authentication"))
+		}
+		if len(auth.ClientID) == 0 {
+			errs = append(errs, field.Required(fldPath.Child("clientID"), "required for oidc authentication"))
+		}
+		leeways := []struct {
+			name  string
+			value int
+		}{
+			{"oidcClockSkewSeconds", auth.OIDCClockSkewSeconds},
+			{"oidcIDTokenLeewaySeconds", auth.OIDCIDTokenLeewaySeconds},
+		}
+		for _, leeway := range leeways {
+			if leeway.value < 0 {
+				errs = append(errs, field.Invalid(fldPath.Child(leeway.name), leeway.value, "must not be negative"))
+			}
+		}
+	}
+	errs = append(errs, validateURL(auth.OIDCIssuer, fldPath.Child("oidcIssuer"))...)
+	errs = append(errs, validateURL(auth.LogoutRedirect, fldPath.Child("logoutRedirect"))...)
+	if auth.InactivityTimeoutSeconds < 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("inactivityTimeoutSeconds"), auth.InactivityTimeoutSeconds, "must not be negative"))
+	}
+	for group, timeout := range auth.GroupInactivityTimeoutSeconds {
+		if timeout <= 0 {
+			errs = append(errs, field.Invalid(fldPath.Child("groupInactivityTimeoutSeconds").Key(group), timeout, "must be positive"))
+		}
+	}
+	return errs
+}
+
+func validateAccessLog(accessLog AccessLog, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if !validAccessLogFormat.Has(accessLog.Format) {
+		errs = append(errs, field.NotSupported(fldPath.Child("format"), accessLog.Format, validAccessLogFormat.List()[1:]))
+	}
+	if accessLog.SamplingRatio < 0 || accessLog.SamplingRatio > 1 {
+		errs = append(errs, field.Invalid(fldPath.Child("samplingRatio"), accessLog.SamplingRatio, "must be between 0 and 1"))
+	}
+	return errs
+}
+
+func validateRateLimit(rateLimit RateLimit, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	limits := []struct {
+		name  string
+		value int
+	}{
+		{"loginRequestsPerMinute", rateLimit.LoginRequestsPerMinute},
+		{"loginLockoutThreshold", rateLimit.LoginLockoutThreshold},
+		{"loginLockoutSeconds", rateLimit.LoginLockoutSeconds},
+		{"apiProxyRequestsPerSecond", rateLimit.APIProxyRequestsPerSecond},
+	}
+	for _, limit := range limits {
+		if limit.value < 0 {
+			errs = append(errs, field.Invalid(fldPath.Child(limit.name), limit.value, "must not be negative"))
+		}
+	}
+	return errs
+}
+
+func validateHTTPServer(httpServer HTTPServer, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	timeouts := []struct {
+		name  string
+		value int
+	}{
+		{"readTimeoutSeconds", httpServer.ReadTimeoutSeconds},
+		{"writeTimeoutSeconds", httpServer.WriteTimeoutSeconds},
+		{"idleTimeoutSeconds", httpServer.IdleTimeoutSeconds},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value < 0 {
+			errs = append(errs, field.Invalid(fldPath.Child(timeout.name), timeout.value, "must not be negative"))
+		}
+	}
+	if httpServer.MaxHeaderBytes != 0 && (httpServer.MaxHeaderBytes < util.ServerMinMaxHeaderBytes || httpServer.MaxHeaderBytes > util.ServerMaxMaxHeaderBytes) {
+		errs = append(errs, field.Invalid(fldPath.Child("maxHeaderBytes"), httpServer.MaxHeaderBytes, fmt.Sprintf("must be between %d and %d", util.ServerMinMaxHeaderBytes, util.ServerMaxMaxHeaderBytes)))
+	}
+	return errs
+}
+
+func validateAPIClient(apiClient APIClient, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if apiClient.QPS < 0 || apiClient.QPS > util.APIClientMaxQPS {
+		errs = append(errs, field.Invalid(fldPath.Child("qps"), apiClient.QPS, fmt.Sprintf("must be between 0 and %d", util.APIClientMaxQPS)))
+	}
+	if apiClient.Burst < 0 || apiClient.Burst > util.APIClientMaxBurst {
+		errs = append(errs, field.Invalid(fldPath.Child("burst"), apiClient.Burst, fmt.Sprintf("must be between 0 and %d", util.APIClientMaxBurst)))
+	} else if apiClient.Burst != 0 && apiClient.Burst < apiClient.QPS {
+		errs = append(errs, field.Invalid(fldPath.Child("burst"), apiClient.Burst, "must not be lower than qps"))
+	}
+	if apiClient.TimeoutSeconds < 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("timeoutSeconds"), apiClient.TimeoutSeconds, "must not be negative"))
+	}
+	return errs
+}
+
+func validateStatusFeed(statusFeed *StatusFeed, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if statusFeed == nil {
+		return errs
+	}
+	if !util.StatusProviderTypes.Has(statusFeed.Type) {
+		errs = append(errs, field.NotSupported(fldPath.Child("type"), statusFeed.Type, util.StatusProviderTypes.List()))
+	}
+	if feedURL, err := url.Parse(statusFeed.URL); err != nil || feedURL.Scheme != "https" || len(feedURL.Host) == 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("url"), statusFeed.URL, "must be an https URL"))
+	}
+	if statusFeed.PollIntervalSeconds < 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("pollIntervalSeconds"), statusFeed.PollIntervalSeconds, "must not be negative"))
+	}
+	return errs
+}
+
+func validateClusterProxy(clusterProxy ClusterProxy, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	names := sets.NewString()
+	for i, cluster := range clusterProxy.Clusters {
+		clusterPath := fldPath.Child("clusters").Index(i)
+		if len(cluster.Name) == 0 {
+			errs = append(errs, field.Required(clusterPath.Child("name"), ""))
+		} else if names.Has(cluster.Name) {
+			errs = append(errs, field.Duplicate(clusterPath.Child("name"), cluster.Name))
+		}
+		names.Insert(cluster.Name)
+		if endpoint, err := url.Parse(cluster.Endpoint); err != nil || endpoint.Scheme != "wss" || len(endpoint.Host) == 0 {
+			errs = append(errs, field.Invalid(clusterPath.Child("endpoint"), cluster.Endpoint, "must be a wss:// URL"))
+		}
+		if len(cluster.TokenAudience) == 0 {
+			errs = append(errs, field.Required(clusterPath.Child("tokenAudience"), ""))
+		}
+	}
+	return errs
+}
+
+func validateMulticluster(multicluster Multicluster, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if multicluster.ClusterSwitcher && multicluster.Hub != "ACM" && multicluster.Hub != "MCE" {
+		errs = append(errs, field.NotSupported(fldPath.Child("hub"), multicluster.Hub, []string{"ACM", "MCE"}))
+	}
+	return errs
+}
+
+func validateManagedClusters(clusters []ManagedCluster, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	names := sets.NewString()
+	for i, cluster := range clusters {
+		clusterPath := fldPath.Index(i)
+		if len(cluster.Name) == 0 {
+			errs = append(errs, field.Required(clusterPath.Child("name"), ""))
+		} else if names.Has(cluster.Name) {
+			errs = append(errs, field.Duplicate(clusterPath.Child("name"), cluster.Name))
+		}
+		names.Insert(cluster.Name)
+		if apiServer, err := url.Parse(cluster.APIServer.URL); err != nil || apiServer.Scheme != "https" || len(apiServer.Host) == 0 {
+			errs = append(errs, field.Invalid(clusterPath.Child("apiServer", "url"), cluster.APIServer.URL, "must be an https:// URL"))
+		}
+	}
+	return errs
+}
+
+func validateContentSecurityPolicy(policy map[string][]string, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	for directive, sources := range policy {
+		if !util.ContentSecurityPolicyDirectives.Has(directive) {
+			errs = append(errs, field.NotSupported(fldPath.Key(directive), directive, util.ContentSecurityPolicyDirectives.List()))
+			continue
+		}
+		for i, source := range sources {
+			if err := util.ValidateContentSecurityPolicySource(source); err != nil {
+				errs = append(errs, field.Invalid(fldPath.Key(directive).Index(i), source, err.Error()))
+			}
+		}
+	}
+	return errs
+}
+
+// validateURL accepts an empty value, anything else must be an absolute URL
+func validateURL(value string, fldPath *field.Path) field.ErrorList {
+	if len(value) == 0 {
+		return nil
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || !parsed.IsAbs() || len(parsed.Host) == 0 {
+		return field.ErrorList{field.Invalid(fldPath, value, "must be an absolute URL")}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package consoleserver
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestValidateConfigYAML(t *testing.T) {
+	validConfig := func() string {
+		config, err := (&ConsoleServerCLIConfigBuilder{}).
+			Host("console-openshift-console.apps.example.com").
+			APIServerURL("https://api.example.com:6443").
+			ConfigYAML()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(config)
+	}
+
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name:    "Test config rendered by the operator is valid",
+			config:  validConfig(),
+			wantErr: "",
+		},
+		{
+			name: "Test value of the wrong type",
+			config: `kind: ConsoleConfig
+apiVersion: console.openshift.io/v1
+plugins: [foo]
+`,
+			wantErr: "console-config.yaml does not match the console config schema: yaml: unmarshal errors:\n  line 3: cannot unmarshal !!seq into map[string]string",
+		},
+		{
+			name: "Test unknown field",
+			config: `kind: ConsoleConfig
+apiVersion: console.openshift.io/v1
+servingInfo:
+  bindAdress: http://[::]:8080
+`,
+			wantErr: "console-config.yaml does not match the console config schema: yaml: unmarshal errors:\n  line 4: field bindAdress not found in type consoleserver.ServingInfo",
+		},
+		{
+			name: "Test invalid plugin endpoints",
+			config: `kind: ConsoleConfig
+apiVersion: console.openshift.io/v1
+servingInfo:
+  bindAddress: http://[::]:8080
+plugins:
+  zeta: zeta-plugin
+  alpha: alpha-plugin
+`,
+			wantErr: `[plugins[alpha]: Invalid value: "alpha-plugin": must be an absolute URL, plugins[zeta]: Invalid value: "zeta-plugin": must be an absolute URL]`,
+		},
+		{
+			name: "Test unsupported serving info fields",
+			config: `kind: ConsoleConfig
+apiVersion: console.openshift.io/v1
+servingInfo:
+  bindAddress: https://[::]:8443
+  certFile: /var/serving-cert/tls.crt
+  keyFile: /var/serving-cert/tls.key
+  clientCA: /var/client-ca/ca.crt
+`,
+			wantErr: "servingInfo.clientCA: Forbidden: not supported by the console",
+		},
+		{
+			name: "Test https without certificate",
+			config: `kind: ConsoleConfig
+apiVersion: console.openshift.io/v1
+servingInfo:
+  bindAddress: https://[::]:8443
+`,
+			wantErr: "servingInfo.certFile: Required value: certFile and keyFile are required when serving https",
+		},
+		{
+			name: "Test invalid values",
+			config: `kind: ConsoleConfig
+apiVersion: console.openshift.io/v1
+servingInfo:
+  bindAddress: http://[::]:8080
+auth:
+  authType: ldap
+  logoutRedirect: /logout
+customization:
+  branding: acme
+`,
+			wantErr: `[auth.authType: Unsupported value: "ldap": supported values: "disabled", "oidc", "openshift", auth.logoutRedirect: Invalid value: "/logout": must be an absolute URL, customization.branding: Unsupported value: "acme": supported values: "azure", "dedicated", "ocp", "okd", "online", "openshift", "rosa"]`,
+		},
+		{
+			name: "Test API client burst lower than qps",
+			config: `kind: ConsoleConfig
+apiVersion: console.openshift.io/v1
+servingInfo:
+  bindAddress: http://[::]:8080
+apiClient:
+  qps: 100
+  burst: 10
+`,
+			wantErr: `apiClient.burst: Invalid value: 10: must not be lower than qps`,
+		},
+		{
+			name: "Test invalid status feed",
+			config: `kind: ConsoleConfig
+apiVersion: console.openshift.io/v1
+servingInfo:
+  bindAddress: http://[::]:8080
+providers:
+  statusFeed:
+    type: rss
+    url: http://status.example.com/feed.xml
+`,
+			wantErr: `[providers.statusFeed.type: Unsupported value: "rss": supported values: "json", "statuspage", providers.statusFeed.url: Invalid value: "http://status.example.com/feed.xml": must be an https URL]`,
+		},
+		{
+			name: "Test invalid cluster-proxy clusters",
+			config: `kind: ConsoleConfig
+apiVersion: console.openshift.io/v1
+servingInfo:
+  bindAddress: http://[::]:8080
+clusterProxy:
+  clusters:
+  - name: east
+    endpoint: wss://proxy.east.example.com/cluster-proxy
+    tokenAudience: east
+  - name: east
+    endpoint: https://proxy.east.example.com/cluster-proxy
+`,
+			wantErr: `[clusterProxy.clusters[1].endpoint: Invalid value: "https://proxy.east.example.com/cluster-proxy": must be a wss:// URL, clusterProxy.clusters[1].name: Duplicate value: "east", clusterProxy.clusters[1].tokenAudience: Required value]`,
+		},
+		{
+			name: "Test invalid multicluster config",
+			config: `kind: ConsoleConfig
+apiVersion: console.openshift.io/v1
+servingInfo:
+  bindAddress: http://[::]:8080
+multicluster:
+  clusterSwitcher: true
+  hub: OCM
+managedClusters:
+- name: east
+  apiServer:
+    url: https://api.east.example.com:6443
+- name: east
+  apiServer:
+    url: api.east.example.com:6443
+`,
+			wantErr: `[managedClusters[1].apiServer.url: Invalid value: "api.east.example.com:6443": must be an https:// URL, managedClusters[1].name: Duplicate value: "east", multicluster.hub: Unsupported value: "OCM": supported values: "ACM", "MCE"]`,
+		},
+		{
+			name: "Test invalid content security policy",
+			config: `kind: ConsoleConfig
+apiVersion: console.openshift.io/v1
+servingInfo:
+  bindAddress: http://[::]:8080
+contentSecurityPolicy:
+  script-src:
+  - https://analytics.example.com
+  - '*'
+`,
+			wantErr: `contentSecurityPolicy[script-src][1]: Invalid value: "*": source "*" allows any host`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateConfigYAML([]byte(tt.config))
+			gotErr := ""
+			if err != nil {
+				gotErr = err.Error()
+			}
+			if diff := deep.Equal(gotErr, tt.wantErr); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
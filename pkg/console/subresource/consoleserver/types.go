@@ -30,6 +30,103 @@ type Config struct {
 	I18nNamespaces []string          `yaml:"i18nNamespaces,omitempty"`
 	Proxy          Proxy             `yaml:"proxy,omitempty"`
 	Telemetry      map[string]string `yaml:"telemetry,omitempty"`
+	AccessLog      AccessLog         `yaml:"accessLog,omitempty"`
+	RateLimit      RateLimit         `yaml:"rateLimit,omitempty"`
+	HTTPServer     HTTPServer        `yaml:"httpServer,omitempty"`
+	APIClient      APIClient         `yaml:"apiClient,omitempty"`
+	ClusterProxy   ClusterProxy      `yaml:"clusterProxy,omitempty"`
+	// ContentSecurityPolicy holds sources added to the console CSP, by directive
+	ContentSecurityPolicy map[string][]string `yaml:"contentSecurityPolicy,omitempty"`
+	// Multicluster is set on hubs, ManagedClusters lists the clusters of the fleet they manage
+	Multicluster    Multicluster     `yaml:"multicluster,omitempty"`
+	ManagedClusters []ManagedCluster `yaml:"managedClusters,omitempty"`
+}
+
+// HTTPServer holds tuning of the console backend HTTP server, zero values keep the console defaults.
+type HTTPServer struct {
+	ReadTimeoutSeconds  int   `yaml:"readTimeoutSeconds,omitempty"`
+	WriteTimeoutSeconds int   `yaml:"writeTimeoutSeconds,omitempty"`
+	IdleTimeoutSeconds  int   `yaml:"idleTimeoutSeconds,omitempty"`
+	MaxHeaderBytes      int   `yaml:"maxHeaderBytes,omitempty"`
+	Compression         *bool `yaml:"compression,omitempty"`
+}
+
+// APIClient holds client side rate limiting of the console backend requests to the Kubernetes API,
+// zero values keep the console defaults.
+type APIClient struct {
+	QPS            int `yaml:"qps,omitempty"`
+	Burst          int `yaml:"burst,omitempty"`
+	TimeoutSeconds int `yaml:"timeoutSeconds,omitempty"`
+}
+
+// ClusterProxy holds the cluster-proxy websocket endpoints of the managed clusters shown in
+// multi-cluster views.
+type ClusterProxy struct {
+	Clusters []ClusterProxyCluster `yaml:"clusters,omitempty"`
+	// UnhealthyClusters are left out of Clusters until their endpoint answers again
+	UnhealthyClusters []UnhealthyClusterProxyCluster `yaml:"unhealthyClusters,omitempty"`
+}
+
+type ClusterProxyCluster struct {
+	Name          string `yaml:"name"`
+	Endpoint      string `yaml:"endpoint"`
+	TokenAudience string `yaml:"tokenAudience"`
+	CAFile        string `yaml:"caFile,omitempty"`
+}
+
+type UnhealthyClusterProxyCluster struct {
+	Name    string `yaml:"name"`
+	Message string `yaml:"message,omitempty"`
+}
+
+// Multicluster enables the cluster switcher of a console running on a hub.
+type Multicluster struct {
+	ClusterSwitcher bool `yaml:"clusterSwitcher,omitempty"`
+	// Hub is ACM or MCE
+	Hub string `yaml:"hub,omitempty"`
+}
+
+// ManagedCluster is a cluster managed by the hub, the console talks to its API server directly.
+type ManagedCluster struct {
+	Name        string                  `yaml:"name"`
+	DisplayName string                  `yaml:"displayName,omitempty"`
+	APIServer   ManagedClusterAPIServer `yaml:"apiServer"`
+	// OAuth is the client the console authenticates users with on the cluster, once provisioned
+	OAuth *ManagedClusterOAuth `yaml:"oauth,omitempty"`
+}
+
+type ManagedClusterAPIServer struct {
+	URL    string `yaml:"url"`
+	CAFile string `yaml:"caFile,omitempty"`
+}
+
+type ManagedClusterOAuth struct {
+	ClientID         string `yaml:"clientID"`
+	ClientSecretFile string `yaml:"clientSecretFile"`
+}
+
+// RateLimit holds configuration for throttling logins and proxied API requests.
+type RateLimit struct {
+	// loginRequestsPerMinute is enforced per client IP
+	LoginRequestsPerMinute int `yaml:"loginRequestsPerMinute,omitempty"`
+	// after loginLockoutThreshold failed logins a user is locked out for loginLockoutSeconds
+	LoginLockoutThreshold int `yaml:"loginLockoutThreshold,omitempty"`
+	LoginLockoutSeconds   int `yaml:"loginLockoutSeconds,omitempty"`
+	// apiProxyRequestsPerSecond is enforced per user
+	APIProxyRequestsPerSecond int `yaml:"apiProxyRequestsPerSecond,omitempty"`
+}
+
+// AccessLog holds configuration for logging the requests served by the console backend.
+type AccessLog struct {
+	Format string `yaml:"format,omitempty"`
+	// file access logs are written to, logs go to stdout when empty
+	File string `yaml:"file,omitempty"`
+	// samplingRatio is the fraction of requests logged
+	SamplingRatio float64 `yaml:"samplingRatio,omitempty"`
+	// requests over maxEventsPerSecond are counted but not logged
+	MaxEventsPerSecond int `yaml:"maxEventsPerSecond,omitempty"`
+	MaxFileSizeMB      int `yaml:"maxFileSizeMB,omitempty"`
+	MaxFileBackups     int `yaml:"maxFileBackups,omitempty"`
 }
 
 type Proxy struct {
@@ -71,6 +168,8 @@ type ClusterInfo struct {
 	NodeArchitectures    []string              `yaml:"nodeArchitectures,omitempty"`
 	NodeOperatingSystems []string              `yaml:"nodeOperatingSystems,omitempty"`
 	CopiedCSVsDisabled   bool                  `yaml:"copiedCSVsDisabled,omitempty"`
+	// readOnly disables all mutating actions in the console UI
+	ReadOnly bool `yaml:"readOnly,omitempty"`
 }
 
 // MonitoringInfo holds configuration for monitoring related services
@@ -89,12 +188,60 @@ type Auth struct {
 	OAuthEndpointCAFile      string   `yaml:"oauthEndpointCAFile,omitempty"`
 	LogoutRedirect           string   `yaml:"logoutRedirect,omitempty"`
 	InactivityTimeoutSeconds int      `yaml:"inactivityTimeoutSeconds,omitempty"`
+	// groupInactivityTimeoutSeconds overrides inactivityTimeoutSeconds for members of a group
+	GroupInactivityTimeoutSeconds map[string]int `yaml:"groupInactivityTimeoutSeconds,omitempty"`
+	// accessTokenMaxAgeSeconds ends the session when the access token it holds expires
+	AccessTokenMaxAgeSeconds int `yaml:"accessTokenMaxAgeSeconds,omitempty"`
+	// oidcProviders lists every OIDC provider users can log in with when there is more than one,
+	// the first one is the one of oidcIssuer and clientID
+	OIDCProviders []OIDCProvider `yaml:"oidcProviders,omitempty"`
+	// oidcPublicClient logs in as a public OIDC client using PKCE, without a client secret
+	OIDCPublicClient bool `yaml:"oidcPublicClient,omitempty"`
+	// oidcClaimMappings are the claim mappings of the provider of oidcIssuer
+	OIDCClaimMappings *OIDCClaimMappings `yaml:"oidcClaimMappings,omitempty"`
+	// oidcClockSkewSeconds is tolerated on the expiry, issued at and not before times of the tokens
+	OIDCClockSkewSeconds int `yaml:"oidcClockSkewSeconds,omitempty"`
+	// oidcIDTokenLeewaySeconds refreshes the ID token this long before it expires
+	OIDCIDTokenLeewaySeconds int `yaml:"oidcIDTokenLeewaySeconds,omitempty"`
+	// oidcIssuerEndpoint is the URL the console reaches oidcIssuer at, for the discovery and
+	// token requests, when the advertised issuer URL does not resolve to it from the cluster
+	OIDCIssuerEndpoint string `yaml:"oidcIssuerEndpoint,omitempty"`
+	// oidcAudiences are the audiences the tokens of oidcIssuer are issued for
+	OIDCAudiences []string `yaml:"oidcAudiences,omitempty"`
+	// oidcCLIClientID is the client oc logs in to oidcIssuer with, shown in the login command
+	OIDCCLIClientID string `yaml:"oidcCLIClientID,omitempty"`
+}
+
+// OIDCProvider is an OIDC provider and the console client registered with it.
+type OIDCProvider struct {
+	Name          string             `yaml:"name"`
+	Issuer        string             `yaml:"issuer"`
+	ClientID      string             `yaml:"clientID"`
+	ExtraScopes   []string           `yaml:"extraScopes,omitempty"`
+	ClaimMappings *OIDCClaimMappings `yaml:"claimMappings,omitempty"`
+	// endpoint the issuer is reached at, see oidcIssuerEndpoint
+	Endpoint string `yaml:"endpoint,omitempty"`
+	// audiences and cliClientID, see oidcAudiences and oidcCLIClientID
+	Audiences   []string `yaml:"audiences,omitempty"`
+	CLIClientID string   `yaml:"cliClientID,omitempty"`
+}
+
+// OIDCClaimMappings maps the claims of the ID token to the user name and groups, the same way
+// the API server does, so the console shows the user the API server authenticates.
+type OIDCClaimMappings struct {
+	UsernameClaim  string `yaml:"usernameClaim,omitempty"`
+	UsernamePrefix string `yaml:"usernamePrefix,omitempty"`
+	GroupsClaim    string `yaml:"groupsClaim,omitempty"`
+	GroupsPrefix   string `yaml:"groupsPrefix,omitempty"`
 }
 
 // Session holds configuration for web-session related configuration
 type Session struct {
 	CookieEncryptionKeyFile     string `yaml:"cookieEncryptionKeyFile,omitempty"`
 	CookieAuthenticationKeyFile string `yaml:"cookieAuthenticationKeyFile,omitempty"`
+	// previous keys are accepted for decoding only, so that sessions survive a key rotation
+	PreviousCookieEncryptionKeyFile     string `yaml:"previousCookieEncryptionKeyFile,omitempty"`
+	PreviousCookieAuthenticationKeyFile string `yaml:"previousCookieAuthenticationKeyFile,omitempty"`
 	// TODO: move InactivityTimeoutSeconds here
 }
 
@@ -236,6 +383,15 @@ type Perspective struct {
 
 type Providers struct {
 	StatuspageID string `yaml:"statuspageID,omitempty"`
+	// StatusFeed is an external status feed the console polls and shows
+	StatusFeed *StatusFeed `yaml:"statusFeed,omitempty"`
+}
+
+// StatusFeed is either the summary API of a statuspage.io page or a custom JSON feed.
+type StatusFeed struct {
+	Type                string `yaml:"type"`
+	URL                 string `yaml:"url"`
+	PollIntervalSeconds int    `yaml:"pollIntervalSeconds,omitempty"`
 }
 
 type HelmChartRepo struct {
@@ -0,0 +1,181 @@
+package deployment
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/util"
+)
+
+// DefaultCPUUtilization is the average CPU utilization the console is scaled on when the
+// autoscaling annotation requests no metric.
+const DefaultCPUUtilization = 80
+
+// AutoscalingConfig is the autoscaling of the console deployment requested by the autoscaling
+// annotation of the operator config, eg. 'minReplicas=2,maxReplicas=6,cpu=70,pods/http_requests=50'.
+type AutoscalingConfig struct {
+	MinReplicas int32
+	MaxReplicas int32
+	// CPUUtilization and MemoryUtilization are average utilizations of the requests, in percent
+	CPUUtilization    *int32
+	MemoryUtilization *int32
+	// PodsMetrics are custom metrics of the console pods and their target average values
+	PodsMetrics map[string]resource.Quantity
+}
+
+// IsAutoscaled is true when the operator config requests autoscaling and the topology has the
+// nodes to scale the console on.
+func IsAutoscaled(operatorConfig *operatorv1.Console, infrastructureConfig *configv1.Infrastructure) bool {
+	_, ok := operatorConfig.Annotations[api.AutoscalingAnnotation]
+	return ok && ShouldDeployHA(infrastructureConfig)
+}
+
+// GetAutoscalingConfig parses the autoscaling annotation of the operator config. The replicas
+// default to the console replicas and MaxConsoleReplicas, the metrics to DefaultCPUUtilization.
+// Invalid entries are left out and reported in the returned error.
+func GetAutoscalingConfig(operatorConfig *operatorv1.Console, infrastructureConfig *configv1.Infrastructure) (AutoscalingConfig, error) {
+	minReplicas, _ := ConsoleReplicas(operatorConfig, infrastructureConfig)
+	config := AutoscalingConfig{
+		MinReplicas: minReplicas,
+		MaxReplicas: MaxConsoleReplicas,
+		PodsMetrics: map[string]resource.Quantity{},
+	}
+	invalid := []string{}
+	for _, entry := range strings.Split(operatorConfig.Annotations[api.AutoscalingAnnotation], ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+		name, value, _ := strings.Cut(entry, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		switch {
+		case name == "minReplicas" || name == "maxReplicas":
+			replicas, err := strconv.ParseInt(value, 10, 32)
+			if err != nil || replicas < 1 || replicas > MaxConsoleReplicas {
+				invalid = append(invalid, entry)
+			} else if name == "minReplicas" {
+				config.MinReplicas = int32(replicas)
+			} else {
+				config.MaxReplicas = int32(replicas)
+			}
+		case name == "cpu" || name == "memory":
+			utilization, err := strconv.ParseInt(value, 10, 32)
+			if err != nil || utilization < 1 {
+				invalid = append(invalid, entry)
+				continue
+			}
+			percent := int32(utilization)
+			if name == "cpu" {
+				config.CPUUtilization = &percent
+			} else {
+				config.MemoryUtilization = &percent
+			}
+		case strings.HasPrefix(name, "pods/") && len(name) > len("pods/"):
+			target, err := resource.ParseQuantity(value)
+			if err != nil || target.Sign() <= 0 {
+				invalid = append(invalid, entry)
+				continue
+			}
+			config.PodsMetrics[strings.TrimPrefix(name, "pods/")] = target
+		default:
+			invalid = append(invalid, entry)
+		}
+	}
+	if config.MinReplicas > config.MaxReplicas {
+		invalid = append(invalid, fmt.Sprintf("minReplicas %d above maxReplicas %d", config.MinReplicas, config.MaxReplicas))
+		config.MaxReplicas = config.MinReplicas
+	}
+	if config.CPUUtilization == nil && config.MemoryUtilization == nil && len(config.PodsMetrics) == 0 {
+		cpu := int32(DefaultCPUUtilization)
+		config.CPUUtilization = &cpu
+	}
+	if len(invalid) > 0 {
+		return config, fmt.Errorf("invalid autoscaling, expected minReplicas=<1-%d>, maxReplicas=<1-%d>, cpu=<percent>, memory=<percent> or pods/<metric>=<average value>: %s", MaxConsoleReplicas, MaxConsoleReplicas, strings.Join(invalid, ", "))
+	}
+	return config, nil
+}
+
+// DefaultHorizontalPodAutoscaler returns the HorizontalPodAutoscaler of the console deployment.
+func DefaultHorizontalPodAutoscaler(operatorConfig *operatorv1.Console, config AutoscalingConfig) *autoscalingv2.HorizontalPodAutoscaler {
+	minReplicas := config.MinReplicas
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      api.OpenShiftConsoleDeploymentName,
+			Namespace: api.OpenShiftConsoleNamespace,
+			Labels:    util.LabelsForConsole(),
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       api.OpenShiftConsoleDeploymentName,
+			},
+			MinReplicas: &minReplicas,
+			MaxReplicas: config.MaxReplicas,
+		},
+	}
+	for _, resourceMetric := range []struct {
+		name        corev1.ResourceName
+		utilization *int32
+	}{
+		{corev1.ResourceCPU, config.CPUUtilization},
+		{corev1.ResourceMemory, config.MemoryUtilization},
+	} {
+		if resourceMetric.utilization == nil {
+			continue
+		}
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, autoscalingv2.MetricSpec{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name: resourceMetric.name,
+				Target: autoscalingv2.MetricTarget{
+					Type:               autoscalingv2.UtilizationMetricType,
+					AverageUtilization: resourceMetric.utilization,
+				},
+			},
+		})
+	}
+	// sorted, so that the HorizontalPodAutoscaler is only updated when the metrics change
+	metricNames := make([]string, 0, len(config.PodsMetrics))
+	for name := range config.PodsMetrics {
+		metricNames = append(metricNames, name)
+	}
+	sort.Strings(metricNames)
+	for _, name := range metricNames {
+		target := config.PodsMetrics[name]
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, autoscalingv2.MetricSpec{
+			Type: autoscalingv2.PodsMetricSourceType,
+			Pods: &autoscalingv2.PodsMetricSource{
+				Metric: autoscalingv2.MetricIdentifier{Name: name},
+				Target: autoscalingv2.MetricTarget{
+					Type:         autoscalingv2.AverageValueMetricType,
+					AverageValue: &target,
+				},
+			},
+		})
+	}
+	util.AddOwnerRef(hpa, util.OwnerRefFrom(operatorConfig))
+	return hpa
+}
+
+// WithAutoscaledReplicas leaves the replicas of the console deployment to the
+// HorizontalPodAutoscaler: required keeps the replicas of existing, unless the console is
+// paused, or not deployed or scaled up from a pause yet.
+func WithAutoscaledReplicas(required, existing *appsv1.Deployment, operatorConfig *operatorv1.Console, infrastructureConfig *configv1.Infrastructure) {
+	if existing == nil || existing.Spec.Replicas == nil || *existing.Spec.Replicas == 0 || IsPaused(operatorConfig) || !IsAutoscaled(operatorConfig, infrastructureConfig) {
+		return
+	}
+	replicas := *existing.Spec.Replicas
+	required.Spec.Replicas = &replicas
+}
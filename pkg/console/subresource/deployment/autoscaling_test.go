@@ -0,0 +1,160 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestGetAutoscalingConfig(t *testing.T) {
+	percent := func(p int32) *int32 { return &p }
+	infrastructureConfigHighlyAvailable := infrastructureConfigWithTopology(configv1.HighlyAvailableTopologyMode,
+		configv1.HighlyAvailableTopologyMode)
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        AutoscalingConfig
+		wantErr     bool
+	}{
+		{
+			name:        "Test default autoscaling",
+			annotations: map[string]string{api.AutoscalingAnnotation: ""},
+			want: AutoscalingConfig{
+				MinReplicas:    DefaultConsoleReplicas,
+				MaxReplicas:    MaxConsoleReplicas,
+				CPUUtilization: percent(DefaultCPUUtilization),
+				PodsMetrics:    map[string]resource.Quantity{},
+			},
+		},
+		{
+			name:        "Test autoscaling on memory and a custom metric",
+			annotations: map[string]string{api.AutoscalingAnnotation: "minReplicas=3, maxReplicas=6, memory=75, pods/http_requests_per_second=50"},
+			want: AutoscalingConfig{
+				MinReplicas:       3,
+				MaxReplicas:       6,
+				MemoryUtilization: percent(75),
+				PodsMetrics:       map[string]resource.Quantity{"http_requests_per_second": resource.MustParse("50")},
+			},
+		},
+		{
+			name: "Test minimum replicas defaulting to the replicas annotation",
+			annotations: map[string]string{
+				api.AutoscalingAnnotation: "cpu=60",
+				api.ReplicasAnnotation:    "3",
+			},
+			want: AutoscalingConfig{
+				MinReplicas:    3,
+				MaxReplicas:    MaxConsoleReplicas,
+				CPUUtilization: percent(60),
+				PodsMetrics:    map[string]resource.Quantity{},
+			},
+		},
+		{
+			name:        "Test invalid autoscaling entries are left out",
+			annotations: map[string]string{api.AutoscalingAnnotation: "maxReplicas=50,cpu=high,pods/=1,disk=10"},
+			want: AutoscalingConfig{
+				MinReplicas:    DefaultConsoleReplicas,
+				MaxReplicas:    MaxConsoleReplicas,
+				CPUUtilization: percent(DefaultCPUUtilization),
+				PodsMetrics:    map[string]resource.Quantity{},
+			},
+			wantErr: true,
+		},
+		{
+			name:        "Test minimum replicas above the maximum",
+			annotations: map[string]string{api.AutoscalingAnnotation: "minReplicas=4,maxReplicas=3"},
+			want: AutoscalingConfig{
+				MinReplicas:    4,
+				MaxReplicas:    4,
+				CPUUtilization: percent(DefaultCPUUtilization),
+				PodsMetrics:    map[string]resource.Quantity{},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorsv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := GetAutoscalingConfig(operatorConfig, infrastructureConfigHighlyAvailable)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetAutoscalingConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithAutoscaledReplicas(t *testing.T) {
+	replicas := func(r int32) *int32 { return &r }
+	infrastructureConfigHighlyAvailable := infrastructureConfigWithTopology(configv1.HighlyAvailableTopologyMode,
+		configv1.HighlyAvailableTopologyMode)
+	infrastructureConfigSingleReplica := infrastructureConfigWithTopology(configv1.SingleReplicaTopologyMode,
+		configv1.SingleReplicaTopologyMode)
+	autoscaled := map[string]string{api.AutoscalingAnnotation: "maxReplicas=6"}
+
+	tests := []struct {
+		name                 string
+		annotations          map[string]string
+		infrastructureConfig *configv1.Infrastructure
+		existing             *appsv1.Deployment
+		want                 *int32
+	}{
+		{
+			name:                 "Test autoscaled replicas kept",
+			annotations:          autoscaled,
+			infrastructureConfig: infrastructureConfigHighlyAvailable,
+			existing:             &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: replicas(5)}},
+			want:                 replicas(5),
+		},
+		{
+			name:                 "Test console not autoscaled",
+			infrastructureConfig: infrastructureConfigHighlyAvailable,
+			existing:             &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: replicas(5)}},
+			want:                 replicas(DefaultConsoleReplicas),
+		},
+		{
+			name:                 "Test console not deployed yet",
+			annotations:          autoscaled,
+			infrastructureConfig: infrastructureConfigHighlyAvailable,
+			want:                 replicas(DefaultConsoleReplicas),
+		},
+		{
+			name:                 "Test console scaled up from a pause",
+			annotations:          autoscaled,
+			infrastructureConfig: infrastructureConfigHighlyAvailable,
+			existing:             &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: replicas(0)}},
+			want:                 replicas(DefaultConsoleReplicas),
+		},
+		{
+			name:                 "Test Single Replica topology not autoscaled",
+			annotations:          autoscaled,
+			infrastructureConfig: infrastructureConfigSingleReplica,
+			existing:             &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: replicas(5)}},
+			want:                 replicas(DefaultConsoleReplicas),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorsv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			required := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: replicas(DefaultConsoleReplicas)}}
+			WithAutoscaledReplicas(required, tt.existing, operatorConfig, tt.infrastructureConfig)
+			if diff := deep.Equal(required.Spec.Replicas, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -2,15 +2,22 @@ package deployment
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
 
 	// kube
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	appsclientv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
 
 	// openshift
 	configv1 "github.com/openshift/api/config/v1"
@@ -25,31 +32,44 @@ const (
 	ConsoleOauthConfigName    = "console-oauth-config"
 	DefaultConsoleReplicas    = 2
 	SingleNodeConsoleReplicas = 1
+	// MaxConsoleReplicas bounds the replicas annotation, the console sessions do not need more
+	MaxConsoleReplicas = 10
+	// systemCertDir is where the console image keeps the system trust bundle
+	systemCertDir = "/etc/pki/tls/certs"
 )
 
 const (
-	configMapResourceVersionAnnotation             = "console.openshift.io/console-config-version"
-	proxyConfigResourceVersionAnnotation           = "console.openshift.io/proxy-config-version"
-	infrastructureConfigResourceVersionAnnotation  = "console.openshift.io/infrastructure-config-version"
-	serviceCAConfigMapResourceVersionAnnotation    = "console.openshift.io/service-ca-config-version"
-	trustedCAConfigMapResourceVersionAnnotation    = "console.openshift.io/trusted-ca-config-version"
-	secretResourceVersionAnnotation                = "console.openshift.io/oauth-secret-version"
-	consoleImageAnnotation                         = "console.openshift.io/image"
-	authnConfigVersionAnnotation                   = "console.openshift.io/authentication-config-version"
-	authnCATrustConfigMapResourceVersionAnnotation = "console.openshift.io/authn-ca-trust-config-version"
-	sessionSecretRVAnnotation                      = "console.openshift.io/session-secret-version"
+	configMapVersionAnnotation              = "console.openshift.io/console-config-version"
+	proxyConfigVersionAnnotation            = "console.openshift.io/proxy-config-version"
+	infrastructureConfigVersionAnnotation   = "console.openshift.io/infrastructure-config-version"
+	serviceCAConfigMapVersionAnnotation     = "console.openshift.io/service-ca-config-version"
+	trustedCAConfigMapVersionAnnotation     = "console.openshift.io/trusted-ca-config-version"
+	secretVersionAnnotation                 = "console.openshift.io/oauth-secret-version"
+	consoleImageAnnotation                  = "console.openshift.io/image"
+	authnConfigVersionAnnotation            = "console.openshift.io/authentication-config-version"
+	authnCATrustConfigMapVersionAnnotation  = "console.openshift.io/authn-ca-trust-config-version"
+	sessionSecretVersionAnnotation          = "console.openshift.io/session-secret-version"
+	OIDCSecretProviderClassAnnotation       = "console.openshift.io/oidc-secret-provider-class"
+	managedClusterCABundleVersionAnnotation = "console.openshift.io/managed-cluster-ca-bundle-version"
+	consoleMountsVersionAnnotation          = "console.openshift.io/console-mounts-version"
 )
 
 var (
 	resourceAnnotations = []string{
-		configMapResourceVersionAnnotation,
-		proxyConfigResourceVersionAnnotation,
-		infrastructureConfigResourceVersionAnnotation,
-		serviceCAConfigMapResourceVersionAnnotation,
-		authnCATrustConfigMapResourceVersionAnnotation,
-		trustedCAConfigMapResourceVersionAnnotation,
-		secretResourceVersionAnnotation,
+		configMapVersionAnnotation,
+		proxyConfigVersionAnnotation,
+		infrastructureConfigVersionAnnotation,
+		serviceCAConfigMapVersionAnnotation,
+		authnCATrustConfigMapVersionAnnotation,
+		trustedCAConfigMapVersionAnnotation,
+		secretVersionAnnotation,
 		consoleImageAnnotation,
+		api.ConsoleReleaseVersionAnnotation,
+		OIDCSecretProviderClassAnnotation,
+		managedClusterCABundleVersionAnnotation,
+		consoleMountsVersionAnnotation,
+		api.SessionPolicyAnnotation,
+		api.OIDCClientSecretSourceAnnotation,
 	}
 )
 
@@ -71,7 +91,11 @@ func DefaultDeployment(
 	authServerCAConfigMap *corev1.ConfigMap,
 	trustedCAConfigMap *corev1.ConfigMap,
 	oAuthClientSecret *corev1.Secret,
+	oidcSecretProviderClass string,
+	noClientSecret bool,
 	sessionSecret *corev1.Secret,
+	managedClusterOAuthSecret *corev1.Secret,
+	managedClusterCABundle *corev1.ConfigMap,
 	proxyConfig *configv1.Proxy,
 	infrastructureConfig *configv1.Infrastructure,
 	canMountCustomLogo bool,
@@ -82,9 +106,15 @@ func DefaultDeployment(
 	}
 
 	deployment := resourceread.ReadDeploymentV1OrDie(bindata.MustAsset("assets/deployments/console-deployment.yaml"))
-	withReplicas(deployment, infrastructureConfig)
+	// impossible replicas are reported by the operator, the console keeps the default ones
+	replicas, _ := ConsoleReplicas(operatorConfig, infrastructureConfig)
+	withReplicas(deployment, operatorConfig, replicas)
 	withAffinity(deployment, infrastructureConfig, "ui")
-	withStrategy(deployment, infrastructureConfig)
+	withTopologySpreadConstraints(deployment, operatorConfig)
+	withRollingUpdate(deployment, operatorConfig, infrastructureConfig)
+	withResources(deployment, operatorConfig, api.ConsoleResourcesAnnotation)
+	withConsoleProbes(deployment, operatorConfig)
+	withGracefulTermination(deployment, operatorConfig)
 	withConsoleAnnotations(
 		deployment,
 		consoleConfigMap,
@@ -104,8 +134,19 @@ func DefaultDeployment(
 		sessionSecret,
 		canMountCustomLogo,
 	)
+	withOIDCClientSecretProviderClass(deployment, oidcSecretProviderClass)
+	withoutClientSecret(deployment, noClientSecret)
+	// invalid access log values are reported by the operator
+	accessLog, _ := util.GetAccessLogConfig(operatorConfig)
+	withAccessLogVolume(deployment, accessLog)
+	withManagedClusterOAuthVolume(deployment, managedClusterOAuthSecret)
 	withConsoleContainerImage(deployment, operatorConfig, proxyConfig)
+	withManagedClusterCABundle(deployment, managedClusterCABundle)
+	withConsoleEnv(deployment, operatorConfig)
 	withConsoleNodeSelector(deployment, infrastructureConfig)
+	withNodePlacement(deployment, operatorConfig)
+	withPriorityClassName(deployment, operatorConfig)
+	withPodTemplateExtensions(deployment, operatorConfig)
 	util.AddOwnerRef(deployment, util.OwnerRefFrom(operatorConfig))
 	return deployment
 }
@@ -117,14 +158,25 @@ func DefaultDownloadsDeployment(
 	downloadsDeployment := resourceread.ReadDeploymentV1OrDie(
 		bindata.MustAsset("assets/deployments/downloads-deployment.yaml"),
 	)
-	withReplicas(downloadsDeployment, infrastructureConfig)
+	withReplicas(downloadsDeployment, operatorConfig, DefaultReplicas(infrastructureConfig))
 	withAffinity(downloadsDeployment, infrastructureConfig, "downloads")
 	withStrategy(downloadsDeployment, infrastructureConfig)
+	withResources(downloadsDeployment, operatorConfig, api.DownloadsResourcesAnnotation)
 	withDownloadsContainerImage(downloadsDeployment)
+	withNodePlacement(downloadsDeployment, operatorConfig)
+	withPriorityClassName(downloadsDeployment, operatorConfig)
 	util.AddOwnerRef(downloadsDeployment, util.OwnerRefFrom(operatorConfig))
 	return downloadsDeployment
 }
 
+// IsPaused reports whether the operator config asks for the console to be scaled to zero. Unlike
+// the Removed state, the route, the OAuth client and the config are kept, so that the console
+// is back as soon as the annotation is removed.
+func IsPaused(operatorConfig *operatorv1.Console) bool {
+	paused, err := strconv.ParseBool(operatorConfig.Annotations[api.PausedAnnotation])
+	return err == nil && paused
+}
+
 // ShouldDeployHA returns true if the console should be deployed in HA mode.
 // If the control plane is externalized, the console should be deployed in HA mode based on the InfrastructureTopology,
 // otherwise it should be deployed in HA mode based on the ControlPlaneTopology.
@@ -134,10 +186,37 @@ func ShouldDeployHA(infrastructureConfig *configv1.Infrastructure) bool {
 			infrastructureConfig.Status.InfrastructureTopology == configv1.HighlyAvailableTopologyMode)
 }
 
-func withReplicas(deployment *appsv1.Deployment, infrastructureConfig *configv1.Infrastructure) {
-	replicas := int32(SingleNodeConsoleReplicas)
+// DefaultReplicas returns the replicas of the topology of the cluster.
+func DefaultReplicas(infrastructureConfig *configv1.Infrastructure) int32 {
 	if ShouldDeployHA(infrastructureConfig) {
-		replicas = int32(DefaultConsoleReplicas)
+		return DefaultConsoleReplicas
+	}
+	return SingleNodeConsoleReplicas
+}
+
+// ConsoleReplicas returns the console replicas requested by the replicas annotation of the
+// operator config, or the default ones of the topology. A single replica topology has a single
+// node to run the console on, more replicas would share it without adding any availability. An
+// impossible value is ignored and returned in the error.
+func ConsoleReplicas(operatorConfig *operatorv1.Console, infrastructureConfig *configv1.Infrastructure) (int32, error) {
+	defaultReplicas := DefaultReplicas(infrastructureConfig)
+	value, ok := operatorConfig.Annotations[api.ReplicasAnnotation]
+	if !ok {
+		return defaultReplicas, nil
+	}
+	replicas, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || replicas < 1 || replicas > MaxConsoleReplicas {
+		return defaultReplicas, fmt.Errorf("%s must be a number of replicas between 1 and %d, keeping %d replicas: %q", api.ReplicasAnnotation, MaxConsoleReplicas, defaultReplicas, value)
+	}
+	if !ShouldDeployHA(infrastructureConfig) && replicas > SingleNodeConsoleReplicas {
+		return defaultReplicas, fmt.Errorf("%s requests %d replicas, a single replica topology runs %d console replica", api.ReplicasAnnotation, replicas, SingleNodeConsoleReplicas)
+	}
+	return int32(replicas), nil
+}
+
+func withReplicas(deployment *appsv1.Deployment, operatorConfig *operatorv1.Console, replicas int32) {
+	if IsPaused(operatorConfig) {
+		replicas = 0
 	}
 	deployment.Spec.Replicas = &replicas
 }
@@ -185,8 +264,8 @@ func withStrategy(deployment *appsv1.Deployment, infrastructureConfig *configv1.
 }
 
 // withConsoleAnnotations adds annotations in the console deployment which are used to track
-// resources that when updated, trigger a new deployment rollout; this happens when the resource
-// version changes.
+// resources that when updated, trigger a new deployment rollout; this happens when the content
+// the console reads from them changes.
 func withConsoleAnnotations(
 	deployment *appsv1.Deployment,
 	consoleConfigMap *corev1.ConfigMap,
@@ -199,21 +278,36 @@ func withConsoleAnnotations(
 	infrastructureConfig *configv1.Infrastructure,
 ) {
 	deployment.ObjectMeta.Annotations = map[string]string{
-		configMapResourceVersionAnnotation:            consoleConfigMap.GetResourceVersion(),
-		serviceCAConfigMapResourceVersionAnnotation:   serviceCAConfigMap.GetResourceVersion(),
-		trustedCAConfigMapResourceVersionAnnotation:   trustedCAConfigMap.GetResourceVersion(),
-		proxyConfigResourceVersionAnnotation:          proxyConfig.GetResourceVersion(),
-		infrastructureConfigResourceVersionAnnotation: infrastructureConfig.GetResourceVersion(),
-		secretResourceVersionAnnotation:               oAuthClientSecret.GetResourceVersion(),
-		consoleImageAnnotation:                        util.GetImageEnv("CONSOLE_IMAGE"),
+		configMapVersionAnnotation:            ConfigMapVersion(consoleConfigMap),
+		serviceCAConfigMapVersionAnnotation:   ConfigMapVersion(serviceCAConfigMap),
+		trustedCAConfigMapVersionAnnotation:   ConfigMapVersion(trustedCAConfigMap),
+		proxyConfigVersionAnnotation:          proxyConfigVersion(proxyConfig),
+		infrastructureConfigVersionAnnotation: infrastructureConfigVersion(infrastructureConfig),
+		consoleImageAnnotation:                util.GetImageEnv("CONSOLE_IMAGE"),
+		api.ConsoleReleaseVersionAnnotation:   os.Getenv("RELEASE_VERSION"),
+	}
+
+	// the client secret is not a Secret when it is mounted from an external secret store
+	if oAuthClientSecret != nil {
+		deployment.ObjectMeta.Annotations[secretVersionAnnotation] = SecretVersion(oAuthClientSecret)
+		// an OIDC client secret is copied from the secret the authentication config references
+		if source, ok := oAuthClientSecret.GetAnnotations()[api.OIDCClientSecretSourceAnnotation]; ok {
+			deployment.ObjectMeta.Annotations[api.OIDCClientSecretSourceAnnotation] = source
+		}
 	}
 
 	if authServerCAConfigMap != nil {
-		deployment.ObjectMeta.Annotations[authnCATrustConfigMapResourceVersionAnnotation] = authServerCAConfigMap.GetResourceVersion()
+		deployment.ObjectMeta.Annotations[authnCATrustConfigMapVersionAnnotation] = CATrustVersion(authServerCAConfigMap)
 	}
 
 	if sessionSecret != nil {
-		deployment.ObjectMeta.Annotations[sessionSecretRVAnnotation] = sessionSecret.GetResourceVersion()
+		deployment.ObjectMeta.Annotations[sessionSecretVersionAnnotation] = SecretVersion(sessionSecret)
+	}
+
+	// the session policy is rendered into console-config, it is tracked on its own so that a
+	// policy change can be told apart from the other config changes
+	if sessionPolicy, ok := consoleConfigMap.GetAnnotations()[api.SessionPolicyAnnotation]; ok {
+		deployment.ObjectMeta.Annotations[api.SessionPolicyAnnotation] = sessionPolicy
 	}
 
 	podAnnotations := deployment.Spec.Template.ObjectMeta.Annotations
@@ -298,6 +392,150 @@ func withConsoleVolumes(
 	deployment.Spec.Template.Spec.Volumes = vols
 }
 
+// CATrustVersion is the version of the CA trust configmap tracked on the console deployment. It
+// is the hash of the bundle merged by the operator, and the hash of the data of any other
+// configmap.
+func CATrustVersion(configMap *corev1.ConfigMap) string {
+	if hash, ok := configMap.GetAnnotations()[api.CABundleHashAnnotation]; ok {
+		return hash
+	}
+	return ConfigMapVersion(configMap)
+}
+
+// withOIDCClientSecretProviderClass sources the client secret from the Secrets Store CSI driver
+// instead of the console-oauth-config secret. The mount path is unchanged, so the console keeps
+// reading the 'clientSecret' file from the same location.
+func withOIDCClientSecretProviderClass(deployment *appsv1.Deployment, secretProviderClass string) {
+	if len(secretProviderClass) == 0 {
+		return
+	}
+
+	volumes := deployment.Spec.Template.Spec.Volumes
+	for i := range volumes {
+		if volumes[i].Name != ConsoleOauthConfigName {
+			continue
+		}
+		volumes[i].VolumeSource = corev1.VolumeSource{
+			CSI: &corev1.CSIVolumeSource{
+				Driver:   api.SecretsStoreCSIDriverName,
+				ReadOnly: pointer.Bool(true),
+				VolumeAttributes: map[string]string{
+					"secretProviderClass": secretProviderClass,
+				},
+			},
+		}
+	}
+
+	// the provider class name stands in for the secret resource version to trigger a rollout
+	deployment.ObjectMeta.Annotations[OIDCSecretProviderClassAnnotation] = secretProviderClass
+	deployment.Spec.Template.ObjectMeta.Annotations[OIDCSecretProviderClassAnnotation] = secretProviderClass
+}
+
+// withoutClientSecret drops the client secret volume of a console logging in as a public
+// OIDC client or running without authentication, there is no console-oauth-config secret
+// to mount.
+func withoutClientSecret(deployment *appsv1.Deployment, noClientSecret bool) {
+	if !noClientSecret {
+		return
+	}
+
+	volumes := []corev1.Volume{}
+	for _, volume := range deployment.Spec.Template.Spec.Volumes {
+		if volume.Name != ConsoleOauthConfigName {
+			volumes = append(volumes, volume)
+		}
+	}
+	deployment.Spec.Template.Spec.Volumes = volumes
+
+	volumeMounts := []corev1.VolumeMount{}
+	for _, volumeMount := range deployment.Spec.Template.Spec.Containers[0].VolumeMounts {
+		if volumeMount.Name != ConsoleOauthConfigName {
+			volumeMounts = append(volumeMounts, volumeMount)
+		}
+	}
+	deployment.Spec.Template.Spec.Containers[0].VolumeMounts = volumeMounts
+}
+
+// withAccessLogVolume mounts the PersistentVolumeClaim access logs are written to. Every pod
+// writes to its own directory of the claim, named after the pod, so that replicas never rotate
+// each other's files.
+func withAccessLogVolume(deployment *appsv1.Deployment, accessLog *util.AccessLogConfig) {
+	if accessLog == nil || accessLog.Destination != util.AccessLogDestinationFile {
+		return
+	}
+
+	deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: api.AccessLogVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: accessLog.Claim,
+			},
+		},
+	})
+	deployment.Spec.Template.Spec.Containers[0].VolumeMounts = append(deployment.Spec.Template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:        api.AccessLogVolumeName,
+		MountPath:   api.AccessLogMountDir,
+		SubPathExpr: "$(POD_NAME)",
+	})
+}
+
+// withManagedClusterOAuthVolume mounts the client secrets of the console clients provisioned on
+// managed clusters, one file per cluster. Clusters are added and removed through console-config,
+// which rolls the console out, the secret itself does not need to.
+func withManagedClusterOAuthVolume(deployment *appsv1.Deployment, managedClusterOAuthSecret *corev1.Secret) {
+	if managedClusterOAuthSecret == nil {
+		return
+	}
+
+	deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: api.ManagedClusterOAuthSecretName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: api.ManagedClusterOAuthSecretName,
+			},
+		},
+	})
+	deployment.Spec.Template.Spec.Containers[0].VolumeMounts = append(deployment.Spec.Template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      api.ManagedClusterOAuthSecretName,
+		ReadOnly:  true,
+		MountPath: api.ManagedClusterOAuthMountDir,
+	})
+}
+
+// withManagedClusterCABundle adds the API server CAs of the managed clusters to the trust bundle
+// of the console. The cluster trust bundle is mounted over the system one, the managed cluster
+// CAs are loaded from their own certificate directory on top of it, and a rotated CA rolls the
+// console out.
+func withManagedClusterCABundle(deployment *appsv1.Deployment, managedClusterCABundle *corev1.ConfigMap) {
+	if managedClusterCABundle == nil {
+		return
+	}
+
+	deployment.ObjectMeta.Annotations[managedClusterCABundleVersionAnnotation] = ConfigMapVersion(managedClusterCABundle)
+	deployment.Spec.Template.ObjectMeta.Annotations[managedClusterCABundleVersionAnnotation] = ConfigMapVersion(managedClusterCABundle)
+	deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: api.ManagedClusterCABundleConfigMapName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: api.ManagedClusterCABundleConfigMapName,
+				},
+			},
+		},
+	})
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      api.ManagedClusterCABundleConfigMapName,
+		ReadOnly:  true,
+		MountPath: api.ManagedClusterCABundleMountDir,
+	})
+	// SSL_CERT_DIR replaces the default certificate directories, keep the system one listed
+	container.Env = append(container.Env, corev1.EnvVar{
+		Name:  "SSL_CERT_DIR",
+		Value: strings.Join([]string{systemCertDir, api.ManagedClusterCABundleMountDir}, ":"),
+	})
+}
+
 func withConsoleContainerImage(
 	deployment *appsv1.Deployment,
 	operatorConfig *operatorv1.Console,
@@ -342,6 +580,144 @@ func withDownloadsContainerImage(downloadsDeployment *appsv1.Deployment) {
 	downloadsDeployment.Spec.Template.Spec.Containers[0].Image = util.GetImageEnv("DOWNLOADS_IMAGE")
 }
 
+// PodTemplateChanged is true when applying required to the live deployment rolls out new pods. The
+// fields the API server defaults in the live pod template are not compared.
+func PodTemplateChanged(required, live *appsv1.Deployment) bool {
+	return !equality.Semantic.DeepDerivative(required.Spec.Template, live.Spec.Template)
+}
+
+// SecurityContextDrift lists the pod and container security contexts of a console pod that override
+// a field the required deployment sets, which happens when an SCC or an admission webhook mutates
+// the pods. Fields the deployment leaves unset are filled in by admission and are not drift.
+func SecurityContextDrift(required *appsv1.Deployment, pod *corev1.Pod) []string {
+	drift := []string{}
+	if overridesFields(required.Spec.Template.Spec.SecurityContext, pod.Spec.SecurityContext) {
+		drift = append(drift, "pod")
+	}
+	for _, requiredContainer := range required.Spec.Template.Spec.Containers {
+		for _, podContainer := range pod.Spec.Containers {
+			if requiredContainer.Name == podContainer.Name && overridesFields(requiredContainer.SecurityContext, podContainer.SecurityContext) {
+				drift = append(drift, fmt.Sprintf("container %s", requiredContainer.Name))
+			}
+		}
+	}
+	return drift
+}
+
+// overridesFields is true when actual has another value for a field set in required.
+func overridesFields(required, actual interface{}) bool {
+	requiredFields, actualFields := map[string]interface{}{}, map[string]interface{}{}
+	requiredJSON, _ := json.Marshal(required)
+	actualJSON, _ := json.Marshal(actual)
+	// a nil security context unmarshals to a nil map
+	_ = json.Unmarshal(requiredJSON, &requiredFields)
+	_ = json.Unmarshal(actualJSON, &actualFields)
+	return !containsFields(actualFields, requiredFields)
+}
+
+func containsFields(actual, required map[string]interface{}) bool {
+	for key, requiredValue := range required {
+		requiredMap, requiredIsMap := requiredValue.(map[string]interface{})
+		actualMap, actualIsMap := actual[key].(map[string]interface{})
+		if requiredIsMap && actualIsMap {
+			if !containsFields(actualMap, requiredMap) {
+				return false
+			}
+			continue
+		}
+		if !reflect.DeepEqual(requiredValue, actual[key]) {
+			return false
+		}
+	}
+	return true
+}
+
+// CanaryDeployment derives the canary from the console deployment: a single replica, not
+// selected by the console service, serving the candidate config of canaryConfigMap.
+func CanaryDeployment(consoleDeployment *appsv1.Deployment, canaryConfigMap *corev1.ConfigMap) *appsv1.Deployment {
+	canary := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            api.OpenShiftConsoleCanaryName,
+			Namespace:       api.OpenShiftConsoleNamespace,
+			Labels:          util.LabelsForCanary(),
+			Annotations:     map[string]string{},
+			OwnerReferences: consoleDeployment.OwnerReferences,
+		},
+		Spec: *consoleDeployment.Spec.DeepCopy(),
+	}
+	for k, v := range consoleDeployment.Annotations {
+		canary.Annotations[k] = v
+	}
+	canary.Annotations[configMapVersionAnnotation] = ConfigMapVersion(canaryConfigMap)
+
+	replicas := int32(1)
+	canary.Spec.Replicas = &replicas
+	canary.Spec.Selector = &metav1.LabelSelector{MatchLabels: util.LabelsForCanary()}
+	canary.Spec.Template.Labels = util.LabelsForCanary()
+	canary.Spec.Template.Spec.Affinity = nil
+	canary.Spec.Strategy.RollingUpdate = &appsv1.RollingUpdateDeployment{}
+	if canary.Spec.Template.Annotations == nil {
+		canary.Spec.Template.Annotations = map[string]string{}
+	}
+	canary.Spec.Template.Annotations[configMapVersionAnnotation] = ConfigMapVersion(canaryConfigMap)
+
+	// same mount paths, other sources
+	for i, volume := range canary.Spec.Template.Spec.Volumes {
+		switch {
+		case volume.Name == api.OpenShiftConsoleConfigMapName && volume.ConfigMap != nil:
+			canary.Spec.Template.Spec.Volumes[i].ConfigMap.Name = canaryConfigMap.Name
+		case volume.Name == api.ConsoleServingCertName && volume.Secret != nil:
+			canary.Spec.Template.Spec.Volumes[i].Secret.SecretName = api.CanaryServingCertName
+		}
+	}
+	return canary
+}
+
+// GreenDeployment derives the green console of a blue/green rollout from the console deployment:
+// the same replicas, not selected by the console service, serving the candidate config of
+// greenConfigMap.
+func GreenDeployment(consoleDeployment *appsv1.Deployment, greenConfigMap *corev1.ConfigMap) *appsv1.Deployment {
+	green := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            api.OpenShiftConsoleGreenName,
+			Namespace:       api.OpenShiftConsoleNamespace,
+			Labels:          util.LabelsForGreen(),
+			Annotations:     map[string]string{},
+			OwnerReferences: consoleDeployment.OwnerReferences,
+		},
+		Spec: *consoleDeployment.Spec.DeepCopy(),
+	}
+	for k, v := range consoleDeployment.Annotations {
+		green.Annotations[k] = v
+	}
+	green.Annotations[configMapVersionAnnotation] = ConfigMapVersion(greenConfigMap)
+
+	green.Spec.Selector = &metav1.LabelSelector{MatchLabels: util.LabelsForGreen()}
+	green.Spec.Template.Labels = util.LabelsForGreen()
+	if green.Spec.Template.Annotations == nil {
+		green.Spec.Template.Annotations = map[string]string{}
+	}
+	green.Spec.Template.Annotations[configMapVersionAnnotation] = ConfigMapVersion(greenConfigMap)
+	// spread the green pods among themselves, the anti-affinity to the console pods would keep
+	// them off the nodes running the console
+	if affinity := green.Spec.Template.Spec.Affinity; affinity != nil && affinity.PodAntiAffinity != nil {
+		for i := range affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+			affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution[i].LabelSelector = &metav1.LabelSelector{MatchLabels: util.LabelsForGreen()}
+		}
+	}
+
+	// same mount paths, other sources
+	for i, volume := range green.Spec.Template.Spec.Volumes {
+		switch {
+		case volume.Name == api.OpenShiftConsoleConfigMapName && volume.ConfigMap != nil:
+			green.Spec.Template.Spec.Volumes[i].ConfigMap.Name = greenConfigMap.Name
+		case volume.Name == api.ConsoleServingCertName && volume.Secret != nil:
+			green.Spec.Template.Spec.Volumes[i].Secret.SecretName = api.GreenServingCertName
+		}
+	}
+	return green
+}
+
 func Stub() *appsv1.Deployment {
 	meta := util.SharedMeta()
 	dep := &appsv1.Deployment{
@@ -373,6 +749,17 @@ func LogDeploymentAnnotationChanges(
 	}
 }
 
+// IsResourceAnnotation returns true for the annotations tracking the resources and the image the
+// console is rolled out with, which only differ from the live deployment to trigger a rollout.
+func IsResourceAnnotation(annotation string) bool {
+	for _, annot := range resourceAnnotations {
+		if annot == annotation {
+			return true
+		}
+	}
+	return false
+}
+
 func GetLogLevelFlag(logLevel operatorv1.LogLevel) string {
 	flag := ""
 	switch logLevel {
@@ -1,6 +1,7 @@
 package deployment
 
 import (
+	"os"
 	"testing"
 
 	"github.com/go-test/deep"
@@ -24,6 +25,8 @@ import (
 const (
 	workloadManagementAnnotation      = "target.workload.openshift.io/management"
 	workloadManagementAnnotationValue = `{"effect": "PreferredDuringScheduling"}`
+	requiredSCCAnnotation             = "openshift.io/required-scc"
+	requiredSCCAnnotationValue        = "restricted-v2"
 )
 
 func TestDefaultDeployment(t *testing.T) {
@@ -43,7 +46,11 @@ func TestDefaultDeployment(t *testing.T) {
 		authnConfig                    *configv1.Authentication
 		trustedCAConfigMap             *corev1.ConfigMap
 		oAuthClientSecret              *corev1.Secret
+		oidcSecretProviderClass        string
+		noClientSecret                 bool
 		sessionSecret                  *corev1.Secret
+		managedClusterOAuthSecret      *corev1.Secret
+		managedClusterCABundle         *corev1.ConfigMap
 		proxyConfig                    *configv1.Proxy
 		infrastructureConfig           *configv1.Infrastructure
 		canMountCustomLogo             bool
@@ -60,32 +67,6 @@ func TestDefaultDeployment(t *testing.T) {
 		Status: operatorsv1.ConsoleStatus{},
 	}
 
-	consoleDeploymentObjectMeta := metav1.ObjectMeta{
-		Name:                       api.OpenShiftConsoleName,
-		Namespace:                  api.OpenShiftConsoleNamespace,
-		GenerateName:               "",
-		SelfLink:                   "",
-		UID:                        "",
-		ResourceVersion:            "",
-		Generation:                 0,
-		CreationTimestamp:          metav1.Time{},
-		DeletionTimestamp:          nil,
-		DeletionGracePeriodSeconds: nil,
-		Labels:                     labels,
-		Annotations: map[string]string{
-			configMapResourceVersionAnnotation:             "",
-			secretResourceVersionAnnotation:                "",
-			authnCATrustConfigMapResourceVersionAnnotation: "",
-			serviceCAConfigMapResourceVersionAnnotation:    "",
-			trustedCAConfigMapResourceVersionAnnotation:    "",
-			proxyConfigResourceVersionAnnotation:           "",
-			infrastructureConfigResourceVersionAnnotation:  "",
-			consoleImageAnnotation:                         "",
-		},
-		OwnerReferences: nil,
-		Finalizers:      nil,
-	}
-
 	consoleConfig := &corev1.ConfigMap{
 		TypeMeta: metav1.TypeMeta{},
 		ObjectMeta: metav1.ObjectMeta{
@@ -122,18 +103,6 @@ func TestDefaultDeployment(t *testing.T) {
 		},
 	}
 
-	consoleDeploymentTemplateAnnotations := map[string]string{
-		configMapResourceVersionAnnotation:             "",
-		secretResourceVersionAnnotation:                "",
-		authnCATrustConfigMapResourceVersionAnnotation: "",
-		serviceCAConfigMapResourceVersionAnnotation:    "",
-		trustedCAConfigMapResourceVersionAnnotation:    "",
-		proxyConfigResourceVersionAnnotation:           "",
-		infrastructureConfigResourceVersionAnnotation:  "",
-		consoleImageAnnotation:                         "",
-		workloadManagementAnnotation:                   workloadManagementAnnotationValue,
-	}
-
 	consoleDeploymentAffinity := &corev1.Affinity{
 		PodAntiAffinity: &corev1.PodAntiAffinity{
 			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
@@ -172,6 +141,45 @@ func TestDefaultDeployment(t *testing.T) {
 		configv1.SingleReplicaTopologyMode)
 	infrastructureConfigExternalTopologyMode := infrastructureConfigWithTopology(configv1.ExternalTopologyMode,
 		configv1.HighlyAvailableTopologyMode)
+	// the versions of the resources the console of each test is rendered from
+	consoleDeploymentAnnotations := func(trustedCAConfigMap *corev1.ConfigMap, infrastructureConfig *configv1.Infrastructure) map[string]string {
+		return map[string]string{
+			configMapVersionAnnotation:             ConfigMapVersion(consoleConfig),
+			secretVersionAnnotation:                "",
+			authnCATrustConfigMapVersionAnnotation: ConfigMapVersion(&corev1.ConfigMap{Data: map[string]string{"ca-bundle.crt": "test"}}),
+			serviceCAConfigMapVersionAnnotation:    "",
+			trustedCAConfigMapVersionAnnotation:    ConfigMapVersion(trustedCAConfigMap),
+			proxyConfigVersionAnnotation:           proxyConfigVersion(proxyConfig),
+			infrastructureConfigVersionAnnotation:  infrastructureConfigVersion(infrastructureConfig),
+			consoleImageAnnotation:                 "",
+			api.ConsoleReleaseVersionAnnotation:    "",
+		}
+	}
+	consoleDeploymentObjectMeta := func(trustedCAConfigMap *corev1.ConfigMap, infrastructureConfig *configv1.Infrastructure) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Name:                       api.OpenShiftConsoleName,
+			Namespace:                  api.OpenShiftConsoleNamespace,
+			GenerateName:               "",
+			SelfLink:                   "",
+			UID:                        "",
+			ResourceVersion:            "",
+			Generation:                 0,
+			CreationTimestamp:          metav1.Time{},
+			DeletionTimestamp:          nil,
+			DeletionGracePeriodSeconds: nil,
+			Labels:                     labels,
+			Annotations:                consoleDeploymentAnnotations(trustedCAConfigMap, infrastructureConfig),
+			OwnerReferences:            nil,
+			Finalizers:                 nil,
+		}
+	}
+	consoleDeploymentTemplateAnnotations := func(trustedCAConfigMap *corev1.ConfigMap, infrastructureConfig *configv1.Infrastructure) map[string]string {
+		annotations := consoleDeploymentAnnotations(trustedCAConfigMap, infrastructureConfig)
+		annotations[workloadManagementAnnotation] = workloadManagementAnnotationValue
+		annotations[requiredSCCAnnotation] = requiredSCCAnnotationValue
+		return annotations
+	}
+
 	consoleDeploymentTemplate := resourceread.ReadDeploymentV1OrDie(bindata.MustAsset("assets/deployments/console-deployment.yaml"))
 	withConsoleContainerImage(consoleDeploymentTemplate, consoleOperatorConfig, proxyConfig)
 	withConsoleVolumes(consoleDeploymentTemplate, &corev1.ConfigMap{
@@ -215,7 +223,7 @@ func TestDefaultDeployment(t *testing.T) {
 					Kind:       "Deployment",
 					APIVersion: "apps/v1",
 				},
-				ObjectMeta: consoleDeploymentObjectMeta,
+				ObjectMeta: consoleDeploymentObjectMeta(trustedCAConfigMapEmpty, infrastructureConfigHighlyAvailable),
 				Spec: appsv1.DeploymentSpec{
 					Replicas: &defaultReplicaCount,
 
@@ -225,7 +233,7 @@ func TestDefaultDeployment(t *testing.T) {
 					Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{
 						Name:        api.OpenShiftConsoleName,
 						Labels:      labels,
-						Annotations: consoleDeploymentTemplateAnnotations,
+						Annotations: consoleDeploymentTemplateAnnotations(trustedCAConfigMapEmpty, infrastructureConfigHighlyAvailable),
 					},
 						Spec: corev1.PodSpec{
 							ServiceAccountName: "console",
@@ -297,7 +305,7 @@ func TestDefaultDeployment(t *testing.T) {
 					Kind:       "Deployment",
 					APIVersion: "apps/v1",
 				},
-				ObjectMeta: consoleDeploymentObjectMeta,
+				ObjectMeta: consoleDeploymentObjectMeta(trustedCAConfigMapSet, infrastructureConfigHighlyAvailable),
 				Spec: appsv1.DeploymentSpec{
 					Replicas: &defaultReplicaCount,
 					Selector: &metav1.LabelSelector{
@@ -306,7 +314,7 @@ func TestDefaultDeployment(t *testing.T) {
 					Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{
 						Name:        api.OpenShiftConsoleName,
 						Labels:      labels,
-						Annotations: consoleDeploymentTemplateAnnotations,
+						Annotations: consoleDeploymentTemplateAnnotations(trustedCAConfigMapSet, infrastructureConfigHighlyAvailable),
 					},
 						Spec: corev1.PodSpec{
 							ServiceAccountName: "console",
@@ -378,7 +386,7 @@ func TestDefaultDeployment(t *testing.T) {
 					Kind:       "Deployment",
 					APIVersion: "apps/v1",
 				},
-				ObjectMeta: consoleDeploymentObjectMeta,
+				ObjectMeta: consoleDeploymentObjectMeta(trustedCAConfigMapEmpty, infrastructureConfigSingleReplica),
 				Spec: appsv1.DeploymentSpec{
 					Replicas: &singleNodeReplicaCount,
 					Selector: &metav1.LabelSelector{
@@ -387,7 +395,7 @@ func TestDefaultDeployment(t *testing.T) {
 					Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{
 						Name:        api.OpenShiftConsoleName,
 						Labels:      labels,
-						Annotations: consoleDeploymentTemplateAnnotations,
+						Annotations: consoleDeploymentTemplateAnnotations(trustedCAConfigMapEmpty, infrastructureConfigSingleReplica),
 					},
 						Spec: corev1.PodSpec{
 							ServiceAccountName: "console",
@@ -452,7 +460,7 @@ func TestDefaultDeployment(t *testing.T) {
 					Kind:       "Deployment",
 					APIVersion: "apps/v1",
 				},
-				ObjectMeta: consoleDeploymentObjectMeta,
+				ObjectMeta: consoleDeploymentObjectMeta(trustedCAConfigMapEmpty, infrastructureConfigExternalTopologyMode),
 				Spec: appsv1.DeploymentSpec{
 					Replicas: &defaultReplicaCount,
 					Selector: &metav1.LabelSelector{
@@ -461,7 +469,7 @@ func TestDefaultDeployment(t *testing.T) {
 					Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{
 						Name:        api.OpenShiftConsoleName,
 						Labels:      labels,
-						Annotations: consoleDeploymentTemplateAnnotations,
+						Annotations: consoleDeploymentTemplateAnnotations(trustedCAConfigMapEmpty, infrastructureConfigExternalTopologyMode),
 					},
 						Spec: corev1.PodSpec{
 							ServiceAccountName: "console",
@@ -516,7 +524,11 @@ func TestDefaultDeployment(t *testing.T) {
 				tt.args.authServerCAConfigMap,
 				tt.args.trustedCAConfigMap,
 				tt.args.oAuthClientSecret,
+				tt.args.oidcSecretProviderClass,
+				tt.args.noClientSecret,
 				tt.args.sessionSecret,
+				tt.args.managedClusterOAuthSecret,
+				tt.args.managedClusterCABundle,
 				tt.args.proxyConfig,
 				tt.args.infrastructureConfig,
 				tt.args.canMountCustomLogo,
@@ -570,22 +582,26 @@ func TestWithConsoleAnnotations(t *testing.T) {
 		ObjectMeta: metav1.ObjectMeta{
 			ResourceVersion: "34343",
 		},
+		Data: map[string]string{"service-ca.crt": "service-ca"},
 	}
 	oauthServingCertConfigMap := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			ResourceVersion: "77777",
 		},
+		Data: map[string]string{"ca-bundle.crt": "oauth-serving-cert"},
 	}
 	trustedCAConfigMap := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			ResourceVersion: "75577",
 		},
+		Data: map[string]string{api.TrustedCABundleKey: "trusted-ca"},
 	}
 
 	oAuthClientSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			ResourceVersion: "101010",
 		},
+		Data: map[string][]byte{"clientSecret": []byte("secret")},
 	}
 
 	tests := []struct {
@@ -621,29 +637,31 @@ func TestWithConsoleAnnotations(t *testing.T) {
 			want: &appsv1.Deployment{
 				ObjectMeta: metav1.ObjectMeta{
 					Annotations: map[string]string{
-						configMapResourceVersionAnnotation:             consoleConfigMap.GetResourceVersion(),
-						serviceCAConfigMapResourceVersionAnnotation:    serviceCAConfigMap.GetResourceVersion(),
-						authnCATrustConfigMapResourceVersionAnnotation: oauthServingCertConfigMap.GetResourceVersion(),
-						trustedCAConfigMapResourceVersionAnnotation:    trustedCAConfigMap.GetResourceVersion(),
-						proxyConfigResourceVersionAnnotation:           proxyConfig.GetResourceVersion(),
-						infrastructureConfigResourceVersionAnnotation:  infrastructureConfig.GetResourceVersion(),
-						secretResourceVersionAnnotation:                oAuthClientSecret.GetResourceVersion(),
-						consoleImageAnnotation:                         util.GetImageEnv("CONSOLE_IMAGE"),
+						configMapVersionAnnotation:             ConfigMapVersion(consoleConfigMap),
+						serviceCAConfigMapVersionAnnotation:    ConfigMapVersion(serviceCAConfigMap),
+						authnCATrustConfigMapVersionAnnotation: CATrustVersion(oauthServingCertConfigMap),
+						trustedCAConfigMapVersionAnnotation:    ConfigMapVersion(trustedCAConfigMap),
+						proxyConfigVersionAnnotation:           proxyConfigVersion(proxyConfig),
+						infrastructureConfigVersionAnnotation:  infrastructureConfigVersion(infrastructureConfig),
+						secretVersionAnnotation:                SecretVersion(oAuthClientSecret),
+						consoleImageAnnotation:                 util.GetImageEnv("CONSOLE_IMAGE"),
+						api.ConsoleReleaseVersionAnnotation:    os.Getenv("RELEASE_VERSION"),
 					},
 				},
 				Spec: appsv1.DeploymentSpec{
 					Template: corev1.PodTemplateSpec{
 						ObjectMeta: metav1.ObjectMeta{
 							Annotations: map[string]string{
-								workloadManagementAnnotation:                   workloadManagementAnnotationValue,
-								configMapResourceVersionAnnotation:             consoleConfigMap.GetResourceVersion(),
-								serviceCAConfigMapResourceVersionAnnotation:    serviceCAConfigMap.GetResourceVersion(),
-								authnCATrustConfigMapResourceVersionAnnotation: oauthServingCertConfigMap.GetResourceVersion(),
-								trustedCAConfigMapResourceVersionAnnotation:    trustedCAConfigMap.GetResourceVersion(),
-								proxyConfigResourceVersionAnnotation:           proxyConfig.GetResourceVersion(),
-								infrastructureConfigResourceVersionAnnotation:  infrastructureConfig.GetResourceVersion(),
-								secretResourceVersionAnnotation:                oAuthClientSecret.GetResourceVersion(),
-								consoleImageAnnotation:                         util.GetImageEnv("CONSOLE_IMAGE"),
+								workloadManagementAnnotation:           workloadManagementAnnotationValue,
+								configMapVersionAnnotation:             ConfigMapVersion(consoleConfigMap),
+								serviceCAConfigMapVersionAnnotation:    ConfigMapVersion(serviceCAConfigMap),
+								authnCATrustConfigMapVersionAnnotation: CATrustVersion(oauthServingCertConfigMap),
+								trustedCAConfigMapVersionAnnotation:    ConfigMapVersion(trustedCAConfigMap),
+								proxyConfigVersionAnnotation:           proxyConfigVersion(proxyConfig),
+								infrastructureConfigVersionAnnotation:  infrastructureConfigVersion(infrastructureConfig),
+								secretVersionAnnotation:                SecretVersion(oAuthClientSecret),
+								consoleImageAnnotation:                 util.GetImageEnv("CONSOLE_IMAGE"),
+								api.ConsoleReleaseVersionAnnotation:    os.Getenv("RELEASE_VERSION"),
 							},
 						},
 					},
@@ -665,10 +683,12 @@ func TestWithReplicas(t *testing.T) {
 	var (
 		singleNodeReplicaCount int32 = SingleNodeConsoleReplicas
 		defaultReplicaCount    int32 = DefaultConsoleReplicas
+		pausedReplicaCount     int32 = 0
 	)
 
 	type args struct {
 		deployment           *appsv1.Deployment
+		operatorConfig       *operatorsv1.Console
 		infrastructureConfig *configv1.Infrastructure
 	}
 
@@ -742,10 +762,31 @@ func TestWithReplicas(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Test Paused console",
+			args: args{
+				deployment: &appsv1.Deployment{
+					Spec: appsv1.DeploymentSpec{},
+				},
+				operatorConfig: &operatorsv1.Console{
+					ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{api.PausedAnnotation: "true"}},
+				},
+				infrastructureConfig: infrastructureConfigHighlyAvailable,
+			},
+			want: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Replicas: &pausedReplicaCount,
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			withReplicas(tt.args.deployment, tt.args.infrastructureConfig)
+			operatorConfig := tt.args.operatorConfig
+			if operatorConfig == nil {
+				operatorConfig = &operatorsv1.Console{}
+			}
+			withReplicas(tt.args.deployment, operatorConfig, DefaultReplicas(tt.args.infrastructureConfig))
 			if diff := deep.Equal(tt.args.deployment, tt.want); diff != nil {
 				t.Error(diff)
 			}
@@ -753,6 +794,80 @@ func TestWithReplicas(t *testing.T) {
 	}
 }
 
+func TestConsoleReplicas(t *testing.T) {
+	infrastructureConfigHighlyAvailable := infrastructureConfigWithTopology(configv1.HighlyAvailableTopologyMode,
+		configv1.HighlyAvailableTopologyMode)
+	infrastructureConfigSingleReplica := infrastructureConfigWithTopology(configv1.SingleReplicaTopologyMode,
+		configv1.SingleReplicaTopologyMode)
+
+	tests := []struct {
+		name                 string
+		annotations          map[string]string
+		infrastructureConfig *configv1.Infrastructure
+		want                 int32
+		wantErr              bool
+	}{
+		{
+			name:                 "Test default Highly Available replicas",
+			infrastructureConfig: infrastructureConfigHighlyAvailable,
+			want:                 DefaultConsoleReplicas,
+		},
+		{
+			name:                 "Test requested Highly Available replicas",
+			annotations:          map[string]string{api.ReplicasAnnotation: "3"},
+			infrastructureConfig: infrastructureConfigHighlyAvailable,
+			want:                 3,
+		},
+		{
+			name:                 "Test single replica on a Highly Available topology",
+			annotations:          map[string]string{api.ReplicasAnnotation: "1"},
+			infrastructureConfig: infrastructureConfigHighlyAvailable,
+			want:                 1,
+		},
+		{
+			name:                 "Test replicas out of bounds",
+			annotations:          map[string]string{api.ReplicasAnnotation: "0"},
+			infrastructureConfig: infrastructureConfigHighlyAvailable,
+			want:                 DefaultConsoleReplicas,
+			wantErr:              true,
+		},
+		{
+			name:                 "Test unparsable replicas",
+			annotations:          map[string]string{api.ReplicasAnnotation: "three"},
+			infrastructureConfig: infrastructureConfigHighlyAvailable,
+			want:                 DefaultConsoleReplicas,
+			wantErr:              true,
+		},
+		{
+			name:                 "Test replicas on a Single Replica topology",
+			annotations:          map[string]string{api.ReplicasAnnotation: "1"},
+			infrastructureConfig: infrastructureConfigSingleReplica,
+			want:                 SingleNodeConsoleReplicas,
+		},
+		{
+			name:                 "Test too many replicas on a Single Replica topology",
+			annotations:          map[string]string{api.ReplicasAnnotation: "2"},
+			infrastructureConfig: infrastructureConfigSingleReplica,
+			want:                 SingleNodeConsoleReplicas,
+			wantErr:              true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorsv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := ConsoleReplicas(operatorConfig, tt.infrastructureConfig)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ConsoleReplicas() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestWithAffinity(t *testing.T) {
 	type args struct {
 		deployment           *appsv1.Deployment
@@ -1174,6 +1289,316 @@ func TestWithConsoleVolumes(t *testing.T) {
 	}
 }
 
+func TestWithOIDCClientSecretProviderClass(t *testing.T) {
+	oauthConfigSecretVolume := corev1.Volume{
+		Name: ConsoleOauthConfigName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: ConsoleOauthConfigName},
+		},
+	}
+	servingCertVolume := corev1.Volume{
+		Name: api.ConsoleServingCertName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: api.ConsoleServingCertName},
+		},
+	}
+	deploymentWithVolumes := func(annotations map[string]string, volumes ...corev1.Volume) *appsv1.Deployment {
+		podAnnotations := map[string]string{}
+		for k, v := range annotations {
+			podAnnotations[k] = v
+		}
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Annotations: podAnnotations},
+					Spec:       corev1.PodSpec{Volumes: volumes},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name                string
+		secretProviderClass string
+		want                *appsv1.Deployment
+	}{
+		{
+			name:                "Test client secret mounted from console-oauth-config",
+			secretProviderClass: "",
+			want:                deploymentWithVolumes(map[string]string{}, servingCertVolume, oauthConfigSecretVolume),
+		},
+		{
+			name:                "Test client secret mounted from a SecretProviderClass",
+			secretProviderClass: "vault-console-oidc",
+			want: deploymentWithVolumes(
+				map[string]string{OIDCSecretProviderClassAnnotation: "vault-console-oidc"},
+				servingCertVolume,
+				corev1.Volume{
+					Name: ConsoleOauthConfigName,
+					VolumeSource: corev1.VolumeSource{
+						CSI: &corev1.CSIVolumeSource{
+							Driver:           api.SecretsStoreCSIDriverName,
+							ReadOnly:         utilpointer.Bool(true),
+							VolumeAttributes: map[string]string{"secretProviderClass": "vault-console-oidc"},
+						},
+					},
+				},
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := deploymentWithVolumes(map[string]string{}, servingCertVolume, oauthConfigSecretVolume)
+			withOIDCClientSecretProviderClass(deployment, tt.secretProviderClass)
+			if diff := deep.Equal(deployment, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestWithoutClientSecret(t *testing.T) {
+	oauthConfigSecretVolume := corev1.Volume{
+		Name: ConsoleOauthConfigName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: ConsoleOauthConfigName},
+		},
+	}
+	servingCertVolume := corev1.Volume{
+		Name: api.ConsoleServingCertName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: api.ConsoleServingCertName},
+		},
+	}
+	oauthConfigSecretMount := corev1.VolumeMount{Name: ConsoleOauthConfigName, ReadOnly: true, MountPath: "/var/oauth-config"}
+	servingCertMount := corev1.VolumeMount{Name: api.ConsoleServingCertName, ReadOnly: true, MountPath: "/var/serving-cert"}
+	deploymentWithVolumes := func(volumes []corev1.Volume, volumeMounts []corev1.VolumeMount) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Volumes:    volumes,
+						Containers: []corev1.Container{{VolumeMounts: volumeMounts}},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		noClientSecret bool
+		want           *appsv1.Deployment
+	}{
+		{
+			name:           "Test confidential client keeps the client secret volume",
+			noClientSecret: false,
+			want: deploymentWithVolumes(
+				[]corev1.Volume{servingCertVolume, oauthConfigSecretVolume},
+				[]corev1.VolumeMount{servingCertMount, oauthConfigSecretMount},
+			),
+		},
+		{
+			name:           "Test public client drops the client secret volume",
+			noClientSecret: true,
+			want: deploymentWithVolumes(
+				[]corev1.Volume{servingCertVolume},
+				[]corev1.VolumeMount{servingCertMount},
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := deploymentWithVolumes(
+				[]corev1.Volume{servingCertVolume, oauthConfigSecretVolume},
+				[]corev1.VolumeMount{servingCertMount, oauthConfigSecretMount},
+			)
+			withoutClientSecret(deployment, tt.noClientSecret)
+			if diff := deep.Equal(deployment, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestWithAccessLogVolume(t *testing.T) {
+	tests := []struct {
+		name        string
+		accessLog   *util.AccessLogConfig
+		wantVolumes []corev1.Volume
+		wantMounts  []corev1.VolumeMount
+	}{
+		{
+			name:      "Test access logging disabled",
+			accessLog: nil,
+		},
+		{
+			name:      "Test access logs written to stdout",
+			accessLog: &util.AccessLogConfig{Format: util.AccessLogFormatCommon, Destination: util.AccessLogDestinationStdout, SamplingRatio: 1},
+		},
+		{
+			name:      "Test access logs written to a file",
+			accessLog: &util.AccessLogConfig{Format: util.AccessLogFormatJSON, Destination: util.AccessLogDestinationFile, Claim: "console-access-logs", SamplingRatio: 1},
+			wantVolumes: []corev1.Volume{
+				{
+					Name: api.AccessLogVolumeName,
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "console-access-logs"},
+					},
+				},
+			},
+			wantMounts: []corev1.VolumeMount{
+				{
+					Name:        api.AccessLogVolumeName,
+					MountPath:   api.AccessLogMountDir,
+					SubPathExpr: "$(POD_NAME)",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "console"}}},
+					},
+				},
+			}
+			withAccessLogVolume(deployment, tt.accessLog)
+			if diff := deep.Equal(deployment.Spec.Template.Spec.Volumes, tt.wantVolumes); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(deployment.Spec.Template.Spec.Containers[0].VolumeMounts, tt.wantMounts); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestWithManagedClusterOAuthVolume(t *testing.T) {
+	tests := []struct {
+		name        string
+		secret      *corev1.Secret
+		wantVolumes []corev1.Volume
+		wantMounts  []corev1.VolumeMount
+	}{
+		{
+			name:   "Test no managed cluster clients",
+			secret: nil,
+		},
+		{
+			name:   "Test managed cluster clients",
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: api.ManagedClusterOAuthSecretName}},
+			wantVolumes: []corev1.Volume{
+				{
+					Name: api.ManagedClusterOAuthSecretName,
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{SecretName: api.ManagedClusterOAuthSecretName},
+					},
+				},
+			},
+			wantMounts: []corev1.VolumeMount{
+				{
+					Name:      api.ManagedClusterOAuthSecretName,
+					ReadOnly:  true,
+					MountPath: api.ManagedClusterOAuthMountDir,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "console"}}},
+					},
+				},
+			}
+			withManagedClusterOAuthVolume(deployment, tt.secret)
+			if diff := deep.Equal(deployment.Spec.Template.Spec.Volumes, tt.wantVolumes); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(deployment.Spec.Template.Spec.Containers[0].VolumeMounts, tt.wantMounts); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestWithManagedClusterCABundle(t *testing.T) {
+	tests := []struct {
+		name            string
+		caBundle        *corev1.ConfigMap
+		wantAnnotations map[string]string
+		wantVolumes     []corev1.Volume
+		wantMounts      []corev1.VolumeMount
+		wantEnv         []corev1.EnvVar
+	}{
+		{
+			name:            "Test no managed cluster CAs",
+			caBundle:        nil,
+			wantAnnotations: map[string]string{},
+		},
+		{
+			name:            "Test managed cluster CAs",
+			caBundle:        &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: api.ManagedClusterCABundleConfigMapName, ResourceVersion: "10"}, Data: map[string]string{"cluster-a.crt": "ca"}},
+			wantAnnotations: map[string]string{managedClusterCABundleVersionAnnotation: ConfigMapVersion(&corev1.ConfigMap{Data: map[string]string{"cluster-a.crt": "ca"}})},
+			wantVolumes: []corev1.Volume{
+				{
+					Name: api.ManagedClusterCABundleConfigMapName,
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{Name: api.ManagedClusterCABundleConfigMapName},
+						},
+					},
+				},
+			},
+			wantMounts: []corev1.VolumeMount{
+				{
+					Name:      api.ManagedClusterCABundleConfigMapName,
+					ReadOnly:  true,
+					MountPath: api.ManagedClusterCABundleMountDir,
+				},
+			},
+			wantEnv: []corev1.EnvVar{
+				{Name: "SSL_CERT_DIR", Value: "/etc/pki/tls/certs:/var/managed-cluster-ca"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{}},
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{}},
+						Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "console"}}},
+					},
+				},
+			}
+			withManagedClusterCABundle(deployment, tt.caBundle)
+			if diff := deep.Equal(deployment.Annotations, tt.wantAnnotations); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(deployment.Spec.Template.Annotations, tt.wantAnnotations); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(deployment.Spec.Template.Spec.Volumes, tt.wantVolumes); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(deployment.Spec.Template.Spec.Containers[0].VolumeMounts, tt.wantMounts); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(deployment.Spec.Template.Spec.Containers[0].Env, tt.wantEnv); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
 func TestWithConsoleContainerImage(t *testing.T) {
 	type args struct {
 		deployment     *appsv1.Deployment
@@ -1721,6 +2146,352 @@ func TestWithDownloadsContainerImage(t *testing.T) {
 	}
 }
 
+func TestPodTemplateChanged(t *testing.T) {
+	deploymentWithTemplate := func(annotations map[string]string, restartPolicy corev1.RestartPolicy, container corev1.Container) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+					Spec: corev1.PodSpec{
+						RestartPolicy: restartPolicy,
+						Containers:    []corev1.Container{container},
+					},
+				},
+			},
+		}
+	}
+	console := corev1.Container{Name: "console", Image: "quay.io/openshift/console:4.16"}
+	defaultedConsole := *console.DeepCopy()
+	defaultedConsole.TerminationMessagePath = corev1.TerminationMessagePathDefault
+	defaultedConsole.ImagePullPolicy = corev1.PullIfNotPresent
+	required := deploymentWithTemplate(map[string]string{"console.openshift.io/console-config-version": "1"}, "", console)
+
+	tests := []struct {
+		name string
+		live *appsv1.Deployment
+		want bool
+	}{
+		{
+			name: "Test same pod template",
+			live: required.DeepCopy(),
+			want: false,
+		},
+		{
+			name: "Test fields defaulted by the API server are not changes",
+			live: deploymentWithTemplate(map[string]string{"console.openshift.io/console-config-version": "1"}, corev1.RestartPolicyAlways, defaultedConsole),
+			want: false,
+		},
+		{
+			name: "Test changed pod annotation",
+			live: deploymentWithTemplate(map[string]string{"console.openshift.io/console-config-version": "0"}, corev1.RestartPolicyAlways, defaultedConsole),
+			want: true,
+		},
+		{
+			name: "Test changed image",
+			live: deploymentWithTemplate(map[string]string{"console.openshift.io/console-config-version": "1"}, "", corev1.Container{Name: "console", Image: "quay.io/openshift/console:4.15"}),
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PodTemplateChanged(required, tt.live); got != tt.want {
+				t.Errorf("PodTemplateChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecurityContextDrift(t *testing.T) {
+	podSecurityContext := &corev1.PodSecurityContext{
+		RunAsNonRoot: utilpointer.Bool(true),
+		SeccompProfile: &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		},
+	}
+	containerSecurityContext := &corev1.SecurityContext{
+		AllowPrivilegeEscalation: utilpointer.Bool(false),
+		Privileged:               utilpointer.Bool(false),
+	}
+	required := &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					SecurityContext: podSecurityContext,
+					Containers: []corev1.Container{
+						{
+							Name:            "console",
+							SecurityContext: containerSecurityContext,
+						},
+					},
+				},
+			},
+		},
+	}
+	podWithSecurityContexts := func(podSecurityContext *corev1.PodSecurityContext, containerSecurityContext *corev1.SecurityContext) *corev1.Pod {
+		return &corev1.Pod{
+			Spec: corev1.PodSpec{
+				SecurityContext: podSecurityContext,
+				Containers: []corev1.Container{
+					{
+						Name:            "console",
+						SecurityContext: containerSecurityContext,
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want []string
+	}{
+		{
+			name: "Test rendered security contexts",
+			pod:  podWithSecurityContexts(podSecurityContext.DeepCopy(), containerSecurityContext.DeepCopy()),
+			want: []string{},
+		},
+		{
+			name: "Test fields filled in by admission are not drift",
+			pod: podWithSecurityContexts(&corev1.PodSecurityContext{
+				RunAsNonRoot: utilpointer.Bool(true),
+				RunAsUser:    utilpointer.Int64(1000650000),
+				SeccompProfile: &corev1.SeccompProfile{
+					Type: corev1.SeccompProfileTypeRuntimeDefault,
+				},
+			}, &corev1.SecurityContext{
+				AllowPrivilegeEscalation: utilpointer.Bool(false),
+				Privileged:               utilpointer.Bool(false),
+				Capabilities: &corev1.Capabilities{
+					Drop: []corev1.Capability{"ALL"},
+				},
+			}),
+			want: []string{},
+		},
+		{
+			name: "Test overridden pod security context",
+			pod: podWithSecurityContexts(&corev1.PodSecurityContext{
+				RunAsNonRoot: utilpointer.Bool(true),
+				SeccompProfile: &corev1.SeccompProfile{
+					Type: corev1.SeccompProfileTypeUnconfined,
+				},
+			}, containerSecurityContext.DeepCopy()),
+			want: []string{"pod"},
+		},
+		{
+			name: "Test overridden container security context",
+			pod: podWithSecurityContexts(podSecurityContext.DeepCopy(), &corev1.SecurityContext{
+				AllowPrivilegeEscalation: utilpointer.Bool(true),
+				Privileged:               utilpointer.Bool(false),
+			}),
+			want: []string{"container console"},
+		},
+		{
+			name: "Test dropped container security context",
+			pod:  podWithSecurityContexts(podSecurityContext.DeepCopy(), nil),
+			want: []string{"container console"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(SecurityContextDrift(required, tt.pod), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestCanaryDeployment(t *testing.T) {
+	consoleReplicas := int32(2)
+	consoleDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        api.OpenShiftConsoleName,
+			Namespace:   api.OpenShiftConsoleNamespace,
+			Labels:      util.LabelsForConsole(),
+			Annotations: map[string]string{configMapVersionAnnotation: "100"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &consoleReplicas,
+			Selector: &metav1.LabelSelector{MatchLabels: util.LabelsForConsole()},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      util.LabelsForConsole(),
+					Annotations: map[string]string{configMapVersionAnnotation: "100"},
+				},
+				Spec: corev1.PodSpec{
+					Affinity: &corev1.Affinity{},
+					Volumes: []corev1.Volume{
+						{
+							Name: api.ConsoleServingCertName,
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{SecretName: api.ConsoleServingCertName},
+							},
+						},
+						{
+							Name: api.OpenShiftConsoleConfigMapName,
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{Name: api.OpenShiftConsoleConfigMapName},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	canaryConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            api.OpenShiftConsoleCanaryConfigMapName,
+			ResourceVersion: "200",
+		},
+		Data: map[string]string{"console-config.yaml": "kind: ConsoleConfig"},
+	}
+
+	canaryReplicas := int32(1)
+	want := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        api.OpenShiftConsoleCanaryName,
+			Namespace:   api.OpenShiftConsoleNamespace,
+			Labels:      util.LabelsForCanary(),
+			Annotations: map[string]string{configMapVersionAnnotation: ConfigMapVersion(canaryConfigMap)},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &canaryReplicas,
+			Selector: &metav1.LabelSelector{MatchLabels: util.LabelsForCanary()},
+			Strategy: appsv1.DeploymentStrategy{RollingUpdate: &appsv1.RollingUpdateDeployment{}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      util.LabelsForCanary(),
+					Annotations: map[string]string{configMapVersionAnnotation: ConfigMapVersion(canaryConfigMap)},
+				},
+				Spec: corev1.PodSpec{
+					Volumes: []corev1.Volume{
+						{
+							Name: api.ConsoleServingCertName,
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{SecretName: api.CanaryServingCertName},
+							},
+						},
+						{
+							Name: api.OpenShiftConsoleConfigMapName,
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{Name: api.OpenShiftConsoleCanaryConfigMapName},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	got := CanaryDeployment(consoleDeployment, canaryConfigMap)
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Error(diff)
+	}
+	// the console deployment is left untouched
+	if diff := deep.Equal(consoleDeployment.Spec.Template.Spec.Volumes[1].ConfigMap.Name, api.OpenShiftConsoleConfigMapName); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestGreenDeployment(t *testing.T) {
+	replicas := int32(2)
+	antiAffinity := func(selector *metav1.LabelSelector) *corev1.Affinity {
+		return &corev1.Affinity{
+			PodAntiAffinity: &corev1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+					LabelSelector: selector,
+					TopologyKey:   "kubernetes.io/hostname",
+				}},
+			},
+		}
+	}
+	volumes := func(servingCert, configMap string) []corev1.Volume {
+		return []corev1.Volume{
+			{
+				Name: api.ConsoleServingCertName,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{SecretName: servingCert},
+				},
+			},
+			{
+				Name: api.OpenShiftConsoleConfigMapName,
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: configMap},
+					},
+				},
+			},
+		}
+	}
+	consoleDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        api.OpenShiftConsoleName,
+			Namespace:   api.OpenShiftConsoleNamespace,
+			Labels:      util.LabelsForConsole(),
+			Annotations: map[string]string{configMapVersionAnnotation: ""},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: util.LabelsForConsole()},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      util.LabelsForConsole(),
+					Annotations: map[string]string{configMapVersionAnnotation: ""},
+				},
+				Spec: corev1.PodSpec{
+					Affinity: antiAffinity(&metav1.LabelSelector{
+						MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "component", Operator: metav1.LabelSelectorOpIn, Values: []string{"ui"}}},
+					}),
+					Volumes: volumes(api.ConsoleServingCertName, api.OpenShiftConsoleConfigMapName),
+				},
+			},
+		},
+	}
+	greenConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            api.OpenShiftConsoleGreenConfigMapName,
+			ResourceVersion: "300",
+		},
+		Data: map[string]string{"console-config.yaml": "kind: ConsoleConfig"},
+	}
+
+	want := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        api.OpenShiftConsoleGreenName,
+			Namespace:   api.OpenShiftConsoleNamespace,
+			Labels:      util.LabelsForGreen(),
+			Annotations: map[string]string{configMapVersionAnnotation: ConfigMapVersion(greenConfigMap)},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: util.LabelsForGreen()},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      util.LabelsForGreen(),
+					Annotations: map[string]string{configMapVersionAnnotation: ConfigMapVersion(greenConfigMap)},
+				},
+				Spec: corev1.PodSpec{
+					Affinity: antiAffinity(&metav1.LabelSelector{MatchLabels: util.LabelsForGreen()}),
+					Volumes:  volumes(api.GreenServingCertName, api.OpenShiftConsoleGreenConfigMapName),
+				},
+			},
+		},
+	}
+
+	got := GreenDeployment(consoleDeployment, greenConfigMap)
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Error(diff)
+	}
+	// the rendered deployment is left untouched
+	if diff := deep.Equal(consoleDeployment.Spec.Template.Spec.Volumes[1].ConfigMap.Name, api.OpenShiftConsoleConfigMapName); diff != nil {
+		t.Error(diff)
+	}
+}
+
 func TestStub(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,123 @@
+package deployment
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+const minAvailableParameter = "minAvailable"
+
+// DisruptionBudget is the budget of the console PodDisruptionBudget, either MinAvailable or
+// MaxUnavailable is set.
+type DisruptionBudget struct {
+	MinAvailable   *intstr.IntOrString
+	MaxUnavailable *intstr.IntOrString
+	// Relaxed is set when the requested budget was replaced by the default one, it would not have
+	// let a single console pod be evicted on a single node or compact cluster
+	Relaxed bool
+}
+
+// DefaultDisruptionBudget lets a single console pod be unavailable at a time.
+func DefaultDisruptionBudget() DisruptionBudget {
+	maxUnavailable := intstr.FromInt32(1)
+	return DisruptionBudget{MaxUnavailable: &maxUnavailable}
+}
+
+// IsCompactCluster is true when every node of the cluster is a control plane node, draining one
+// of them leaves the console pods no other node to run on.
+func IsCompactCluster(nodes []*corev1.Node) bool {
+	for _, node := range nodes {
+		if _, ok := node.Labels[api.NodeRoleMasterLabel]; !ok {
+			return false
+		}
+	}
+	return len(nodes) > 0
+}
+
+// GetDisruptionBudget parses the pod-disruption-budget annotation of the operator config, a single
+// '<minAvailable|maxUnavailable>=<replicas|percentage>' entry. An invalid budget falls back to the
+// default one and is reported in the returned error. On single node and compact clusters a budget
+// that would let none of the console replicas be evicted is relaxed to the default one, so that
+// node drains do not get stuck on the console.
+func GetDisruptionBudget(operatorConfig *operatorv1.Console, infrastructureConfig *configv1.Infrastructure, nodes []*corev1.Node) (DisruptionBudget, error) {
+	value, ok := operatorConfig.Annotations[api.PodDisruptionBudgetAnnotation]
+	if !ok {
+		return DefaultDisruptionBudget(), nil
+	}
+
+	budget := DisruptionBudget{}
+	parameter, parameterValue, found := strings.Cut(value, "=")
+	replicas := intstr.Parse(strings.TrimSpace(parameterValue))
+	switch strings.TrimSpace(parameter) {
+	case minAvailableParameter:
+		budget.MinAvailable = &replicas
+	case maxUnavailableParameter:
+		budget.MaxUnavailable = &replicas
+	default:
+		found = false
+	}
+	if !found || !validDisruptionBudgetParameter(replicas) {
+		return DefaultDisruptionBudget(), fmt.Errorf("invalid pod disruption budget, expected %s to be <minAvailable|maxUnavailable>=<replicas|percentage>: %s", api.PodDisruptionBudgetAnnotation, value)
+	}
+
+	if ShouldDeployHA(infrastructureConfig) && !IsCompactCluster(nodes) {
+		return budget, nil
+	}
+	if disruptionsAllowed(budget, expectedConsoleReplicas(operatorConfig, infrastructureConfig)) {
+		return budget, nil
+	}
+	relaxed := DefaultDisruptionBudget()
+	relaxed.Relaxed = true
+	return relaxed, nil
+}
+
+// validDisruptionBudgetParameter accepts a number of replicas or a percentage of at most 100% of
+// the replicas.
+func validDisruptionBudgetParameter(replicas intstr.IntOrString) bool {
+	if replicas.Type == intstr.Int {
+		return replicas.IntVal >= 0
+	}
+	percentage, err := intstr.GetScaledValueFromIntOrPercent(&replicas, 100, false)
+	return err == nil && percentage >= 0 && percentage <= 100
+}
+
+// expectedConsoleReplicas are the fewest replicas the console runs, the minimum replicas of an
+// autoscaled console.
+func expectedConsoleReplicas(operatorConfig *operatorv1.Console, infrastructureConfig *configv1.Infrastructure) int {
+	if IsPaused(operatorConfig) {
+		return 0
+	}
+	if IsAutoscaled(operatorConfig, infrastructureConfig) {
+		autoscalingConfig, _ := GetAutoscalingConfig(operatorConfig, infrastructureConfig)
+		return int(autoscalingConfig.MinReplicas)
+	}
+	replicas, _ := ConsoleReplicas(operatorConfig, infrastructureConfig)
+	return int(replicas)
+}
+
+// disruptionsAllowed rounds percentages up, like the disruption controller does.
+func disruptionsAllowed(budget DisruptionBudget, replicas int) bool {
+	if replicas == 0 {
+		return true
+	}
+	if budget.MinAvailable != nil {
+		minAvailable, err := intstr.GetScaledValueFromIntOrPercent(budget.MinAvailable, replicas, true)
+		return err == nil && minAvailable < replicas
+	}
+	maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(budget.MaxUnavailable, replicas, true)
+	return err == nil && maxUnavailable > 0
+}
+
+// WithDisruptionBudget sets the budget of the console PodDisruptionBudget.
+func WithDisruptionBudget(pdb *policyv1.PodDisruptionBudget, budget DisruptionBudget) {
+	pdb.Spec.MinAvailable = budget.MinAvailable
+	pdb.Spec.MaxUnavailable = budget.MaxUnavailable
+}
@@ -0,0 +1,115 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestGetDisruptionBudget(t *testing.T) {
+	infrastructureConfigHighlyAvailable := infrastructureConfigWithTopology(configv1.HighlyAvailableTopologyMode, configv1.HighlyAvailableTopologyMode)
+	infrastructureConfigSingleReplica := infrastructureConfigWithTopology(configv1.SingleReplicaTopologyMode, configv1.SingleReplicaTopologyMode)
+	node := func(name string, roles ...string) *corev1.Node {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}}}
+		for _, role := range roles {
+			node.Labels["node-role.kubernetes.io/"+role] = ""
+		}
+		return node
+	}
+	compactNodes := []*corev1.Node{node("a", "master", "worker"), node("b", "master", "worker"), node("c", "master", "worker")}
+	nodes := append([]*corev1.Node{node("d", "worker")}, compactNodes...)
+	replicas := func(value string) *intstr.IntOrString {
+		replicas := intstr.Parse(value)
+		return &replicas
+	}
+	relaxed := DefaultDisruptionBudget()
+	relaxed.Relaxed = true
+
+	tests := []struct {
+		name                 string
+		annotations          map[string]string
+		infrastructureConfig *configv1.Infrastructure
+		nodes                []*corev1.Node
+		want                 DisruptionBudget
+		wantErr              bool
+	}{
+		{
+			name:                 "Test default pod disruption budget",
+			infrastructureConfig: infrastructureConfigHighlyAvailable,
+			nodes:                nodes,
+			want:                 DefaultDisruptionBudget(),
+		},
+		{
+			name:                 "Test min available pod disruption budget",
+			annotations:          map[string]string{api.PodDisruptionBudgetAnnotation: "minAvailable=50%"},
+			infrastructureConfig: infrastructureConfigHighlyAvailable,
+			nodes:                nodes,
+			want:                 DisruptionBudget{MinAvailable: replicas("50%")},
+		},
+		{
+			name:                 "Test pod disruption budget blocking drains is kept on a highly available cluster",
+			annotations:          map[string]string{api.PodDisruptionBudgetAnnotation: "minAvailable=100%"},
+			infrastructureConfig: infrastructureConfigHighlyAvailable,
+			nodes:                nodes,
+			want:                 DisruptionBudget{MinAvailable: replicas("100%")},
+		},
+		{
+			name:                 "Test pod disruption budget blocking drains is relaxed on a compact cluster",
+			annotations:          map[string]string{api.PodDisruptionBudgetAnnotation: "minAvailable=2"},
+			infrastructureConfig: infrastructureConfigHighlyAvailable,
+			nodes:                compactNodes,
+			want:                 relaxed,
+		},
+		{
+			name:                 "Test pod disruption budget allowing drains is kept on a compact cluster",
+			annotations:          map[string]string{api.PodDisruptionBudgetAnnotation: "maxUnavailable=50%", api.ReplicasAnnotation: "3"},
+			infrastructureConfig: infrastructureConfigHighlyAvailable,
+			nodes:                compactNodes,
+			want:                 DisruptionBudget{MaxUnavailable: replicas("50%")},
+		},
+		{
+			name:                 "Test pod disruption budget blocking drains is relaxed on a single node cluster",
+			annotations:          map[string]string{api.PodDisruptionBudgetAnnotation: "maxUnavailable=0"},
+			infrastructureConfig: infrastructureConfigSingleReplica,
+			nodes:                []*corev1.Node{node("a", "master", "worker")},
+			want:                 relaxed,
+		},
+		{
+			name:                 "Test invalid pod disruption budget",
+			annotations:          map[string]string{api.PodDisruptionBudgetAnnotation: "minAvailable=150%"},
+			infrastructureConfig: infrastructureConfigHighlyAvailable,
+			nodes:                nodes,
+			want:                 DefaultDisruptionBudget(),
+			wantErr:              true,
+		},
+		{
+			name:                 "Test unknown pod disruption budget",
+			annotations:          map[string]string{api.PodDisruptionBudgetAnnotation: "maxAvailable=1"},
+			infrastructureConfig: infrastructureConfigHighlyAvailable,
+			nodes:                nodes,
+			want:                 DefaultDisruptionBudget(),
+			wantErr:              true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorsv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := GetDisruptionBudget(operatorConfig, tt.infrastructureConfig, tt.nodes)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetDisruptionBudget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
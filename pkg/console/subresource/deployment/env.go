@@ -0,0 +1,87 @@
+package deployment
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+// reservedEnvNames are the environment variables the operator sets on the console container, from
+// the cluster proxy, the pod and the managed cluster CAs
+var reservedEnvNames = map[string]bool{
+	"HTTPS_PROXY":  true,
+	"HTTP_PROXY":   true,
+	"NO_PROXY":     true,
+	"POD_NAME":     true,
+	"SSL_CERT_DIR": true,
+}
+
+// GetConsoleEnv parses the console-env annotation of the operator config, a JSON array of
+// environment variables with either a value or a valueFrom source, eg. the BRIDGE_ variables the
+// console reads its flags from. The variables the operator sets are never replaced: they are left
+// out like invalid variables and reported in the returned error.
+func GetConsoleEnv(operatorConfig *operatorv1.Console) ([]corev1.EnvVar, error) {
+	value, ok := operatorConfig.Annotations[api.ConsoleEnvAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	requested := []corev1.EnvVar{}
+	if err := json.Unmarshal([]byte(value), &requested); err != nil {
+		return nil, fmt.Errorf("invalid %s, expected a JSON array of environment variables: %v", api.ConsoleEnvAnnotation, err)
+	}
+
+	env := []corev1.EnvVar{}
+	invalid := []string{}
+	names := map[string]bool{}
+	for _, envVar := range requested {
+		if reservedEnvNames[envVar.Name] || names[envVar.Name] || len(validation.IsEnvVarName(envVar.Name)) > 0 || !validEnvVarSource(envVar) {
+			invalid = append(invalid, fmt.Sprintf("%q", envVar.Name))
+			continue
+		}
+		names[envVar.Name] = true
+		env = append(env, envVar)
+	}
+	if len(invalid) > 0 {
+		return env, fmt.Errorf("invalid console environment variables, the variables the operator sets cannot be replaced and a variable needs a unique name and either a value or a single valueFrom source: %s", strings.Join(invalid, ", "))
+	}
+	return env, nil
+}
+
+func validEnvVarSource(envVar corev1.EnvVar) bool {
+	source := envVar.ValueFrom
+	if source == nil {
+		return true
+	}
+	if len(envVar.Value) > 0 {
+		return false
+	}
+	sources := 0
+	if source.FieldRef != nil {
+		sources++
+	}
+	if source.ResourceFieldRef != nil {
+		sources++
+	}
+	if source.ConfigMapKeyRef != nil {
+		sources++
+	}
+	if source.SecretKeyRef != nil {
+		sources++
+	}
+	return sources == 1
+}
+
+// withConsoleEnv adds the valid requested environment variables to the console container, after
+// the ones the operator sets. Invalid variables are reported by the operator.
+func withConsoleEnv(deployment *appsv1.Deployment, operatorConfig *operatorv1.Console) {
+	env, _ := GetConsoleEnv(operatorConfig)
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	container.Env = append(container.Env, env...)
+}
@@ -0,0 +1,70 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestGetConsoleEnv(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        []corev1.EnvVar
+		wantErr     bool
+	}{
+		{
+			name: "Test no console environment variables",
+			want: nil,
+		},
+		{
+			name: "Test console environment variables",
+			annotations: map[string]string{
+				api.ConsoleEnvAnnotation: `[{"name": "BRIDGE_K8S_MODE_OFF_CLUSTER_ALERTMANAGER", "value": "https://alertmanager.example.com"}, {"name": "FEATURE_TOKEN", "valueFrom": {"secretKeyRef": {"name": "console-features", "key": "token"}}}]`,
+			},
+			want: []corev1.EnvVar{
+				{Name: "BRIDGE_K8S_MODE_OFF_CLUSTER_ALERTMANAGER", Value: "https://alertmanager.example.com"},
+				{Name: "FEATURE_TOKEN", ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "console-features"}, Key: "token"},
+				}},
+			},
+		},
+		{
+			name: "Test operator environment variables are never replaced",
+			annotations: map[string]string{
+				api.ConsoleEnvAnnotation: `[{"name": "HTTPS_PROXY", "value": "https://proxy.example.com"}, {"name": "FEATURE", "value": "on"}, {"name": "FEATURE", "value": "off"}, {"name": "1FEATURE"}, {"name": "BOTH", "value": "on", "valueFrom": {"fieldRef": {"fieldPath": "metadata.name"}}}]`,
+			},
+			want: []corev1.EnvVar{
+				{Name: "FEATURE", Value: "on"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Test malformed console environment variables",
+			annotations: map[string]string{
+				api.ConsoleEnvAnnotation: `FEATURE=on`,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorsv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := GetConsoleEnv(operatorConfig)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetConsoleEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
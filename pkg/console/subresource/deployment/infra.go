@@ -0,0 +1,93 @@
+package deployment
+
+import (
+	"fmt"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+// IsInfraNodePlacementEnabled is true when the operator config opts in to running the console and
+// downloads pods on the infra nodes of the cluster.
+func IsInfraNodePlacementEnabled(operatorConfig *operatorv1.Console) bool {
+	enabled, err := strconv.ParseBool(operatorConfig.Annotations[api.InfraNodePlacementAnnotation])
+	return err == nil && enabled
+}
+
+// IsInfraNode is true for a node carrying the infra node role label.
+func IsInfraNode(node *corev1.Node) bool {
+	_, ok := node.Labels[api.NodeRoleInfraLabel]
+	return ok
+}
+
+// GetInfraNodePlacement returns whether the pods of the deployment are moved to the infra nodes:
+// the operator config opts in, no node selector is requested by the node selector annotation and
+// the cluster has infra nodes. The replicas of a deployment spreading them across nodes need a
+// schedulable infra node each, with fewer the pods keep the default placement and the missing
+// nodes are reported in the returned error.
+func GetInfraNodePlacement(operatorConfig *operatorv1.Console, deployment *appsv1.Deployment, nodes []*corev1.Node) (bool, error) {
+	if !IsInfraNodePlacementEnabled(operatorConfig) {
+		return false, nil
+	}
+	if _, ok := operatorConfig.Annotations[api.NodeSelectorAnnotation]; ok {
+		return false, nil
+	}
+	infraNodes := 0
+	for _, node := range nodes {
+		if IsInfraNode(node) && !node.Spec.Unschedulable {
+			infraNodes++
+		}
+	}
+	if infraNodes == 0 {
+		return false, nil
+	}
+	if required := infraNodesRequired(deployment); infraNodes < required {
+		return false, fmt.Errorf("%s requires %d schedulable infra nodes for the %s replicas spread across nodes, found %d: keeping the default placement", api.InfraNodePlacementAnnotation, required, deployment.Name, infraNodes)
+	}
+	return true, nil
+}
+
+// infraNodesRequired is one node per replica when the pods of the deployment cannot share a node,
+// a single node otherwise.
+func infraNodesRequired(deployment *appsv1.Deployment) int {
+	affinity := deployment.Spec.Template.Spec.Affinity
+	if affinity == nil || affinity.PodAntiAffinity == nil || len(affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution) == 0 {
+		return 1
+	}
+	if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas < 1 {
+		return 1
+	}
+	return int(*deployment.Spec.Replicas)
+}
+
+// WithInfraNodePlacement moves the pods of the deployment to the infra nodes, tolerating the infra
+// node role taint these nodes are usually given to keep other workloads off them. The operating
+// system of the nodes is still selected. A deployment that cannot be moved is reported by its
+// controller.
+func WithInfraNodePlacement(deployment *appsv1.Deployment, operatorConfig *operatorv1.Console, nodes []*corev1.Node) {
+	if ok, _ := GetInfraNodePlacement(operatorConfig, deployment, nodes); !ok {
+		return
+	}
+	podSpec := &deployment.Spec.Template.Spec
+	nodeSelector := map[string]string{api.NodeRoleInfraLabel: ""}
+	if operatingSystem, ok := podSpec.NodeSelector[api.NodeOperatingSystemLabel]; ok {
+		nodeSelector[api.NodeOperatingSystemLabel] = operatingSystem
+	}
+	podSpec.NodeSelector = nodeSelector
+	podSpec.Tolerations = append(podSpec.Tolerations,
+		corev1.Toleration{
+			Key:      api.NodeRoleInfraLabel,
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+		},
+		corev1.Toleration{
+			Key:      api.NodeRoleInfraLabel,
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoExecute,
+		},
+	)
+}
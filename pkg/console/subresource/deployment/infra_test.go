@@ -0,0 +1,191 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestWithInfraNodePlacement(t *testing.T) {
+	masterToleration := corev1.Toleration{
+		Key:      api.NodeRoleMasterLabel,
+		Operator: corev1.TolerationOpExists,
+		Effect:   corev1.TaintEffectNoSchedule,
+	}
+	infraTolerations := []corev1.Toleration{
+		masterToleration,
+		{
+			Key:      api.NodeRoleInfraLabel,
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+		},
+		{
+			Key:      api.NodeRoleInfraLabel,
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoExecute,
+		},
+	}
+	defaultPodSpec := corev1.PodSpec{
+		NodeSelector: map[string]string{api.NodeRoleMasterLabel: ""},
+		Tolerations:  []corev1.Toleration{masterToleration},
+	}
+	node := func(name string, unschedulable bool, roles ...string) *corev1.Node {
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+		}
+		for _, role := range roles {
+			node.Labels[role] = ""
+		}
+		return node
+	}
+	withInfraNodes := []*corev1.Node{
+		node("master-0", false, api.NodeRoleMasterLabel),
+		node("infra-0", false, api.NodeRoleInfraLabel),
+	}
+
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		nodeSelector map[string]string
+		nodes        []*corev1.Node
+		want         corev1.PodSpec
+	}{
+		{
+			name:  "Test default placement when not opted in",
+			nodes: withInfraNodes,
+			want:  defaultPodSpec,
+		},
+		{
+			name:        "Test default placement without infra nodes",
+			annotations: map[string]string{api.InfraNodePlacementAnnotation: "true"},
+			nodes:       []*corev1.Node{node("master-0", false, api.NodeRoleMasterLabel)},
+			want:        defaultPodSpec,
+		},
+		{
+			name:        "Test default placement when the infra nodes are cordoned",
+			annotations: map[string]string{api.InfraNodePlacementAnnotation: "true"},
+			nodes:       []*corev1.Node{node("master-0", false, api.NodeRoleMasterLabel), node("infra-0", true, api.NodeRoleInfraLabel)},
+			want:        defaultPodSpec,
+		},
+		{
+			name:        "Test infra node placement",
+			annotations: map[string]string{api.InfraNodePlacementAnnotation: "true"},
+			nodes:       withInfraNodes,
+			want: corev1.PodSpec{
+				NodeSelector: map[string]string{api.NodeRoleInfraLabel: ""},
+				Tolerations:  infraTolerations,
+			},
+		},
+		{
+			name:         "Test infra node placement keeps the operating system",
+			annotations:  map[string]string{api.InfraNodePlacementAnnotation: "true"},
+			nodeSelector: map[string]string{api.NodeOperatingSystemLabel: "linux"},
+			nodes:        withInfraNodes,
+			want: corev1.PodSpec{
+				NodeSelector: map[string]string{api.NodeRoleInfraLabel: "", api.NodeOperatingSystemLabel: "linux"},
+				Tolerations:  infraTolerations,
+			},
+		},
+		{
+			name: "Test requested node selector takes precedence",
+			annotations: map[string]string{
+				api.InfraNodePlacementAnnotation: "true",
+				api.NodeSelectorAnnotation:       "node-role.kubernetes.io/worker=",
+			},
+			nodes: withInfraNodes,
+			want:  defaultPodSpec,
+		},
+		{
+			name:        "Test invalid opt-in keeps the default placement",
+			annotations: map[string]string{api.InfraNodePlacementAnnotation: "infra"},
+			nodes:       withInfraNodes,
+			want:        defaultPodSpec,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorsv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			deployment := &appsv1.Deployment{}
+			deployment.Spec.Template.Spec = *defaultPodSpec.DeepCopy()
+			if tt.nodeSelector != nil {
+				deployment.Spec.Template.Spec.NodeSelector = tt.nodeSelector
+			}
+			WithInfraNodePlacement(deployment, operatorConfig, tt.nodes)
+			if diff := deep.Equal(deployment.Spec.Template.Spec, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestGetInfraNodePlacement(t *testing.T) {
+	infraNode := func(name string, unschedulable bool) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{api.NodeRoleInfraLabel: ""}},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+		}
+	}
+	optedIn := &operatorsv1.Console{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{api.InfraNodePlacementAnnotation: "true"}},
+	}
+	deployment := func(replicas int32, topology configv1.TopologyMode) *appsv1.Deployment {
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: api.OpenShiftConsoleDeploymentName},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		}
+		withAffinity(deployment, infrastructureConfigWithTopology(topology, topology), "ui")
+		return deployment
+	}
+
+	tests := []struct {
+		name       string
+		deployment *appsv1.Deployment
+		nodes      []*corev1.Node
+		want       bool
+		wantErr    bool
+	}{
+		{
+			name:       "Test replicas spread across as many infra nodes",
+			deployment: deployment(2, configv1.HighlyAvailableTopologyMode),
+			nodes:      []*corev1.Node{infraNode("infra-0", false), infraNode("infra-1", false)},
+			want:       true,
+		},
+		{
+			name:       "Test replicas spread across fewer infra nodes keep the default placement",
+			deployment: deployment(2, configv1.HighlyAvailableTopologyMode),
+			nodes:      []*corev1.Node{infraNode("infra-0", false), infraNode("infra-1", true)},
+			wantErr:    true,
+		},
+		{
+			name:       "Test single replica topology needs a single infra node",
+			deployment: deployment(1, configv1.SingleReplicaTopologyMode),
+			nodes:      []*corev1.Node{infraNode("infra-0", false)},
+			want:       true,
+		},
+		{
+			name:       "Test no infra nodes keep the default placement",
+			deployment: deployment(2, configv1.HighlyAvailableTopologyMode),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetInfraNodePlacement(optedIn, tt.deployment, tt.nodes)
+			if got != tt.want {
+				t.Errorf("GetInfraNodePlacement() = %v, want %v", got, tt.want)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetInfraNodePlacement() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
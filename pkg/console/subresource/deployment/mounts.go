@@ -0,0 +1,150 @@
+package deployment
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+const (
+	consoleMountConfigMapKind = "configmap"
+	consoleMountSecretKind    = "secret"
+)
+
+// reservedMountDirs are the directories the operator mounts into the console container, or the
+// console reads its system trust and service account from. No mount can be made in or over them.
+var reservedMountDirs = []string{
+	"/var/serving-cert",
+	"/var/oauth-config",
+	"/var/console-config",
+	"/var/service-ca",
+	"/var/logo",
+	"/var/oauth-serving-cert",
+	"/var/run/secrets",
+	api.AccessLogMountDir,
+	api.AuthServerCAMountDir,
+	api.ManagedClusterCABundleMountDir,
+	api.ManagedClusterOAuthMountDir,
+	api.SessionSecretMountDir,
+	api.TrustedCABundleMountDir,
+	systemCertDir,
+}
+
+// ConsoleMount is a configmap or a secret of the console namespace mounted into the console
+// container, eg. a corporate CA bundle or a kerberos config.
+type ConsoleMount struct {
+	// Secret mounts a secret rather than a configmap
+	Secret    bool
+	Name      string
+	MountPath string
+	// Version is the content hash of the mounted configmap or secret, set once it is found
+	Version string
+}
+
+// GetConsoleMounts parses the console-mounts annotation of the operator config,
+// '<configmap|secret>/<name>:<mount path>' entries of configmaps and secrets of the console
+// namespace. A mount path is absolute and cannot be in or over another mount, or a directory of
+// the console. Invalid entries are left out and reported in the returned error.
+func GetConsoleMounts(operatorConfig *operatorv1.Console) ([]ConsoleMount, error) {
+	value, ok := operatorConfig.Annotations[api.ConsoleMountsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	mounts := []ConsoleMount{}
+	invalid := []string{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+		mount, ok := parseConsoleMount(entry)
+		if !ok || overlapsMounts(mount.MountPath, reservedMountDirs) {
+			invalid = append(invalid, entry)
+			continue
+		}
+		mountPaths := []string{}
+		for _, m := range mounts {
+			mountPaths = append(mountPaths, m.MountPath)
+		}
+		if overlapsMounts(mount.MountPath, mountPaths) {
+			invalid = append(invalid, entry)
+			continue
+		}
+		mounts = append(mounts, mount)
+	}
+	if len(invalid) > 0 {
+		return mounts, fmt.Errorf("invalid %s, expected <configmap|secret>/<name>:<absolute mount path> entries not in or over another mount: %s", api.ConsoleMountsAnnotation, strings.Join(invalid, ", "))
+	}
+	return mounts, nil
+}
+
+func parseConsoleMount(entry string) (ConsoleMount, bool) {
+	source, mountPath, found := strings.Cut(entry, ":")
+	kind, name, _ := strings.Cut(source, "/")
+	mount := ConsoleMount{
+		Name:      strings.TrimSpace(name),
+		MountPath: strings.TrimSpace(mountPath),
+	}
+	switch strings.TrimSpace(kind) {
+	case consoleMountConfigMapKind:
+	case consoleMountSecretKind:
+		mount.Secret = true
+	default:
+		return ConsoleMount{}, false
+	}
+	if !found || len(validation.IsDNS1123Subdomain(mount.Name)) > 0 {
+		return ConsoleMount{}, false
+	}
+	if !path.IsAbs(mount.MountPath) || path.Clean(mount.MountPath) != mount.MountPath || mount.MountPath == "/" {
+		return ConsoleMount{}, false
+	}
+	return mount, true
+}
+
+// overlapsMounts is true when mountPath is one of mountPaths, or in or over one of them.
+func overlapsMounts(mountPath string, mountPaths []string) bool {
+	for _, p := range mountPaths {
+		p = path.Clean(p)
+		if mountPath == p || strings.HasPrefix(mountPath, p+"/") || strings.HasPrefix(p, mountPath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// WithConsoleMounts mounts the configmaps and secrets read-only into the console container. Their
+// content hashes roll the console out, so that eg. a renewed CA bundle is picked up.
+func WithConsoleMounts(deployment *appsv1.Deployment, mounts []ConsoleMount) {
+	if len(mounts) == 0 {
+		return
+	}
+
+	deployment.ObjectMeta.Annotations[consoleMountsVersionAnnotation] = contentHash(mounts)
+	deployment.Spec.Template.ObjectMeta.Annotations[consoleMountsVersionAnnotation] = contentHash(mounts)
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	for i, mount := range mounts {
+		// the configmap and secret names may be too long or have dots for a volume name
+		volume := corev1.Volume{Name: fmt.Sprintf("console-mount-%d", i)}
+		if mount.Secret {
+			volume.Secret = &corev1.SecretVolumeSource{SecretName: mount.Name}
+		} else {
+			volume.ConfigMap = &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: mount.Name},
+			}
+		}
+		deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, volume)
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      volume.Name,
+			ReadOnly:  true,
+			MountPath: mount.MountPath,
+		})
+	}
+}
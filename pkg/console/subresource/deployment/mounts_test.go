@@ -0,0 +1,125 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestGetConsoleMounts(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        []ConsoleMount
+		wantErr     bool
+	}{
+		{
+			name: "Test no mounts",
+		},
+		{
+			name:        "Test configmap and secret mounts",
+			annotations: map[string]string{api.ConsoleMountsAnnotation: "configmap/corporate-ca:/etc/corporate-ca, secret/krb5.conf:/etc/krb5"},
+			want: []ConsoleMount{
+				{Name: "corporate-ca", MountPath: "/etc/corporate-ca"},
+				{Secret: true, Name: "krb5.conf", MountPath: "/etc/krb5"},
+			},
+		},
+		{
+			name:        "Test invalid mounts are left out",
+			annotations: map[string]string{api.ConsoleMountsAnnotation: "configmap/corporate-ca:/etc/corporate-ca,pod/console:/etc/pod,secret/Krb5:/etc/krb5,configmap/ca:etc/ca,configmap/ca:/etc/ca/../ca,secret/krb5:/"},
+			want: []ConsoleMount{
+				{Name: "corporate-ca", MountPath: "/etc/corporate-ca"},
+			},
+			wantErr: true,
+		},
+		{
+			name:        "Test mounts in or over the console directories are left out",
+			annotations: map[string]string{api.ConsoleMountsAnnotation: "configmap/ca:/var/service-ca/extra,configmap/ca:/etc/pki,secret/oauth:/var/oauth-config"},
+			want:        []ConsoleMount{},
+			wantErr:     true,
+		},
+		{
+			name:        "Test mounts in or over another mount are left out",
+			annotations: map[string]string{api.ConsoleMountsAnnotation: "configmap/ca:/etc/extra/ca,configmap/krb5:/etc/extra,secret/keytab:/etc/extra/ca"},
+			want: []ConsoleMount{
+				{Name: "ca", MountPath: "/etc/extra/ca"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorsv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := GetConsoleMounts(operatorConfig)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetConsoleMounts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithConsoleMounts(t *testing.T) {
+	mounts := []ConsoleMount{
+		{Name: "corporate-ca", MountPath: "/etc/corporate-ca", Version: "1"},
+		{Secret: true, Name: "krb5.conf", MountPath: "/etc/krb5", Version: "2"},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{}},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "console"}},
+				},
+			},
+		},
+	}
+	WithConsoleMounts(deployment, mounts)
+
+	wantVolumes := []corev1.Volume{
+		{
+			Name: "console-mount-0",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "corporate-ca"},
+				},
+			},
+		},
+		{
+			Name: "console-mount-1",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: "krb5.conf"},
+			},
+		},
+	}
+	wantVolumeMounts := []corev1.VolumeMount{
+		{Name: "console-mount-0", ReadOnly: true, MountPath: "/etc/corporate-ca"},
+		{Name: "console-mount-1", ReadOnly: true, MountPath: "/etc/krb5"},
+	}
+	if diff := deep.Equal(deployment.Spec.Template.Spec.Volumes, wantVolumes); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(deployment.Spec.Template.Spec.Containers[0].VolumeMounts, wantVolumeMounts); diff != nil {
+		t.Error(diff)
+	}
+
+	version := deployment.Spec.Template.Annotations[consoleMountsVersionAnnotation]
+	if len(version) == 0 || deployment.Annotations[consoleMountsVersionAnnotation] != version {
+		t.Errorf("expected the console mounts version on the deployment and its pods, got %q and %q", deployment.Annotations[consoleMountsVersionAnnotation], version)
+	}
+	mounts[1].Version = "3"
+	if contentHash(mounts) == version {
+		t.Error("expected a new console mounts version for new mounted content")
+	}
+}
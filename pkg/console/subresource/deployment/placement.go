@@ -0,0 +1,106 @@
+package deployment
+
+import (
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+// NodePlacement is where the console and downloads pods are scheduled, requested by the node
+// selector and tolerations annotations of the operator config.
+type NodePlacement struct {
+	// NodeSelector replaces the node role selector of the pods, nil keeps it
+	NodeSelector map[string]string
+	// Tolerations are tolerated on top of the default tolerations of the pods
+	Tolerations []corev1.Toleration
+}
+
+// GetNodePlacement parses the node selector annotation, '<label>=<value>' entries, and the
+// tolerations annotation, '<key>[=<value>][:<NoSchedule|PreferNoSchedule|NoExecute>]' entries, of
+// the operator config. A toleration without a value tolerates the taint whatever its value, one
+// without an effect tolerates every effect. Invalid entries are left out and reported in the
+// returned error.
+func GetNodePlacement(operatorConfig *operatorv1.Console) (NodePlacement, error) {
+	placement := NodePlacement{}
+	invalid := []string{}
+	if value, ok := operatorConfig.Annotations[api.NodeSelectorAnnotation]; ok {
+		placement.NodeSelector = map[string]string{}
+		for _, entry := range strings.Split(value, ",") {
+			entry = strings.TrimSpace(entry)
+			if len(entry) == 0 {
+				continue
+			}
+			label, labelValue, found := strings.Cut(entry, "=")
+			if !found || len(validation.IsQualifiedName(label)) > 0 || len(validation.IsValidLabelValue(labelValue)) > 0 {
+				invalid = append(invalid, entry)
+				continue
+			}
+			placement.NodeSelector[label] = labelValue
+		}
+	}
+	if value, ok := operatorConfig.Annotations[api.TolerationsAnnotation]; ok {
+		for _, entry := range strings.Split(value, ",") {
+			entry = strings.TrimSpace(entry)
+			if len(entry) == 0 {
+				continue
+			}
+			toleration, ok := parseToleration(entry)
+			if !ok {
+				invalid = append(invalid, entry)
+				continue
+			}
+			placement.Tolerations = append(placement.Tolerations, toleration)
+		}
+	}
+	if len(invalid) > 0 {
+		return placement, fmt.Errorf("invalid node placement, expected %s entries <label>=<value> and %s entries <key>[=<value>][:<effect>]: %s", api.NodeSelectorAnnotation, api.TolerationsAnnotation, strings.Join(invalid, ", "))
+	}
+	return placement, nil
+}
+
+func parseToleration(entry string) (corev1.Toleration, bool) {
+	taint, effect, _ := strings.Cut(entry, ":")
+	key, value, hasValue := strings.Cut(taint, "=")
+	if len(validation.IsQualifiedName(key)) > 0 || (hasValue && len(validation.IsValidLabelValue(value)) > 0) {
+		return corev1.Toleration{}, false
+	}
+	toleration := corev1.Toleration{
+		Key:      key,
+		Operator: corev1.TolerationOpExists,
+		Effect:   corev1.TaintEffect(effect),
+	}
+	if hasValue {
+		toleration.Operator = corev1.TolerationOpEqual
+		toleration.Value = value
+	}
+	switch toleration.Effect {
+	case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		return toleration, true
+	default:
+		return corev1.Toleration{}, false
+	}
+}
+
+// withNodePlacement schedules the pods on the requested nodes. The operating system of the nodes
+// is still selected unless the node selector annotation selects another one.
+func withNodePlacement(deployment *appsv1.Deployment, operatorConfig *operatorv1.Console) {
+	placement, _ := GetNodePlacement(operatorConfig)
+	podSpec := &deployment.Spec.Template.Spec
+	if placement.NodeSelector != nil {
+		nodeSelector := map[string]string{}
+		if operatingSystem, ok := podSpec.NodeSelector[api.NodeOperatingSystemLabel]; ok {
+			nodeSelector[api.NodeOperatingSystemLabel] = operatingSystem
+		}
+		for label, value := range placement.NodeSelector {
+			nodeSelector[label] = value
+		}
+		podSpec.NodeSelector = nodeSelector
+	}
+	podSpec.Tolerations = append(podSpec.Tolerations, placement.Tolerations...)
+}
@@ -0,0 +1,119 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestGetNodePlacement(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        NodePlacement
+		wantErr     bool
+	}{
+		{
+			name: "Test default node placement",
+			want: NodePlacement{},
+		},
+		{
+			name: "Test infra node placement",
+			annotations: map[string]string{
+				api.NodeSelectorAnnotation: "node-role.kubernetes.io/infra=",
+				api.TolerationsAnnotation:  "node-role.kubernetes.io/infra=reserved:NoSchedule, node-role.kubernetes.io/infra:NoExecute",
+			},
+			want: NodePlacement{
+				NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+				Tolerations: []corev1.Toleration{
+					{Key: "node-role.kubernetes.io/infra", Operator: corev1.TolerationOpEqual, Value: "reserved", Effect: corev1.TaintEffectNoSchedule},
+					{Key: "node-role.kubernetes.io/infra", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+				},
+			},
+		},
+		{
+			name: "Test toleration of every effect",
+			annotations: map[string]string{
+				api.TolerationsAnnotation: "dedicated",
+			},
+			want: NodePlacement{
+				Tolerations: []corev1.Toleration{
+					{Key: "dedicated", Operator: corev1.TolerationOpExists},
+				},
+			},
+		},
+		{
+			name: "Test invalid node placement is left out",
+			annotations: map[string]string{
+				api.NodeSelectorAnnotation: "infra, zone=us-east-1a, bad key=value",
+				api.TolerationsAnnotation:  "dedicated:Never, gpu=true:NoSchedule",
+			},
+			want: NodePlacement{
+				NodeSelector: map[string]string{"zone": "us-east-1a"},
+				Tolerations: []corev1.Toleration{
+					{Key: "gpu", Operator: corev1.TolerationOpEqual, Value: "true", Effect: corev1.TaintEffectNoSchedule},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorsv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := GetNodePlacement(operatorConfig)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetNodePlacement() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithNodePlacement(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					NodeSelector: map[string]string{
+						api.NodeOperatingSystemLabel:     "linux",
+						"node-role.kubernetes.io/master": "",
+					},
+					Tolerations: []corev1.Toleration{
+						{Key: "node-role.kubernetes.io/master", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+					},
+				},
+			},
+		},
+	}
+	operatorConfig := &operatorsv1.Console{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			api.NodeSelectorAnnotation: "node-role.kubernetes.io/infra=",
+			api.TolerationsAnnotation:  "node-role.kubernetes.io/infra:NoSchedule",
+		}},
+	}
+	withNodePlacement(deployment, operatorConfig)
+
+	want := corev1.PodSpec{
+		NodeSelector: map[string]string{
+			api.NodeOperatingSystemLabel:    "linux",
+			"node-role.kubernetes.io/infra": "",
+		},
+		Tolerations: []corev1.Toleration{
+			{Key: "node-role.kubernetes.io/master", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+			{Key: "node-role.kubernetes.io/infra", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+		},
+	}
+	if diff := deep.Equal(deployment.Spec.Template.Spec, want); diff != nil {
+		t.Error(diff)
+	}
+}
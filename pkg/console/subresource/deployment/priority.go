@@ -0,0 +1,35 @@
+package deployment
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+// DefaultPriorityClassName is the priority of the console and downloads pods, they are part of the
+// cluster and are the last to be evicted.
+const DefaultPriorityClassName = "system-cluster-critical"
+
+// GetPriorityClassName returns the priority class of the console and downloads pods requested by
+// the priority-class annotation of the operator config, or DefaultPriorityClassName. An invalid
+// name keeps the default one and is reported in the returned error.
+func GetPriorityClassName(operatorConfig *operatorv1.Console) (string, error) {
+	priorityClassName, ok := operatorConfig.Annotations[api.PriorityClassAnnotation]
+	if !ok {
+		return DefaultPriorityClassName, nil
+	}
+	if errs := validation.IsDNS1123Subdomain(priorityClassName); len(errs) > 0 {
+		return DefaultPriorityClassName, fmt.Errorf("invalid %s %q: %v", api.PriorityClassAnnotation, priorityClassName, errs)
+	}
+	return priorityClassName, nil
+}
+
+// withPriorityClassName sets the priority class of the pods, an invalid one is reported by the
+// operator.
+func withPriorityClassName(deployment *appsv1.Deployment, operatorConfig *operatorv1.Console) {
+	deployment.Spec.Template.Spec.PriorityClassName, _ = GetPriorityClassName(operatorConfig)
+}
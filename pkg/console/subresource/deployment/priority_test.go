@@ -0,0 +1,49 @@
+package deployment
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestGetPriorityClassName(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        string
+		wantErr     bool
+	}{
+		{
+			name: "Test default priority class",
+			want: DefaultPriorityClassName,
+		},
+		{
+			name:        "Test requested priority class",
+			annotations: map[string]string{api.PriorityClassAnnotation: "openshift-user-critical"},
+			want:        "openshift-user-critical",
+		},
+		{
+			name:        "Test invalid priority class keeps the default one",
+			annotations: map[string]string{api.PriorityClassAnnotation: "User Critical"},
+			want:        DefaultPriorityClassName,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorsv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := GetPriorityClassName(operatorConfig)
+			if got != tt.want {
+				t.Errorf("GetPriorityClassName() = %q, want %q", got, tt.want)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetPriorityClassName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package deployment
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/bindata"
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+)
+
+// ConsoleProbes are the probes of the console container.
+type ConsoleProbes struct {
+	Startup   *corev1.Probe
+	Liveness  *corev1.Probe
+	Readiness *corev1.Probe
+}
+
+// GetConsoleProbes returns the probes of the console container, the defaults of the console
+// deployment with the overrides of the console-probes annotation of the operator config,
+// '<startup|liveness|readiness>.<initialDelaySeconds|timeoutSeconds|periodSeconds|failureThreshold|successThreshold>=<value>'
+// entries, eg. a longer liveness timeout for a console slowed down by its identity provider or
+// proxy. Only the readiness probe can require more than one success. Invalid entries are left
+// out and reported in the returned error.
+func GetConsoleProbes(operatorConfig *operatorv1.Console) (ConsoleProbes, error) {
+	container := resourceread.ReadDeploymentV1OrDie(bindata.MustAsset("assets/deployments/console-deployment.yaml")).Spec.Template.Spec.Containers[0]
+	probes := ConsoleProbes{
+		Startup:   container.StartupProbe,
+		Liveness:  container.LivenessProbe,
+		Readiness: container.ReadinessProbe,
+	}
+	value, ok := operatorConfig.Annotations[api.ConsoleProbesAnnotation]
+	if !ok {
+		return probes, nil
+	}
+
+	invalid := []string{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+		name, parameterValue, _ := strings.Cut(entry, "=")
+		probeName, parameter, _ := strings.Cut(strings.TrimSpace(name), ".")
+		parsed, err := strconv.ParseInt(strings.TrimSpace(parameterValue), 10, 32)
+		if err != nil {
+			invalid = append(invalid, entry)
+			continue
+		}
+
+		var probe *corev1.Probe
+		switch probeName {
+		case "startup":
+			probe = probes.Startup
+		case "liveness":
+			probe = probes.Liveness
+		case "readiness":
+			probe = probes.Readiness
+		}
+		field := probeParameter(probe, parameter)
+		switch {
+		case field == nil:
+			invalid = append(invalid, entry)
+		case parameter == "initialDelaySeconds" && parsed < 0:
+			invalid = append(invalid, entry)
+		case parameter != "initialDelaySeconds" && parsed < 1:
+			invalid = append(invalid, entry)
+		// the API server rejects startup and liveness probes requiring more than one success
+		case parameter == "successThreshold" && probe != probes.Readiness && parsed != 1:
+			invalid = append(invalid, entry)
+		default:
+			*field = int32(parsed)
+		}
+	}
+	if len(invalid) > 0 {
+		return probes, fmt.Errorf("invalid %s, expected <startup|liveness|readiness>.<initialDelaySeconds|timeoutSeconds|periodSeconds|failureThreshold|successThreshold>=<value>: %s", api.ConsoleProbesAnnotation, strings.Join(invalid, ", "))
+	}
+	return probes, nil
+}
+
+func probeParameter(probe *corev1.Probe, parameter string) *int32 {
+	if probe == nil {
+		return nil
+	}
+	switch parameter {
+	case "initialDelaySeconds":
+		return &probe.InitialDelaySeconds
+	case "timeoutSeconds":
+		return &probe.TimeoutSeconds
+	case "periodSeconds":
+		return &probe.PeriodSeconds
+	case "failureThreshold":
+		return &probe.FailureThreshold
+	case "successThreshold":
+		return &probe.SuccessThreshold
+	}
+	return nil
+}
+
+// withConsoleProbes tunes the probes of the console container, invalid overrides are reported by
+// the operator.
+func withConsoleProbes(deployment *appsv1.Deployment, operatorConfig *operatorv1.Console) {
+	probes, _ := GetConsoleProbes(operatorConfig)
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	container.StartupProbe = probes.Startup
+	container.LivenessProbe = probes.Liveness
+	container.ReadinessProbe = probes.Readiness
+}
@@ -0,0 +1,71 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestGetConsoleProbes(t *testing.T) {
+	defaults, err := GetConsoleProbes(&operatorsv1.Console{})
+	if err != nil {
+		t.Fatalf("GetConsoleProbes() of the defaults: %v", err)
+	}
+	probes := func(tune func(probes ConsoleProbes)) ConsoleProbes {
+		tuned := ConsoleProbes{
+			Startup:   defaults.Startup.DeepCopy(),
+			Liveness:  defaults.Liveness.DeepCopy(),
+			Readiness: defaults.Readiness.DeepCopy(),
+		}
+		tune(tuned)
+		return tuned
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        ConsoleProbes
+		wantErr     bool
+	}{
+		{
+			name: "Test default probes",
+			want: defaults,
+		},
+		{
+			name:        "Test slow identity provider",
+			annotations: map[string]string{api.ConsoleProbesAnnotation: "liveness.timeoutSeconds=30, liveness.failureThreshold=3, readiness.timeoutSeconds=5, startup.periodSeconds=20"},
+			want: probes(func(probes ConsoleProbes) {
+				probes.Liveness.TimeoutSeconds = 30
+				probes.Liveness.FailureThreshold = 3
+				probes.Readiness.TimeoutSeconds = 5
+				probes.Startup.PeriodSeconds = 20
+			}),
+		},
+		{
+			name:        "Test invalid probe parameters are left out",
+			annotations: map[string]string{api.ConsoleProbesAnnotation: "liveness.successThreshold=2,readiness.successThreshold=2,readiness.periodSeconds=0,startup.initialDelaySeconds=-1,liveness.port=80,health.timeoutSeconds=5,liveness.timeoutSeconds=ten"},
+			want: probes(func(probes ConsoleProbes) {
+				probes.Readiness.SuccessThreshold = 2
+			}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorsv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := GetConsoleProbes(operatorConfig)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetConsoleProbes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package deployment
+
+import (
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/bindata"
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+)
+
+// ConsoleResources returns the resources of the console container, the defaults of the console
+// deployment with the overrides of the console resources annotation.
+func ConsoleResources(operatorConfig *operatorv1.Console) (corev1.ResourceRequirements, error) {
+	return defaultResourcesWithOverrides(operatorConfig, api.ConsoleResourcesAnnotation, "assets/deployments/console-deployment.yaml")
+}
+
+// DownloadsResources returns the resources of the downloads container, the defaults of the
+// downloads deployment with the overrides of the downloads resources annotation.
+func DownloadsResources(operatorConfig *operatorv1.Console) (corev1.ResourceRequirements, error) {
+	return defaultResourcesWithOverrides(operatorConfig, api.DownloadsResourcesAnnotation, "assets/deployments/downloads-deployment.yaml")
+}
+
+func defaultResourcesWithOverrides(operatorConfig *operatorv1.Console, annotation string, asset string) (corev1.ResourceRequirements, error) {
+	deployment := resourceread.ReadDeploymentV1OrDie(bindata.MustAsset(asset))
+	return ResourcesWithOverrides(operatorConfig, annotation, deployment.Spec.Template.Spec.Containers[0].Resources)
+}
+
+// ResourcesWithOverrides returns defaults with the overrides of the resources annotation of the
+// operator config, <requests|limits>.<cpu|memory>=<quantity> entries. Invalid entries, and
+// limits below the requests, are left out and reported in the returned error.
+func ResourcesWithOverrides(operatorConfig *operatorv1.Console, annotation string, defaults corev1.ResourceRequirements) (corev1.ResourceRequirements, error) {
+	resources := *defaults.DeepCopy()
+	value, ok := operatorConfig.Annotations[annotation]
+	if !ok {
+		return resources, nil
+	}
+	invalid := []string{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+		name, quantity, _ := strings.Cut(entry, "=")
+		kind, resourceName, _ := strings.Cut(strings.TrimSpace(name), ".")
+		parsed, err := resource.ParseQuantity(strings.TrimSpace(quantity))
+		if err != nil || parsed.Sign() <= 0 {
+			invalid = append(invalid, entry)
+			continue
+		}
+		switch corev1.ResourceName(resourceName) {
+		case corev1.ResourceCPU, corev1.ResourceMemory:
+		default:
+			invalid = append(invalid, entry)
+			continue
+		}
+		switch kind {
+		case "requests":
+			if resources.Requests == nil {
+				resources.Requests = corev1.ResourceList{}
+			}
+			resources.Requests[corev1.ResourceName(resourceName)] = parsed
+		case "limits":
+			if resources.Limits == nil {
+				resources.Limits = corev1.ResourceList{}
+			}
+			resources.Limits[corev1.ResourceName(resourceName)] = parsed
+		default:
+			invalid = append(invalid, entry)
+		}
+	}
+	// the API server rejects pods with limits below their requests
+	for resourceName, limit := range resources.Limits {
+		if request, ok := resources.Requests[resourceName]; ok && limit.Cmp(request) < 0 {
+			invalid = append(invalid, fmt.Sprintf("limits.%s=%s below requests.%s=%s", resourceName, limit.String(), resourceName, request.String()))
+			delete(resources.Limits, resourceName)
+		}
+	}
+	if len(invalid) > 0 {
+		return resources, fmt.Errorf("invalid %s, expected <requests|limits>.<cpu|memory>=<quantity>: %s", annotation, strings.Join(invalid, ", "))
+	}
+	return resources, nil
+}
+
+// withResources overrides the resources of the first container, invalid overrides are reported
+// by the controllers.
+func withResources(deployment *appsv1.Deployment, operatorConfig *operatorv1.Console, annotation string) {
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	container.Resources, _ = ResourcesWithOverrides(operatorConfig, annotation, container.Resources)
+}
@@ -0,0 +1,83 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestResourcesWithOverrides(t *testing.T) {
+	defaults := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("10m"),
+			corev1.ResourceMemory: resource.MustParse("100Mi"),
+		},
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        corev1.ResourceRequirements
+		wantErr     bool
+	}{
+		{
+			name: "Test default resources",
+			want: defaults,
+		},
+		{
+			name:        "Test raised requests and limits",
+			annotations: map[string]string{api.ConsoleResourcesAnnotation: "requests.cpu=100m, requests.memory=512Mi, limits.memory=1Gi"},
+			want: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100m"),
+					corev1.ResourceMemory: resource.MustParse("512Mi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+			},
+		},
+		{
+			name:        "Test invalid overrides are left out",
+			annotations: map[string]string{api.ConsoleResourcesAnnotation: "requests.cpu=lots,requests.storage=1Gi,limits.cpu=-1,requests.memory=200Mi"},
+			want: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("10m"),
+					corev1.ResourceMemory: resource.MustParse("200Mi"),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:        "Test limit below the request is left out",
+			annotations: map[string]string{api.ConsoleResourcesAnnotation: "limits.memory=50Mi,limits.cpu=1"},
+			want: corev1.ResourceRequirements{
+				Requests: defaults.Requests,
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("1"),
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorsv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := ResourcesWithOverrides(operatorConfig, api.ConsoleResourcesAnnotation, defaults)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ResourcesWithOverrides() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package deployment
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/bindata"
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/library-go/pkg/operator/resource/resourceread"
+)
+
+// reservedAnnotationPrefixes are the pod template annotations the operator tracks the console
+// rollouts with
+var reservedAnnotationPrefixes = []string{
+	"console.openshift.io/",
+	"console.operator.openshift.io/",
+	"operator.openshift.io/",
+}
+
+// PodTemplateExtensions are added to the console pod template for a service mesh, or any other
+// sidecar injector, requested by the pod template annotations of the operator config. They are
+// rendered into the pod template so that the operator does not strip them on every sync.
+type PodTemplateExtensions struct {
+	// Labels are '<label>=<value>' entries of the pod template labels annotation
+	Labels map[string]string
+	// Annotations are a JSON object of the pod template annotations annotation, eg. the
+	// sidecar injection annotations of a service mesh
+	Annotations map[string]string
+	// Containers are a JSON array of the sidecar containers annotation
+	Containers []corev1.Container
+}
+
+// GetPodTemplateExtensions parses the pod template annotations of the operator config. The
+// labels, annotations and containers of the console pod template, and the annotations the
+// operator tracks the rollouts with, are never replaced: they are left out like invalid entries
+// and reported in the returned error.
+func GetPodTemplateExtensions(operatorConfig *operatorv1.Console) (PodTemplateExtensions, error) {
+	template := resourceread.ReadDeploymentV1OrDie(bindata.MustAsset("assets/deployments/console-deployment.yaml")).Spec.Template
+	extensions := PodTemplateExtensions{}
+	invalid := []string{}
+
+	if value, ok := operatorConfig.Annotations[api.PodTemplateLabelsAnnotation]; ok {
+		extensions.Labels = map[string]string{}
+		for _, entry := range strings.Split(value, ",") {
+			entry = strings.TrimSpace(entry)
+			if len(entry) == 0 {
+				continue
+			}
+			label, labelValue, found := strings.Cut(entry, "=")
+			_, reserved := template.Labels[label]
+			if !found || reserved || len(validation.IsQualifiedName(label)) > 0 || len(validation.IsValidLabelValue(labelValue)) > 0 {
+				invalid = append(invalid, fmt.Sprintf("label %s", entry))
+				continue
+			}
+			extensions.Labels[label] = labelValue
+		}
+	}
+
+	if value, ok := operatorConfig.Annotations[api.PodTemplateAnnotationsAnnotation]; ok {
+		annotations := map[string]string{}
+		if err := json.Unmarshal([]byte(value), &annotations); err != nil {
+			invalid = append(invalid, fmt.Sprintf("annotations: %v", err))
+		}
+		extensions.Annotations = map[string]string{}
+		for annotation, annotationValue := range annotations {
+			_, reserved := template.Annotations[annotation]
+			for _, prefix := range reservedAnnotationPrefixes {
+				reserved = reserved || strings.HasPrefix(annotation, prefix)
+			}
+			if reserved || len(validation.IsQualifiedName(annotation)) > 0 {
+				invalid = append(invalid, fmt.Sprintf("annotation %s", annotation))
+				continue
+			}
+			extensions.Annotations[annotation] = annotationValue
+		}
+	}
+
+	if value, ok := operatorConfig.Annotations[api.SidecarContainersAnnotation]; ok {
+		containers := []corev1.Container{}
+		if err := json.Unmarshal([]byte(value), &containers); err != nil {
+			invalid = append(invalid, fmt.Sprintf("containers: %v", err))
+		}
+		names := map[string]bool{}
+		for _, container := range template.Spec.Containers {
+			names[container.Name] = true
+		}
+		for _, container := range containers {
+			if names[container.Name] || len(validation.IsDNS1123Label(container.Name)) > 0 || len(container.Image) == 0 {
+				invalid = append(invalid, fmt.Sprintf("container %q", container.Name))
+				continue
+			}
+			names[container.Name] = true
+			extensions.Containers = append(extensions.Containers, container)
+		}
+	}
+
+	if len(invalid) > 0 {
+		return extensions, fmt.Errorf("invalid pod template extensions, labels, annotations and containers of the console pods cannot be replaced, sidecar containers need a name and an image: %s", strings.Join(invalid, ", "))
+	}
+	return extensions, nil
+}
+
+// withPodTemplateExtensions adds the valid extensions to the console pod template, invalid ones
+// are reported by the operator.
+func withPodTemplateExtensions(deployment *appsv1.Deployment, operatorConfig *operatorv1.Console) {
+	template := &deployment.Spec.Template
+	extensions, _ := GetPodTemplateExtensions(operatorConfig)
+	for label, value := range extensions.Labels {
+		if template.Labels == nil {
+			template.Labels = map[string]string{}
+		}
+		template.Labels[label] = value
+	}
+	for annotation, value := range extensions.Annotations {
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations[annotation] = value
+	}
+	template.Spec.Containers = append(template.Spec.Containers, extensions.Containers...)
+}
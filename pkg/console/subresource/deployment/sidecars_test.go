@@ -0,0 +1,83 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestGetPodTemplateExtensions(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        PodTemplateExtensions
+		wantErr     bool
+	}{
+		{
+			name: "Test no pod template extensions",
+			want: PodTemplateExtensions{},
+		},
+		{
+			name: "Test service mesh sidecar injection",
+			annotations: map[string]string{
+				api.PodTemplateLabelsAnnotation:      "sidecar.istio.io/inject=true",
+				api.PodTemplateAnnotationsAnnotation: `{"traffic.sidecar.istio.io/excludeOutboundIPRanges": "172.30.0.1/32,10.0.0.1/32"}`,
+			},
+			want: PodTemplateExtensions{
+				Labels:      map[string]string{"sidecar.istio.io/inject": "true"},
+				Annotations: map[string]string{"traffic.sidecar.istio.io/excludeOutboundIPRanges": "172.30.0.1/32,10.0.0.1/32"},
+			},
+		},
+		{
+			name: "Test sidecar containers",
+			annotations: map[string]string{
+				api.SidecarContainersAnnotation: `[{"name": "proxy", "image": "quay.io/example/proxy:v1"}]`,
+			},
+			want: PodTemplateExtensions{
+				Containers: []corev1.Container{{Name: "proxy", Image: "quay.io/example/proxy:v1"}},
+			},
+		},
+		{
+			name: "Test console pod template is never replaced",
+			annotations: map[string]string{
+				api.PodTemplateLabelsAnnotation:      "component=mesh,version=v1",
+				api.PodTemplateAnnotationsAnnotation: `{"openshift.io/required-scc": "privileged", "console.openshift.io/image": "other", "mesh/enabled": "true"}`,
+				api.SidecarContainersAnnotation:      `[{"name": "console", "image": "quay.io/example/console"}, {"name": "noimage"}]`,
+			},
+			want: PodTemplateExtensions{
+				Labels:      map[string]string{"version": "v1"},
+				Annotations: map[string]string{"mesh/enabled": "true"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Test malformed pod template annotations",
+			annotations: map[string]string{
+				api.PodTemplateAnnotationsAnnotation: `inject=true`,
+			},
+			want: PodTemplateExtensions{
+				Annotations: map[string]string{},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorsv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := GetPodTemplateExtensions(operatorConfig)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetPodTemplateExtensions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
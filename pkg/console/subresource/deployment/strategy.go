@@ -0,0 +1,98 @@
+package deployment
+
+import (
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+const (
+	maxSurgeParameter       = "maxSurge"
+	maxUnavailableParameter = "maxUnavailable"
+)
+
+// GetRollingUpdate returns the rolling update of the console deployment: the default one of the
+// topology, with the parameters of the rolling-update annotation of the operator config,
+// '<maxSurge|maxUnavailable>=<replicas|percentage>' entries, on top. Invalid entries are left
+// out and reported in the returned error, so are a maxSurge and a maxUnavailable both of 0 which
+// would never roll the console out.
+func GetRollingUpdate(operatorConfig *operatorv1.Console, infrastructureConfig *configv1.Infrastructure) (*appsv1.RollingUpdateDeployment, error) {
+	deployment := &appsv1.Deployment{}
+	withStrategy(deployment, infrastructureConfig)
+	rollingUpdate := deployment.Spec.Strategy.RollingUpdate
+	value, ok := operatorConfig.Annotations[api.RollingUpdateAnnotation]
+	if !ok {
+		return rollingUpdate, nil
+	}
+
+	requested := appsv1.RollingUpdateDeployment{}
+	invalid := []string{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+		parameter, parameterValue, found := strings.Cut(entry, "=")
+		replicas := intstr.Parse(strings.TrimSpace(parameterValue))
+		if !found || !validRollingUpdateParameter(strings.TrimSpace(parameter), replicas) {
+			invalid = append(invalid, entry)
+			continue
+		}
+		switch strings.TrimSpace(parameter) {
+		case maxSurgeParameter:
+			requested.MaxSurge = &replicas
+		case maxUnavailableParameter:
+			requested.MaxUnavailable = &replicas
+		}
+	}
+	if isZeroReplicas(requested.MaxSurge) && isZeroReplicas(requested.MaxUnavailable) {
+		invalid = append(invalid, fmt.Sprintf("%s and %s of 0", maxSurgeParameter, maxUnavailableParameter))
+	} else {
+		if requested.MaxSurge != nil {
+			rollingUpdate.MaxSurge = requested.MaxSurge
+		}
+		if requested.MaxUnavailable != nil {
+			rollingUpdate.MaxUnavailable = requested.MaxUnavailable
+		}
+	}
+	if len(invalid) > 0 {
+		return rollingUpdate, fmt.Errorf("invalid rolling update, expected %s entries <maxSurge|maxUnavailable>=<replicas|percentage> not both 0: %s", api.RollingUpdateAnnotation, strings.Join(invalid, ", "))
+	}
+	return rollingUpdate, nil
+}
+
+// validRollingUpdateParameter accepts a number of replicas or a percentage of the replicas, at
+// most 100% of them can be unavailable.
+func validRollingUpdateParameter(parameter string, replicas intstr.IntOrString) bool {
+	if parameter != maxSurgeParameter && parameter != maxUnavailableParameter {
+		return false
+	}
+	if replicas.Type == intstr.Int {
+		return replicas.IntVal >= 0
+	}
+	percentage, err := intstr.GetScaledValueFromIntOrPercent(&replicas, 100, false)
+	if err != nil || percentage < 0 {
+		return false
+	}
+	return parameter == maxSurgeParameter || percentage <= 100
+}
+
+func isZeroReplicas(replicas *intstr.IntOrString) bool {
+	if replicas == nil {
+		return false
+	}
+	value, err := intstr.GetScaledValueFromIntOrPercent(replicas, 100, false)
+	return err == nil && value == 0
+}
+
+// withRollingUpdate rolls the console out with the requested rolling update, invalid parameters
+// are reported by the operator.
+func withRollingUpdate(deployment *appsv1.Deployment, operatorConfig *operatorv1.Console, infrastructureConfig *configv1.Infrastructure) {
+	deployment.Spec.Strategy.RollingUpdate, _ = GetRollingUpdate(operatorConfig, infrastructureConfig)
+}
@@ -0,0 +1,91 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestGetRollingUpdate(t *testing.T) {
+	infrastructureConfigHighlyAvailable := infrastructureConfigWithTopology(configv1.HighlyAvailableTopologyMode, configv1.HighlyAvailableTopologyMode)
+	infrastructureConfigSingleReplica := infrastructureConfigWithTopology(configv1.SingleReplicaTopologyMode, configv1.SingleReplicaTopologyMode)
+	replicas := func(value string) *intstr.IntOrString {
+		replicas := intstr.Parse(value)
+		return &replicas
+	}
+
+	tests := []struct {
+		name                 string
+		annotations          map[string]string
+		infrastructureConfig *configv1.Infrastructure
+		want                 *appsv1.RollingUpdateDeployment
+		wantErr              bool
+	}{
+		{
+			name:                 "Test default highly available rolling update",
+			infrastructureConfig: infrastructureConfigHighlyAvailable,
+			want: &appsv1.RollingUpdateDeployment{
+				MaxSurge:       &intstr.IntOrString{IntVal: 3},
+				MaxUnavailable: &intstr.IntOrString{IntVal: 1},
+			},
+		},
+		{
+			name:                 "Test surge only rolling update",
+			annotations:          map[string]string{api.RollingUpdateAnnotation: "maxSurge=25%, maxUnavailable=0"},
+			infrastructureConfig: infrastructureConfigHighlyAvailable,
+			want: &appsv1.RollingUpdateDeployment{
+				MaxSurge:       replicas("25%"),
+				MaxUnavailable: replicas("0"),
+			},
+		},
+		{
+			name:                 "Test single replica rolling update",
+			annotations:          map[string]string{api.RollingUpdateAnnotation: "maxSurge=1"},
+			infrastructureConfig: infrastructureConfigSingleReplica,
+			want: &appsv1.RollingUpdateDeployment{
+				MaxSurge: replicas("1"),
+			},
+		},
+		{
+			name:                 "Test invalid rolling update is left out",
+			annotations:          map[string]string{api.RollingUpdateAnnotation: "maxSurge=-1,maxUnavailable=150%,maxReady=1,maxUnavailable=50%"},
+			infrastructureConfig: infrastructureConfigHighlyAvailable,
+			want: &appsv1.RollingUpdateDeployment{
+				MaxSurge:       &intstr.IntOrString{IntVal: 3},
+				MaxUnavailable: replicas("50%"),
+			},
+			wantErr: true,
+		},
+		{
+			name:                 "Test rolling update that never rolls out",
+			annotations:          map[string]string{api.RollingUpdateAnnotation: "maxSurge=0%,maxUnavailable=0"},
+			infrastructureConfig: infrastructureConfigHighlyAvailable,
+			want: &appsv1.RollingUpdateDeployment{
+				MaxSurge:       &intstr.IntOrString{IntVal: 3},
+				MaxUnavailable: &intstr.IntOrString{IntVal: 1},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorsv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := GetRollingUpdate(operatorConfig, tt.infrastructureConfig)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetRollingUpdate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
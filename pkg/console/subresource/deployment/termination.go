@@ -0,0 +1,110 @@
+package deployment
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+const (
+	// DefaultPreStopSeconds keeps a terminating console pod serving while the router and the
+	// endpoints stop sending it new connections
+	DefaultPreStopSeconds = 25
+	// DefaultGracePeriodSeconds leaves the console the time to drain its sessions once the preStop
+	// sleep is over
+	DefaultGracePeriodSeconds = 40
+	// MaxGracePeriodSeconds bounds the grace period, a terminating console holds up node drains
+	// and upgrades
+	MaxGracePeriodSeconds = 600
+
+	preStopSecondsParameter     = "preStopSeconds"
+	gracePeriodSecondsParameter = "gracePeriodSeconds"
+)
+
+// GracefulTermination is how long a terminating console pod keeps serving before it is stopped,
+// and how long it has to drain its websocket and terminal sessions before it is killed.
+type GracefulTermination struct {
+	PreStopSeconds     int64
+	GracePeriodSeconds int64
+}
+
+// GetGracefulTermination parses the graceful-termination annotation of the operator config,
+// '<preStopSeconds|gracePeriodSeconds>=<seconds>' entries. Without a grace period, the console is
+// given as long after a longer preStop sleep as after the default one. The preStop sleep has to
+// be over before the grace period is, invalid entries are left out and reported in the returned
+// error.
+func GetGracefulTermination(operatorConfig *operatorv1.Console) (GracefulTermination, error) {
+	termination := GracefulTermination{
+		PreStopSeconds:     DefaultPreStopSeconds,
+		GracePeriodSeconds: DefaultGracePeriodSeconds,
+	}
+	value, ok := operatorConfig.Annotations[api.GracefulTerminationAnnotation]
+	if !ok {
+		return termination, nil
+	}
+
+	invalid := []string{}
+	var gracePeriodSeconds *int64
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+		parameter, seconds, _ := strings.Cut(entry, "=")
+		parsed, err := strconv.ParseInt(strings.TrimSpace(seconds), 10, 64)
+		if err != nil || parsed < 0 || parsed >= MaxGracePeriodSeconds {
+			invalid = append(invalid, entry)
+			continue
+		}
+		switch strings.TrimSpace(parameter) {
+		case preStopSecondsParameter:
+			termination.PreStopSeconds = parsed
+		case gracePeriodSecondsParameter:
+			gracePeriodSeconds = &parsed
+		default:
+			invalid = append(invalid, entry)
+		}
+	}
+
+	termination.GracePeriodSeconds = termination.PreStopSeconds + DefaultGracePeriodSeconds - DefaultPreStopSeconds
+	if termination.GracePeriodSeconds > MaxGracePeriodSeconds {
+		termination.GracePeriodSeconds = MaxGracePeriodSeconds
+	}
+	if gracePeriodSeconds != nil {
+		if *gracePeriodSeconds > termination.PreStopSeconds {
+			termination.GracePeriodSeconds = *gracePeriodSeconds
+		} else {
+			invalid = append(invalid, fmt.Sprintf("%s=%d not longer than %s=%d", gracePeriodSecondsParameter, *gracePeriodSeconds, preStopSecondsParameter, termination.PreStopSeconds))
+		}
+	}
+	if len(invalid) > 0 {
+		return termination, fmt.Errorf("invalid %s, expected <preStopSeconds|gracePeriodSeconds>=<seconds> below %d: %s", api.GracefulTerminationAnnotation, MaxGracePeriodSeconds, strings.Join(invalid, ", "))
+	}
+	return termination, nil
+}
+
+// withGracefulTermination sets the preStop sleep of the console container and the grace period of
+// the console pods, a console without a preStop sleep is stopped right away. Invalid entries are
+// reported by the operator.
+func withGracefulTermination(deployment *appsv1.Deployment, operatorConfig *operatorv1.Console) {
+	termination, _ := GetGracefulTermination(operatorConfig)
+	deployment.Spec.Template.Spec.TerminationGracePeriodSeconds = &termination.GracePeriodSeconds
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	if termination.PreStopSeconds == 0 {
+		container.Lifecycle = nil
+		return
+	}
+	container.Lifecycle = &corev1.Lifecycle{
+		PreStop: &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"sleep", strconv.FormatInt(termination.PreStopSeconds, 10)},
+			},
+		},
+	}
+}
@@ -0,0 +1,61 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestGetGracefulTermination(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        GracefulTermination
+		wantErr     bool
+	}{
+		{
+			name: "Test default graceful termination",
+			want: GracefulTermination{PreStopSeconds: DefaultPreStopSeconds, GracePeriodSeconds: DefaultGracePeriodSeconds},
+		},
+		{
+			name:        "Test longer preStop sleep keeps the time to drain the sessions",
+			annotations: map[string]string{api.GracefulTerminationAnnotation: "preStopSeconds=60"},
+			want:        GracefulTermination{PreStopSeconds: 60, GracePeriodSeconds: 75},
+		},
+		{
+			name:        "Test preStop sleep and grace period",
+			annotations: map[string]string{api.GracefulTerminationAnnotation: "preStopSeconds=30, gracePeriodSeconds=120"},
+			want:        GracefulTermination{PreStopSeconds: 30, GracePeriodSeconds: 120},
+		},
+		{
+			name:        "Test grace period not longer than the preStop sleep",
+			annotations: map[string]string{api.GracefulTerminationAnnotation: "preStopSeconds=30,gracePeriodSeconds=30"},
+			want:        GracefulTermination{PreStopSeconds: 30, GracePeriodSeconds: 45},
+			wantErr:     true,
+		},
+		{
+			name:        "Test invalid graceful termination is left out",
+			annotations: map[string]string{api.GracefulTerminationAnnotation: "preStopSeconds=-1,gracePeriodSeconds=3600,drainSeconds=10"},
+			want:        GracefulTermination{PreStopSeconds: DefaultPreStopSeconds, GracePeriodSeconds: DefaultGracePeriodSeconds},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorsv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := GetGracefulTermination(operatorConfig)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetGracefulTermination() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
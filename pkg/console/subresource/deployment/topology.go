@@ -0,0 +1,92 @@
+package deployment
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+// topologySpreadKeys are the topologies the console pods can be spread over
+var topologySpreadKeys = map[string]string{
+	"zone":     corev1.LabelTopologyZone,
+	"hostname": corev1.LabelHostname,
+}
+
+// GetTopologySpreadConstraints parses the topology spread annotation of the operator config,
+// '<zone|hostname>[:<maxSkew>[:<DoNotSchedule|ScheduleAnyway>]]' entries, into the topology
+// spread constraints of the console pods. The max skew defaults to 1 and unsatisfiable
+// constraints to ScheduleAnyway. Without the annotation the console pods are only spread by
+// the anti-affinity of the topology. Invalid entries are left out and reported in the returned
+// error.
+func GetTopologySpreadConstraints(operatorConfig *operatorv1.Console) ([]corev1.TopologySpreadConstraint, error) {
+	value, ok := operatorConfig.Annotations[api.TopologySpreadAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	constraints := []corev1.TopologySpreadConstraint{}
+	spread := map[string]bool{}
+	invalid := []string{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+		fields := strings.Split(entry, ":")
+		topologyKey, ok := topologySpreadKeys[fields[0]]
+		if !ok || len(fields) > 3 || spread[topologyKey] {
+			invalid = append(invalid, entry)
+			continue
+		}
+		maxSkew := int64(1)
+		if len(fields) > 1 {
+			parsed, err := strconv.ParseInt(fields[1], 10, 32)
+			if err != nil || parsed < 1 {
+				invalid = append(invalid, entry)
+				continue
+			}
+			maxSkew = parsed
+		}
+		whenUnsatisfiable := corev1.ScheduleAnyway
+		if len(fields) > 2 {
+			switch action := corev1.UnsatisfiableConstraintAction(fields[2]); action {
+			case corev1.DoNotSchedule, corev1.ScheduleAnyway:
+				whenUnsatisfiable = action
+			default:
+				invalid = append(invalid, entry)
+				continue
+			}
+		}
+		spread[topologyKey] = true
+		constraints = append(constraints, corev1.TopologySpreadConstraint{
+			MaxSkew:           int32(maxSkew),
+			TopologyKey:       topologyKey,
+			WhenUnsatisfiable: whenUnsatisfiable,
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": api.OpenShiftConsoleName, "component": "ui"},
+			},
+			// the pods of a rollout are spread apart from the ones they replace
+			MatchLabelKeys: []string{appsv1.DefaultDeploymentUniqueLabelKey},
+		})
+	}
+	if len(invalid) > 0 {
+		return constraints, fmt.Errorf("invalid topology spread, expected <zone|hostname>[:<maxSkew>[:<DoNotSchedule|ScheduleAnyway>]] once per topology: %s", strings.Join(invalid, ", "))
+	}
+	return constraints, nil
+}
+
+// withTopologySpreadConstraints spreads the console pods with the valid topology spread
+// constraints, invalid ones are reported by the operator.
+func withTopologySpreadConstraints(deployment *appsv1.Deployment, operatorConfig *operatorv1.Console) {
+	constraints, _ := GetTopologySpreadConstraints(operatorConfig)
+	if len(constraints) == 0 {
+		return
+	}
+	deployment.Spec.Template.Spec.TopologySpreadConstraints = constraints
+}
@@ -0,0 +1,71 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorsv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestGetTopologySpreadConstraints(t *testing.T) {
+	constraint := func(topologyKey string, maxSkew int32, whenUnsatisfiable corev1.UnsatisfiableConstraintAction) corev1.TopologySpreadConstraint {
+		return corev1.TopologySpreadConstraint{
+			MaxSkew:           maxSkew,
+			TopologyKey:       topologyKey,
+			WhenUnsatisfiable: whenUnsatisfiable,
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "console", "component": "ui"},
+			},
+			MatchLabelKeys: []string{"pod-template-hash"},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        []corev1.TopologySpreadConstraint
+		wantErr     bool
+	}{
+		{
+			name: "Test no topology spread",
+			want: nil,
+		},
+		{
+			name:        "Test default zone spread",
+			annotations: map[string]string{api.TopologySpreadAnnotation: "zone"},
+			want:        []corev1.TopologySpreadConstraint{constraint(corev1.LabelTopologyZone, 1, corev1.ScheduleAnyway)},
+		},
+		{
+			name:        "Test zone and hostname spread",
+			annotations: map[string]string{api.TopologySpreadAnnotation: "zone:1:DoNotSchedule, hostname:2"},
+			want: []corev1.TopologySpreadConstraint{
+				constraint(corev1.LabelTopologyZone, 1, corev1.DoNotSchedule),
+				constraint(corev1.LabelHostname, 2, corev1.ScheduleAnyway),
+			},
+		},
+		{
+			name:        "Test invalid topology spread is left out",
+			annotations: map[string]string{api.TopologySpreadAnnotation: "rack,zone:0,zone:1:Never,hostname,hostname:3"},
+			want:        []corev1.TopologySpreadConstraint{constraint(corev1.LabelHostname, 1, corev1.ScheduleAnyway)},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorsv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := GetTopologySpreadConstraints(operatorConfig)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetTopologySpreadConstraints() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
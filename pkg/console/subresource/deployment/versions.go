@@ -0,0 +1,61 @@
+package deployment
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// The console deployment tracks the resources it is rendered from in annotations, a new value rolls
+// the console out. The values are hashes of the content the console reads rather than resource
+// versions, so that metadata-only writes, eg. of labels, annotations or owner references, do not
+// restart the console pods.
+
+// ConfigMapVersion is the hash of the data of a configmap. A missing configmap, or one with no
+// data yet, has no version.
+func ConfigMapVersion(configMap *corev1.ConfigMap) string {
+	if configMap == nil || (len(configMap.Data) == 0 && len(configMap.BinaryData) == 0) {
+		return ""
+	}
+	return contentHash(configMap.Data, configMap.BinaryData)
+}
+
+// SecretVersion is the hash of the data of a secret. A missing secret, or one with no data yet,
+// has no version.
+func SecretVersion(secret *corev1.Secret) string {
+	if secret == nil || len(secret.Data) == 0 {
+		return ""
+	}
+	return contentHash(secret.Data)
+}
+
+// proxyConfigVersion is the hash of the proxy settings the console runs with.
+func proxyConfigVersion(proxyConfig *configv1.Proxy) string {
+	if proxyConfig == nil {
+		return ""
+	}
+	return contentHash(proxyConfig.Status)
+}
+
+// infrastructureConfigVersion is the hash of the infrastructure status, the topology and API
+// server URLs of the cluster.
+func infrastructureConfigVersion(infrastructureConfig *configv1.Infrastructure) string {
+	if infrastructureConfig == nil {
+		return ""
+	}
+	return contentHash(infrastructureConfig.Status)
+}
+
+func contentHash(content ...interface{}) string {
+	hash := sha256.New()
+	for _, c := range content {
+		// json.Marshal sorts map keys, the hash is stable
+		marshalled, _ := json.Marshal(c)
+		hash.Write(marshalled)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,66 @@
+package deployment
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigMapVersion(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "console-config", ResourceVersion: "10"},
+		Data:       map[string]string{"console-config.yaml": "kind: ConsoleConfig"},
+	}
+	metadataWrite := configMap.DeepCopy()
+	metadataWrite.ResourceVersion = "11"
+	metadataWrite.Labels = map[string]string{"app": "console"}
+	dataWrite := configMap.DeepCopy()
+	dataWrite.ResourceVersion = "12"
+	dataWrite.Data["console-config.yaml"] = "kind: ConsoleConfig\napiVersion: console.openshift.io/v1"
+
+	tests := []struct {
+		name      string
+		configMap *corev1.ConfigMap
+		same      bool
+	}{
+		{
+			name:      "Test metadata-only write keeps the version",
+			configMap: metadataWrite,
+			same:      true,
+		},
+		{
+			name:      "Test data write changes the version",
+			configMap: dataWrite,
+			same:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := ConfigMapVersion(tt.configMap) == ConfigMapVersion(configMap); same != tt.same {
+				t.Errorf("ConfigMapVersion() of %s is the same: %v, want %v", tt.configMap.ResourceVersion, same, tt.same)
+			}
+		})
+	}
+	if version := ConfigMapVersion(&corev1.ConfigMap{}); version != "" {
+		t.Errorf("ConfigMapVersion() of a configmap without data = %q, want none", version)
+	}
+}
+
+func TestSecretVersion(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "console-oauth-config", ResourceVersion: "10"},
+		Data:       map[string][]byte{"clientSecret": []byte("secret")},
+	}
+	metadataWrite := secret.DeepCopy()
+	metadataWrite.ResourceVersion = "11"
+	metadataWrite.Annotations = map[string]string{"rotated": "true"}
+	if SecretVersion(metadataWrite) != SecretVersion(secret) {
+		t.Error("SecretVersion() changed with a metadata-only write")
+	}
+	dataWrite := secret.DeepCopy()
+	dataWrite.Data["clientSecret"] = []byte("rotated")
+	if SecretVersion(dataWrite) == SecretVersion(secret) {
+		t.Error("SecretVersion() did not change with a data write")
+	}
+}
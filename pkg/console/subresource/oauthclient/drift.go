@@ -0,0 +1,62 @@
+package oauthclient
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+// SetDataHash records the hash of the fields the operator writes on the OAuthClient, so that out
+// of band changes can be told apart from the operator's own on the next sync.
+func SetDataHash(required *oauthv1.OAuthClient) {
+	if required.Annotations == nil {
+		required.Annotations = map[string]string{}
+	}
+	required.Annotations[api.ConfigMapDataHashAnnotation] = dataHash(required)
+}
+
+// DataDrift returns the fields of existing that were changed by someone other than the operator
+// and are about to be reverted to required, with a diff of the reverted redirect URIs. The client
+// secrets are never part of the diff. An OAuthClient written before the data hash was recorded
+// never drifts.
+func DataDrift(required, existing *oauthv1.OAuthClient) (fields []string, diff string) {
+	hash, ok := existing.Annotations[api.ConfigMapDataHashAnnotation]
+	if !ok || hash == dataHash(existing) {
+		return nil, ""
+	}
+	if existing.Secret != required.Secret {
+		fields = append(fields, "secret")
+	}
+	if !equality.Semantic.DeepEqual(existing.AdditionalSecrets, required.AdditionalSecrets) {
+		fields = append(fields, "additionalSecrets")
+	}
+	if !equality.Semantic.DeepEqual(existing.RedirectURIs, required.RedirectURIs) {
+		fields = append(fields, "redirectURIs")
+		diff = cmp.Diff(existing.RedirectURIs, required.RedirectURIs)
+	}
+	return fields, diff
+}
+
+func dataHash(client *oauthv1.OAuthClient) string {
+	// the API server drops empty lists, they hash like unset ones
+	nilIfEmpty := func(values []string) []string {
+		if len(values) == 0 {
+			return nil
+		}
+		return values
+	}
+	marshalled, _ := json.Marshal(struct {
+		Secret            string   `json:"secret"`
+		AdditionalSecrets []string `json:"additionalSecrets"`
+		RedirectURIs      []string `json:"redirectURIs"`
+	}{client.Secret, nilIfEmpty(client.AdditionalSecrets), nilIfEmpty(client.RedirectURIs)})
+	sum := sha256.Sum256(marshalled)
+	return hex.EncodeToString(sum[:])
+}
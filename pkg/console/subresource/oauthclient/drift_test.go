@@ -0,0 +1,86 @@
+package oauthclient
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+)
+
+func TestDataDrift(t *testing.T) {
+	applied := func(client *oauthv1.OAuthClient) *oauthv1.OAuthClient {
+		SetDataHash(client)
+		return client
+	}
+	edited := func(client *oauthv1.OAuthClient, edit func(*oauthv1.OAuthClient)) *oauthv1.OAuthClient {
+		client = client.DeepCopy()
+		edit(client)
+		return client
+	}
+
+	tests := []struct {
+		name       string
+		required   *oauthv1.OAuthClient
+		existing   *oauthv1.OAuthClient
+		wantFields []string
+		wantDiff   bool
+	}{
+		{
+			name:     "Test operator change is not drift",
+			required: &oauthv1.OAuthClient{Secret: "new", RedirectURIs: []string{"https://new.example.com/auth/callback"}},
+			existing: applied(&oauthv1.OAuthClient{Secret: "old", RedirectURIs: []string{"https://old.example.com/auth/callback"}}),
+		},
+		{
+			name:     "Test OAuthClient written without a data hash is not drift",
+			required: &oauthv1.OAuthClient{Secret: "secret", RedirectURIs: []string{"https://console.example.com/auth/callback"}},
+			existing: &oauthv1.OAuthClient{
+				ObjectMeta:   metav1.ObjectMeta{Annotations: map[string]string{}},
+				Secret:       "edited",
+				RedirectURIs: []string{"https://edited.example.com/auth/callback"},
+			},
+		},
+		{
+			name:     "Test empty lists dropped by the API server are not drift",
+			required: &oauthv1.OAuthClient{Secret: "secret", AdditionalSecrets: []string{}},
+			existing: edited(
+				applied(&oauthv1.OAuthClient{Secret: "secret", AdditionalSecrets: []string{}}),
+				func(client *oauthv1.OAuthClient) { client.AdditionalSecrets = nil },
+			),
+		},
+		{
+			name:     "Test out of band secret change is drift without a diff",
+			required: &oauthv1.OAuthClient{Secret: "secret", RedirectURIs: []string{"https://console.example.com/auth/callback"}},
+			existing: edited(
+				applied(&oauthv1.OAuthClient{Secret: "secret", RedirectURIs: []string{"https://console.example.com/auth/callback"}}),
+				func(client *oauthv1.OAuthClient) { client.Secret = "edited" },
+			),
+			wantFields: []string{"secret"},
+		},
+		{
+			name:     "Test out of band redirect URIs change is drift",
+			required: &oauthv1.OAuthClient{Secret: "secret", RedirectURIs: []string{"https://console.example.com/auth/callback"}},
+			existing: edited(
+				applied(&oauthv1.OAuthClient{Secret: "secret", RedirectURIs: []string{"https://console.example.com/auth/callback"}}),
+				func(client *oauthv1.OAuthClient) {
+					client.AdditionalSecrets = []string{"added"}
+					client.RedirectURIs = append(client.RedirectURIs, "https://attacker.example.com/auth/callback")
+				},
+			),
+			wantFields: []string{"additionalSecrets", "redirectURIs"},
+			wantDiff:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, diff := DataDrift(tt.required, tt.existing)
+			if d := deep.Equal(fields, tt.wantFields); d != nil {
+				t.Error(d)
+			}
+			if gotDiff := len(diff) > 0; gotDiff != tt.wantDiff {
+				t.Errorf("got diff %q, want diff %v", diff, tt.wantDiff)
+			}
+		})
+	}
+}
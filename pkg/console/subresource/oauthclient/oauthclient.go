@@ -6,6 +6,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	oauthv1 "github.com/openshift/api/oauth/v1"
 	oauthclient "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
@@ -21,6 +22,7 @@ import (
 //     once its in a trustworthy state, PR to library-go so it can live with
 //     the other Apply funcs
 func CustomApplyOAuth(client oauthclient.OAuthClientsGetter, required *oauthv1.OAuthClient, ctx context.Context) (*oauthv1.OAuthClient, bool, error) {
+	SetDataHash(required)
 	existing, err := client.OAuthClients().Get(ctx, required.Name, metav1.GetOptions{})
 	if apierrors.IsNotFound(err) {
 		actual, err := client.OAuthClients().Create(ctx, required, metav1.CreateOptions{})
@@ -34,22 +36,29 @@ func CustomApplyOAuth(client oauthclient.OAuthClientsGetter, required *oauthv1.O
 	// tedious to manually copy things over
 	modified := resourcemerge.BoolPtr(false)
 	resourcemerge.EnsureObjectMeta(modified, &existing.ObjectMeta, required.ObjectMeta)
-	// at present, we only care about these two fields. this is NOT generic to all oauth clients
+	// at present, we only care about these fields. this is NOT generic to all oauth clients
 	secretSame := equality.Semantic.DeepEqual(existing.Secret, required.Secret)
+	additionalSecretsSame := equality.Semantic.DeepEqual(existing.AdditionalSecrets, required.AdditionalSecrets)
 	redirectsSame := equality.Semantic.DeepEqual(existing.RedirectURIs, required.RedirectURIs)
+	tokenPolicySame := equality.Semantic.DeepEqual(existing.AccessTokenMaxAgeSeconds, required.AccessTokenMaxAgeSeconds) &&
+		equality.Semantic.DeepEqual(existing.AccessTokenInactivityTimeoutSeconds, required.AccessTokenInactivityTimeoutSeconds)
+	grantPolicySame := existing.GrantMethod == required.GrantMethod && existing.RespondWithChallenges == required.RespondWithChallenges
 	// nothing changed, so don't update
-	if secretSame && redirectsSame && !*modified {
+	if secretSame && additionalSecretsSame && redirectsSame && tokenPolicySame && grantPolicySame && !*modified {
 		// per ApplyService, etc, if nothing changed, return nil.
 		return nil, false, nil
 	}
 	existing.Secret = required.Secret
-	// existing.AdditionalSecrets = required.AdditionalSecrets
-	// existing.RespondWithChallenges = required.RespondWithChallenges
+	// the previous secret is accepted while the console rolls out with a rotated one
+	existing.AdditionalSecrets = required.AdditionalSecrets
 	existing.RedirectURIs = required.RedirectURIs
-	// existing.GrantMethod = required.GrantMethod
+	// the grant policy of the console, set by the operator config or by hand
+	existing.GrantMethod = required.GrantMethod
+	existing.RespondWithChallenges = required.RespondWithChallenges
 	// existing.ScopeRestrictions = required.ScopeRestrictions
-	// existing.AccessTokenMaxAgeSeconds = required.AccessTokenMaxAgeSeconds
-	// existing.AccessTokenInactivityTimeoutSeconds = required.AccessTokenInactivityTimeoutSeconds
+	// the token policy of the console, set by the operator config or by hand
+	existing.AccessTokenMaxAgeSeconds = required.AccessTokenMaxAgeSeconds
+	existing.AccessTokenInactivityTimeoutSeconds = required.AccessTokenInactivityTimeoutSeconds
 	actual, err := client.OAuthClients().Update(ctx, existing, metav1.UpdateOptions{})
 	return actual, true, err
 }
@@ -66,8 +75,12 @@ func RegisterConsoleToOAuthClient(client *oauthv1.OAuthClient, host string, rand
 // Console does not have create/delete priviledges on oauth clients, only update
 func DeRegisterConsoleFromOAuthClient(client *oauthv1.OAuthClient) *oauthv1.OAuthClient {
 	client.RedirectURIs = []string{}
+	// the teardown is not drift once the console is managed again
+	delete(client.Annotations, api.ConfigMapDataHashAnnotation)
 	// changing the string to anything else will invalidate the client
 	client.Secret = crypto.Random256BitsString()
+	// the previous client secret of a rotation must not outlive the console either
+	client.AdditionalSecrets = nil
 	return client
 }
 
@@ -102,6 +115,40 @@ func SetRedirectURI(client *oauthv1.OAuthClient, host string) *oauthv1.OAuthClie
 	return client
 }
 
+// AddRedirectURIs adds the redirect URIs the client does not accept yet, after the console one.
+func AddRedirectURIs(client *oauthv1.OAuthClient, redirectURIs []string) *oauthv1.OAuthClient {
+	accepted := sets.NewString(client.RedirectURIs...)
+	for _, redirectURI := range redirectURIs {
+		if !accepted.Has(redirectURI) {
+			accepted.Insert(redirectURI)
+			client.RedirectURIs = append(client.RedirectURIs, redirectURI)
+		}
+	}
+	return client
+}
+
+// SetTokenPolicy overrides the token policy of the cluster for the console tokens.
+func SetTokenPolicy(client *oauthv1.OAuthClient, policy util.OAuthClientTokenPolicy) *oauthv1.OAuthClient {
+	if policy.AccessTokenInactivityTimeoutSeconds != nil {
+		client.AccessTokenInactivityTimeoutSeconds = policy.AccessTokenInactivityTimeoutSeconds
+	}
+	if policy.AccessTokenMaxAgeSeconds != nil {
+		client.AccessTokenMaxAgeSeconds = policy.AccessTokenMaxAgeSeconds
+	}
+	return client
+}
+
+// SetGrantPolicy sets how the console tokens are granted.
+func SetGrantPolicy(client *oauthv1.OAuthClient, policy util.OAuthClientGrantPolicy) *oauthv1.OAuthClient {
+	if len(policy.GrantMethod) > 0 {
+		client.GrantMethod = policy.GrantMethod
+	}
+	if policy.RespondWithChallenges != nil {
+		client.RespondWithChallenges = *policy.RespondWithChallenges
+	}
+	return client
+}
+
 func GetRedirectURIs(client *oauthv1.OAuthClient) []string {
 	return client.RedirectURIs
 }
@@ -133,3 +133,32 @@ func TestSetRedirectURI(t *testing.T) {
 		})
 	}
 }
+
+func TestAddRedirectURIs(t *testing.T) {
+	tests := []struct {
+		name         string
+		client       *oauthv1.OAuthClient
+		redirectURIs []string
+		want         []string
+	}{
+		{
+			name:         "Test no redirect URIs added",
+			client:       &oauthv1.OAuthClient{RedirectURIs: []string{"https://example.com/auth/callback"}},
+			redirectURIs: nil,
+			want:         []string{"https://example.com/auth/callback"},
+		},
+		{
+			name:         "Test redirect URIs added after the console one",
+			client:       &oauthv1.OAuthClient{RedirectURIs: []string{"https://example.com/auth/callback"}},
+			redirectURIs: []string{"https://proxy.example.com/auth/callback", "https://example.com/auth/callback", "https://proxy.example.com/auth/callback"},
+			want:         []string{"https://example.com/auth/callback", "https://proxy.example.com/auth/callback"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(AddRedirectURIs(tt.client, tt.redirectURIs).RedirectURIs, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package oauthtemplates
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+const (
+	// keys of the template secrets the OAuth server reads
+	LoginTemplateKey     = "login.html"
+	ProvidersTemplateKey = "providers.html"
+	ErrorTemplateKey     = "errors.html"
+
+	defaultProductName     = "OpenShift"
+	defaultBackgroundColor = "#151515"
+	defaultAccentColor     = "#0066cc"
+)
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Branding is the console customization the OAuth server login pages are rendered with.
+type Branding struct {
+	ProductName string
+	// LogoDataURI embeds the custom logo, the OAuth server does not serve the console assets
+	LogoDataURI     string
+	BackgroundColor string
+	AccentColor     string
+}
+
+// GetBranding returns the branding of the console customization. logoConfigMap is the openshift-config
+// ConfigMap of the custom logo, nil if there is none. The colors of the OAuth templates colors
+// annotation are <name>=<hex color> entries, invalid ones are left out and reported in the error.
+func GetBranding(operatorConfig *operatorv1.Console, logoConfigMap *corev1.ConfigMap) (Branding, error) {
+	branding := Branding{
+		ProductName:     operatorConfig.Spec.Customization.CustomProductName,
+		BackgroundColor: defaultBackgroundColor,
+		AccentColor:     defaultAccentColor,
+	}
+	if len(branding.ProductName) == 0 {
+		branding.ProductName = defaultProductName
+	}
+
+	if logoKey := operatorConfig.Spec.Customization.CustomLogoFile.Key; logoConfigMap != nil && len(logoKey) > 0 {
+		logo, ok := logoConfigMap.BinaryData[logoKey]
+		if !ok {
+			logo = []byte(logoConfigMap.Data[logoKey])
+		}
+		if len(logo) > 0 {
+			branding.LogoDataURI = fmt.Sprintf("data:%s;base64,%s", logoContentType(logoKey, logo), base64.StdEncoding.EncodeToString(logo))
+		}
+	}
+
+	value, ok := operatorConfig.Annotations[api.OAuthTemplatesColorsAnnotation]
+	if !ok {
+		return branding, nil
+	}
+	invalid := []string{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+		name, color, _ := strings.Cut(entry, "=")
+		name, color = strings.TrimSpace(name), strings.TrimSpace(color)
+		switch {
+		case !hexColor.MatchString(color):
+			invalid = append(invalid, entry)
+		case name == "background":
+			branding.BackgroundColor = color
+		case name == "accent":
+			branding.AccentColor = color
+		default:
+			invalid = append(invalid, entry)
+		}
+	}
+	if len(invalid) > 0 {
+		return branding, fmt.Errorf("invalid OAuth template colors, expected background=<hex color> or accent=<hex color>: %s", strings.Join(invalid, ", "))
+	}
+	return branding, nil
+}
+
+func logoContentType(key string, logo []byte) string {
+	if path.Ext(key) == ".svg" {
+		return "image/svg+xml"
+	}
+	return http.DetectContentType(logo)
+}
+
+// DefaultSecrets returns the openshift-config secrets of the login, provider selection and error
+// templates of the OAuth server, rendered with branding.
+func DefaultSecrets(branding Branding) ([]*corev1.Secret, error) {
+	secrets := []*corev1.Secret{}
+	for _, t := range []struct {
+		secretName, key, page string
+	}{
+		{api.OAuthLoginTemplateSecretName, LoginTemplateKey, "login"},
+		{api.OAuthProvidersTemplateSecretName, ProvidersTemplateKey, "providers"},
+		{api.OAuthErrorTemplateSecretName, ErrorTemplateKey, "error"},
+	} {
+		var rendered bytes.Buffer
+		if err := templates.ExecuteTemplate(&rendered, t.page, branding); err != nil {
+			return nil, fmt.Errorf("failed to render the %s template: %w", t.page, err)
+		}
+		secrets = append(secrets, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      t.secretName,
+				Namespace: api.OpenShiftConfigNamespace,
+				Labels:    map[string]string{"app": api.OpenShiftConsoleName},
+			},
+			Data: map[string][]byte{t.key: rendered.Bytes()},
+		})
+	}
+	return secrets, nil
+}
+
+// escape escapes text for HTML and for the OAuth server, which executes the rendered pages as
+// templates of their own.
+func escape(text string) string {
+	return strings.NewReplacer("{", "&#123;", "}", "&#125;").Replace(html.EscapeString(text))
+}
+
+// templates render the pages of the OAuth server, [[ ]] delimits the branding while {{ }} is
+// left for the OAuth server.
+var templates = template.Must(template.New("").Delims("[[", "]]").Funcs(template.FuncMap{"escape": escape}).Parse(`
+[[- define "head" -]]
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>[[ escape .ProductName ]]</title>
+<style>
+body { margin: 0; font-family: "Red Hat Text", "Overpass", Helvetica, Arial, sans-serif; background: [[ .BackgroundColor ]]; color: #151515; }
+main { max-width: 26rem; margin: 8vh auto; padding: 2rem; background: #fff; border-top: 4px solid [[ .AccentColor ]]; }
+.logo { display: block; max-width: 100%; max-height: 4rem; margin-bottom: 1.5rem; }
+.product { font-size: 1.5rem; margin: 0 0 1.5rem; }
+label, input, button, .provider { display: block; width: 100%; box-sizing: border-box; margin-bottom: 1rem; }
+input { padding: .5rem; border: 1px solid #8a8d90; }
+button, .provider { padding: .5rem; border: 0; background: [[ .AccentColor ]]; color: #fff; text-align: center; text-decoration: none; cursor: pointer; }
+.error { color: #c9190b; }
+</style>
+</head>
+<body>
+<main>
+[[ if .LogoDataURI ]]<img class="logo" src="[[ .LogoDataURI ]]" alt="[[ escape .ProductName ]]">[[ else ]]<p class="product">[[ escape .ProductName ]]</p>[[ end ]]
+[[- end ]]
+
+[[- define "foot" ]]
+</main>
+</body>
+</html>
+[[ end -]]
+
+[[- define "login" ]][[ template "head" . ]]
+<h1>Log in to your account</h1>
+{{ if .Error }}<p class="error" role="alert">{{ .Error }}</p>{{ end }}
+<form action="{{ .Action }}" method="POST">
+<input type="hidden" name="{{ .Names.Then }}" value="{{ .Values.Then }}">
+<input type="hidden" name="{{ .Names.CSRF }}" value="{{ .Values.CSRF }}">
+<label for="inputUsername">Username</label>
+<input type="text" id="inputUsername" name="{{ .Names.Username }}" value="{{ .Values.Username }}" autofocus autocapitalize="off" autocorrect="off" required>
+<label for="inputPassword">Password</label>
+<input type="password" id="inputPassword" name="{{ .Names.Password }}" required>
+<button type="submit">Log in</button>
+</form>
+[[- template "foot" . ]][[ end ]]
+
+[[- define "providers" ]][[ template "head" . ]]
+<h1>Log in with</h1>
+{{ range $provider := .Providers }}<a class="provider" href="{{ $provider.URL }}">{{ $provider.Name }}</a>
+{{ end }}
+[[- template "foot" . ]][[ end ]]
+
+[[- define "error" ]][[ template "head" . ]]
+<h1>Error</h1>
+<p class="error" role="alert">{{ .Error }}</p>
+[[- template "foot" . ]][[ end ]]
+`))
@@ -0,0 +1,143 @@
+package oauthtemplates
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestGetBranding(t *testing.T) {
+	tests := []struct {
+		name          string
+		customization operatorv1.ConsoleCustomization
+		annotations   map[string]string
+		logoConfigMap *corev1.ConfigMap
+		wantBranding  Branding
+		wantErr       bool
+	}{
+		{
+			name: "Test default branding",
+			wantBranding: Branding{
+				ProductName:     "OpenShift",
+				BackgroundColor: defaultBackgroundColor,
+				AccentColor:     defaultAccentColor,
+			},
+		},
+		{
+			name: "Test custom product name, logo and colors",
+			customization: operatorv1.ConsoleCustomization{
+				CustomProductName: "Example Cloud",
+				CustomLogoFile:    configv1.ConfigMapFileReference{Name: "logo", Key: "logo.svg"},
+			},
+			annotations: map[string]string{api.OAuthTemplatesColorsAnnotation: "background=#fff, accent=#AA0000"},
+			logoConfigMap: &corev1.ConfigMap{
+				Data: map[string]string{"logo.svg": "<svg/>"},
+			},
+			wantBranding: Branding{
+				ProductName:     "Example Cloud",
+				LogoDataURI:     "data:image/svg+xml;base64,PHN2Zy8+",
+				BackgroundColor: "#fff",
+				AccentColor:     "#AA0000",
+			},
+		},
+		{
+			name: "Test logo missing from the ConfigMap",
+			customization: operatorv1.ConsoleCustomization{
+				CustomLogoFile: configv1.ConfigMapFileReference{Name: "logo", Key: "logo.png"},
+			},
+			logoConfigMap: &corev1.ConfigMap{},
+			wantBranding: Branding{
+				ProductName:     "OpenShift",
+				BackgroundColor: defaultBackgroundColor,
+				AccentColor:     defaultAccentColor,
+			},
+		},
+		{
+			name:        "Test invalid colors left out",
+			annotations: map[string]string{api.OAuthTemplatesColorsAnnotation: "background=red;,accent=#00aa00,border=#000"},
+			wantBranding: Branding{
+				ProductName:     "OpenShift",
+				BackgroundColor: defaultBackgroundColor,
+				AccentColor:     "#00aa00",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+				Spec:       operatorv1.ConsoleSpec{Customization: tt.customization},
+			}
+			branding, err := GetBranding(operatorConfig, tt.logoConfigMap)
+			if diff := deep.Equal(tt.wantBranding, branding); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetBranding() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDefaultSecrets(t *testing.T) {
+	secrets, err := DefaultSecrets(Branding{
+		ProductName:     `{{ .Values.CSRF }} <b>Cloud</b>`,
+		BackgroundColor: defaultBackgroundColor,
+		AccentColor:     defaultAccentColor,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the OAuth server executes the rendered pages with its own data
+	data := map[string]interface{}{
+		"Action":    "/login",
+		"Error":     "Invalid login",
+		"ErrorCode": "access_denied",
+		"Names":     map[string]string{"Then": "then", "CSRF": "csrf", "Username": "username", "Password": "password"},
+		"Values":    map[string]string{"Then": "/", "CSRF": "secret-csrf", "Username": "kube"},
+		"Providers": []map[string]string{{"Name": "htpasswd", "URL": "/oauth/authorize?idp=htpasswd"}},
+	}
+	for _, secret := range secrets {
+		if secret.Namespace != api.OpenShiftConfigNamespace {
+			t.Errorf("%s is in namespace %q", secret.Name, secret.Namespace)
+		}
+		for key, page := range secret.Data {
+			tmpl, err := template.New(key).Parse(string(page))
+			if err != nil {
+				t.Fatalf("%s/%s does not parse: %v", secret.Name, key, err)
+			}
+			var rendered strings.Builder
+			if err := tmpl.Execute(&rendered, data); err != nil {
+				t.Fatalf("%s/%s does not execute: %v", secret.Name, key, err)
+			}
+			if strings.Contains(rendered.String(), "secret-csrf <b>") || strings.Contains(rendered.String(), "<b>Cloud") {
+				t.Errorf("%s/%s does not escape the product name:\n%s", secret.Name, key, rendered.String())
+			}
+		}
+	}
+
+	wantKeys := map[string]string{
+		api.OAuthLoginTemplateSecretName:     LoginTemplateKey,
+		api.OAuthProvidersTemplateSecretName: ProvidersTemplateKey,
+		api.OAuthErrorTemplateSecretName:     ErrorTemplateKey,
+	}
+	gotKeys := map[string]string{}
+	for _, secret := range secrets {
+		for key := range secret.Data {
+			gotKeys[secret.Name] = key
+		}
+	}
+	if diff := deep.Equal(wantKeys, gotKeys); diff != nil {
+		t.Error(diff)
+	}
+}
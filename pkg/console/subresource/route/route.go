@@ -6,6 +6,7 @@ import (
 	"encoding/pem"
 	"fmt"
 	"net/url"
+	"strconv"
 	"time"
 
 	// kube
@@ -26,12 +27,17 @@ import (
 
 	"github.com/openshift/console-operator/bindata"
 	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/console/subresource/util"
 )
 
 const (
 	// ingress instance named "default" is the OOTB ingresscontroller
 	// this is an implicit stable API
 	defaultIngressController = "default"
+
+	// per client IP rate limiting enforced by the router
+	rateLimitConnectionsAnnotation = "haproxy.router.openshift.io/rate-limit-connections"
+	rateLimitHTTPAnnotation        = "haproxy.router.openshift.io/rate-limit-connections.rate-http"
 )
 
 // holds information about custom TLS certificate and its key
@@ -45,6 +51,12 @@ type RouteConfig struct {
 	customRoute  RouteControllerSpec
 	domain       string
 	routeName    string
+	// HTTP requests per client IP allowed by the router, 0 is unlimited
+	httpRateLimitPerIP int
+	// percentage of the console traffic sent to the canary service, 0 is no canary
+	canaryWeight int
+	// the console traffic goes to the green service of a blue/green rollout
+	greenBackend bool
 }
 
 type RouteControllerSpec struct {
@@ -115,6 +127,11 @@ func NewRouteConfig(operatorConfig *operatorv1.Console, ingressConfig *configv1.
 		domain:       ingressConfig.Spec.Domain,
 		routeName:    routeName,
 	}
+	if routeName == api.OpenShiftConsoleRouteName {
+		// invalid rate limits are reported by the console operator
+		rateLimit, _ := util.GetRateLimitConfig(operatorConfig)
+		customHostnameSpec.httpRateLimitPerIP = rateLimit.RouteHTTPRequestsPerIP
+	}
 
 	return customHostnameSpec
 }
@@ -164,6 +181,9 @@ func (rc *RouteConfig) DefaultRoute(tlsConfig *CustomTLSCert, ingressConfig *con
 	}
 	route.Spec.Host = GetDefaultRouteHost(rc.routeName, ingressConfig)
 	setTLS(tlsConfig, route)
+	rc.setRateLimit(route)
+	rc.setGreenBackend(route)
+	rc.setCanaryBackend(route)
 	return route
 }
 
@@ -171,9 +191,32 @@ func (rc *RouteConfig) CustomRoute(tlsConfig *CustomTLSCert, routeName string) *
 	route := resourceread.ReadRouteV1OrDie(bindata.MustAsset(fmt.Sprintf("assets/routes/%s-custom-route.yaml", rc.routeName)))
 	route.Spec.Host = rc.customRoute.hostname
 	setTLS(tlsConfig, route)
+	rc.setRateLimit(route)
+	rc.setGreenBackend(route)
+	rc.setCanaryBackend(route)
 	return route
 }
 
+// RouteTLSSecret is an openshift-config secret serving the custom TLS certificate of a route.
+type RouteTLSSecret struct {
+	RouteName  string
+	SecretName string
+}
+
+// GetTLSSecrets returns the custom TLS secrets of the console and downloads routes.
+func GetTLSSecrets(operatorConfig *operatorv1.Console, ingressConfig *configv1.Ingress) []RouteTLSSecret {
+	secrets := []RouteTLSSecret{}
+	for _, routeName := range []string{api.OpenShiftConsoleRouteName, api.OpenShiftConsoleDownloadsRouteName} {
+		routeConfig := NewRouteConfig(operatorConfig, ingressConfig, routeName)
+		for _, secretName := range []string{routeConfig.GetDefaultTLSSecretName(), routeConfig.GetCustomTLSSecretName()} {
+			if len(secretName) != 0 {
+				secrets = append(secrets, RouteTLSSecret{RouteName: routeName, SecretName: secretName})
+			}
+		}
+	}
+	return secrets
+}
+
 func GetDefaultRouteHost(routeName string, ingressConfig *configv1.Ingress) string {
 	return fmt.Sprintf("%s-%s.%s", routeName, api.OpenShiftConsoleNamespace, ingressConfig.Spec.Domain)
 }
@@ -284,6 +327,74 @@ func setTLS(tlsConfig *CustomTLSCert, route *routev1.Route) {
 	}
 }
 
+// setRateLimit sets the router rate limiting annotations on the console routes. When no
+// limit is configured the annotations are removed, downloads routes are never limited.
+func (rc *RouteConfig) setRateLimit(route *routev1.Route) {
+	if rc.routeName != api.OpenShiftConsoleRouteName {
+		return
+	}
+	if route.Annotations == nil {
+		route.Annotations = map[string]string{}
+	}
+	if rc.httpRateLimitPerIP == 0 {
+		// a trailing dash makes the resource merge remove the annotation
+		route.Annotations[rateLimitConnectionsAnnotation+"-"] = ""
+		route.Annotations[rateLimitHTTPAnnotation+"-"] = ""
+		return
+	}
+	route.Annotations[rateLimitConnectionsAnnotation] = "true"
+	route.Annotations[rateLimitHTTPAnnotation] = strconv.Itoa(rc.httpRateLimitPerIP)
+}
+
+// SetGreenBackend sends the traffic of the routes serving the console service to the green
+// service while a blue/green rollout is cut over.
+func (rc *RouteConfig) SetGreenBackend(green bool) {
+	rc.greenBackend = green && rc.routeName == api.OpenShiftConsoleRouteName
+}
+
+// BackendConflictErr reports a canary running while a blue/green rollout is cut over, the route
+// sends all the traffic to the green service and none to the canary.
+func (rc *RouteConfig) BackendConflictErr() error {
+	if rc.greenBackend && rc.canaryWeight > 0 {
+		return fmt.Errorf("the %s route is cut over to the green console of a blue/green rollout, the canary gets no traffic: remove the %s annotation or roll back the blue/green rollout", rc.routeName, api.CanaryConfigOverridesAnnotation)
+	}
+	return nil
+}
+
+// setGreenBackend switches the route to the green service in a single update, so that all the
+// console traffic moves at once. The canary split is not applied meanwhile.
+func (rc *RouteConfig) setGreenBackend(route *routev1.Route) {
+	if !rc.greenBackend || route.Spec.To.Name != api.OpenShiftConsoleServiceName {
+		return
+	}
+	route.Spec.To.Name = api.OpenShiftConsoleGreenName
+}
+
+// SetCanaryBackend splits the traffic of the routes serving the console service with the canary
+// service, weight is the percentage sent to the canary and 0 while no canary runs.
+func (rc *RouteConfig) SetCanaryBackend(weight int) {
+	if rc.routeName != api.OpenShiftConsoleRouteName {
+		weight = 0
+	}
+	rc.canaryWeight = weight
+}
+
+// setCanaryBackend splits the traffic of the route serving the console service with the canary
+// service. Without a running canary the route has no alternate backends.
+func (rc *RouteConfig) setCanaryBackend(route *routev1.Route) {
+	if rc.canaryWeight == 0 || rc.greenBackend || route.Spec.To.Name != api.OpenShiftConsoleServiceName {
+		return
+	}
+	consoleWeight := int32(100 - rc.canaryWeight)
+	canaryWeight := int32(rc.canaryWeight)
+	route.Spec.To.Weight = &consoleWeight
+	route.Spec.AlternateBackends = []routev1.RouteTargetReference{{
+		Kind:   "Service",
+		Name:   api.OpenShiftConsoleCanaryName,
+		Weight: &canaryWeight,
+	}}
+}
+
 func GetCustomRouteName(routeName string) string {
 	return fmt.Sprintf("%s-custom", routeName)
 }
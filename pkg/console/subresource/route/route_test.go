@@ -5,7 +5,12 @@ import (
 
 	"github.com/go-test/deep"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/openshift/console-operator/pkg/api"
 )
 
 func TestGetDefaultRouteHost(t *testing.T) {
@@ -39,3 +44,205 @@ func TestGetDefaultRouteHost(t *testing.T) {
 		})
 	}
 }
+
+func TestDefaultRouteRateLimit(t *testing.T) {
+	ingressConfig := &configv1.Ingress{
+		Spec: configv1.IngressSpec{
+			Domain: "apps.devcluster.openshift.com",
+		},
+	}
+	tests := []struct {
+		name        string
+		routeName   string
+		annotations map[string]string
+		want        map[string]string
+	}{
+		{
+			name:        "Test console route without rate limit",
+			routeName:   api.OpenShiftConsoleRouteName,
+			annotations: map[string]string{},
+			want: map[string]string{
+				"haproxy.router.openshift.io/timeout": "5m",
+				rateLimitConnectionsAnnotation + "-":  "",
+				rateLimitHTTPAnnotation + "-":         "",
+			},
+		},
+		{
+			name:        "Test console route with rate limit",
+			routeName:   api.OpenShiftConsoleRouteName,
+			annotations: map[string]string{api.RouteRateLimitAnnotation: "30"},
+			want: map[string]string{
+				"haproxy.router.openshift.io/timeout": "5m",
+				rateLimitConnectionsAnnotation:        "true",
+				rateLimitHTTPAnnotation:               "30",
+			},
+		},
+		{
+			name:        "Test downloads route is not rate limited",
+			routeName:   api.OpenShiftConsoleDownloadsRouteName,
+			annotations: map[string]string{api.RouteRateLimitAnnotation: "30"},
+			want:        nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			route := NewRouteConfig(operatorConfig, ingressConfig, tt.routeName).DefaultRoute(nil, ingressConfig)
+			if diff := deep.Equal(route.Annotations, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestDefaultRouteCanaryBackend(t *testing.T) {
+	ingressConfig := &configv1.Ingress{
+		Spec: configv1.IngressSpec{
+			Domain: "apps.devcluster.openshift.com",
+		},
+	}
+	weight := func(w int32) *int32 { return &w }
+	tests := []struct {
+		name                  string
+		routeName             string
+		canaryWeight          int
+		wantWeight            *int32
+		wantAlternateBackends []routev1.RouteTargetReference
+	}{
+		{
+			name:       "Test console route without canary",
+			routeName:  api.OpenShiftConsoleRouteName,
+			wantWeight: weight(100),
+		},
+		{
+			name:         "Test console route with canary",
+			routeName:    api.OpenShiftConsoleRouteName,
+			canaryWeight: 20,
+			wantWeight:   weight(80),
+			wantAlternateBackends: []routev1.RouteTargetReference{{
+				Kind:   "Service",
+				Name:   api.OpenShiftConsoleCanaryName,
+				Weight: weight(20),
+			}},
+		},
+		{
+			name:         "Test downloads route is not split",
+			routeName:    api.OpenShiftConsoleDownloadsRouteName,
+			canaryWeight: 20,
+			wantWeight:   weight(100),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routeConfig := NewRouteConfig(&operatorv1.Console{}, ingressConfig, tt.routeName)
+			routeConfig.SetCanaryBackend(tt.canaryWeight)
+			route := routeConfig.DefaultRoute(nil, ingressConfig)
+			if diff := deep.Equal(route.Spec.To.Weight, tt.wantWeight); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(route.Spec.AlternateBackends, tt.wantAlternateBackends); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestDefaultRouteGreenBackend(t *testing.T) {
+	ingressConfig := &configv1.Ingress{
+		Spec: configv1.IngressSpec{
+			Domain: "apps.devcluster.openshift.com",
+		},
+	}
+	tests := []struct {
+		name                  string
+		routeName             string
+		annotations           map[string]string
+		canaryWeight          int
+		green                 bool
+		wantTo                string
+		wantAlternateBackends []routev1.RouteTargetReference
+		wantConflict          bool
+	}{
+		{
+			name:        "Test console route before the cut over",
+			routeName:   api.OpenShiftConsoleRouteName,
+			annotations: map[string]string{},
+			wantTo:      api.OpenShiftConsoleServiceName,
+		},
+		{
+			name:        "Test console route after the cut over",
+			routeName:   api.OpenShiftConsoleRouteName,
+			annotations: map[string]string{},
+			green:       true,
+			wantTo:      api.OpenShiftConsoleGreenName,
+		},
+		{
+			name:         "Test cut over console route is not split with the canary",
+			routeName:    api.OpenShiftConsoleRouteName,
+			annotations:  map[string]string{},
+			canaryWeight: 20,
+			green:        true,
+			wantTo:       api.OpenShiftConsoleGreenName,
+			wantConflict: true,
+		},
+		{
+			name:        "Test downloads route is not cut over",
+			routeName:   api.OpenShiftConsoleDownloadsRouteName,
+			annotations: map[string]string{},
+			green:       true,
+			wantTo:      api.OpenShiftConsoleDownloadsRouteName,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			routeConfig := NewRouteConfig(operatorConfig, ingressConfig, tt.routeName)
+			routeConfig.SetGreenBackend(tt.green)
+			routeConfig.SetCanaryBackend(tt.canaryWeight)
+			route := routeConfig.DefaultRoute(nil, ingressConfig)
+			if diff := deep.Equal(route.Spec.To.Name, tt.wantTo); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(route.Spec.AlternateBackends, tt.wantAlternateBackends); diff != nil {
+				t.Error(diff)
+			}
+			if err := routeConfig.BackendConflictErr(); (err != nil) != tt.wantConflict {
+				t.Errorf("BackendConflictErr() = %v, wantConflict %v", err, tt.wantConflict)
+			}
+		})
+	}
+}
+
+func TestGetTLSSecrets(t *testing.T) {
+	ingressConfig := &configv1.Ingress{
+		Spec: configv1.IngressSpec{
+			Domain: "apps.example.com",
+			ComponentRoutes: []configv1.ComponentRouteSpec{
+				{
+					Name:                     api.OpenShiftConsoleDownloadsRouteName,
+					Namespace:                api.OpenShiftConsoleNamespace,
+					Hostname:                 "downloads.example.com",
+					ServingCertKeyPairSecret: configv1.SecretNameReference{Name: "downloads-tls"},
+				},
+			},
+		},
+	}
+	operatorConfig := &operatorv1.Console{
+		Spec: operatorv1.ConsoleSpec{
+			Route: operatorv1.ConsoleConfigRoute{
+				Secret: configv1.SecretNameReference{Name: "console-tls"},
+			},
+		},
+	}
+	want := []RouteTLSSecret{
+		{RouteName: api.OpenShiftConsoleRouteName, SecretName: "console-tls"},
+		{RouteName: api.OpenShiftConsoleDownloadsRouteName, SecretName: "downloads-tls"},
+	}
+	if diff := deep.Equal(GetTLSSecrets(operatorConfig, ingressConfig), want); diff != nil {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,122 @@
+package secret
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+	"github.com/openshift/console-operator/pkg/crypto"
+)
+
+const (
+	// MinOAuthClientSecretRotationInterval keeps a short interval from rolling out the console
+	// over and over again
+	MinOAuthClientSecretRotationInterval = time.Hour
+
+	oauthClientSecretRotatedAtAnnotation       = "console.openshift.io/oauth-client-secret-rotated-at"
+	oauthClientSecretRotationTriggerAnnotation = "console.openshift.io/oauth-client-secret-rotation-trigger"
+)
+
+// GetOAuthClientSecretRotationInterval reads the OAuthClient secret rotation interval from the
+// operator config annotation. It returns 0, rotation on the rotate annotation only, if it is
+// unset, invalid or shorter than MinOAuthClientSecretRotationInterval.
+func GetOAuthClientSecretRotationInterval(operatorConfig *operatorv1.Console) time.Duration {
+	value, ok := operatorConfig.Annotations[api.OAuthClientSecretRotationAnnotation]
+	if !ok {
+		return 0
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval < MinOAuthClientSecretRotationInterval {
+		klog.Warningf("%s must be a duration of at least %s, ignoring %q", api.OAuthClientSecretRotationAnnotation, MinOAuthClientSecretRotationInterval, value)
+		return 0
+	}
+	return interval
+}
+
+// GetOAuthClientSecretRef returns the name of the openshift-config secret the client secret of the
+// console OAuthClient is sourced from instead of being generated, eg. synced from an external
+// secret manager by the External Secrets operator. Its 'clientSecret' key is copied to
+// console-oauth-config.
+func GetOAuthClientSecretRef(operatorConfig *operatorv1.Console) string {
+	return operatorConfig.Annotations[api.OAuthClientSecretRefAnnotation]
+}
+
+// OAuthClientSecretStalenessErr returns an error when the client secret in configSecret, the
+// source of the console client secret, was last refreshed longer than the max age annotation of
+// the operator config ago. The secret is refreshed when it is last written to, by the external
+// secret manager syncing it or by hand.
+func OAuthClientSecretStalenessErr(operatorConfig *operatorv1.Console, configSecret *corev1.Secret, now time.Time) error {
+	value, ok := operatorConfig.Annotations[api.OAuthClientSecretMaxAgeAnnotation]
+	if !ok {
+		return nil
+	}
+	maxAge, err := time.ParseDuration(value)
+	if err != nil || maxAge <= 0 {
+		klog.Warningf("%s must be a positive duration, ignoring %q", api.OAuthClientSecretMaxAgeAnnotation, value)
+		return nil
+	}
+	refreshedAt := RefreshedAt(configSecret)
+	if now.Sub(refreshedAt) < maxAge {
+		return nil
+	}
+	return fmt.Errorf("the client secret in %s/%s was last refreshed at %s, more than %s ago", configSecret.Namespace, configSecret.Name, refreshedAt.UTC().Format(time.RFC3339), maxAge)
+}
+
+// RefreshedAt returns when secret was last written to, according to its managed fields, or when
+// it was created.
+func RefreshedAt(secret *corev1.Secret) time.Time {
+	refreshedAt := secret.CreationTimestamp.Time
+	for _, managedFields := range secret.ManagedFields {
+		if managedFields.Time != nil && managedFields.Time.After(refreshedAt) {
+			refreshedAt = managedFields.Time.Time
+		}
+	}
+	return refreshedAt
+}
+
+// IsOAuthClientSecretRotationDue is true when the client secret in console-oauth-config is to be
+// rotated: the rotate annotation of the operator config was set to a value the secret was not
+// rotated for yet, or the rotation interval elapsed since the secret was last rotated, or created.
+func IsOAuthClientSecretRotationDue(operatorConfig *operatorv1.Console, clientSecret *corev1.Secret, now time.Time) bool {
+	// the external secret manager rotates a client secret it is the source of
+	if len(GetOAuthClientSecretRef(operatorConfig)) > 0 {
+		return false
+	}
+	if trigger, ok := operatorConfig.Annotations[api.OAuthClientSecretRotateAnnotation]; ok && trigger != clientSecret.Annotations[oauthClientSecretRotationTriggerAnnotation] {
+		return true
+	}
+	interval := GetOAuthClientSecretRotationInterval(operatorConfig)
+	if interval <= 0 {
+		return false
+	}
+	rotatedAt, ok := OAuthClientSecretRotatedAt(clientSecret)
+	if !ok {
+		rotatedAt = clientSecret.CreationTimestamp.Time
+	}
+	return now.Sub(rotatedAt) >= interval
+}
+
+// OAuthClientSecretRotatedAt returns when the client secret in console-oauth-config was last
+// rotated, false if it never was.
+func OAuthClientSecretRotatedAt(clientSecret *corev1.Secret) (time.Time, bool) {
+	rotatedAt, err := time.Parse(time.RFC3339, clientSecret.Annotations[oauthClientSecretRotatedAtAnnotation])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return rotatedAt, true
+}
+
+// RotatedOAuthClientSecret is the console-oauth-config secret with a new client secret, annotated
+// with the time and the rotate annotation value it was rotated at.
+func RotatedOAuthClientSecret(operatorConfig *operatorv1.Console, now time.Time) *corev1.Secret {
+	secret := DefaultSecret(operatorConfig, crypto.Random256BitsString())
+	secret.Annotations = map[string]string{
+		oauthClientSecretRotatedAtAnnotation:       now.UTC().Format(time.RFC3339),
+		oauthClientSecretRotationTriggerAnnotation: operatorConfig.Annotations[api.OAuthClientSecretRotateAnnotation],
+	}
+	return secret
+}
@@ -0,0 +1,142 @@
+package secret
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestIsOAuthClientSecretRotationDue(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	operatorConfig := func(annotations map[string]string) *operatorv1.Console {
+		return &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}
+	}
+	clientSecret := func(created time.Time, annotations map[string]string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created), Annotations: annotations}}
+	}
+
+	tests := []struct {
+		name           string
+		operatorConfig *operatorv1.Console
+		clientSecret   *corev1.Secret
+		want           bool
+	}{
+		{
+			name:           "Test no rotation configured",
+			operatorConfig: operatorConfig(nil),
+			clientSecret:   clientSecret(now.Add(-365*24*time.Hour), nil),
+			want:           false,
+		},
+		{
+			name:           "Test rotation requested",
+			operatorConfig: operatorConfig(map[string]string{api.OAuthClientSecretRotateAnnotation: "1"}),
+			clientSecret:   clientSecret(now, nil),
+			want:           true,
+		},
+		{
+			name:           "Test rotation request already handled",
+			operatorConfig: operatorConfig(map[string]string{api.OAuthClientSecretRotateAnnotation: "1"}),
+			clientSecret:   clientSecret(now, map[string]string{oauthClientSecretRotationTriggerAnnotation: "1"}),
+			want:           false,
+		},
+		{
+			name:           "Test rotation interval elapsed since creation",
+			operatorConfig: operatorConfig(map[string]string{api.OAuthClientSecretRotationAnnotation: "24h"}),
+			clientSecret:   clientSecret(now.Add(-25*time.Hour), nil),
+			want:           true,
+		},
+		{
+			name:           "Test rotation interval not elapsed since last rotation",
+			operatorConfig: operatorConfig(map[string]string{api.OAuthClientSecretRotationAnnotation: "24h"}),
+			clientSecret:   clientSecret(now.Add(-25*time.Hour), map[string]string{oauthClientSecretRotatedAtAnnotation: "2026-10-15T00:00:00Z"}),
+			want:           false,
+		},
+		{
+			name:           "Test client secret sourced from another secret not rotated",
+			operatorConfig: operatorConfig(map[string]string{api.OAuthClientSecretRotateAnnotation: "1", api.OAuthClientSecretRefAnnotation: "console-client-secret"}),
+			clientSecret:   clientSecret(now, nil),
+			want:           false,
+		},
+		{
+			name:           "Test rotation interval shorter than the minimum ignored",
+			operatorConfig: operatorConfig(map[string]string{api.OAuthClientSecretRotationAnnotation: "1m"}),
+			clientSecret:   clientSecret(now.Add(-25*time.Hour), nil),
+			want:           false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(IsOAuthClientSecretRotationDue(tt.operatorConfig, tt.clientSecret, now), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestOAuthClientSecretStalenessErr(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	operatorConfig := func(annotations map[string]string) *operatorv1.Console {
+		return &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}
+	}
+	configSecret := func(created time.Time, updated ...time.Time) *corev1.Secret {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: api.OpenShiftConfigNamespace, Name: "console-client-secret", CreationTimestamp: metav1.NewTime(created)}}
+		for i := range updated {
+			updatedAt := metav1.NewTime(updated[i])
+			secret.ManagedFields = append(secret.ManagedFields, metav1.ManagedFieldsEntry{Manager: "external-secrets", Operation: metav1.ManagedFieldsOperationUpdate, Time: &updatedAt})
+		}
+		return secret
+	}
+
+	tests := []struct {
+		name           string
+		operatorConfig *operatorv1.Console
+		configSecret   *corev1.Secret
+		wantErr        bool
+	}{
+		{
+			name:           "Test no max age",
+			operatorConfig: operatorConfig(nil),
+			configSecret:   configSecret(now.Add(-365 * 24 * time.Hour)),
+			wantErr:        false,
+		},
+		{
+			name:           "Test client secret created within its max age",
+			operatorConfig: operatorConfig(map[string]string{api.OAuthClientSecretMaxAgeAnnotation: "24h"}),
+			configSecret:   configSecret(now.Add(-time.Hour)),
+			wantErr:        false,
+		},
+		{
+			name:           "Test client secret refreshed within its max age",
+			operatorConfig: operatorConfig(map[string]string{api.OAuthClientSecretMaxAgeAnnotation: "24h"}),
+			configSecret:   configSecret(now.Add(-48*time.Hour), now.Add(-30*time.Hour), now.Add(-time.Hour)),
+			wantErr:        false,
+		},
+		{
+			name:           "Test client secret not refreshed within its max age",
+			operatorConfig: operatorConfig(map[string]string{api.OAuthClientSecretMaxAgeAnnotation: "24h"}),
+			configSecret:   configSecret(now.Add(-48*time.Hour), now.Add(-30*time.Hour)),
+			wantErr:        true,
+		},
+		{
+			name:           "Test invalid max age ignored",
+			operatorConfig: operatorConfig(map[string]string{api.OAuthClientSecretMaxAgeAnnotation: "a day"}),
+			configSecret:   configSecret(now.Add(-48 * time.Hour)),
+			wantErr:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := OAuthClientSecretStalenessErr(tt.operatorConfig, tt.configSecret, now)
+			if diff := deep.Equal(err != nil, tt.wantErr); diff != nil {
+				t.Errorf("%v: %v", diff, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package secret
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+// NextClientSecretKey holds the client secret an OIDC client secret is rotated to. It is staged
+// next to clientSecret once the OIDC provider accepts both, the console is rolled out with it when
+// the rotation is due.
+const NextClientSecretKey = "nextClientSecret"
+
+// OIDCClientSecretRotation is the rotation state of the OIDC client secret of the console.
+type OIDCClientSecretRotation struct {
+	// Staged is true while a next client secret different from the current one is staged
+	Staged bool
+	// Due is true once the staged client secret is to be rolled out
+	Due bool
+	// At is when the staged client secret is rolled out, zero for right away
+	At time.Time
+}
+
+// GetOIDCClientSecretRotation returns the rotation state of the OIDC client secret in configSecret.
+// The rotation annotation of the operator config schedules it at an RFC3339 time, without it the
+// next client secret is rolled out as soon as it is staged.
+func GetOIDCClientSecretRotation(operatorConfig *operatorv1.Console, configSecret *corev1.Secret, now time.Time) (OIDCClientSecretRotation, error) {
+	rotation := OIDCClientSecretRotation{}
+	next := configSecret.Data[NextClientSecretKey]
+	if len(next) == 0 || string(next) == GetSecretString(configSecret) {
+		return rotation, nil
+	}
+	rotation.Staged = true
+
+	if value, ok := operatorConfig.Annotations[api.OIDCClientSecretRotationAnnotation]; ok {
+		at, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			// an invalid schedule holds the rotation rather than rolling out early
+			return rotation, fmt.Errorf("invalid %s annotation, expected an RFC3339 time: %q", api.OIDCClientSecretRotationAnnotation, value)
+		}
+		rotation.At = at
+	}
+	rotation.Due = !now.Before(rotation.At)
+	return rotation, nil
+}
+
+// GetOIDCClientSecret returns the client secret the console is to be deployed with, the staged
+// next client secret once its rotation is due.
+func GetOIDCClientSecret(operatorConfig *operatorv1.Console, configSecret *corev1.Secret, now time.Time) string {
+	if rotation, err := GetOIDCClientSecretRotation(operatorConfig, configSecret, now); err == nil && rotation.Due {
+		return string(configSecret.Data[NextClientSecretKey])
+	}
+	return GetSecretString(configSecret)
+}
+
+// ValidateOIDCClientSecret checks that the client secret the console is to be deployed with is
+// set, is valid UTF-8 and has no surrounding whitespace, eg. the trailing newline of a secret
+// created from a file, which the console cannot log in with.
+func ValidateOIDCClientSecret(clientSecret string) error {
+	switch {
+	case len(clientSecret) == 0:
+		return fmt.Errorf("empty %q key", ClientSecretKey)
+	case !utf8.ValidString(clientSecret):
+		return fmt.Errorf("%q key is not valid UTF-8", ClientSecretKey)
+	case strings.TrimSpace(clientSecret) != clientSecret:
+		return fmt.Errorf("%q key has leading or trailing whitespace", ClientSecretKey)
+	}
+	return nil
+}
+
+// DefaultOIDCClientSecret is the console-oauth-config secret holding the client secret copied
+// from the configSecret the OIDC client config references. The source annotation records the
+// secret and the version it was copied from.
+func DefaultOIDCClientSecret(cr *operatorv1.Console, clientSecret string, configSecret *corev1.Secret) *corev1.Secret {
+	secret := DefaultSecret(cr, clientSecret)
+	secret.Annotations = map[string]string{
+		api.OIDCClientSecretSourceAnnotation: fmt.Sprintf("%s/%s@%s", configSecret.Namespace, configSecret.Name, configSecret.ResourceVersion),
+	}
+	return secret
+}
+
+// IsOIDCClientSecretCopyOf is true when the client secret in secret was copied from the
+// openshift-config secret named configSecretName, whatever the version it was copied from.
+func IsOIDCClientSecretCopyOf(secret *corev1.Secret, configSecretName string) bool {
+	source := secret.GetAnnotations()[api.OIDCClientSecretSourceAnnotation]
+	return strings.HasPrefix(source, fmt.Sprintf("%s/%s@", api.OpenShiftConfigNamespace, configSecretName))
+}
@@ -0,0 +1,178 @@
+package secret
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestGetOIDCClientSecretRotation(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	configSecret := func(data map[string]string) *corev1.Secret {
+		secret := &corev1.Secret{Data: map[string][]byte{}}
+		for k, v := range data {
+			secret.Data[k] = []byte(v)
+		}
+		return secret
+	}
+	operatorConfig := func(annotations map[string]string) *operatorv1.Console {
+		return &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}
+	}
+
+	tests := []struct {
+		name           string
+		operatorConfig *operatorv1.Console
+		configSecret   *corev1.Secret
+		want           OIDCClientSecretRotation
+		wantSecret     string
+		wantErr        bool
+	}{
+		{
+			name:           "Test no next client secret staged",
+			operatorConfig: operatorConfig(nil),
+			configSecret:   configSecret(map[string]string{ClientSecretKey: "current"}),
+			want:           OIDCClientSecretRotation{},
+			wantSecret:     "current",
+		},
+		{
+			name:           "Test next client secret already rolled out",
+			operatorConfig: operatorConfig(nil),
+			configSecret:   configSecret(map[string]string{ClientSecretKey: "next", NextClientSecretKey: "next"}),
+			want:           OIDCClientSecretRotation{},
+			wantSecret:     "next",
+		},
+		{
+			name:           "Test next client secret rolled out right away",
+			operatorConfig: operatorConfig(nil),
+			configSecret:   configSecret(map[string]string{ClientSecretKey: "current", NextClientSecretKey: "next"}),
+			want:           OIDCClientSecretRotation{Staged: true, Due: true},
+			wantSecret:     "next",
+		},
+		{
+			name:           "Test next client secret scheduled later",
+			operatorConfig: operatorConfig(map[string]string{api.OIDCClientSecretRotationAnnotation: "2026-10-15T13:00:00Z"}),
+			configSecret:   configSecret(map[string]string{ClientSecretKey: "current", NextClientSecretKey: "next"}),
+			want:           OIDCClientSecretRotation{Staged: true, At: now.Add(time.Hour)},
+			wantSecret:     "current",
+		},
+		{
+			name:           "Test next client secret scheduled earlier",
+			operatorConfig: operatorConfig(map[string]string{api.OIDCClientSecretRotationAnnotation: "2026-10-15T11:00:00Z"}),
+			configSecret:   configSecret(map[string]string{ClientSecretKey: "current", NextClientSecretKey: "next"}),
+			want:           OIDCClientSecretRotation{Staged: true, Due: true, At: now.Add(-time.Hour)},
+			wantSecret:     "next",
+		},
+		{
+			name:           "Test invalid schedule holds the rotation",
+			operatorConfig: operatorConfig(map[string]string{api.OIDCClientSecretRotationAnnotation: "tomorrow"}),
+			configSecret:   configSecret(map[string]string{ClientSecretKey: "current", NextClientSecretKey: "next"}),
+			want:           OIDCClientSecretRotation{Staged: true},
+			wantSecret:     "current",
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetOIDCClientSecretRotation(tt.operatorConfig, tt.configSecret, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetOIDCClientSecretRotation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := deep.Equal(tt.want, got); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(tt.wantSecret, GetOIDCClientSecret(tt.operatorConfig, tt.configSecret, now)); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestIsOIDCClientSecretCopyOf(t *testing.T) {
+	configSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "corporate-client", Namespace: api.OpenShiftConfigNamespace, ResourceVersion: "42"},
+	}
+	copied := DefaultOIDCClientSecret(&operatorv1.Console{}, "secret", configSecret)
+
+	tests := []struct {
+		name             string
+		secret           *corev1.Secret
+		configSecretName string
+		want             bool
+	}{
+		{
+			name:             "Test copy of the referenced secret",
+			secret:           copied,
+			configSecretName: "corporate-client",
+			want:             true,
+		},
+		{
+			name:             "Test copy of another secret",
+			secret:           copied,
+			configSecretName: "corporate",
+			want:             false,
+		},
+		{
+			name:             "Test secret without a source",
+			secret:           &corev1.Secret{},
+			configSecretName: "corporate-client",
+			want:             false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(tt.want, IsOIDCClientSecretCopyOf(tt.secret, tt.configSecretName)); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+	if diff := deep.Equal("openshift-config/corporate-client@42", copied.Annotations[api.OIDCClientSecretSourceAnnotation]); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestValidateOIDCClientSecret(t *testing.T) {
+	tests := []struct {
+		name         string
+		clientSecret string
+		wantErr      bool
+	}{
+		{
+			name:         "Test valid client secret",
+			clientSecret: "s3cr3t",
+		},
+		{
+			name:         "Test empty client secret",
+			clientSecret: "",
+			wantErr:      true,
+		},
+		{
+			name:         "Test client secret with a trailing newline",
+			clientSecret: "s3cr3t\n",
+			wantErr:      true,
+		},
+		{
+			name:         "Test client secret with leading whitespace",
+			clientSecret: " s3cr3t",
+			wantErr:      true,
+		},
+		{
+			name:         "Test client secret not valid UTF-8",
+			clientSecret: "s3cr3t\xff",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateOIDCClientSecret(tt.clientSecret); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateOIDCClientSecret() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
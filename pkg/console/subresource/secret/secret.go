@@ -9,7 +9,12 @@ import (
 	"github.com/openshift/console-operator/pkg/console/subresource/util"
 )
 
-const ClientSecretKey = "clientSecret"
+const (
+	ClientSecretKey = "clientSecret"
+	// PreviousClientSecretKey holds the client secret clientSecret replaced, the console pods
+	// still running with it keep logging users in until they are rolled out
+	PreviousClientSecretKey = "previousClientSecret"
+)
 
 func DefaultSecret(cr *operatorv1.Console, randomBits string) *corev1.Secret {
 	secret := Stub()
@@ -44,3 +49,24 @@ func SetSecretString(secret *corev1.Secret, randomBits string) *corev1.Secret {
 	}
 	return secret
 }
+
+func GetPreviousSecretString(secret *corev1.Secret) string {
+	return string(secret.Data[PreviousClientSecretKey])
+}
+
+// WithPreviousClientSecret keeps the client secret of existing in required as the previous one
+// when required replaces it, or the previous one of existing when it does not, so that applying
+// required only changes the secret, and rolls out the console, when the client secret does.
+func WithPreviousClientSecret(required, existing *corev1.Secret) *corev1.Secret {
+	if existing == nil {
+		return required
+	}
+	previous := GetPreviousSecretString(existing)
+	if current := GetSecretString(existing); current != GetSecretString(required) {
+		previous = current
+	}
+	if len(previous) > 0 {
+		required.Data[PreviousClientSecretKey] = []byte(previous)
+	}
+	return required
+}
@@ -143,3 +143,46 @@ func TestSetSecretString(t *testing.T) {
 		})
 	}
 }
+
+func TestWithPreviousClientSecret(t *testing.T) {
+	secret := func(data map[string]string) *corev1.Secret {
+		secret := &corev1.Secret{Data: map[string][]byte{}}
+		for k, v := range data {
+			secret.Data[k] = []byte(v)
+		}
+		return secret
+	}
+
+	tests := []struct {
+		name     string
+		required *corev1.Secret
+		existing *corev1.Secret
+		want     *corev1.Secret
+	}{
+		{
+			name:     "Test no existing client secret",
+			required: secret(map[string]string{ClientSecretKey: "new"}),
+			existing: nil,
+			want:     secret(map[string]string{ClientSecretKey: "new"}),
+		},
+		{
+			name:     "Test replaced client secret kept as the previous one",
+			required: secret(map[string]string{ClientSecretKey: "new"}),
+			existing: secret(map[string]string{ClientSecretKey: "current", PreviousClientSecretKey: "old"}),
+			want:     secret(map[string]string{ClientSecretKey: "new", PreviousClientSecretKey: "current"}),
+		},
+		{
+			name:     "Test previous client secret kept while the client secret is unchanged",
+			required: secret(map[string]string{ClientSecretKey: "current"}),
+			existing: secret(map[string]string{ClientSecretKey: "current", PreviousClientSecretKey: "old"}),
+			want:     secret(map[string]string{ClientSecretKey: "current", PreviousClientSecretKey: "old"}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(WithPreviousClientSecret(tt.required, tt.existing), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
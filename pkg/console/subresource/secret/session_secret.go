@@ -4,14 +4,29 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"strconv"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/console-operator/pkg/api"
 	"github.com/openshift/console-operator/pkg/console/subresource/util"
 )
 
+const (
+	// DefaultSessionKeyRotationInterval is used when the operator config does not
+	// set the session key rotation annotation.
+	DefaultSessionKeyRotationInterval = 30 * 24 * time.Hour
+
+	sessionKeyVersionAnnotation   = "console.openshift.io/session-key-version"
+	sessionKeyRotatedAtAnnotation = "console.openshift.io/session-key-rotated-at"
+
+	sha256KeyLenBytes = sha256.BlockSize // max key size with HMAC SHA256
+	aes256KeyLenBytes = 32               // max key size with AES (AES-256)
+)
+
 func DefaultSessionSecret(cr *operatorv1.Console) *corev1.Secret {
 	meta := util.SharedMeta()
 	meta.Name = api.SessionSecretName
@@ -26,30 +41,83 @@ func DefaultSessionSecret(cr *operatorv1.Console) *corev1.Secret {
 }
 
 func ResetSessionSecretKeysIfNeeded(secret *corev1.Secret) bool {
-	const (
-		sha256KeyLenBytes = sha256.BlockSize // max key size with HMAC SHA256
-		aes256KeyLenBytes = 32               // max key size with AES (AES-256)
-	)
-
 	var changed bool
 
 	if secret.Data == nil {
 		secret.Data = map[string][]byte{}
 	}
 
-	if len(secret.Data["sessionEncryptionKey"]) != aes256KeyLenBytes {
-		secret.Data["sessionEncryptionKey"] = []byte(randomString(aes256KeyLenBytes))
+	if len(secret.Data[api.SessionEncryptionKey]) != aes256KeyLenBytes {
+		secret.Data[api.SessionEncryptionKey] = []byte(randomString(aes256KeyLenBytes))
 		changed = true
 	}
 
-	if len(secret.Data["sessionAuthenticationKey"]) != sha256KeyLenBytes {
-		secret.Data["sessionAuthenticationKey"] = []byte(randomString(sha256KeyLenBytes))
+	if len(secret.Data[api.SessionAuthenticationKey]) != sha256KeyLenBytes {
+		secret.Data[api.SessionAuthenticationKey] = []byte(randomString(sha256KeyLenBytes))
 		changed = true
 	}
 
+	if changed {
+		markSessionKeysRotated(secret, time.Now())
+	}
+
 	return changed
 }
 
+// RotateSessionSecretKeysIfNeeded moves the current session keys into the
+// previous key slots and generates a new current key pair once the rotation
+// interval has elapsed. The console keeps accepting cookies sealed with the
+// previous keys, so existing sessions survive a single rotation.
+// A non-positive rotationInterval disables rotation.
+func RotateSessionSecretKeysIfNeeded(secret *corev1.Secret, rotationInterval time.Duration, now time.Time) bool {
+	if rotationInterval <= 0 {
+		return false
+	}
+
+	rotatedAt, err := time.Parse(time.RFC3339, secret.Annotations[sessionKeyRotatedAtAnnotation])
+	if err != nil {
+		// secrets created before rotation was introduced have no timestamp,
+		// start counting from now rather than rotating immediately
+		markSessionKeysRotated(secret, now)
+		return true
+	}
+	if now.Sub(rotatedAt) < rotationInterval {
+		return false
+	}
+
+	klog.V(2).Infof("rotating session keys last rotated at %s", rotatedAt.Format(time.RFC3339))
+	secret.Data[api.PreviousSessionEncryptionKey] = secret.Data[api.SessionEncryptionKey]
+	secret.Data[api.PreviousSessionAuthenticationKey] = secret.Data[api.SessionAuthenticationKey]
+	secret.Data[api.SessionEncryptionKey] = []byte(randomString(aes256KeyLenBytes))
+	secret.Data[api.SessionAuthenticationKey] = []byte(randomString(sha256KeyLenBytes))
+	markSessionKeysRotated(secret, now)
+	return true
+}
+
+// GetSessionKeyRotationInterval reads the rotation interval from the operator config
+// annotation, falling back to DefaultSessionKeyRotationInterval if unset or invalid.
+func GetSessionKeyRotationInterval(operatorConfig *operatorv1.Console) time.Duration {
+	value, ok := operatorConfig.Annotations[api.SessionKeyRotationAnnotation]
+	if !ok {
+		return DefaultSessionKeyRotationInterval
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil {
+		klog.Warningf("invalid %s annotation value %q, using default of %s: %v", api.SessionKeyRotationAnnotation, value, DefaultSessionKeyRotationInterval, err)
+		return DefaultSessionKeyRotationInterval
+	}
+	return interval
+}
+
+func markSessionKeysRotated(secret *corev1.Secret, now time.Time) {
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	version, _ := strconv.Atoi(secret.Annotations[sessionKeyVersionAnnotation])
+	secret.Annotations[sessionKeyVersionAnnotation] = strconv.Itoa(version + 1)
+	secret.Annotations[sessionKeyRotatedAtAnnotation] = now.UTC().Format(time.RFC3339)
+}
+
 // needs to be in lib-go
 func randomBytes(size int) []byte {
 	b := make([]byte, size)
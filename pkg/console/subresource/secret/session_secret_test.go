@@ -0,0 +1,148 @@
+package secret
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/console-operator/pkg/api"
+)
+
+func TestRotateSessionSecretKeysIfNeeded(t *testing.T) {
+	now := time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)
+	currentEncryption := []byte(randomString(aes256KeyLenBytes))
+	currentAuthentication := []byte(randomString(sha256KeyLenBytes))
+
+	secretRotatedAt := func(rotatedAt string) *corev1.Secret {
+		annotations := map[string]string{}
+		if len(rotatedAt) > 0 {
+			annotations[sessionKeyVersionAnnotation] = "1"
+			annotations[sessionKeyRotatedAtAnnotation] = rotatedAt
+		}
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+			Data: map[string][]byte{
+				api.SessionEncryptionKey:     currentEncryption,
+				api.SessionAuthenticationKey: currentAuthentication,
+			},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		secret          *corev1.Secret
+		interval        time.Duration
+		wantChanged     bool
+		wantRotated     bool
+		wantVersion     string
+		wantRotatedAtTS string
+	}{
+		{
+			name:            "Test rotation disabled",
+			secret:          secretRotatedAt("2023-01-01T00:00:00Z"),
+			interval:        0,
+			wantChanged:     false,
+			wantVersion:     "1",
+			wantRotatedAtTS: "2023-01-01T00:00:00Z",
+		},
+		{
+			name:            "Test rotation not yet due",
+			secret:          secretRotatedAt("2024-01-30T00:00:00Z"),
+			interval:        DefaultSessionKeyRotationInterval,
+			wantChanged:     false,
+			wantVersion:     "1",
+			wantRotatedAtTS: "2024-01-30T00:00:00Z",
+		},
+		{
+			name:            "Test rotation due",
+			secret:          secretRotatedAt("2024-01-01T00:00:00Z"),
+			interval:        DefaultSessionKeyRotationInterval,
+			wantChanged:     true,
+			wantRotated:     true,
+			wantVersion:     "2",
+			wantRotatedAtTS: "2024-01-31T00:00:00Z",
+		},
+		{
+			name:            "Test secret without rotation timestamp is stamped but not rotated",
+			secret:          secretRotatedAt(""),
+			interval:        DefaultSessionKeyRotationInterval,
+			wantChanged:     true,
+			wantVersion:     "1",
+			wantRotatedAtTS: "2024-01-31T00:00:00Z",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := RotateSessionSecretKeysIfNeeded(tt.secret, tt.interval, now)
+			if diff := deep.Equal(changed, tt.wantChanged); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(tt.secret.Annotations[sessionKeyVersionAnnotation], tt.wantVersion); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(tt.secret.Annotations[sessionKeyRotatedAtAnnotation], tt.wantRotatedAtTS); diff != nil {
+				t.Error(diff)
+			}
+
+			if !tt.wantRotated {
+				if _, ok := tt.secret.Data[api.PreviousSessionEncryptionKey]; ok {
+					t.Errorf("expected no previous session keys")
+				}
+				return
+			}
+			if diff := deep.Equal(tt.secret.Data[api.PreviousSessionEncryptionKey], currentEncryption); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(tt.secret.Data[api.PreviousSessionAuthenticationKey], currentAuthentication); diff != nil {
+				t.Error(diff)
+			}
+			if string(tt.secret.Data[api.SessionEncryptionKey]) == string(currentEncryption) {
+				t.Errorf("expected a new session encryption key")
+			}
+			if len(tt.secret.Data[api.SessionAuthenticationKey]) != sha256KeyLenBytes {
+				t.Errorf("expected a %d byte session authentication key", sha256KeyLenBytes)
+			}
+		})
+	}
+}
+
+func TestGetSessionKeyRotationInterval(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        time.Duration
+	}{
+		{
+			name: "Test default interval",
+			want: DefaultSessionKeyRotationInterval,
+		},
+		{
+			name:        "Test custom interval",
+			annotations: map[string]string{api.SessionKeyRotationAnnotation: "168h"},
+			want:        168 * time.Hour,
+		},
+		{
+			name:        "Test disabled rotation",
+			annotations: map[string]string{api.SessionKeyRotationAnnotation: "0"},
+			want:        0,
+		},
+		{
+			name:        "Test invalid interval",
+			annotations: map[string]string{api.SessionKeyRotationAnnotation: "weekly"},
+			want:        DefaultSessionKeyRotationInterval,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			if diff := deep.Equal(GetSessionKeyRotationInterval(operatorConfig), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
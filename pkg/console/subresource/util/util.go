@@ -2,19 +2,25 @@ package util
 
 import (
 	"fmt"
+	"math"
+	"net/url"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
-	"golang.org/x/exp/slices"
 	yaml "gopkg.in/yaml.v2"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/klog/v2"
 
 	configv1 "github.com/openshift/api/config/v1"
+	oauthv1 "github.com/openshift/api/oauth/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/console-operator/pkg/api"
 	proxyv1alpha1 "open-cluster-management.io/cluster-proxy/pkg/apis/proxy/v1alpha1"
@@ -45,6 +51,23 @@ func LabelsForConsole() map[string]string {
 	return allLabels
 }
 
+// LabelsForCanary labels the canary console pods, which the console service must not select.
+func LabelsForCanary() map[string]string {
+	return map[string]string{
+		"app":       api.OpenShiftConsoleName,
+		"component": "ui-canary",
+	}
+}
+
+// LabelsForGreen labels the pods of the green console of a blue/green rollout, which the
+// console service must not select.
+func LabelsForGreen() map[string]string {
+	return map[string]string{
+		"app":       api.OpenShiftConsoleName,
+		"component": "ui-green",
+	}
+}
+
 func LabelsForDownloads() map[string]string {
 	return map[string]string{
 		"app":       api.OpenShiftConsoleName,
@@ -152,19 +175,911 @@ func RemoveDuplicateStr(strSlice []string) []string {
 	return list
 }
 
+// OIDCProviderClient is the console client of one of the OIDC providers of the cluster.
+type OIDCProviderClient struct {
+	Provider configv1.OIDCProvider
+	Client   configv1.OIDCClientConfig
+	// Endpoint the issuer is reached at when it differs from the advertised issuer URL
+	Endpoint string
+}
+
+// GetOIDCProviderClients returns the console clients of the OIDC providers, in the order of the
+// providers. Providers without a console client are left out.
+func GetOIDCProviderClients(authnConfig *configv1.Authentication) []OIDCProviderClient {
+	providerClients := []OIDCProviderClient{}
+	for _, provider := range authnConfig.Spec.OIDCProviders {
+		for _, client := range provider.OIDCClients {
+			if client.ComponentNamespace == api.TargetNamespace && client.ComponentName == api.OpenShiftConsoleName {
+				providerClients = append(providerClients, OIDCProviderClient{Provider: provider, Client: client})
+				break
+			}
+		}
+	}
+	return providerClients
+}
+
+// GetOIDCCLIClient returns the client the oc CLI logs in to provider with, declared for the cli
+// component of openshift-console, or nil if the provider has none.
+func GetOIDCCLIClient(provider configv1.OIDCProvider) *configv1.OIDCClientConfig {
+	for i, client := range provider.OIDCClients {
+		if client.ComponentNamespace == api.TargetNamespace && client.ComponentName == api.OpenShiftCLIComponentName {
+			return &provider.OIDCClients[i]
+		}
+	}
+	return nil
+}
+
+// GetPrimaryOIDCProvider returns the first OIDC provider with a console client, or the first
+// provider when none has one yet.
+func GetPrimaryOIDCProvider(authnConfig *configv1.Authentication) *configv1.OIDCProvider {
+	if providerClients := GetOIDCProviderClients(authnConfig); len(providerClients) > 0 {
+		return &providerClients[0].Provider
+	}
+	if len(authnConfig.Spec.OIDCProviders) > 0 {
+		return &authnConfig.Spec.OIDCProviders[0]
+	}
+	return nil
+}
+
+// GetOIDCClientConfig returns the console client of the first OIDC provider that has one, the
+// primary provider whose client secret and CA the console is deployed with.
 func GetOIDCClientConfig(authnConfig *configv1.Authentication) *configv1.OIDCClientConfig {
-	if len(authnConfig.Spec.OIDCProviders) == 0 {
+	providerClients := GetOIDCProviderClients(authnConfig)
+	if len(providerClients) == 0 {
 		return nil
 	}
+	return &providerClients[0].Client
+}
 
-	var oidcClientConfig *configv1.OIDCClientConfig
-	slices.IndexFunc[configv1.OIDCClientConfig](authnConfig.Spec.OIDCProviders[0].OIDCClients, func(oc configv1.OIDCClientConfig) bool {
-		if oc.ComponentNamespace == api.TargetNamespace && oc.ComponentName == api.OpenShiftConsoleName {
-			oidcClientConfig = &oc
-			return true
-		}
+// GetOIDCSecretProviderClass returns the SecretProviderClass the console OIDC client secret
+// is mounted from, or an empty string if the secret is copied into console-oauth-config.
+func GetOIDCSecretProviderClass(operatorConfig *operatorv1.Console, authnConfig *configv1.Authentication) string {
+	if authnConfig.Spec.Type != configv1.AuthenticationTypeOIDC {
+		return ""
+	}
+	return operatorConfig.Annotations[api.OIDCSecretProviderClassAnnotation]
+}
+
+// IsAuthenticationDisabled is true when the cluster has no identity provider. The console then
+// runs without a login, and without an OAuth client or client secret.
+func IsAuthenticationDisabled(authnConfig *configv1.Authentication) bool {
+	return authnConfig.Spec.Type == configv1.AuthenticationTypeNone
+}
+
+// IsOIDCPublicClient is true when the console logs in as a public OIDC client using PKCE. The
+// public client annotation of the operator config only applies to a console client without
+// a client secret reference.
+func IsOIDCPublicClient(operatorConfig *operatorv1.Console, authnConfig *configv1.Authentication) bool {
+	if authnConfig.Spec.Type != configv1.AuthenticationTypeOIDC {
+		return false
+	}
+	clientConfig := GetOIDCClientConfig(authnConfig)
+	if clientConfig == nil || len(clientConfig.ClientSecret.Name) > 0 {
 		return false
-	})
+	}
+	publicClient, err := strconv.ParseBool(operatorConfig.Annotations[api.OIDCPublicClientAnnotation])
+	return err == nil && publicClient
+}
+
+// GetOIDCIssuerEndpoints returns the URLs the console reaches the issuers of the OIDC providers
+// at, by provider name, for providers advertising an issuer URL the cluster does not resolve the
+// same way, eg. behind split-horizon DNS. The annotation lists <provider>=<https URL> entries,
+// invalid ones are left out and reported in the error.
+func GetOIDCIssuerEndpoints(operatorConfig *operatorv1.Console) (map[string]string, error) {
+	value, ok := operatorConfig.Annotations[api.OIDCIssuerEndpointsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	endpoints := map[string]string{}
+	invalid := []string{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+		provider, endpoint, _ := strings.Cut(entry, "=")
+		provider, endpoint = strings.TrimSpace(provider), strings.TrimSpace(endpoint)
+		endpointURL, err := url.Parse(endpoint)
+		if err != nil || len(provider) == 0 || endpointURL.Scheme != "https" || len(endpointURL.Host) == 0 {
+			invalid = append(invalid, entry)
+			continue
+		}
+		endpoints[provider] = endpoint
+	}
+	if len(invalid) > 0 {
+		return endpoints, fmt.Errorf("invalid OIDC issuer endpoints, expected <provider>=<https URL>: %s", strings.Join(invalid, ", "))
+	}
+	return endpoints, nil
+}
 
-	return oidcClientConfig
+// GetOAuthServerHost returns the host of the route of the integrated OAuth server, customized
+// through the component routes of the ingress config.
+func GetOAuthServerHost(ingressConfig *configv1.Ingress) string {
+	host := "oauth-openshift." + ingressConfig.Spec.Domain
+	for _, componentRoute := range ingressConfig.Spec.ComponentRoutes {
+		if componentRoute.Namespace == api.OpenShiftAuthenticationNamespace && componentRoute.Name == api.OAuthRouteName {
+			host = string(componentRoute.Hostname)
+		}
+	}
+	return host
+}
+
+// GetOAuthRedirectURIs returns the redirect URIs the console OAuthClient accepts on top of the
+// callback of the console route, for the console served under other hostnames, eg. through
+// additional domains or a reverse proxy. The annotation lists https URLs, invalid ones are left
+// out and reported in the error.
+func GetOAuthRedirectURIs(operatorConfig *operatorv1.Console) ([]string, error) {
+	value, ok := operatorConfig.Annotations[api.OAuthRedirectURIsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	redirectURIs := []string{}
+	invalid := []string{}
+	for _, redirectURI := range strings.Split(value, ",") {
+		redirectURI = strings.TrimSpace(redirectURI)
+		if len(redirectURI) == 0 {
+			continue
+		}
+		parsed, err := url.Parse(redirectURI)
+		if err != nil || parsed.Scheme != "https" || len(parsed.Host) == 0 || len(parsed.Fragment) > 0 {
+			invalid = append(invalid, redirectURI)
+			continue
+		}
+		redirectURIs = append(redirectURIs, redirectURI)
+	}
+	if len(invalid) > 0 {
+		return redirectURIs, fmt.Errorf("invalid OAuth redirect URIs, expected https URLs: %s", strings.Join(invalid, ", "))
+	}
+	return redirectURIs, nil
+}
+
+const (
+	// bounds of the OIDC token validation leeway, values outside of them are ignored
+	OIDCMinTokenLeeway = time.Second
+	OIDCMaxTokenLeeway = 10 * time.Minute
+)
+
+// OIDCTokenValidationConfig is the leeway the console validates the OIDC tokens with, requested
+// by the OIDC annotations of the operator config for providers with skewed clocks. Zero values
+// keep the console defaults.
+type OIDCTokenValidationConfig struct {
+	// ClockSkew tolerated on the expiry, issued at and not before times of the tokens
+	ClockSkew time.Duration
+	// IDTokenLeeway is how long before its expiry the ID token is refreshed
+	IDTokenLeeway time.Duration
+}
+
+// GetOIDCTokenValidationConfig returns the requested token validation leeway, only with OIDC
+// authentication. Invalid values are ignored and keep the console default.
+func GetOIDCTokenValidationConfig(operatorConfig *operatorv1.Console, authnConfig *configv1.Authentication) OIDCTokenValidationConfig {
+	if authnConfig.Spec.Type != configv1.AuthenticationTypeOIDC {
+		return OIDCTokenValidationConfig{}
+	}
+	return OIDCTokenValidationConfig{
+		ClockSkew:     durationAnnotation(operatorConfig, api.OIDCClockSkewAnnotation, OIDCMinTokenLeeway, OIDCMaxTokenLeeway),
+		IDTokenLeeway: durationAnnotation(operatorConfig, api.OIDCIDTokenLeewayAnnotation, OIDCMinTokenLeeway, OIDCMaxTokenLeeway),
+	}
+}
+
+const (
+	AccessLogFormatCommon      = "common"
+	AccessLogFormatJSON        = "json"
+	AccessLogDestinationStdout = "stdout"
+	// AccessLogDestinationFile writes the access logs to the PersistentVolumeClaim in the
+	// console namespace named by the access-log-claim annotation, one directory per pod.
+	// Replicas run on different nodes, so the claim needs the ReadWriteMany access mode.
+	AccessLogDestinationFile = "file"
+
+	// guardrails on the volume of access logs, they are not configurable
+	AccessLogMaxEventsPerSecond = 100
+	AccessLogMaxFileSizeMB      = 50
+	AccessLogMaxFileBackups     = 3
+)
+
+// AccessLogConfig is the console backend access log configuration requested by the
+// access-log annotations of the operator config.
+type AccessLogConfig struct {
+	Format      string
+	Destination string
+	// the PersistentVolumeClaim the file destination writes to
+	Claim         string
+	SamplingRatio float64
+}
+
+// GetAccessLogConfig returns the requested access log configuration, or nil if access
+// logging is not enabled. Access logging is enabled by setting a format; invalid values
+// fall back to the defaults so that a typo never turns auditing off, and are reported in
+// the returned error. The file destination without a claim falls back to stdout, files
+// that do not outlive the pod are no audit trail.
+func GetAccessLogConfig(operatorConfig *operatorv1.Console) (*AccessLogConfig, error) {
+	format, enabled := operatorConfig.Annotations[api.AccessLogFormatAnnotation]
+	if !enabled {
+		return nil, nil
+	}
+	config := &AccessLogConfig{
+		Format:        AccessLogFormatCommon,
+		Destination:   AccessLogDestinationStdout,
+		SamplingRatio: 1,
+	}
+
+	invalid := []string{}
+	switch format {
+	case "":
+	case AccessLogFormatCommon, AccessLogFormatJSON:
+		config.Format = format
+	default:
+		invalid = append(invalid, fmt.Sprintf("%s %q is not %s or %s", api.AccessLogFormatAnnotation, format, AccessLogFormatCommon, AccessLogFormatJSON))
+	}
+
+	switch destination := operatorConfig.Annotations[api.AccessLogDestinationAnnotation]; destination {
+	case "":
+	case AccessLogDestinationStdout:
+		config.Destination = destination
+	case AccessLogDestinationFile:
+		claim := operatorConfig.Annotations[api.AccessLogClaimAnnotation]
+		if len(claim) == 0 {
+			invalid = append(invalid, fmt.Sprintf("%s %q requires a PersistentVolumeClaim in %s", api.AccessLogDestinationAnnotation, destination, api.AccessLogClaimAnnotation))
+			break
+		}
+		config.Destination = destination
+		config.Claim = claim
+	default:
+		invalid = append(invalid, fmt.Sprintf("%s %q is not %s or %s", api.AccessLogDestinationAnnotation, destination, AccessLogDestinationStdout, AccessLogDestinationFile))
+	}
+
+	if sampling, ok := operatorConfig.Annotations[api.AccessLogSamplingAnnotation]; ok {
+		ratio, err := strconv.ParseFloat(sampling, 64)
+		if err != nil || ratio <= 0 || ratio > 1 {
+			invalid = append(invalid, fmt.Sprintf("%s %q is not in (0, 1]", api.AccessLogSamplingAnnotation, sampling))
+		} else {
+			config.SamplingRatio = ratio
+		}
+	}
+	if len(invalid) > 0 {
+		return config, fmt.Errorf("invalid access log configuration, using the defaults instead: %s", strings.Join(invalid, ", "))
+	}
+	return config, nil
+}
+
+// RateLimitConfig is the rate limiting and brute-force protection requested by the
+// rate-limit and login-lockout annotations of the operator config. Zero values are off.
+type RateLimitConfig struct {
+	// login requests per minute per client IP
+	LoginRequestsPerMinute int
+	// failed logins of a user after which further attempts are refused for LoginLockoutDuration
+	LoginLockoutThreshold int
+	LoginLockoutDuration  time.Duration
+	// proxied API requests per second per user
+	APIProxyRequestsPerSecond int
+	// HTTP requests per client IP enforced by the router on the console routes
+	RouteHTTPRequestsPerIP int
+}
+
+// GetRateLimitConfig returns the requested rate limits. Invalid values leave the corresponding
+// limit off and are reported in the returned error.
+func GetRateLimitConfig(operatorConfig *operatorv1.Console) (RateLimitConfig, error) {
+	config := RateLimitConfig{}
+	invalid := []string{}
+	for annotation, limit := range map[string]*int{
+		api.LoginRateLimitAnnotation:    &config.LoginRequestsPerMinute,
+		api.APIProxyRateLimitAnnotation: &config.APIProxyRequestsPerSecond,
+		api.RouteRateLimitAnnotation:    &config.RouteHTTPRequestsPerIP,
+	} {
+		value, err := parsePositiveIntAnnotation(operatorConfig, annotation)
+		if err != nil {
+			invalid = append(invalid, err.Error())
+		}
+		*limit = value
+	}
+
+	// login-lockout is <failed attempts>/<lockout duration>, eg. 5/15m
+	if lockout, ok := operatorConfig.Annotations[api.LoginLockoutAnnotation]; ok {
+		attempts, duration, _ := strings.Cut(lockout, "/")
+		threshold, thresholdErr := strconv.Atoi(attempts)
+		lockoutDuration, durationErr := time.ParseDuration(duration)
+		if thresholdErr != nil || durationErr != nil || threshold <= 0 || lockoutDuration < time.Second {
+			invalid = append(invalid, fmt.Sprintf("%s must be <failed attempts>/<duration> such as 5/15m, ignoring %q", api.LoginLockoutAnnotation, lockout))
+		} else {
+			config.LoginLockoutThreshold = threshold
+			config.LoginLockoutDuration = lockoutDuration
+		}
+	}
+	if len(invalid) > 0 {
+		// map iteration order is random, keep the message stable
+		sort.Strings(invalid)
+		return config, fmt.Errorf("invalid rate limits are left off: %s", strings.Join(invalid, ", "))
+	}
+	return config, nil
+}
+
+// positiveIntAnnotation is parsePositiveIntAnnotation for the annotations whose invalid values
+// are only logged.
+func positiveIntAnnotation(operatorConfig *operatorv1.Console, annotation string) int {
+	value, err := parsePositiveIntAnnotation(operatorConfig, annotation)
+	if err != nil {
+		klog.Warning(err)
+	}
+	return value
+}
+
+// parsePositiveIntAnnotation returns the positive integer of an annotation, 0 when it is not set
+// or invalid.
+func parsePositiveIntAnnotation(operatorConfig *operatorv1.Console, annotation string) (int, error) {
+	value, ok := operatorConfig.Annotations[annotation]
+	if !ok {
+		return 0, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer, ignoring %q", annotation, value)
+	}
+	return parsed, nil
+}
+
+const (
+	// bounds of the console backend HTTP server tuning, values outside of them are ignored
+	ServerMinTimeout        = time.Second
+	ServerMaxTimeout        = time.Hour
+	ServerMinMaxHeaderBytes = 4 << 10
+	ServerMaxMaxHeaderBytes = 1 << 20
+)
+
+// ServerTuningConfig is the console backend HTTP server tuning requested by the server
+// annotations of the operator config. Zero values keep the console defaults.
+type ServerTuningConfig struct {
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	// how long idle keep-alive connections are kept open
+	IdleTimeout    time.Duration
+	MaxHeaderBytes int
+	// gzip compression of responses, nil keeps the console default
+	Compression *bool
+}
+
+// GetServerTuningConfig returns the requested server tuning. Invalid values are ignored
+// and keep the console default.
+func GetServerTuningConfig(operatorConfig *operatorv1.Console) ServerTuningConfig {
+	config := ServerTuningConfig{
+		ReadTimeout:  durationAnnotation(operatorConfig, api.ServerReadTimeoutAnnotation, ServerMinTimeout, ServerMaxTimeout),
+		WriteTimeout: durationAnnotation(operatorConfig, api.ServerWriteTimeoutAnnotation, ServerMinTimeout, ServerMaxTimeout),
+		IdleTimeout:  durationAnnotation(operatorConfig, api.ServerIdleTimeoutAnnotation, ServerMinTimeout, ServerMaxTimeout),
+	}
+
+	if maxHeaderBytes := positiveIntAnnotation(operatorConfig, api.ServerMaxHeaderBytesAnnotation); maxHeaderBytes != 0 {
+		if maxHeaderBytes < ServerMinMaxHeaderBytes || maxHeaderBytes > ServerMaxMaxHeaderBytes {
+			klog.Warningf("%s must be between %d and %d, ignoring %d", api.ServerMaxHeaderBytesAnnotation, ServerMinMaxHeaderBytes, ServerMaxMaxHeaderBytes, maxHeaderBytes)
+		} else {
+			config.MaxHeaderBytes = maxHeaderBytes
+		}
+	}
+
+	if value, ok := operatorConfig.Annotations[api.ServerCompressionAnnotation]; ok {
+		compression, err := strconv.ParseBool(value)
+		if err != nil {
+			klog.Warningf("%s must be a boolean, ignoring %q", api.ServerCompressionAnnotation, value)
+		} else {
+			config.Compression = &compression
+		}
+	}
+	return config
+}
+
+const (
+	// bounds of the console backend Kubernetes API client tuning, values outside of them are ignored
+	APIClientMaxQPS     = 1000
+	APIClientMaxBurst   = 2000
+	APIClientMinTimeout = time.Second
+	APIClientMaxTimeout = 5 * time.Minute
+)
+
+// APIClientConfig is the client side rate limiting and timeout of the console backend clients of
+// the Kubernetes API, requested by the api-client annotations of the operator config. Zero values
+// keep the console defaults.
+type APIClientConfig struct {
+	QPS   int
+	Burst int
+	// Timeout of a single request to the API server
+	Timeout time.Duration
+}
+
+// GetAPIClientConfig returns the requested API client tuning. Invalid values keep the console
+// defaults and are reported in the returned error. A burst lower than the QPS is raised to it, the
+// client would never reach its QPS otherwise.
+func GetAPIClientConfig(operatorConfig *operatorv1.Console) (APIClientConfig, error) {
+	config := APIClientConfig{}
+	invalid := []string{}
+	timeout, err := parseDurationAnnotation(operatorConfig, api.APIClientTimeoutAnnotation, APIClientMinTimeout, APIClientMaxTimeout)
+	if err != nil {
+		invalid = append(invalid, err.Error())
+	}
+	config.Timeout = timeout
+
+	qps, err := parsePositiveIntAnnotation(operatorConfig, api.APIClientQPSAnnotation)
+	switch {
+	case err != nil:
+		invalid = append(invalid, err.Error())
+	case qps > APIClientMaxQPS:
+		invalid = append(invalid, fmt.Sprintf("%s must not be more than %d, ignoring %d", api.APIClientQPSAnnotation, APIClientMaxQPS, qps))
+	default:
+		config.QPS = qps
+	}
+	burst, err := parsePositiveIntAnnotation(operatorConfig, api.APIClientBurstAnnotation)
+	switch {
+	case err != nil:
+		invalid = append(invalid, err.Error())
+	case burst > APIClientMaxBurst:
+		invalid = append(invalid, fmt.Sprintf("%s must not be more than %d, ignoring %d", api.APIClientBurstAnnotation, APIClientMaxBurst, burst))
+	default:
+		config.Burst = burst
+	}
+	if config.Burst != 0 && config.Burst < config.QPS {
+		klog.Warningf("%s %d is lower than %s %d, using %d", api.APIClientBurstAnnotation, config.Burst, api.APIClientQPSAnnotation, config.QPS, config.QPS)
+		config.Burst = config.QPS
+	}
+	if len(invalid) > 0 {
+		return config, fmt.Errorf("invalid API client tuning, using the console defaults instead: %s", strings.Join(invalid, ", "))
+	}
+	return config, nil
+}
+
+// durationAnnotation is parseDurationAnnotation for the annotations whose invalid values are only
+// logged.
+func durationAnnotation(operatorConfig *operatorv1.Console, annotation string, min, max time.Duration) time.Duration {
+	duration, err := parseDurationAnnotation(operatorConfig, annotation, min, max)
+	if err != nil {
+		klog.Warning(err)
+	}
+	return duration
+}
+
+// parseDurationAnnotation returns the duration of an annotation, 0 when it is not set or not
+// between min and max.
+func parseDurationAnnotation(operatorConfig *operatorv1.Console, annotation string, min, max time.Duration) (time.Duration, error) {
+	value, ok := operatorConfig.Annotations[annotation]
+	if !ok {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < min || duration > max {
+		return 0, fmt.Errorf("%s must be a duration between %s and %s, ignoring %q", annotation, min, max, value)
+	}
+	return duration, nil
+}
+
+const (
+	CanaryDefaultWeight   = 10
+	CanaryMaxWeight       = 50
+	CanaryDefaultDuration = 15 * time.Minute
+	CanaryMinDuration     = time.Minute
+	CanaryMaxDuration     = 24 * time.Hour
+)
+
+// CanaryConfig is the canary of a candidate console config requested by the canary
+// annotations of the operator config.
+type CanaryConfig struct {
+	// Overrides are merged into console-config like unsupportedConfigOverrides
+	Overrides []byte
+	// Weight is the percentage of the console route traffic sent to the canary
+	Weight int
+	// Duration the canary has to stay healthy before it is promoted
+	Duration time.Duration
+}
+
+// GetCanaryConfig returns the requested canary, or nil if there is no candidate config.
+// Invalid weights and durations fall back to the defaults.
+func GetCanaryConfig(operatorConfig *operatorv1.Console) *CanaryConfig {
+	overrides, ok := operatorConfig.Annotations[api.CanaryConfigOverridesAnnotation]
+	if !ok || len(strings.TrimSpace(overrides)) == 0 {
+		return nil
+	}
+	config := &CanaryConfig{
+		Overrides: []byte(overrides),
+		Weight:    CanaryDefaultWeight,
+		Duration:  CanaryDefaultDuration,
+	}
+	if weight := positiveIntAnnotation(operatorConfig, api.CanaryWeightAnnotation); weight > CanaryMaxWeight {
+		klog.Warningf("%s must not be more than %d, using %d", api.CanaryWeightAnnotation, CanaryMaxWeight, CanaryDefaultWeight)
+	} else if weight > 0 {
+		config.Weight = weight
+	}
+	if duration := durationAnnotation(operatorConfig, api.CanaryDurationAnnotation, CanaryMinDuration, CanaryMaxDuration); duration > 0 {
+		config.Duration = duration
+	}
+	return config
+}
+
+const (
+	// RolloutStrategyBlueGreen rolls console-config changes out to a green console first
+	RolloutStrategyBlueGreen = "BlueGreen"
+	// RolloutStrategyRollingUpdate updates the console deployment in place, the default
+	RolloutStrategyRollingUpdate = "RollingUpdate"
+
+	BlueGreenDefaultDuration = 5 * time.Minute
+	BlueGreenMinDuration     = time.Minute
+	BlueGreenMaxDuration     = 24 * time.Hour
+)
+
+// BlueGreenConfig is the blue/green rollout requested by the rollout-strategy annotation of
+// the operator config.
+type BlueGreenConfig struct {
+	// Duration the green console has to stay healthy before the route is cut over to it,
+	// and to serve the console traffic before its config is promoted
+	Duration time.Duration
+	// Rollback is the value of the rollback annotation, a new value rolls back the rollout
+	Rollback string
+}
+
+// GetBlueGreenConfig returns the requested blue/green rollout, or nil if console-config
+// changes are rolled out in place. An invalid duration falls back to the default.
+func GetBlueGreenConfig(operatorConfig *operatorv1.Console) *BlueGreenConfig {
+	strategy, ok := operatorConfig.Annotations[api.RolloutStrategyAnnotation]
+	if !ok || strategy == RolloutStrategyRollingUpdate {
+		return nil
+	}
+	if strategy != RolloutStrategyBlueGreen {
+		klog.Warningf("%s must be %s or %s, ignoring %q", api.RolloutStrategyAnnotation, RolloutStrategyBlueGreen, RolloutStrategyRollingUpdate, strategy)
+		return nil
+	}
+	config := &BlueGreenConfig{
+		Duration: BlueGreenDefaultDuration,
+		Rollback: operatorConfig.Annotations[api.BlueGreenRollbackAnnotation],
+	}
+	if duration := durationAnnotation(operatorConfig, api.BlueGreenDurationAnnotation, BlueGreenMinDuration, BlueGreenMaxDuration); duration > 0 {
+		config.Duration = duration
+	}
+	return config
+}
+
+// GetGroupInactivityTimeouts parses the group-inactivity-timeouts annotation, a comma separated
+// list of <group>=<duration> such as contractors=15m,sre=8h, into timeouts in seconds.
+// Invalid entries are left out and reported in the returned error.
+func GetGroupInactivityTimeouts(operatorConfig *operatorv1.Console) (map[string]int, error) {
+	value, ok := operatorConfig.Annotations[api.GroupInactivityTimeoutsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	timeouts := map[string]int{}
+	invalid := []string{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+		group, timeout, _ := strings.Cut(entry, "=")
+		duration, err := time.ParseDuration(strings.TrimSpace(timeout))
+		group = strings.TrimSpace(group)
+		if err != nil || len(group) == 0 || duration < time.Second {
+			invalid = append(invalid, entry)
+			continue
+		}
+		timeouts[group] = int(duration.Seconds())
+	}
+	if len(invalid) > 0 {
+		return timeouts, fmt.Errorf("invalid group inactivity timeouts, expected <group>=<duration>: %s", strings.Join(invalid, ", "))
+	}
+	return timeouts, nil
+}
+
+// SessionPolicy is the lifetime of the console sessions, set by the OAuth token policy of the
+// cluster. Zero leaves the console defaults.
+type SessionPolicy struct {
+	InactivityTimeoutSeconds int
+	AccessTokenMaxAgeSeconds int
+}
+
+// GetSessionPolicy returns the token policy of the console OAuth client, falling back to the one
+// of the cluster OAuth config. There is no such policy with an OIDC provider.
+func GetSessionPolicy(authConfig *configv1.Authentication, oauthConfig *configv1.OAuth, oauthClient *oauthv1.OAuthClient) SessionPolicy {
+	policy := SessionPolicy{}
+	if authConfig.Spec.Type != "" && authConfig.Spec.Type != configv1.AuthenticationTypeIntegratedOAuth {
+		return policy
+	}
+	if oauthConfig != nil {
+		if timeout := oauthConfig.Spec.TokenConfig.AccessTokenInactivityTimeout; timeout != nil {
+			policy.InactivityTimeoutSeconds = int(timeout.Seconds())
+		}
+		policy.AccessTokenMaxAgeSeconds = int(oauthConfig.Spec.TokenConfig.AccessTokenMaxAgeSeconds)
+	}
+	if oauthClient != nil {
+		if oauthClient.AccessTokenInactivityTimeoutSeconds != nil {
+			policy.InactivityTimeoutSeconds = int(*oauthClient.AccessTokenInactivityTimeoutSeconds)
+		}
+		if oauthClient.AccessTokenMaxAgeSeconds != nil {
+			policy.AccessTokenMaxAgeSeconds = int(*oauthClient.AccessTokenMaxAgeSeconds)
+		}
+	}
+	return policy
+}
+
+// OAuthMinInactivityTimeout is the shortest token inactivity timeout the OAuth server accepts.
+const OAuthMinInactivityTimeout = 300 * time.Second
+
+// OAuthClientTokenPolicy is the token policy the console OAuthClient is registered with, requested
+// by the access token annotations of the operator config. Nil fields leave the OAuthClient as is,
+// zero disables the inactivity timeout or the expiry of the console tokens.
+type OAuthClientTokenPolicy struct {
+	AccessTokenInactivityTimeoutSeconds *int32
+	AccessTokenMaxAgeSeconds            *int32
+}
+
+// GetOAuthClientTokenPolicy parses the access token annotations of the operator config. Invalid
+// values are left out and reported in the returned error.
+func GetOAuthClientTokenPolicy(operatorConfig *operatorv1.Console) (OAuthClientTokenPolicy, error) {
+	policy := OAuthClientTokenPolicy{}
+	invalid := []string{}
+	if value, ok := operatorConfig.Annotations[api.AccessTokenInactivityAnnotation]; ok {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout < 0 || (timeout > 0 && timeout < OAuthMinInactivityTimeout) {
+			invalid = append(invalid, fmt.Sprintf("%s must be 0 or a duration of at least %s: %q", api.AccessTokenInactivityAnnotation, OAuthMinInactivityTimeout, value))
+		} else {
+			seconds := int32(timeout.Seconds())
+			policy.AccessTokenInactivityTimeoutSeconds = &seconds
+		}
+	}
+	if value, ok := operatorConfig.Annotations[api.AccessTokenMaxAgeAnnotation]; ok {
+		maxAge, err := time.ParseDuration(value)
+		if err != nil || maxAge < 0 || maxAge.Seconds() > math.MaxInt32 {
+			invalid = append(invalid, fmt.Sprintf("%s must be 0 or a positive duration: %q", api.AccessTokenMaxAgeAnnotation, value))
+		} else {
+			seconds := int32(maxAge.Seconds())
+			policy.AccessTokenMaxAgeSeconds = &seconds
+		}
+	}
+	if len(invalid) > 0 {
+		return policy, fmt.Errorf("invalid access token policy: %s", strings.Join(invalid, ", "))
+	}
+	return policy, nil
+}
+
+// OAuthClientGrantPolicy is how the console OAuthClient grants its tokens, requested by the grant
+// annotations of the operator config. Unset fields leave the OAuthClient as is.
+type OAuthClientGrantPolicy struct {
+	// GrantMethod is auto to approve the console grants or prompt to ask the users for consent
+	GrantMethod oauthv1.GrantHandlerType
+	// RespondWithChallenges answers unauthenticated requests with WWW-Authenticate challenges
+	// instead of redirecting them to the login page
+	RespondWithChallenges *bool
+}
+
+// GetOAuthClientGrantPolicy parses the grant annotations of the operator config. Invalid values
+// are left out and reported in the returned error.
+func GetOAuthClientGrantPolicy(operatorConfig *operatorv1.Console) (OAuthClientGrantPolicy, error) {
+	policy := OAuthClientGrantPolicy{}
+	invalid := []string{}
+	if value, ok := operatorConfig.Annotations[api.OAuthGrantMethodAnnotation]; ok {
+		switch grantMethod := oauthv1.GrantHandlerType(value); grantMethod {
+		case oauthv1.GrantHandlerAuto, oauthv1.GrantHandlerPrompt:
+			policy.GrantMethod = grantMethod
+		default:
+			invalid = append(invalid, fmt.Sprintf("%s must be %q or %q: %q", api.OAuthGrantMethodAnnotation, oauthv1.GrantHandlerAuto, oauthv1.GrantHandlerPrompt, value))
+		}
+	}
+	if value, ok := operatorConfig.Annotations[api.OAuthChallengesAnnotation]; ok {
+		respond, err := strconv.ParseBool(value)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s must be true or false: %q", api.OAuthChallengesAnnotation, value))
+		} else {
+			policy.RespondWithChallenges = &respond
+		}
+	}
+	if len(invalid) > 0 {
+		return policy, fmt.Errorf("invalid OAuth grant policy: %s", strings.Join(invalid, ", "))
+	}
+	return policy, nil
+}
+
+// String identifies the policy in the console-config and the console deployment, so that a
+// policy change rolls out the console and is visible on the rollout.
+func (p SessionPolicy) String() string {
+	return fmt.Sprintf("inactivity-%ds-max-age-%ds", p.InactivityTimeoutSeconds, p.AccessTokenMaxAgeSeconds)
+}
+
+// ContentSecurityPolicyDirectives are the CSP directives the console lets cluster admins and
+// plugins extend. Sources are appended to the ones the console allows by default.
+var ContentSecurityPolicyDirectives = sets.NewString(
+	"default-src",
+	"script-src",
+	"style-src",
+	"img-src",
+	"font-src",
+	"connect-src",
+	"frame-src",
+	"object-src",
+)
+
+// ValidateContentSecurityPolicySource rejects sources that would weaken the console CSP
+// instead of extending it, eg. * or 'unsafe-eval'.
+func ValidateContentSecurityPolicySource(source string) error {
+	switch {
+	case len(source) == 0:
+		return fmt.Errorf("source must not be empty")
+	case strings.ContainsAny(source, " \t\n;,\"'") && source != "'self'" && source != "'none'":
+		return fmt.Errorf("source %q must be a host source, 'self' or 'none'", source)
+	case source == "*" || source == "http:" || source == "https:":
+		return fmt.Errorf("source %q allows any host", source)
+	case strings.HasPrefix(source, "http://"):
+		return fmt.Errorf("source %q is not served over https", source)
+	}
+	return nil
+}
+
+// ParseContentSecurityPolicy parses a policy in CSP syntax, eg.
+// "script-src https://analytics.example.com; img-src https://cdn.example.com", into sources by
+// directive. Unknown directives and invalid sources are left out and reported in the returned error.
+func ParseContentSecurityPolicy(policy string) (map[string][]string, error) {
+	parsed := map[string][]string{}
+	invalid := []string{}
+	for _, directive := range strings.Split(policy, ";") {
+		fields := strings.Fields(directive)
+		if len(fields) == 0 {
+			continue
+		}
+		name := strings.ToLower(fields[0])
+		if !ContentSecurityPolicyDirectives.Has(name) {
+			invalid = append(invalid, fmt.Sprintf("unsupported directive %q", name))
+			continue
+		}
+		for _, source := range fields[1:] {
+			if err := ValidateContentSecurityPolicySource(source); err != nil {
+				invalid = append(invalid, fmt.Sprintf("%s: %v", name, err))
+				continue
+			}
+			parsed[name] = append(parsed[name], source)
+		}
+	}
+	if len(invalid) > 0 {
+		return MergeContentSecurityPolicies(parsed), fmt.Errorf("invalid content security policy: %s", strings.Join(invalid, ", "))
+	}
+	return MergeContentSecurityPolicies(parsed), nil
+}
+
+// GetContentSecurityPolicy returns the CSP extensions requested by the content-security-policy
+// annotation of the operator config.
+func GetContentSecurityPolicy(operatorConfig *operatorv1.Console) (map[string][]string, error) {
+	policy, ok := operatorConfig.Annotations[api.ContentSecurityPolicyAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	return ParseContentSecurityPolicy(policy)
+}
+
+// MergeContentSecurityPolicies returns the union of the sources of every policy, sorted and
+// without duplicates, or nil if there are none.
+func MergeContentSecurityPolicies(policies ...map[string][]string) map[string][]string {
+	merged := map[string]sets.String{}
+	for _, policy := range policies {
+		for directive, sources := range policy {
+			if _, ok := merged[directive]; !ok {
+				merged[directive] = sets.NewString()
+			}
+			merged[directive].Insert(sources...)
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	result := map[string][]string{}
+	for directive, sources := range merged {
+		result[directive] = sources.List()
+	}
+	return result
+}
+
+// ContentSecurityPolicyAllows reports whether one of the sources, in the syntax accepted by
+// ValidateContentSecurityPolicySource, matches target, eg. https://*.example.com allows
+// https://status.example.com/api/v2/summary.json.
+func ContentSecurityPolicyAllows(sources []string, target *url.URL) bool {
+	for _, source := range sources {
+		if source == "'self'" || source == "'none'" {
+			continue
+		}
+		// scheme sources such as data: allow a scheme rather than a host
+		if strings.HasSuffix(source, ":") && !strings.Contains(source, "/") {
+			if strings.TrimSuffix(source, ":") == target.Scheme {
+				return true
+			}
+			continue
+		}
+		scheme, host := "https", source
+		if parts := strings.SplitN(source, "://", 2); len(parts) == 2 {
+			scheme, host = parts[0], parts[1]
+		}
+		sourcePath := ""
+		if i := strings.Index(host, "/"); i >= 0 {
+			host, sourcePath = host[:i], host[i:]
+		}
+		sourcePort := ""
+		if h, p, found := strings.Cut(host, ":"); found {
+			host, sourcePort = h, p
+		}
+		if scheme != target.Scheme {
+			continue
+		}
+		if sourcePort != "" && sourcePort != "*" && sourcePort != target.Port() && !(sourcePort == "443" && target.Port() == "") {
+			continue
+		}
+		if wildcard, ok := strings.CutPrefix(host, "*."); ok {
+			if !strings.HasSuffix(target.Hostname(), "."+wildcard) {
+				continue
+			}
+		} else if host != target.Hostname() {
+			continue
+		}
+		// a path ending with a slash allows everything below it, any other path only itself
+		if sourcePath != "" && sourcePath != "/" && sourcePath != target.Path && !(strings.HasSuffix(sourcePath, "/") && strings.HasPrefix(target.Path, sourcePath)) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+const (
+	StatusProviderTypeStatuspage = "statuspage"
+	StatusProviderTypeJSON       = "json"
+
+	StatusProviderDefaultInterval = 5 * time.Minute
+	StatusProviderMinInterval     = 30 * time.Second
+	StatusProviderMaxInterval     = time.Hour
+)
+
+// StatusProviderTypes are the status feeds the console can render, the summary API of a
+// statuspage.io page or a custom JSON feed.
+var StatusProviderTypes = sets.NewString(StatusProviderTypeStatuspage, StatusProviderTypeJSON)
+
+// StatusProviderConfig is the external status feed requested by the status-provider annotations
+// of the operator config.
+type StatusProviderConfig struct {
+	Type string
+	URL  string
+	// PollInterval is how often the console refreshes the feed
+	PollInterval time.Duration
+}
+
+// GetStatusProviderConfig returns the requested status feed, or nil if there is none. The
+// console polls the feed from the browser, so its URL has to be allowed by the connect-src
+// directive of contentSecurityPolicy, a feed the console could not reach is reported instead.
+// An invalid poll interval falls back to the default.
+func GetStatusProviderConfig(operatorConfig *operatorv1.Console, contentSecurityPolicy map[string][]string) (*StatusProviderConfig, error) {
+	feedURL, ok := operatorConfig.Annotations[api.StatusProviderURLAnnotation]
+	if !ok || len(feedURL) == 0 {
+		if _, ok := operatorConfig.Annotations[api.StatusProviderTypeAnnotation]; ok {
+			return nil, fmt.Errorf("invalid status provider: %s is required", api.StatusProviderURLAnnotation)
+		}
+		return nil, nil
+	}
+	config := &StatusProviderConfig{
+		Type:         operatorConfig.Annotations[api.StatusProviderTypeAnnotation],
+		URL:          feedURL,
+		PollInterval: StatusProviderDefaultInterval,
+	}
+	if !StatusProviderTypes.Has(config.Type) {
+		return nil, fmt.Errorf("invalid status provider: %s %q is not one of %s", api.StatusProviderTypeAnnotation, config.Type, strings.Join(StatusProviderTypes.List(), ", "))
+	}
+	parsed, err := url.Parse(feedURL)
+	if err != nil || parsed.Scheme != "https" || len(parsed.Host) == 0 {
+		return nil, fmt.Errorf("invalid status provider: %s %q must be an https URL", api.StatusProviderURLAnnotation, feedURL)
+	}
+	if !ContentSecurityPolicyAllows(contentSecurityPolicy["connect-src"], parsed) {
+		return nil, fmt.Errorf("invalid status provider: %s %q is not reachable from the console, allow it in the connect-src directive of %s", api.StatusProviderURLAnnotation, feedURL, api.ContentSecurityPolicyAnnotation)
+	}
+	if interval := durationAnnotation(operatorConfig, api.StatusProviderIntervalAnnotation, StatusProviderMinInterval, StatusProviderMaxInterval); interval > 0 {
+		config.PollInterval = interval
+	}
+	return config, nil
+}
+
+const (
+	PreUpgradeDefaultWindow = 72 * time.Hour
+	PreUpgradeMinWindow     = time.Hour
+	PreUpgradeMaxWindow     = 30 * 24 * time.Hour
+)
+
+// GetPreUpgradeWindow returns how long the console has to stay healthy across an upgrade,
+// custom route certificates expiring within it fail the pre-upgrade checks.
+func GetPreUpgradeWindow(operatorConfig *operatorv1.Console) time.Duration {
+	if window := durationAnnotation(operatorConfig, api.PreUpgradeWindowAnnotation, PreUpgradeMinWindow, PreUpgradeMaxWindow); window > 0 {
+		return window
+	}
+	return PreUpgradeDefaultWindow
 }
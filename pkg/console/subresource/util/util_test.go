@@ -1,12 +1,16 @@
 package util
 
 import (
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/go-test/deep"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	configv1 "github.com/openshift/api/config/v1"
+	oauthv1 "github.com/openshift/api/oauth/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 	"github.com/openshift/console-operator/pkg/api"
 )
@@ -200,3 +204,833 @@ func TestRemoveDuplicateStr(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAccessLogConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        *AccessLogConfig
+		wantErr     bool
+	}{
+		{
+			name:        "Test access logging disabled",
+			annotations: map[string]string{},
+			want:        nil,
+		},
+		{
+			name:        "Test access logging with defaults",
+			annotations: map[string]string{api.AccessLogFormatAnnotation: ""},
+			want:        &AccessLogConfig{Format: AccessLogFormatCommon, Destination: AccessLogDestinationStdout, SamplingRatio: 1},
+		},
+		{
+			name: "Test access logging to a file with sampling",
+			annotations: map[string]string{
+				api.AccessLogFormatAnnotation:      "json",
+				api.AccessLogDestinationAnnotation: "file",
+				api.AccessLogClaimAnnotation:       "console-access-logs",
+				api.AccessLogSamplingAnnotation:    "0.1",
+			},
+			want: &AccessLogConfig{Format: AccessLogFormatJSON, Destination: AccessLogDestinationFile, Claim: "console-access-logs", SamplingRatio: 0.1},
+		},
+		{
+			name: "Test access logging to a file without a claim falls back to stdout",
+			annotations: map[string]string{
+				api.AccessLogFormatAnnotation:      "json",
+				api.AccessLogDestinationAnnotation: "file",
+			},
+			want:    &AccessLogConfig{Format: AccessLogFormatJSON, Destination: AccessLogDestinationStdout, SamplingRatio: 1},
+			wantErr: true,
+		},
+		{
+			name: "Test invalid values fall back to defaults",
+			annotations: map[string]string{
+				api.AccessLogFormatAnnotation:      "xml",
+				api.AccessLogDestinationAnnotation: "syslog",
+				api.AccessLogSamplingAnnotation:    "2",
+			},
+			want:    &AccessLogConfig{Format: AccessLogFormatCommon, Destination: AccessLogDestinationStdout, SamplingRatio: 1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := GetAccessLogConfig(operatorConfig)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetAccessLogConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetRateLimitConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        RateLimitConfig
+		wantErr     bool
+	}{
+		{
+			name:        "Test rate limits off by default",
+			annotations: map[string]string{},
+			want:        RateLimitConfig{},
+		},
+		{
+			name: "Test all rate limits",
+			annotations: map[string]string{
+				api.LoginRateLimitAnnotation:    "10",
+				api.LoginLockoutAnnotation:      "5/15m",
+				api.APIProxyRateLimitAnnotation: "50",
+				api.RouteRateLimitAnnotation:    "100",
+			},
+			want: RateLimitConfig{
+				LoginRequestsPerMinute:    10,
+				LoginLockoutThreshold:     5,
+				LoginLockoutDuration:      15 * time.Minute,
+				APIProxyRequestsPerSecond: 50,
+				RouteHTTPRequestsPerIP:    100,
+			},
+		},
+		{
+			name: "Test invalid rate limits are ignored",
+			annotations: map[string]string{
+				api.LoginRateLimitAnnotation:    "-1",
+				api.LoginLockoutAnnotation:      "5",
+				api.APIProxyRateLimitAnnotation: "fast",
+			},
+			want:    RateLimitConfig{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := GetRateLimitConfig(operatorConfig)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetRateLimitConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetServerTuningConfig(t *testing.T) {
+	compression := false
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        ServerTuningConfig
+	}{
+		{
+			name:        "Test console defaults without server tuning",
+			annotations: map[string]string{},
+			want:        ServerTuningConfig{},
+		},
+		{
+			name: "Test all server tuning",
+			annotations: map[string]string{
+				api.ServerReadTimeoutAnnotation:    "30s",
+				api.ServerWriteTimeoutAnnotation:   "10m",
+				api.ServerIdleTimeoutAnnotation:    "2m",
+				api.ServerMaxHeaderBytesAnnotation: "65536",
+				api.ServerCompressionAnnotation:    "false",
+			},
+			want: ServerTuningConfig{
+				ReadTimeout:    30 * time.Second,
+				WriteTimeout:   10 * time.Minute,
+				IdleTimeout:    2 * time.Minute,
+				MaxHeaderBytes: 65536,
+				Compression:    &compression,
+			},
+		},
+		{
+			name: "Test invalid server tuning is ignored",
+			annotations: map[string]string{
+				api.ServerReadTimeoutAnnotation:    "100ms",
+				api.ServerWriteTimeoutAnnotation:   "2h",
+				api.ServerIdleTimeoutAnnotation:    "forever",
+				api.ServerMaxHeaderBytesAnnotation: "1024",
+				api.ServerCompressionAnnotation:    "gzip",
+			},
+			want: ServerTuningConfig{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			if diff := deep.Equal(GetServerTuningConfig(operatorConfig), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestGetAPIClientConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        APIClientConfig
+		wantErr     bool
+	}{
+		{
+			name:        "Test console defaults without API client tuning",
+			annotations: map[string]string{},
+			want:        APIClientConfig{},
+		},
+		{
+			name: "Test all API client tuning",
+			annotations: map[string]string{
+				api.APIClientQPSAnnotation:     "100",
+				api.APIClientBurstAnnotation:   "200",
+				api.APIClientTimeoutAnnotation: "45s",
+			},
+			want: APIClientConfig{
+				QPS:     100,
+				Burst:   200,
+				Timeout: 45 * time.Second,
+			},
+		},
+		{
+			name: "Test burst lower than QPS is raised",
+			annotations: map[string]string{
+				api.APIClientQPSAnnotation:   "100",
+				api.APIClientBurstAnnotation: "20",
+			},
+			want: APIClientConfig{
+				QPS:   100,
+				Burst: 100,
+			},
+		},
+		{
+			name: "Test invalid API client tuning is ignored",
+			annotations: map[string]string{
+				api.APIClientQPSAnnotation:     "5000",
+				api.APIClientBurstAnnotation:   "-1",
+				api.APIClientTimeoutAnnotation: "1h",
+			},
+			want:    APIClientConfig{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := GetAPIClientConfig(operatorConfig)
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetAPIClientConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetGroupInactivityTimeouts(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        map[string]int
+		wantErr     bool
+	}{
+		{
+			name:        "Test no group overrides",
+			annotations: map[string]string{},
+			want:        nil,
+		},
+		{
+			name:        "Test group overrides",
+			annotations: map[string]string{api.GroupInactivityTimeoutsAnnotation: "contractors=15m, sre=8h"},
+			want:        map[string]int{"contractors": 900, "sre": 28800},
+		},
+		{
+			name:        "Test invalid group overrides are left out",
+			annotations: map[string]string{api.GroupInactivityTimeoutsAnnotation: "contractors=15m,sre,=1h,admins=forever"},
+			want:        map[string]int{"contractors": 900},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := GetGroupInactivityTimeouts(operatorConfig)
+			if diff := deep.Equal(err != nil, tt.wantErr); diff != nil {
+				t.Errorf("%v: %v", diff, err)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestGetOIDCIssuerEndpoints(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        map[string]string
+		wantErr     bool
+	}{
+		{
+			name:        "Test no issuer endpoints",
+			annotations: map[string]string{},
+			want:        nil,
+		},
+		{
+			name:        "Test issuer endpoints",
+			annotations: map[string]string{api.OIDCIssuerEndpointsAnnotation: "keycloak=https://keycloak.idp.svc:8443/realms/ocp, azure=https://10.0.0.5"},
+			want:        map[string]string{"keycloak": "https://keycloak.idp.svc:8443/realms/ocp", "azure": "https://10.0.0.5"},
+		},
+		{
+			name:        "Test invalid issuer endpoints are left out",
+			annotations: map[string]string{api.OIDCIssuerEndpointsAnnotation: "keycloak=https://keycloak.idp.svc,azure=http://10.0.0.5,=https://idp.example.com,okta"},
+			want:        map[string]string{"keycloak": "https://keycloak.idp.svc"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := GetOIDCIssuerEndpoints(operatorConfig)
+			if diff := deep.Equal(err != nil, tt.wantErr); diff != nil {
+				t.Errorf("%v: %v", diff, err)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestGetOAuthRedirectURIs(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        []string
+		wantErr     bool
+	}{
+		{
+			name:        "Test no redirect URIs",
+			annotations: map[string]string{},
+			want:        nil,
+		},
+		{
+			name:        "Test redirect URIs",
+			annotations: map[string]string{api.OAuthRedirectURIsAnnotation: "https://console.apps.example.com/auth/callback, https://proxy.example.com:8443/console/auth/callback"},
+			want:        []string{"https://console.apps.example.com/auth/callback", "https://proxy.example.com:8443/console/auth/callback"},
+		},
+		{
+			name:        "Test invalid redirect URIs are left out",
+			annotations: map[string]string{api.OAuthRedirectURIsAnnotation: "https://console.apps.example.com/auth/callback,http://console.example.com/auth/callback,/auth/callback,https://console.example.com/auth/callback#top"},
+			want:        []string{"https://console.apps.example.com/auth/callback"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := GetOAuthRedirectURIs(operatorConfig)
+			if diff := deep.Equal(err != nil, tt.wantErr); diff != nil {
+				t.Errorf("%v: %v", diff, err)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestGetOAuthClientTokenPolicy(t *testing.T) {
+	seconds := func(s int32) *int32 { return &s }
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        OAuthClientTokenPolicy
+		wantErr     bool
+	}{
+		{
+			name:        "Test no token policy",
+			annotations: map[string]string{},
+			want:        OAuthClientTokenPolicy{},
+		},
+		{
+			name: "Test token policy",
+			annotations: map[string]string{
+				api.AccessTokenInactivityAnnotation: "15m",
+				api.AccessTokenMaxAgeAnnotation:     "8h",
+			},
+			want: OAuthClientTokenPolicy{AccessTokenInactivityTimeoutSeconds: seconds(900), AccessTokenMaxAgeSeconds: seconds(28800)},
+		},
+		{
+			name: "Test token policy disabling the timeouts",
+			annotations: map[string]string{
+				api.AccessTokenInactivityAnnotation: "0s",
+				api.AccessTokenMaxAgeAnnotation:     "0s",
+			},
+			want: OAuthClientTokenPolicy{AccessTokenInactivityTimeoutSeconds: seconds(0), AccessTokenMaxAgeSeconds: seconds(0)},
+		},
+		{
+			name: "Test invalid token policy is left out",
+			annotations: map[string]string{
+				api.AccessTokenInactivityAnnotation: "1m",
+				api.AccessTokenMaxAgeAnnotation:     "8h",
+			},
+			want:    OAuthClientTokenPolicy{AccessTokenMaxAgeSeconds: seconds(28800)},
+			wantErr: true,
+		},
+		{
+			name: "Test unparsable token policy is left out",
+			annotations: map[string]string{
+				api.AccessTokenMaxAgeAnnotation: "a day",
+			},
+			want:    OAuthClientTokenPolicy{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := GetOAuthClientTokenPolicy(operatorConfig)
+			if diff := deep.Equal(err != nil, tt.wantErr); diff != nil {
+				t.Errorf("%v: %v", diff, err)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestGetOAuthClientGrantPolicy(t *testing.T) {
+	respond := func(b bool) *bool { return &b }
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        OAuthClientGrantPolicy
+		wantErr     bool
+	}{
+		{
+			name:        "Test no grant policy",
+			annotations: map[string]string{},
+			want:        OAuthClientGrantPolicy{},
+		},
+		{
+			name: "Test grant policy prompting for consent",
+			annotations: map[string]string{
+				api.OAuthGrantMethodAnnotation: "prompt",
+				api.OAuthChallengesAnnotation:  "false",
+			},
+			want: OAuthClientGrantPolicy{GrantMethod: oauthv1.GrantHandlerPrompt, RespondWithChallenges: respond(false)},
+		},
+		{
+			name: "Test grant policy responding with challenges",
+			annotations: map[string]string{
+				api.OAuthChallengesAnnotation: "true",
+			},
+			want: OAuthClientGrantPolicy{RespondWithChallenges: respond(true)},
+		},
+		{
+			name: "Test denying grant method is left out",
+			annotations: map[string]string{
+				api.OAuthGrantMethodAnnotation: "deny",
+				api.OAuthChallengesAnnotation:  "true",
+			},
+			want:    OAuthClientGrantPolicy{RespondWithChallenges: respond(true)},
+			wantErr: true,
+		},
+		{
+			name: "Test unparsable challenges are left out",
+			annotations: map[string]string{
+				api.OAuthGrantMethodAnnotation: "auto",
+				api.OAuthChallengesAnnotation:  "sometimes",
+			},
+			want:    OAuthClientGrantPolicy{GrantMethod: oauthv1.GrantHandlerAuto},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := GetOAuthClientGrantPolicy(operatorConfig)
+			if diff := deep.Equal(err != nil, tt.wantErr); diff != nil {
+				t.Errorf("%v: %v", diff, err)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestGetSessionPolicy(t *testing.T) {
+	clientInactivityTimeout, clientMaxAge := int32(600), int32(7200)
+	oauthConfig := &configv1.OAuth{Spec: configv1.OAuthSpec{TokenConfig: configv1.TokenConfig{
+		AccessTokenInactivityTimeout: &metav1.Duration{Duration: 5 * time.Minute},
+		AccessTokenMaxAgeSeconds:     86400,
+	}}}
+	tests := []struct {
+		name        string
+		authConfig  *configv1.Authentication
+		oauthClient *oauthv1.OAuthClient
+		want        SessionPolicy
+	}{
+		{
+			name:       "Test cluster token policy",
+			authConfig: &configv1.Authentication{},
+			want:       SessionPolicy{InactivityTimeoutSeconds: 300, AccessTokenMaxAgeSeconds: 86400},
+		},
+		{
+			name:       "Test console OAuth client overrides",
+			authConfig: &configv1.Authentication{Spec: configv1.AuthenticationSpec{Type: configv1.AuthenticationTypeIntegratedOAuth}},
+			oauthClient: &oauthv1.OAuthClient{
+				AccessTokenInactivityTimeoutSeconds: &clientInactivityTimeout,
+				AccessTokenMaxAgeSeconds:            &clientMaxAge,
+			},
+			want: SessionPolicy{InactivityTimeoutSeconds: 600, AccessTokenMaxAgeSeconds: 7200},
+		},
+		{
+			name:       "Test OIDC provider",
+			authConfig: &configv1.Authentication{Spec: configv1.AuthenticationSpec{Type: configv1.AuthenticationTypeOIDC}},
+			want:       SessionPolicy{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(GetSessionPolicy(tt.authConfig, oauthConfig, tt.oauthClient), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestIsOIDCPublicClient(t *testing.T) {
+	authConfig := func(authType configv1.AuthenticationType, clientSecretName string) *configv1.Authentication {
+		return &configv1.Authentication{Spec: configv1.AuthenticationSpec{
+			Type: authType,
+			OIDCProviders: []configv1.OIDCProvider{{
+				Name: "corporate",
+				OIDCClients: []configv1.OIDCClientConfig{{
+					ComponentNamespace: api.TargetNamespace,
+					ComponentName:      api.OpenShiftConsoleName,
+					ClientID:           "console",
+					ClientSecret:       configv1.SecretNameReference{Name: clientSecretName},
+				}},
+			}},
+		}}
+	}
+	publicClient := &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{api.OIDCPublicClientAnnotation: "true"}}}
+	tests := []struct {
+		name           string
+		operatorConfig *operatorv1.Console
+		authConfig     *configv1.Authentication
+		want           bool
+	}{
+		{
+			name:           "Test public client without a client secret",
+			operatorConfig: publicClient,
+			authConfig:     authConfig(configv1.AuthenticationTypeOIDC, ""),
+			want:           true,
+		},
+		{
+			name:           "Test public client annotation ignored with a client secret",
+			operatorConfig: publicClient,
+			authConfig:     authConfig(configv1.AuthenticationTypeOIDC, "console-secret"),
+			want:           false,
+		},
+		{
+			name:           "Test missing client secret without the public client annotation",
+			operatorConfig: &operatorv1.Console{},
+			authConfig:     authConfig(configv1.AuthenticationTypeOIDC, ""),
+			want:           false,
+		},
+		{
+			name:           "Test public client annotation ignored without OIDC",
+			operatorConfig: publicClient,
+			authConfig:     authConfig(configv1.AuthenticationTypeIntegratedOAuth, ""),
+			want:           false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(IsOIDCPublicClient(tt.operatorConfig, tt.authConfig), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestGetOIDCTokenValidationConfig(t *testing.T) {
+	operatorConfig := func(annotations map[string]string) *operatorv1.Console {
+		return &operatorv1.Console{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}
+	}
+	oidc := &configv1.Authentication{Spec: configv1.AuthenticationSpec{Type: configv1.AuthenticationTypeOIDC}}
+	tests := []struct {
+		name           string
+		operatorConfig *operatorv1.Console
+		authConfig     *configv1.Authentication
+		want           OIDCTokenValidationConfig
+	}{
+		{
+			name:           "Test console defaults",
+			operatorConfig: operatorConfig(nil),
+			authConfig:     oidc,
+			want:           OIDCTokenValidationConfig{},
+		},
+		{
+			name: "Test clock skew and ID token leeway",
+			operatorConfig: operatorConfig(map[string]string{
+				api.OIDCClockSkewAnnotation:     "30s",
+				api.OIDCIDTokenLeewayAnnotation: "2m",
+			}),
+			authConfig: oidc,
+			want:       OIDCTokenValidationConfig{ClockSkew: 30 * time.Second, IDTokenLeeway: 2 * time.Minute},
+		},
+		{
+			name: "Test leeway out of bounds ignored",
+			operatorConfig: operatorConfig(map[string]string{
+				api.OIDCClockSkewAnnotation:     "1h",
+				api.OIDCIDTokenLeewayAnnotation: "later",
+			}),
+			authConfig: oidc,
+			want:       OIDCTokenValidationConfig{},
+		},
+		{
+			name:           "Test leeway ignored without OIDC",
+			operatorConfig: operatorConfig(map[string]string{api.OIDCClockSkewAnnotation: "30s"}),
+			authConfig:     &configv1.Authentication{},
+			want:           OIDCTokenValidationConfig{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(GetOIDCTokenValidationConfig(tt.operatorConfig, tt.authConfig), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestGetContentSecurityPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        map[string][]string
+		wantErr     bool
+	}{
+		{
+			name:        "Test no content security policy",
+			annotations: map[string]string{},
+			want:        nil,
+		},
+		{
+			name:        "Test content security policy",
+			annotations: map[string]string{api.ContentSecurityPolicyAnnotation: "Script-Src https://b.example.com https://a.example.com; frame-src https://a.example.com;; script-src https://a.example.com"},
+			want: map[string][]string{
+				"frame-src":  {"https://a.example.com"},
+				"script-src": {"https://a.example.com", "https://b.example.com"},
+			},
+		},
+		{
+			name:        "Test invalid directives and sources are left out",
+			annotations: map[string]string{api.ContentSecurityPolicyAnnotation: "script-src 'self' 'unsafe-eval' * http://a.example.com; sandbox allow-scripts; img-src data: https:"},
+			want: map[string][]string{
+				"img-src":    {"data:"},
+				"script-src": {"'self'"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := GetContentSecurityPolicy(operatorConfig)
+			if diff := deep.Equal(err != nil, tt.wantErr); diff != nil {
+				t.Errorf("%v: %v", diff, err)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestContentSecurityPolicyAllows(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources []string
+		target  string
+		want    bool
+	}{
+		{
+			name:    "Test host source",
+			sources: []string{"https://status.example.com"},
+			target:  "https://status.example.com/api/v2/summary.json",
+			want:    true,
+		},
+		{
+			name:    "Test host source without a scheme",
+			sources: []string{"status.example.com"},
+			target:  "https://status.example.com/api/v2/summary.json",
+			want:    true,
+		},
+		{
+			name:    "Test wildcard host source",
+			sources: []string{"https://*.example.com"},
+			target:  "https://status.example.com/feed.json",
+			want:    true,
+		},
+		{
+			name:    "Test wildcard does not match the bare domain",
+			sources: []string{"https://*.example.com"},
+			target:  "https://example.com/feed.json",
+			want:    false,
+		},
+		{
+			name:    "Test port mismatch",
+			sources: []string{"https://status.example.com:8443"},
+			target:  "https://status.example.com/feed.json",
+			want:    false,
+		},
+		{
+			name:    "Test path prefix",
+			sources: []string{"https://status.example.com/api/"},
+			target:  "https://status.example.com/api/v2/summary.json",
+			want:    true,
+		},
+		{
+			name:    "Test other path",
+			sources: []string{"https://status.example.com/api/"},
+			target:  "https://status.example.com/feed.json",
+			want:    false,
+		},
+		{
+			name:    "Test keyword and scheme sources",
+			sources: []string{"'self'", "data:"},
+			target:  "https://status.example.com/feed.json",
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := url.Parse(tt.target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := deep.Equal(ContentSecurityPolicyAllows(tt.sources, target), tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestGetStatusProviderConfig(t *testing.T) {
+	contentSecurityPolicy := map[string][]string{"connect-src": {"https://status.example.com"}}
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        *StatusProviderConfig
+		wantErr     bool
+	}{
+		{
+			name:        "Test no status provider",
+			annotations: map[string]string{},
+			want:        nil,
+		},
+		{
+			name: "Test status provider",
+			annotations: map[string]string{
+				api.StatusProviderTypeAnnotation:     "statuspage",
+				api.StatusProviderURLAnnotation:      "https://status.example.com/api/v2/summary.json",
+				api.StatusProviderIntervalAnnotation: "1m",
+			},
+			want: &StatusProviderConfig{
+				Type:         StatusProviderTypeStatuspage,
+				URL:          "https://status.example.com/api/v2/summary.json",
+				PollInterval: time.Minute,
+			},
+		},
+		{
+			name: "Test invalid poll interval falls back to the default",
+			annotations: map[string]string{
+				api.StatusProviderTypeAnnotation:     "json",
+				api.StatusProviderURLAnnotation:      "https://status.example.com/feed.json",
+				api.StatusProviderIntervalAnnotation: "1s",
+			},
+			want: &StatusProviderConfig{
+				Type:         StatusProviderTypeJSON,
+				URL:          "https://status.example.com/feed.json",
+				PollInterval: StatusProviderDefaultInterval,
+			},
+		},
+		{
+			name: "Test missing URL",
+			annotations: map[string]string{
+				api.StatusProviderTypeAnnotation: "json",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Test unsupported type",
+			annotations: map[string]string{
+				api.StatusProviderTypeAnnotation: "rss",
+				api.StatusProviderURLAnnotation:  "https://status.example.com/feed.xml",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Test URL not served over https",
+			annotations: map[string]string{
+				api.StatusProviderTypeAnnotation: "json",
+				api.StatusProviderURLAnnotation:  "http://status.example.com/feed.json",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Test URL not allowed by connect-src",
+			annotations: map[string]string{
+				api.StatusProviderTypeAnnotation: "json",
+				api.StatusProviderURLAnnotation:  "https://status.other.com/feed.json",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operatorConfig := &operatorv1.Console{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			got, err := GetStatusProviderConfig(operatorConfig, contentSecurityPolicy)
+			if diff := deep.Equal(err != nil, tt.wantErr); diff != nil {
+				t.Errorf("%v: %v", diff, err)
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -246,6 +246,28 @@ func SetLogLevel(t *testing.T, client *ClientSet, logLevel operatorsv1.LogLevel)
 	return nil
 }
 
+// ForceSync sets the force-sync annotation on the operator config so that every controller syncs
+// right away, and waits for the operator to clear it.
+func ForceSync(t *testing.T, client *ClientSet) error {
+	t.Helper()
+	value := time.Now().UTC().Format(time.RFC3339Nano)
+	t.Logf("forcing a sync of the console operator (%s) ...", value)
+	patch := fmt.Sprintf(`{"metadata": {"annotations": {%q: %q}}}`, consoleapi.ForceSyncAnnotation, value)
+	_, err := client.Operator.Consoles().Patch(context.TODO(), consoleapi.ConfigResourceName, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		return err
+	}
+
+	return wait.PollImmediate(1*time.Second, 1*time.Minute, func() (bool, error) {
+		operatorConfig, err := client.Operator.Consoles().Get(context.TODO(), consoleapi.ConfigResourceName, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
+		}
+		_, pending := operatorConfig.Annotations[consoleapi.ForceSyncAnnotation]
+		return !pending, nil
+	})
+}
+
 func GenerationChanged(oldGeneration, newGeneration int64) bool {
 	return oldGeneration == newGeneration
 }